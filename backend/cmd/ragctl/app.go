@@ -0,0 +1,461 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/internal/services"
+	"bedrock-rag-sample/backend/internal/worker"
+	"bedrock-rag-sample/backend/pkg/aws"
+)
+
+// shutdownTimeout は終了時にバックグラウンドタスク (再インデックスなど) を待つ時間
+const shutdownTimeout = 30 * time.Second
+
+// app はサブコマンドが共有する出力先と依存先
+// 依存先はサブコマンドが必要とした時点で初期化する (migrate はBedrockやS3に接続しない)
+// テストでは初期化前のフィールドに差し替えたサービスを設定する
+type app struct {
+	cfg    *config.Config
+	stdout io.Writer
+	stderr io.Writer
+	format string // outputText / outputJSON
+
+	workers *worker.Group
+
+	db            *domain.DBHandler
+	documents     domain.DBHandlerInterface
+	s3Client      aws.S3ClientInterface
+	awsS3Client   *aws.S3Client
+	bedrockClient *aws.BedrockClient
+	textModels    *services.TextModelRouter
+
+	ingestService    services.IngestServiceInterface
+	qaService        services.QAServiceInterface
+	recommendService services.RecommendServiceInterface
+	summarizeService services.SummarizeServiceInterface
+	extractService   services.ExtractServiceInterface
+	reindexService   services.ReindexServiceInterface
+	syncService      services.S3SyncServiceInterface
+	crawlService     services.WebCrawlServiceInterface
+	gitSyncService   services.GitSyncServiceInterface
+	migrator         interface {
+		Migrate(ctx context.Context) error
+	}
+}
+
+// newApp は新しいappを生成する
+func newApp(cfg *config.Config, stdout, stderr io.Writer) *app {
+	return &app{
+		cfg:     cfg,
+		stdout:  stdout,
+		stderr:  stderr,
+		format:  outputText,
+		workers: worker.NewGroup(),
+	}
+}
+
+// close はバックグラウンドタスクの完了を待ち、DBとの接続を閉じる
+func (a *app) close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if err := a.workers.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("バックグラウンドタスクが終了しませんでした: %w", err))
+	}
+	if a.db != nil {
+		if err := a.db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("データベース接続のクローズに失敗しました: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// database はDBハンドラーを返す
+func (a *app) database() (*domain.DBHandler, error) {
+	if a.db != nil {
+		return a.db, nil
+	}
+	db, err := domain.NewDBHandler(a.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("データベースに接続できません: %w", err)
+	}
+	a.db = db
+	return db, nil
+}
+
+// documentStore はドキュメントの一覧と削除に使用するDBハンドラーを返す
+func (a *app) documentStore() (domain.DBHandlerInterface, error) {
+	if a.documents != nil {
+		return a.documents, nil
+	}
+	db, err := a.database()
+	if err != nil {
+		return nil, err
+	}
+	a.documents = db
+	return db, nil
+}
+
+// s3 はS3クライアントを返す
+func (a *app) s3() (*aws.S3Client, error) {
+	if a.awsS3Client != nil {
+		return a.awsS3Client, nil
+	}
+	client, err := aws.NewS3Client(a.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("S3クライアントの初期化に失敗しました: %w", err)
+	}
+	a.awsS3Client = client
+	return client, nil
+}
+
+// objectStore はS3のオブジェクトの一覧と取得に使用するクライアントを返す
+func (a *app) objectStore() (aws.S3ClientInterface, error) {
+	if a.s3Client != nil {
+		return a.s3Client, nil
+	}
+	client, err := a.s3()
+	if err != nil {
+		return nil, err
+	}
+	a.s3Client = client
+	return client, nil
+}
+
+// bedrock はBedrockクライアントを返す
+// Embeddingキャッシュと使用量の記録はサーバーと同じ設定で有効にする (DBに接続できる場合のみ)
+func (a *app) bedrock() (*aws.BedrockClient, error) {
+	if a.bedrockClient != nil {
+		return a.bedrockClient, nil
+	}
+	client, err := aws.NewBedrockClient(a.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("Bedrockクライアントの初期化に失敗しました: %w", err)
+	}
+	if a.cfg.EmbedCache.Enabled || a.cfg.Usage.Enabled {
+		if db, err := a.database(); err == nil {
+			if a.cfg.EmbedCache.Enabled {
+				client.SetEmbeddingCache(services.NewEmbeddingCache(db, a.cfg.EmbedCache.MemoryEntries))
+			}
+			if a.cfg.Usage.Enabled {
+				client.SetUsageRecorder(services.NewUsageService(db, services.NewPriceTable(a.cfg.Usage.Prices)))
+			}
+		}
+	}
+	a.bedrockClient = client
+	return client, nil
+}
+
+// models はテキスト生成モデルの選択を返す
+func (a *app) models() (*services.TextModelRouter, error) {
+	if a.textModels != nil {
+		return a.textModels, nil
+	}
+	router, err := services.NewTextModelRouter(a.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("テキスト生成モデルの設定が不正です: %w", err)
+	}
+	a.textModels = router
+	return router, nil
+}
+
+// redactor はBedrockに送るテキストの個人情報を除去する処理を返す (REDACT_ENABLED を指定していない場合は nil)
+// 監査の記録はサーバーと同じくDBに保存する (DBに接続できる場合のみ)
+func (a *app) redactor() (*services.PIIRedactor, error) {
+	if !a.cfg.Redact.Enabled {
+		return nil, nil
+	}
+	var store domain.DBHandlerInterface
+	if db, err := a.database(); err == nil {
+		store = db
+	}
+	redactor, err := services.NewPIIRedactor(a.cfg.Redact, store)
+	if err != nil {
+		return nil, fmt.Errorf("個人情報の除去の設定が不正です: %w", err)
+	}
+	return redactor, nil
+}
+
+// recommend は検索に使用するサービスを返す
+func (a *app) recommend() (services.RecommendServiceInterface, error) {
+	if a.recommendService != nil {
+		return a.recommendService, nil
+	}
+	db, err := a.database()
+	if err != nil {
+		return nil, err
+	}
+	client, err := a.bedrock()
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := a.redactor()
+	if err != nil {
+		return nil, err
+	}
+	a.recommendService = services.NewRecommendService(client, db, redactor)
+	return a.recommendService, nil
+}
+
+// ingest はドキュメントの取り込みに使用するサービスを返す
+func (a *app) ingest() (services.IngestServiceInterface, error) {
+	if a.ingestService != nil {
+		return a.ingestService, nil
+	}
+	recommendService, err := a.recommend()
+	if err != nil {
+		return nil, err
+	}
+	s3Client, err := a.s3()
+	if err != nil {
+		return nil, err
+	}
+	textractClient, err := aws.NewTextractClient(a.cfg, s3Client)
+	if err != nil {
+		return nil, fmt.Errorf("Textractクライアントの初期化に失敗しました: %w", err)
+	}
+	db, err := a.database()
+	if err != nil {
+		return nil, err
+	}
+	classifier, err := a.classifier()
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := a.redactor()
+	if err != nil {
+		return nil, err
+	}
+	a.ingestService = services.NewIngestService(s3Client, textractClient, db, recommendService, classifier, redactor, a.cfg.Ingest)
+	return a.ingestService, nil
+}
+
+// taxonomy はドキュメントのカテゴリの一覧を返す
+func (a *app) taxonomy() (*services.Taxonomy, error) {
+	taxonomy, err := services.LoadTaxonomy(a.cfg.Classify)
+	if err != nil {
+		return nil, fmt.Errorf("タクソノミーの設定が不正です: %w", err)
+	}
+	return taxonomy, nil
+}
+
+// classifier は取り込み時の分類器を返す (CLASSIFY_MODE を指定していない場合は nil)
+func (a *app) classifier() (services.DocumentClassifier, error) {
+	if a.cfg.Classify.Mode == "" {
+		return nil, nil
+	}
+	taxonomy, err := a.taxonomy()
+	if err != nil {
+		return nil, err
+	}
+	client, err := a.bedrock()
+	if err != nil {
+		return nil, err
+	}
+	extractService, err := a.extract()
+	if err != nil {
+		return nil, err
+	}
+	classifier, err := services.NewDocumentClassifier(a.cfg.Classify, taxonomy, client, extractService)
+	if err != nil {
+		return nil, fmt.Errorf("分類の設定が不正です: %w", err)
+	}
+	return classifier, nil
+}
+
+// qa はQAサービスを返す (回答キャッシュは使用しない)
+func (a *app) qa() (services.QAServiceInterface, error) {
+	if a.qaService != nil {
+		return a.qaService, nil
+	}
+	client, err := a.bedrock()
+	if err != nil {
+		return nil, err
+	}
+	router, err := a.models()
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := a.redactor()
+	if err != nil {
+		return nil, err
+	}
+	qaService, err := services.NewQAService(client, a.cfg, router, nil, redactor)
+	if err != nil {
+		return nil, fmt.Errorf("QAサービスの初期化に失敗しました (BEDROCK_KB_IDを確認してください): %w", err)
+	}
+	a.qaService = qaService
+	return qaService, nil
+}
+
+// summarize は要約サービスを返す
+func (a *app) summarize() (services.SummarizeServiceInterface, error) {
+	if a.summarizeService != nil {
+		return a.summarizeService, nil
+	}
+	client, err := a.bedrock()
+	if err != nil {
+		return nil, err
+	}
+	router, err := a.models()
+	if err != nil {
+		return nil, err
+	}
+	s3Client, err := a.s3()
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := a.redactor()
+	if err != nil {
+		return nil, err
+	}
+	a.summarizeService = services.NewSummarizeService(client, services.NewUploadService(s3Client), router, redactor)
+	return a.summarizeService, nil
+}
+
+// extract は構造化データ抽出サービスを返す
+func (a *app) extract() (services.ExtractServiceInterface, error) {
+	if a.extractService != nil {
+		return a.extractService, nil
+	}
+	client, err := a.bedrock()
+	if err != nil {
+		return nil, err
+	}
+	router, err := a.models()
+	if err != nil {
+		return nil, err
+	}
+	summarizeService, err := a.summarize()
+	if err != nil {
+		return nil, err
+	}
+	s3Client, err := a.s3()
+	if err != nil {
+		return nil, err
+	}
+	textractClient, err := aws.NewTextractClient(a.cfg, s3Client)
+	if err != nil {
+		return nil, fmt.Errorf("Textractクライアントの初期化に失敗しました: %w", err)
+	}
+	redactor, err := a.redactor()
+	if err != nil {
+		return nil, err
+	}
+	a.extractService = services.NewExtractService(client, services.NewDocumentService(textractClient, summarizeService), router, redactor)
+	return a.extractService, nil
+}
+
+// reindex は再インデックスサービスを返す
+// 再インデックスはこのプロセスのバックグラウンドタスクとして実行する
+func (a *app) reindex() (services.ReindexServiceInterface, error) {
+	if a.reindexService != nil {
+		return a.reindexService, nil
+	}
+	db, err := a.database()
+	if err != nil {
+		return nil, err
+	}
+	client, err := a.bedrock()
+	if err != nil {
+		return nil, err
+	}
+	a.reindexService = services.NewReindexService(client, db, a.workers)
+	return a.reindexService, nil
+}
+
+// s3Sync はS3のプレフィックスの同期サービスを返す
+// SYNC_ENABLED に関わらず、サーバーと同じプレフィックス (SYNC_S3_PREFIX) を同期する
+func (a *app) s3Sync() (services.S3SyncServiceInterface, error) {
+	if a.syncService != nil {
+		return a.syncService, nil
+	}
+	ingestService, err := a.ingest()
+	if err != nil {
+		return nil, err
+	}
+	s3Client, err := a.s3()
+	if err != nil {
+		return nil, err
+	}
+	db, err := a.database()
+	if err != nil {
+		return nil, err
+	}
+	syncService, err := services.NewS3SyncService(s3Client, db, ingestService, a.workers, a.cfg)
+	if err != nil {
+		return nil, err
+	}
+	a.syncService = syncService
+	return syncService, nil
+}
+
+// webCrawl はWebページのクロールサービスを返す
+// サーバーと同じシード・サイトマップ (CRAWL_SEEDS・CRAWL_SITEMAPS) をクロールする
+func (a *app) webCrawl() (services.WebCrawlServiceInterface, error) {
+	if a.crawlService != nil {
+		return a.crawlService, nil
+	}
+	ingestService, err := a.ingest()
+	if err != nil {
+		return nil, err
+	}
+	s3Client, err := a.s3()
+	if err != nil {
+		return nil, err
+	}
+	db, err := a.database()
+	if err != nil {
+		return nil, err
+	}
+	crawlService, err := services.NewWebCrawlService(s3Client, db, ingestService, a.workers, a.cfg)
+	if err != nil {
+		return nil, err
+	}
+	a.crawlService = crawlService
+	return crawlService, nil
+}
+
+// gitSync はgitリポジトリの同期サービスを返す
+// サーバーと同じリポジトリ・ref (GIT_REPO・GIT_REF) を同期する
+func (a *app) gitSync() (services.GitSyncServiceInterface, error) {
+	if a.gitSyncService != nil {
+		return a.gitSyncService, nil
+	}
+	ingestService, err := a.ingest()
+	if err != nil {
+		return nil, err
+	}
+	db, err := a.database()
+	if err != nil {
+		return nil, err
+	}
+	gitSyncService, err := services.NewGitSyncService(db, ingestService, a.workers, a.cfg)
+	if err != nil {
+		return nil, err
+	}
+	a.gitSyncService = gitSyncService
+	return gitSyncService, nil
+}
+
+// migrations はスキーマのマイグレーションを適用するDBハンドラーを返す
+func (a *app) migrations() (interface {
+	Migrate(ctx context.Context) error
+}, error) {
+	if a.migrator != nil {
+		return a.migrator, nil
+	}
+	db, err := a.database()
+	if err != nil {
+		return nil, err
+	}
+	a.migrator = db
+	return db, nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/internal/services"
+)
+
+// runDocs はドキュメントの一覧・分類の編集・削除を行う
+func runDocs(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 {
+		return usageError("docs list、docs tag または docs delete を指定してください")
+	}
+	switch args[0] {
+	case "list":
+		return runDocsList(ctx, a, args[1:])
+	case "tag":
+		return runDocsTag(ctx, a, args[1:])
+	case "delete":
+		return runDocsDelete(ctx, a, args[1:])
+	default:
+		return usageError("不明なサブコマンドです: docs %s", args[0])
+	}
+}
+
+// runDocsList はドキュメントをIDの順に一覧する
+func runDocsList(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "docs list")
+	limit := flags.Int("limit", 100, "最大件数")
+	after := flags.Int64("after", 0, "このIDより後のドキュメントを表示する (ページング用)")
+	allVersions := flags.Bool("all-versions", false, "最新以外の版も表示する")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	store, err := a.documentStore()
+	if err != nil {
+		return err
+	}
+	docs, err := store.ListDocuments(ctx, domain.DocumentListFilter{AfterID: *after, Limit: *limit, AllVersions: *allVersions})
+	if err != nil {
+		return err
+	}
+	if docs == nil {
+		docs = []domain.Document{}
+	}
+
+	return a.print(docs, func(w io.Writer) {
+		if len(docs) == 0 {
+			fmt.Fprintln(w, "ドキュメントはありません")
+			return
+		}
+		fmt.Fprintf(w, "%-8s %-10s %-7s %-6s %-20s %-10s %s\n", "ID", "LOGICAL_ID", "VERSION", "LATEST", "CREATED_AT", "CATEGORY", "FILENAME")
+		for _, doc := range docs {
+			category := doc.Category
+			if category == "" {
+				category = "-"
+			}
+			fmt.Fprintf(w, "%-8d %-10d %-7d %-6t %-20s %-10s %s%s\n", doc.ID, doc.LogicalID, doc.Version, doc.IsLatest, doc.CreatedAt.Format("2006-01-02 15:04:05"), category, doc.Filename, formatTags(doc.Tags))
+		}
+		if len(docs) == *limit {
+			fmt.Fprintf(w, "\n続きは -after %d で表示できます\n", docs[len(docs)-1].ID)
+		}
+	})
+}
+
+// runDocsTag はドキュメント (版) のカテゴリとタグを置き換える
+func runDocsTag(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "docs tag")
+	category := flags.String("category", "", "カテゴリ (省略時は未分類)")
+	tags := flags.String("tags", "", "タグ (カンマ区切り。省略時はタグをすべて外す)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError("分類を編集するドキュメントのIDを1つ指定してください")
+	}
+	id, err := strconv.ParseInt(flags.Arg(0), 10, 64)
+	if err != nil || id <= 0 {
+		return usageError("ドキュメントIDが不正です: %s", flags.Arg(0))
+	}
+
+	store, err := a.documentStore()
+	if err != nil {
+		return err
+	}
+	taxonomy, err := a.taxonomy()
+	if err != nil {
+		return err
+	}
+	doc, err := services.NewDocumentTagService(store, taxonomy).UpdateClassification(ctx, id, *category, splitTags(*tags))
+	if err != nil {
+		return err
+	}
+
+	return a.print(doc, func(w io.Writer) {
+		category := doc.Category
+		if category == "" {
+			category = "(未分類)"
+		}
+		fmt.Fprintf(w, "%d %s: %s%s\n", doc.ID, doc.Filename, category, formatTags(doc.Tags))
+	})
+}
+
+// splitTags はカンマ区切りのタグを分割する (空の場合は nil)
+func splitTags(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// formatTags はタグを一覧の表示用に整形する
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(tags, ", ") + "]"
+}
+
+// deletedDocument はドキュメント1件の削除結果
+type deletedDocument struct {
+	ID      int64  `json:"id"`
+	Deleted bool   `json:"deleted"`
+	Error   string `json:"error,omitempty"`
+}
+
+// runDocsDelete はドキュメント (版) とそのチャンクを削除する
+// S3のオブジェクトは削除しない (版ごとのファイルを後から確認できるよう残す)
+func runDocsDelete(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "docs delete")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return usageError("削除するドキュメントのIDを指定してください")
+	}
+	ids := make([]int64, flags.NArg())
+	for i, arg := range flags.Args() {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || id <= 0 {
+			return usageError("ドキュメントIDが不正です: %s", arg)
+		}
+		ids[i] = id
+	}
+
+	store, err := a.documentStore()
+	if err != nil {
+		return err
+	}
+	results := make([]deletedDocument, len(ids))
+	var failed int
+	for i, id := range ids {
+		results[i] = deletedDocument{ID: id, Deleted: true}
+		if err := store.DeleteDocument(ctx, id); err != nil {
+			results[i] = deletedDocument{ID: id, Error: err.Error()}
+			failed++
+		}
+	}
+
+	if err := a.print(results, func(w io.Writer) {
+		for _, r := range results {
+			if r.Deleted {
+				fmt.Fprintf(w, "deleted  %d\n", r.ID)
+			} else {
+				fmt.Fprintf(w, "failed   %d: %s\n", r.ID, r.Error)
+			}
+		}
+	}); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d件の削除に失敗しました", failed)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"bedrock-rag-sample/backend/internal/eval"
+)
+
+// runEval はゴールデンデータセットをQAサービスと検索に通して品質を評価する
+// -offline を指定した場合はAWSとDBに接続せず、-corpus のドキュメントを取り込んだフェイクで評価する
+func runEval(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "eval")
+	golden := flags.String("golden", "", "ゴールデンデータセット (JSONL)")
+	offline := flags.Bool("offline", false, "オフラインのフェイクで評価する")
+	corpus := flags.String("corpus", "", "オフライン評価で取り込むドキュメント (JSONL)")
+	k := flags.Int("k", eval.DefaultK, "recall@k と nDCG@k の k")
+	label := flags.String("label", "", "レポートの見出し (プロンプトやチャンクサイズなどの条件)")
+	model := flags.String("model", "", "回答の生成に使用するモデルのカタログ名")
+	jsonPath := flags.String("json", "", "JSONのレポートの出力先")
+	markdownPath := flags.String("markdown", "", "Markdownのレポートの出力先")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *golden == "" {
+		return usageError("-golden を指定してください")
+	}
+	if *offline != (*corpus != "") {
+		return usageError("-offline と -corpus は同時に指定してください")
+	}
+
+	cases, err := loadFile(*golden, eval.LoadGoldenSet)
+	if err != nil {
+		return err
+	}
+
+	opts := eval.Options{Label: *label, K: *k, Model: *model}
+	var runner *eval.Runner
+	if *offline {
+		docs, err := loadFile(*corpus, eval.LoadCorpus)
+		if err != nil {
+			return err
+		}
+		env, err := eval.NewOfflineEnvironment(ctx, docs)
+		if err != nil {
+			return err
+		}
+		runner = env.NewRunner(opts)
+	} else {
+		qaService, err := a.qa()
+		if err != nil {
+			return err
+		}
+		recommendService, err := a.recommend()
+		if err != nil {
+			return err
+		}
+		client, err := a.bedrock()
+		if err != nil {
+			return err
+		}
+		runner = eval.NewRunner(qaService, recommendService, client, opts)
+	}
+
+	report, err := runner.Run(ctx, *golden, cases)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(*jsonPath, report.WriteJSON); err != nil {
+		return err
+	}
+	if err := writeFile(*markdownPath, report.WriteMarkdown); err != nil {
+		return err
+	}
+
+	if a.format == outputJSON {
+		return report.WriteJSON(a.stdout)
+	}
+	return report.WriteMarkdown(a.stdout)
+}
+
+// loadFile はファイルを開いて load で読み込む
+func loadFile[T any](path string, load func(io.Reader) (T, error)) (T, error) {
+	var zero T
+	f, err := os.Open(path)
+	if err != nil {
+		return zero, fmt.Errorf("ファイルを開けません: %w", err)
+	}
+	defer f.Close()
+
+	v, err := load(f)
+	if err != nil {
+		return zero, fmt.Errorf("%s: %w", path, err)
+	}
+	return v, nil
+}
+
+// writeFile は write でファイルに書き出す (path が空の場合は何もしない)
+func writeFile(path string, write func(io.Writer) error) (err error) {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("ファイルを作成できません: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("ファイルの書き込みに失敗しました: %w", closeErr)
+		}
+	}()
+	return write(f)
+}
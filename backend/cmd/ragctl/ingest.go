@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"bedrock-rag-sample/backend/internal/services"
+)
+
+// 取り込み結果の状態
+const (
+	ingestCreated = "created" // 新しいドキュメント (または版) として取り込んだ
+	ingestLinked  = "linked"  // 重複のため既存のドキュメントを返した
+	ingestSkipped = "skipped" // 取り込めないファイル形式のため読み飛ばした
+	ingestFailed  = "failed"
+)
+
+// ingestItem は取り込み対象1件の結果
+type ingestItem struct {
+	Source string                 `json:"source"` // ローカルのパスまたは s3://bucket/key
+	Status string                 `json:"status"`
+	Result *services.IngestResult `json:"result,omitempty"`
+	Error  string                 `json:"error,omitempty"`
+}
+
+// ingestSource は取り込み対象のファイル
+type ingestSource struct {
+	name string // 表示名
+	file string // 取り込むファイル名 (拡張子でテキストの抽出方法を決める)
+	read func(ctx context.Context) ([]byte, error)
+}
+
+// runIngest はファイル・ディレクトリ (再帰的に走査する)・S3のプレフィックスを取り込む
+// 一部のファイルの取り込みに失敗しても残りの取り込みを続け、最後にエラーを返す
+func runIngest(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "ingest")
+	onDuplicate := flags.String("on-duplicate", "", "重複したドキュメントの扱い (reject / link / version。省略時は設定の既定値)")
+	versionOf := flags.Int64("version-of", 0, "指定した論理ドキュメントの新しい版として取り込む (ファイル1件のみ)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return usageError("取り込むファイル・ディレクトリ・S3のプレフィックスを指定してください")
+	}
+
+	var sources []ingestSource
+	for _, target := range flags.Args() {
+		found, err := a.collectSources(ctx, target)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, found...)
+	}
+	if *versionOf != 0 && len(sources) != 1 {
+		return usageError("-version-of はファイル1件にのみ指定できます (%d件)", len(sources))
+	}
+
+	ingestService, err := a.ingest()
+	if err != nil {
+		return err
+	}
+
+	items := make([]ingestItem, 0, len(sources))
+	var failed int
+	for _, source := range sources {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("取り込みが中断されました: %w", err)
+		}
+		item := ingestOne(ctx, ingestService, source, *onDuplicate, *versionOf)
+		if item.Status == ingestFailed {
+			failed++
+		}
+		if a.format == outputText {
+			printIngestItem(a.stdout, item)
+		}
+		items = append(items, item)
+	}
+
+	if err := a.print(items, func(w io.Writer) {
+		counts := make(map[string]int)
+		for _, item := range items {
+			counts[item.Status]++
+		}
+		fmt.Fprintf(w, "取り込み: %d件 (新規: %d, 既存: %d, スキップ: %d, 失敗: %d)\n",
+			len(items), counts[ingestCreated], counts[ingestLinked], counts[ingestSkipped], counts[ingestFailed])
+	}); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d件の取り込みに失敗しました", failed)
+	}
+	return nil
+}
+
+// ingestOne はファイル1件を取り込む
+func ingestOne(ctx context.Context, ingestService services.IngestServiceInterface, source ingestSource, onDuplicate string, logicalID int64) ingestItem {
+	item := ingestItem{Source: source.name}
+	content, err := source.read(ctx)
+	if err != nil {
+		item.Status, item.Error = ingestFailed, err.Error()
+		return item
+	}
+
+	result, err := ingestService.Ingest(ctx, services.IngestRequest{
+		Filename:        source.file,
+		Content:         content,
+		DuplicatePolicy: onDuplicate,
+		LogicalID:       logicalID,
+	})
+	switch {
+	case errors.Is(err, services.ErrUnsupportedFileType):
+		item.Status, item.Error = ingestSkipped, err.Error()
+	case err != nil:
+		item.Status, item.Error = ingestFailed, err.Error()
+	case result.Linked:
+		item.Status, item.Result = ingestLinked, result
+	default:
+		item.Status, item.Result = ingestCreated, result
+	}
+	return item
+}
+
+// printIngestItem は取り込み結果1件を1行で表示する
+func printIngestItem(w io.Writer, item ingestItem) {
+	if item.Result == nil || item.Result.Document == nil {
+		fmt.Fprintf(w, "%-8s %s: %s\n", item.Status, item.Source, item.Error)
+		return
+	}
+	doc := item.Result.Document
+	fmt.Fprintf(w, "%-8s %s -> id=%d logical_id=%d version=%d\n", item.Status, item.Source, doc.ID, doc.LogicalID, doc.Version)
+}
+
+// collectSources は取り込み対象のファイルを列挙する
+func (a *app) collectSources(ctx context.Context, target string) ([]ingestSource, error) {
+	if strings.HasPrefix(target, "s3://") {
+		return a.collectS3Sources(ctx, target)
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, fmt.Errorf("取り込み対象を開けません: %w", err)
+	}
+	if !info.IsDir() {
+		return []ingestSource{localSource(target)}, nil
+	}
+
+	var sources []ingestSource
+	err = filepath.WalkDir(target, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// 隠しディレクトリ (.git など) と隠しファイルは対象にしない
+		if p != target && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			sources = append(sources, localSource(p))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ディレクトリの走査に失敗しました (%s): %w", target, err)
+	}
+	return sources, nil
+}
+
+// localSource はローカルのファイルを取り込み対象にする
+func localSource(p string) ingestSource {
+	return ingestSource{
+		name: p,
+		file: filepath.Base(p),
+		read: func(context.Context) ([]byte, error) {
+			content, err := os.ReadFile(p)
+			if err != nil {
+				return nil, fmt.Errorf("ファイルの読み込みに失敗しました: %w", err)
+			}
+			return content, nil
+		},
+	}
+}
+
+// collectS3Sources は s3://bucket/prefix 以下のオブジェクトを取り込み対象にする
+// バケットは設定のバケット (S3_BUCKET_NAME) と同じである必要がある
+func (a *app) collectS3Sources(ctx context.Context, target string) ([]ingestSource, error) {
+	bucket, prefix, err := parseS3URI(target)
+	if err != nil {
+		return nil, err
+	}
+	if bucket != a.cfg.AWS.S3BucketName {
+		return nil, usageError("設定のバケット (%s) 以外からは取り込めません: %s", a.cfg.AWS.S3BucketName, bucket)
+	}
+
+	s3Client, err := a.objectStore()
+	if err != nil {
+		return nil, err
+	}
+	objects, err := s3Client.ListObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]ingestSource, len(objects))
+	for i, obj := range objects {
+		key := obj.Key
+		sources[i] = ingestSource{
+			name: "s3://" + bucket + "/" + key,
+			file: path.Base(key),
+			read: func(ctx context.Context) ([]byte, error) {
+				return s3Client.DownloadFileContent(ctx, key)
+			},
+		}
+	}
+	return sources, nil
+}
+
+// parseS3URI は s3://bucket/prefix をバケットとプレフィックスに分ける
+func parseS3URI(uri string) (bucket, prefix string, err error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return "", "", usageError("S3のプレフィックスは s3://bucket/prefix の形式で指定してください: %s", uri)
+	}
+	return u.Host, strings.TrimPrefix(u.Path, "/"), nil
+}
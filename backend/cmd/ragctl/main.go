@@ -0,0 +1,151 @@
+// ragctl はドキュメントの取り込み・検索・運用操作をHTTPを経由せずに行うコマンドラインツール
+// サーバーと同じ環境変数の設定を読み込み、services パッケージの処理をそのまま呼び出す
+//
+//	ragctl [-o text|json] <command> [flags] [args]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"bedrock-rag-sample/backend/config"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// command はサブコマンドの定義
+type command struct {
+	usage string // 引数の書式
+	short string // 説明
+	run   func(ctx context.Context, a *app, args []string) error
+}
+
+// commands はサブコマンドの一覧
+var commands = map[string]command{
+	"ingest":    {usage: "ingest [-on-duplicate reject|link|version] [-version-of ID] <file|dir|s3://bucket/prefix>...", short: "ファイル・ディレクトリ・S3のプレフィックスを取り込む", run: runIngest},
+	"query":     {usage: `query [-model NAME] "<question>"`, short: "質問に回答する", run: runQuery},
+	"recommend": {usage: `recommend [-limit N] [-all-versions] [-category NAME] [-tags TAG,...] "<query>"`, short: "クエリに類似したドキュメントを検索する", run: runRecommend},
+	"summarize": {usage: `summarize [-model NAME] (-file PATH | -s3-key KEY | "<text>")`, short: "テキスト・ファイルを要約する", run: runSummarize},
+	"extract":   {usage: `extract -schema FILE [-model NAME] (-file PATH | -s3-key KEY | "<text>")`, short: "JSON Schemaに従う構造化データを抽出する", run: runExtract},
+	"docs":      {usage: "docs list [-limit N] [-after ID] [-all-versions] | docs tag [-category NAME] [-tags TAG,...] <id> | docs delete <id>...", short: "ドキュメントの一覧・分類の編集・削除", run: runDocs},
+	"reindex":   {usage: "reindex start <model> | status | activate | rollback | finalize | cancel", short: "Embeddingモデルの切り替え (再インデックス)", run: runReindex},
+	"sync":      {usage: "sync run | status", short: "S3のプレフィックスに置かれたドキュメントを同期する (SYNC_S3_PREFIX)", run: runSync},
+	"crawl":     {usage: "crawl run | status", short: "Webページをクロールして取り込む (CRAWL_SEEDS・CRAWL_SITEMAPS)", run: runCrawl},
+	"gitsync":   {usage: "gitsync run | status", short: "gitリポジトリのドキュメントを同期する (GIT_REPO・GIT_REF)", run: runGitSync},
+	"migrate":   {usage: "migrate", short: "データベースのスキーマのマイグレーションを適用する", run: runMigrate},
+	"eval":      {usage: "eval -golden FILE [-offline -corpus FILE] [-k N] [-label TEXT] [-model NAME] [-json FILE] [-markdown FILE]", short: "ゴールデンデータセットで品質を評価する", run: runEval},
+}
+
+// errUsage は引数が不正な場合のエラー (使い方を表示して終了コード2で終了する)
+var errUsage = errors.New("引数が不正です")
+
+// usageError は使い方の誤りを表すエラーを返す
+func usageError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+func main() {
+	// ログは標準エラー出力に人が読める形式で出力し、標準出力は結果だけにする
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	if levelStr := os.Getenv("LOG_LEVEL"); levelStr != "" {
+		if level, err := zerolog.ParseLevel(levelStr); err == nil {
+			zerolog.SetGlobalLevel(level)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	code := run(ctx, newApp(config.NewConfig(), os.Stdout, os.Stderr), os.Args[1:])
+	stop()
+	os.Exit(code)
+}
+
+// run はコマンドラインを解釈してサブコマンドを実行し、終了コードを返す
+func run(ctx context.Context, a *app, args []string) int {
+	global := flag.NewFlagSet("ragctl", flag.ContinueOnError)
+	global.SetOutput(a.stderr)
+	global.StringVar(&a.format, "o", outputText, "出力形式 (text / json)")
+	global.Usage = func() { printUsage(a.stderr) }
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if a.format != outputText && a.format != outputJSON {
+		fmt.Fprintf(a.stderr, "出力形式は text または json を指定してください: %s\n", a.format)
+		return 2
+	}
+
+	if global.NArg() == 0 {
+		printUsage(a.stderr)
+		return 2
+	}
+	name := global.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(a.stderr, "不明なコマンドです: %s\n\n", name)
+		printUsage(a.stderr)
+		return 2
+	}
+
+	err := cmd.run(ctx, a, global.Args()[1:])
+	if closeErr := a.close(); closeErr != nil {
+		fmt.Fprintf(a.stderr, "warning: %v\n", closeErr)
+	}
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(a.stderr, "%v\n使い方: ragctl %s\n", err, cmd.usage)
+		return 2
+	default:
+		fmt.Fprintf(a.stderr, "error: %v\n", err)
+		return 1
+	}
+}
+
+// printUsage はサブコマンドの一覧を表示する
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "使い方: ragctl [-o text|json] <command> [flags] [args]")
+	fmt.Fprintln(w, "\nコマンド:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].short)
+		fmt.Fprintf(w, "  %-10s   ragctl %s\n", "", commands[name].usage)
+	}
+	fmt.Fprintln(w, "\n設定はサーバーと同じ環境変数 (AWS_REGION, DB_HOST など) から読み込みます")
+}
+
+// newFlagSet はサブコマンドのフラグを解析する FlagSet を生成する
+func newFlagSet(a *app, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("ragctl "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	return fs
+}
+
+// parseFlags はフラグを解析する (フラグの後に位置引数が続く)
+// 解析のエラーは FlagSet が表示済みのため、使い方の誤りとして返す
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	return nil
+}
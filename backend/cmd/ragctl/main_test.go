@@ -0,0 +1,585 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	domainmocks "bedrock-rag-sample/backend/internal/domain/mocks"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+	"bedrock-rag-sample/backend/pkg/aws"
+	awsmock "bedrock-rag-sample/backend/pkg/aws/mock"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestApp はテスト用のappと標準出力・標準エラー出力のバッファを返す
+func newTestApp() (*app, *bytes.Buffer, *bytes.Buffer) {
+	var stdout, stderr bytes.Buffer
+	cfg := &config.Config{AWS: config.AWSConfig{S3BucketName: "docs-bucket"}}
+	return newApp(cfg, &stdout, &stderr), &stdout, &stderr
+}
+
+// migratorFunc は関数をマイグレーションの実行に使う
+type migratorFunc func(ctx context.Context) error
+
+func (f migratorFunc) Migrate(ctx context.Context) error { return f(ctx) }
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("異常系: 不明なコマンドは使い方を表示して終了コード2", func(t *testing.T) {
+		a, _, stderr := newTestApp()
+
+		code := run(ctx, a, []string{"bogus"})
+
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr.String(), "不明なコマンドです: bogus")
+		assert.Contains(t, stderr.String(), "ragctl ingest")
+	})
+
+	t.Run("異常系: 不正な出力形式", func(t *testing.T) {
+		a, _, _ := newTestApp()
+
+		assert.Equal(t, 2, run(ctx, a, []string{"-o", "yaml", "migrate"}))
+	})
+
+	t.Run("正常系: migrate", func(t *testing.T) {
+		a, stdout, _ := newTestApp()
+		called := false
+		a.migrator = migratorFunc(func(context.Context) error {
+			called = true
+			return nil
+		})
+
+		code := run(ctx, a, []string{"migrate"})
+
+		assert.Equal(t, 0, code)
+		assert.True(t, called)
+		assert.Contains(t, stdout.String(), "マイグレーションを適用しました")
+	})
+
+	t.Run("異常系: サービスのエラーは終了コード1", func(t *testing.T) {
+		a, _, stderr := newTestApp()
+		a.migrator = migratorFunc(func(context.Context) error { return errors.New("connection refused") })
+
+		code := run(ctx, a, []string{"migrate"})
+
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr.String(), "connection refused")
+	})
+}
+
+func TestRunIngest(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: ディレクトリを再帰的に取り込み、取り込めない形式は読み飛ばす", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "hr", ".git"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "hr", "leave.txt"), []byte("有給休暇"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "logo.svg"), []byte("<svg/>"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "hr", ".git", "HEAD"), []byte("ref"), 0o644))
+
+		ctrl := gomock.NewController(t)
+		mockIngestService := servicemocks.NewMockIngestServiceInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.ingestService = mockIngestService
+		a.format = outputJSON
+
+		mockIngestService.EXPECT().Ingest(gomock.Any(), services.IngestRequest{Filename: "leave.txt", Content: []byte("有給休暇"), DuplicatePolicy: "link"}).
+			Return(&services.IngestResult{Document: &domain.Document{ID: 3, LogicalID: 3, Version: 1}}, nil)
+		mockIngestService.EXPECT().Ingest(gomock.Any(), gomock.Any()).Return(nil, services.ErrUnsupportedFileType)
+
+		code := run(ctx, a, []string{"-o", "json", "ingest", "-on-duplicate", "link", dir})
+
+		assert.Equal(t, 0, code)
+		var items []ingestItem
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &items))
+		require.Len(t, items, 2)
+		assert.Equal(t, ingestCreated, items[0].Status)
+		assert.Equal(t, int64(3), items[0].Result.Document.ID)
+		assert.Equal(t, ingestSkipped, items[1].Status)
+	})
+
+	t.Run("正常系: S3のプレフィックスのオブジェクトを取り込む", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockIngestService := servicemocks.NewMockIngestServiceInterface(ctrl)
+		mockS3Client := awsmock.NewMockS3ClientInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.ingestService = mockIngestService
+		a.s3Client = mockS3Client
+
+		mockS3Client.EXPECT().ListObjects(gomock.Any(), "inbox/").Return([]aws.S3Object{{Key: "inbox/manual.md"}}, nil)
+		mockS3Client.EXPECT().DownloadFileContent(gomock.Any(), "inbox/manual.md").Return([]byte("# 手順"), nil)
+		mockIngestService.EXPECT().Ingest(gomock.Any(), services.IngestRequest{Filename: "manual.md", Content: []byte("# 手順")}).
+			Return(&services.IngestResult{Document: &domain.Document{ID: 9, LogicalID: 1, Version: 2}, Linked: true}, nil)
+
+		code := run(ctx, a, []string{"ingest", "s3://docs-bucket/inbox/"})
+
+		assert.Equal(t, 0, code)
+		assert.Contains(t, stdout.String(), "linked   s3://docs-bucket/inbox/manual.md -> id=9 logical_id=1 version=2")
+		assert.Contains(t, stdout.String(), "既存: 1")
+	})
+
+	t.Run("異常系: 失敗したファイルがある場合は終了コード1", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "leave.txt")
+		require.NoError(t, os.WriteFile(file, []byte("有給休暇"), 0o644))
+
+		ctrl := gomock.NewController(t)
+		mockIngestService := servicemocks.NewMockIngestServiceInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.ingestService = mockIngestService
+
+		mockIngestService.EXPECT().Ingest(gomock.Any(), gomock.Any()).Return(nil, &services.DuplicateDocumentError{Existing: services.DuplicateMatch{DocumentID: 3}})
+
+		code := run(ctx, a, []string{"ingest", file})
+
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stdout.String(), "failed")
+	})
+
+	t.Run("異常系: 設定と異なるバケット", func(t *testing.T) {
+		a, _, _ := newTestApp()
+
+		assert.Equal(t, 2, run(ctx, a, []string{"ingest", "s3://other-bucket/inbox/"}))
+	})
+
+	t.Run("異常系: -version-of は複数のファイルに指定できない", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b"), 0o644))
+		a, _, _ := newTestApp()
+
+		assert.Equal(t, 2, run(ctx, a, []string{"ingest", "-version-of", "1", dir}))
+	})
+}
+
+func TestRunQuery(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 回答と参照したドキュメントを表示する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockQAService := servicemocks.NewMockQAServiceInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.qaService = mockQAService
+
+		mockQAService.EXPECT().SimpleRAG(gomock.Any(), "有給休暇の申請期限は？", "claude-3-haiku").Return(&services.QAResult{
+			Answer:             "3日前までです。",
+			RetrievedDocuments: []services.RetrievedDocument{{DocumentID: "doc-1", Content: "申請は3日前まで", Score: 0.9}},
+			Model:              "claude-3-haiku",
+		}, nil)
+
+		code := run(ctx, a, []string{"query", "-model", "claude-3-haiku", "有給休暇の申請期限は？"})
+
+		assert.Equal(t, 0, code)
+		assert.Contains(t, stdout.String(), "3日前までです。")
+		assert.Contains(t, stdout.String(), "[1] doc-1 (score: 0.900) 申請は3日前まで")
+	})
+
+	t.Run("異常系: 質問がない", func(t *testing.T) {
+		a, _, _ := newTestApp()
+
+		assert.Equal(t, 2, run(ctx, a, []string{"query"}))
+	})
+}
+
+func TestRunRecommend(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRecommendService := servicemocks.NewMockRecommendServiceInterface(ctrl)
+	a, stdout, _ := newTestApp()
+	a.recommendService = mockRecommendService
+
+	mockRecommendService.EXPECT().FindSimilarDocuments(gomock.Any(), "有給休暇", 3, domain.ChunkFilter{AllVersions: true}).Return(&services.RecommendResult{
+		RecommendedChunks: []domain.DocumentChunk{{DocumentID: 5, ChunkIndex: 1, Content: "有給休暇の申請", Similarity: 0.25, Citation: "https://git.example.com/hr/handbook/blob/abc123/leave.md#L3-L8"}},
+		Documents:         map[int64]*domain.Document{5: {ID: 5, Filename: "leave.txt", Version: 2}},
+	}, nil)
+
+	code := run(context.Background(), a, []string{"recommend", "-limit", "3", "-all-versions", "有給休暇"})
+
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout.String(), " 1. leave.txt (id=5 version=2) chunk=1 distance=0.2500")
+	assert.Contains(t, stdout.String(), "出典: https://git.example.com/hr/handbook/blob/abc123/leave.md#L3-L8")
+}
+
+func TestRunRecommend_Filter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRecommendService := servicemocks.NewMockRecommendServiceInterface(ctrl)
+	a, stdout, _ := newTestApp()
+	a.recommendService = mockRecommendService
+
+	mockRecommendService.EXPECT().FindSimilarDocuments(gomock.Any(), "有給休暇", 5, domain.ChunkFilter{Category: "policy", Tags: []string{"人事", "休暇"}}).
+		Return(&services.RecommendResult{}, nil)
+
+	code := run(context.Background(), a, []string{"recommend", "-category", "policy", "-tags", "人事,休暇", "有給休暇"})
+
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout.String(), "該当するドキュメントはありません")
+}
+
+func TestRunSummarize(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: テキストを要約する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSummarizeService := servicemocks.NewMockSummarizeServiceInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.summarizeService = mockSummarizeService
+
+		mockSummarizeService.EXPECT().SummarizeText(gomock.Any(), "長い 文章", "").Return(&services.SummarizeResult{Summary: "要約"}, nil)
+
+		code := run(ctx, a, []string{"summarize", "長い", "文章"})
+
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "要約\n", stdout.String())
+	})
+
+	t.Run("正常系: S3のファイルを要約する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSummarizeService := servicemocks.NewMockSummarizeServiceInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.summarizeService = mockSummarizeService
+
+		mockSummarizeService.EXPECT().SummarizeFileByS3Key(gomock.Any(), "documents/pdf/a.pdf", "nova-lite").Return(&services.SummarizeResult{Summary: "要約", Model: "nova-lite"}, nil)
+
+		code := run(ctx, a, []string{"-o", "json", "summarize", "-s3-key", "documents/pdf/a.pdf", "-model", "nova-lite"})
+
+		assert.Equal(t, 0, code)
+		assert.JSONEq(t, `{"summary": "要約", "model": "nova-lite"}`, stdout.String())
+	})
+
+	t.Run("異常系: 要約する対象が複数", func(t *testing.T) {
+		a, _, _ := newTestApp()
+
+		assert.Equal(t, 2, run(ctx, a, []string{"summarize", "-s3-key", "a.pdf", "テキスト"}))
+	})
+}
+
+func TestRunExtract(t *testing.T) {
+	ctx := context.Background()
+	schemaFile := filepath.Join(t.TempDir(), "invoice.schema.json")
+	require.NoError(t, os.WriteFile(schemaFile, []byte(`{"type": "object", "properties": {"total": {"type": "integer"}}}`), 0o644))
+
+	t.Run("正常系: S3のドキュメントから抽出する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockExtractService := servicemocks.NewMockExtractServiceInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.extractService = mockExtractService
+
+		mockExtractService.EXPECT().
+			Extract(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req services.ExtractRequest) (*services.ExtractResult, error) {
+				assert.Equal(t, "documents/pdf/invoice.pdf", req.S3Key)
+				assert.JSONEq(t, `{"type": "object", "properties": {"total": {"type": "integer"}}}`, string(req.Schema))
+				return &services.ExtractResult{
+					Data: json.RawMessage(`{"total":10800}`),
+					Spans: []services.FieldSpan{
+						{Path: "/total", Quote: "10,800円", Found: true, Start: 20, End: 27, StartLine: 3, EndLine: 3},
+					},
+					Model: "claude-3-haiku",
+				}, nil
+			})
+
+		code := run(ctx, a, []string{"extract", "-schema", schemaFile, "-s3-key", "documents/pdf/invoice.pdf"})
+
+		assert.Equal(t, 0, code)
+		assert.Contains(t, stdout.String(), "\"total\": 10800")
+		assert.Contains(t, stdout.String(), `/total: "10,800円" (3-3行目)`)
+		assert.Contains(t, stdout.String(), "モデル: claude-3-haiku")
+	})
+
+	t.Run("異常系: スキーマを指定しない", func(t *testing.T) {
+		a, _, _ := newTestApp()
+
+		assert.Equal(t, 2, run(ctx, a, []string{"extract", "テキスト"}))
+	})
+}
+
+func TestRunDocs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 一覧をJSONで出力する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.documents = mockDBHandler
+
+		mockDBHandler.EXPECT().ListDocuments(gomock.Any(), domain.DocumentListFilter{AfterID: 10, Limit: 2}).
+			Return([]domain.Document{{ID: 11, LogicalID: 11, Version: 1, IsLatest: true, Filename: "leave.txt"}}, nil)
+
+		code := run(ctx, a, []string{"-o", "json", "docs", "list", "-after", "10", "-limit", "2"})
+
+		assert.Equal(t, 0, code)
+		var docs []domain.Document
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &docs))
+		assert.Equal(t, "leave.txt", docs[0].Filename)
+	})
+
+	t.Run("正常系: 分類を編集する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.cfg.Classify.Categories = []string{"contract", "policy"}
+		a.documents = mockDBHandler
+
+		mockDBHandler.EXPECT().UpdateDocumentClassification(gomock.Any(), int64(3), "policy", []string{"人事", "aws"}).Return(nil)
+		mockDBHandler.EXPECT().GetDocumentByID(gomock.Any(), int64(3)).
+			Return(&domain.Document{ID: 3, Filename: "leave.txt", Category: "policy", Tags: []string{"人事", "aws"}}, nil)
+
+		code := run(ctx, a, []string{"docs", "tag", "-category", "policy", "-tags", "人事, AWS", "3"})
+
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "3 leave.txt: policy [人事, aws]\n", stdout.String())
+	})
+
+	t.Run("異常系: タクソノミーにないカテゴリ", func(t *testing.T) {
+		a, _, stderr := newTestApp()
+		a.cfg.Classify.Categories = []string{"contract"}
+		a.documents = domainmocks.NewMockDBHandlerInterface(gomock.NewController(t))
+
+		code := run(ctx, a, []string{"docs", "tag", "-category", "memo", "3"})
+
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr.String(), "タクソノミーに定義されていないカテゴリです")
+	})
+
+	t.Run("異常系: 削除できなかったドキュメントがある場合は終了コード1", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.documents = mockDBHandler
+
+		mockDBHandler.EXPECT().DeleteDocument(gomock.Any(), int64(3)).Return(nil)
+		mockDBHandler.EXPECT().DeleteDocument(gomock.Any(), int64(4)).Return(domain.ErrDocumentNotFound)
+
+		code := run(ctx, a, []string{"docs", "delete", "3", "4"})
+
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stdout.String(), "deleted  3")
+		assert.Contains(t, stdout.String(), "failed   4")
+	})
+
+	t.Run("異常系: 不正なID", func(t *testing.T) {
+		a, _, _ := newTestApp()
+
+		assert.Equal(t, 2, run(ctx, a, []string{"docs", "delete", "abc"}))
+	})
+}
+
+func TestRunReindex(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 切り替えて状態を表示する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockReindexService := servicemocks.NewMockReindexServiceInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.reindexService = mockReindexService
+
+		mockReindexService.EXPECT().Activate(gomock.Any()).Return(nil)
+		mockReindexService.EXPECT().Status(gomock.Any()).Return(&services.ReindexStatus{
+			Active:   domain.EmbeddingSpace{Model: "titan-v2-1024", Dimension: 1024},
+			Previous: &domain.EmbeddingSpace{Model: "titan-v1", Dimension: 1536},
+		}, nil)
+
+		code := run(ctx, a, []string{"reindex", "activate"})
+
+		assert.Equal(t, 0, code)
+		assert.Contains(t, stdout.String(), "検索に使用しているモデル: titan-v2-1024 (1024次元)")
+		assert.Contains(t, stdout.String(), "ロールバック可能なモデル: titan-v1")
+	})
+
+	t.Run("正常系: 開始したジョブの生成が終わるまで待つ", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockReindexService := servicemocks.NewMockReindexServiceInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.reindexService = mockReindexService
+		defer func(interval time.Duration) { reindexPollInterval = interval }(reindexPollInterval)
+		reindexPollInterval = time.Millisecond
+
+		running := &services.ReindexStatus{Job: &domain.ReindexJob{ID: 1, TargetModel: "titan-v2-1024", Status: domain.ReindexStatusRunning}, Progress: 50}
+		ready := &services.ReindexStatus{Job: &domain.ReindexJob{ID: 1, TargetModel: "titan-v2-1024", Status: domain.ReindexStatusReady}, Progress: 100}
+		mockReindexService.EXPECT().Start(gomock.Any(), "titan-v2-1024").Return(running.Job, nil)
+		gomock.InOrder(
+			mockReindexService.EXPECT().Status(gomock.Any()).Return(running, nil),
+			mockReindexService.EXPECT().Status(gomock.Any()).Return(ready, nil).Times(2),
+		)
+
+		code := run(ctx, a, []string{"reindex", "start", "titan-v2-1024"})
+
+		assert.Equal(t, 0, code)
+		assert.Contains(t, stdout.String(), "状態: ready 進捗: 100.0%")
+	})
+
+	t.Run("異常系: 不明なサブコマンド", func(t *testing.T) {
+		a, _, _ := newTestApp()
+
+		assert.Equal(t, 2, run(ctx, a, []string{"reindex", "pause"}))
+	})
+}
+
+func TestRunSync(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 同期して処理件数を表示する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSyncService := servicemocks.NewMockS3SyncServiceInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.syncService = mockSyncService
+
+		mockSyncService.EXPECT().Run(gomock.Any()).Return(&domain.SyncState{
+			Source: "s3://docs-bucket/inbox/",
+			Stats:  domain.SyncStats{Created: 2, Deleted: 1, Unchanged: 5},
+		}, nil)
+
+		code := run(ctx, a, []string{"sync", "run"})
+
+		assert.Equal(t, 0, code)
+		assert.Contains(t, stdout.String(), "状態: 待機中")
+		assert.Contains(t, stdout.String(), "新規: 2, 更新: 0, 削除: 1, 変更なし: 5, スキップ: 0, 失敗: 0")
+	})
+
+	t.Run("正常系: 中断した同期の状態を表示する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSyncService := servicemocks.NewMockS3SyncServiceInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.syncService = mockSyncService
+
+		mockSyncService.EXPECT().Status(gomock.Any()).Return(&domain.SyncState{
+			Source: "s3://docs-bucket/inbox/", Checkpoint: "inbox/b.txt", LastError: "access denied",
+		}, nil)
+
+		code := run(ctx, a, []string{"sync", "status"})
+
+		assert.Equal(t, 0, code)
+		assert.Contains(t, stdout.String(), "状態: 中断 (inbox/b.txt まで処理済み。次回の同期で再開します)")
+		assert.Contains(t, stdout.String(), "エラー: access denied")
+	})
+
+	t.Run("異常系: 同期を実行中", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSyncService := servicemocks.NewMockS3SyncServiceInterface(ctrl)
+		a, _, stderr := newTestApp()
+		a.syncService = mockSyncService
+
+		mockSyncService.EXPECT().Run(gomock.Any()).Return(nil, services.ErrSyncInProgress)
+
+		code := run(ctx, a, []string{"sync", "run"})
+
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr.String(), "同期を実行中です")
+	})
+}
+
+func TestRunCrawl(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: クロールして処理件数を表示する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockCrawlService := servicemocks.NewMockWebCrawlServiceInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.crawlService = mockCrawlService
+
+		mockCrawlService.EXPECT().Run(gomock.Any()).Return(&domain.SyncState{
+			Source: "web:wiki.example.com",
+			Stats:  domain.SyncStats{Created: 3, Unchanged: 10, Skipped: 1},
+		}, nil)
+
+		code := run(ctx, a, []string{"crawl", "run"})
+
+		assert.Equal(t, 0, code)
+		assert.Contains(t, stdout.String(), "取り込み元: web:wiki.example.com")
+		assert.Contains(t, stdout.String(), "新規: 3, 更新: 0, 削除: 0, 変更なし: 10, スキップ: 1, 失敗: 0")
+	})
+
+	t.Run("異常系: 不明なサブコマンド", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		a, _, _ := newTestApp()
+		a.crawlService = servicemocks.NewMockWebCrawlServiceInterface(ctrl)
+
+		code := run(ctx, a, []string{"crawl", "start"})
+
+		assert.Equal(t, 2, code)
+	})
+}
+
+func TestRunGitSync(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 同期して処理件数と同期したコミットを表示する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockGitSyncService := servicemocks.NewMockGitSyncServiceInterface(ctrl)
+		a, stdout, _ := newTestApp()
+		a.gitSyncService = mockGitSyncService
+
+		mockGitSyncService.EXPECT().Run(gomock.Any()).Return(&domain.SyncState{
+			Source: "git+https://git.example.com/hr/handbook.git#main:",
+			Cursor: "abc123 0f1e2d",
+			Stats:  domain.SyncStats{Updated: 2, Deleted: 1},
+		}, nil)
+
+		code := run(ctx, a, []string{"gitsync", "run"})
+
+		assert.Equal(t, 0, code)
+		assert.Contains(t, stdout.String(), "取り込み元: git+https://git.example.com/hr/handbook.git#main:")
+		assert.Contains(t, stdout.String(), "新規: 0, 更新: 2, 削除: 1, 変更なし: 0, スキップ: 0, 失敗: 0")
+		assert.Contains(t, stdout.String(), "同期した版: abc123 0f1e2d")
+	})
+
+	t.Run("異常系: 不明なサブコマンド", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		a, _, _ := newTestApp()
+		a.gitSyncService = servicemocks.NewMockGitSyncServiceInterface(ctrl)
+
+		code := run(ctx, a, []string{"gitsync", "start"})
+
+		assert.Equal(t, 2, code)
+	})
+}
+
+func TestRunEval(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: オフラインのフェイクで評価し、レポートを書き出す", func(t *testing.T) {
+		out := t.TempDir()
+		a, stdout, _ := newTestApp()
+
+		code := run(ctx, a, []string{"eval",
+			"-offline",
+			"-golden", "../../internal/eval/testdata/golden.jsonl",
+			"-corpus", "../../internal/eval/testdata/corpus.jsonl",
+			"-label", "baseline",
+			"-json", filepath.Join(out, "report.json"),
+		})
+
+		assert.Equal(t, 0, code)
+		assert.Contains(t, stdout.String(), "# RAG評価レポート: baseline")
+		data, err := os.ReadFile(filepath.Join(out, "report.json"))
+		require.NoError(t, err)
+		assert.True(t, json.Valid(data))
+	})
+
+	t.Run("異常系: -offline には -corpus が必要", func(t *testing.T) {
+		a, _, _ := newTestApp()
+
+		assert.Equal(t, 2, run(ctx, a, []string{"eval", "-offline", "-golden", "golden.jsonl"}))
+	})
+}
+
+func TestParseS3URI(t *testing.T) {
+	bucket, prefix, err := parseS3URI("s3://docs-bucket/inbox/hr/")
+	require.NoError(t, err)
+	assert.Equal(t, "docs-bucket", bucket)
+	assert.Equal(t, "inbox/hr/", prefix)
+
+	_, _, err = parseS3URI("docs-bucket/inbox")
+	assert.ErrorIs(t, err, errUsage)
+}
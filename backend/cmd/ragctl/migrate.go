@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// runMigrate はデータベースのスキーマのマイグレーションを適用する (適用済みのものは読み飛ばす)
+func runMigrate(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "migrate")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usageError("migrate は引数を取りません")
+	}
+
+	migrator, err := a.migrations()
+	if err != nil {
+		return err
+	}
+	if err := migrator.Migrate(ctx); err != nil {
+		return fmt.Errorf("マイグレーションに失敗しました: %w", err)
+	}
+	return a.print(map[string]string{"status": "ok"}, func(w io.Writer) {
+		fmt.Fprintln(w, "マイグレーションを適用しました")
+	})
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// 出力形式
+const (
+	outputText = "text" // 人が読む形式
+	outputJSON = "json" // スクリプトで処理する形式 (HTTPのレスポンスと同じJSON)
+)
+
+// snippetLength はテキスト出力で表示する本文の最大文字数
+const snippetLength = 80
+
+// print は結果を出力形式に従って書き出す (text の場合は printText で整形する)
+func (a *app) print(v any, printText func(w io.Writer)) error {
+	if a.format == outputJSON {
+		encoder := json.NewEncoder(a.stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("結果の書き出しに失敗しました: %w", err)
+		}
+		return nil
+	}
+	printText(a.stdout)
+	return nil
+}
+
+// snippet は改行を詰めたテキストの先頭を表示用に返す
+func snippet(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= snippetLength {
+		return text
+	}
+	return string([]rune(text)[:snippetLength]) + "…"
+}
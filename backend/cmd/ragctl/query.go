@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/internal/services"
+)
+
+// runQuery は質問に回答する
+func runQuery(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "query")
+	model := flags.String("model", "", "回答の生成に使用するモデルのカタログ名 (省略時はQAの既定モデル)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	question := strings.Join(flags.Args(), " ")
+	if question == "" {
+		return usageError("質問を指定してください")
+	}
+
+	qaService, err := a.qa()
+	if err != nil {
+		return err
+	}
+	result, err := qaService.SimpleRAG(ctx, question, *model)
+	if err != nil {
+		return err
+	}
+
+	return a.print(result, func(w io.Writer) {
+		fmt.Fprintln(w, result.Answer)
+		if len(result.RetrievedDocuments) > 0 {
+			fmt.Fprintln(w, "\n参照したドキュメント:")
+			for i, doc := range result.RetrievedDocuments {
+				fmt.Fprintf(w, "  [%d] %s (score: %.3f) %s\n", i+1, doc.DocumentID, doc.Score, snippet(doc.Content))
+			}
+		}
+		if result.Model != "" {
+			fmt.Fprintf(w, "\nモデル: %s\n", result.Model)
+		}
+		if result.Cached {
+			fmt.Fprintln(w, "(キャッシュした回答)")
+		}
+	})
+}
+
+// runRecommend はクエリに類似したドキュメントを検索する
+func runRecommend(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "recommend")
+	limit := flags.Int("limit", 5, "取得するチャンクの最大件数")
+	allVersions := flags.Bool("all-versions", false, "最新以外の版も検索する")
+	category := flags.String("category", "", "このカテゴリのドキュメントのみを検索する")
+	tags := flags.String("tags", "", "これらのタグ (カンマ区切り) がすべて付いたドキュメントのみを検索する")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	query := strings.Join(flags.Args(), " ")
+	if query == "" {
+		return usageError("検索するクエリを指定してください")
+	}
+
+	recommendService, err := a.recommend()
+	if err != nil {
+		return err
+	}
+	result, err := recommendService.FindSimilarDocuments(ctx, query, *limit, domain.ChunkFilter{AllVersions: *allVersions, Category: *category, Tags: splitTags(*tags)})
+	if err != nil {
+		return err
+	}
+
+	return a.print(result, func(w io.Writer) {
+		if len(result.RecommendedChunks) == 0 {
+			fmt.Fprintln(w, "該当するドキュメントはありません")
+			return
+		}
+		for i, chunk := range result.RecommendedChunks {
+			name := fmt.Sprintf("document %d", chunk.DocumentID)
+			if doc := result.Documents[chunk.DocumentID]; doc != nil {
+				name = fmt.Sprintf("%s (id=%d version=%d)", doc.Filename, doc.ID, doc.Version)
+			}
+			fmt.Fprintf(w, "%2d. %s chunk=%d distance=%.4f\n    %s\n", i+1, name, chunk.ChunkIndex, chunk.Similarity, snippet(chunk.Content))
+			if chunk.Citation != "" {
+				fmt.Fprintf(w, "    出典: %s\n", chunk.Citation)
+			}
+		}
+	})
+}
+
+// runSummarize はテキスト・ローカルのファイル・S3のファイルを要約する
+func runSummarize(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "summarize")
+	model := flags.String("model", "", "要約に使用するモデルのカタログ名 (省略時は要約の既定モデル)")
+	file := flags.String("file", "", "要約するローカルのファイル")
+	s3Key := flags.String("s3-key", "", "要約するS3のファイルのキー")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	text := strings.Join(flags.Args(), " ")
+
+	sources := 0
+	for _, given := range []bool{*file != "", *s3Key != "", text != ""} {
+		if given {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return usageError("-file、-s3-key、テキストのいずれか1つを指定してください")
+	}
+	if *file != "" && *model != "" {
+		return usageError("-file の要約では -model を指定できません (要約の既定モデルを使用します)")
+	}
+
+	summarizeService, err := a.summarize()
+	if err != nil {
+		return err
+	}
+
+	var result *services.SummarizeResult
+	switch {
+	case *file != "":
+		f, err := os.Open(*file)
+		if err != nil {
+			return fmt.Errorf("ファイルを開けません: %w", err)
+		}
+		defer f.Close()
+		result, err = summarizeService.SummarizeFile(ctx, f, filepath.Base(*file))
+		if err != nil {
+			return err
+		}
+	case *s3Key != "":
+		result, err = summarizeService.SummarizeFileByS3Key(ctx, *s3Key, *model)
+		if err != nil {
+			return err
+		}
+	default:
+		result, err = summarizeService.SummarizeText(ctx, text, *model)
+		if err != nil {
+			return err
+		}
+	}
+
+	return a.print(result, func(w io.Writer) {
+		fmt.Fprintln(w, result.Summary)
+		if result.Model != "" {
+			fmt.Fprintf(w, "\nモデル: %s\n", result.Model)
+		}
+	})
+}
+
+// runExtract はテキスト・ローカルのテキストファイル・S3のドキュメントから、JSON Schemaに従う構造化データを抽出する
+func runExtract(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "extract")
+	schemaFile := flags.String("schema", "", "抽出する項目のJSON Schemaのファイル")
+	model := flags.String("model", "", "抽出に使用するモデルのカタログ名 (省略時は抽出の既定モデル)")
+	file := flags.String("file", "", "抽出するローカルのテキストファイル")
+	s3Key := flags.String("s3-key", "", "抽出するS3のドキュメント (PDF・画像) のキー")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	text := strings.Join(flags.Args(), " ")
+
+	if *schemaFile == "" {
+		return usageError("-schema を指定してください")
+	}
+	sources := 0
+	for _, given := range []bool{*file != "", *s3Key != "", text != ""} {
+		if given {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return usageError("-file、-s3-key、テキストのいずれか1つを指定してください")
+	}
+
+	schema, err := os.ReadFile(*schemaFile)
+	if err != nil {
+		return fmt.Errorf("JSON Schemaのファイルを読み込めません: %w", err)
+	}
+	if *file != "" {
+		content, err := os.ReadFile(*file)
+		if err != nil {
+			return fmt.Errorf("ファイルを読み込めません: %w", err)
+		}
+		text = string(content)
+	}
+
+	extractService, err := a.extract()
+	if err != nil {
+		return err
+	}
+	result, err := extractService.Extract(ctx, services.ExtractRequest{Text: text, S3Key: *s3Key, Schema: schema, Model: *model})
+	if err != nil {
+		return err
+	}
+
+	return a.print(result, func(w io.Writer) {
+		var data bytes.Buffer
+		if err := json.Indent(&data, result.Data, "", "  "); err != nil {
+			data.Reset()
+			data.Write(result.Data)
+		}
+		fmt.Fprintln(w, data.String())
+		if len(result.Spans) > 0 {
+			fmt.Fprintln(w, "\n根拠:")
+			for _, span := range result.Spans {
+				if !span.Found {
+					fmt.Fprintf(w, "  %s: %q (本文に見つかりません)\n", span.Path, span.Quote)
+					continue
+				}
+				fmt.Fprintf(w, "  %s: %q (%d-%d行目)\n", span.Path, span.Quote, span.StartLine, span.EndLine)
+			}
+		}
+		if result.Model != "" {
+			fmt.Fprintf(w, "\nモデル: %s\n", result.Model)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/internal/services"
+)
+
+// reindexPollInterval は再インデックスの進捗を確認する間隔
+var reindexPollInterval = 2 * time.Second
+
+// runReindex はEmbeddingモデルの切り替え (再インデックス) を操作する
+func runReindex(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 {
+		return usageError("reindex のサブコマンドを指定してください")
+	}
+	op, args := args[0], args[1:]
+
+	var transition func(services.ReindexServiceInterface, context.Context) error
+	switch op {
+	case "start":
+		return runReindexStart(ctx, a, args)
+	case "status":
+	case "activate":
+		transition = services.ReindexServiceInterface.Activate
+	case "rollback":
+		transition = services.ReindexServiceInterface.Rollback
+	case "finalize":
+		transition = services.ReindexServiceInterface.Finalize
+	case "cancel":
+		transition = services.ReindexServiceInterface.Cancel
+	default:
+		return usageError("不明なサブコマンドです: reindex %s", op)
+	}
+	if len(args) > 0 {
+		return usageError("reindex %s は引数を取りません", op)
+	}
+
+	reindexService, err := a.reindex()
+	if err != nil {
+		return err
+	}
+	if transition != nil {
+		if err := transition(reindexService, ctx); err != nil {
+			return err
+		}
+	}
+	return a.printReindexStatus(ctx, reindexService)
+}
+
+// runReindexStart は再インデックスを開始し、切り替え先のEmbeddingの生成が終わるまで待つ
+// 途中で中断した場合も、ジョブはサーバーの起動時 (または再度の start) に続きから再開される
+func runReindexStart(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return usageError("切り替え先のEmbeddingモデルを指定してください")
+	}
+
+	reindexService, err := a.reindex()
+	if err != nil {
+		return err
+	}
+	job, err := reindexService.Start(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stderr, "再インデックスを開始しました (job: %d, model: %s)\n", job.ID, job.TargetModel)
+
+	ticker := time.NewTicker(reindexPollInterval)
+	defer ticker.Stop()
+	for {
+		status, err := reindexService.Status(ctx)
+		if err != nil {
+			return err
+		}
+		if status.Job == nil || status.Job.Status != domain.ReindexStatusRunning {
+			break
+		}
+		fmt.Fprintf(a.stderr, "生成中: %.1f%% (%d チャンク)\n", status.Progress, status.Job.Processed)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("再インデックスの待機を中断しました (ジョブは再開できます): %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return a.printReindexStatus(ctx, reindexService)
+}
+
+// printReindexStatus は再インデックスの状態を表示する
+func (a *app) printReindexStatus(ctx context.Context, reindexService services.ReindexServiceInterface) error {
+	status, err := reindexService.Status(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(status, func(w io.Writer) {
+		fmt.Fprintf(w, "検索に使用しているモデル: %s (%d次元)\n", status.Active.Model, status.Active.Dimension)
+		if status.Previous != nil {
+			fmt.Fprintf(w, "ロールバック可能なモデル: %s (%d次元)\n", status.Previous.Model, status.Previous.Dimension)
+		}
+		if status.Job == nil {
+			fmt.Fprintln(w, "実行中の再インデックスはありません")
+			return
+		}
+		fmt.Fprintf(w, "ジョブ: %d (%s -> %s) 状態: %s 進捗: %.1f%%\n", status.Job.ID, status.Active.Model, status.Job.TargetModel, status.Job.Status, status.Progress)
+		if status.Job.Error != "" {
+			fmt.Fprintf(w, "エラー: %s\n", status.Job.Error)
+		}
+	})
+}
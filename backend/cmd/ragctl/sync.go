@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"bedrock-rag-sample/backend/internal/domain"
+)
+
+// runSync はS3のプレフィックスに置かれたドキュメントを同期する
+// run は同期が完了するまで待ち、中断した場合は次回の run (またはサーバーの定期的な同期) がチェックポイントから再開する
+func runSync(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return usageError("sync run または sync status を指定してください")
+	}
+	syncService, err := a.s3Sync()
+	if err != nil {
+		return err
+	}
+
+	var state *domain.SyncState
+	switch args[0] {
+	case "run":
+		state, err = syncService.Run(ctx)
+	case "status":
+		state, err = syncService.Status(ctx)
+	default:
+		return usageError("不明なサブコマンドです: sync %s", args[0])
+	}
+	if err != nil {
+		return err
+	}
+	return a.printSyncState(state)
+}
+
+// runCrawl はWebページをクロールして取り込む (CRAWL_SEEDS・CRAWL_SITEMAPS)
+// run はクロールが完了するまで待つ。中断した場合は次回の run (またはサーバーの定期的なクロール) が最初からクロールし直す
+func runCrawl(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return usageError("crawl run または crawl status を指定してください")
+	}
+	crawlService, err := a.webCrawl()
+	if err != nil {
+		return err
+	}
+
+	var state *domain.SyncState
+	switch args[0] {
+	case "run":
+		state, err = crawlService.Run(ctx)
+	case "status":
+		state, err = crawlService.Status(ctx)
+	default:
+		return usageError("不明なサブコマンドです: crawl %s", args[0])
+	}
+	if err != nil {
+		return err
+	}
+	return a.printSyncState(state)
+}
+
+// runGitSync はgitリポジトリのドキュメントを同期する (GIT_REPO・GIT_REF)
+// run は前回同期したコミットから最新のコミットまでに変更されたファイルを取り込み、完了するまで待つ
+func runGitSync(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return usageError("gitsync run または gitsync status を指定してください")
+	}
+	gitSyncService, err := a.gitSync()
+	if err != nil {
+		return err
+	}
+
+	var state *domain.SyncState
+	switch args[0] {
+	case "run":
+		state, err = gitSyncService.Run(ctx)
+	case "status":
+		state, err = gitSyncService.Status(ctx)
+	default:
+		return usageError("不明なサブコマンドです: gitsync %s", args[0])
+	}
+	if err != nil {
+		return err
+	}
+	return a.printSyncState(state)
+}
+
+// printSyncState は同期 (クロール) の状態と処理件数を表示する
+func (a *app) printSyncState(state *domain.SyncState) error {
+	return a.print(state, func(w io.Writer) {
+		fmt.Fprintf(w, "取り込み元: %s\n", state.Source)
+		switch {
+		case state.Running:
+			fmt.Fprintf(w, "状態: 実行中 (%s まで処理済み)\n", state.Checkpoint)
+		case state.Checkpoint != "":
+			fmt.Fprintf(w, "状態: 中断 (%s まで処理済み。次回の同期で再開します)\n", state.Checkpoint)
+		default:
+			fmt.Fprintln(w, "状態: 待機中")
+		}
+		stats := state.Stats
+		fmt.Fprintf(w, "新規: %d, 更新: %d, 削除: %d, 変更なし: %d, スキップ: %d, 失敗: %d\n",
+			stats.Created, stats.Updated, stats.Deleted, stats.Unchanged, stats.Skipped, stats.Failed)
+		if state.LastCompletedAt != nil {
+			fmt.Fprintf(w, "最後に完了した同期: %s\n", state.LastCompletedAt.Format("2006-01-02 15:04:05"))
+		}
+		if state.Cursor != "" {
+			fmt.Fprintf(w, "同期した版: %s\n", state.Cursor)
+		}
+		if state.LastError != "" {
+			fmt.Fprintf(w, "エラー: %s\n", state.LastError)
+		}
+	})
+}
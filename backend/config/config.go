@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // AWSConfig はAWS関連の設定を保持する構造体
@@ -9,8 +12,29 @@ type AWSConfig struct {
 	Region          string
 	S3BucketName    string
 	S3DocumentsPath string
-	BedrockModelID  string
+	BedrockModelID  string // テキスト生成の既定モデル (カタログ名またはBedrockのモデルID)
 	KnowledgeBaseID string
+
+	// テキスト生成モデルの選択
+	TextModelAllowList []string // リクエストで指定できるモデルのカタログ名 (空の場合はカタログの全モデル)
+	SummarizeModel     string   // 要約エンドポイントの既定モデル (空の場合は BedrockModelID)
+	QAModel            string   // QAエンドポイントの既定モデル (空の場合は BedrockModelID)
+	ExtractModel       string   // 構造化データ抽出エンドポイントの既定モデル (空の場合は BedrockModelID)
+
+	// Embedding生成
+	EmbeddingModel       string // Embeddingモデルのレジストリ名 (例: "titan-v2-1024")
+	EmbeddingNormalize   bool   // EmbeddingをL2正規化するかどうか
+	EmbeddingConcurrency int    // 複数テキストのEmbeddingを並行生成する際のワーカー数
+	EmbeddingBatchSize   int    // バッチ入力に対応したモデルで1リクエストにまとめるテキスト数
+
+	// InvokeModel の同時実行数制御 (モデルごと)
+	BedrockMaxConcurrency   int            // モデルごとの同時実行数の上限 (0以下で無制限)
+	BedrockModelConcurrency map[string]int // モデルIDごとの上限の上書き
+	BedrockConcurrencyWait  time.Duration  // 実行枠が空くまで待機する最大時間
+
+	// Bedrock Guardrails (テキスト生成・RetrieveAndGenerate に適用する)
+	GuardrailID      string // ガードレールのIDまたはARN (空の場合は適用しない)
+	GuardrailVersion string // ガードレールのバージョン (例: "1"、"DRAFT")
 }
 
 // DBConfig はデータベース関連の設定を保持する構造体
@@ -21,23 +45,214 @@ type DBConfig struct {
 	Password string
 	Name     string
 	SSLMode  string
+
+	AutoMigrate bool // 起動時にスキーマのマイグレーションを適用するかどうか
+}
+
+// ServerConfig はHTTPサーバー関連の設定を保持する構造体
+type ServerConfig struct {
+	Address           string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration // SIGTERM受信後、処理中のリクエストとバックグラウンドタスクを待つ猶予
+
+	// X-Forwarded-For を付与するプロキシ (ALBなど) のCIDR
+	// 空の場合はクライアントが偽装できる X-Forwarded-For / X-Real-IP を使わず、接続元のIPアドレスを送信元とする
+	TrustedProxies []string
+}
+
+// RateLimitConfig はクライアントごとのレート制限の設定を保持する構造体
+type RateLimitConfig struct {
+	Enabled           bool
+	RequestsPerSecond float64       // トークンの補充レート
+	Burst             int           // バケットの容量 (瞬間的に許容するリクエスト数)
+	IdleTTL           time.Duration // この期間リクエストがないクライアントのバケットを破棄する
+}
+
+// ResilienceConfig はAWS呼び出しの再試行とサーキットブレーカーの設定を保持する構造体
+type ResilienceConfig struct {
+	MaxAttempts             int           // 最大試行回数 (初回を含む)
+	BaseDelay               time.Duration // 指数バックオフの基準待機時間
+	MaxDelay                time.Duration // 待機時間の上限
+	AttemptTimeout          time.Duration // 1回の呼び出しのタイムアウト
+	BreakerFailureThreshold int           // この回数連続で失敗すると遮断する (0以下で無効)
+	BreakerOpenTimeout      time.Duration // 遮断してから試験的に再開するまでの時間
+}
+
+// HealthConfig はヘルスチェック関連の設定を保持する構造体
+type HealthConfig struct {
+	CheckTimeout    time.Duration // 依存先1件あたりのチェックタイムアウト
+	BedrockCacheTTL time.Duration // Bedrockの疎通確認結果をキャッシュする期間
+}
+
+// ModelPrice はモデルの1,000トークンあたりの料金 (USD)
+type ModelPrice struct {
+	InputPer1K  float64 `json:"input_per_1k"`
+	OutputPer1K float64 `json:"output_per_1k"`
+}
+
+// UsageConfig はトークン使用量と料金の記録に関する設定を保持する構造体
+type UsageConfig struct {
+	Enabled bool                  // モデル呼び出しごとの使用量をDBに記録するかどうか
+	Prices  map[string]ModelPrice // モデルのカタログ名 (またはモデルID) ごとの料金の上書き
+}
+
+// TenantBudget はテナントごとの利用上限 (0は無制限)
+type TenantBudget struct {
+	MonthlyTokens  int64   // 当月 (UTC) の入出力トークン数の合計
+	MonthlyCostUSD float64 // 当月 (UTC) の料金の合計
+	DailyRequests  int     // 当日 (UTC) のモデルを呼び出したAPIリクエスト数
+}
+
+// BudgetConfig はテナントごとの予算・クォータの設定を保持する構造体
+// テナントは認証していないヘッダー (X-Tenant-ID / X-API-Key) で決まるため、上限は目安であり不正な利用を防ぐものではない
+type BudgetConfig struct {
+	Enabled         bool
+	Default         TenantBudget            // 個別の設定がないテナントの上限
+	Tenants         map[string]TenantBudget // テナントごとの上限 (0の項目は Default を使う)
+	SoftLimitRatio  float64                 // 上限に対してこの割合を超えるとレスポンスヘッダーで警告する
+	RefreshInterval time.Duration           // 使用量をDBから読み直す間隔 (複数タスク間の同期)
+}
+
+// AnswerCacheConfig はQAの回答キャッシュの設定を保持する構造体
+type AnswerCacheConfig struct {
+	Enabled             bool
+	SimilarityThreshold float64       // キャッシュを利用するクエリEmbeddingのコサイン類似度の下限
+	TTL                 time.Duration // キャッシュした回答の有効期間
+}
+
+// EmbeddingCacheConfig は同じテキストのEmbeddingを再利用するキャッシュの設定を保持する構造体
+type EmbeddingCacheConfig struct {
+	Enabled       bool
+	MemoryEntries int // DBの手前に置くメモリ上のキャッシュ (LRU) の件数
+}
+
+// IngestConfig はドキュメントの取り込みに関する設定を保持する構造体
+type IngestConfig struct {
+	DuplicatePolicy          string // 重複したドキュメントの既定の扱い (reject / link / version)
+	NearDuplicateMaxDistance int    // 類似した重複とみなすSimHashのハミング距離の上限 (負の値で検出しない)
+}
+
+// SyncConfig はバケットに直接置かれたドキュメントの同期に関する設定を保持する構造体
+type SyncConfig struct {
+	Enabled   bool
+	Prefix    string        // 同期するS3のプレフィックス (取り込んだファイルの保存先 S3_DOCUMENTS_PATH と重ならないこと)
+	Interval  time.Duration // 定期的に同期する間隔 (0以下の場合はAPIからの指示でのみ同期する)
+	BatchSize int           // 1回に一覧するオブジェクト数 (チェックポイントの記録単位。ディレクトリの監視と共通)
+}
+
+// WatchConfig はローカルのディレクトリを監視して取り込む設定を保持する構造体
+type WatchConfig struct {
+	Dir          string        // 監視するディレクトリ (空の場合は監視しない)
+	Include      []string      // 取り込むファイルのグロブ (空の場合は取り込めるすべてのファイル)
+	Exclude      []string      // 除外するファイル・ディレクトリのグロブ
+	Debounce     time.Duration // 最後の変更からこの時間が経過してから同期する (連続した書き込みを1回の同期にまとめる)
+	PollInterval time.Duration // inotify を使えない場合にディレクトリを走査する間隔
+}
+
+// CrawlConfig はWebページをクロールして取り込む設定を保持する構造体
+type CrawlConfig struct {
+	Seeds          []string      // クロールを始めるページのURL
+	Sitemaps       []string      // ページのURLを列挙するサイトマップ (sitemap.xml) のURL
+	AllowedDomains []string      // クロールするドメイン (サブドメインを含む。空の場合は Seeds と Sitemaps のホスト)
+	MaxDepth       int           // Seeds・サイトマップのページからたどるリンクの深さの上限
+	MaxPages       int           // 1回のクロールで取得するページ数の上限
+	Interval       time.Duration // 定期的にクロールする間隔 (0以下の場合はCLIからの指示でのみクロールする)
+	Delay          time.Duration // 同じホストへのリクエストの最小の間隔 (robots.txt の Crawl-delay の方が長ければそちらを使う)
+	Timeout        time.Duration // 1回のリクエストのタイムアウト
+	UserAgent      string        // リクエストの User-Agent (robots.txt の規則の判定にも使う)
+}
+
+// GitConfig はgitリポジトリのドキュメントを取り込む設定を保持する構造体
+type GitConfig struct {
+	Repo     string        // 取り込むリポジトリ (リモートのURLまたはローカルのパス。空の場合は取り込まない)
+	Ref      string        // 取り込むブランチ・タグ・コミット
+	CloneDir string        // リモートのリポジトリのミラーを作成するディレクトリ (空の場合は Repo をローカルのリポジトリとして開く)
+	Include  []string      // 取り込むファイルのグロブ
+	Exclude  []string      // 除外するファイル・ディレクトリのグロブ
+	Interval time.Duration // 定期的に同期する間隔 (0以下の場合はCLIからの指示でのみ同期する)
+}
+
+// ClassifyConfig は取り込み時のドキュメントの分類に関する設定を保持する構造体
+type ClassifyConfig struct {
+	Mode         string   // 分類の方式 (llm / centroid。空の場合は分類しない)
+	Categories   []string // タクソノミーのカテゴリ名
+	TaxonomyFile string   // カテゴリの説明と例文を定義したJSONファイル (指定した場合は Categories より優先する)
+	Model        string   // llm 方式で使用するテキスト生成モデル (空の場合は構造化データ抽出の既定モデル)
+	MaxTags      int      // 1つのドキュメントに付けるタグの上限
+	MinScore     float64  // centroid 方式でカテゴリを付ける類似度 (コサイン類似度) の下限
+	SampleChars  int      // 分類に使用するドキュメントの先頭の文字数
+}
+
+// RedactConfig はBedrockに送るテキストの個人情報の除去に関する設定を保持する構造体
+type RedactConfig struct {
+	Enabled        bool              // 個人情報を検出するか
+	DefaultMode    string            // 既定の扱い (off / mask / hash / reject)
+	Modes          map[string]string // エンドポイントごとの扱い (ingest / summarize / qa / extract / recommend)
+	Detectors      []string          // 使用する組み込みの検出器
+	DictionaryFile string            // 氏名・取引先名などを登録した辞書のファイル (空の場合は使用しない)
+	HashKey        string            // hash で使用するHMACの鍵 (監査の記録にも値のハッシュを残す)
 }
 
 // Config はアプリケーション全体の設定を保持する構造体
 type Config struct {
-	AWS AWSConfig
-	DB  DBConfig
+	Server     ServerConfig
+	AWS        AWSConfig
+	DB         DBConfig
+	RateLimit  RateLimitConfig
+	Resilience ResilienceConfig
+	Health     HealthConfig
+	Usage      UsageConfig
+	Budget     BudgetConfig
+	QACache    AnswerCacheConfig
+	EmbedCache EmbeddingCacheConfig
+	Ingest     IngestConfig
+	Sync       SyncConfig
+	Watch      WatchConfig
+	Crawl      CrawlConfig
+	Git        GitConfig
+	Classify   ClassifyConfig
+	Redact     RedactConfig
 }
 
 // NewConfig は新しい設定オブジェクトを作成する
 func NewConfig() *Config {
 	return &Config{
+		Server: ServerConfig{
+			Address:           getEnvOrDefault("SERVER_ADDRESS", ":8080"),
+			ReadTimeout:       getDurationOrDefault("SERVER_READ_TIMEOUT", 30*time.Second),
+			ReadHeaderTimeout: getDurationOrDefault("SERVER_READ_HEADER_TIMEOUT", 10*time.Second),
+			// LLMの応答生成やTextract処理に時間がかかるため長めに設定する
+			WriteTimeout:    getDurationOrDefault("SERVER_WRITE_TIMEOUT", 120*time.Second),
+			IdleTimeout:     getDurationOrDefault("SERVER_IDLE_TIMEOUT", 120*time.Second),
+			ShutdownTimeout: getDurationOrDefault("SERVER_SHUTDOWN_TIMEOUT", 25*time.Second),
+			TrustedProxies:  getListOrDefault("SERVER_TRUSTED_PROXIES", nil),
+		},
 		AWS: AWSConfig{
 			Region:          getEnvOrDefault("AWS_REGION", "us-west-2"),
 			S3BucketName:    getEnvOrDefault("S3_BUCKET_NAME", "bedrock-rag-documents"),
 			S3DocumentsPath: getEnvOrDefault("S3_DOCUMENTS_PATH", "documents/"),
 			BedrockModelID:  getEnvOrDefault("BEDROCK_MODEL_ID", "anthropic.claude-3-haiku-20240307-v1:0"),
 			KnowledgeBaseID: getEnvOrDefault("BEDROCK_KB_ID", ""),
+
+			TextModelAllowList: getListOrDefault("BEDROCK_ALLOWED_MODELS", nil),
+			SummarizeModel:     getEnvOrDefault("BEDROCK_SUMMARIZE_MODEL", ""),
+			QAModel:            getEnvOrDefault("BEDROCK_QA_MODEL", ""),
+			ExtractModel:       getEnvOrDefault("BEDROCK_EXTRACT_MODEL", ""),
+
+			EmbeddingModel:       getEnvOrDefault("BEDROCK_EMBEDDING_MODEL", "titan-v1"),
+			EmbeddingNormalize:   getBoolOrDefault("BEDROCK_EMBEDDING_NORMALIZE", false),
+			EmbeddingConcurrency: getIntOrDefault("BEDROCK_EMBEDDING_CONCURRENCY", 4),
+			EmbeddingBatchSize:   getIntOrDefault("BEDROCK_EMBEDDING_BATCH_SIZE", 96),
+
+			BedrockMaxConcurrency:   getIntOrDefault("BEDROCK_MAX_CONCURRENCY", 8),
+			BedrockModelConcurrency: getIntMapOrDefault("BEDROCK_MODEL_CONCURRENCY", nil),
+			BedrockConcurrencyWait:  getDurationOrDefault("BEDROCK_CONCURRENCY_WAIT", 10*time.Second),
+
+			GuardrailID:      getEnvOrDefault("BEDROCK_GUARDRAIL_ID", ""),
+			GuardrailVersion: getEnvOrDefault("BEDROCK_GUARDRAIL_VERSION", "DRAFT"),
 		},
 		DB: DBConfig{
 			Host:     getEnvOrDefault("DB_HOST", "localhost"),
@@ -46,6 +261,104 @@ func NewConfig() *Config {
 			Password: getEnvOrDefault("DB_PASSWORD", "postgres"),
 			Name:     getEnvOrDefault("DB_NAME", "bedrock_rag"),
 			SSLMode:  getEnvOrDefault("DB_SSL_MODE", "disable"),
+
+			AutoMigrate: getBoolOrDefault("DB_AUTO_MIGRATE", true),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:           getBoolOrDefault("RATE_LIMIT_ENABLED", true),
+			RequestsPerSecond: getFloatOrDefault("RATE_LIMIT_RPS", 2),
+			Burst:             getIntOrDefault("RATE_LIMIT_BURST", 10),
+			IdleTTL:           getDurationOrDefault("RATE_LIMIT_IDLE_TTL", 10*time.Minute),
+		},
+		Resilience: ResilienceConfig{
+			MaxAttempts:             getIntOrDefault("RETRY_MAX_ATTEMPTS", 4),
+			BaseDelay:               getDurationOrDefault("RETRY_BASE_DELAY", 200*time.Millisecond),
+			MaxDelay:                getDurationOrDefault("RETRY_MAX_DELAY", 5*time.Second),
+			AttemptTimeout:          getDurationOrDefault("RETRY_ATTEMPT_TIMEOUT", 60*time.Second),
+			BreakerFailureThreshold: getIntOrDefault("CIRCUIT_BREAKER_FAILURE_THRESHOLD", 5),
+			BreakerOpenTimeout:      getDurationOrDefault("CIRCUIT_BREAKER_OPEN_TIMEOUT", 30*time.Second),
+		},
+		Health: HealthConfig{
+			CheckTimeout:    getDurationOrDefault("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+			BedrockCacheTTL: getDurationOrDefault("HEALTH_BEDROCK_CACHE_TTL", 5*time.Minute),
+		},
+		Usage: UsageConfig{
+			Enabled: getBoolOrDefault("USAGE_TRACKING_ENABLED", true),
+			Prices:  getPriceMapOrDefault("USAGE_MODEL_PRICES", nil),
+		},
+		Budget: BudgetConfig{
+			Enabled: getBoolOrDefault("BUDGET_ENABLED", false),
+			Default: TenantBudget{
+				MonthlyTokens:  int64(getIntOrDefault("BUDGET_MONTHLY_TOKENS", 0)),
+				MonthlyCostUSD: getFloatOrDefault("BUDGET_MONTHLY_COST_USD", 0),
+				DailyRequests:  getIntOrDefault("BUDGET_DAILY_REQUESTS", 0),
+			},
+			Tenants:         getBudgetMapOrDefault("BUDGET_TENANT_LIMITS", nil),
+			SoftLimitRatio:  getFloatOrDefault("BUDGET_SOFT_LIMIT_RATIO", 0.8),
+			RefreshInterval: getDurationOrDefault("BUDGET_REFRESH_INTERVAL", 30*time.Second),
+		},
+		QACache: AnswerCacheConfig{
+			Enabled:             getBoolOrDefault("QA_CACHE_ENABLED", false),
+			SimilarityThreshold: getFloatOrDefault("QA_CACHE_SIMILARITY_THRESHOLD", 0.95),
+			TTL:                 getDurationOrDefault("QA_CACHE_TTL", 24*time.Hour),
+		},
+		EmbedCache: EmbeddingCacheConfig{
+			Enabled:       getBoolOrDefault("EMBEDDING_CACHE_ENABLED", true),
+			MemoryEntries: getIntOrDefault("EMBEDDING_CACHE_MEMORY_ENTRIES", 5000),
+		},
+		Ingest: IngestConfig{
+			DuplicatePolicy:          getEnvOrDefault("INGEST_DUPLICATE_POLICY", "reject"),
+			NearDuplicateMaxDistance: getIntOrDefault("INGEST_NEAR_DUPLICATE_MAX_DISTANCE", 3),
+		},
+		Sync: SyncConfig{
+			Enabled:   getBoolOrDefault("SYNC_ENABLED", false),
+			Prefix:    getEnvOrDefault("SYNC_S3_PREFIX", "inbox/"),
+			Interval:  getDurationOrDefault("SYNC_INTERVAL", 15*time.Minute),
+			BatchSize: getIntOrDefault("SYNC_BATCH_SIZE", 100),
+		},
+		Watch: WatchConfig{
+			Dir:     getEnvOrDefault("WATCH_DIR", ""),
+			Include: getListOrDefault("WATCH_INCLUDE", nil),
+			// 隠しファイル・ディレクトリ (.git やエディタのスワップファイルなど) と一時ファイルは既定で除外する
+			Exclude:      getListOrDefault("WATCH_EXCLUDE", []string{".*", "*~", "*.tmp", "node_modules"}),
+			Debounce:     getDurationOrDefault("WATCH_DEBOUNCE", 2*time.Second),
+			PollInterval: getDurationOrDefault("WATCH_POLL_INTERVAL", 30*time.Second),
+		},
+		Crawl: CrawlConfig{
+			Seeds:          getListOrDefault("CRAWL_SEEDS", nil),
+			Sitemaps:       getListOrDefault("CRAWL_SITEMAPS", nil),
+			AllowedDomains: getListOrDefault("CRAWL_ALLOWED_DOMAINS", nil),
+			MaxDepth:       getIntOrDefault("CRAWL_MAX_DEPTH", 3),
+			MaxPages:       getIntOrDefault("CRAWL_MAX_PAGES", 1000),
+			Interval:       getDurationOrDefault("CRAWL_INTERVAL", 24*time.Hour),
+			Delay:          getDurationOrDefault("CRAWL_DELAY", 500*time.Millisecond),
+			Timeout:        getDurationOrDefault("CRAWL_TIMEOUT", 30*time.Second),
+			UserAgent:      getEnvOrDefault("CRAWL_USER_AGENT", "bedrock-rag-crawler/1.0"),
+		},
+		Git: GitConfig{
+			Repo:     getEnvOrDefault("GIT_REPO", ""),
+			Ref:      getEnvOrDefault("GIT_REF", "HEAD"),
+			CloneDir: getEnvOrDefault("GIT_CLONE_DIR", ""),
+			Include:  getListOrDefault("GIT_INCLUDE", []string{"**/*.md", "**/*.adoc"}),
+			Exclude:  getListOrDefault("GIT_EXCLUDE", nil),
+			Interval: getDurationOrDefault("GIT_SYNC_INTERVAL", time.Hour),
+		},
+		Classify: ClassifyConfig{
+			Mode:         getEnvOrDefault("CLASSIFY_MODE", ""),
+			Categories:   getListOrDefault("CLASSIFY_CATEGORIES", []string{"contract", "invoice", "manual", "policy"}),
+			TaxonomyFile: getEnvOrDefault("CLASSIFY_TAXONOMY_FILE", ""),
+			Model:        getEnvOrDefault("CLASSIFY_MODEL", ""),
+			MaxTags:      getIntOrDefault("CLASSIFY_MAX_TAGS", 5),
+			MinScore:     getFloatOrDefault("CLASSIFY_MIN_SCORE", 0.2),
+			SampleChars:  getIntOrDefault("CLASSIFY_SAMPLE_CHARS", 4000),
+		},
+		Redact: RedactConfig{
+			Enabled:        getBoolOrDefault("REDACT_ENABLED", false),
+			DefaultMode:    getEnvOrDefault("REDACT_DEFAULT_MODE", "mask"),
+			Modes:          getStringMapOrDefault("REDACT_MODES", map[string]string{}),
+			Detectors:      getListOrDefault("REDACT_DETECTORS", []string{"phone", "postal_code", "my_number", "credit_card", "email"}),
+			DictionaryFile: getEnvOrDefault("REDACT_DICTIONARY_FILE", ""),
+			HashKey:        getEnvOrDefault("REDACT_HASH_KEY", ""),
 		},
 	}
 }
@@ -67,3 +380,183 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getDurationOrDefault は環境変数から time.Duration 形式 (例: "30s") の値を取得する
+// 未設定または解析できない場合はデフォルト値を返す
+func getDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return d
+}
+
+// getIntOrDefault は環境変数から整数値を取得する
+// 未設定または解析できない場合はデフォルト値を返す
+func getIntOrDefault(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+	return i
+}
+
+// getFloatOrDefault は環境変数から浮動小数点数を取得する
+// 未設定または解析できない場合はデフォルト値を返す
+func getFloatOrDefault(key string, defaultValue float64) float64 {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return f
+}
+
+// getBoolOrDefault は環境変数から真偽値を取得する
+// 未設定または解析できない場合はデフォルト値を返す
+func getBoolOrDefault(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return b
+}
+
+// getListOrDefault はカンマ区切りの環境変数をスライスに変換する (空の要素は無視する)
+func getListOrDefault(key string, defaultValue []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue
+	}
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getIntMapOrDefault は "key1=1,key2=2" 形式の環境変数を map に変換する
+// 解析できない要素は無視する
+func getIntMapOrDefault(key string, defaultValue map[string]int) map[string]int {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue
+	}
+	result := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			continue
+		}
+		result[strings.TrimSpace(k)] = i
+	}
+	return result
+}
+
+// getStringMapOrDefault は "key1=value1,key2=value2" 形式の環境変数を map に変換する
+// 解析できない要素は無視する
+func getStringMapOrDefault(key string, defaultValue map[string]string) map[string]string {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue
+	}
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return result
+}
+
+// getPriceMapOrDefault は "model=入力単価:出力単価,..." 形式の環境変数を料金表に変換する
+// 出力単価は省略でき (Embeddingモデルなど)、解析できない要素は無視する
+func getPriceMapOrDefault(key string, defaultValue map[string]ModelPrice) map[string]ModelPrice {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue
+	}
+	result := make(map[string]ModelPrice)
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		in, out, _ := strings.Cut(strings.TrimSpace(v), ":")
+		var price ModelPrice
+		var err error
+		if price.InputPer1K, err = strconv.ParseFloat(strings.TrimSpace(in), 64); err != nil {
+			continue
+		}
+		if out != "" {
+			if price.OutputPer1K, err = strconv.ParseFloat(strings.TrimSpace(out), 64); err != nil {
+				continue
+			}
+		}
+		result[strings.TrimSpace(k)] = price
+	}
+	return result
+}
+
+// getBudgetMapOrDefault は "tenant=月間トークン数:月間料金:日次リクエスト数,..." 形式の環境変数をテナントごとの上限に変換する
+// 各項目は省略でき (例: "tenant-a=:50:"), 解析できない要素は無視する
+func getBudgetMapOrDefault(key string, defaultValue map[string]TenantBudget) map[string]TenantBudget {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue
+	}
+	result := make(map[string]TenantBudget)
+	for _, pair := range strings.Split(value, ",") {
+		// テナントにはAPIキー由来の "key:..." もあるため、先に "=" で分割する
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		fields := strings.Split(v, ":")
+		if len(fields) != 3 {
+			continue
+		}
+		var budget TenantBudget
+		var err error
+		if f := strings.TrimSpace(fields[0]); f != "" {
+			if budget.MonthlyTokens, err = strconv.ParseInt(f, 10, 64); err != nil {
+				continue
+			}
+		}
+		if f := strings.TrimSpace(fields[1]); f != "" {
+			if budget.MonthlyCostUSD, err = strconv.ParseFloat(f, 64); err != nil {
+				continue
+			}
+		}
+		if f := strings.TrimSpace(fields[2]); f != "" {
+			if budget.DailyRequests, err = strconv.Atoi(f); err != nil {
+				continue
+			}
+		}
+		result[strings.TrimSpace(k)] = budget
+	}
+	return result
+}
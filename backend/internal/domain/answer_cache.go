@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
+)
+
+// CachedAnswer はQAの回答キャッシュ1件分
+// 回答は呼び出し元の型をJSONにしたものをそのまま保持する
+type CachedAnswer struct {
+	ID          int64
+	Query       string
+	Model       string         // 回答の生成に使用したモデルのカタログ名
+	Space       EmbeddingSpace // クエリEmbeddingのモデルと次元数
+	Embedding   []float32
+	Result      []byte   // 回答 (JSON)
+	DocumentIDs []string // 回答の根拠にしたドキュメントのID
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	Similarity  float64 // 検索したクエリとのコサイン類似度 (検索結果のみ)
+}
+
+// FindCachedAnswer はクエリEmbeddingに最も近い有効な回答キャッシュを返す
+// 同じモデル・同じEmbeddingモデルのキャッシュのみを対象とし、該当がない場合は nil を返す
+func (h *DBHandler) FindCachedAnswer(ctx context.Context, model string, space EmbeddingSpace, embedding []float32, now time.Time) (*CachedAnswer, error) {
+	if len(embedding) != space.Dimension {
+		return nil, fmt.Errorf("%w: query has %d dimensions, model %s expects %d", ErrEmbeddingDimensionMismatch, len(embedding), space.Model, space.Dimension)
+	}
+
+	entry := CachedAnswer{Model: model, Space: space}
+	err := h.DB.QueryRowContext(ctx, `
+        SELECT id, query, result, document_ids, created_at, expires_at, 1 - (embedding <=> $1) AS similarity
+        FROM qa_answer_cache
+        WHERE model = $2 AND embedding_model = $3 AND embedding_dimension = $4 AND expires_at > $5
+        ORDER BY embedding <=> $1
+        LIMIT 1
+    `, pgvector.NewVector(embedding), model, space.Model, space.Dimension, now).Scan(
+		&entry.ID, &entry.Query, &entry.Result, pq.Array(&entry.DocumentIDs), &entry.CreatedAt, &entry.ExpiresAt, &entry.Similarity)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cached answer: %w", err)
+	}
+	return &entry, nil
+}
+
+// SaveCachedAnswer は回答キャッシュを1件保存する
+// 期限切れのキャッシュが溜まらないよう、保存の前に削除する
+func (h *DBHandler) SaveCachedAnswer(ctx context.Context, entry CachedAnswer) error {
+	if len(entry.Embedding) != entry.Space.Dimension {
+		return fmt.Errorf("%w: query has %d dimensions, model %s expects %d", ErrEmbeddingDimensionMismatch, len(entry.Embedding), entry.Space.Model, entry.Space.Dimension)
+	}
+
+	if _, err := h.DB.ExecContext(ctx, `DELETE FROM qa_answer_cache WHERE expires_at <= $1`, entry.CreatedAt); err != nil {
+		return fmt.Errorf("failed to delete expired cached answers: %w", err)
+	}
+	_, err := h.DB.ExecContext(ctx, `
+        INSERT INTO qa_answer_cache (query, model, embedding_model, embedding_dimension, embedding, result, document_ids, created_at, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    `, entry.Query, entry.Model, entry.Space.Model, entry.Space.Dimension, pgvector.NewVector(entry.Embedding),
+		entry.Result, pq.Array(entry.DocumentIDs), entry.CreatedAt, entry.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save cached answer: %w", err)
+	}
+	return nil
+}
+
+// InvalidateCachedAnswers は回答キャッシュをすべて削除し、削除した件数を返す
+func (h *DBHandler) InvalidateCachedAnswers(ctx context.Context) (int64, error) {
+	result, err := h.DB.ExecContext(ctx, `DELETE FROM qa_answer_cache`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to invalidate cached answers: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// InvalidateCachedAnswersForDocument は指定したドキュメントを根拠にした回答キャッシュを削除し、削除した件数を返す
+func (h *DBHandler) InvalidateCachedAnswersForDocument(ctx context.Context, documentID string) (int64, error) {
+	result, err := h.DB.ExecContext(ctx, `DELETE FROM qa_answer_cache WHERE $1 = ANY(document_ids)`, documentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to invalidate cached answers for document %s: %w", documentID, err)
+	}
+	return result.RowsAffected()
+}
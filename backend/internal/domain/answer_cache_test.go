@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindCachedAnswer(t *testing.T) {
+	ctx := context.Background()
+	space := EmbeddingSpace{Model: "titan-v2-256", Dimension: 2}
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("正常系: 最も近い有効なキャッシュを返す", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("FROM qa_answer_cache").
+			WithArgs(sqlmock.AnyArg(), "claude-3-haiku", "titan-v2-256", 2, now).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "query", "result", "document_ids", "created_at", "expires_at", "similarity"}).
+				AddRow(int64(7), "有給の申請方法は？", []byte(`{"answer":"ポータルから申請します。"}`), "{doc-1,doc-2}", now.Add(-time.Hour), now.Add(time.Hour), 0.98))
+
+		entry, err := h.FindCachedAnswer(ctx, "claude-3-haiku", space, []float32{0.6, 0.8}, now)
+
+		require.NoError(t, err)
+		require.NotNil(t, entry)
+		assert.Equal(t, int64(7), entry.ID)
+		assert.Equal(t, []string{"doc-1", "doc-2"}, entry.DocumentIDs)
+		assert.InDelta(t, 0.98, entry.Similarity, 1e-9)
+		assert.JSONEq(t, `{"answer":"ポータルから申請します。"}`, string(entry.Result))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("正常系: 該当なし", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("FROM qa_answer_cache").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		entry, err := h.FindCachedAnswer(ctx, "claude-3-haiku", space, []float32{0.6, 0.8}, now)
+
+		assert.NoError(t, err)
+		assert.Nil(t, entry)
+	})
+
+	t.Run("異常系: 次元数がモデルの定義と異なる", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		_, err := h.FindCachedAnswer(ctx, "claude-3-haiku", space, []float32{0.1}, now)
+
+		assert.ErrorIs(t, err, ErrEmbeddingDimensionMismatch)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSaveCachedAnswer(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	entry := CachedAnswer{
+		Query:       "有給の申請方法は？",
+		Model:       "claude-3-haiku",
+		Space:       EmbeddingSpace{Model: "titan-v2-256", Dimension: 2},
+		Embedding:   []float32{0.6, 0.8},
+		Result:      []byte(`{}`),
+		DocumentIDs: []string{"doc-1"},
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+
+	t.Run("正常系: 期限切れのキャッシュを削除してから保存する", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("DELETE FROM qa_answer_cache WHERE expires_at <= \\$1").WithArgs(now).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("INSERT INTO qa_answer_cache").
+			WithArgs("有給の申請方法は？", "claude-3-haiku", "titan-v2-256", 2, sqlmock.AnyArg(), []byte(`{}`), sqlmock.AnyArg(), now, now.Add(time.Hour)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := h.SaveCachedAnswer(ctx, entry)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 保存エラー", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		dbErr := errors.New("connection refused")
+		mock.ExpectExec("DELETE FROM qa_answer_cache").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO qa_answer_cache").WillReturnError(dbErr)
+
+		err := h.SaveCachedAnswer(ctx, entry)
+
+		assert.ErrorIs(t, err, dbErr)
+	})
+}
+
+func TestInvalidateCachedAnswers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 全件", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("DELETE FROM qa_answer_cache").WillReturnResult(sqlmock.NewResult(0, 5))
+
+		n, err := h.InvalidateCachedAnswers(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), n)
+	})
+
+	t.Run("正常系: ドキュメントを根拠にした回答のみ", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("DELETE FROM qa_answer_cache WHERE \\$1 = ANY\\(document_ids\\)").WithArgs("doc-1").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		n, err := h.InvalidateCachedAnswersForDocument(ctx, "doc-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+	})
+}
@@ -8,7 +8,7 @@ import (
 
 	"bedrock-rag-sample/backend/config"
 
-	_ "github.com/lib/pq"             // PostgreSQL driver
+	"github.com/lib/pq"               // PostgreSQL driver
 	"github.com/pgvector/pgvector-go" // pgvector
 )
 
@@ -43,32 +43,139 @@ func (h *DBHandler) Close() error {
 	return nil
 }
 
+// Ping はデータベースへの接続を確認する (ヘルスチェック用)
+func (h *DBHandler) Ping(ctx context.Context) error {
+	if h.DB == nil {
+		return fmt.Errorf("database is not initialized")
+	}
+	if err := h.DB.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
 // --- DocumentChunk関連のメソッド ---
 
-// SaveDocumentEmbedding はドキュメントチャンクのEmbeddingを保存する
-func (h *DBHandler) SaveDocumentEmbedding(ctx context.Context, documentID int64, chunkContent string, chunkIndex int, embedding []float32) (int64, error) {
-	query := `
-        INSERT INTO document_chunks (document_id, chunk_index, content, embedding)
-        VALUES ($1, $2, $3, $4)
-        RETURNING id
-    `
-	var chunkID int64
-	err := h.DB.QueryRowContext(ctx, query, documentID, chunkIndex, chunkContent, pgvector.NewVector(embedding)).Scan(&chunkID)
+// SaveDocumentChunks はドキュメントの全チャンクを1トランザクションで保存する
+// 既存のチャンクは置き換えるため、再処理しても重複や一部だけ保存された状態は残らない
+// 各チャンクには生成に使用したEmbeddingモデルと次元数を記録する
+// 検索用のモデルが space と異なる場合 (生成中に切り替わった場合) は書き込まずに ErrEmbeddingSpaceChanged を返す
+// shadow を指定した場合は検索用以外のモデルのEmbeddingも同じトランザクションで保存する (nil の場合は保存しない)
+func (h *DBHandler) SaveDocumentChunks(ctx context.Context, documentID int64, space EmbeddingSpace, chunks []ChunkEmbedding, shadow *ShadowEmbeddingSet) (err error) {
+	for _, chunk := range chunks {
+		if len(chunk.Embedding) != space.Dimension {
+			return fmt.Errorf("%w: chunk %d has %d dimensions, model %s expects %d", ErrEmbeddingDimensionMismatch, chunk.ChunkIndex, len(chunk.Embedding), space.Model, space.Dimension)
+		}
+	}
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// swapEmbeddings と同じ順序 (document_chunks → embedding_index_state) でロックを取得し、
+	// 入れ替えと並行して書き込んだチャンクが切り替え前のモデルのまま残らないようにする
+	if _, err = tx.ExecContext(ctx, `LOCK TABLE document_chunks IN ROW EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("failed to lock document chunks: %w", err)
+	}
+	var active EmbeddingSpace
+	if err = tx.QueryRowContext(ctx, `
+        SELECT active_model, active_dimension FROM embedding_index_state WHERE id FOR SHARE
+    `).Scan(&active.Model, &active.Dimension); err != nil {
+		return fmt.Errorf("failed to get embedding index state: %w", err)
+	}
+	if active != space {
+		return fmt.Errorf("%w (active: %s, embedded with: %s)", ErrEmbeddingSpaceChanged, active.Model, space.Model)
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM document_chunks WHERE document_id = $1`, documentID); err != nil {
+		return fmt.Errorf("failed to delete existing document chunks: %w", err)
+	}
+	// 再インデックス用のEmbeddingも古い内容のものになるため削除する
+	if _, err = tx.ExecContext(ctx, `DELETE FROM document_chunk_embeddings WHERE document_id = $1`, documentID); err != nil {
+		return fmt.Errorf("failed to delete existing shadow embeddings: %w", err)
+	}
+
+	// COPY で全チャンクを1回の往復でまとめて書き込む (行ごとの INSERT より大幅に速い)
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("document_chunks", "document_id", "chunk_index", "content", "start_line", "end_line", "embedding", "embedding_model", "embedding_dimension"))
 	if err != nil {
-		return 0, fmt.Errorf("failed to insert document chunk: %w", err)
+		return fmt.Errorf("failed to prepare chunk copy: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, chunk := range chunks {
+		if _, err = stmt.ExecContext(ctx, documentID, chunk.ChunkIndex, chunk.Content, chunk.StartLine, chunk.EndLine, pgvector.NewVector(chunk.Embedding), space.Model, space.Dimension); err != nil {
+			return fmt.Errorf("failed to buffer document chunk %d: %w", chunk.ChunkIndex, err)
+		}
 	}
-	return chunkID, nil
+	// 引数なしの Exec でバッファした行を送信する
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("failed to copy document chunks: %w", err)
+	}
+	// 切り替え用のEmbeddingがチャンクと揃わないまま検索できる状態にならないよう、同じトランザクションで書き込む
+	if shadow != nil {
+		if err = upsertShadowEmbeddings(ctx, tx, shadow.Space, shadow.Embeddings); err != nil {
+			return err
+		}
+	}
+	// ドキュメントが変わると以前の回答が古くなるため、QAの回答キャッシュも破棄する
+	// キャッシュした回答の document_ids はKnowledge BaseのIDで documents.id と対応しないため、根拠にしたドキュメントでは絞り込めない
+	if _, err = tx.ExecContext(ctx, `DELETE FROM qa_answer_cache`); err != nil {
+		return fmt.Errorf("failed to invalidate cached answers: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit document chunks: %w", err)
+	}
+	return nil
 }
 
 // FindSimilarChunks は指定されたEmbeddingに類似したチャンクを検索する (L2距離)
-func (h *DBHandler) FindSimilarChunks(ctx context.Context, queryEmbedding []float32, limit int) ([]DocumentChunk, error) {
+// 異なるモデルのベクトル同士は比較できないため、別モデルのチャンクが混在している場合は検索しない
+// filter.AllVersions が false の場合は最新の版のドキュメントのみを検索する
+func (h *DBHandler) FindSimilarChunks(ctx context.Context, space EmbeddingSpace, queryEmbedding []float32, limit int, filter ChunkFilter) ([]DocumentChunk, error) {
+	if len(queryEmbedding) != space.Dimension {
+		return nil, fmt.Errorf("%w: query has %d dimensions, model %s expects %d", ErrEmbeddingDimensionMismatch, len(queryEmbedding), space.Model, space.Dimension)
+	}
+
+	var mixed bool
+	if err := h.DB.QueryRowContext(ctx, `
+        SELECT EXISTS (
+            SELECT 1 FROM document_chunks
+            WHERE embedding_model <> $1 OR embedding_dimension <> $2
+        )
+    `, space.Model, space.Dimension).Scan(&mixed); err != nil {
+		return nil, fmt.Errorf("failed to check embedding models: %w", err)
+	}
+	if mixed {
+		return nil, fmt.Errorf("%w (model: %s)", ErrMixedEmbeddingModels, space.Model)
+	}
+
+	// カテゴリとタグの条件は指定した場合のみ加える
+	args := []any{pgvector.NewVector(queryEmbedding), space.Model, limit, filter.AllVersions}
+	var conditions string
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		conditions += fmt.Sprintf(" AND d.category = $%d", len(args))
+	}
+	if len(filter.Tags) > 0 {
+		args = append(args, pq.StringArray(filter.Tags))
+		conditions += fmt.Sprintf(" AND d.tags @> $%d", len(args))
+	}
 	query := `
-        SELECT id, document_id, chunk_index, content, embedding <-> $1 AS similarity
-        FROM document_chunks
+        SELECT c.id, c.document_id, c.chunk_index, c.content, c.start_line, c.end_line, c.embedding <-> $1 AS similarity
+        FROM document_chunks c
+        JOIN documents d ON d.id = c.document_id
+        WHERE c.embedding_model = $2 AND ($4 OR d.is_latest)` + conditions + `
         ORDER BY similarity
-        LIMIT $2
+        LIMIT $3
     `
-	rows, err := h.DB.QueryContext(ctx, query, pgvector.NewVector(queryEmbedding), limit)
+	rows, err := h.DB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query similar chunks: %w", err)
 	}
@@ -78,7 +185,7 @@ func (h *DBHandler) FindSimilarChunks(ctx context.Context, queryEmbedding []floa
 	for rows.Next() {
 		var chunk DocumentChunk
 		var embedding pgvector.Vector
-		if err := rows.Scan(&chunk.ID, &chunk.DocumentID, &chunk.ChunkIndex, &chunk.Content, &chunk.Similarity); err != nil {
+		if err := rows.Scan(&chunk.ID, &chunk.DocumentID, &chunk.ChunkIndex, &chunk.Content, &chunk.StartLine, &chunk.EndLine, &chunk.Similarity); err != nil {
 			return nil, fmt.Errorf("failed to scan chunk row: %w", err)
 		}
 		chunk.Embedding = embedding // スキャンしたEmbeddingを設定
@@ -96,13 +203,12 @@ func (h *DBHandler) FindSimilarChunks(ctx context.Context, queryEmbedding []floa
 
 // GetDocumentByID はIDでドキュメントを取得する (Contentは含まない)
 func (h *DBHandler) GetDocumentByID(ctx context.Context, documentID int64) (*Document, error) {
-	query := `SELECT id, filename, s3_key, created_at FROM documents WHERE id = $1`
-	row := h.DB.QueryRowContext(ctx, query, documentID)
+	row := h.DB.QueryRowContext(ctx, `SELECT `+documentColumns+` FROM documents WHERE id = $1`, documentID)
 
 	var doc Document
-	if err := row.Scan(&doc.ID, &doc.Filename, &doc.S3Key, &doc.CreatedAt); err != nil {
+	if err := scanDocument(row, &doc); err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("document not found with id %d", documentID)
+			return nil, fmt.Errorf("%w with id %d", ErrDocumentNotFound, documentID)
 		}
 		return nil, fmt.Errorf("failed to scan document row: %w", err)
 	}
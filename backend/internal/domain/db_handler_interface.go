@@ -2,13 +2,63 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 // DBHandlerInterface はデータベース操作のためのインターフェース
 type DBHandlerInterface interface {
-	SaveDocumentEmbedding(ctx context.Context, documentID int64, chunk string, chunkIndex int, embedding []float32) (int64, error)
-	FindSimilarChunks(ctx context.Context, embedding []float32, limit int) ([]DocumentChunk, error)
+	SaveDocumentChunks(ctx context.Context, documentID int64, space EmbeddingSpace, chunks []ChunkEmbedding, shadow *ShadowEmbeddingSet) error
+	FindSimilarChunks(ctx context.Context, space EmbeddingSpace, embedding []float32, limit int, filter ChunkFilter) ([]DocumentChunk, error)
 	GetDocumentByID(ctx context.Context, documentID int64) (*Document, error)
+	CreateDocument(ctx context.Context, doc *Document) error
+	FindDocumentByContentHash(ctx context.Context, contentHash string) (*Document, error)
+	FindNearDuplicateDocuments(ctx context.Context, simHash int64, maxDistance, limit int) ([]NearDuplicate, error)
+	DeleteDocument(ctx context.Context, documentID int64) error
+	ListDocuments(ctx context.Context, filter DocumentListFilter) ([]Document, error)
+	ListDocumentVersions(ctx context.Context, logicalID int64) ([]Document, error)
+	GetDocumentVersion(ctx context.Context, logicalID int64, version int) (*Document, error)
+	ListDocumentsBySource(ctx context.Context, r SourceRange) ([]Document, error)
+	UpdateDocumentSource(ctx context.Context, documentID int64, version string, modifiedAt time.Time) error
+	UpdateDocumentClassification(ctx context.Context, documentID int64, category string, tags []string) error
+
+	// 取り込み元の同期
+	GetSyncState(ctx context.Context, source string) (*SyncState, error)
+	ClaimSyncState(ctx context.Context, source, owner string, ttl time.Duration) (*SyncState, bool, error)
+	SaveSyncCheckpoint(ctx context.Context, source, owner string, ttl time.Duration, checkpoint string, stats SyncStats) error
+	CompleteSyncPass(ctx context.Context, source, owner, cursor string, stats SyncStats) error
+	ReleaseSyncState(ctx context.Context, source, owner, message string) error
+
+	// Embeddingモデルの切り替え (再インデックス)
+	GetEmbeddingIndexState(ctx context.Context, initial EmbeddingSpace) (*EmbeddingIndexState, error)
+	CreateReindexJob(ctx context.Context, target EmbeddingSpace) (*ReindexJob, error)
+	FindOpenReindexJob(ctx context.Context) (*ReindexJob, error)
+	ClaimReindexJob(ctx context.Context, jobID int64, owner string, ttl time.Duration) (bool, error)
+	ListChunksForReindex(ctx context.Context, target EmbeddingSpace, afterChunkID int64, limit int) ([]DocumentChunk, error)
+	SaveReindexBatch(ctx context.Context, job *ReindexJob, owner string, ttl time.Duration, embeddings []ShadowEmbedding, lastChunkID int64) error
+	GetReindexCoverage(ctx context.Context, space EmbeddingSpace) (ReindexCoverage, error)
+	UpdateReindexJobStatus(ctx context.Context, jobID int64, from []string, to string, message string) error
+	ActivateReindexJob(ctx context.Context, jobID int64) error
+	RollbackReindexJob(ctx context.Context, jobID int64) error
+	FinalizeReindexJob(ctx context.Context, jobID int64) error
+	CancelReindexJob(ctx context.Context, jobID int64) error
+
+	// トークン使用量と料金
+	SaveUsageRecord(ctx context.Context, record UsageRecord) error
+	AggregateUsage(ctx context.Context, filter UsageFilter) ([]UsageAggregate, error)
+	GetTenantUsage(ctx context.Context, tenant string, monthStart, dayStart time.Time) (TenantUsage, error)
+
+	// QAの回答キャッシュ
+	FindCachedAnswer(ctx context.Context, model string, space EmbeddingSpace, embedding []float32, now time.Time) (*CachedAnswer, error)
+	SaveCachedAnswer(ctx context.Context, entry CachedAnswer) error
+	InvalidateCachedAnswers(ctx context.Context) (int64, error)
+	InvalidateCachedAnswersForDocument(ctx context.Context, documentID string) (int64, error)
+
+	// Embeddingのキャッシュ
+	GetCachedEmbeddings(ctx context.Context, key string, hashes []string) (map[string][]float32, error)
+	SaveCachedEmbeddings(ctx context.Context, key string, entries []CachedEmbedding) error
+
+	// 個人情報の除去の監査
+	SaveRedactionAudit(ctx context.Context, records []PIIRedaction) error
 	// 他の DBHandler メソッドが必要であればここに追加
 }
 
@@ -0,0 +1,244 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupMockDB(t *testing.T) (*DBHandler, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return &DBHandler{DB: db}, mock, func() {
+		db.Close()
+	}
+}
+
+func TestSaveDocumentChunks(t *testing.T) {
+	ctx := context.Background()
+	space := EmbeddingSpace{Model: "titan-v2-256", Dimension: 2}
+	chunks := []ChunkEmbedding{
+		{ChunkIndex: 0, Content: "チャンク1", StartLine: 1, EndLine: 3, Embedding: []float32{0.1, 0.2}},
+		{ChunkIndex: 1, Content: "チャンク2", StartLine: 5, EndLine: 5, Embedding: []float32{0.3, 0.4}},
+	}
+
+	t.Run("正常系: 既存チャンクを置き換えてコミットする", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("LOCK TABLE document_chunks IN ROW EXCLUSIVE MODE").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT active_model, active_dimension FROM embedding_index_state WHERE id FOR SHARE").
+			WillReturnRows(sqlmock.NewRows([]string{"active_model", "active_dimension"}).AddRow("titan-v2-256", 2))
+		mock.ExpectExec("DELETE FROM document_chunks WHERE document_id = \\$1").
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec("DELETE FROM document_chunk_embeddings WHERE document_id = \\$1").
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		prep := mock.ExpectPrepare(`COPY "document_chunks" \("document_id", "chunk_index", "content", "start_line", "end_line", "embedding", "embedding_model", "embedding_dimension"\) FROM STDIN`)
+		prep.ExpectExec().WithArgs(int64(1), 0, "チャンク1", 1, 3, sqlmock.AnyArg(), "titan-v2-256", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		prep.ExpectExec().WithArgs(int64(1), 1, "チャンク2", 5, 5, sqlmock.AnyArg(), "titan-v2-256", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("DELETE FROM qa_answer_cache").WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectCommit()
+
+		err := h.SaveDocumentChunks(ctx, 1, space, chunks, nil)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("正常系: Knowledge BaseのIDを根拠にした回答も含めて回答キャッシュを破棄する", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("LOCK TABLE document_chunks IN ROW EXCLUSIVE MODE").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT active_model, active_dimension FROM embedding_index_state WHERE id FOR SHARE").
+			WillReturnRows(sqlmock.NewRows([]string{"active_model", "active_dimension"}).AddRow("titan-v2-256", 2))
+		mock.ExpectExec("DELETE FROM document_chunks").WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM document_chunk_embeddings").WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+		prep := mock.ExpectPrepare("COPY")
+		prep.ExpectExec().WithArgs(int64(1), 0, "チャンク1", 1, 3, sqlmock.AnyArg(), "titan-v2-256", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		prep.ExpectExec().WithArgs(int64(1), 1, "チャンク2", 5, 5, sqlmock.AnyArg(), "titan-v2-256", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 2))
+		// キャッシュした回答の document_ids ("mock-doc-001" など) は documents.id と対応しないため、条件を付けずに破棄する
+		mock.ExpectExec("^DELETE FROM qa_answer_cache$").WithArgs().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := h.SaveDocumentChunks(ctx, 1, space, chunks, nil)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 途中で失敗した場合はロールバックする", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		insertErr := errors.New("insert failed")
+		mock.ExpectBegin()
+		mock.ExpectExec("LOCK TABLE document_chunks IN ROW EXCLUSIVE MODE").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT active_model, active_dimension FROM embedding_index_state WHERE id FOR SHARE").
+			WillReturnRows(sqlmock.NewRows([]string{"active_model", "active_dimension"}).AddRow("titan-v2-256", 2))
+		mock.ExpectExec("DELETE FROM document_chunks").WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM document_chunk_embeddings").WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+		prep := mock.ExpectPrepare(`COPY "document_chunks"`)
+		prep.ExpectExec().WithArgs(int64(1), 0, "チャンク1", 1, 3, sqlmock.AnyArg(), "titan-v2-256", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		prep.ExpectExec().WithArgs(int64(1), 1, "チャンク2", 5, 5, sqlmock.AnyArg(), "titan-v2-256", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		prep.ExpectExec().WithArgs().WillReturnError(insertErr)
+		mock.ExpectRollback()
+
+		err := h.SaveDocumentChunks(ctx, 1, space, chunks, nil)
+
+		assert.ErrorIs(t, err, insertErr)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 切り替え用のEmbeddingの保存に失敗した場合はチャンクもロールバックする", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		shadowSpace := EmbeddingSpace{Model: "titan-v1", Dimension: 1}
+		shadow := &ShadowEmbeddingSet{Space: shadowSpace, Embeddings: []ShadowEmbedding{
+			{DocumentID: 1, ChunkIndex: 0, Embedding: []float32{0.5}},
+			{DocumentID: 1, ChunkIndex: 1, Embedding: []float32{0.6}},
+		}}
+		upsertErr := errors.New("upsert failed")
+		mock.ExpectBegin()
+		mock.ExpectExec("LOCK TABLE document_chunks IN ROW EXCLUSIVE MODE").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT active_model, active_dimension FROM embedding_index_state WHERE id FOR SHARE").
+			WillReturnRows(sqlmock.NewRows([]string{"active_model", "active_dimension"}).AddRow("titan-v2-256", 2))
+		mock.ExpectExec("DELETE FROM document_chunks").WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM document_chunk_embeddings").WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+		prep := mock.ExpectPrepare(`COPY "document_chunks"`)
+		prep.ExpectExec().WithArgs(int64(1), 0, "チャンク1", 1, 3, sqlmock.AnyArg(), "titan-v2-256", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		prep.ExpectExec().WithArgs(int64(1), 1, "チャンク2", 5, 5, sqlmock.AnyArg(), "titan-v2-256", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 2))
+		upsert := mock.ExpectPrepare("INSERT INTO document_chunk_embeddings")
+		upsert.ExpectExec().WithArgs(int64(1), 0, "titan-v1", 1, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		upsert.ExpectExec().WithArgs(int64(1), 1, "titan-v1", 1, sqlmock.AnyArg()).WillReturnError(upsertErr)
+		// チャンクだけが検索できる状態で残らないよう、コミットせずにロールバックする
+		mock.ExpectRollback()
+
+		err := h.SaveDocumentChunks(ctx, 1, space, chunks, shadow)
+
+		assert.ErrorIs(t, err, upsertErr)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 生成中に検索用のモデルが切り替わった場合は書き込まない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("LOCK TABLE document_chunks IN ROW EXCLUSIVE MODE").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT active_model, active_dimension FROM embedding_index_state WHERE id FOR SHARE").
+			WillReturnRows(sqlmock.NewRows([]string{"active_model", "active_dimension"}).AddRow("titan-v2-1024", 1024))
+		mock.ExpectRollback()
+
+		err := h.SaveDocumentChunks(ctx, 1, space, chunks, nil)
+
+		assert.ErrorIs(t, err, ErrEmbeddingSpaceChanged)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 次元数がモデルの定義と異なる場合は書き込まない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		err := h.SaveDocumentChunks(ctx, 1, EmbeddingSpace{Model: "titan-v1", Dimension: 1536}, chunks, nil)
+
+		assert.ErrorIs(t, err, ErrEmbeddingDimensionMismatch)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFindSimilarChunks(t *testing.T) {
+	ctx := context.Background()
+	space := EmbeddingSpace{Model: "titan-v2-256", Dimension: 2}
+	query := []float32{0.1, 0.2}
+
+	t.Run("正常系: 同じモデルのチャンクだけを検索する", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs("titan-v2-256", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery("WHERE c.embedding_model = \\$2 AND \\(\\$4 OR d.is_latest\\)").
+			WithArgs(sqlmock.AnyArg(), "titan-v2-256", 5, false).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "document_id", "chunk_index", "content", "start_line", "end_line", "similarity"}).
+				AddRow(int64(1), int64(10), 0, "チャンク", 3, 8, 0.5))
+
+		chunks, err := h.FindSimilarChunks(ctx, space, query, 5, ChunkFilter{})
+
+		require.NoError(t, err)
+		require.Len(t, chunks, 1)
+		assert.Equal(t, int64(10), chunks[0].DocumentID)
+		assert.Equal(t, 3, chunks[0].StartLine)
+		assert.Equal(t, 8, chunks[0].EndLine)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("正常系: 過去の版も検索する", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("SELECT EXISTS").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery("FROM document_chunks c").
+			WithArgs(sqlmock.AnyArg(), "titan-v2-256", 5, true).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "document_id", "chunk_index", "content", "start_line", "end_line", "similarity"}))
+
+		_, err := h.FindSimilarChunks(ctx, space, query, 5, ChunkFilter{AllVersions: true})
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("正常系: カテゴリとタグで絞り込む", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("SELECT EXISTS").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery("AND d.category = \\$5 AND d.tags @> \\$6").
+			WithArgs(sqlmock.AnyArg(), "titan-v2-256", 5, false, "policy", pq.StringArray{"人事"}).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "document_id", "chunk_index", "content", "start_line", "end_line", "similarity"}))
+
+		_, err := h.FindSimilarChunks(ctx, space, query, 5, ChunkFilter{Category: "policy", Tags: []string{"人事"}})
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 別モデルのチャンクが混在している場合は検索しない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs("titan-v2-256", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		_, err := h.FindSimilarChunks(ctx, space, query, 5, ChunkFilter{})
+
+		assert.ErrorIs(t, err, ErrMixedEmbeddingModels)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: クエリの次元数が異なる場合は検索しない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		_, err := h.FindSimilarChunks(ctx, space, []float32{0.1, 0.2, 0.3}, 5, ChunkFilter{})
+
+		assert.ErrorIs(t, err, ErrEmbeddingDimensionMismatch)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
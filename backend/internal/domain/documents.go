@@ -0,0 +1,361 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// NearDuplicate は類似した重複の候補となる既存のドキュメント
+type NearDuplicate struct {
+	DocumentID int64
+	LogicalID  int64
+	Filename   string
+	Distance   int // SimHashのハミング距離 (0〜64)
+}
+
+// documentColumns はドキュメントの一覧や版の取得で読み込む列 (Content を除く)
+const documentColumns = `id, logical_id, version, is_latest, filename, s3_key, COALESCE(content_hash, ''), duplicate_of, created_at,
+    COALESCE(source_uri, ''), COALESCE(source_version, ''), source_modified_at, COALESCE(category, ''), tags`
+
+// scanDocument は documentColumns の順に読み込む
+func scanDocument(row interface{ Scan(dest ...any) error }, doc *Document, extra ...any) error {
+	var tags pq.StringArray
+	dest := append([]any{&doc.ID, &doc.LogicalID, &doc.Version, &doc.IsLatest, &doc.Filename, &doc.S3Key, &doc.ContentHash, &doc.DuplicateOf, &doc.CreatedAt,
+		&doc.SourceURI, &doc.SourceVersion, &doc.SourceModifiedAt, &doc.Category, &tags}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	if len(tags) > 0 {
+		doc.Tags = tags
+	}
+	return nil
+}
+
+// CreateDocument はドキュメントを保存し、採番したIDと版、作成日時を doc に設定する
+// doc.LogicalID が 0 の場合は新しい論理ドキュメントの最初の版とし、それ以外の場合はその論理ドキュメントの最新の版として追加する
+func (h *DBHandler) CreateDocument(ctx context.Context, doc *Document) (err error) {
+	contentHash := nullString(doc.ContentHash)
+	sourceURI, sourceVersion := nullString(doc.SourceURI), nullString(doc.SourceVersion)
+	category, tags := nullString(doc.Category), tagArray(doc.Tags)
+
+	if doc.LogicalID == 0 {
+		// 最初の版は自身のIDを論理ドキュメントのIDとするため、IDを先に採番する
+		err := h.DB.QueryRowContext(ctx, `
+            WITH next AS (SELECT nextval(pg_get_serial_sequence('documents', 'id')) AS id)
+            INSERT INTO documents (id, logical_id, version, is_latest, filename, s3_key, content, content_hash, simhash, duplicate_of,
+                                   source_uri, source_version, source_modified_at, category, tags)
+            SELECT next.id, next.id, 1, TRUE, $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11 FROM next
+            RETURNING id, logical_id, version, is_latest, created_at
+        `, doc.Filename, doc.S3Key, doc.Content, contentHash, doc.SimHash, doc.DuplicateOf, sourceURI, sourceVersion, doc.SourceModifiedAt, category, tags).
+			Scan(&doc.ID, &doc.LogicalID, &doc.Version, &doc.IsLatest, &doc.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create document: %w", err)
+		}
+		return nil
+	}
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 同じ論理ドキュメントへの版の追加を直列化するため、最新の版の行をロックする
+	var latestID int64
+	var latestVersion int
+	err = tx.QueryRowContext(ctx, `
+        SELECT id, version FROM documents
+        WHERE logical_id = $1 AND is_latest
+        FOR UPDATE
+    `, doc.LogicalID).Scan(&latestID, &latestVersion)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w with logical id %d", ErrDocumentNotFound, doc.LogicalID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock latest document version: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE documents SET is_latest = FALSE WHERE id = $1`, latestID); err != nil {
+		return fmt.Errorf("failed to update latest document version: %w", err)
+	}
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO documents (logical_id, version, is_latest, filename, s3_key, content, content_hash, simhash, duplicate_of,
+                               source_uri, source_version, source_modified_at, category, tags)
+        VALUES ($1, $2, TRUE, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+        RETURNING id, version, is_latest, created_at
+    `, doc.LogicalID, latestVersion+1, doc.Filename, doc.S3Key, doc.Content, contentHash, doc.SimHash, doc.DuplicateOf,
+		sourceURI, sourceVersion, doc.SourceModifiedAt, category, tags).
+		Scan(&doc.ID, &doc.Version, &doc.IsLatest, &doc.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create document version: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit document version: %w", err)
+	}
+	return nil
+}
+
+// FindDocumentByContentHash はファイルのハッシュが一致する最も古いドキュメントを返す (該当がない場合は nil)
+func (h *DBHandler) FindDocumentByContentHash(ctx context.Context, contentHash string) (*Document, error) {
+	var doc Document
+	err := scanDocument(h.DB.QueryRowContext(ctx, `
+        SELECT `+documentColumns+` FROM documents
+        WHERE content_hash = $1
+        ORDER BY id
+        LIMIT 1
+    `, contentHash), &doc)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find document by content hash: %w", err)
+	}
+	return &doc, nil
+}
+
+// FindNearDuplicateDocuments はSimHashのハミング距離が maxDistance 以下のドキュメントを距離の近い順に返す
+func (h *DBHandler) FindNearDuplicateDocuments(ctx context.Context, simHash int64, maxDistance, limit int) ([]NearDuplicate, error) {
+	// bit_count は PostgreSQL 14 以降のため、ビット列の '1' の数で距離を求める
+	rows, err := h.DB.QueryContext(ctx, `
+        SELECT id, logical_id, filename, distance FROM (
+            SELECT id, logical_id, filename, length(replace(((simhash # $1)::bit(64))::text, '0', '')) AS distance
+            FROM documents
+            WHERE simhash IS NOT NULL
+        ) d
+        WHERE distance <= $2
+        ORDER BY distance, id
+        LIMIT $3
+    `, simHash, maxDistance, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find near-duplicate documents: %w", err)
+	}
+	defer rows.Close()
+
+	var duplicates []NearDuplicate
+	for rows.Next() {
+		var d NearDuplicate
+		if err := rows.Scan(&d.DocumentID, &d.LogicalID, &d.Filename, &d.Distance); err != nil {
+			return nil, fmt.Errorf("failed to scan near-duplicate document: %w", err)
+		}
+		duplicates = append(duplicates, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating near-duplicate documents: %w", err)
+	}
+	return duplicates, nil
+}
+
+// DeleteDocument はドキュメント (1つの版) とそのチャンクを削除する
+// 最新の版を削除した場合は、残っている版のうち最も新しいものを最新の版に戻す
+// 削除したドキュメントを根拠にしたQAの回答キャッシュも合わせて破棄する
+func (h *DBHandler) DeleteDocument(ctx context.Context, documentID int64) (err error) {
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// チャンクと再インデックス用のEmbeddingは ON DELETE CASCADE で削除される (個人情報の監査の記録は document_id を NULL にして残す)
+	var logicalID int64
+	var wasLatest bool
+	err = tx.QueryRowContext(ctx, `DELETE FROM documents WHERE id = $1 RETURNING logical_id, is_latest`, documentID).Scan(&logicalID, &wasLatest)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w with id %d", ErrDocumentNotFound, documentID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	if wasLatest {
+		if _, err = tx.ExecContext(ctx, `
+            UPDATE documents SET is_latest = TRUE
+            WHERE id = (SELECT id FROM documents WHERE logical_id = $1 ORDER BY version DESC LIMIT 1)
+        `, logicalID); err != nil {
+			return fmt.Errorf("failed to restore latest document version: %w", err)
+		}
+	}
+	// 取り込み時と同様に、キャッシュした回答は documents.id で絞り込めないため全件を破棄する
+	if _, err = tx.ExecContext(ctx, `DELETE FROM qa_answer_cache`); err != nil {
+		return fmt.Errorf("failed to invalidate cached answers: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit document deletion: %w", err)
+	}
+	return nil
+}
+
+// defaultDocumentListLimit はドキュメントの一覧の既定の最大件数
+const defaultDocumentListLimit = 100
+
+// ListDocuments はドキュメントをIDの順に返す (Contentは含まない)
+// 既定では最新の版のみを返し、filter.AllVersions で過去の版も含める
+func (h *DBHandler) ListDocuments(ctx context.Context, filter DocumentListFilter) ([]Document, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultDocumentListLimit
+	}
+	rows, err := h.DB.QueryContext(ctx, `
+        SELECT `+documentColumns+` FROM documents
+        WHERE id > $1 AND ($2 OR is_latest)
+        ORDER BY id
+        LIMIT $3
+    `, filter.AfterID, filter.AllVersions, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []Document
+	for rows.Next() {
+		var doc Document
+		if err := scanDocument(rows, &doc); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating documents: %w", err)
+	}
+	return docs, nil
+}
+
+// ListDocumentVersions は論理ドキュメントの版を古い順に返す (Contentは含まない)
+func (h *DBHandler) ListDocumentVersions(ctx context.Context, logicalID int64) ([]Document, error) {
+	rows, err := h.DB.QueryContext(ctx, `
+        SELECT `+documentColumns+` FROM documents
+        WHERE logical_id = $1
+        ORDER BY version
+    `, logicalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list document versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []Document
+	for rows.Next() {
+		var doc Document
+		if err := scanDocument(rows, &doc); err != nil {
+			return nil, fmt.Errorf("failed to scan document version: %w", err)
+		}
+		versions = append(versions, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating document versions: %w", err)
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w with logical id %d", ErrDocumentNotFound, logicalID)
+	}
+	return versions, nil
+}
+
+// GetDocumentVersion は論理ドキュメントの指定した版を抽出したテキスト (Content) を含めて返す
+// version が 0 の場合は最新の版を返す
+func (h *DBHandler) GetDocumentVersion(ctx context.Context, logicalID int64, version int) (*Document, error) {
+	var doc Document
+	err := scanDocument(h.DB.QueryRowContext(ctx, `
+        SELECT `+documentColumns+`, content FROM documents
+        WHERE logical_id = $1 AND (version = $2 OR ($2 = 0 AND is_latest))
+    `, logicalID, version), &doc, &doc.Content)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w with logical id %d and version %d", ErrDocumentNotFound, logicalID, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document version: %w", err)
+	}
+	return &doc, nil
+}
+
+// ListDocumentsBySource は取り込み元のURIが r の範囲にあるドキュメントを、URIのバイト順 (同じURIの中ではIDの順) に返す (Contentは含まない)
+// 取り込み元の一覧 (S3のキーの順) と突き合わせるため、照合順序に依存しないバイト順で並べる
+func (h *DBHandler) ListDocumentsBySource(ctx context.Context, r SourceRange) ([]Document, error) {
+	rows, err := h.DB.QueryContext(ctx, `
+        SELECT `+documentColumns+` FROM documents
+        WHERE source_uri IS NOT NULL
+          AND left(source_uri, length($1)) = $1
+          AND source_uri COLLATE "C" > $2
+          AND ($3 = '' OR source_uri COLLATE "C" <= $3)
+        ORDER BY source_uri COLLATE "C", id
+    `, r.Prefix, r.After, r.Through)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents by source: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []Document
+	for rows.Next() {
+		var doc Document
+		if err := scanDocument(rows, &doc); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating documents: %w", err)
+	}
+	return docs, nil
+}
+
+// UpdateDocumentSource は取り込み元の識別子と更新日時を更新する
+// 取り込み元が更新されたものの内容が変わっていない場合に、次回の同期で再度比較しないよう記録する
+func (h *DBHandler) UpdateDocumentSource(ctx context.Context, documentID int64, version string, modifiedAt time.Time) error {
+	result, err := h.DB.ExecContext(ctx, `
+        UPDATE documents SET source_version = $2, source_modified_at = $3
+        WHERE id = $1
+    `, documentID, version, modifiedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update document source: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update document source: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w with id %d", ErrDocumentNotFound, documentID)
+	}
+	return nil
+}
+
+// UpdateDocumentClassification はドキュメント (1つの版) のカテゴリとタグを置き換える
+func (h *DBHandler) UpdateDocumentClassification(ctx context.Context, documentID int64, category string, tags []string) error {
+	result, err := h.DB.ExecContext(ctx, `
+        UPDATE documents SET category = $2, tags = $3
+        WHERE id = $1
+    `, documentID, nullString(category), tagArray(tags))
+	if err != nil {
+		return fmt.Errorf("failed to update document classification: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update document classification: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w with id %d", ErrDocumentNotFound, documentID)
+	}
+	return nil
+}
+
+// tagArray はタグを TEXT[] として保存する (タグがない場合は空の配列)
+func tagArray(tags []string) pq.StringArray {
+	if tags == nil {
+		return pq.StringArray{}
+	}
+	return pq.StringArray(tags)
+}
+
+// nullString は空文字列を NULL として保存する
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
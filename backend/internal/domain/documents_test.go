@@ -0,0 +1,441 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// documentRowColumns は documentColumns に対応するテスト用の列名
+var documentRowColumns = []string{"id", "logical_id", "version", "is_latest", "filename", "s3_key", "content_hash", "duplicate_of", "created_at",
+	"source_uri", "source_version", "source_modified_at", "category", "tags"}
+
+func TestCreateDocument(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("正常系: 新しい論理ドキュメントの最初の版とする", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		duplicateOf := int64(3)
+		doc := &Document{
+			Filename: "leave.txt", S3Key: "documents/others/leave.txt", Content: "本文", ContentHash: "abc", SimHash: -42, DuplicateOf: &duplicateOf,
+			SourceURI: "s3://bucket/inbox/leave.txt", SourceVersion: "etag-1", SourceModifiedAt: &now,
+			Category: "policy", Tags: []string{"人事", "休暇"},
+		}
+		mock.ExpectQuery("WITH next AS .* INSERT INTO documents").
+			WithArgs("leave.txt", "documents/others/leave.txt", "本文", "abc", int64(-42), &duplicateOf, "s3://bucket/inbox/leave.txt", "etag-1", &now,
+				"policy", pq.StringArray{"人事", "休暇"}).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "logical_id", "version", "is_latest", "created_at"}).AddRow(int64(10), int64(10), 1, true, now))
+
+		err := h.CreateDocument(ctx, doc)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), doc.ID)
+		assert.Equal(t, int64(10), doc.LogicalID)
+		assert.Equal(t, 1, doc.Version)
+		assert.True(t, doc.IsLatest)
+		assert.Equal(t, now, doc.CreatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("正常系: 既存の論理ドキュメントの新しい版として追加する", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		doc := &Document{LogicalID: 10, Filename: "leave.txt", S3Key: "documents/others/leave-v3.txt", Content: "本文"}
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, version FROM documents").WithArgs(int64(10)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(int64(15), 2))
+		mock.ExpectExec("UPDATE documents SET is_latest = FALSE WHERE id = \\$1").WithArgs(int64(15)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("INSERT INTO documents").
+			WithArgs(int64(10), 3, "leave.txt", "documents/others/leave-v3.txt", "本文", sqlmock.AnyArg(), int64(0), sqlmock.AnyArg(), nil, nil, nil, nil, pq.StringArray{}).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "version", "is_latest", "created_at"}).AddRow(int64(20), 3, true, now))
+		mock.ExpectCommit()
+
+		err := h.CreateDocument(ctx, doc)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(20), doc.ID)
+		assert.Equal(t, 3, doc.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 論理ドキュメントが存在しない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, version FROM documents").WillReturnRows(sqlmock.NewRows([]string{"id", "version"}))
+		mock.ExpectRollback()
+
+		err := h.CreateDocument(ctx, &Document{LogicalID: 99, Filename: "leave.txt"})
+
+		assert.ErrorIs(t, err, ErrDocumentNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFindDocumentByContentHash(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 一致するドキュメントを返す", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("FROM documents").WithArgs("abc").
+			WillReturnRows(sqlmock.NewRows(documentRowColumns).
+				AddRow(int64(3), int64(1), 2, true, "leave.txt", "documents/others/leave.txt", "abc", nil, time.Now(), "", "", nil, "", "{}"))
+
+		doc, err := h.FindDocumentByContentHash(ctx, "abc")
+
+		require.NoError(t, err)
+		require.NotNil(t, doc)
+		assert.Equal(t, int64(3), doc.ID)
+		assert.Equal(t, int64(1), doc.LogicalID)
+		assert.Nil(t, doc.DuplicateOf)
+	})
+
+	t.Run("正常系: 該当なし", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("FROM documents").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		doc, err := h.FindDocumentByContentHash(ctx, "abc")
+
+		assert.NoError(t, err)
+		assert.Nil(t, doc)
+	})
+}
+
+func TestGetDocumentByID(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 取り込み元を含めて返す", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("FROM documents WHERE id = \\$1").WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows(documentRowColumns).
+				AddRow(int64(3), int64(3), 1, true, "leave.md", "k1", "abc", nil, time.Now(), "git+https://git.example.com/handbook.git#main:hr/leave.md", "0123abcd", nil, "policy", "{人事,休暇}"))
+
+		doc, err := h.GetDocumentByID(ctx, 3)
+
+		require.NoError(t, err)
+		assert.Equal(t, "git+https://git.example.com/handbook.git#main:hr/leave.md", doc.SourceURI)
+		assert.Equal(t, "0123abcd", doc.SourceVersion)
+		assert.Equal(t, "policy", doc.Category)
+		assert.Equal(t, []string{"人事", "休暇"}, doc.Tags)
+	})
+
+	t.Run("異常系: 存在しない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("FROM documents").WillReturnRows(sqlmock.NewRows(documentRowColumns))
+
+		_, err := h.GetDocumentByID(ctx, 3)
+
+		assert.ErrorIs(t, err, ErrDocumentNotFound)
+	})
+}
+
+func TestFindNearDuplicateDocuments(t *testing.T) {
+	ctx := context.Background()
+	h, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("simhash # \\$1").WithArgs(int64(42), 3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "logical_id", "filename", "distance"}).AddRow(int64(3), int64(1), "leave.txt", 2))
+
+	duplicates, err := h.FindNearDuplicateDocuments(ctx, 42, 3, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, []NearDuplicate{{DocumentID: 3, LogicalID: 1, Filename: "leave.txt", Distance: 2}}, duplicates)
+}
+
+func TestDeleteDocument(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 最新の版を削除した場合は1つ前の版を最新に戻す", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("DELETE FROM documents WHERE id = \\$1 RETURNING logical_id, is_latest").WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"logical_id", "is_latest"}).AddRow(int64(1), true))
+		mock.ExpectExec("UPDATE documents SET is_latest = TRUE").WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE FROM qa_answer_cache$").WithArgs().WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		err := h.DeleteDocument(ctx, 3)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("正常系: 過去の版の削除は最新の版を変えない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("DELETE FROM documents").WithArgs(int64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"logical_id", "is_latest"}).AddRow(int64(1), false))
+		mock.ExpectExec("DELETE FROM qa_answer_cache$").WithArgs().WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := h.DeleteDocument(ctx, 2)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: ドキュメントが存在しない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("DELETE FROM documents").WillReturnRows(sqlmock.NewRows([]string{"logical_id", "is_latest"}))
+		mock.ExpectRollback()
+
+		err := h.DeleteDocument(ctx, 3)
+
+		assert.ErrorIs(t, err, ErrDocumentNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 削除エラー", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		dbErr := errors.New("connection refused")
+		mock.ExpectBegin()
+		mock.ExpectQuery("DELETE FROM documents").WillReturnError(dbErr)
+		mock.ExpectRollback()
+
+		err := h.DeleteDocument(ctx, 3)
+
+		assert.ErrorIs(t, err, dbErr)
+	})
+}
+
+func TestListDocumentVersions(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("正常系: 版を古い順に返す", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("WHERE logical_id = \\$1\\s+ORDER BY version").WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(documentRowColumns).
+				AddRow(int64(1), int64(1), 1, false, "leave.txt", "k1", "h1", nil, now, "", "", nil, "", "{}").
+				AddRow(int64(5), int64(1), 2, true, "leave.txt", "k2", "h2", nil, now, "", "", nil, "", "{}"))
+
+		versions, err := h.ListDocumentVersions(ctx, 1)
+
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		assert.Equal(t, 2, versions[1].Version)
+		assert.True(t, versions[1].IsLatest)
+	})
+
+	t.Run("異常系: 論理ドキュメントが存在しない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("FROM documents").WillReturnRows(sqlmock.NewRows(documentRowColumns))
+
+		_, err := h.ListDocumentVersions(ctx, 1)
+
+		assert.ErrorIs(t, err, ErrDocumentNotFound)
+	})
+}
+
+func TestGetDocumentVersion(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 抽出したテキストを含めて返す", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("content FROM documents").WithArgs(int64(1), 0).
+			WillReturnRows(sqlmock.NewRows(append(documentRowColumns, "content")).
+				AddRow(int64(5), int64(1), 2, true, "leave.txt", "k2", "h2", int64(1), time.Now(), "", "", nil, "", "{}", "本文"))
+
+		doc, err := h.GetDocumentVersion(ctx, 1, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, "本文", doc.Content)
+		require.NotNil(t, doc.DuplicateOf)
+		assert.Equal(t, int64(1), *doc.DuplicateOf)
+	})
+
+	t.Run("異常系: 版が存在しない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("FROM documents").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err := h.GetDocumentVersion(ctx, 1, 9)
+
+		assert.ErrorIs(t, err, ErrDocumentNotFound)
+	})
+}
+
+func TestListDocuments(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 既定では最新の版をIDの順に返す", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("WHERE id > \\$1 AND \\(\\$2 OR is_latest\\)\\s+ORDER BY id\\s+LIMIT \\$3").WithArgs(int64(0), false, 100).
+			WillReturnRows(sqlmock.NewRows(documentRowColumns).
+				AddRow(int64(5), int64(1), 2, true, "leave.txt", "k2", "h2", nil, time.Now(), "", "", nil, "", "{}").
+				AddRow(int64(6), int64(6), 1, true, "expense.txt", "k3", "h3", nil, time.Now(), "", "", nil, "", "{}"))
+
+		docs, err := h.ListDocuments(ctx, DocumentListFilter{})
+
+		require.NoError(t, err)
+		require.Len(t, docs, 2)
+		assert.Equal(t, int64(5), docs[0].ID)
+		assert.Equal(t, "expense.txt", docs[1].Filename)
+	})
+
+	t.Run("正常系: 過去の版を含めてページングする", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("FROM documents").WithArgs(int64(5), true, 10).WillReturnRows(sqlmock.NewRows(documentRowColumns))
+
+		docs, err := h.ListDocuments(ctx, DocumentListFilter{AfterID: 5, Limit: 10, AllVersions: true})
+
+		require.NoError(t, err)
+		assert.Empty(t, docs)
+	})
+
+	t.Run("異常系: 取得エラー", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("FROM documents").WillReturnError(errors.New("connection refused"))
+
+		_, err := h.ListDocuments(ctx, DocumentListFilter{})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestListDocumentsBySource(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 取り込み元の範囲のドキュメントを返す", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		modifiedAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+		mock.ExpectQuery("WHERE source_uri IS NOT NULL.*ORDER BY source_uri COLLATE \"C\", id").
+			WithArgs("s3://bucket/inbox/", "s3://bucket/inbox/a.txt", "s3://bucket/inbox/m.txt").
+			WillReturnRows(sqlmock.NewRows(documentRowColumns).
+				AddRow(int64(7), int64(7), 1, true, "b.txt", "documents/others/b.txt", "h1", nil, time.Now(), "s3://bucket/inbox/b.txt", "etag-1", modifiedAt, "", "{}"))
+
+		docs, err := h.ListDocumentsBySource(ctx, SourceRange{Prefix: "s3://bucket/inbox/", After: "s3://bucket/inbox/a.txt", Through: "s3://bucket/inbox/m.txt"})
+
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+		assert.Equal(t, "s3://bucket/inbox/b.txt", docs[0].SourceURI)
+		assert.Equal(t, "etag-1", docs[0].SourceVersion)
+		require.NotNil(t, docs[0].SourceModifiedAt)
+		assert.Equal(t, modifiedAt, *docs[0].SourceModifiedAt)
+	})
+
+	t.Run("異常系: 取得エラー", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("FROM documents").WillReturnError(errors.New("connection refused"))
+
+		_, err := h.ListDocumentsBySource(ctx, SourceRange{Prefix: "s3://bucket/inbox/"})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestUpdateDocumentSource(t *testing.T) {
+	ctx := context.Background()
+	modifiedAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("正常系: 取り込み元の識別子と更新日時を更新する", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("UPDATE documents SET source_version").WithArgs(int64(7), "etag-2", modifiedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := h.UpdateDocumentSource(ctx, 7, "etag-2", modifiedAt)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: ドキュメントが存在しない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("UPDATE documents SET source_version").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := h.UpdateDocumentSource(ctx, 99, "etag-2", modifiedAt)
+
+		assert.ErrorIs(t, err, ErrDocumentNotFound)
+	})
+}
+
+func TestUpdateDocumentClassification(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: カテゴリとタグを更新する", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("UPDATE documents SET category = \\$2, tags = \\$3").WithArgs(int64(7), "invoice", pq.StringArray{"経理"}).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := h.UpdateDocumentClassification(ctx, 7, "invoice", []string{"経理"})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("正常系: 空のカテゴリとタグで分類を外す", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("UPDATE documents SET category").WithArgs(int64(7), nil, pq.StringArray{}).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := h.UpdateDocumentClassification(ctx, 7, "", nil)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: ドキュメントが存在しない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("UPDATE documents SET category").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := h.UpdateDocumentClassification(ctx, 99, "invoice", nil)
+
+		assert.ErrorIs(t, err, ErrDocumentNotFound)
+	})
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
+)
+
+// CachedEmbedding はテキスト1件分のキャッシュ済みEmbedding
+type CachedEmbedding struct {
+	TextHash  string // 正規化したテキストのSHA-256 (16進数)
+	Embedding []float32
+}
+
+// GetCachedEmbeddings は key のキャッシュから hashes に一致するEmbeddingを返す (テキストのハッシュ → Embedding)
+func (h *DBHandler) GetCachedEmbeddings(ctx context.Context, key string, hashes []string) (map[string][]float32, error) {
+	rows, err := h.DB.QueryContext(ctx, `
+        SELECT text_hash, embedding FROM embedding_cache
+        WHERE cache_key = $1 AND text_hash = ANY($2)
+    `, key, pq.Array(hashes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cached embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	embeddings := make(map[string][]float32, len(hashes))
+	for rows.Next() {
+		var hash string
+		var vec pgvector.Vector
+		if err := rows.Scan(&hash, &vec); err != nil {
+			return nil, fmt.Errorf("failed to scan cached embedding: %w", err)
+		}
+		embeddings[hash] = vec.Slice()
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cached embeddings: %w", err)
+	}
+	return embeddings, nil
+}
+
+// SaveCachedEmbeddings は key のキャッシュにEmbeddingをまとめて保存する
+// 同じテキストのEmbeddingは同じ値になるため、保存済みのものはそのまま残す
+func (h *DBHandler) SaveCachedEmbeddings(ctx context.Context, key string, entries []CachedEmbedding) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`INSERT INTO embedding_cache (cache_key, text_hash, dimension, embedding) VALUES `)
+	args := make([]any, 0, len(entries)*4)
+	for i, entry := range entries {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		n := len(args)
+		fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4)
+		args = append(args, key, entry.TextHash, len(entry.Embedding), pgvector.NewVector(entry.Embedding))
+	}
+	sb.WriteString(` ON CONFLICT (cache_key, text_hash) DO NOTHING`)
+
+	if _, err := h.DB.ExecContext(ctx, sb.String(), args...); err != nil {
+		return fmt.Errorf("failed to save cached embeddings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCachedEmbeddings(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("FROM embedding_cache").
+			WithArgs("titan-v2-256/document", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"text_hash", "embedding"}).AddRow("hash-a", "[0.1,0.2]"))
+
+		embeddings, err := h.GetCachedEmbeddings(ctx, "titan-v2-256/document", []string{"hash-a", "hash-b"})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]float32{"hash-a": {0.1, 0.2}}, embeddings)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 検索エラー", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		dbErr := errors.New("connection refused")
+		mock.ExpectQuery("FROM embedding_cache").WillReturnError(dbErr)
+
+		_, err := h.GetCachedEmbeddings(ctx, "titan-v2-256/document", []string{"hash-a"})
+
+		assert.ErrorIs(t, err, dbErr)
+	})
+}
+
+func TestSaveCachedEmbeddings(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: まとめて保存し、保存済みのものは残す", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec(`INSERT INTO embedding_cache \(cache_key, text_hash, dimension, embedding\) VALUES \(\$1, \$2, \$3, \$4\), \(\$5, \$6, \$7, \$8\) ON CONFLICT \(cache_key, text_hash\) DO NOTHING`).
+			WithArgs("titan-v2-256/document", "hash-a", 2, sqlmock.AnyArg(), "titan-v2-256/document", "hash-b", 2, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		err := h.SaveCachedEmbeddings(ctx, "titan-v2-256/document", []CachedEmbedding{
+			{TextHash: "hash-a", Embedding: []float32{0.1, 0.2}},
+			{TextHash: "hash-b", Embedding: []float32{0.3, 0.4}},
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("正常系: 空の場合は何もしない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		assert.NoError(t, h.SaveCachedEmbeddings(ctx, "titan-v2-256/document", nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
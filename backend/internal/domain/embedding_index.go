@@ -0,0 +1,534 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go" // pgvector
+)
+
+// 再インデックスジョブの状態
+const (
+	ReindexStatusRunning   = "running"   // 新しいモデルのEmbeddingを生成中
+	ReindexStatusFailed    = "failed"    // 生成中にエラーで停止した (再開可能)
+	ReindexStatusReady     = "ready"     // 全チャンクの生成が完了し、切り替え可能
+	ReindexStatusActivated = "activated" // 検索を新しいモデルに切り替え済み (旧Embeddingを削除するまでロールバック可能)
+	ReindexStatusFinalized = "finalized" // 旧Embeddingを削除した (ロールバック不可)
+	ReindexStatusCancelled = "cancelled" // 切り替えずに中止した
+)
+
+var (
+	// ErrReindexJobNotFound は対象の再インデックスジョブが存在しない場合のエラー
+	ErrReindexJobNotFound = errors.New("再インデックスジョブが見つかりません")
+	// ErrReindexInvalidState はジョブの状態が操作と合わない場合のエラー
+	ErrReindexInvalidState = errors.New("再インデックスジョブの状態がこの操作に対応していません")
+	// ErrReindexIncomplete は切り替え先のEmbeddingが全チャンク分揃っていない場合のエラー
+	ErrReindexIncomplete = errors.New("切り替え先のEmbeddingが全チャンク分揃っていません")
+	// ErrReindexLeaseLost は別のプロセスがジョブを引き継いだか、ジョブが中止された場合のエラー
+	ErrReindexLeaseLost = errors.New("再インデックスジョブの実行権を失いました")
+)
+
+// EmbeddingIndexState は検索に使用しているEmbeddingモデルの状態
+type EmbeddingIndexState struct {
+	Active   EmbeddingSpace  // document_chunks.embedding のモデル (検索に使用する)
+	Previous *EmbeddingSpace // 切り替え前のモデル (ロールバック用に旧Embeddingが残っている場合のみ)
+	Pending  *EmbeddingSpace // 生成中・切り替え待ちのモデル (再インデックス中の場合のみ)
+}
+
+// Secondary はインジェスト時に検索用と並行してEmbeddingを書き込むべきモデルを返す
+// 再インデックス中は切り替え先、切り替え後は旧モデルにも書き込み、途中で追加された文書も切り替え・ロールバックできるようにする
+func (s *EmbeddingIndexState) Secondary() *EmbeddingSpace {
+	if s.Pending != nil {
+		return s.Pending
+	}
+	return s.Previous
+}
+
+// ReindexJob はEmbeddingモデルを切り替えるための再インデックスジョブ
+type ReindexJob struct {
+	ID          int64     `json:"id"`
+	TargetModel string    `json:"target_model"`
+	Dimension   int       `json:"dimension"`
+	Status      string    `json:"status"`
+	LastChunkID int64     `json:"last_chunk_id"` // 処理済みの最大チャンクID (再開位置)
+	Processed   int       `json:"processed"`
+	Error       string    `json:"error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Target はジョブの切り替え先のモデルを返す
+func (j *ReindexJob) Target() EmbeddingSpace {
+	return EmbeddingSpace{Model: j.TargetModel, Dimension: j.Dimension}
+}
+
+// ReindexCoverage は切り替え先のEmbeddingが生成済みのチャンク数
+type ReindexCoverage struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+}
+
+// Complete は全チャンクのEmbeddingが揃っているかを返す
+func (c ReindexCoverage) Complete() bool {
+	return c.Covered >= c.Total
+}
+
+// ShadowEmbedding は検索には使用しない、切り替え用のチャンクのEmbedding
+type ShadowEmbedding struct {
+	DocumentID int64
+	ChunkIndex int
+	Embedding  []float32
+}
+
+// ShadowEmbeddingSet はインジェスト時に検索用以外のモデル (切り替え先・切り戻し先) で生成したEmbedding
+type ShadowEmbeddingSet struct {
+	Space      EmbeddingSpace
+	Embeddings []ShadowEmbedding
+}
+
+// openReindexStatuses は終了していないジョブの状態 (同時に1件のみ存在できる)
+const openReindexStatuses = `('running', 'failed', 'ready', 'activated')`
+
+// GetEmbeddingIndexState は検索に使用しているEmbeddingモデルの状態を取得する
+// まだ記録がない場合は initial を検索用のモデルとして記録する
+func (h *DBHandler) GetEmbeddingIndexState(ctx context.Context, initial EmbeddingSpace) (*EmbeddingIndexState, error) {
+	if _, err := h.DB.ExecContext(ctx, `
+        INSERT INTO embedding_index_state (id, active_model, active_dimension)
+        VALUES (TRUE, $1, $2)
+        ON CONFLICT (id) DO NOTHING
+    `, initial.Model, initial.Dimension); err != nil {
+		return nil, fmt.Errorf("failed to initialize embedding index state: %w", err)
+	}
+
+	var (
+		state                               EmbeddingIndexState
+		previousModel, pendingModel         sql.NullString
+		previousDimension, pendingDimension sql.NullInt64
+	)
+	err := h.DB.QueryRowContext(ctx, `
+        SELECT s.active_model, s.active_dimension, s.previous_model, s.previous_dimension,
+               j.target_model, j.target_dimension
+        FROM embedding_index_state s
+        LEFT JOIN reindex_jobs j ON j.status IN ('running', 'failed', 'ready')
+        WHERE s.id
+    `).Scan(&state.Active.Model, &state.Active.Dimension, &previousModel, &previousDimension, &pendingModel, &pendingDimension)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding index state: %w", err)
+	}
+	if previousModel.Valid {
+		state.Previous = &EmbeddingSpace{Model: previousModel.String, Dimension: int(previousDimension.Int64)}
+	}
+	if pendingModel.Valid {
+		state.Pending = &EmbeddingSpace{Model: pendingModel.String, Dimension: int(pendingDimension.Int64)}
+	}
+	return &state, nil
+}
+
+// CreateReindexJob は新しい再インデックスジョブを作成する
+// 終了していないジョブが既にある場合は ErrReindexInvalidState を返す
+func (h *DBHandler) CreateReindexJob(ctx context.Context, target EmbeddingSpace) (*ReindexJob, error) {
+	job, err := scanReindexJob(h.DB.QueryRowContext(ctx, `
+        INSERT INTO reindex_jobs (target_model, target_dimension, status)
+        SELECT $1, $2, 'running'
+        WHERE NOT EXISTS (SELECT 1 FROM reindex_jobs WHERE status IN `+openReindexStatuses+`)
+        RETURNING `+reindexJobColumns,
+		target.Model, target.Dimension))
+	if errors.Is(err, ErrReindexJobNotFound) {
+		return nil, fmt.Errorf("%w: another reindex job is in progress", ErrReindexInvalidState)
+	}
+	return job, err
+}
+
+// FindOpenReindexJob は終了していない再インデックスジョブを取得する (存在しない場合は nil)
+func (h *DBHandler) FindOpenReindexJob(ctx context.Context) (*ReindexJob, error) {
+	job, err := scanReindexJob(h.DB.QueryRowContext(ctx, `
+        SELECT `+reindexJobColumns+` FROM reindex_jobs
+        WHERE status IN `+openReindexStatuses+`
+        ORDER BY id DESC
+        LIMIT 1
+    `))
+	if errors.Is(err, ErrReindexJobNotFound) {
+		return nil, nil
+	}
+	return job, err
+}
+
+// ClaimReindexJob はジョブの実行権 (リース) を取得し、状態を running にする
+// 他のプロセスが有効なリースを保持している場合は false を返す
+func (h *DBHandler) ClaimReindexJob(ctx context.Context, jobID int64, owner string, ttl time.Duration) (bool, error) {
+	result, err := h.DB.ExecContext(ctx, `
+        UPDATE reindex_jobs
+        SET status = 'running', error = NULL, lease_owner = $2,
+            lease_until = NOW() + make_interval(secs => $3), updated_at = NOW()
+        WHERE id = $1 AND status IN ('running', 'failed')
+          AND (lease_owner IS NULL OR lease_owner = $2 OR lease_until < NOW())
+    `, jobID, owner, ttl.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("failed to claim reindex job %d: %w", jobID, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim reindex job %d: %w", jobID, err)
+	}
+	return n > 0, nil
+}
+
+// ListChunksForReindex は切り替え先のEmbeddingが未生成のチャンクをID順に取得する
+func (h *DBHandler) ListChunksForReindex(ctx context.Context, target EmbeddingSpace, afterChunkID int64, limit int) ([]DocumentChunk, error) {
+	rows, err := h.DB.QueryContext(ctx, `
+        SELECT c.id, c.document_id, c.chunk_index, c.content
+        FROM document_chunks c
+        WHERE c.id > $1
+          AND NOT EXISTS (
+            SELECT 1 FROM document_chunk_embeddings s
+            WHERE s.document_id = c.document_id AND s.chunk_index = c.chunk_index AND s.embedding_model = $2
+          )
+        ORDER BY c.id
+        LIMIT $3
+    `, afterChunkID, target.Model, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks for reindex: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []DocumentChunk
+	for rows.Next() {
+		var chunk DocumentChunk
+		if err := rows.Scan(&chunk.ID, &chunk.DocumentID, &chunk.ChunkIndex, &chunk.Content); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk row: %w", err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+	return chunks, nil
+}
+
+// SaveReindexBatch は切り替え先のEmbeddingと処理位置を1トランザクションで保存する
+// 処理位置と書き込み済みのEmbeddingが常に一致するため、クラッシュ後も lastChunkID から再開できる
+func (h *DBHandler) SaveReindexBatch(ctx context.Context, job *ReindexJob, owner string, ttl time.Duration, embeddings []ShadowEmbedding, lastChunkID int64) (err error) {
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// リースを延長しつつ、中止や他プロセスへの引き継ぎが起きていないことを確認する
+	result, err := tx.ExecContext(ctx, `
+        UPDATE reindex_jobs
+        SET last_chunk_id = $3, processed = processed + $4,
+            lease_until = NOW() + make_interval(secs => $5), updated_at = NOW()
+        WHERE id = $1 AND status = 'running' AND lease_owner = $2
+    `, job.ID, owner, lastChunkID, len(embeddings), ttl.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to update reindex job %d: %w", job.ID, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrReindexLeaseLost
+	}
+
+	if err = upsertShadowEmbeddings(ctx, tx, job.Target(), embeddings); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reindex batch: %w", err)
+	}
+	return nil
+}
+
+// upsertShadowEmbeddings は切り替え用のEmbeddingを書き込む (同じチャンク・モデルの既存の行は上書きする)
+func upsertShadowEmbeddings(ctx context.Context, tx *sql.Tx, space EmbeddingSpace, embeddings []ShadowEmbedding) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+	stmt, err := tx.PrepareContext(ctx, `
+        INSERT INTO document_chunk_embeddings (document_id, chunk_index, embedding_model, embedding_dimension, embedding)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (document_id, chunk_index, embedding_model)
+        DO UPDATE SET embedding = EXCLUDED.embedding, embedding_dimension = EXCLUDED.embedding_dimension
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to prepare shadow embedding upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, e := range embeddings {
+		if len(e.Embedding) != space.Dimension {
+			return fmt.Errorf("%w: chunk %d of document %d has %d dimensions, model %s expects %d", ErrEmbeddingDimensionMismatch, e.ChunkIndex, e.DocumentID, len(e.Embedding), space.Model, space.Dimension)
+		}
+		if _, err := stmt.ExecContext(ctx, e.DocumentID, e.ChunkIndex, space.Model, space.Dimension, pgvector.NewVector(e.Embedding)); err != nil {
+			return fmt.Errorf("failed to upsert shadow embedding (document: %d, chunk: %d): %w", e.DocumentID, e.ChunkIndex, err)
+		}
+	}
+	return nil
+}
+
+// GetReindexCoverage は全チャンクのうち space のEmbeddingが存在するチャンク数を返す
+func (h *DBHandler) GetReindexCoverage(ctx context.Context, space EmbeddingSpace) (ReindexCoverage, error) {
+	return getCoverage(ctx, h.DB, space)
+}
+
+// queryRower は *sql.DB と *sql.Tx の共通部分
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func getCoverage(ctx context.Context, q queryRower, space EmbeddingSpace) (ReindexCoverage, error) {
+	var coverage ReindexCoverage
+	err := q.QueryRowContext(ctx, `
+        SELECT COUNT(*), COUNT(s.document_id)
+        FROM document_chunks c
+        LEFT JOIN document_chunk_embeddings s
+          ON s.document_id = c.document_id AND s.chunk_index = c.chunk_index AND s.embedding_model = $1
+    `, space.Model).Scan(&coverage.Total, &coverage.Covered)
+	if err != nil {
+		return ReindexCoverage{}, fmt.Errorf("failed to count reindex coverage: %w", err)
+	}
+	return coverage, nil
+}
+
+// UpdateReindexJobStatus はジョブの状態を from から to に変更する
+// 状態が from のいずれでもない場合は ErrReindexInvalidState を返す
+func (h *DBHandler) UpdateReindexJobStatus(ctx context.Context, jobID int64, from []string, to string, message string) error {
+	result, err := h.DB.ExecContext(ctx, `
+        UPDATE reindex_jobs
+        SET status = $2, error = NULLIF($3, ''), lease_owner = NULL, lease_until = NULL, updated_at = NOW()
+        WHERE id = $1 AND status = ANY($4)
+    `, jobID, to, message, pq.Array(from))
+	if err != nil {
+		return fmt.Errorf("failed to update reindex job %d: %w", jobID, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w (job: %d, to: %s)", ErrReindexInvalidState, jobID, to)
+	}
+	return nil
+}
+
+// ActivateReindexJob は検索に使用するEmbeddingを切り替え先のモデルに入れ替える
+// 全チャンク分揃っていない場合は ErrReindexIncomplete を返し、何も変更しない
+// 旧モデルのEmbeddingは FinalizeReindexJob まで残し、RollbackReindexJob で戻せるようにする
+func (h *DBHandler) ActivateReindexJob(ctx context.Context, jobID int64) error {
+	return h.swapEmbeddings(ctx, jobID, ReindexStatusReady, ReindexStatusActivated, func(job *ReindexJob, _ *EmbeddingIndexState) (EmbeddingSpace, error) {
+		return job.Target(), nil
+	})
+}
+
+// RollbackReindexJob は検索に使用するEmbeddingを切り替え前のモデルに戻す
+// 切り替え先のEmbeddingは残るため、再度 ActivateReindexJob で切り替えられる
+func (h *DBHandler) RollbackReindexJob(ctx context.Context, jobID int64) error {
+	return h.swapEmbeddings(ctx, jobID, ReindexStatusActivated, ReindexStatusReady, func(_ *ReindexJob, state *EmbeddingIndexState) (EmbeddingSpace, error) {
+		if state.Previous == nil {
+			return EmbeddingSpace{}, fmt.Errorf("%w: previous embeddings have already been dropped", ErrReindexInvalidState)
+		}
+		return *state.Previous, nil
+	})
+}
+
+// swapEmbeddings は document_chunks.embedding と切り替え用テーブルのEmbeddingを1トランザクションで入れ替える
+// 入れ替え中はチャンクの書き込みをロックし、途中でインジェストされたチャンクが取り残されないようにする
+func (h *DBHandler) swapEmbeddings(ctx context.Context, jobID int64, from, to string, next func(job *ReindexJob, state *EmbeddingIndexState) (EmbeddingSpace, error)) (err error) {
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `LOCK TABLE document_chunks IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("failed to lock document chunks: %w", err)
+	}
+
+	job, err := scanReindexJob(tx.QueryRowContext(ctx, `SELECT `+reindexJobColumns+` FROM reindex_jobs WHERE id = $1 FOR UPDATE`, jobID))
+	if err != nil {
+		return err
+	}
+	if job.Status != from {
+		return fmt.Errorf("%w (job: %d, status: %s)", ErrReindexInvalidState, jobID, job.Status)
+	}
+
+	var (
+		state             EmbeddingIndexState
+		previousModel     sql.NullString
+		previousDimension sql.NullInt64
+	)
+	if err = tx.QueryRowContext(ctx, `
+        SELECT active_model, active_dimension, previous_model, previous_dimension
+        FROM embedding_index_state WHERE id FOR UPDATE
+    `).Scan(&state.Active.Model, &state.Active.Dimension, &previousModel, &previousDimension); err != nil {
+		return fmt.Errorf("failed to get embedding index state: %w", err)
+	}
+	if previousModel.Valid {
+		state.Previous = &EmbeddingSpace{Model: previousModel.String, Dimension: int(previousDimension.Int64)}
+	}
+
+	target, err := next(job, &state)
+	if err != nil {
+		return err
+	}
+
+	coverage, err := getCoverage(ctx, tx, target)
+	if err != nil {
+		return err
+	}
+	if !coverage.Complete() {
+		return fmt.Errorf("%w (%s: %d/%d)", ErrReindexIncomplete, target.Model, coverage.Covered, coverage.Total)
+	}
+
+	statements := []struct {
+		query string
+		args  []any
+		desc  string
+	}{
+		{
+			// 現在のEmbeddingを切り替え用テーブルに退避する
+			query: `
+                INSERT INTO document_chunk_embeddings (document_id, chunk_index, embedding_model, embedding_dimension, embedding)
+                SELECT document_id, chunk_index, embedding_model, embedding_dimension, embedding FROM document_chunks
+                ON CONFLICT (document_id, chunk_index, embedding_model)
+                DO UPDATE SET embedding = EXCLUDED.embedding, embedding_dimension = EXCLUDED.embedding_dimension
+            `,
+			desc: "back up active embeddings",
+		},
+		{
+			query: `
+                UPDATE document_chunks c
+                SET embedding = s.embedding, embedding_model = s.embedding_model, embedding_dimension = s.embedding_dimension
+                FROM document_chunk_embeddings s
+                WHERE s.document_id = c.document_id AND s.chunk_index = c.chunk_index AND s.embedding_model = $1
+            `,
+			args: []any{target.Model},
+			desc: "swap embeddings",
+		},
+		{
+			// 検索用の列に移したEmbeddingは切り替え用テーブルから削除する
+			query: `DELETE FROM document_chunk_embeddings WHERE embedding_model = $1`,
+			args:  []any{target.Model},
+			desc:  "delete swapped shadow embeddings",
+		},
+		{
+			query: `
+                UPDATE embedding_index_state
+                SET active_model = $1, active_dimension = $2, previous_model = $3, previous_dimension = $4, updated_at = NOW()
+                WHERE id
+            `,
+			args: []any{target.Model, target.Dimension, state.Active.Model, state.Active.Dimension},
+			desc: "update embedding index state",
+		},
+		{
+			query: `UPDATE reindex_jobs SET status = $2, updated_at = NOW() WHERE id = $1`,
+			args:  []any{jobID, to},
+			desc:  "update reindex job status",
+		},
+	}
+	for _, s := range statements {
+		if _, err = tx.ExecContext(ctx, s.query, s.args...); err != nil {
+			return fmt.Errorf("failed to %s: %w", s.desc, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit embedding swap: %w", err)
+	}
+	return nil
+}
+
+// FinalizeReindexJob は切り替え前のモデルのEmbeddingを削除し、ジョブを完了する (以降はロールバックできない)
+func (h *DBHandler) FinalizeReindexJob(ctx context.Context, jobID int64) error {
+	return h.closeReindexJob(ctx, jobID, []string{ReindexStatusActivated}, ReindexStatusFinalized, func(ctx context.Context, tx *sql.Tx, _ *ReindexJob) error {
+		if _, err := tx.ExecContext(ctx, `
+            DELETE FROM document_chunk_embeddings
+            WHERE embedding_model = (SELECT previous_model FROM embedding_index_state WHERE id)
+        `); err != nil {
+			return fmt.Errorf("failed to drop previous embeddings: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE embedding_index_state SET previous_model = NULL, previous_dimension = NULL, updated_at = NOW() WHERE id
+        `); err != nil {
+			return fmt.Errorf("failed to update embedding index state: %w", err)
+		}
+		return nil
+	})
+}
+
+// CancelReindexJob は切り替え前のジョブを中止し、生成済みの切り替え先のEmbeddingを削除する
+func (h *DBHandler) CancelReindexJob(ctx context.Context, jobID int64) error {
+	from := []string{ReindexStatusRunning, ReindexStatusFailed, ReindexStatusReady}
+	return h.closeReindexJob(ctx, jobID, from, ReindexStatusCancelled, func(ctx context.Context, tx *sql.Tx, job *ReindexJob) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM document_chunk_embeddings WHERE embedding_model = $1`, job.TargetModel); err != nil {
+			return fmt.Errorf("failed to drop target embeddings: %w", err)
+		}
+		// ロールバック後に中止した場合、切り替え先は切り替え前のモデルとして記録されている
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE embedding_index_state SET previous_model = NULL, previous_dimension = NULL, updated_at = NOW()
+            WHERE id AND previous_model = $1
+        `, job.TargetModel); err != nil {
+			return fmt.Errorf("failed to update embedding index state: %w", err)
+		}
+		return nil
+	})
+}
+
+// closeReindexJob はジョブを終了状態にし、cleanup で不要になった切り替え用のEmbeddingを削除する
+func (h *DBHandler) closeReindexJob(ctx context.Context, jobID int64, from []string, to string, cleanup func(ctx context.Context, tx *sql.Tx, job *ReindexJob) error) (err error) {
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	job, err := scanReindexJob(tx.QueryRowContext(ctx, `SELECT `+reindexJobColumns+` FROM reindex_jobs WHERE id = $1 FOR UPDATE`, jobID))
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(from, job.Status) {
+		return fmt.Errorf("%w (job: %d, status: %s)", ErrReindexInvalidState, jobID, job.Status)
+	}
+
+	if err = cleanup(ctx, tx, job); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `
+        UPDATE reindex_jobs SET status = $2, lease_owner = NULL, lease_until = NULL, updated_at = NOW() WHERE id = $1
+    `, jobID, to); err != nil {
+		return fmt.Errorf("failed to update reindex job %d: %w", jobID, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reindex job %d: %w", jobID, err)
+	}
+	return nil
+}
+
+// reindexJobColumns は ReindexJob の読み込みに使用する列
+const reindexJobColumns = `id, target_model, target_dimension, status, last_chunk_id, processed, COALESCE(error, ''), created_at, updated_at`
+
+// scanReindexJob は reindexJobColumns の順で1行を読み込む
+func scanReindexJob(row *sql.Row) (*ReindexJob, error) {
+	var job ReindexJob
+	err := row.Scan(&job.ID, &job.TargetModel, &job.Dimension, &job.Status, &job.LastChunkID, &job.Processed, &job.Error, &job.CreatedAt, &job.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReindexJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan reindex job: %w", err)
+	}
+	return &job, nil
+}
@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var reindexJobRowColumns = []string{"id", "target_model", "target_dimension", "status", "last_chunk_id", "processed", "error", "created_at", "updated_at"}
+
+func TestGetEmbeddingIndexState(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 再インデックス中は切り替え先を並行書き込みの対象とする", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("INSERT INTO embedding_index_state").
+			WithArgs("titan-v1", 1536).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("FROM embedding_index_state").
+			WillReturnRows(sqlmock.NewRows([]string{"active_model", "active_dimension", "previous_model", "previous_dimension", "target_model", "target_dimension"}).
+				AddRow("titan-v1", 1536, nil, nil, "titan-v2-256", 256))
+
+		state, err := h.GetEmbeddingIndexState(ctx, EmbeddingSpace{Model: "titan-v1", Dimension: 1536})
+
+		require.NoError(t, err)
+		assert.Equal(t, EmbeddingSpace{Model: "titan-v1", Dimension: 1536}, state.Active)
+		assert.Nil(t, state.Previous)
+		assert.Equal(t, &EmbeddingSpace{Model: "titan-v2-256", Dimension: 256}, state.Secondary())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("正常系: 切り替え後は旧モデルを並行書き込みの対象とする", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("INSERT INTO embedding_index_state").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("FROM embedding_index_state").
+			WillReturnRows(sqlmock.NewRows([]string{"active_model", "active_dimension", "previous_model", "previous_dimension", "target_model", "target_dimension"}).
+				AddRow("titan-v2-256", 256, "titan-v1", 1536, nil, nil))
+
+		state, err := h.GetEmbeddingIndexState(ctx, EmbeddingSpace{Model: "titan-v1", Dimension: 1536})
+
+		require.NoError(t, err)
+		assert.Equal(t, "titan-v2-256", state.Active.Model)
+		assert.Equal(t, &EmbeddingSpace{Model: "titan-v1", Dimension: 1536}, state.Secondary())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSaveReindexBatch(t *testing.T) {
+	ctx := context.Background()
+	job := &ReindexJob{ID: 7, TargetModel: "titan-v2-256", Dimension: 2}
+	embeddings := []ShadowEmbedding{{DocumentID: 1, ChunkIndex: 0, Embedding: []float32{0.1, 0.2}}}
+
+	t.Run("正常系: 処理位置とEmbeddingを同じトランザクションで保存する", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE reindex_jobs").
+			WithArgs(int64(7), "owner", int64(42), 1, float64(60)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		prep := mock.ExpectPrepare("INSERT INTO document_chunk_embeddings")
+		prep.ExpectExec().WithArgs(int64(1), 0, "titan-v2-256", 2, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := h.SaveReindexBatch(ctx, job, "owner", time.Minute, embeddings, 42)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 実行権を失った場合は書き込まない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE reindex_jobs").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := h.SaveReindexBatch(ctx, job, "owner", time.Minute, embeddings, 42)
+
+		assert.ErrorIs(t, err, ErrReindexLeaseLost)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestActivateReindexJob(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	expectSwapPrelude := func(mock sqlmock.Sqlmock, status string) {
+		mock.ExpectBegin()
+		mock.ExpectExec("LOCK TABLE document_chunks").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("FROM reindex_jobs WHERE id = \\$1 FOR UPDATE").
+			WithArgs(int64(7)).
+			WillReturnRows(sqlmock.NewRows(reindexJobRowColumns).AddRow(int64(7), "titan-v2-256", 256, status, int64(0), 0, "", now, now))
+	}
+
+	t.Run("正常系: 全チャンク分揃っている場合は検索用のEmbeddingを入れ替える", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		expectSwapPrelude(mock, ReindexStatusReady)
+		mock.ExpectQuery("FROM embedding_index_state").
+			WillReturnRows(sqlmock.NewRows([]string{"active_model", "active_dimension", "previous_model", "previous_dimension"}).
+				AddRow("titan-v1", 1536, nil, nil))
+		mock.ExpectQuery("SELECT COUNT").WithArgs("titan-v2-256").
+			WillReturnRows(sqlmock.NewRows([]string{"total", "covered"}).AddRow(10, 10))
+		mock.ExpectExec("INSERT INTO document_chunk_embeddings").WillReturnResult(sqlmock.NewResult(0, 10))
+		mock.ExpectExec("UPDATE document_chunks").WithArgs("titan-v2-256").WillReturnResult(sqlmock.NewResult(0, 10))
+		mock.ExpectExec("DELETE FROM document_chunk_embeddings").WithArgs("titan-v2-256").WillReturnResult(sqlmock.NewResult(0, 10))
+		mock.ExpectExec("UPDATE embedding_index_state").
+			WithArgs("titan-v2-256", 256, "titan-v1", 1536).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE reindex_jobs").WithArgs(int64(7), ReindexStatusActivated).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := h.ActivateReindexJob(ctx, 7)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 未生成のチャンクが残っている場合は切り替えない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		expectSwapPrelude(mock, ReindexStatusReady)
+		mock.ExpectQuery("FROM embedding_index_state").
+			WillReturnRows(sqlmock.NewRows([]string{"active_model", "active_dimension", "previous_model", "previous_dimension"}).
+				AddRow("titan-v1", 1536, nil, nil))
+		mock.ExpectQuery("SELECT COUNT").WithArgs("titan-v2-256").
+			WillReturnRows(sqlmock.NewRows([]string{"total", "covered"}).AddRow(10, 9))
+		mock.ExpectRollback()
+
+		err := h.ActivateReindexJob(ctx, 7)
+
+		assert.ErrorIs(t, err, ErrReindexIncomplete)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 生成中のジョブは切り替えない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		expectSwapPrelude(mock, ReindexStatusRunning)
+		mock.ExpectRollback()
+
+		err := h.ActivateReindexJob(ctx, 7)
+
+		assert.ErrorIs(t, err, ErrReindexInvalidState)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
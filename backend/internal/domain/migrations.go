@@ -0,0 +1,336 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// migrationLockID は複数のタスクが同時に起動した場合にマイグレーションを直列化するアドバイザリーロックのキー
+const migrationLockID = 7428610931
+
+// migration はスキーマ変更1件分の定義
+type migration struct {
+	version    int
+	name       string
+	statements []string
+}
+
+// migrations は適用順に並べたスキーマ変更の一覧
+// 適用済みの定義は変更せず、変更が必要な場合は新しいバージョンを追加すること
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "create_documents_and_chunks",
+		statements: []string{
+			`CREATE EXTENSION IF NOT EXISTS vector`,
+			`CREATE TABLE IF NOT EXISTS documents (
+				id BIGSERIAL PRIMARY KEY,
+				filename TEXT NOT NULL,
+				s3_key TEXT NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+			)`,
+			`CREATE TABLE IF NOT EXISTS document_chunks (
+				id BIGSERIAL PRIMARY KEY,
+				document_id BIGINT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+				chunk_index INTEGER NOT NULL,
+				content TEXT NOT NULL,
+				embedding vector(1536)
+			)`,
+		},
+	},
+	{
+		// Embeddingモデルを切り替えられるよう、チャンクごとにモデルと次元数を保持し、列の次元数の固定を外す
+		version: 2,
+		name:    "add_embedding_model_to_chunks",
+		statements: []string{
+			`ALTER TABLE document_chunks ADD COLUMN IF NOT EXISTS embedding_model TEXT NOT NULL DEFAULT 'titan-v1'`,
+			`ALTER TABLE document_chunks ADD COLUMN IF NOT EXISTS embedding_dimension INTEGER NOT NULL DEFAULT 1536`,
+			`ALTER TABLE document_chunks ALTER COLUMN embedding_model DROP DEFAULT`,
+			`ALTER TABLE document_chunks ALTER COLUMN embedding_dimension DROP DEFAULT`,
+			`ALTER TABLE document_chunks ALTER COLUMN embedding TYPE vector`,
+			`CREATE INDEX IF NOT EXISTS document_chunks_embedding_model_idx ON document_chunks (embedding_model)`,
+		},
+	},
+	{
+		// 検索を止めずにEmbeddingモデルを切り替えるための状態・切り替え用Embedding・再インデックスジョブ
+		version: 3,
+		name:    "add_reindex_tables",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS embedding_index_state (
+				id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+				active_model TEXT NOT NULL,
+				active_dimension INTEGER NOT NULL,
+				previous_model TEXT,
+				previous_dimension INTEGER,
+				updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+			)`,
+			`CREATE TABLE IF NOT EXISTS document_chunk_embeddings (
+				document_id BIGINT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+				chunk_index INTEGER NOT NULL,
+				embedding_model TEXT NOT NULL,
+				embedding_dimension INTEGER NOT NULL,
+				embedding vector NOT NULL,
+				PRIMARY KEY (document_id, chunk_index, embedding_model)
+			)`,
+			`CREATE INDEX IF NOT EXISTS document_chunk_embeddings_model_idx ON document_chunk_embeddings (embedding_model)`,
+			`CREATE TABLE IF NOT EXISTS reindex_jobs (
+				id BIGSERIAL PRIMARY KEY,
+				target_model TEXT NOT NULL,
+				target_dimension INTEGER NOT NULL,
+				status TEXT NOT NULL,
+				last_chunk_id BIGINT NOT NULL DEFAULT 0,
+				processed INTEGER NOT NULL DEFAULT 0,
+				error TEXT,
+				lease_owner TEXT,
+				lease_until TIMESTAMP WITH TIME ZONE,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+			)`,
+			// 終了していないジョブは同時に1件のみ
+			`CREATE UNIQUE INDEX IF NOT EXISTS reindex_jobs_open_idx ON reindex_jobs ((TRUE))
+				WHERE status IN ('running', 'failed', 'ready', 'activated')`,
+			`CREATE INDEX IF NOT EXISTS document_chunks_document_chunk_idx ON document_chunks (document_id, chunk_index)`,
+		},
+	},
+	{
+		// モデル呼び出しごとのトークン使用量と料金 (請求額をテナント・エンドポイントに按分するため)
+		version: 4,
+		name:    "create_llm_usage",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS llm_usage (
+				id BIGSERIAL PRIMARY KEY,
+				request_id TEXT NOT NULL,
+				tenant TEXT NOT NULL,
+				endpoint TEXT NOT NULL,
+				model TEXT NOT NULL,
+				operation TEXT NOT NULL,
+				input_tokens INTEGER NOT NULL DEFAULT 0,
+				output_tokens INTEGER NOT NULL DEFAULT 0,
+				cost_usd NUMERIC(18, 8) NOT NULL DEFAULT 0,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+			)`,
+			`CREATE INDEX IF NOT EXISTS llm_usage_created_at_idx ON llm_usage (created_at)`,
+			`CREATE INDEX IF NOT EXISTS llm_usage_tenant_created_at_idx ON llm_usage (tenant, created_at)`,
+			`CREATE INDEX IF NOT EXISTS llm_usage_request_id_idx ON llm_usage (request_id)`,
+		},
+	},
+	{
+		// 繰り返される質問に検索と生成をやり直さずに回答するための、クエリEmbeddingをキーにした回答キャッシュ
+		version: 5,
+		name:    "create_qa_answer_cache",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS qa_answer_cache (
+				id BIGSERIAL PRIMARY KEY,
+				query TEXT NOT NULL,
+				model TEXT NOT NULL,
+				embedding_model TEXT NOT NULL,
+				embedding_dimension INTEGER NOT NULL,
+				embedding vector NOT NULL,
+				result JSONB NOT NULL,
+				document_ids TEXT[] NOT NULL DEFAULT '{}',
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS qa_answer_cache_model_idx ON qa_answer_cache (model, embedding_model, embedding_dimension)`,
+			`CREATE INDEX IF NOT EXISTS qa_answer_cache_expires_at_idx ON qa_answer_cache (expires_at)`,
+			`CREATE INDEX IF NOT EXISTS qa_answer_cache_document_ids_idx ON qa_answer_cache USING GIN (document_ids)`,
+		},
+	},
+	{
+		// 同じテキストを再度Embeddingしないよう、モデルと正規化したテキストのハッシュをキーにしたEmbeddingのキャッシュ
+		version: 6,
+		name:    "create_embedding_cache",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS embedding_cache (
+				cache_key TEXT NOT NULL,
+				text_hash TEXT NOT NULL,
+				dimension INTEGER NOT NULL,
+				embedding vector NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (cache_key, text_hash)
+			)`,
+		},
+	},
+	{
+		// 取り込み時に重複したドキュメントを検出するため、ファイルのハッシュと抽出したテキストのSimHashを保持する
+		version: 7,
+		name:    "add_document_fingerprints",
+		statements: []string{
+			`ALTER TABLE documents ADD COLUMN IF NOT EXISTS content TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE documents ADD COLUMN IF NOT EXISTS content_hash TEXT`,
+			`ALTER TABLE documents ADD COLUMN IF NOT EXISTS simhash BIGINT`,
+			`ALTER TABLE documents ADD COLUMN IF NOT EXISTS duplicate_of BIGINT REFERENCES documents(id) ON DELETE SET NULL`,
+			`CREATE INDEX IF NOT EXISTS documents_content_hash_idx ON documents (content_hash)`,
+		},
+	},
+	{
+		// 更新されたドキュメントを別のドキュメントとしてではなく、同じ論理ドキュメントの新しい版として保持する
+		// 既存のドキュメントはそれぞれ版が1つだけの論理ドキュメントとする
+		version: 8,
+		name:    "add_document_versions",
+		statements: []string{
+			`ALTER TABLE documents ADD COLUMN IF NOT EXISTS logical_id BIGINT`,
+			`UPDATE documents SET logical_id = id WHERE logical_id IS NULL`,
+			`ALTER TABLE documents ALTER COLUMN logical_id SET NOT NULL`,
+			`ALTER TABLE documents ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`,
+			`ALTER TABLE documents ADD COLUMN IF NOT EXISTS is_latest BOOLEAN NOT NULL DEFAULT TRUE`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS documents_logical_version_idx ON documents (logical_id, version)`,
+			// 最新の版は論理ドキュメントごとに1件のみ
+			`CREATE UNIQUE INDEX IF NOT EXISTS documents_latest_idx ON documents (logical_id) WHERE is_latest`,
+		},
+	},
+	{
+		// バケットに直接置かれたドキュメントを同期するため、取り込み元とその時点の識別子を保持し、走査の再開位置を記録する
+		version: 9,
+		name:    "add_document_sources_and_sync_states",
+		statements: []string{
+			`ALTER TABLE documents ADD COLUMN IF NOT EXISTS source_uri TEXT`,
+			`ALTER TABLE documents ADD COLUMN IF NOT EXISTS source_version TEXT`,
+			`ALTER TABLE documents ADD COLUMN IF NOT EXISTS source_modified_at TIMESTAMP WITH TIME ZONE`,
+			// 取り込み元の一覧とキーの順に突き合わせるため、バイト順で索引を作る
+			`CREATE INDEX IF NOT EXISTS documents_source_uri_idx ON documents ((source_uri COLLATE "C")) WHERE source_uri IS NOT NULL`,
+			`CREATE TABLE IF NOT EXISTS sync_states (
+				source TEXT PRIMARY KEY,
+				checkpoint TEXT NOT NULL DEFAULT '',
+				created INTEGER NOT NULL DEFAULT 0,
+				updated INTEGER NOT NULL DEFAULT 0,
+				deleted INTEGER NOT NULL DEFAULT 0,
+				unchanged INTEGER NOT NULL DEFAULT 0,
+				skipped INTEGER NOT NULL DEFAULT 0,
+				failed INTEGER NOT NULL DEFAULT 0,
+				pass_started_at TIMESTAMP WITH TIME ZONE,
+				last_completed_at TIMESTAMP WITH TIME ZONE,
+				last_error TEXT,
+				lease_owner TEXT,
+				lease_until TIMESTAMP WITH TIME ZONE,
+				updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+			)`,
+		},
+	},
+	{
+		// gitリポジトリの差分だけを同期するため最後に同期したコミットを記録し、引用で行の範囲を示すためチャンクの行番号を保持する
+		// 行番号が 0 のチャンク (既存のチャンク) は範囲が不明
+		version: 10,
+		name:    "add_sync_cursor_and_chunk_lines",
+		statements: []string{
+			`ALTER TABLE sync_states ADD COLUMN IF NOT EXISTS cursor TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE document_chunks ADD COLUMN IF NOT EXISTS start_line INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE document_chunks ADD COLUMN IF NOT EXISTS end_line INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		// 取り込み時に分類したカテゴリとタグを保持し、検索の絞り込みに使う
+		version: 11,
+		name:    "add_document_category_and_tags",
+		statements: []string{
+			`ALTER TABLE documents ADD COLUMN IF NOT EXISTS category TEXT`,
+			`ALTER TABLE documents ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}'`,
+			`CREATE INDEX IF NOT EXISTS documents_category_idx ON documents (category) WHERE category IS NOT NULL`,
+			`CREATE INDEX IF NOT EXISTS documents_tags_idx ON documents USING GIN (tags)`,
+		},
+	},
+	{
+		// Bedrockに送る前に除去した個人情報の監査の記録 (元の値は保存しない)
+		version: 12,
+		name:    "create_pii_redactions",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS pii_redactions (
+				id BIGSERIAL PRIMARY KEY,
+				endpoint TEXT NOT NULL,
+				document_id BIGINT REFERENCES documents(id) ON DELETE CASCADE,
+				request_id TEXT NOT NULL DEFAULT '',
+				tenant TEXT NOT NULL DEFAULT '',
+				pii_type TEXT NOT NULL,
+				start_offset INTEGER NOT NULL,
+				end_offset INTEGER NOT NULL,
+				value_hash TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+			)`,
+			`CREATE INDEX IF NOT EXISTS pii_redactions_document_id_idx ON pii_redactions (document_id)`,
+			`CREATE INDEX IF NOT EXISTS pii_redactions_created_at_idx ON pii_redactions (created_at)`,
+		},
+	},
+	{
+		// 日次リクエスト数をクライアントが指定できる request_id ではなく、サーバーが採番したIDで数える
+		// 既存の行は従来どおり request_id ごとに1件と数えられるよう、request_id から値を埋める
+		version: 13,
+		name:    "add_llm_usage_scope_id",
+		statements: []string{
+			`ALTER TABLE llm_usage ADD COLUMN IF NOT EXISTS scope_id TEXT NOT NULL DEFAULT ''`,
+			`UPDATE llm_usage SET scope_id = 'legacy:' || request_id WHERE scope_id = ''`,
+		},
+	},
+	{
+		// 個人情報の監査の記録はドキュメントを削除した後も残すため、参照先が削除された場合は document_id を NULL にする
+		version: 14,
+		name:    "keep_pii_redactions_on_document_delete",
+		statements: []string{
+			`ALTER TABLE pii_redactions DROP CONSTRAINT IF EXISTS pii_redactions_document_id_fkey`,
+			`ALTER TABLE pii_redactions ADD CONSTRAINT pii_redactions_document_id_fkey
+				FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE SET NULL`,
+		},
+	},
+}
+
+// Migrate は未適用のマイグレーションを順に適用する
+// 各マイグレーションは1トランザクションで適用し、適用済みのバージョンは schema_migrations に記録する
+func (h *DBHandler) Migrate(ctx context.Context) error {
+	if _, err := h.DB.ExecContext(ctx, `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version INTEGER PRIMARY KEY,
+            name TEXT NOT NULL,
+            applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+        )
+    `); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	for _, m := range migrations {
+		if err := h.applyMigration(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMigration はマイグレーション1件を未適用の場合のみ適用する
+func (h *DBHandler) applyMigration(ctx context.Context, m migration) (err error) {
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// トランザクション終了時に自動で解放される
+	if _, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+
+	var applied bool
+	if err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, m.version).Scan(&applied); err != nil {
+		return fmt.Errorf("failed to check migration %d: %w", m.version, err)
+	}
+	if applied {
+		return tx.Commit()
+	}
+
+	for _, stmt := range m.statements {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to apply migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+	if _, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.version, m.name); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", m.version, err)
+	}
+	log.Printf("Applied migration %d (%s)", m.version, m.name)
+	return nil
+}
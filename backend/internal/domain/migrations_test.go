@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 未適用のマイグレーションだけを適用して記録する", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		for i, m := range migrations {
+			mock.ExpectBegin()
+			mock.ExpectExec("SELECT pg_advisory_xact_lock").WithArgs(migrationLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+			// 最初のマイグレーションのみ適用済み
+			mock.ExpectQuery("SELECT EXISTS").WithArgs(m.version).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(i == 0))
+			if i > 0 {
+				for range m.statements {
+					mock.ExpectExec(".+").WillReturnResult(sqlmock.NewResult(0, 0))
+				}
+				mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(m.version, m.name).WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectCommit()
+		}
+
+		err := h.Migrate(ctx)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 適用に失敗した場合はロールバックして以降を適用しない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		applyErr := errors.New("syntax error")
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectBegin()
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").WithArgs(migrationLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT EXISTS").WithArgs(migrations[0].version).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec("CREATE EXTENSION").WillReturnError(applyErr)
+		mock.ExpectRollback()
+
+		err := h.Migrate(ctx)
+
+		assert.ErrorIs(t, err, applyErr)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: internal/domain/db_handler_interface.go
+// Source: db_handler_interface.go
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -8,6 +8,7 @@ import (
 	domain "bedrock-rag-sample/backend/internal/domain"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,19 +36,239 @@ func (m *MockDBHandlerInterface) EXPECT() *MockDBHandlerInterfaceMockRecorder {
 	return m.recorder
 }
 
+// ActivateReindexJob mocks base method.
+func (m *MockDBHandlerInterface) ActivateReindexJob(ctx context.Context, jobID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActivateReindexJob", ctx, jobID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ActivateReindexJob indicates an expected call of ActivateReindexJob.
+func (mr *MockDBHandlerInterfaceMockRecorder) ActivateReindexJob(ctx, jobID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivateReindexJob", reflect.TypeOf((*MockDBHandlerInterface)(nil).ActivateReindexJob), ctx, jobID)
+}
+
+// AggregateUsage mocks base method.
+func (m *MockDBHandlerInterface) AggregateUsage(ctx context.Context, filter domain.UsageFilter) ([]domain.UsageAggregate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AggregateUsage", ctx, filter)
+	ret0, _ := ret[0].([]domain.UsageAggregate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AggregateUsage indicates an expected call of AggregateUsage.
+func (mr *MockDBHandlerInterfaceMockRecorder) AggregateUsage(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AggregateUsage", reflect.TypeOf((*MockDBHandlerInterface)(nil).AggregateUsage), ctx, filter)
+}
+
+// CancelReindexJob mocks base method.
+func (m *MockDBHandlerInterface) CancelReindexJob(ctx context.Context, jobID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelReindexJob", ctx, jobID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelReindexJob indicates an expected call of CancelReindexJob.
+func (mr *MockDBHandlerInterfaceMockRecorder) CancelReindexJob(ctx, jobID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelReindexJob", reflect.TypeOf((*MockDBHandlerInterface)(nil).CancelReindexJob), ctx, jobID)
+}
+
+// ClaimReindexJob mocks base method.
+func (m *MockDBHandlerInterface) ClaimReindexJob(ctx context.Context, jobID int64, owner string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimReindexJob", ctx, jobID, owner, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimReindexJob indicates an expected call of ClaimReindexJob.
+func (mr *MockDBHandlerInterfaceMockRecorder) ClaimReindexJob(ctx, jobID, owner, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimReindexJob", reflect.TypeOf((*MockDBHandlerInterface)(nil).ClaimReindexJob), ctx, jobID, owner, ttl)
+}
+
+// ClaimSyncState mocks base method.
+func (m *MockDBHandlerInterface) ClaimSyncState(ctx context.Context, source, owner string, ttl time.Duration) (*domain.SyncState, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimSyncState", ctx, source, owner, ttl)
+	ret0, _ := ret[0].(*domain.SyncState)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ClaimSyncState indicates an expected call of ClaimSyncState.
+func (mr *MockDBHandlerInterfaceMockRecorder) ClaimSyncState(ctx, source, owner, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimSyncState", reflect.TypeOf((*MockDBHandlerInterface)(nil).ClaimSyncState), ctx, source, owner, ttl)
+}
+
+// CompleteSyncPass mocks base method.
+func (m *MockDBHandlerInterface) CompleteSyncPass(ctx context.Context, source, owner, cursor string, stats domain.SyncStats) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteSyncPass", ctx, source, owner, cursor, stats)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteSyncPass indicates an expected call of CompleteSyncPass.
+func (mr *MockDBHandlerInterfaceMockRecorder) CompleteSyncPass(ctx, source, owner, cursor, stats interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteSyncPass", reflect.TypeOf((*MockDBHandlerInterface)(nil).CompleteSyncPass), ctx, source, owner, cursor, stats)
+}
+
+// CreateDocument mocks base method.
+func (m *MockDBHandlerInterface) CreateDocument(ctx context.Context, doc *domain.Document) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDocument", ctx, doc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDocument indicates an expected call of CreateDocument.
+func (mr *MockDBHandlerInterfaceMockRecorder) CreateDocument(ctx, doc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDocument", reflect.TypeOf((*MockDBHandlerInterface)(nil).CreateDocument), ctx, doc)
+}
+
+// CreateReindexJob mocks base method.
+func (m *MockDBHandlerInterface) CreateReindexJob(ctx context.Context, target domain.EmbeddingSpace) (*domain.ReindexJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReindexJob", ctx, target)
+	ret0, _ := ret[0].(*domain.ReindexJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReindexJob indicates an expected call of CreateReindexJob.
+func (mr *MockDBHandlerInterfaceMockRecorder) CreateReindexJob(ctx, target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReindexJob", reflect.TypeOf((*MockDBHandlerInterface)(nil).CreateReindexJob), ctx, target)
+}
+
+// DeleteDocument mocks base method.
+func (m *MockDBHandlerInterface) DeleteDocument(ctx context.Context, documentID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDocument", ctx, documentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDocument indicates an expected call of DeleteDocument.
+func (mr *MockDBHandlerInterfaceMockRecorder) DeleteDocument(ctx, documentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDocument", reflect.TypeOf((*MockDBHandlerInterface)(nil).DeleteDocument), ctx, documentID)
+}
+
+// FinalizeReindexJob mocks base method.
+func (m *MockDBHandlerInterface) FinalizeReindexJob(ctx context.Context, jobID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinalizeReindexJob", ctx, jobID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinalizeReindexJob indicates an expected call of FinalizeReindexJob.
+func (mr *MockDBHandlerInterfaceMockRecorder) FinalizeReindexJob(ctx, jobID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinalizeReindexJob", reflect.TypeOf((*MockDBHandlerInterface)(nil).FinalizeReindexJob), ctx, jobID)
+}
+
+// FindCachedAnswer mocks base method.
+func (m *MockDBHandlerInterface) FindCachedAnswer(ctx context.Context, model string, space domain.EmbeddingSpace, embedding []float32, now time.Time) (*domain.CachedAnswer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCachedAnswer", ctx, model, space, embedding, now)
+	ret0, _ := ret[0].(*domain.CachedAnswer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCachedAnswer indicates an expected call of FindCachedAnswer.
+func (mr *MockDBHandlerInterfaceMockRecorder) FindCachedAnswer(ctx, model, space, embedding, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCachedAnswer", reflect.TypeOf((*MockDBHandlerInterface)(nil).FindCachedAnswer), ctx, model, space, embedding, now)
+}
+
+// FindDocumentByContentHash mocks base method.
+func (m *MockDBHandlerInterface) FindDocumentByContentHash(ctx context.Context, contentHash string) (*domain.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDocumentByContentHash", ctx, contentHash)
+	ret0, _ := ret[0].(*domain.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDocumentByContentHash indicates an expected call of FindDocumentByContentHash.
+func (mr *MockDBHandlerInterfaceMockRecorder) FindDocumentByContentHash(ctx, contentHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDocumentByContentHash", reflect.TypeOf((*MockDBHandlerInterface)(nil).FindDocumentByContentHash), ctx, contentHash)
+}
+
+// FindNearDuplicateDocuments mocks base method.
+func (m *MockDBHandlerInterface) FindNearDuplicateDocuments(ctx context.Context, simHash int64, maxDistance, limit int) ([]domain.NearDuplicate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindNearDuplicateDocuments", ctx, simHash, maxDistance, limit)
+	ret0, _ := ret[0].([]domain.NearDuplicate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindNearDuplicateDocuments indicates an expected call of FindNearDuplicateDocuments.
+func (mr *MockDBHandlerInterfaceMockRecorder) FindNearDuplicateDocuments(ctx, simHash, maxDistance, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindNearDuplicateDocuments", reflect.TypeOf((*MockDBHandlerInterface)(nil).FindNearDuplicateDocuments), ctx, simHash, maxDistance, limit)
+}
+
+// FindOpenReindexJob mocks base method.
+func (m *MockDBHandlerInterface) FindOpenReindexJob(ctx context.Context) (*domain.ReindexJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOpenReindexJob", ctx)
+	ret0, _ := ret[0].(*domain.ReindexJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOpenReindexJob indicates an expected call of FindOpenReindexJob.
+func (mr *MockDBHandlerInterfaceMockRecorder) FindOpenReindexJob(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOpenReindexJob", reflect.TypeOf((*MockDBHandlerInterface)(nil).FindOpenReindexJob), ctx)
+}
+
 // FindSimilarChunks mocks base method.
-func (m *MockDBHandlerInterface) FindSimilarChunks(ctx context.Context, embedding []float32, limit int) ([]domain.DocumentChunk, error) {
+func (m *MockDBHandlerInterface) FindSimilarChunks(ctx context.Context, space domain.EmbeddingSpace, embedding []float32, limit int, filter domain.ChunkFilter) ([]domain.DocumentChunk, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindSimilarChunks", ctx, embedding, limit)
+	ret := m.ctrl.Call(m, "FindSimilarChunks", ctx, space, embedding, limit, filter)
 	ret0, _ := ret[0].([]domain.DocumentChunk)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindSimilarChunks indicates an expected call of FindSimilarChunks.
-func (mr *MockDBHandlerInterfaceMockRecorder) FindSimilarChunks(ctx, embedding, limit interface{}) *gomock.Call {
+func (mr *MockDBHandlerInterfaceMockRecorder) FindSimilarChunks(ctx, space, embedding, limit, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSimilarChunks", reflect.TypeOf((*MockDBHandlerInterface)(nil).FindSimilarChunks), ctx, embedding, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSimilarChunks", reflect.TypeOf((*MockDBHandlerInterface)(nil).FindSimilarChunks), ctx, space, embedding, limit, filter)
+}
+
+// GetCachedEmbeddings mocks base method.
+func (m *MockDBHandlerInterface) GetCachedEmbeddings(ctx context.Context, key string, hashes []string) (map[string][]float32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCachedEmbeddings", ctx, key, hashes)
+	ret0, _ := ret[0].(map[string][]float32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCachedEmbeddings indicates an expected call of GetCachedEmbeddings.
+func (mr *MockDBHandlerInterfaceMockRecorder) GetCachedEmbeddings(ctx, key, hashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCachedEmbeddings", reflect.TypeOf((*MockDBHandlerInterface)(nil).GetCachedEmbeddings), ctx, key, hashes)
 }
 
 // GetDocumentByID mocks base method.
@@ -65,17 +286,335 @@ func (mr *MockDBHandlerInterfaceMockRecorder) GetDocumentByID(ctx, documentID in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDocumentByID", reflect.TypeOf((*MockDBHandlerInterface)(nil).GetDocumentByID), ctx, documentID)
 }
 
-// SaveDocumentEmbedding mocks base method.
-func (m *MockDBHandlerInterface) SaveDocumentEmbedding(ctx context.Context, documentID int64, chunk string, chunkIndex int, embedding []float32) (int64, error) {
+// GetDocumentVersion mocks base method.
+func (m *MockDBHandlerInterface) GetDocumentVersion(ctx context.Context, logicalID int64, version int) (*domain.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDocumentVersion", ctx, logicalID, version)
+	ret0, _ := ret[0].(*domain.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDocumentVersion indicates an expected call of GetDocumentVersion.
+func (mr *MockDBHandlerInterfaceMockRecorder) GetDocumentVersion(ctx, logicalID, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDocumentVersion", reflect.TypeOf((*MockDBHandlerInterface)(nil).GetDocumentVersion), ctx, logicalID, version)
+}
+
+// GetEmbeddingIndexState mocks base method.
+func (m *MockDBHandlerInterface) GetEmbeddingIndexState(ctx context.Context, initial domain.EmbeddingSpace) (*domain.EmbeddingIndexState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmbeddingIndexState", ctx, initial)
+	ret0, _ := ret[0].(*domain.EmbeddingIndexState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmbeddingIndexState indicates an expected call of GetEmbeddingIndexState.
+func (mr *MockDBHandlerInterfaceMockRecorder) GetEmbeddingIndexState(ctx, initial interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmbeddingIndexState", reflect.TypeOf((*MockDBHandlerInterface)(nil).GetEmbeddingIndexState), ctx, initial)
+}
+
+// GetReindexCoverage mocks base method.
+func (m *MockDBHandlerInterface) GetReindexCoverage(ctx context.Context, space domain.EmbeddingSpace) (domain.ReindexCoverage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReindexCoverage", ctx, space)
+	ret0, _ := ret[0].(domain.ReindexCoverage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReindexCoverage indicates an expected call of GetReindexCoverage.
+func (mr *MockDBHandlerInterfaceMockRecorder) GetReindexCoverage(ctx, space interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReindexCoverage", reflect.TypeOf((*MockDBHandlerInterface)(nil).GetReindexCoverage), ctx, space)
+}
+
+// GetSyncState mocks base method.
+func (m *MockDBHandlerInterface) GetSyncState(ctx context.Context, source string) (*domain.SyncState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSyncState", ctx, source)
+	ret0, _ := ret[0].(*domain.SyncState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSyncState indicates an expected call of GetSyncState.
+func (mr *MockDBHandlerInterfaceMockRecorder) GetSyncState(ctx, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSyncState", reflect.TypeOf((*MockDBHandlerInterface)(nil).GetSyncState), ctx, source)
+}
+
+// GetTenantUsage mocks base method.
+func (m *MockDBHandlerInterface) GetTenantUsage(ctx context.Context, tenant string, monthStart, dayStart time.Time) (domain.TenantUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantUsage", ctx, tenant, monthStart, dayStart)
+	ret0, _ := ret[0].(domain.TenantUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenantUsage indicates an expected call of GetTenantUsage.
+func (mr *MockDBHandlerInterfaceMockRecorder) GetTenantUsage(ctx, tenant, monthStart, dayStart interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantUsage", reflect.TypeOf((*MockDBHandlerInterface)(nil).GetTenantUsage), ctx, tenant, monthStart, dayStart)
+}
+
+// InvalidateCachedAnswers mocks base method.
+func (m *MockDBHandlerInterface) InvalidateCachedAnswers(ctx context.Context) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveDocumentEmbedding", ctx, documentID, chunk, chunkIndex, embedding)
+	ret := m.ctrl.Call(m, "InvalidateCachedAnswers", ctx)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SaveDocumentEmbedding indicates an expected call of SaveDocumentEmbedding.
-func (mr *MockDBHandlerInterfaceMockRecorder) SaveDocumentEmbedding(ctx, documentID, chunk, chunkIndex, embedding interface{}) *gomock.Call {
+// InvalidateCachedAnswers indicates an expected call of InvalidateCachedAnswers.
+func (mr *MockDBHandlerInterfaceMockRecorder) InvalidateCachedAnswers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateCachedAnswers", reflect.TypeOf((*MockDBHandlerInterface)(nil).InvalidateCachedAnswers), ctx)
+}
+
+// InvalidateCachedAnswersForDocument mocks base method.
+func (m *MockDBHandlerInterface) InvalidateCachedAnswersForDocument(ctx context.Context, documentID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateCachedAnswersForDocument", ctx, documentID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InvalidateCachedAnswersForDocument indicates an expected call of InvalidateCachedAnswersForDocument.
+func (mr *MockDBHandlerInterfaceMockRecorder) InvalidateCachedAnswersForDocument(ctx, documentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateCachedAnswersForDocument", reflect.TypeOf((*MockDBHandlerInterface)(nil).InvalidateCachedAnswersForDocument), ctx, documentID)
+}
+
+// ListChunksForReindex mocks base method.
+func (m *MockDBHandlerInterface) ListChunksForReindex(ctx context.Context, target domain.EmbeddingSpace, afterChunkID int64, limit int) ([]domain.DocumentChunk, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChunksForReindex", ctx, target, afterChunkID, limit)
+	ret0, _ := ret[0].([]domain.DocumentChunk)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChunksForReindex indicates an expected call of ListChunksForReindex.
+func (mr *MockDBHandlerInterfaceMockRecorder) ListChunksForReindex(ctx, target, afterChunkID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChunksForReindex", reflect.TypeOf((*MockDBHandlerInterface)(nil).ListChunksForReindex), ctx, target, afterChunkID, limit)
+}
+
+// ListDocumentVersions mocks base method.
+func (m *MockDBHandlerInterface) ListDocumentVersions(ctx context.Context, logicalID int64) ([]domain.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDocumentVersions", ctx, logicalID)
+	ret0, _ := ret[0].([]domain.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDocumentVersions indicates an expected call of ListDocumentVersions.
+func (mr *MockDBHandlerInterfaceMockRecorder) ListDocumentVersions(ctx, logicalID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDocumentVersions", reflect.TypeOf((*MockDBHandlerInterface)(nil).ListDocumentVersions), ctx, logicalID)
+}
+
+// ListDocuments mocks base method.
+func (m *MockDBHandlerInterface) ListDocuments(ctx context.Context, filter domain.DocumentListFilter) ([]domain.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDocuments", ctx, filter)
+	ret0, _ := ret[0].([]domain.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDocuments indicates an expected call of ListDocuments.
+func (mr *MockDBHandlerInterfaceMockRecorder) ListDocuments(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDocuments", reflect.TypeOf((*MockDBHandlerInterface)(nil).ListDocuments), ctx, filter)
+}
+
+// ListDocumentsBySource mocks base method.
+func (m *MockDBHandlerInterface) ListDocumentsBySource(ctx context.Context, r domain.SourceRange) ([]domain.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDocumentsBySource", ctx, r)
+	ret0, _ := ret[0].([]domain.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDocumentsBySource indicates an expected call of ListDocumentsBySource.
+func (mr *MockDBHandlerInterfaceMockRecorder) ListDocumentsBySource(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDocumentsBySource", reflect.TypeOf((*MockDBHandlerInterface)(nil).ListDocumentsBySource), ctx, r)
+}
+
+// ReleaseSyncState mocks base method.
+func (m *MockDBHandlerInterface) ReleaseSyncState(ctx context.Context, source, owner, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseSyncState", ctx, source, owner, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseSyncState indicates an expected call of ReleaseSyncState.
+func (mr *MockDBHandlerInterfaceMockRecorder) ReleaseSyncState(ctx, source, owner, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseSyncState", reflect.TypeOf((*MockDBHandlerInterface)(nil).ReleaseSyncState), ctx, source, owner, message)
+}
+
+// RollbackReindexJob mocks base method.
+func (m *MockDBHandlerInterface) RollbackReindexJob(ctx context.Context, jobID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollbackReindexJob", ctx, jobID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RollbackReindexJob indicates an expected call of RollbackReindexJob.
+func (mr *MockDBHandlerInterfaceMockRecorder) RollbackReindexJob(ctx, jobID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackReindexJob", reflect.TypeOf((*MockDBHandlerInterface)(nil).RollbackReindexJob), ctx, jobID)
+}
+
+// SaveCachedAnswer mocks base method.
+func (m *MockDBHandlerInterface) SaveCachedAnswer(ctx context.Context, entry domain.CachedAnswer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCachedAnswer", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCachedAnswer indicates an expected call of SaveCachedAnswer.
+func (mr *MockDBHandlerInterfaceMockRecorder) SaveCachedAnswer(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCachedAnswer", reflect.TypeOf((*MockDBHandlerInterface)(nil).SaveCachedAnswer), ctx, entry)
+}
+
+// SaveCachedEmbeddings mocks base method.
+func (m *MockDBHandlerInterface) SaveCachedEmbeddings(ctx context.Context, key string, entries []domain.CachedEmbedding) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCachedEmbeddings", ctx, key, entries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCachedEmbeddings indicates an expected call of SaveCachedEmbeddings.
+func (mr *MockDBHandlerInterfaceMockRecorder) SaveCachedEmbeddings(ctx, key, entries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCachedEmbeddings", reflect.TypeOf((*MockDBHandlerInterface)(nil).SaveCachedEmbeddings), ctx, key, entries)
+}
+
+// SaveDocumentChunks mocks base method.
+func (m *MockDBHandlerInterface) SaveDocumentChunks(ctx context.Context, documentID int64, space domain.EmbeddingSpace, chunks []domain.ChunkEmbedding, shadow *domain.ShadowEmbeddingSet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDocumentChunks", ctx, documentID, space, chunks, shadow)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDocumentChunks indicates an expected call of SaveDocumentChunks.
+func (mr *MockDBHandlerInterfaceMockRecorder) SaveDocumentChunks(ctx, documentID, space, chunks, shadow interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDocumentChunks", reflect.TypeOf((*MockDBHandlerInterface)(nil).SaveDocumentChunks), ctx, documentID, space, chunks, shadow)
+}
+
+// SaveRedactionAudit mocks base method.
+func (m *MockDBHandlerInterface) SaveRedactionAudit(ctx context.Context, records []domain.PIIRedaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRedactionAudit", ctx, records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRedactionAudit indicates an expected call of SaveRedactionAudit.
+func (mr *MockDBHandlerInterfaceMockRecorder) SaveRedactionAudit(ctx, records interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRedactionAudit", reflect.TypeOf((*MockDBHandlerInterface)(nil).SaveRedactionAudit), ctx, records)
+}
+
+// SaveReindexBatch mocks base method.
+func (m *MockDBHandlerInterface) SaveReindexBatch(ctx context.Context, job *domain.ReindexJob, owner string, ttl time.Duration, embeddings []domain.ShadowEmbedding, lastChunkID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReindexBatch", ctx, job, owner, ttl, embeddings, lastChunkID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReindexBatch indicates an expected call of SaveReindexBatch.
+func (mr *MockDBHandlerInterfaceMockRecorder) SaveReindexBatch(ctx, job, owner, ttl, embeddings, lastChunkID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReindexBatch", reflect.TypeOf((*MockDBHandlerInterface)(nil).SaveReindexBatch), ctx, job, owner, ttl, embeddings, lastChunkID)
+}
+
+// SaveSyncCheckpoint mocks base method.
+func (m *MockDBHandlerInterface) SaveSyncCheckpoint(ctx context.Context, source, owner string, ttl time.Duration, checkpoint string, stats domain.SyncStats) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSyncCheckpoint", ctx, source, owner, ttl, checkpoint, stats)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSyncCheckpoint indicates an expected call of SaveSyncCheckpoint.
+func (mr *MockDBHandlerInterfaceMockRecorder) SaveSyncCheckpoint(ctx, source, owner, ttl, checkpoint, stats interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSyncCheckpoint", reflect.TypeOf((*MockDBHandlerInterface)(nil).SaveSyncCheckpoint), ctx, source, owner, ttl, checkpoint, stats)
+}
+
+// SaveUsageRecord mocks base method.
+func (m *MockDBHandlerInterface) SaveUsageRecord(ctx context.Context, record domain.UsageRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUsageRecord", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveUsageRecord indicates an expected call of SaveUsageRecord.
+func (mr *MockDBHandlerInterfaceMockRecorder) SaveUsageRecord(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUsageRecord", reflect.TypeOf((*MockDBHandlerInterface)(nil).SaveUsageRecord), ctx, record)
+}
+
+// UpdateDocumentClassification mocks base method.
+func (m *MockDBHandlerInterface) UpdateDocumentClassification(ctx context.Context, documentID int64, category string, tags []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDocumentClassification", ctx, documentID, category, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDocumentClassification indicates an expected call of UpdateDocumentClassification.
+func (mr *MockDBHandlerInterfaceMockRecorder) UpdateDocumentClassification(ctx, documentID, category, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDocumentClassification", reflect.TypeOf((*MockDBHandlerInterface)(nil).UpdateDocumentClassification), ctx, documentID, category, tags)
+}
+
+// UpdateDocumentSource mocks base method.
+func (m *MockDBHandlerInterface) UpdateDocumentSource(ctx context.Context, documentID int64, version string, modifiedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDocumentSource", ctx, documentID, version, modifiedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDocumentSource indicates an expected call of UpdateDocumentSource.
+func (mr *MockDBHandlerInterfaceMockRecorder) UpdateDocumentSource(ctx, documentID, version, modifiedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDocumentSource", reflect.TypeOf((*MockDBHandlerInterface)(nil).UpdateDocumentSource), ctx, documentID, version, modifiedAt)
+}
+
+// UpdateReindexJobStatus mocks base method.
+func (m *MockDBHandlerInterface) UpdateReindexJobStatus(ctx context.Context, jobID int64, from []string, to, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReindexJobStatus", ctx, jobID, from, to, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReindexJobStatus indicates an expected call of UpdateReindexJobStatus.
+func (mr *MockDBHandlerInterfaceMockRecorder) UpdateReindexJobStatus(ctx, jobID, from, to, message interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDocumentEmbedding", reflect.TypeOf((*MockDBHandlerInterface)(nil).SaveDocumentEmbedding), ctx, documentID, chunk, chunkIndex, embedding)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReindexJobStatus", reflect.TypeOf((*MockDBHandlerInterface)(nil).UpdateReindexJobStatus), ctx, jobID, from, to, message)
 }
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"time"
 
 	"github.com/pgvector/pgvector-go" // pgvector
@@ -8,19 +9,87 @@ import (
 
 // Document はドキュメント情報を表す構造体
 type Document struct {
-	ID        int64     `json:"id"`
-	Filename  string    `json:"filename"`
-	S3Key     string    `json:"s3_key"`
-	Content   string    `json:"content,omitempty"` // 必要に応じて読み込む
-	CreatedAt time.Time `json:"created_at"`
+	ID          int64     `json:"id"`
+	LogicalID   int64     `json:"logical_id"` // 版をまとめる論理ドキュメントのID (最初の版のID)
+	Version     int       `json:"version"`    // 論理ドキュメント内の版番号 (1 から)
+	IsLatest    bool      `json:"is_latest"`  // 最新の版かどうか (検索は既定で最新の版のみを対象とする)
+	Filename    string    `json:"filename"`
+	S3Key       string    `json:"s3_key"`                 // 版ごとに異なるオブジェクトに保存する
+	Content     string    `json:"content,omitempty"`      // 必要に応じて読み込む
+	ContentHash string    `json:"content_hash,omitempty"` // アップロードされたファイルのSHA-256 (完全一致の重複の検出用)
+	SimHash     int64     `json:"-"`                      // 抽出したテキストのSimHash (類似した重複の検出用)
+	DuplicateOf *int64    `json:"duplicate_of,omitempty"` // 重複と知りつつ取り込んだ場合の既存のドキュメント
+	CreatedAt   time.Time `json:"created_at"`
+
+	// 取り込み元 (同期で取り込んだ場合のみ)
+	SourceURI        string     `json:"source_uri,omitempty"`         // 取り込み元のURI (例: s3://bucket/key)
+	SourceVersion    string     `json:"source_version,omitempty"`     // 取り込んだ時点の取り込み元の識別子 (S3のETagなど)
+	SourceModifiedAt *time.Time `json:"source_modified_at,omitempty"` // 取り込んだ時点の取り込み元の更新日時
+
+	// 分類 (取り込み時に自動で付与し、APIで編集できる)
+	Category string   `json:"category,omitempty"` // タクソノミーのカテゴリ (空の場合は未分類)
+	Tags     []string `json:"tags,omitempty"`     // 自由なタグ
+}
+
+// ChunkFilter はチャンクの検索対象を絞り込む条件
+type ChunkFilter struct {
+	AllVersions bool     // true の場合は最新以外の版も検索する
+	Category    string   // 指定した場合はこのカテゴリのドキュメントのみを検索する
+	Tags        []string // 指定した場合はすべてのタグが付いたドキュメントのみを検索する
+}
+
+// DocumentListFilter はドキュメントの一覧の条件
+type DocumentListFilter struct {
+	AfterID     int64 // このIDより後のドキュメントを返す (ページング用)
+	Limit       int   // 最大件数 (0以下の場合は既定値)
+	AllVersions bool  // true の場合は最新以外の版も含める
+}
+
+// SourceRange は取り込み元のURIの範囲 (バイト順)
+type SourceRange struct {
+	Prefix  string // URIの接頭辞
+	After   string // このURIより後 (空の場合は先頭から)
+	Through string // このURIまで (空の場合は末尾まで)
 }
 
+// ErrDocumentNotFound は指定したドキュメントが存在しない場合のエラー
+var ErrDocumentNotFound = errors.New("document not found")
+
 // DocumentChunk はドキュメントのチャンクとEmbeddingを表す構造体
 type DocumentChunk struct {
 	ID         int64           `json:"id"`
 	DocumentID int64           `json:"document_id"`
 	ChunkIndex int             `json:"chunk_index"`
 	Content    string          `json:"content"`
+	StartLine  int             `json:"start_line,omitempty"` // ドキュメントのテキストでのチャンクの最初の行 (1 から。0 は不明)
+	EndLine    int             `json:"end_line,omitempty"`   // ドキュメントのテキストでのチャンクの最後の行
 	Embedding  pgvector.Vector `json:"-"`                    // JSONには含めない
 	Similarity float64         `json:"similarity,omitempty"` // 類似度検索の結果で使用
+	Citation   string          `json:"citation,omitempty"`   // 引用元のリンク (取り込み元のURIと行の範囲。類似度検索の結果で使用)
+}
+
+// ChunkEmbedding は保存前のチャンクとそのEmbedding
+type ChunkEmbedding struct {
+	ChunkIndex int
+	Content    string
+	StartLine  int
+	EndLine    int
+	Embedding  []float32
+}
+
+// EmbeddingSpace はEmbeddingを生成したモデルとその次元数
+// 同じ EmbeddingSpace のベクトル同士でなければ距離を比較できない
+type EmbeddingSpace struct {
+	Model     string `json:"model"` // Embeddingモデルのレジストリ名
+	Dimension int    `json:"dimension"`
 }
+
+// ErrMixedEmbeddingModels は検索対象に別モデルのEmbeddingが含まれている場合のエラー
+var ErrMixedEmbeddingModels = errors.New("異なるEmbeddingモデルで生成されたチャンクが混在しているため検索できません。再インデックスしてください")
+
+// ErrEmbeddingSpaceChanged はEmbeddingを生成した後、保存するまでの間に検索用のモデルが切り替わった場合のエラー
+// 呼び出し側は切り替え後のモデルでEmbeddingを生成し直して保存する
+var ErrEmbeddingSpaceChanged = errors.New("Embeddingの生成中に検索用のEmbeddingモデルが切り替わりました")
+
+// ErrEmbeddingDimensionMismatch はEmbeddingの次元数がモデルの定義と一致しない場合のエラー
+var ErrEmbeddingDimensionMismatch = errors.New("Embeddingの次元数がモデルの定義と一致しません")
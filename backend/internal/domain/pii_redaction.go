@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PIIRedaction はBedrockに送る前に除去した個人情報1件の監査の記録 (元の値は含めない)
+type PIIRedaction struct {
+	Endpoint   string // 除去したエンドポイント (ingest / qa など)
+	DocumentID *int64 // 取り込み時に除去した場合のドキュメントID
+	RequestID  string
+	Tenant     string
+	Type       string // 個人情報の種類 (phone / email など)
+	Start      int    // 元のテキストでの開始位置 (文字単位)
+	End        int    // 元のテキストでの終了位置 (文字単位、この位置を含まない)
+	ValueHash  string // 元の値のHMAC-SHA256 (鍵を設定していない場合は空)
+}
+
+// SaveRedactionAudit は個人情報の除去の監査の記録をまとめて保存する
+func (h *DBHandler) SaveRedactionAudit(ctx context.Context, records []PIIRedaction) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`INSERT INTO pii_redactions (endpoint, document_id, request_id, tenant, pii_type, start_offset, end_offset, value_hash) VALUES `)
+	args := make([]any, 0, len(records)*8)
+	for i, r := range records {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		n := len(args)
+		fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8)
+		args = append(args, r.Endpoint, r.DocumentID, r.RequestID, r.Tenant, r.Type, r.Start, r.End, r.ValueHash)
+	}
+
+	if _, err := h.DB.ExecContext(ctx, sb.String(), args...); err != nil {
+		return fmt.Errorf("failed to save redaction audit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveRedactionAudit(t *testing.T) {
+	ctx := context.Background()
+	documentID := int64(42)
+
+	t.Run("正常系: まとめて保存する", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec(`INSERT INTO pii_redactions .+ VALUES \(\$1, .+\), \(\$9, .+\)`).
+			WithArgs("ingest", &documentID, "req-1", "tenant-a", "phone", 3, 16, "abc",
+				"qa", nil, "req-2", "tenant-a", "email", 0, 12, "").
+			WillReturnResult(sqlmock.NewResult(2, 2))
+
+		err := h.SaveRedactionAudit(ctx, []PIIRedaction{
+			{Endpoint: "ingest", DocumentID: &documentID, RequestID: "req-1", Tenant: "tenant-a", Type: "phone", Start: 3, End: 16, ValueHash: "abc"},
+			{Endpoint: "qa", RequestID: "req-2", Tenant: "tenant-a", Type: "email", Start: 0, End: 12},
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("正常系: 記録がない場合は保存しない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		err := h.SaveRedactionAudit(ctx, nil)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 保存エラー", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		dbErr := errors.New("connection refused")
+		mock.ExpectExec("INSERT INTO pii_redactions").WillReturnError(dbErr)
+
+		err := h.SaveRedactionAudit(ctx, []PIIRedaction{{Endpoint: "qa", Type: "email"}})
+
+		assert.ErrorIs(t, err, dbErr)
+	})
+}
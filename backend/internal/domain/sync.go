@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSyncLeaseLost は別のプロセスが同期を引き継いだ場合のエラー
+var ErrSyncLeaseLost = errors.New("同期の実行権を失いました")
+
+// SyncStats は同期1回分 (取り込み元の先頭から末尾まで) の処理件数
+type SyncStats struct {
+	Created   int `json:"created"`   // 新しく取り込んだ
+	Updated   int `json:"updated"`   // 更新されたため新しい版として取り込んだ
+	Deleted   int `json:"deleted"`   // 取り込み元から削除されたためドキュメントを削除した
+	Unchanged int `json:"unchanged"` // 変更がなかった
+	Skipped   int `json:"skipped"`   // 取り込めないファイル形式のため読み飛ばした
+	Failed    int `json:"failed"`    // 取り込みに失敗した (次回の同期で再度取り込む)
+}
+
+// Add は件数を足し合わせる
+func (s *SyncStats) Add(other SyncStats) {
+	s.Created += other.Created
+	s.Updated += other.Updated
+	s.Deleted += other.Deleted
+	s.Unchanged += other.Unchanged
+	s.Skipped += other.Skipped
+	s.Failed += other.Failed
+}
+
+// SyncState は取り込み元ごとの同期の状態
+// 大きな取り込み元を途中から再開できるよう、処理済みの位置 (チェックポイント) を記録する
+type SyncState struct {
+	Source          string     `json:"source"`
+	Checkpoint      string     `json:"checkpoint,omitempty"` // 処理済みの最後のキー (空の場合は次の同期を先頭から始める)
+	Cursor          string     `json:"cursor,omitempty"`     // 最後に完了した同期で取り込んだ取り込み元の版 (gitのコミットなど。次回は差分だけを同期する)
+	Stats           SyncStats  `json:"stats"`                // 実行中 (または最後に完了した) 同期の処理件数
+	Running         bool       `json:"running"`              // いずれかのプロセスが同期を実行中かどうか
+	PassStartedAt   *time.Time `json:"pass_started_at,omitempty"`
+	LastCompletedAt *time.Time `json:"last_completed_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// syncStateColumns は同期の状態を読み込む列
+const syncStateColumns = `source, checkpoint, cursor, created, updated, deleted, unchanged, skipped, failed,
+    (lease_owner IS NOT NULL AND lease_until > NOW()), pass_started_at, last_completed_at, COALESCE(last_error, ''), updated_at`
+
+// scanSyncState は syncStateColumns の順で1行を読み込む
+func scanSyncState(row *sql.Row) (*SyncState, error) {
+	var state SyncState
+	err := row.Scan(&state.Source, &state.Checkpoint, &state.Cursor,
+		&state.Stats.Created, &state.Stats.Updated, &state.Stats.Deleted, &state.Stats.Unchanged, &state.Stats.Skipped, &state.Stats.Failed,
+		&state.Running, &state.PassStartedAt, &state.LastCompletedAt, &state.LastError, &state.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// GetSyncState は取り込み元の同期の状態を取得する (まだ同期していない場合は Source のみを設定した状態を返す)
+func (h *DBHandler) GetSyncState(ctx context.Context, source string) (*SyncState, error) {
+	state, err := scanSyncState(h.DB.QueryRowContext(ctx, `SELECT `+syncStateColumns+` FROM sync_states WHERE source = $1`, source))
+	if errors.Is(err, sql.ErrNoRows) {
+		return &SyncState{Source: source}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync state: %w", err)
+	}
+	return state, nil
+}
+
+// ClaimSyncState は同期の実行権 (リース) を取得し、同期の状態を返す
+// チェックポイントが空の場合は新しい同期として処理件数を初期化する
+// 他のプロセスが有効なリースを保持している場合は nil と false を返す
+func (h *DBHandler) ClaimSyncState(ctx context.Context, source, owner string, ttl time.Duration) (*SyncState, bool, error) {
+	if _, err := h.DB.ExecContext(ctx, `
+        INSERT INTO sync_states (source) VALUES ($1)
+        ON CONFLICT (source) DO NOTHING
+    `, source); err != nil {
+		return nil, false, fmt.Errorf("failed to initialize sync state: %w", err)
+	}
+
+	state, err := scanSyncState(h.DB.QueryRowContext(ctx, `
+        UPDATE sync_states
+        SET lease_owner = $2, lease_until = NOW() + make_interval(secs => $3), last_error = NULL,
+            created = CASE WHEN checkpoint = '' THEN 0 ELSE created END,
+            updated = CASE WHEN checkpoint = '' THEN 0 ELSE updated END,
+            deleted = CASE WHEN checkpoint = '' THEN 0 ELSE deleted END,
+            unchanged = CASE WHEN checkpoint = '' THEN 0 ELSE unchanged END,
+            skipped = CASE WHEN checkpoint = '' THEN 0 ELSE skipped END,
+            failed = CASE WHEN checkpoint = '' THEN 0 ELSE failed END,
+            pass_started_at = CASE WHEN checkpoint = '' THEN NOW() ELSE pass_started_at END,
+            updated_at = NOW()
+        WHERE source = $1
+          AND (lease_owner IS NULL OR lease_owner = $2 OR lease_until < NOW())
+        RETURNING `+syncStateColumns,
+		source, owner, ttl.Seconds()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to claim sync state: %w", err)
+	}
+	return state, true, nil
+}
+
+// SaveSyncCheckpoint は処理済みの位置と処理件数を記録し、リースを延長する
+// 他のプロセスに実行権が移っている場合は ErrSyncLeaseLost を返す
+func (h *DBHandler) SaveSyncCheckpoint(ctx context.Context, source, owner string, ttl time.Duration, checkpoint string, stats SyncStats) error {
+	result, err := h.DB.ExecContext(ctx, `
+        UPDATE sync_states
+        SET checkpoint = $3, created = $4, updated = $5, deleted = $6, unchanged = $7, skipped = $8, failed = $9,
+            lease_until = NOW() + make_interval(secs => $10), updated_at = NOW()
+        WHERE source = $1 AND lease_owner = $2
+    `, source, owner, checkpoint, stats.Created, stats.Updated, stats.Deleted, stats.Unchanged, stats.Skipped, stats.Failed, ttl.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to save sync checkpoint: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrSyncLeaseLost
+	}
+	return nil
+}
+
+// CompleteSyncPass は取り込み元の末尾まで同期したことを記録し、チェックポイントを先頭に戻してリースを解放する
+// cursor には同期した取り込み元の版を記録する (版のない取り込み元は空)
+func (h *DBHandler) CompleteSyncPass(ctx context.Context, source, owner, cursor string, stats SyncStats) error {
+	result, err := h.DB.ExecContext(ctx, `
+        UPDATE sync_states
+        SET checkpoint = '', cursor = $3, created = $4, updated = $5, deleted = $6, unchanged = $7, skipped = $8, failed = $9,
+            last_completed_at = NOW(), lease_owner = NULL, lease_until = NULL, updated_at = NOW()
+        WHERE source = $1 AND lease_owner = $2
+    `, source, owner, cursor, stats.Created, stats.Updated, stats.Deleted, stats.Unchanged, stats.Skipped, stats.Failed)
+	if err != nil {
+		return fmt.Errorf("failed to complete sync pass: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrSyncLeaseLost
+	}
+	return nil
+}
+
+// ReleaseSyncState は同期を中断してリースを解放する (チェックポイントは残し、次回の同期で続きから再開する)
+// message が空でない場合は中断の理由として記録する
+func (h *DBHandler) ReleaseSyncState(ctx context.Context, source, owner, message string) error {
+	if _, err := h.DB.ExecContext(ctx, `
+        UPDATE sync_states
+        SET lease_owner = NULL, lease_until = NULL, last_error = NULLIF($3, ''), updated_at = NOW()
+        WHERE source = $1 AND lease_owner = $2
+    `, source, owner, message); err != nil {
+		return fmt.Errorf("failed to release sync state: %w", err)
+	}
+	return nil
+}
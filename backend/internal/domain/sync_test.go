@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncStateRowColumns は syncStateColumns に対応するテスト用の列名
+var syncStateRowColumns = []string{"source", "checkpoint", "cursor", "created", "updated", "deleted", "unchanged", "skipped", "failed",
+	"running", "pass_started_at", "last_completed_at", "last_error", "updated_at"}
+
+func TestGetSyncState(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 同期の状態を返す", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		now := time.Now()
+		mock.ExpectQuery("FROM sync_states WHERE source = \\$1").WithArgs("s3://bucket/inbox/").
+			WillReturnRows(sqlmock.NewRows(syncStateRowColumns).
+				AddRow("s3://bucket/inbox/", "inbox/b.txt", "", 1, 2, 0, 3, 0, 0, true, now, nil, "", now))
+
+		state, err := h.GetSyncState(ctx, "s3://bucket/inbox/")
+
+		require.NoError(t, err)
+		assert.Equal(t, "inbox/b.txt", state.Checkpoint)
+		assert.Equal(t, SyncStats{Created: 1, Updated: 2, Unchanged: 3}, state.Stats)
+		assert.True(t, state.Running)
+		assert.Nil(t, state.LastCompletedAt)
+	})
+
+	t.Run("正常系: まだ同期していない", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("FROM sync_states").WillReturnRows(sqlmock.NewRows(syncStateRowColumns))
+
+		state, err := h.GetSyncState(ctx, "s3://bucket/inbox/")
+
+		require.NoError(t, err)
+		assert.Equal(t, &SyncState{Source: "s3://bucket/inbox/"}, state)
+	})
+}
+
+func TestClaimSyncState(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: リースを取得する", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		now := time.Now()
+		mock.ExpectExec("INSERT INTO sync_states .* ON CONFLICT \\(source\\) DO NOTHING").WithArgs("s3://bucket/inbox/").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("UPDATE sync_states\\s+SET lease_owner = \\$2").WithArgs("s3://bucket/inbox/", "host-1", float64(300)).
+			WillReturnRows(sqlmock.NewRows(syncStateRowColumns).
+				AddRow("s3://bucket/inbox/", "", "", 0, 0, 0, 0, 0, 0, true, now, nil, "", now))
+
+		state, claimed, err := h.ClaimSyncState(ctx, "s3://bucket/inbox/", "host-1", 5*time.Minute)
+
+		require.NoError(t, err)
+		assert.True(t, claimed)
+		assert.True(t, state.Running)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("正常系: 他のプロセスが実行中", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("INSERT INTO sync_states").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("UPDATE sync_states").WillReturnRows(sqlmock.NewRows(syncStateRowColumns))
+
+		state, claimed, err := h.ClaimSyncState(ctx, "s3://bucket/inbox/", "host-2", 5*time.Minute)
+
+		require.NoError(t, err)
+		assert.False(t, claimed)
+		assert.Nil(t, state)
+	})
+}
+
+func TestSaveSyncCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	stats := SyncStats{Created: 1, Deleted: 2, Failed: 1}
+
+	t.Run("正常系: チェックポイントを記録する", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("UPDATE sync_states\\s+SET checkpoint = \\$3").
+			WithArgs("s3://bucket/inbox/", "host-1", "inbox/b.txt", 1, 0, 2, 0, 0, 1, float64(300)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := h.SaveSyncCheckpoint(ctx, "s3://bucket/inbox/", "host-1", 5*time.Minute, "inbox/b.txt", stats)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: リースを失った", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("UPDATE sync_states").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := h.SaveSyncCheckpoint(ctx, "s3://bucket/inbox/", "host-1", 5*time.Minute, "inbox/b.txt", stats)
+
+		assert.ErrorIs(t, err, ErrSyncLeaseLost)
+	})
+}
+
+func TestCompleteSyncPass(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: チェックポイントを先頭に戻してリースを解放する", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("SET checkpoint = '', cursor = \\$3, .* last_completed_at = NOW\\(\\), lease_owner = NULL").
+			WithArgs("git+https://git.example.com/handbook.git#main:", "host-1", "0123abcd", 1, 0, 0, 4, 0, 0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := h.CompleteSyncPass(ctx, "git+https://git.example.com/handbook.git#main:", "host-1", "0123abcd", SyncStats{Created: 1, Unchanged: 4})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: リースを失った", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("UPDATE sync_states").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := h.CompleteSyncPass(ctx, "s3://bucket/inbox/", "host-1", "", SyncStats{})
+
+		assert.ErrorIs(t, err, ErrSyncLeaseLost)
+	})
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// UsageRecord はモデル呼び出し1回分のトークン使用量と料金
+type UsageRecord struct {
+	RequestID    string // ログとの突き合わせ用 (クライアントが指定した X-Request-ID の場合がある)
+	ScopeID      string // サーバーがリクエストごとに採番したID (リクエスト数の集計に使う)
+	Tenant       string
+	Endpoint     string
+	Model        string
+	Operation    string
+	InputTokens  int
+	OutputTokens int
+	CostUSD      float64 // 記録時点の料金表で算出した料金
+	CreatedAt    time.Time
+}
+
+// UsageFilter は使用量の集計対象の条件
+type UsageFilter struct {
+	From     time.Time // この時刻以降 (含む)
+	To       time.Time // この時刻より前 (含まない)
+	Tenant   string    // 空の場合は全テナント
+	Endpoint string    // 空の場合は全エンドポイント
+}
+
+// UsageAggregate は日 (UTC)・エンドポイント・テナントごとの使用量の集計
+type UsageAggregate struct {
+	Day          time.Time
+	Endpoint     string
+	Tenant       string
+	Requests     int // APIリクエスト数 (サーバーが採番したIDの種類数)
+	Calls        int // モデルの呼び出し回数
+	InputTokens  int64
+	OutputTokens int64
+	CostUSD      float64
+}
+
+// TenantUsage はテナントの予算の判定に使う使用量
+type TenantUsage struct {
+	MonthlyTokens  int64
+	MonthlyCostUSD float64
+	DailyRequests  int
+}
+
+// SaveUsageRecord は使用量を1件保存する
+func (h *DBHandler) SaveUsageRecord(ctx context.Context, record UsageRecord) error {
+	_, err := h.DB.ExecContext(ctx, `
+        INSERT INTO llm_usage (request_id, scope_id, tenant, endpoint, model, operation, input_tokens, output_tokens, cost_usd, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    `, record.RequestID, record.ScopeID, record.Tenant, record.Endpoint, record.Model, record.Operation,
+		record.InputTokens, record.OutputTokens, record.CostUSD, record.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save usage record: %w", err)
+	}
+	return nil
+}
+
+// AggregateUsage は条件に一致する使用量を日・エンドポイント・テナントごとに集計する
+func (h *DBHandler) AggregateUsage(ctx context.Context, filter UsageFilter) ([]UsageAggregate, error) {
+	rows, err := h.DB.QueryContext(ctx, `
+        SELECT (created_at AT TIME ZONE 'UTC')::date AS day, endpoint, tenant,
+               COUNT(DISTINCT scope_id), COUNT(*),
+               COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(cost_usd), 0)
+        FROM llm_usage
+        WHERE created_at >= $1 AND created_at < $2
+          AND ($3 = '' OR tenant = $3)
+          AND ($4 = '' OR endpoint = $4)
+        GROUP BY day, endpoint, tenant
+        ORDER BY day, endpoint, tenant
+    `, filter.From, filter.To, filter.Tenant, filter.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate usage: %w", err)
+	}
+	defer rows.Close()
+
+	var aggregates []UsageAggregate
+	for rows.Next() {
+		var a UsageAggregate
+		if err := rows.Scan(&a.Day, &a.Endpoint, &a.Tenant, &a.Requests, &a.Calls, &a.InputTokens, &a.OutputTokens, &a.CostUSD); err != nil {
+			return nil, fmt.Errorf("failed to scan usage aggregate: %w", err)
+		}
+		aggregates = append(aggregates, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage aggregates: %w", err)
+	}
+	return aggregates, nil
+}
+
+// GetTenantUsage はテナントの monthStart 以降のトークン数・料金と、dayStart 以降のリクエスト数を返す
+// リクエスト数はクライアントが指定できる request_id ではなく、サーバーが採番した scope_id の種類数で数える
+func (h *DBHandler) GetTenantUsage(ctx context.Context, tenant string, monthStart, dayStart time.Time) (TenantUsage, error) {
+	var usage TenantUsage
+	err := h.DB.QueryRowContext(ctx, `
+        SELECT COALESCE(SUM(input_tokens + output_tokens) FILTER (WHERE created_at >= $2), 0),
+               COALESCE(SUM(cost_usd) FILTER (WHERE created_at >= $2), 0),
+               COUNT(DISTINCT scope_id) FILTER (WHERE created_at >= $3)
+        FROM llm_usage
+        WHERE tenant = $1 AND created_at >= LEAST($2::timestamptz, $3::timestamptz)
+    `, tenant, monthStart, dayStart).Scan(&usage.MonthlyTokens, &usage.MonthlyCostUSD, &usage.DailyRequests)
+	if err != nil {
+		return TenantUsage{}, fmt.Errorf("failed to get tenant usage: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveUsageRecord(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("正常系", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectExec("INSERT INTO llm_usage").
+			WithArgs("req-1", "3MZQ7KXW", "tenant-a", "/api/v1/qa", "claude-3-haiku", "converse", 100, 20, 0.00005, now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := h.SaveUsageRecord(ctx, UsageRecord{
+			RequestID: "req-1", ScopeID: "3MZQ7KXW", Tenant: "tenant-a", Endpoint: "/api/v1/qa", Model: "claude-3-haiku", Operation: "converse",
+			InputTokens: 100, OutputTokens: 20, CostUSD: 0.00005, CreatedAt: now,
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 保存エラー", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		dbErr := errors.New("connection refused")
+		mock.ExpectExec("INSERT INTO llm_usage").WillReturnError(dbErr)
+
+		err := h.SaveUsageRecord(ctx, UsageRecord{CreatedAt: now})
+
+		assert.ErrorIs(t, err, dbErr)
+	})
+}
+
+func TestAggregateUsage(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	t.Run("正常系: 日・エンドポイント・テナントごとの集計", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		mock.ExpectQuery("FROM llm_usage").
+			WithArgs(from, to, "tenant-a", "").
+			WillReturnRows(sqlmock.NewRows([]string{"day", "endpoint", "tenant", "requests", "calls", "input_tokens", "output_tokens", "cost_usd"}).
+				AddRow(from, "/api/v1/qa", "tenant-a", 3, 6, 1200, 300, 0.0123).
+				AddRow(from.AddDate(0, 0, 1), "/api/v1/summarize/text", "tenant-a", 1, 1, 500, 100, 0.001))
+
+		aggregates, err := h.AggregateUsage(ctx, UsageFilter{From: from, To: to, Tenant: "tenant-a"})
+
+		require.NoError(t, err)
+		require.Len(t, aggregates, 2)
+		assert.Equal(t, UsageAggregate{
+			Day: from, Endpoint: "/api/v1/qa", Tenant: "tenant-a",
+			Requests: 3, Calls: 6, InputTokens: 1200, OutputTokens: 300, CostUSD: 0.0123,
+		}, aggregates[0])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: クエリエラー", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		dbErr := errors.New("timeout")
+		mock.ExpectQuery("FROM llm_usage").WillReturnError(dbErr)
+
+		_, err := h.AggregateUsage(ctx, UsageFilter{From: from, To: to})
+
+		assert.ErrorIs(t, err, dbErr)
+	})
+}
+
+func TestGetTenantUsage(t *testing.T) {
+	ctx := context.Background()
+	monthStart := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	dayStart := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	t.Run("正常系", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		// リクエスト数はクライアントが指定できる request_id ではなく、サーバーが採番した scope_id で数える
+		mock.ExpectQuery(`COUNT\(DISTINCT scope_id\) FILTER \(WHERE created_at >= \$3\)\s+FROM llm_usage`).
+			WithArgs("tenant-a", monthStart, dayStart).
+			WillReturnRows(sqlmock.NewRows([]string{"tokens", "cost", "requests"}).AddRow(150000, 1.25, 42))
+
+		usage, err := h.GetTenantUsage(ctx, "tenant-a", monthStart, dayStart)
+
+		require.NoError(t, err)
+		assert.Equal(t, TenantUsage{MonthlyTokens: 150000, MonthlyCostUSD: 1.25, DailyRequests: 42}, usage)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: クエリエラー", func(t *testing.T) {
+		h, mock, cleanup := setupMockDB(t)
+		defer cleanup()
+
+		dbErr := errors.New("timeout")
+		mock.ExpectQuery("FROM llm_usage").WillReturnError(dbErr)
+
+		_, err := h.GetTenantUsage(ctx, "tenant-a", monthStart, dayStart)
+
+		assert.ErrorIs(t, err, dbErr)
+	})
+}
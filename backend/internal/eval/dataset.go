@@ -0,0 +1,108 @@
+package eval
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// GoldenCase はゴールデンデータセットの1件 (質問と期待する結果)
+type GoldenCase struct {
+	ID                  string  `json:"id"`                    // 省略した場合は行番号
+	Question            string  `json:"question"`              // 評価する質問
+	ExpectedDocumentIDs []int64 `json:"expected_document_ids"` // 検索されるべき論理ドキュメントのID (空の場合は検索の指標を計算しない)
+	ReferenceAnswer     string  `json:"reference_answer"`      // 模範回答 (空の場合は回答類似度を計算しない)
+}
+
+// CorpusDocument はオフライン評価で取り込むドキュメント
+type CorpusDocument struct {
+	ID       int64  `json:"id"` // 論理ドキュメントのID (ゴールデンデータセットの expected_document_ids と対応する)
+	Filename string `json:"filename"`
+	Content  string `json:"content"`
+}
+
+// maxLineSize はJSONLの1行の最大サイズ
+const maxLineSize = 4 * 1024 * 1024
+
+// LoadGoldenSet はJSONL形式のゴールデンデータセットを読み込む
+// 空行は無視し、IDの重複や質問のない行はエラーとする
+func LoadGoldenSet(r io.Reader) ([]GoldenCase, error) {
+	var cases []GoldenCase
+	seen := make(map[string]bool)
+	err := readJSONLines(r, func(line int, data []byte) error {
+		var c GoldenCase
+		if err := json.Unmarshal(data, &c); err != nil {
+			return err
+		}
+		if c.Question == "" {
+			return errors.New("question が空です")
+		}
+		if c.ID == "" {
+			c.ID = strconv.Itoa(line)
+		}
+		if seen[c.ID] {
+			return fmt.Errorf("id %q が重複しています", c.ID)
+		}
+		seen[c.ID] = true
+		cases = append(cases, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(cases) == 0 {
+		return nil, errors.New("ゴールデンデータセットが空です")
+	}
+	return cases, nil
+}
+
+// LoadCorpus はJSONL形式のオフライン評価用のドキュメントを読み込む
+func LoadCorpus(r io.Reader) ([]CorpusDocument, error) {
+	var docs []CorpusDocument
+	seen := make(map[int64]bool)
+	err := readJSONLines(r, func(_ int, data []byte) error {
+		var d CorpusDocument
+		if err := json.Unmarshal(data, &d); err != nil {
+			return err
+		}
+		if d.ID <= 0 {
+			return errors.New("id は1以上で指定してください")
+		}
+		if seen[d.ID] {
+			return fmt.Errorf("id %d が重複しています", d.ID)
+		}
+		seen[d.ID] = true
+		if d.Filename == "" {
+			d.Filename = fmt.Sprintf("document-%d.txt", d.ID)
+		}
+		docs = append(docs, d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// readJSONLines はJSONLの空行以外の各行を fn に渡す (line は1から始まる行番号)
+func readJSONLines(r io.Reader, fn func(line int, data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if err := fn(line, data); err != nil {
+			return fmt.Errorf("%d行目: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("JSONLの読み込みに失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package eval
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadGoldenSet(t *testing.T) {
+	t.Run("正常系: 空行を無視し、IDを省略した場合は行番号にする", func(t *testing.T) {
+		input := `{"id": "a", "question": "q1", "expected_document_ids": [1, 2], "reference_answer": "r1"}
+
+{"question": "q2"}
+`
+		cases, err := LoadGoldenSet(strings.NewReader(input))
+
+		require.NoError(t, err)
+		require.Len(t, cases, 2)
+		assert.Equal(t, GoldenCase{ID: "a", Question: "q1", ExpectedDocumentIDs: []int64{1, 2}, ReferenceAnswer: "r1"}, cases[0])
+		assert.Equal(t, "3", cases[1].ID)
+	})
+
+	t.Run("正常系: testdata のデータセットを読み込める", func(t *testing.T) {
+		f, err := os.Open("testdata/golden.jsonl")
+		require.NoError(t, err)
+		defer f.Close()
+
+		cases, err := LoadGoldenSet(f)
+
+		require.NoError(t, err)
+		assert.Len(t, cases, 4)
+	})
+
+	t.Run("異常系: 不正な行は行番号付きのエラー", func(t *testing.T) {
+		_, err := LoadGoldenSet(strings.NewReader("{\"question\": \"q1\"}\n{broken"))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2行目")
+	})
+
+	t.Run("異常系: 質問がない", func(t *testing.T) {
+		_, err := LoadGoldenSet(strings.NewReader(`{"id": "a"}`))
+
+		assert.ErrorContains(t, err, "question が空です")
+	})
+
+	t.Run("異常系: IDの重複", func(t *testing.T) {
+		_, err := LoadGoldenSet(strings.NewReader("{\"id\": \"a\", \"question\": \"q1\"}\n{\"id\": \"a\", \"question\": \"q2\"}"))
+
+		assert.ErrorContains(t, err, "重複")
+	})
+
+	t.Run("異常系: 空のデータセット", func(t *testing.T) {
+		_, err := LoadGoldenSet(strings.NewReader("\n"))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadCorpus(t *testing.T) {
+	t.Run("正常系: ファイル名を省略した場合はIDから付ける", func(t *testing.T) {
+		docs, err := LoadCorpus(strings.NewReader(`{"id": 7, "content": "本文"}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, []CorpusDocument{{ID: 7, Filename: "document-7.txt", Content: "本文"}}, docs)
+	})
+
+	t.Run("異常系: IDがない", func(t *testing.T) {
+		_, err := LoadCorpus(strings.NewReader(`{"content": "本文"}`))
+
+		assert.Error(t, err)
+	})
+}
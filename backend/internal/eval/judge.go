@@ -0,0 +1,70 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"bedrock-rag-sample/backend/pkg/aws"
+)
+
+// judgePromptHeader は忠実性を評価するプロンプトの冒頭
+// オフラインのフェイクはこの見出しで評価のプロンプトを見分ける
+const judgePromptHeader = "あなたはRAGシステムの回答を評価する審査員です。"
+
+// Judgement はLLMによる忠実性の評価結果
+type Judgement struct {
+	Score  float64 `json:"score"`  // 0 (根拠のない回答) から 1 (参考情報だけに基づく回答)
+	Reason string  `json:"reason"` // 評価の理由
+}
+
+// judgeFaithfulness は回答が参考情報だけに基づいているか (忠実性) をLLMに評価させる
+func judgeFaithfulness(ctx context.Context, client aws.BedrockClientInterface, question, answer string, contexts []string) (*Judgement, error) {
+	output, err := client.GenerateText(ctx, buildJudgePrompt(question, answer, contexts))
+	if err != nil {
+		return nil, fmt.Errorf("忠実性の評価に失敗しました: %w", err)
+	}
+	return parseJudgement(output)
+}
+
+// buildJudgePrompt は忠実性を評価するプロンプトを構築する
+func buildJudgePrompt(question, answer string, contexts []string) string {
+	var sb strings.Builder
+	sb.WriteString(judgePromptHeader + "\n")
+	sb.WriteString("回答が参考情報の内容だけに基づいているか (忠実性) を0から1の数値で評価してください。\n")
+	sb.WriteString("参考情報にない内容や矛盾する内容が多いほど低く評価します。\n")
+	sb.WriteString(`次のJSONのみを出力してください: {"score": <0から1の数値>, "reason": "<評価の理由>"}` + "\n\n")
+
+	sb.WriteString("<context>\n")
+	for i, c := range contexts {
+		sb.WriteString(fmt.Sprintf("文書[%d]:\n%s\n", i+1, c))
+	}
+	sb.WriteString("</context>\n\n")
+	sb.WriteString(fmt.Sprintf("<question>\n%s\n</question>\n\n", question))
+	sb.WriteString(fmt.Sprintf("<answer>\n%s\n</answer>", answer))
+	return sb.String()
+}
+
+// parseJudgement はLLMの出力から評価のJSONを取り出す
+// JSONの前後に説明文が付いていても受け付け、スコアは0から1の範囲に収める
+func parseJudgement(output string) (*Judgement, error) {
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("評価結果にJSONが含まれていません: %q", output)
+	}
+
+	var raw struct {
+		Score  *float64 `json:"score"`
+		Reason string   `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(output[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("評価結果のJSONの解析に失敗しました: %w", err)
+	}
+	if raw.Score == nil {
+		return nil, errors.New("評価結果に score が含まれていません")
+	}
+	return &Judgement{Score: min(max(*raw.Score, 0), 1), Reason: raw.Reason}, nil
+}
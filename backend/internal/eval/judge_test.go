@@ -0,0 +1,36 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJudgement(t *testing.T) {
+	t.Run("正常系: 前後の説明文を無視してJSONを取り出す", func(t *testing.T) {
+		j, err := parseJudgement("評価します。\n{\"score\": 0.8, \"reason\": \"概ね根拠あり\"}\n以上")
+
+		require.NoError(t, err)
+		assert.Equal(t, &Judgement{Score: 0.8, Reason: "概ね根拠あり"}, j)
+	})
+
+	t.Run("正常系: 範囲外のスコアは0から1に収める", func(t *testing.T) {
+		j, err := parseJudgement(`{"score": -3}`)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, j.Score)
+	})
+
+	t.Run("異常系: JSONがない", func(t *testing.T) {
+		_, err := parseJudgement("良い回答です")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("異常系: スコアがない", func(t *testing.T) {
+		_, err := parseJudgement(`{"reason": "不明"}`)
+
+		assert.ErrorContains(t, err, "score")
+	})
+}
@@ -0,0 +1,86 @@
+package eval
+
+import (
+	"math"
+)
+
+// RecallAtK は上位 k 件に含まれる期待ドキュメントの割合を返す
+func RecallAtK(ranked, expected []int64, k int) float64 {
+	if len(expected) == 0 {
+		return 0
+	}
+	relevant := toSet(expected)
+	hits := 0
+	for _, id := range topK(ranked, k) {
+		if relevant[id] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(relevant))
+}
+
+// ReciprocalRank は最初に見つかった期待ドキュメントの順位の逆数を返す (上位 k 件にない場合は0)
+// 平均を取ると MRR (Mean Reciprocal Rank) になる
+func ReciprocalRank(ranked, expected []int64, k int) float64 {
+	relevant := toSet(expected)
+	for i, id := range topK(ranked, k) {
+		if relevant[id] {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// NDCGAtK は上位 k 件の nDCG を返す
+// 関連度は期待ドキュメントを1、それ以外を0とする二値で計算する
+func NDCGAtK(ranked, expected []int64, k int) float64 {
+	relevant := toSet(expected)
+	if len(relevant) == 0 {
+		return 0
+	}
+	var dcg float64
+	for i, id := range topK(ranked, k) {
+		if relevant[id] {
+			dcg += 1 / math.Log2(float64(i+2))
+		}
+	}
+	var ideal float64
+	for i := 0; i < min(len(relevant), k); i++ {
+		ideal += 1 / math.Log2(float64(i+2))
+	}
+	return dcg / ideal
+}
+
+// CosineSimilarity は2つのベクトルのコサイン類似度を返す (次元が異なる場合やゼロベクトルの場合は0)
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// topK は ranked の先頭 k 件を返す
+func topK(ranked []int64, k int) []int64 {
+	if k > 0 && len(ranked) > k {
+		return ranked[:k]
+	}
+	return ranked
+}
+
+// toSet はIDの集合を返す
+func toSet(ids []int64) map[int64]bool {
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
@@ -0,0 +1,38 @@
+package eval
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetrievalMetrics(t *testing.T) {
+	ranked := []int64{4, 1, 3, 2}
+
+	t.Run("正常系: recall@k は上位k件に含まれる期待ドキュメントの割合", func(t *testing.T) {
+		assert.Equal(t, 0.5, RecallAtK(ranked, []int64{1, 2}, 3))
+		assert.Equal(t, 1.0, RecallAtK(ranked, []int64{1, 2}, 4))
+		assert.Equal(t, 0.0, RecallAtK(ranked, nil, 3))
+	})
+
+	t.Run("正常系: 逆順位は最初に見つかった期待ドキュメントの順位の逆数", func(t *testing.T) {
+		assert.Equal(t, 0.5, ReciprocalRank(ranked, []int64{1, 2}, 3))
+		assert.Equal(t, 1.0, ReciprocalRank(ranked, []int64{4}, 3))
+		assert.Equal(t, 0.0, ReciprocalRank(ranked, []int64{2}, 3))
+	})
+
+	t.Run("正常系: nDCG@k は理想の順位で1になる", func(t *testing.T) {
+		assert.Equal(t, 1.0, NDCGAtK(ranked, []int64{4, 1}, 3))
+		expected := (1 / math.Log2(3)) / (1 + 1/math.Log2(3))
+		assert.InDelta(t, expected, NDCGAtK(ranked, []int64{1, 2}, 3), 1e-9)
+		assert.Equal(t, 0.0, NDCGAtK(ranked, []int64{9}, 3))
+	})
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 1.0, CosineSimilarity([]float32{1, 2}, []float32{2, 4}), 1e-9)
+	assert.InDelta(t, 0.0, CosineSimilarity([]float32{1, 0}, []float32{0, 1}), 1e-9)
+	assert.Equal(t, 0.0, CosineSimilarity([]float32{1}, []float32{1, 0}))
+	assert.Equal(t, 0.0, CosineSimilarity([]float32{0, 0}, []float32{1, 0}))
+}
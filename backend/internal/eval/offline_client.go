@@ -0,0 +1,256 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"bedrock-rag-sample/backend/pkg/aws"
+)
+
+// OfflineEmbeddingModel はオフラインのフェイクが生成するEmbeddingのモデル
+var OfflineEmbeddingModel = aws.EmbeddingModel{Name: "offline-hash-256", ModelID: "offline.hash-bigram-256", Dimension: 256, MaxBatchSize: 16}
+
+// OfflineTextModel はオフラインのフェイクが回答を生成するモデル
+var OfflineTextModel = aws.TextModel{Name: "offline-extractive", ModelID: "offline.extractive", Family: "offline", MaxTokens: 1024, SupportsSystemPrompt: true}
+
+// offlineNoAnswer は参考情報がない場合のオフラインの回答
+const offlineNoAnswer = "提供された情報からは回答できません。"
+
+// OfflineBedrockClient はBedrockを呼び出さずに決定的な結果を返すフェイク
+// 評価やCLIをAWSの認証情報なしで実行するために使用する
+//   - Embedding: 文字bigramをハッシュしたベクトル (L2正規化済み)
+//   - テキスト生成: プロンプトの参考情報から質問に最も近い文を抜き出す
+//   - 忠実性の評価: 回答の文字bigramのうち参考情報に含まれる割合をスコアにする
+type OfflineBedrockClient struct {
+	textModel      aws.TextModel
+	embeddingModel aws.EmbeddingModel
+}
+
+// NewOfflineBedrockClient は新しいOfflineBedrockClientを生成する
+func NewOfflineBedrockClient() *OfflineBedrockClient {
+	return &OfflineBedrockClient{
+		textModel:      OfflineTextModel,
+		embeddingModel: OfflineEmbeddingModel,
+	}
+}
+
+// インターフェースを実装していることを静的にチェック
+var _ aws.BedrockClientInterface = (*OfflineBedrockClient)(nil)
+
+// GenerateSummary は先頭の文を要約として返す
+func (c *OfflineBedrockClient) GenerateSummary(_ context.Context, text string) (string, error) {
+	sentences := splitSentences(text)
+	if len(sentences) == 0 {
+		return "", nil
+	}
+	return strings.Join(sentences[:min(len(sentences), 3)], ""), nil
+}
+
+// GenerateText はプロンプトに対する決定的な応答を返す
+func (c *OfflineBedrockClient) GenerateText(_ context.Context, prompt string) (string, error) {
+	if strings.HasPrefix(prompt, judgePromptHeader) {
+		return offlineJudge(prompt)
+	}
+	return offlineAnswer(prompt), nil
+}
+
+// Converse は最後のユーザーのメッセージに GenerateText と同じ応答を返す
+func (c *OfflineBedrockClient) Converse(ctx context.Context, req aws.ConverseRequest) (*aws.ConverseResponse, error) {
+	var prompt string
+	for i := len(req.Messages) - 1; i >= 0 && prompt == ""; i-- {
+		if req.Messages[i].Role != aws.ConverseRoleUser {
+			continue
+		}
+		for _, content := range req.Messages[i].Content {
+			prompt += content.Text
+		}
+	}
+	text, err := c.GenerateText(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return &aws.ConverseResponse{
+		Model:      c.textModel.Name,
+		Message:    aws.ConverseMessage{Role: aws.ConverseRoleAssistant, Content: []aws.ConverseContent{{Text: text}}},
+		StopReason: "end_turn",
+	}, nil
+}
+
+// TextModel はテキスト生成のモデルを返す
+func (c *OfflineBedrockClient) TextModel() aws.TextModel {
+	return c.textModel
+}
+
+// WithTextModel はテキスト生成のモデル名だけを差し替えたクライアントを返す (応答は変わらない)
+func (c *OfflineBedrockClient) WithTextModel(model aws.TextModel) aws.BedrockClientInterface {
+	clone := *c
+	clone.textModel = model
+	return &clone
+}
+
+// GenerateEmbedding はテキストの文字bigramをハッシュしたベクトルを返す
+func (c *OfflineBedrockClient) GenerateEmbedding(_ context.Context, text string) ([]float32, error) {
+	return hashEmbedding(text, c.embeddingModel.Dimension), nil
+}
+
+// GenerateEmbeddings は複数テキストのEmbeddingを入力と同じ順序で返す
+func (c *OfflineBedrockClient) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i], _ = c.GenerateEmbedding(ctx, text)
+	}
+	return embeddings, nil
+}
+
+// EmbeddingModel はEmbeddingのモデルを返す
+func (c *OfflineBedrockClient) EmbeddingModel() aws.EmbeddingModel {
+	return c.embeddingModel
+}
+
+// WithEmbeddingModel はEmbeddingのモデルを差し替えたクライアントを返す (ベクトルの次元数もモデルに合わせる)
+func (c *OfflineBedrockClient) WithEmbeddingModel(model aws.EmbeddingModel) aws.BedrockClientInterface {
+	clone := *c
+	clone.embeddingModel = model
+	return &clone
+}
+
+// hashEmbedding は文字bigramを dimension 次元にハッシュしたL2正規化済みのベクトルを返す
+func hashEmbedding(text string, dimension int) []float32 {
+	vector := make([]float32, dimension)
+	if dimension == 0 {
+		return vector
+	}
+	for _, gram := range bigrams(text) {
+		h := fnv.New32a()
+		h.Write([]byte(gram))
+		vector[h.Sum32()%uint32(dimension)]++
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return vector
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] = float32(float64(vector[i]) / norm)
+	}
+	return vector
+}
+
+// bigrams は空白と記号を除いて小文字にしたテキストの文字bigramを返す (1文字の場合はその文字)
+func bigrams(text string) []string {
+	var runes []rune
+	for _, r := range text {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			continue
+		}
+		runes = append(runes, unicode.ToLower(r))
+	}
+	if len(runes) == 1 {
+		return []string{string(runes)}
+	}
+	grams := make([]string, 0, max(len(runes)-1, 0))
+	for i := 0; i+1 < len(runes); i++ {
+		grams = append(grams, string(runes[i:i+2]))
+	}
+	return grams
+}
+
+// bigramOverlap は a の文字bigramのうち b に含まれる割合を返す
+func bigramOverlap(a, b string) float64 {
+	grams := bigrams(a)
+	if len(grams) == 0 {
+		return 0
+	}
+	set := make(map[string]bool)
+	for _, gram := range bigrams(b) {
+		set[gram] = true
+	}
+	hits := 0
+	for _, gram := range grams {
+		if set[gram] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(grams))
+}
+
+// splitSentences はテキストを句点と改行で文に分割する (句点は文に含める)
+func splitSentences(text string) []string {
+	var sentences []string
+	var sb strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(sb.String()); s != "" {
+			sentences = append(sentences, s)
+		}
+		sb.Reset()
+	}
+	for _, r := range text {
+		if r == '\n' {
+			flush()
+			continue
+		}
+		sb.WriteRune(r)
+		if r == '。' || r == '！' || r == '？' {
+			flush()
+		}
+	}
+	flush()
+	return sentences
+}
+
+// offlineAnswer はRAGのプロンプト (buildRAGPrompt の形式) の参考情報から、質問と最も重なる文を回答として返す
+func offlineAnswer(prompt string) string {
+	reference, question := prompt, prompt
+	if i := strings.LastIndex(prompt, "質問: "); i >= 0 {
+		reference, question = prompt[:i], prompt[i+len("質問: "):]
+	}
+
+	var best string
+	var bestScore float64
+	for _, sentence := range splitSentences(reference) {
+		if strings.HasPrefix(sentence, "以下は質問に関連する情報です") || strings.HasPrefix(sentence, "文書[") {
+			continue
+		}
+		if s := bigramOverlap(question, sentence); s > bestScore {
+			best, bestScore = sentence, s
+		}
+	}
+	if best == "" {
+		return offlineNoAnswer
+	}
+	return best
+}
+
+// offlineJudge は評価のプロンプト (buildJudgePrompt の形式) の回答が参考情報に含まれる割合をスコアとして返す
+func offlineJudge(prompt string) (string, error) {
+	reference := section(prompt, "context")
+	answer := section(prompt, "answer")
+	score := math.Round(bigramOverlap(answer, reference)*100) / 100
+	output, err := json.Marshal(Judgement{
+		Score:  score,
+		Reason: fmt.Sprintf("回答の文字bigramのうち%.0f%%が参考情報に含まれています", score*100),
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// section はプロンプトの <name>...</name> で囲まれた部分を返す
+func section(prompt, name string) string {
+	start := strings.Index(prompt, "<"+name+">")
+	end := strings.LastIndex(prompt, "</"+name+">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return strings.TrimSpace(prompt[start+len(name)+2 : end])
+}
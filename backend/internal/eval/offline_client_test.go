@@ -0,0 +1,51 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfflineBedrockClient(t *testing.T) {
+	ctx := context.Background()
+	client := NewOfflineBedrockClient()
+
+	t.Run("正常系: Embeddingは決定的でL2正規化されている", func(t *testing.T) {
+		a, err := client.GenerateEmbedding(ctx, "有給休暇の申請")
+		require.NoError(t, err)
+		b, err := client.GenerateEmbedding(ctx, "有給休暇の申請")
+		require.NoError(t, err)
+
+		assert.Equal(t, a, b)
+		assert.Len(t, a, OfflineEmbeddingModel.Dimension)
+		assert.InDelta(t, 1.0, CosineSimilarity(a, a), 1e-6)
+	})
+
+	t.Run("正常系: 参考情報から質問に最も近い文を回答する", func(t *testing.T) {
+		prompt := "以下は質問に関連する情報です:\n\n文書[1]:\n経費は月末締めです。有給休暇の申請は3日前までです。\n\n質問: 有給休暇の申請期限は？"
+
+		answer, err := client.GenerateText(ctx, prompt)
+
+		require.NoError(t, err)
+		assert.Equal(t, "有給休暇の申請は3日前までです。", answer)
+	})
+
+	t.Run("正常系: 参考情報がない場合は回答できない旨を返す", func(t *testing.T) {
+		answer, err := client.GenerateText(ctx, "質問: 有給休暇の申請期限は？")
+
+		require.NoError(t, err)
+		assert.Equal(t, offlineNoAnswer, answer)
+	})
+
+	t.Run("正常系: 忠実性の評価は参考情報に含まれる割合をスコアにする", func(t *testing.T) {
+		faithful, err := judgeFaithfulness(ctx, client, "期限は？", "申請は3日前まで", []string{"申請は3日前までです。"})
+		require.NoError(t, err)
+		unfaithful, err := judgeFaithfulness(ctx, client, "期限は？", "翌月10日に支払われます", []string{"申請は3日前までです。"})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1.0, faithful.Score)
+		assert.Less(t, unfaithful.Score, 0.5)
+	})
+}
@@ -0,0 +1,201 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/internal/services"
+)
+
+// MemoryIndex はドキュメントとチャンクのEmbeddingをメモリに保持するDBのフェイク
+// RecommendService が使用するメソッドのみを実装し、それ以外のメソッドを呼び出すと panic する
+type MemoryIndex struct {
+	domain.DBHandlerInterface
+
+	mu        sync.RWMutex
+	space     *domain.EmbeddingSpace
+	documents map[int64]*domain.Document
+	chunks    []memoryChunk
+	lastID    int64 // 最後に採番したチャンクのID
+}
+
+// memoryChunk は保存したチャンク
+type memoryChunk struct {
+	id         int64
+	documentID int64
+	chunkIndex int
+	content    string
+	embedding  []float32
+}
+
+// NewMemoryIndex は空のMemoryIndexを生成する
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{documents: make(map[int64]*domain.Document)}
+}
+
+// AddDocument はドキュメントを登録する (論理ドキュメントIDと版が未設定の場合は最初の版とする)
+func (m *MemoryIndex) AddDocument(doc *domain.Document) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *doc
+	if stored.LogicalID == 0 {
+		stored.LogicalID = stored.ID
+	}
+	if stored.Version == 0 {
+		stored.Version = 1
+		stored.IsLatest = true
+	}
+	m.documents[stored.ID] = &stored
+}
+
+// GetEmbeddingIndexState は検索に使用するモデルを返す (最初に指定されたモデルを記録する)
+func (m *MemoryIndex) GetEmbeddingIndexState(_ context.Context, initial domain.EmbeddingSpace) (*domain.EmbeddingIndexState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.space == nil {
+		m.space = &initial
+	}
+	return &domain.EmbeddingIndexState{Active: *m.space}, nil
+}
+
+// SaveDocumentChunks はドキュメントのチャンクを置き換える
+// オフラインではモデルを切り替えないため、shadow は保存しない
+func (m *MemoryIndex) SaveDocumentChunks(_ context.Context, documentID int64, space domain.EmbeddingSpace, chunks []domain.ChunkEmbedding, _ *domain.ShadowEmbeddingSet) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, chunk := range chunks {
+		if len(chunk.Embedding) != space.Dimension {
+			return fmt.Errorf("%w: chunk %d has %d dimensions, model %s expects %d", domain.ErrEmbeddingDimensionMismatch, chunk.ChunkIndex, len(chunk.Embedding), space.Model, space.Dimension)
+		}
+	}
+
+	kept := m.chunks[:0]
+	for _, chunk := range m.chunks {
+		if chunk.documentID != documentID {
+			kept = append(kept, chunk)
+		}
+	}
+	m.chunks = kept
+	for _, chunk := range chunks {
+		m.lastID++
+		m.chunks = append(m.chunks, memoryChunk{
+			id:         m.lastID,
+			documentID: documentID,
+			chunkIndex: chunk.ChunkIndex,
+			content:    chunk.Content,
+			embedding:  chunk.Embedding,
+		})
+	}
+	return nil
+}
+
+// FindSimilarChunks はL2距離が近い順にチャンクを返す (DBHandler と同じく Similarity は距離)
+func (m *MemoryIndex) FindSimilarChunks(_ context.Context, space domain.EmbeddingSpace, embedding []float32, limit int, filter domain.ChunkFilter) ([]domain.DocumentChunk, error) {
+	if len(embedding) != space.Dimension {
+		return nil, fmt.Errorf("%w: query has %d dimensions, model %s expects %d", domain.ErrEmbeddingDimensionMismatch, len(embedding), space.Model, space.Dimension)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var results []domain.DocumentChunk
+	for _, chunk := range m.chunks {
+		doc := m.documents[chunk.documentID]
+		if doc == nil || (!filter.AllVersions && !doc.IsLatest) {
+			continue
+		}
+		results = append(results, domain.DocumentChunk{
+			ID:         chunk.id,
+			DocumentID: chunk.documentID,
+			ChunkIndex: chunk.chunkIndex,
+			Content:    chunk.content,
+			Similarity: l2Distance(embedding, chunk.embedding),
+		})
+	}
+	// 距離が同じ場合も実行ごとに順位が変わらないようチャンクIDで並べる
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Similarity != results[j].Similarity {
+			return results[i].Similarity < results[j].Similarity
+		}
+		return results[i].ID < results[j].ID
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// GetDocumentByID はドキュメントを返す
+func (m *MemoryIndex) GetDocumentByID(_ context.Context, documentID int64) (*domain.Document, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	doc, ok := m.documents[documentID]
+	if !ok {
+		return nil, domain.ErrDocumentNotFound
+	}
+	copied := *doc
+	return &copied, nil
+}
+
+// l2Distance は2つのベクトルのL2距離を返す
+func l2Distance(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		d := float64(a[i]) - float64(b[i])
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// OfflineEnvironment はオフラインのフェイクで組み立てたQAサービスと検索
+type OfflineEnvironment struct {
+	BedrockClient    *OfflineBedrockClient
+	Index            *MemoryIndex
+	QAService        *services.QAService
+	RecommendService *services.RecommendService
+}
+
+// offlineKnowledgeBaseID はオフラインのQAサービスに設定するKnowledge Base ID (AWSには接続しない)
+const offlineKnowledgeBaseID = "offline"
+
+// NewOfflineEnvironment はコーパスを取り込んだオフラインの環境を生成する
+func NewOfflineEnvironment(ctx context.Context, corpus []CorpusDocument) (*OfflineEnvironment, error) {
+	client := NewOfflineBedrockClient()
+	index := NewMemoryIndex()
+	recommendService := services.NewRecommendService(client, index, nil)
+
+	cfg := &config.Config{AWS: config.AWSConfig{Region: "us-east-1", KnowledgeBaseID: offlineKnowledgeBaseID}}
+	qaService, err := services.NewQAService(client, cfg, nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("QAサービスの初期化に失敗しました: %w", err)
+	}
+
+	for _, c := range corpus {
+		doc := &domain.Document{ID: c.ID, Filename: c.Filename, Content: c.Content}
+		index.AddDocument(doc)
+		if err := recommendService.ProcessDocumentForEmbedding(ctx, doc); err != nil {
+			return nil, fmt.Errorf("ドキュメント %d の取り込みに失敗しました: %w", c.ID, err)
+		}
+	}
+
+	return &OfflineEnvironment{
+		BedrockClient:    client,
+		Index:            index,
+		QAService:        qaService,
+		RecommendService: recommendService,
+	}, nil
+}
+
+// NewRunner はオフラインの環境で評価するRunnerを生成する
+func (e *OfflineEnvironment) NewRunner(opts Options) *Runner {
+	return NewRunner(e.QAService, e.RecommendService, e.BedrockClient, opts)
+}
@@ -0,0 +1,81 @@
+package eval_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/eval"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runOffline は testdata のコーパスとデータセットをオフラインのフェイクで評価する
+func runOffline(t *testing.T) *eval.Report {
+	t.Helper()
+	ctx := context.Background()
+
+	corpusFile, err := os.Open("testdata/corpus.jsonl")
+	require.NoError(t, err)
+	defer corpusFile.Close()
+	corpus, err := eval.LoadCorpus(corpusFile)
+	require.NoError(t, err)
+
+	goldenFile, err := os.Open("testdata/golden.jsonl")
+	require.NoError(t, err)
+	defer goldenFile.Close()
+	cases, err := eval.LoadGoldenSet(goldenFile)
+	require.NoError(t, err)
+
+	env, err := eval.NewOfflineEnvironment(ctx, corpus)
+	require.NoError(t, err)
+	report, err := env.NewRunner(eval.Options{Label: "offline", K: 3}).Run(ctx, "testdata/golden.jsonl", cases)
+	require.NoError(t, err)
+	return report
+}
+
+func TestOfflineEnvironment(t *testing.T) {
+	t.Run("正常系: オフラインのフェイクで全ケースを評価できる", func(t *testing.T) {
+		report := runOffline(t)
+
+		assert.Equal(t, 4, report.Summary.Cases)
+		assert.Equal(t, 0, report.Summary.FailedCases)
+		// 質問と文字が重なるドキュメントが最上位に検索される
+		assert.Equal(t, int64(1), report.Cases[0].RetrievedDocumentIDs[0])
+		assert.Equal(t, int64(2), report.Cases[1].RetrievedDocumentIDs[0])
+		assert.Equal(t, int64(3), report.Cases[2].RetrievedDocumentIDs[0])
+		assert.Equal(t, 1.0, *report.Summary.MRR)
+		require.NotNil(t, report.Summary.RecallAtK)
+		require.NotNil(t, report.Summary.NDCGAtK)
+		require.NotNil(t, report.Summary.AnswerSimilarity)
+		require.NotNil(t, report.Summary.Faithfulness)
+		// 期待ドキュメントのないケースは検索の指標を持たない
+		assert.Nil(t, report.Cases[3].RecallAtK)
+		assert.Nil(t, report.Cases[3].AnswerSimilarity)
+		assert.NotNil(t, report.Cases[3].Faithfulness)
+	})
+
+	t.Run("正常系: 同じ入力からは同じレポートを出力する", func(t *testing.T) {
+		var first, second bytes.Buffer
+		require.NoError(t, runOffline(t).WriteJSON(&first))
+		require.NoError(t, runOffline(t).WriteJSON(&second))
+
+		assert.Equal(t, first.String(), second.String())
+		assert.True(t, json.Valid(first.Bytes()))
+	})
+
+	t.Run("正常系: Markdownのレポートに指標とケースの表を出力する", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, runOffline(t).WriteMarkdown(&buf))
+
+		md := buf.String()
+		assert.Contains(t, md, "# RAG評価レポート: offline")
+		assert.Contains(t, md, "| recall@3 |")
+		assert.Contains(t, md, "| MRR | 1.000 |")
+		assert.Contains(t, md, "| leave-apply |")
+		assert.Contains(t, md, "| no-reference | - | - | - | - |")
+	})
+}
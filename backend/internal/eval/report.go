@@ -0,0 +1,168 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Report は評価の結果
+// 実行ごとに差分を取れるよう、時刻などの実行環境に依存する値は含めない
+type Report struct {
+	Label   string       `json:"label,omitempty"`
+	Dataset string       `json:"dataset"`
+	K       int          `json:"k"`
+	Summary Summary      `json:"summary"`
+	Cases   []CaseResult `json:"cases"`
+}
+
+// Summary はケース全体の指標の平均
+// 指標を計算できなかったケース (期待ドキュメントや模範回答がない、エラーになった) は平均に含めない
+type Summary struct {
+	Cases            int      `json:"cases"`
+	FailedCases      int      `json:"failed_cases"` // エラーが1つ以上あったケース数
+	RecallAtK        *float64 `json:"recall_at_k,omitempty"`
+	MRR              *float64 `json:"mrr,omitempty"`
+	NDCGAtK          *float64 `json:"ndcg_at_k,omitempty"`
+	AnswerSimilarity *float64 `json:"answer_similarity,omitempty"`
+	Faithfulness     *float64 `json:"faithfulness,omitempty"`
+}
+
+// CaseResult は1件のケースの評価結果 (計算できなかった指標は省略する)
+type CaseResult struct {
+	ID                   string   `json:"id"`
+	Question             string   `json:"question"`
+	ExpectedDocumentIDs  []int64  `json:"expected_document_ids,omitempty"`
+	RetrievedDocumentIDs []int64  `json:"retrieved_document_ids,omitempty"`
+	RecallAtK            *float64 `json:"recall_at_k,omitempty"`
+	ReciprocalRank       *float64 `json:"reciprocal_rank,omitempty"`
+	NDCGAtK              *float64 `json:"ndcg_at_k,omitempty"`
+	Answer               string   `json:"answer,omitempty"`
+	AnswerSimilarity     *float64 `json:"answer_similarity,omitempty"`
+	Faithfulness         *float64 `json:"faithfulness,omitempty"`
+	FaithfulnessReason   string   `json:"faithfulness_reason,omitempty"`
+	Errors               []string `json:"errors,omitempty"`
+}
+
+// summarize はケースごとの指標を平均する
+func summarize(cases []CaseResult) Summary {
+	summary := Summary{Cases: len(cases)}
+	var recall, rr, ndcg, similarity, faithfulness []float64
+	for _, c := range cases {
+		if len(c.Errors) > 0 {
+			summary.FailedCases++
+		}
+		recall = appendScore(recall, c.RecallAtK)
+		rr = appendScore(rr, c.ReciprocalRank)
+		ndcg = appendScore(ndcg, c.NDCGAtK)
+		similarity = appendScore(similarity, c.AnswerSimilarity)
+		faithfulness = appendScore(faithfulness, c.Faithfulness)
+	}
+	summary.RecallAtK = mean(recall)
+	summary.MRR = mean(rr)
+	summary.NDCGAtK = mean(ndcg)
+	summary.AnswerSimilarity = mean(similarity)
+	summary.Faithfulness = mean(faithfulness)
+	return summary
+}
+
+// appendScore は計算できた指標のみを追加する
+func appendScore(values []float64, v *float64) []float64 {
+	if v == nil {
+		return values
+	}
+	return append(values, *v)
+}
+
+// mean は平均を返す (値がない場合は nil)
+func mean(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return score(sum / float64(len(values)))
+}
+
+// WriteJSON はレポートをインデント付きのJSONで書き出す
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("レポートの書き出しに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// WriteMarkdown はレポートをMarkdownの表で書き出す
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	title := "RAG評価レポート"
+	if r.Label != "" {
+		title += ": " + r.Label
+	}
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	fmt.Fprintf(&sb, "- データセット: %s\n", r.Dataset)
+	fmt.Fprintf(&sb, "- ケース数: %d (エラー: %d)\n", r.Summary.Cases, r.Summary.FailedCases)
+	fmt.Fprintf(&sb, "- k: %d\n\n", r.K)
+
+	sb.WriteString("## 指標\n\n")
+	sb.WriteString("| 指標 | 値 |\n|---|---|\n")
+	fmt.Fprintf(&sb, "| recall@%d | %s |\n", r.K, formatScore(r.Summary.RecallAtK))
+	fmt.Fprintf(&sb, "| MRR | %s |\n", formatScore(r.Summary.MRR))
+	fmt.Fprintf(&sb, "| nDCG@%d | %s |\n", r.K, formatScore(r.Summary.NDCGAtK))
+	fmt.Fprintf(&sb, "| 回答類似度 | %s |\n", formatScore(r.Summary.AnswerSimilarity))
+	fmt.Fprintf(&sb, "| 忠実性 | %s |\n\n", formatScore(r.Summary.Faithfulness))
+
+	sb.WriteString("## ケースごとの結果\n\n")
+	fmt.Fprintf(&sb, "| ID | recall@%d | RR | nDCG@%d | 回答類似度 | 忠実性 | 期待 | 検索結果 | エラー |\n", r.K, r.K)
+	sb.WriteString("|---|---|---|---|---|---|---|---|---|\n")
+	for _, c := range r.Cases {
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			escapeCell(c.ID),
+			formatScore(c.RecallAtK),
+			formatScore(c.ReciprocalRank),
+			formatScore(c.NDCGAtK),
+			formatScore(c.AnswerSimilarity),
+			formatScore(c.Faithfulness),
+			formatIDs(c.ExpectedDocumentIDs),
+			formatIDs(c.RetrievedDocumentIDs),
+			escapeCell(strings.Join(c.Errors, "; ")),
+		)
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("レポートの書き出しに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// formatScore は指標を小数点以下3桁で表示する (計算できなかった指標は "-")
+func formatScore(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.3f", *v)
+}
+
+// formatIDs はドキュメントIDをカンマ区切りで表示する
+func formatIDs(ids []int64) string {
+	if len(ids) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprintf("%d", id)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// escapeCell は表のセルを壊さないよう、区切り文字と改行を置き換える
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
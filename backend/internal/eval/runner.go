@@ -0,0 +1,159 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/internal/services"
+	"bedrock-rag-sample/backend/pkg/aws"
+)
+
+// DefaultK は検索の指標を計算する上位件数の既定値
+const DefaultK = 5
+
+// chunksPerDocument はドキュメント単位で上位 k 件を得るために取得するチャンク数の倍率
+// 同じドキュメントのチャンクが上位を占めても k 件のドキュメントが揃うよう多めに取得する
+const chunksPerDocument = 4
+
+// Options は評価の実行方法
+type Options struct {
+	Label string // レポートの見出し (プロンプトやチャンクサイズなど、実行ごとの条件を記録する)
+	K     int    // recall@k と nDCG@k の k (0以下の場合は DefaultK)
+	Model string // 回答の生成に使用するモデルのカタログ名 (空の場合はQAエンドポイントの既定モデル)
+}
+
+// Runner はゴールデンデータセットをQAサービスと検索に通して品質を評価する
+type Runner struct {
+	qaService        services.QAServiceInterface
+	recommendService services.RecommendServiceInterface
+	bedrockClient    aws.BedrockClientInterface // 回答類似度のEmbeddingと忠実性の評価に使用する
+	opts             Options
+}
+
+// NewRunner は新しいRunnerを生成する
+func NewRunner(qaService services.QAServiceInterface, recommendService services.RecommendServiceInterface, bedrockClient aws.BedrockClientInterface, opts Options) *Runner {
+	if opts.K <= 0 {
+		opts.K = DefaultK
+	}
+	return &Runner{
+		qaService:        qaService,
+		recommendService: recommendService,
+		bedrockClient:    bedrockClient,
+		opts:             opts,
+	}
+}
+
+// Run はすべてのケースを評価してレポートを返す
+// 個々のケースの失敗はレポートに記録して評価を続け、コンテキストが終了した場合のみエラーを返す
+func (r *Runner) Run(ctx context.Context, dataset string, cases []GoldenCase) (*Report, error) {
+	report := &Report{
+		Label:   r.opts.Label,
+		Dataset: dataset,
+		K:       r.opts.K,
+		Cases:   make([]CaseResult, 0, len(cases)),
+	}
+	for _, c := range cases {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("評価が中断されました: %w", err)
+		}
+		report.Cases = append(report.Cases, r.evaluate(ctx, c))
+	}
+	report.Summary = summarize(report.Cases)
+	return report, nil
+}
+
+// evaluate は1件のケースを評価する
+func (r *Runner) evaluate(ctx context.Context, c GoldenCase) CaseResult {
+	result := CaseResult{
+		ID:                  c.ID,
+		Question:            c.Question,
+		ExpectedDocumentIDs: c.ExpectedDocumentIDs,
+	}
+
+	if len(c.ExpectedDocumentIDs) > 0 {
+		ranked, err := r.retrieve(ctx, c.Question)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		} else {
+			result.RetrievedDocumentIDs = ranked
+			result.RecallAtK = score(RecallAtK(ranked, c.ExpectedDocumentIDs, r.opts.K))
+			result.ReciprocalRank = score(ReciprocalRank(ranked, c.ExpectedDocumentIDs, r.opts.K))
+			result.NDCGAtK = score(NDCGAtK(ranked, c.ExpectedDocumentIDs, r.opts.K))
+		}
+	}
+
+	qa, err := r.qaService.SimpleRAG(ctx, c.Question, r.opts.Model)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("回答の生成に失敗しました: %v", err))
+		return result
+	}
+	result.Answer = qa.Answer
+
+	if c.ReferenceAnswer != "" {
+		similarity, err := r.answerSimilarity(ctx, qa.Answer, c.ReferenceAnswer)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		} else {
+			result.AnswerSimilarity = score(similarity)
+		}
+	}
+
+	contexts := make([]string, len(qa.RetrievedDocuments))
+	for i, doc := range qa.RetrievedDocuments {
+		contexts[i] = doc.Content
+	}
+	judgement, err := judgeFaithfulness(ctx, r.bedrockClient, c.Question, qa.Answer, contexts)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	} else {
+		result.Faithfulness = score(judgement.Score)
+		result.FaithfulnessReason = judgement.Reason
+	}
+	return result
+}
+
+// retrieve はクエリで検索した論理ドキュメントのIDを順位の順に最大 k 件返す
+func (r *Runner) retrieve(ctx context.Context, query string) ([]int64, error) {
+	found, err := r.recommendService.FindSimilarDocuments(ctx, query, r.opts.K*chunksPerDocument, domain.ChunkFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("検索に失敗しました: %w", err)
+	}
+
+	ranked := make([]int64, 0, r.opts.K)
+	seen := make(map[int64]bool)
+	for _, chunk := range found.RecommendedChunks {
+		id := chunk.DocumentID
+		if doc := found.Documents[chunk.DocumentID]; doc != nil && doc.LogicalID != 0 {
+			id = doc.LogicalID
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ranked = append(ranked, id)
+		if len(ranked) == r.opts.K {
+			break
+		}
+	}
+	return ranked, nil
+}
+
+// answerSimilarity は回答と模範回答のEmbeddingのコサイン類似度を返す
+func (r *Runner) answerSimilarity(ctx context.Context, answer, reference string) (float64, error) {
+	embeddings, err := r.bedrockClient.GenerateEmbeddings(ctx, []string{answer, reference})
+	if err != nil {
+		return 0, fmt.Errorf("回答類似度のEmbedding生成に失敗しました: %w", err)
+	}
+	if len(embeddings) != 2 {
+		return 0, fmt.Errorf("embedding生成結果の件数が一致しません (入力: 2, 出力: %d)", len(embeddings))
+	}
+	return CosineSimilarity(embeddings[0], embeddings[1]), nil
+}
+
+// score は実行ごとの差分が丸め誤差で生じないよう、指標を小数点以下4桁に丸める
+func score(v float64) *float64 {
+	rounded := math.Round(v*1e4) / 1e4
+	return &rounded
+}
@@ -0,0 +1,110 @@
+package eval_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/internal/eval"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner_Run(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 検索の指標を論理ドキュメント単位で計算し、回答を評価する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockQAService := servicemocks.NewMockQAServiceInterface(ctrl)
+		mockRecommendService := servicemocks.NewMockRecommendServiceInterface(ctrl)
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		runner := eval.NewRunner(mockQAService, mockRecommendService, mockBedrockClient, eval.Options{Label: "baseline", K: 2, Model: "claude-3-haiku"})
+
+		// チャンク 10 と 11 は同じ論理ドキュメント (1) の別の版
+		mockRecommendService.EXPECT().FindSimilarDocuments(gomock.Any(), "有給休暇の申請期限は？", 8, domain.ChunkFilter{}).Return(&services.RecommendResult{
+			RecommendedChunks: []domain.DocumentChunk{{DocumentID: 20}, {DocumentID: 10}, {DocumentID: 11}, {DocumentID: 30}},
+			Documents: map[int64]*domain.Document{
+				10: {ID: 10, LogicalID: 1},
+				11: {ID: 11, LogicalID: 1},
+				20: {ID: 20, LogicalID: 20},
+			},
+		}, nil)
+		mockQAService.EXPECT().SimpleRAG(gomock.Any(), "有給休暇の申請期限は？", "claude-3-haiku").Return(&services.QAResult{
+			Answer:             "3日前までです。",
+			RetrievedDocuments: []services.RetrievedDocument{{Content: "申請は3日前まで"}},
+		}, nil)
+		mockBedrockClient.EXPECT().GenerateEmbeddings(gomock.Any(), []string{"3日前までです。", "取得日の3日前まで"}).Return([][]float32{{1, 0}, {1, 1}}, nil)
+		mockBedrockClient.EXPECT().GenerateText(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, prompt string) (string, error) {
+			assert.Contains(t, prompt, "申請は3日前まで")
+			assert.Contains(t, prompt, "3日前までです。")
+			return "評価結果: {\"score\": 1.5, \"reason\": \"参考情報どおり\"}", nil
+		})
+
+		report, err := runner.Run(ctx, "golden.jsonl", []eval.GoldenCase{{
+			ID:                  "leave",
+			Question:            "有給休暇の申請期限は？",
+			ExpectedDocumentIDs: []int64{1},
+			ReferenceAnswer:     "取得日の3日前まで",
+		}})
+
+		require.NoError(t, err)
+		assert.Equal(t, "baseline", report.Label)
+		assert.Equal(t, 2, report.K)
+		require.Len(t, report.Cases, 1)
+		c := report.Cases[0]
+		assert.Equal(t, []int64{20, 1}, c.RetrievedDocumentIDs)
+		assert.Equal(t, 1.0, *c.RecallAtK)
+		assert.Equal(t, 0.5, *c.ReciprocalRank)
+		assert.Equal(t, 0.6309, *c.NDCGAtK)
+		assert.Equal(t, 0.7071, *c.AnswerSimilarity)
+		assert.Equal(t, 1.0, *c.Faithfulness) // 範囲外のスコアは1に収める
+		assert.Equal(t, "参考情報どおり", c.FaithfulnessReason)
+		assert.Empty(t, c.Errors)
+		assert.Equal(t, 0.5, *report.Summary.MRR)
+		assert.Equal(t, 0, report.Summary.FailedCases)
+	})
+
+	t.Run("正常系: 失敗したケースはエラーを記録して評価を続け、平均から除く", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockQAService := servicemocks.NewMockQAServiceInterface(ctrl)
+		mockRecommendService := servicemocks.NewMockRecommendServiceInterface(ctrl)
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		runner := eval.NewRunner(mockQAService, mockRecommendService, mockBedrockClient, eval.Options{})
+
+		mockRecommendService.EXPECT().FindSimilarDocuments(gomock.Any(), "q1", eval.DefaultK*4, gomock.Any()).Return(nil, errors.New("connection refused"))
+		mockQAService.EXPECT().SimpleRAG(gomock.Any(), "q1", "").Return(nil, errors.New("throttled"))
+		mockQAService.EXPECT().SimpleRAG(gomock.Any(), "q2", "").Return(&services.QAResult{Answer: "a2"}, nil)
+		mockBedrockClient.EXPECT().GenerateText(gomock.Any(), gomock.Any()).Return("スコアは付けられません", nil)
+
+		report, err := runner.Run(ctx, "golden.jsonl", []eval.GoldenCase{
+			{ID: "1", Question: "q1", ExpectedDocumentIDs: []int64{1}},
+			{ID: "2", Question: "q2"},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, report.Cases, 2)
+		assert.Len(t, report.Cases[0].Errors, 2)
+		assert.Nil(t, report.Cases[0].RecallAtK)
+		assert.Len(t, report.Cases[1].Errors, 1)
+		assert.Equal(t, "a2", report.Cases[1].Answer)
+		assert.Equal(t, 2, report.Summary.FailedCases)
+		assert.Nil(t, report.Summary.RecallAtK)
+		assert.Nil(t, report.Summary.Faithfulness)
+	})
+
+	t.Run("異常系: コンテキストが終了した場合は中断する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		runner := eval.NewRunner(servicemocks.NewMockQAServiceInterface(ctrl), servicemocks.NewMockRecommendServiceInterface(ctrl), servicemocks.NewMockBedrockClientInterface(ctrl), eval.Options{})
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := runner.Run(canceled, "golden.jsonl", []eval.GoldenCase{{ID: "1", Question: "q1"}})
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
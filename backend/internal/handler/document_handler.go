@@ -2,7 +2,6 @@ package handler
 
 import (
 	"bedrock-rag-sample/backend/internal/services"
-	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -38,7 +37,7 @@ func (h *DocumentHandler) HandleProcessDocument(c echo.Context) error {
 
 	result, err := h.documentService.ProcessDocumentByS3Key(c.Request().Context(), req.S3Key)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("ドキュメント処理に失敗しました: %v", err))
+		return newServiceError(c, "ドキュメント処理に失敗しました", err)
 	}
 
 	// 成功レスポンスを返す (例: 抽出されたテキストや要約を含む)
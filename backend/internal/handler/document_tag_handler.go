@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DocumentTagHandler はドキュメントの分類 (カテゴリとタグ) を編集するハンドラー
+type DocumentTagHandler struct {
+	tagService services.DocumentTagServiceInterface
+}
+
+// NewDocumentTagHandler は新しいDocumentTagHandlerを生成する
+func NewDocumentTagHandler(tagService services.DocumentTagServiceInterface) *DocumentTagHandler {
+	return &DocumentTagHandler{
+		tagService: tagService,
+	}
+}
+
+// UpdateTagsRequest は分類の更新リクエスト (カテゴリとタグをリクエストの内容で置き換える)
+type UpdateTagsRequest struct {
+	Category string   `json:"category"` // 空の場合は未分類とする
+	Tags     []string `json:"tags"`     // 空の場合はタグをすべて外す
+}
+
+// HandleUpdateTags はドキュメントのカテゴリとタグを置き換え、更新後のドキュメントを返す
+func (h *DocumentTagHandler) HandleUpdateTags(c echo.Context) error {
+	documentID, err := parseDocumentID(c)
+	if err != nil {
+		return err
+	}
+	var req UpdateTagsRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "不正なリクエストです")
+	}
+
+	doc, err := h.tagService.UpdateClassification(c.Request().Context(), documentID, req.Category, req.Tags)
+	if err != nil {
+		return newServiceError(c, "分類の更新に失敗しました", err)
+	}
+
+	return c.JSON(http.StatusOK, doc)
+}
@@ -0,0 +1,89 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/internal/handler"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentTagHandler_HandleUpdateTags(t *testing.T) {
+	e := echo.New()
+
+	newContext := func(id, body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPut, "/documents/"+id+"/tags", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return c, rec
+	}
+	setup := func(t *testing.T) (*handler.DocumentTagHandler, *servicemocks.MockDocumentTagServiceInterface) {
+		ctrl := gomock.NewController(t)
+		mockTagService := servicemocks.NewMockDocumentTagServiceInterface(ctrl)
+		return handler.NewDocumentTagHandler(mockTagService), mockTagService
+	}
+
+	t.Run("正常系: 更新後のドキュメントを返す", func(t *testing.T) {
+		tagHandler, mockTagService := setup(t)
+		mockTagService.EXPECT().UpdateClassification(gomock.Any(), int64(3), "policy", []string{"人事", "休暇"}).
+			Return(&domain.Document{ID: 3, Filename: "leave.txt", Category: "policy", Tags: []string{"人事", "休暇"}}, nil)
+
+		c, rec := newContext("3", `{"category": "policy", "tags": ["人事", "休暇"]}`)
+		err := tagHandler.HandleUpdateTags(c)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"category":"policy"`)
+		assert.Contains(t, rec.Body.String(), `"tags":["人事","休暇"]`)
+	})
+
+	t.Run("異常系: タクソノミーにないカテゴリは400", func(t *testing.T) {
+		tagHandler, mockTagService := setup(t)
+		mockTagService.EXPECT().UpdateClassification(gomock.Any(), int64(3), "memo", gomock.Any()).
+			Return(nil, fmt.Errorf("%w: memo", services.ErrUnknownCategory))
+
+		c, _ := newContext("3", `{"category": "memo"}`)
+		err := tagHandler.HandleUpdateTags(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+	})
+
+	t.Run("異常系: 存在しないドキュメントは404", func(t *testing.T) {
+		tagHandler, mockTagService := setup(t)
+		mockTagService.EXPECT().UpdateClassification(gomock.Any(), int64(99), "", gomock.Any()).
+			Return(nil, fmt.Errorf("分類の更新に失敗しました: %w", domain.ErrDocumentNotFound))
+
+		c, _ := newContext("99", `{"tags": ["aws"]}`)
+		err := tagHandler.HandleUpdateTags(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, he.Code)
+	})
+
+	t.Run("異常系: 不正なIDは400", func(t *testing.T) {
+		tagHandler, _ := setup(t)
+
+		c, _ := newContext("abc", `{}`)
+		err := tagHandler.HandleUpdateTags(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+	})
+}
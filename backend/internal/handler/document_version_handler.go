@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DocumentVersionHandler はドキュメントの版に関するハンドラー
+type DocumentVersionHandler struct {
+	versionService services.DocumentVersionServiceInterface
+}
+
+// NewDocumentVersionHandler は新しいDocumentVersionHandlerを生成する
+func NewDocumentVersionHandler(versionService services.DocumentVersionServiceInterface) *DocumentVersionHandler {
+	return &DocumentVersionHandler{
+		versionService: versionService,
+	}
+}
+
+// DocumentVersionsResponse は版の一覧のレスポンス
+type DocumentVersionsResponse struct {
+	LogicalID int64             `json:"logical_id"`
+	Versions  []domain.Document `json:"versions"`
+}
+
+// HandleListVersions は論理ドキュメントの版を古い順に返す
+func (h *DocumentVersionHandler) HandleListVersions(c echo.Context) error {
+	logicalID, err := parseDocumentID(c)
+	if err != nil {
+		return err
+	}
+
+	versions, err := h.versionService.ListVersions(c.Request().Context(), logicalID)
+	if err != nil {
+		return newServiceError(c, "版の一覧の取得に失敗しました", err)
+	}
+
+	return c.JSON(http.StatusOK, DocumentVersionsResponse{LogicalID: logicalID, Versions: versions})
+}
+
+// HandleGetVersion は指定した版を抽出したテキストを含めて返す
+// 版に "latest" を指定した場合は最新の版を返す
+func (h *DocumentVersionHandler) HandleGetVersion(c echo.Context) error {
+	logicalID, err := parseDocumentID(c)
+	if err != nil {
+		return err
+	}
+	version := 0
+	if v := c.Param("version"); v != "latest" {
+		if version, err = strconv.Atoi(v); err != nil || version < 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, "版は1以上の整数または latest で指定してください")
+		}
+	}
+
+	doc, err := h.versionService.GetVersion(c.Request().Context(), logicalID, version)
+	if err != nil {
+		return newServiceError(c, "版の取得に失敗しました", err)
+	}
+
+	return c.JSON(http.StatusOK, doc)
+}
+
+// HandleDiff は2つの版から抽出したテキストの差分を返す
+// クエリパラメータ from, to を省略した場合は、最新の版とその1つ前の版を比較する
+func (h *DocumentVersionHandler) HandleDiff(c echo.Context) error {
+	logicalID, err := parseDocumentID(c)
+	if err != nil {
+		return err
+	}
+	var from, to int
+	if err := echo.QueryParamsBinder(c).Int("from", &from).Int("to", &to).BindError(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "from と to は版を整数で指定してください")
+	}
+
+	diff, err := h.versionService.Diff(c.Request().Context(), logicalID, from, to)
+	if err != nil {
+		return newServiceError(c, "差分の作成に失敗しました", err)
+	}
+
+	return c.JSON(http.StatusOK, diff)
+}
+
+// parseDocumentID はパスパラメータ id から論理ドキュメントのIDを取得する
+func parseDocumentID(c echo.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "ドキュメントIDが不正です")
+	}
+	return id, nil
+}
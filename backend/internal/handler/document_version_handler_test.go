@@ -0,0 +1,110 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/internal/handler"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentVersionHandler(t *testing.T) {
+	e := echo.New()
+
+	newContext := func(target string, names []string, values []string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames(names...)
+		c.SetParamValues(values...)
+		return c, rec
+	}
+	setup := func(t *testing.T) (*handler.DocumentVersionHandler, *servicemocks.MockDocumentVersionServiceInterface) {
+		ctrl := gomock.NewController(t)
+		mockVersionService := servicemocks.NewMockDocumentVersionServiceInterface(ctrl)
+		return handler.NewDocumentVersionHandler(mockVersionService), mockVersionService
+	}
+
+	t.Run("正常系: 版の一覧", func(t *testing.T) {
+		versionHandler, mockVersionService := setup(t)
+		mockVersionService.EXPECT().ListVersions(gomock.Any(), int64(1)).
+			Return([]domain.Document{{ID: 1, LogicalID: 1, Version: 1}, {ID: 5, LogicalID: 1, Version: 2, IsLatest: true}}, nil)
+
+		c, rec := newContext("/documents/1/versions", []string{"id"}, []string{"1"})
+		err := versionHandler.HandleListVersions(c)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"version":2`)
+	})
+
+	t.Run("正常系: latest は最新の版", func(t *testing.T) {
+		versionHandler, mockVersionService := setup(t)
+		mockVersionService.EXPECT().GetVersion(gomock.Any(), int64(1), 0).
+			Return(&domain.Document{ID: 5, LogicalID: 1, Version: 2, Content: "本文"}, nil)
+
+		c, rec := newContext("/documents/1/versions/latest", []string{"id", "version"}, []string{"1", "latest"})
+		err := versionHandler.HandleGetVersion(c)
+
+		require.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"content":"本文"`)
+	})
+
+	t.Run("異常系: 存在しない版は404", func(t *testing.T) {
+		versionHandler, mockVersionService := setup(t)
+		mockVersionService.EXPECT().GetVersion(gomock.Any(), int64(1), 9).
+			Return(nil, fmt.Errorf("%w with logical id 1 and version 9", domain.ErrDocumentNotFound))
+
+		c, _ := newContext("/documents/1/versions/9", []string{"id", "version"}, []string{"1", "9"})
+		err := versionHandler.HandleGetVersion(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, he.Code)
+	})
+
+	t.Run("異常系: 不正なIDは400", func(t *testing.T) {
+		versionHandler, _ := setup(t)
+
+		c, _ := newContext("/documents/abc/versions", []string{"id"}, []string{"abc"})
+		err := versionHandler.HandleListVersions(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+	})
+
+	t.Run("正常系: 差分", func(t *testing.T) {
+		versionHandler, mockVersionService := setup(t)
+		mockVersionService.EXPECT().Diff(gomock.Any(), int64(1), 1, 3).
+			Return(&services.DocumentDiff{LogicalID: 1, FromVersion: 1, ToVersion: 3, Added: 1, Unified: "--- v1\n+++ v3\n"}, nil)
+
+		c, rec := newContext("/documents/1/diff?from=1&to=3", []string{"id"}, []string{"1"})
+		err := versionHandler.HandleDiff(c)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"to_version":3`)
+	})
+
+	t.Run("異常系: 比較する版の指定が不正な場合は400", func(t *testing.T) {
+		versionHandler, mockVersionService := setup(t)
+		mockVersionService.EXPECT().Diff(gomock.Any(), int64(1), 0, 0).Return(nil, services.ErrInvalidVersionRange)
+
+		c, _ := newContext("/documents/1/diff", []string{"id"}, []string{"1"})
+		err := versionHandler.HandleDiff(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+	})
+}
@@ -1,10 +1,39 @@
 package dto
 
+// ErrorCodeBudgetExceeded はテナントの予算・クォータの上限に達した場合のエラーコード
+// 流量制御による一時的な 429 (TOO_MANY_REQUESTS) と区別し、集計期間が切り替わるまで再試行しても成功しないことを示す
+const ErrorCodeBudgetExceeded = "BUDGET_EXCEEDED"
+
+// ErrorCodeDuplicateDocument は取り込もうとしたドキュメントが既存のドキュメントと重複している場合のエラーコード
+const ErrorCodeDuplicateDocument = "DUPLICATE_DOCUMENT"
+
+// ErrorCodeContentBlocked はBedrock Guardrailsが入力または出力を遮断した場合のエラーコード
+// 同じ内容で再試行しても成功しないため、モデル呼び出しの障害 (500) と区別する
+const ErrorCodeContentBlocked = "CONTENT_BLOCKED"
+
+// ErrorCodeExtractionInvalid は生成し直してもJSON Schemaに適合する抽出結果が得られなかった場合のエラーコード
+const ErrorCodeExtractionInvalid = "EXTRACTION_INVALID"
+
+// ErrorCodePIIDetected は個人情報の取り扱いが reject の場合に、入力から個人情報を検出したため拒否したときのエラーコード
+const ErrorCodePIIDetected = "PII_DETECTED"
+
+// GuardrailFinding はガードレールが遮断した理由
+type GuardrailFinding struct {
+	Source string `json:"source"` // input (質問・検索した文書) / output (生成した回答)
+	Policy string `json:"policy"` // content / topic / word / sensitive_information / contextual_grounding
+	Type   string `json:"type"`   // フィルターの種類・トピック名・PIIの種類など
+	Action string `json:"action"` // BLOCKED / ANONYMIZED
+}
+
 // ErrorDetail はAPIエラーレスポンスの詳細を表す
 type ErrorDetail struct {
 	Code    string `json:"code"`              // エラーコード (例: "INVALID_PARAMETER")
 	Message string `json:"message"`           // ユーザー向けエラーメッセージ
 	Details string `json:"details,omitempty"` // (オプション) 詳細なエラー情報
+
+	ExistingDocumentID *int64             `json:"existing_document_id,omitempty"` // (オプション) 重複していた既存のドキュメントのID
+	GuardrailFindings  []GuardrailFinding `json:"guardrail_findings,omitempty"`   // (オプション) ガードレールが遮断した理由
+	PIITypes           []string           `json:"pii_types,omitempty"`            // (オプション) 検出した個人情報の種類
 }
 
 // ErrorResponse はAPIエラーレスポンスの全体構造を表す
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// EmbeddingCacheHandler はEmbeddingのキャッシュに関するハンドラー
+type EmbeddingCacheHandler struct {
+	cache *services.EmbeddingCache
+}
+
+// NewEmbeddingCacheHandler は新しいEmbeddingCacheHandlerを生成する
+func NewEmbeddingCacheHandler(cache *services.EmbeddingCache) *EmbeddingCacheHandler {
+	return &EmbeddingCacheHandler{
+		cache: cache,
+	}
+}
+
+// HandleStats はこのタスクの起動以降のキャッシュのヒット率を返す
+func (h *EmbeddingCacheHandler) HandleStats(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.cache.Stats())
+}
@@ -0,0 +1,39 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/handler"
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddingCacheHandler_HandleStats(t *testing.T) {
+	ctx := context.Background()
+	cache := services.NewEmbeddingCache(nil, 10)
+	cache.PutEmbeddings(ctx, "titan-v2-256/document", []string{"a"}, [][]float32{{1, 1}})
+	cache.GetEmbeddings(ctx, "titan-v2-256/document", []string{"a", "b"})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/embeddings/cache/stats", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handler.NewEmbeddingCacheHandler(cache).HandleStats(c)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var stats services.EmbeddingCacheStats
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	assert.Equal(t, int64(2), stats.Total.Lookups)
+	assert.Equal(t, int64(1), stats.Total.MemoryHits)
+	assert.Equal(t, 0.5, stats.Total.HitRate)
+	assert.Equal(t, 1, stats.MemoryEntries)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/internal/services"
+	"bedrock-rag-sample/backend/pkg/aws"
+	"bedrock-rag-sample/backend/pkg/jsonschema"
+
+	"github.com/labstack/echo/v4"
+)
+
+// retryAfterSeconds は流量制御によるエラー時にクライアントへ提示する再試行までの秒数
+const retryAfterSeconds = 5
+
+// newServiceError はサービス層のエラーをHTTPエラーに変換する
+// 流量制御に起因するエラーは再試行可能であることを示すステータスと Retry-After ヘッダーを返し、それ以外は500とする
+// Embeddingモデルの混在や再インデックスの状態に反する操作、既存のドキュメントとの重複はインデックスの状態に起因するため409とする
+// 構造化データ抽出で、生成し直してもJSON Schemaに適合する出力が得られなかった場合と、個人情報を拒否する設定で個人情報を検出した場合、
+// ガードレールが入力または出力を遮断した場合は422とする
+func newServiceError(c echo.Context, message string, err error) *echo.HTTPError {
+	// 予算・クォータの上限は集計期間が切り替わるまで解除されないため、その時刻までを Retry-After で示す
+	var budgetErr *services.BudgetExceededError
+	if errors.As(err, &budgetErr) {
+		retryAfter := max(int(math.Ceil(time.Until(budgetErr.ResetAt).Seconds())), 1)
+		c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		return echo.NewHTTPError(http.StatusTooManyRequests, fmt.Sprintf("%s: %v", message, err)).SetInternal(err)
+	}
+
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, aws.ErrModelBusy), errors.Is(err, aws.ErrCircuitOpen):
+		status = http.StatusServiceUnavailable
+	case aws.IsThrottlingError(err):
+		status = http.StatusTooManyRequests
+	case errors.Is(err, domain.ErrMixedEmbeddingModels),
+		errors.Is(err, domain.ErrReindexInvalidState),
+		errors.Is(err, domain.ErrReindexIncomplete),
+		errors.Is(err, services.ErrDuplicateDocument),
+		errors.Is(err, services.ErrSyncInProgress):
+		status = http.StatusConflict
+	case errors.Is(err, domain.ErrReindexJobNotFound),
+		errors.Is(err, domain.ErrDocumentNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrExtractionInvalid),
+		errors.Is(err, services.ErrPIIDetected),
+		errors.Is(err, aws.ErrContentBlocked):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, aws.ErrUnknownEmbeddingModel),
+		errors.Is(err, aws.ErrUnknownTextModel),
+		errors.Is(err, aws.ErrToolsNotSupported),
+		errors.Is(err, services.ErrModelNotAllowed),
+		errors.Is(err, services.ErrInvalidUsagePeriod),
+		errors.Is(err, services.ErrInvalidDuplicatePolicy),
+		errors.Is(err, services.ErrUnsupportedFileType),
+		errors.Is(err, services.ErrNoExtractableText),
+		errors.Is(err, services.ErrInvalidVersionRange),
+		errors.Is(err, services.ErrInvalidExtractRequest),
+		errors.Is(err, services.ErrUnknownCategory),
+		errors.Is(err, services.ErrInvalidTags),
+		errors.Is(err, jsonschema.ErrInvalidSchema):
+		status = http.StatusBadRequest
+	}
+
+	if status == http.StatusServiceUnavailable || status == http.StatusTooManyRequests {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+	}
+
+	return echo.NewHTTPError(status, fmt.Sprintf("%s: %v", message, err)).SetInternal(err)
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ExtractHandler は構造化データ抽出に関するハンドラー
+type ExtractHandler struct {
+	extractService services.ExtractServiceInterface
+}
+
+// NewExtractHandler は新しいExtractHandlerを生成する
+func NewExtractHandler(extractService services.ExtractServiceInterface) *ExtractHandler {
+	return &ExtractHandler{
+		extractService: extractService,
+	}
+}
+
+// ExtractRequest は構造化データ抽出リクエストの構造体
+type ExtractRequest struct {
+	Text   string          `json:"text,omitempty"`   // 抽出の対象とするテキスト
+	S3Key  string          `json:"s3_key,omitempty"` // 抽出の対象とするドキュメント (PDF・画像) のS3キー
+	Schema json.RawMessage `json:"schema"`           // 抽出する項目のJSON Schema
+	Model  string          `json:"model,omitempty"`  // 抽出に使用するモデル (省略時は抽出の既定モデル)
+}
+
+// HandleExtract はテキストまたはS3のドキュメントから、指定されたJSON Schemaに従う構造化データを抽出する
+// 生成し直してもスキーマに適合する出力が得られない場合は422を返す
+func (h *ExtractHandler) HandleExtract(c echo.Context) error {
+	var req ExtractRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "不正なリクエストです")
+	}
+
+	if (req.Text == "") == (req.S3Key == "") {
+		return echo.NewHTTPError(http.StatusBadRequest, "抽出するテキストまたはファイルのS3キーのどちらか一方を指定してください")
+	}
+	if len(req.Schema) == 0 || string(req.Schema) == "null" {
+		return echo.NewHTTPError(http.StatusBadRequest, "抽出する項目のJSON Schemaを指定してください")
+	}
+
+	result, err := h.extractService.Extract(c.Request().Context(), services.ExtractRequest{
+		Text:   req.Text,
+		S3Key:  req.S3Key,
+		Schema: req.Schema,
+		Model:  req.Model,
+	})
+	if err != nil {
+		return newServiceError(c, "構造化データの抽出に失敗しました", err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
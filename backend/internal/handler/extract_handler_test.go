@@ -0,0 +1,120 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/handler"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+	"bedrock-rag-sample/backend/pkg/jsonschema"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractHandler_HandleExtract(t *testing.T) {
+	e := echo.New()
+	schema := `{"type": "object", "properties": {"total": {"type": "integer"}}, "required": ["total"]}`
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/extract", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("正常系: 抽出結果と根拠の箇所を返す", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExtractService := servicemocks.NewMockExtractServiceInterface(ctrl)
+		extractHandler := handler.NewExtractHandler(mockExtractService)
+
+		mockExtractService.EXPECT().
+			Extract(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ any, req services.ExtractRequest) (*services.ExtractResult, error) {
+				assert.Equal(t, "documents/invoice.pdf", req.S3Key)
+				assert.Equal(t, "claude-3-haiku", req.Model)
+				assert.JSONEq(t, schema, string(req.Schema))
+				return &services.ExtractResult{
+					Data:     json.RawMessage(`{"total":10800}`),
+					Spans:    []services.FieldSpan{{Path: "/total", Quote: "10,800円", Found: true, Start: 6, End: 13, StartLine: 1, EndLine: 1}},
+					Model:    "claude-3-haiku",
+					Attempts: 1,
+				}, nil
+			})
+
+		c, rec := newContext(fmt.Sprintf(`{"s3_key": "documents/invoice.pdf", "model": "claude-3-haiku", "schema": %s}`, schema))
+		err := extractHandler.HandleExtract(c)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var resp struct {
+			Data  map[string]int       `json:"data"`
+			Spans []services.FieldSpan `json:"spans"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, 10800, resp.Data["total"])
+		assert.Equal(t, "/total", resp.Spans[0].Path)
+	})
+
+	t.Run("異常系: 入力が不正な場合は400", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		extractHandler := handler.NewExtractHandler(servicemocks.NewMockExtractServiceInterface(ctrl))
+
+		for _, body := range []string{
+			fmt.Sprintf(`{"schema": %s}`, schema),
+			fmt.Sprintf(`{"text": "本文", "s3_key": "documents/a.pdf", "schema": %s}`, schema),
+			`{"text": "本文"}`,
+			`{"text": "本文", "schema": null}`,
+		} {
+			c, _ := newContext(body)
+			err := extractHandler.HandleExtract(c)
+
+			he, ok := err.(*echo.HTTPError)
+			require.True(t, ok, body)
+			assert.Equal(t, http.StatusBadRequest, he.Code, body)
+		}
+	})
+
+	t.Run("異常系: スキーマが不正な場合は400", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExtractService := servicemocks.NewMockExtractServiceInterface(ctrl)
+		extractHandler := handler.NewExtractHandler(mockExtractService)
+
+		mockExtractService.EXPECT().Extract(gomock.Any(), gomock.Any()).
+			Return(nil, fmt.Errorf("%w: 不明な型です: invoice", jsonschema.ErrInvalidSchema))
+
+		c, _ := newContext(`{"text": "本文", "schema": {"type": "invoice"}}`)
+		err := extractHandler.HandleExtract(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+	})
+
+	t.Run("異常系: スキーマに適合する出力が得られない場合は422", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExtractService := servicemocks.NewMockExtractServiceInterface(ctrl)
+		extractHandler := handler.NewExtractHandler(mockExtractService)
+
+		mockExtractService.EXPECT().Extract(gomock.Any(), gomock.Any()).
+			Return(nil, fmt.Errorf("%w (3 回生成): data/total: 必須のプロパティがありません", services.ErrExtractionInvalid))
+
+		c, _ := newContext(fmt.Sprintf(`{"text": "本文", "schema": %s}`, schema))
+		err := extractHandler.HandleExtract(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusUnprocessableEntity, he.Code)
+	})
+}
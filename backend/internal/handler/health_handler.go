@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"bedrock-rag-sample/backend/internal/services"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HealthHandler はliveness/readinessチェックに関するハンドラー
+type HealthHandler struct {
+	healthService services.HealthServiceInterface
+}
+
+// NewHealthHandler は新しいHealthHandlerを生成する
+func NewHealthHandler(healthService services.HealthServiceInterface) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+	}
+}
+
+// HandleLivez はプロセスが応答可能かどうかのみを返す
+// 依存先の状態は見ないため、依存先の障害でコンテナが再起動されることはない
+func (h *HealthHandler) HandleLivez(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": services.HealthStatusOK,
+	})
+}
+
+// HandleReadyz は依存先ごとのチェック結果と機能ごとの利用可否を返す
+// 必須の依存先が停止している場合のみ503を返し、一部機能の縮退時は200を返す
+func (h *HealthHandler) HandleReadyz(c echo.Context) error {
+	report := h.healthService.Readiness(c.Request().Context())
+
+	statusCode := http.StatusOK
+	if report.Status == services.HealthStatusUnavailable {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	return c.JSON(statusCode, report)
+}
@@ -0,0 +1,103 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/handler"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler_HandleLivez(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHealthService := servicemocks.NewMockHealthServiceInterface(ctrl)
+	healthHandler := handler.NewHealthHandler(mockHealthService)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/livez", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// livenessは依存先をチェックしない
+	mockHealthService.EXPECT().Readiness(gomock.Any()).Times(0)
+
+	err := healthHandler.HandleLivez(c)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
+
+func TestHealthHandler_HandleReadyz(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHealthService := servicemocks.NewMockHealthServiceInterface(ctrl)
+	healthHandler := handler.NewHealthHandler(mockHealthService)
+
+	e := echo.New()
+
+	testCases := []struct {
+		name           string
+		report         *services.HealthReport
+		expectedStatus int
+	}{
+		{
+			name: "正常系",
+			report: &services.HealthReport{
+				Status:   services.HealthStatusOK,
+				Checks:   map[string]services.CheckResult{"s3": {Status: services.CheckStatusUp}},
+				Features: map[string]services.FeatureStatus{"upload": {Available: true}},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "縮退時は200",
+			report: &services.HealthReport{
+				Status:   services.HealthStatusDegraded,
+				Checks:   map[string]services.CheckResult{"postgres": {Status: services.CheckStatusDown, Error: "down"}},
+				Features: map[string]services.FeatureStatus{"recommend": {Available: false, Degraded: []string{"postgres"}}},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "利用不可時は503",
+			report: &services.HealthReport{
+				Status:   services.HealthStatusUnavailable,
+				Checks:   map[string]services.CheckResult{"s3": {Status: services.CheckStatusDown, Critical: true}},
+				Features: map[string]services.FeatureStatus{"upload": {Available: false, Degraded: []string{"s3"}}},
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			mockHealthService.EXPECT().Readiness(gomock.Any()).Return(tc.report).Times(1)
+
+			err := healthHandler.HandleReadyz(c)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+
+			var resp services.HealthReport
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tc.report.Status, resp.Status)
+			assert.Equal(t, tc.report.Features, resp.Features)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// IngestHandler はドキュメントの取り込みに関するハンドラー
+type IngestHandler struct {
+	ingestService services.IngestServiceInterface
+}
+
+// NewIngestHandler は新しいIngestHandlerを生成する
+func NewIngestHandler(ingestService services.IngestServiceInterface) *IngestHandler {
+	return &IngestHandler{
+		ingestService: ingestService,
+	}
+}
+
+// HandleIngest はアップロードされたファイルを検索対象のドキュメントとして取り込む
+// 重複したドキュメントの扱いはフォームの on_duplicate (reject / link / version) で指定する
+// 新たに取り込んだ場合は201、重複のため既存のドキュメントを返した場合は200を返す
+func (h *IngestHandler) HandleIngest(c echo.Context) error {
+	return h.ingest(c, 0)
+}
+
+// HandleIngestVersion はアップロードされたファイルを論理ドキュメント (パスパラメータ id) の新しい版として取り込む
+// 以前の版は残り、検索は既定で新しい版のみを対象とする
+func (h *IngestHandler) HandleIngestVersion(c echo.Context) error {
+	logicalID, err := parseDocumentID(c)
+	if err != nil {
+		return err
+	}
+	return h.ingest(c, logicalID)
+}
+
+// ingest はフォームのファイルを取り込む (logicalID が 0 の場合は新しいドキュメントとして取り込む)
+func (h *IngestHandler) ingest(c echo.Context, logicalID int64) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ファイルの取得に失敗しました: %v", err))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ファイルの読み込みに失敗しました: %v", err))
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ファイルの読み込みに失敗しました: %v", err))
+	}
+
+	result, err := h.ingestService.Ingest(c.Request().Context(), services.IngestRequest{
+		Filename:        fileHeader.Filename,
+		Content:         content,
+		DuplicatePolicy: c.FormValue("on_duplicate"),
+		LogicalID:       logicalID,
+	})
+	if err != nil {
+		return newServiceError(c, "ドキュメントの取り込みに失敗しました", err)
+	}
+
+	if result.Linked {
+		return c.JSON(http.StatusOK, result)
+	}
+	return c.JSON(http.StatusCreated, result)
+}
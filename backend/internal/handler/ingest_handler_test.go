@@ -0,0 +1,146 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/internal/handler"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestHandler_HandleIngest(t *testing.T) {
+	e := echo.New()
+
+	newContext := func(t *testing.T, onDuplicate string) (echo.Context, *httptest.ResponseRecorder) {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "leave.txt")
+		require.NoError(t, err)
+		_, err = part.Write([]byte("有給休暇の申請方法"))
+		require.NoError(t, err)
+		if onDuplicate != "" {
+			require.NoError(t, writer.WriteField("on_duplicate", onDuplicate))
+		}
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/documents", body)
+		req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("正常系: 取り込んだ場合は201", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockIngestService := servicemocks.NewMockIngestServiceInterface(ctrl)
+		ingestHandler := handler.NewIngestHandler(mockIngestService)
+
+		mockIngestService.EXPECT().Ingest(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, req services.IngestRequest) (*services.IngestResult, error) {
+			assert.Equal(t, "leave.txt", req.Filename)
+			assert.Equal(t, "有給休暇の申請方法", string(req.Content))
+			assert.Equal(t, services.DuplicatePolicyVersion, req.DuplicatePolicy)
+			return &services.IngestResult{Document: &domain.Document{ID: 10, Filename: "leave.txt"}}, nil
+		})
+
+		c, rec := newContext(t, services.DuplicatePolicyVersion)
+		err := ingestHandler.HandleIngest(c)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"id":10`)
+	})
+
+	t.Run("正常系: 既存のドキュメントを返した場合は200", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockIngestService := servicemocks.NewMockIngestServiceInterface(ctrl)
+		ingestHandler := handler.NewIngestHandler(mockIngestService)
+
+		mockIngestService.EXPECT().Ingest(gomock.Any(), gomock.Any()).Return(&services.IngestResult{
+			Document:  &domain.Document{ID: 3, Filename: "leave.txt"},
+			Linked:    true,
+			Duplicate: &services.DuplicateMatch{DocumentID: 3, Filename: "leave.txt", Match: services.DuplicateMatchExact, Similarity: 1},
+		}, nil)
+
+		c, rec := newContext(t, services.DuplicatePolicyLink)
+		err := ingestHandler.HandleIngest(c)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"linked":true`)
+	})
+
+	t.Run("正常系: 新しい版として取り込む", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockIngestService := servicemocks.NewMockIngestServiceInterface(ctrl)
+		ingestHandler := handler.NewIngestHandler(mockIngestService)
+
+		mockIngestService.EXPECT().Ingest(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, req services.IngestRequest) (*services.IngestResult, error) {
+			assert.Equal(t, int64(1), req.LogicalID)
+			return &services.IngestResult{Document: &domain.Document{ID: 12, LogicalID: 1, Version: 2}}, nil
+		})
+
+		c, rec := newContext(t, "")
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		err := ingestHandler.HandleIngestVersion(c)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"version":2`)
+	})
+
+	t.Run("異常系: 重複は409", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockIngestService := servicemocks.NewMockIngestServiceInterface(ctrl)
+		ingestHandler := handler.NewIngestHandler(mockIngestService)
+
+		dupErr := &services.DuplicateDocumentError{Existing: services.DuplicateMatch{DocumentID: 3, Match: services.DuplicateMatchNear, Similarity: 0.97}}
+		mockIngestService.EXPECT().Ingest(gomock.Any(), gomock.Any()).Return(nil, dupErr)
+
+		c, _ := newContext(t, "")
+		err := ingestHandler.HandleIngest(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusConflict, he.Code)
+		assert.ErrorIs(t, he, services.ErrDuplicateDocument)
+	})
+
+	t.Run("異常系: 重複したドキュメントの扱いが不正な場合は400", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockIngestService := servicemocks.NewMockIngestServiceInterface(ctrl)
+		ingestHandler := handler.NewIngestHandler(mockIngestService)
+
+		mockIngestService.EXPECT().Ingest(gomock.Any(), gomock.Any()).Return(nil, services.ErrInvalidDuplicatePolicy)
+
+		c, _ := newContext(t, "overwrite")
+		err := ingestHandler.HandleIngest(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+	})
+
+	t.Run("異常系: ファイルがない場合は400", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		ingestHandler := handler.NewIngestHandler(servicemocks.NewMockIngestServiceInterface(ctrl))
+
+		req := httptest.NewRequest(http.MethodPost, "/documents", nil)
+		c := e.NewContext(req, httptest.NewRecorder())
+		err := ingestHandler.HandleIngest(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+	})
+}
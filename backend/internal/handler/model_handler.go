@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"bedrock-rag-sample/backend/internal/services"
+	"bedrock-rag-sample/backend/pkg/aws"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ModelHandler はリクエストで指定できるテキスト生成モデルに関するハンドラー
+type ModelHandler struct {
+	models *services.TextModelRouter
+}
+
+// NewModelHandler は新しいModelHandlerを生成する
+func NewModelHandler(models *services.TextModelRouter) *ModelHandler {
+	return &ModelHandler{
+		models: models,
+	}
+}
+
+// ModelListResponse はモデル一覧のレスポンス
+type ModelListResponse struct {
+	Models   []aws.TextModel   `json:"models"`   // リクエストで指定できるモデル
+	Defaults map[string]string `json:"defaults"` // エンドポイントごとの既定モデル
+}
+
+// HandleListModels はリクエストで指定できるモデルとエンドポイントごとの既定モデルを返す
+func (h *ModelHandler) HandleListModels(c echo.Context) error {
+	return c.JSON(http.StatusOK, ModelListResponse{
+		Models:   h.models.Allowed(),
+		Defaults: h.models.Defaults(),
+	})
+}
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"bedrock-rag-sample/backend/internal/services"
-	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -23,6 +22,7 @@ func NewQAHandler(qaService services.QAServiceInterface) *QAHandler {
 // QARequest はQAリクエストの構造体
 type QARequest struct {
 	Query string `json:"query"`
+	Model string `json:"model,omitempty"` // 回答の生成に使用するモデル (省略時はQAの既定モデル)
 }
 
 // HandleQA はQAリクエストを処理する
@@ -36,10 +36,26 @@ func (h *QAHandler) HandleQA(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "質問を入力してください")
 	}
 
-	result, err := h.qaService.SimpleRAG(c.Request().Context(), req.Query)
+	result, err := h.qaService.SimpleRAG(c.Request().Context(), req.Query, req.Model)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("QA処理に失敗しました: %v", err))
+		return newServiceError(c, "QA処理に失敗しました", err)
 	}
 
 	return c.JSON(http.StatusOK, result)
 }
+
+// InvalidateCacheResponse は回答キャッシュの破棄結果
+type InvalidateCacheResponse struct {
+	Invalidated int64 `json:"invalidated"` // 破棄した回答の件数
+}
+
+// HandleInvalidateCache は回答キャッシュを破棄する
+// クエリパラメータ document_id (回答の retrieved_documents に含まれるKnowledge BaseのドキュメントID) を指定した場合は、そのドキュメントを根拠にした回答のみを破棄する
+func (h *QAHandler) HandleInvalidateCache(c echo.Context) error {
+	n, err := h.qaService.InvalidateAnswerCache(c.Request().Context(), c.QueryParam("document_id"))
+	if err != nil {
+		return newServiceError(c, "回答キャッシュの破棄に失敗しました", err)
+	}
+
+	return c.JSON(http.StatusOK, InvalidateCacheResponse{Invalidated: n})
+}
@@ -4,14 +4,20 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"bedrock-rag-sample/backend/internal/handler"
 	"bedrock-rag-sample/backend/internal/services"
 	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+	"bedrock-rag-sample/backend/pkg/aws"
+	"bedrock-rag-sample/backend/pkg/redact"
 
+	"github.com/aws/smithy-go"
 	"github.com/golang/mock/gomock"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
@@ -47,7 +53,7 @@ func TestQAHandler_HandleQA(t *testing.T) {
 
 		// モックの設定
 		mockQAService.EXPECT().
-			SimpleRAG(gomock.Any(), query).
+			SimpleRAG(gomock.Any(), query, "").
 			Return(serviceResult, nil).
 			Times(1)
 
@@ -104,7 +110,7 @@ func TestQAHandler_HandleQA(t *testing.T) {
 
 		serviceError := errors.New("qa service failed")
 		mockQAService.EXPECT().
-			SimpleRAG(gomock.Any(), query).
+			SimpleRAG(gomock.Any(), query, "").
 			Return(nil, serviceError).
 			Times(1)
 
@@ -117,4 +123,166 @@ func TestQAHandler_HandleQA(t *testing.T) {
 		assert.Contains(t, httpError.Message, "QA処理に失敗しました")
 		assert.Contains(t, httpError.Message.(string), serviceError.Error())
 	})
+
+	t.Run("異常系_モデルの同時実行数超過", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/qa", bytes.NewReader(reqBytes))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockQAService.EXPECT().
+			SimpleRAG(gomock.Any(), query, "").
+			Return(nil, fmt.Errorf("回答の生成に失敗しました: %w", aws.ErrModelBusy)).
+			Times(1)
+
+		err := qaHandler.HandleQA(c)
+
+		require.Error(t, err)
+		httpError, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusServiceUnavailable, httpError.Code)
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("異常系_Bedrockのスロットリング", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/qa", bytes.NewReader(reqBytes))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		throttled := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+		mockQAService.EXPECT().
+			SimpleRAG(gomock.Any(), query, "").
+			Return(nil, fmt.Errorf("回答の生成に失敗しました: %w", throttled)).
+			Times(1)
+
+		err := qaHandler.HandleQA(c)
+
+		require.Error(t, err)
+		httpError, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusTooManyRequests, httpError.Code)
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("異常系_個人情報を含む質問", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/qa", bytes.NewReader(reqBytes))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockQAService.EXPECT().
+			SimpleRAG(gomock.Any(), query, "").
+			Return(nil, &redact.RejectedError{Types: []string{"phone"}}).
+			Times(1)
+
+		err := qaHandler.HandleQA(c)
+
+		require.Error(t, err)
+		httpError, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusUnprocessableEntity, httpError.Code)
+		assert.Contains(t, httpError.Message.(string), "phone")
+	})
+
+	t.Run("異常系_ガードレールによる遮断", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/qa", bytes.NewReader(reqBytes))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		blockedErr := &aws.ContentBlockedError{Model: "claude-3-haiku", Outcome: aws.GuardrailOutcome{
+			Blocked:  true,
+			Findings: []aws.GuardrailFinding{{Source: aws.GuardrailSourceInput, Policy: aws.GuardrailPolicyContent, Type: "PROMPT_ATTACK", Action: "BLOCKED"}},
+		}}
+		mockQAService.EXPECT().
+			SimpleRAG(gomock.Any(), query, "").
+			Return(nil, fmt.Errorf("回答の生成に失敗しました: %w", blockedErr)).
+			Times(1)
+
+		err := qaHandler.HandleQA(c)
+
+		require.Error(t, err)
+		httpError, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusUnprocessableEntity, httpError.Code)
+		assert.ErrorIs(t, httpError, aws.ErrContentBlocked)
+		assert.Contains(t, httpError.Message.(string), "PROMPT_ATTACK")
+	})
+
+	t.Run("異常系_テナントの予算超過", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/qa", bytes.NewReader(reqBytes))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		budgetErr := &services.BudgetExceededError{Tenant: "tenant-a", Limit: services.BudgetLimitMonthlyCost, ResetAt: time.Now().Add(time.Hour)}
+		mockQAService.EXPECT().
+			SimpleRAG(gomock.Any(), query, "").
+			Return(nil, fmt.Errorf("回答の生成に失敗しました: %w", budgetErr)).
+			Times(1)
+
+		err := qaHandler.HandleQA(c)
+
+		require.Error(t, err)
+		httpError, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusTooManyRequests, httpError.Code)
+		assert.ErrorIs(t, httpError, services.ErrBudgetExceeded)
+		retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+		require.NoError(t, err)
+		assert.InDelta(t, 3600, retryAfter, 5)
+	})
+}
+
+func TestQAHandler_HandleInvalidateCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQAService := servicemocks.NewMockQAServiceInterface(ctrl)
+	qaHandler := handler.NewQAHandler(mockQAService)
+
+	e := echo.New()
+
+	t.Run("正常系_全件", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/qa/cache", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockQAService.EXPECT().InvalidateAnswerCache(gomock.Any(), "").Return(int64(12), nil).Times(1)
+
+		err := qaHandler.HandleInvalidateCache(c)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"invalidated":12}`, rec.Body.String())
+	})
+
+	t.Run("正常系_ドキュメント指定", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/qa/cache?document_id=doc-1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockQAService.EXPECT().InvalidateAnswerCache(gomock.Any(), "doc-1").Return(int64(2), nil).Times(1)
+
+		err := qaHandler.HandleInvalidateCache(c)
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"invalidated":2}`, rec.Body.String())
+	})
+
+	t.Run("異常系_サービスエラー", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/qa/cache", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockQAService.EXPECT().InvalidateAnswerCache(gomock.Any(), "").Return(int64(0), errors.New("db error")).Times(1)
+
+		err := qaHandler.HandleInvalidateCache(c)
+
+		require.Error(t, err)
+		httpError, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusInternalServerError, httpError.Code)
+	})
 }
@@ -3,7 +3,6 @@ package handler
 import (
 	"bedrock-rag-sample/backend/internal/domain" // domain をインポート
 	"bedrock-rag-sample/backend/internal/services"
-	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -25,8 +24,11 @@ func NewRecommendHandler(recommendService services.RecommendServiceInterface, db
 
 // RecommendRequest は推薦リクエストの構造体
 type RecommendRequest struct {
-	Query string `json:"query"`
-	Limit int    `json:"limit,omitempty"`
+	Query       string   `json:"query"`
+	Limit       int      `json:"limit,omitempty"`
+	AllVersions bool     `json:"all_versions,omitempty"` // true の場合は最新以外の版のドキュメントも検索する
+	Category    string   `json:"category,omitempty"`     // 指定した場合はこのカテゴリのドキュメントのみを検索する
+	Tags        []string `json:"tags,omitempty"`         // 指定した場合はすべてのタグが付いたドキュメントのみを検索する
 }
 
 // HandleRecommend は類似文書の推薦リクエストを処理する
@@ -45,9 +47,9 @@ func (h *RecommendHandler) HandleRecommend(c echo.Context) error {
 		limit = 5 // デフォルト値
 	}
 
-	result, err := h.recommendService.FindSimilarDocuments(c.Request().Context(), req.Query, limit)
+	result, err := h.recommendService.FindSimilarDocuments(c.Request().Context(), req.Query, limit, domain.ChunkFilter{AllVersions: req.AllVersions, Category: req.Category, Tags: req.Tags})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("推薦処理に失敗しました: %v", err))
+		return newServiceError(c, "推薦処理に失敗しました", err)
 	}
 
 	return c.JSON(http.StatusOK, result)
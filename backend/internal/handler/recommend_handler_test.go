@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -53,7 +54,7 @@ func TestRecommendHandler_HandleRecommend(t *testing.T) {
 
 		// モックの設定
 		mockRecommendService.EXPECT().
-			FindSimilarDocuments(gomock.Any(), query, limit).
+			FindSimilarDocuments(gomock.Any(), query, limit, domain.ChunkFilter{}).
 			Return(serviceResult, nil).
 			Times(1)
 
@@ -81,7 +82,7 @@ func TestRecommendHandler_HandleRecommend(t *testing.T) {
 
 		// モックの設定 (limit がデフォルト値で呼ばれることを期待)
 		mockRecommendService.EXPECT().
-			FindSimilarDocuments(gomock.Any(), query, defaultLimit).
+			FindSimilarDocuments(gomock.Any(), query, defaultLimit, domain.ChunkFilter{}).
 			Return(serviceResult, nil).
 			Times(1)
 
@@ -91,6 +92,40 @@ func TestRecommendHandler_HandleRecommend(t *testing.T) {
 		// レスポンス内容は上の正常系と同じと仮定
 	})
 
+	t.Run("正常系_過去の版も検索", func(t *testing.T) {
+		reqBytesAllVersions, _ := json.Marshal(handler.RecommendRequest{Query: query, Limit: limit, AllVersions: true})
+		req := httptest.NewRequest(http.MethodPost, "/recommend", bytes.NewReader(reqBytesAllVersions))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockRecommendService.EXPECT().
+			FindSimilarDocuments(gomock.Any(), query, limit, domain.ChunkFilter{AllVersions: true}).
+			Return(serviceResult, nil).
+			Times(1)
+
+		err := recommendHandler.HandleRecommend(c)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("正常系_カテゴリとタグで絞り込む", func(t *testing.T) {
+		reqBytesFiltered, _ := json.Marshal(handler.RecommendRequest{Query: query, Limit: limit, Category: "policy", Tags: []string{"人事"}})
+		req := httptest.NewRequest(http.MethodPost, "/recommend", bytes.NewReader(reqBytesFiltered))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockRecommendService.EXPECT().
+			FindSimilarDocuments(gomock.Any(), query, limit, domain.ChunkFilter{Category: "policy", Tags: []string{"人事"}}).
+			Return(serviceResult, nil).
+			Times(1)
+
+		err := recommendHandler.HandleRecommend(c)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
 	t.Run("異常系_リクエストボディ不正", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPost, "/recommend", bytes.NewReader([]byte("invalid json")))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
@@ -131,7 +166,7 @@ func TestRecommendHandler_HandleRecommend(t *testing.T) {
 
 		serviceError := errors.New("recommend service failed")
 		mockRecommendService.EXPECT().
-			FindSimilarDocuments(gomock.Any(), query, limit).
+			FindSimilarDocuments(gomock.Any(), query, limit, domain.ChunkFilter{}).
 			Return(nil, serviceError).
 			Times(1)
 
@@ -144,4 +179,24 @@ func TestRecommendHandler_HandleRecommend(t *testing.T) {
 		assert.Contains(t, httpError.Message, "推薦処理に失敗しました")
 		assert.Contains(t, httpError.Message.(string), serviceError.Error())
 	})
+
+	t.Run("異常系_Embeddingモデル混在", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/recommend", bytes.NewReader(reqBytes))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockRecommendService.EXPECT().
+			FindSimilarDocuments(gomock.Any(), query, limit, domain.ChunkFilter{}).
+			Return(nil, fmt.Errorf("類似チャンクの検索に失敗しました: %w", domain.ErrMixedEmbeddingModels)).
+			Times(1)
+
+		err := recommendHandler.HandleRecommend(c)
+
+		require.Error(t, err)
+		httpError, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusConflict, httpError.Code)
+		assert.Empty(t, rec.Header().Get("Retry-After"))
+	})
 }
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReindexHandler はEmbeddingモデル切り替えのための再インデックスに関するハンドラー
+type ReindexHandler struct {
+	reindexService services.ReindexServiceInterface
+}
+
+// NewReindexHandler は新しいReindexHandlerを生成する
+func NewReindexHandler(reindexService services.ReindexServiceInterface) *ReindexHandler {
+	return &ReindexHandler{
+		reindexService: reindexService,
+	}
+}
+
+// ReindexRequest は再インデックス開始リクエストの構造体
+type ReindexRequest struct {
+	Model string `json:"model"` // 切り替え先のEmbeddingモデル (レジストリ名)
+}
+
+// HandleStartReindex は新しいモデルでの再インデックスをバックグラウンドで開始する
+func (h *ReindexHandler) HandleStartReindex(c echo.Context) error {
+	var req ReindexRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "不正なリクエストです")
+	}
+
+	if req.Model == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "切り替え先のモデルを指定してください")
+	}
+
+	job, err := h.reindexService.Start(c.Request().Context(), req.Model)
+	if err != nil {
+		return newServiceError(c, "再インデックスの開始に失敗しました", err)
+	}
+
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// HandleReindexStatus は検索に使用しているモデルと再インデックスの進捗を返す
+func (h *ReindexHandler) HandleReindexStatus(c echo.Context) error {
+	status, err := h.reindexService.Status(c.Request().Context())
+	if err != nil {
+		return newServiceError(c, "再インデックスの状態の取得に失敗しました", err)
+	}
+
+	return c.JSON(http.StatusOK, status)
+}
+
+// HandleActivate は検索を切り替え先のモデルに切り替える
+func (h *ReindexHandler) HandleActivate(c echo.Context) error {
+	return h.transition(c, h.reindexService.Activate, "モデルの切り替えに失敗しました")
+}
+
+// HandleRollback は検索を切り替え前のモデルに戻す
+func (h *ReindexHandler) HandleRollback(c echo.Context) error {
+	return h.transition(c, h.reindexService.Rollback, "ロールバックに失敗しました")
+}
+
+// HandleFinalize は切り替え前のモデルのEmbeddingを削除する
+func (h *ReindexHandler) HandleFinalize(c echo.Context) error {
+	return h.transition(c, h.reindexService.Finalize, "再インデックスの完了処理に失敗しました")
+}
+
+// HandleCancel は再インデックスを中止する
+func (h *ReindexHandler) HandleCancel(c echo.Context) error {
+	return h.transition(c, h.reindexService.Cancel, "再インデックスの中止に失敗しました")
+}
+
+// transition は状態を変更する操作を実行し、変更後の状態を返す
+func (h *ReindexHandler) transition(c echo.Context, op func(ctx context.Context) error, message string) error {
+	if err := op(c.Request().Context()); err != nil {
+		return newServiceError(c, message, err)
+	}
+	return h.HandleReindexStatus(c)
+}
@@ -0,0 +1,138 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/internal/handler"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+	"bedrock-rag-sample/backend/pkg/aws"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReindexHandler_HandleStartReindex(t *testing.T) {
+	e := echo.New()
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/embeddings/reindex", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("正常系: 202を返す", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockReindexService := servicemocks.NewMockReindexServiceInterface(ctrl)
+		reindexHandler := handler.NewReindexHandler(mockReindexService)
+
+		mockReindexService.EXPECT().
+			Start(gomock.Any(), "titan-v2-256").
+			Return(&domain.ReindexJob{ID: 7, TargetModel: "titan-v2-256", Status: domain.ReindexStatusRunning}, nil)
+
+		c, rec := newContext(`{"model":"titan-v2-256"}`)
+		err := reindexHandler.HandleStartReindex(c)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"target_model":"titan-v2-256"`)
+	})
+
+	t.Run("異常系: モデル未指定は400", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		reindexHandler := handler.NewReindexHandler(servicemocks.NewMockReindexServiceInterface(ctrl))
+
+		c, _ := newContext(`{}`)
+		err := reindexHandler.HandleStartReindex(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+	})
+
+	t.Run("異常系: 未登録のモデルは400", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockReindexService := servicemocks.NewMockReindexServiceInterface(ctrl)
+		reindexHandler := handler.NewReindexHandler(mockReindexService)
+
+		mockReindexService.EXPECT().Start(gomock.Any(), "unknown").
+			Return(nil, fmt.Errorf("%w: unknown", aws.ErrUnknownEmbeddingModel))
+
+		c, _ := newContext(`{"model":"unknown"}`)
+		err := reindexHandler.HandleStartReindex(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+	})
+}
+
+func TestReindexHandler_HandleActivate(t *testing.T) {
+	e := echo.New()
+
+	t.Run("正常系: 切り替え後の状態を返す", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockReindexService := servicemocks.NewMockReindexServiceInterface(ctrl)
+		reindexHandler := handler.NewReindexHandler(mockReindexService)
+
+		v1 := domain.EmbeddingSpace{Model: "titan-v1", Dimension: 1536}
+		mockReindexService.EXPECT().Activate(gomock.Any()).Return(nil)
+		mockReindexService.EXPECT().Status(gomock.Any()).Return(&services.ReindexStatus{
+			Active:   domain.EmbeddingSpace{Model: "titan-v2-256", Dimension: 256},
+			Previous: &v1,
+			Progress: 100,
+		}, nil)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/embeddings/reindex/activate", nil), rec)
+		err := reindexHandler.HandleActivate(c)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"previous":{"model":"titan-v1","dimension":1536}`)
+	})
+
+	t.Run("異常系: 未生成のチャンクが残っている場合は409", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockReindexService := servicemocks.NewMockReindexServiceInterface(ctrl)
+		reindexHandler := handler.NewReindexHandler(mockReindexService)
+
+		mockReindexService.EXPECT().Activate(gomock.Any()).Return(fmt.Errorf("%w (9/10)", domain.ErrReindexIncomplete))
+
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/embeddings/reindex/activate", nil), httptest.NewRecorder())
+		err := reindexHandler.HandleActivate(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusConflict, he.Code)
+	})
+
+	t.Run("異常系: 進行中のジョブがない場合は404", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockReindexService := servicemocks.NewMockReindexServiceInterface(ctrl)
+		reindexHandler := handler.NewReindexHandler(mockReindexService)
+
+		mockReindexService.EXPECT().Rollback(gomock.Any()).Return(domain.ErrReindexJobNotFound)
+
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/embeddings/reindex/rollback", nil), httptest.NewRecorder())
+		err := reindexHandler.HandleRollback(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, he.Code)
+	})
+}
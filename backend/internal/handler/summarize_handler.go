@@ -2,7 +2,6 @@ package handler
 
 import (
 	"bedrock-rag-sample/backend/internal/services"
-	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -22,7 +21,8 @@ func NewSummarizeHandler(summarizeService services.SummarizeServiceInterface) *S
 
 // TextSummarizeRequest はテキスト要約リクエストの構造体
 type TextSummarizeRequest struct {
-	Text string `json:"text"`
+	Text  string `json:"text"`
+	Model string `json:"model,omitempty"` // 要約に使用するモデル (省略時は要約の既定モデル)
 }
 
 // HandleTextSummarize は自由テキストの要約リクエストを処理する
@@ -36,19 +36,18 @@ func (h *SummarizeHandler) HandleTextSummarize(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "要約するテキストを指定してください")
 	}
 
-	result, err := h.summarizeService.SummarizeText(c.Request().Context(), req.Text)
+	result, err := h.summarizeService.SummarizeText(c.Request().Context(), req.Text, req.Model)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("要約処理に失敗しました: %v", err))
+		return newServiceError(c, "要約処理に失敗しました", err)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{
-		"summary": result.Summary,
-	})
+	return c.JSON(http.StatusOK, summaryResponse(result))
 }
 
 // FileSummarizeRequest はファイル要約リクエストの構造体
 type FileSummarizeRequest struct {
 	S3Key string `json:"s3_key"`
+	Model string `json:"model,omitempty"` // 要約に使用するモデル (省略時は要約の既定モデル)
 }
 
 // HandleFileSummarize は指定されたS3ファイルの要約リクエストを処理する
@@ -62,12 +61,21 @@ func (h *SummarizeHandler) HandleFileSummarize(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "要約するファイルのS3キーを指定してください")
 	}
 
-	result, err := h.summarizeService.SummarizeFileByS3Key(c.Request().Context(), req.S3Key)
+	result, err := h.summarizeService.SummarizeFileByS3Key(c.Request().Context(), req.S3Key, req.Model)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("ファイル要約処理に失敗しました: %v", err))
+		return newServiceError(c, "ファイル要約処理に失敗しました", err)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{
+	return c.JSON(http.StatusOK, summaryResponse(result))
+}
+
+// summaryResponse は要約結果をレスポンスの形式に変換する (使用したモデルはモデル選択が有効な場合のみ含める)
+func summaryResponse(result *services.SummarizeResult) map[string]string {
+	response := map[string]string{
 		"summary": result.Summary,
-	})
+	}
+	if result.Model != "" {
+		response["model"] = result.Model
+	}
+	return response
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -43,7 +44,7 @@ func TestSummarizeHandler_HandleTextSummarize(t *testing.T) {
 
 		// モックの設定
 		mockSummarizeService.EXPECT().
-			SummarizeText(gomock.Any(), inputText).
+			SummarizeText(gomock.Any(), inputText, "").
 			Return(serviceResult, nil).
 			Times(1)
 
@@ -60,6 +61,45 @@ func TestSummarizeHandler_HandleTextSummarize(t *testing.T) {
 		assert.Equal(t, serviceResult.Summary, resp["summary"])
 	})
 
+	t.Run("正常系_モデル指定", func(t *testing.T) {
+		body, _ := json.Marshal(handler.TextSummarizeRequest{Text: inputText, Model: "claude-3-5-sonnet"})
+		req := httptest.NewRequest(http.MethodPost, "/summarize/text", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockSummarizeService.EXPECT().
+			SummarizeText(gomock.Any(), inputText, "claude-3-5-sonnet").
+			Return(&services.SummarizeResult{Summary: "要約結果", Model: "claude-3-5-sonnet"}, nil).
+			Times(1)
+
+		err := summarizeHandler.HandleTextSummarize(c)
+
+		require.NoError(t, err)
+		var resp map[string]string
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "claude-3-5-sonnet", resp["model"])
+	})
+
+	t.Run("異常系_許可されていないモデル", func(t *testing.T) {
+		body, _ := json.Marshal(handler.TextSummarizeRequest{Text: inputText, Model: "mistral-large"})
+		req := httptest.NewRequest(http.MethodPost, "/summarize/text", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockSummarizeService.EXPECT().
+			SummarizeText(gomock.Any(), inputText, "mistral-large").
+			Return(nil, fmt.Errorf("%w: mistral-large", services.ErrModelNotAllowed)).
+			Times(1)
+
+		err := summarizeHandler.HandleTextSummarize(c)
+
+		httpError, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpError.Code)
+	})
+
 	t.Run("異常系_リクエストボディ不正", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPost, "/summarize/text", bytes.NewReader([]byte("invalid json")))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
@@ -100,7 +140,7 @@ func TestSummarizeHandler_HandleTextSummarize(t *testing.T) {
 
 		serviceError := errors.New("summarize service failed")
 		mockSummarizeService.EXPECT().
-			SummarizeText(gomock.Any(), inputText).
+			SummarizeText(gomock.Any(), inputText, "").
 			Return(nil, serviceError).
 			Times(1)
 
@@ -140,7 +180,7 @@ func TestSummarizeHandler_HandleFileSummarize(t *testing.T) {
 
 		// モックの設定
 		mockSummarizeService.EXPECT().
-			SummarizeFileByS3Key(gomock.Any(), s3Key).
+			SummarizeFileByS3Key(gomock.Any(), s3Key, "").
 			Return(serviceResult, nil).
 			Times(1)
 
@@ -197,7 +237,7 @@ func TestSummarizeHandler_HandleFileSummarize(t *testing.T) {
 
 		serviceError := errors.New("summarize file service failed")
 		mockSummarizeService.EXPECT().
-			SummarizeFileByS3Key(gomock.Any(), s3Key).
+			SummarizeFileByS3Key(gomock.Any(), s3Key, "").
 			Return(nil, serviceError).
 			Times(1)
 
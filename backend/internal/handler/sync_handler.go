@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SyncHandler はS3のプレフィックスに直接置かれたドキュメントの同期に関するハンドラー
+type SyncHandler struct {
+	syncService services.S3SyncServiceInterface
+}
+
+// NewSyncHandler は新しいSyncHandlerを生成する
+func NewSyncHandler(syncService services.S3SyncServiceInterface) *SyncHandler {
+	return &SyncHandler{
+		syncService: syncService,
+	}
+}
+
+// HandleStartSync は同期をバックグラウンドで開始する (定期的な同期を待たずに取り込む場合に使う)
+func (h *SyncHandler) HandleStartSync(c echo.Context) error {
+	state, err := h.syncService.Trigger(c.Request().Context())
+	if err != nil {
+		return newServiceError(c, "同期の開始に失敗しました", err)
+	}
+
+	return c.JSON(http.StatusAccepted, state)
+}
+
+// HandleSyncStatus は同期の状態 (チェックポイントと処理件数) を返す
+func (h *SyncHandler) HandleSyncStatus(c echo.Context) error {
+	state, err := h.syncService.Status(c.Request().Context())
+	if err != nil {
+		return newServiceError(c, "同期の状態の取得に失敗しました", err)
+	}
+
+	return c.JSON(http.StatusOK, state)
+}
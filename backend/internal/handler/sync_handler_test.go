@@ -0,0 +1,104 @@
+package handler_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/internal/handler"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncHandler_HandleStartSync(t *testing.T) {
+	e := echo.New()
+
+	newContext := func() (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/documents/sync", nil)
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("正常系: 202を返す", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSyncService := servicemocks.NewMockS3SyncServiceInterface(ctrl)
+		syncHandler := handler.NewSyncHandler(mockSyncService)
+
+		mockSyncService.EXPECT().Trigger(gomock.Any()).
+			Return(&domain.SyncState{Source: "s3://bucket/inbox/", Checkpoint: "inbox/b.txt"}, nil)
+
+		c, rec := newContext()
+		err := syncHandler.HandleStartSync(c)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"checkpoint":"inbox/b.txt"`)
+	})
+
+	t.Run("異常系: 同期を実行中の場合は409", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSyncService := servicemocks.NewMockS3SyncServiceInterface(ctrl)
+		syncHandler := handler.NewSyncHandler(mockSyncService)
+
+		mockSyncService.EXPECT().Trigger(gomock.Any()).Return(nil, services.ErrSyncInProgress)
+
+		c, _ := newContext()
+		err := syncHandler.HandleStartSync(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusConflict, he.Code)
+	})
+}
+
+func TestSyncHandler_HandleSyncStatus(t *testing.T) {
+	e := echo.New()
+
+	newContext := func() (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/documents/sync", nil)
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("正常系: 同期の状態を返す", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSyncService := servicemocks.NewMockS3SyncServiceInterface(ctrl)
+		syncHandler := handler.NewSyncHandler(mockSyncService)
+
+		mockSyncService.EXPECT().Status(gomock.Any()).
+			Return(&domain.SyncState{Source: "s3://bucket/inbox/", Stats: domain.SyncStats{Created: 3}}, nil)
+
+		c, rec := newContext()
+		err := syncHandler.HandleSyncStatus(c)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"created":3`)
+	})
+
+	t.Run("異常系: 取得エラーは500", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSyncService := servicemocks.NewMockS3SyncServiceInterface(ctrl)
+		syncHandler := handler.NewSyncHandler(mockSyncService)
+
+		mockSyncService.EXPECT().Status(gomock.Any()).Return(nil, errors.New("connection refused"))
+
+		c, _ := newContext()
+		err := syncHandler.HandleSyncStatus(c)
+
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusInternalServerError, he.Code)
+	})
+}
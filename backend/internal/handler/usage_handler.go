@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// usageDateLayout は使用量レポートのクエリパラメータの日付形式
+const usageDateLayout = "2006-01-02"
+
+// UsageHandler はトークン使用量と料金のレポートに関するハンドラー
+type UsageHandler struct {
+	usageService services.UsageServiceInterface
+	now          func() time.Time
+}
+
+// NewUsageHandler は新しいUsageHandlerを生成する
+func NewUsageHandler(usageService services.UsageServiceInterface) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+		now:          time.Now,
+	}
+}
+
+// HandleUsageReport は期間内の使用量と料金を日・エンドポイント・テナントごとに集計して返す
+// from/to (YYYY-MM-DD、UTC、両端を含む) を省略した場合は当月1日から今日までを集計する
+// tenant/endpoint を指定した場合はその値で絞り込む
+func (h *UsageHandler) HandleUsageReport(c echo.Context) error {
+	today := h.now().UTC()
+	from := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := today
+
+	var err error
+	if v := c.QueryParam("from"); v != "" {
+		if from, err = time.Parse(usageDateLayout, v); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "from は YYYY-MM-DD 形式で指定してください")
+		}
+	}
+	if v := c.QueryParam("to"); v != "" {
+		if to, err = time.Parse(usageDateLayout, v); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "to は YYYY-MM-DD 形式で指定してください")
+		}
+	}
+
+	report, err := h.usageService.Report(c.Request().Context(), services.UsageReportQuery{
+		From:     from,
+		To:       to,
+		Tenant:   c.QueryParam("tenant"),
+		Endpoint: c.QueryParam("endpoint"),
+	})
+	if err != nil {
+		return newServiceError(c, "使用量の集計に失敗しました", err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
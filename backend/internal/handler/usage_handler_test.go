@@ -0,0 +1,120 @@
+package handler_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bedrock-rag-sample/backend/internal/handler"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageHandler_HandleUsageReport(t *testing.T) {
+	e := echo.New()
+
+	newContext := func(query string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/usage/report?"+query, nil)
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("正常系: 期間とテナントを指定して集計する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUsageService := servicemocks.NewMockUsageServiceInterface(ctrl)
+		usageHandler := handler.NewUsageHandler(mockUsageService)
+
+		mockUsageService.EXPECT().
+			Report(gomock.Any(), services.UsageReportQuery{
+				From:   time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+				To:     time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC),
+				Tenant: "tenant-a",
+			}).
+			Return(&services.UsageReport{
+				From: "2026-10-01", To: "2026-10-15",
+				Rows:  []services.UsageReportRow{{Day: "2026-10-01", Endpoint: "/api/v1/qa", Tenant: "tenant-a", Requests: 1, Calls: 2, CostUSD: 0.01}},
+				Total: services.UsageTotals{Calls: 2, CostUSD: 0.01},
+			}, nil)
+
+		c, rec := newContext("from=2026-10-01&to=2026-10-15&tenant=tenant-a")
+		err := usageHandler.HandleUsageReport(c)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"endpoint":"/api/v1/qa"`)
+		assert.Contains(t, rec.Body.String(), `"cost_usd":0.01`)
+	})
+
+	t.Run("正常系: 期間を省略した場合は当月1日から今日まで", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUsageService := servicemocks.NewMockUsageServiceInterface(ctrl)
+		usageHandler := handler.NewUsageHandler(mockUsageService)
+
+		mockUsageService.EXPECT().
+			Report(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ any, query services.UsageReportQuery) (*services.UsageReport, error) {
+				today := time.Now().UTC()
+				assert.Equal(t, 1, query.From.Day())
+				assert.Equal(t, today.Month(), query.From.Month())
+				assert.Equal(t, today.Day(), query.To.Day())
+				return &services.UsageReport{}, nil
+			})
+
+		c, _ := newContext("")
+		require.NoError(t, usageHandler.HandleUsageReport(c))
+	})
+
+	t.Run("異常系: 日付の形式が不正", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		usageHandler := handler.NewUsageHandler(servicemocks.NewMockUsageServiceInterface(ctrl))
+
+		c, _ := newContext("from=2026/10/01")
+		err := usageHandler.HandleUsageReport(c)
+
+		httpError, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpError.Code)
+	})
+
+	t.Run("異常系: 期間が逆転している場合は400", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUsageService := servicemocks.NewMockUsageServiceInterface(ctrl)
+		usageHandler := handler.NewUsageHandler(mockUsageService)
+
+		mockUsageService.EXPECT().Report(gomock.Any(), gomock.Any()).Return(nil, services.ErrInvalidUsagePeriod)
+
+		c, _ := newContext("from=2026-10-15&to=2026-10-01")
+		err := usageHandler.HandleUsageReport(c)
+
+		httpError, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpError.Code)
+	})
+
+	t.Run("異常系: 集計エラー", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUsageService := servicemocks.NewMockUsageServiceInterface(ctrl)
+		usageHandler := handler.NewUsageHandler(mockUsageService)
+
+		mockUsageService.EXPECT().Report(gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+
+		c, _ := newContext("")
+		err := usageHandler.HandleUsageReport(c)
+
+		httpError, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusInternalServerError, httpError.Code)
+	})
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 予算・クォータのソフトリミットを通知するレスポンスヘッダー
+const (
+	HeaderBudgetWarning = "X-Budget-Warning" // ソフトリミットを超えた項目 (カンマ区切り)
+)
+
+// budgetRemainingHeaders は項目ごとの上限までの残りを返すヘッダー
+var budgetRemainingHeaders = map[string]string{
+	services.BudgetLimitMonthlyTokens: "X-Budget-Monthly-Tokens-Remaining",
+	services.BudgetLimitMonthlyCost:   "X-Budget-Monthly-Cost-Remaining",
+	services.BudgetLimitDailyRequests: "X-Budget-Daily-Requests-Remaining",
+}
+
+// BudgetWarner はテナントのソフトリミットの超過状況を返す (services.BudgetService が実装する)
+type BudgetWarner interface {
+	Warnings(tenant string) []services.BudgetWarning
+}
+
+// BudgetHeaders はテナントの使用量がソフトリミットを超えている場合に警告ヘッダーを付与するミドルウェアを返す
+// このリクエストでの使用量も反映するため、レスポンスの書き込み直前に判定する
+func BudgetHeaders(budget BudgetWarner) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			res := c.Response()
+			res.Before(func() {
+				warnings := budget.Warnings(TenantKey(c))
+				if len(warnings) == 0 {
+					return
+				}
+				limits := make([]string, 0, len(warnings))
+				for _, w := range warnings {
+					limits = append(limits, w.Limit)
+					if header, ok := budgetRemainingHeaders[w.Limit]; ok {
+						res.Header().Set(header, strconv.FormatFloat(w.Remaining, 'f', -1, 64))
+					}
+				}
+				res.Header().Set(HeaderBudgetWarning, strings.Join(limits, ","))
+			})
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeBudgetWarner map[string][]services.BudgetWarning
+
+func (f fakeBudgetWarner) Warnings(tenant string) []services.BudgetWarning {
+	return f[tenant]
+}
+
+func TestBudgetHeaders(t *testing.T) {
+	e := echo.New()
+	warner := fakeBudgetWarner{
+		"tenant-a": {
+			{Limit: services.BudgetLimitMonthlyCost, Remaining: 1.5},
+			{Limit: services.BudgetLimitDailyRequests, Remaining: 3},
+		},
+	}
+	h := BudgetHeaders(warner)(func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	call := func(tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/qa", nil)
+		req.Header.Set(HeaderTenantID, tenant)
+		rec := httptest.NewRecorder()
+		_ = h(e.NewContext(req, rec))
+		return rec
+	}
+
+	t.Run("ソフトリミットを超えた項目と残りをヘッダーで通知する", func(t *testing.T) {
+		rec := call("tenant-a")
+
+		assert.Equal(t, "monthly_cost,daily_requests", rec.Header().Get(HeaderBudgetWarning))
+		assert.Equal(t, "1.5", rec.Header().Get("X-Budget-Monthly-Cost-Remaining"))
+		assert.Equal(t, "3", rec.Header().Get("X-Budget-Daily-Requests-Remaining"))
+		assert.Empty(t, rec.Header().Get("X-Budget-Monthly-Tokens-Remaining"))
+	})
+
+	t.Run("超えていなければヘッダーを付与しない", func(t *testing.T) {
+		rec := call("tenant-b")
+
+		assert.Empty(t, rec.Header().Get(HeaderBudgetWarning))
+	})
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NewIPExtractor はリクエストの送信元IPアドレスを決める echo.IPExtractor を返す
+// trustedProxies (ALBなどのCIDR) を指定した場合は、それらから受け取った X-Forwarded-For のうち信頼しないアドレスで最も右のものを使う
+// 指定しない場合はクライアントが自由に設定できる X-Forwarded-For / X-Real-IP を使わず、接続元のアドレスを使う
+func NewIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	// 既定で信頼されるループバック・リンクローカル・プライベートアドレスは信頼せず、指定した範囲のみを信頼する
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, cidr := range trustedProxies {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("信頼するプロキシのCIDRが不正です (%s): %w", cidr, err)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIPExtractor(t *testing.T) {
+	newRequest := func(remoteAddr, xff string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		if xff != "" {
+			req.Header.Set(echo.HeaderXForwardedFor, xff)
+		}
+		req.Header.Set(echo.HeaderXRealIP, "192.0.2.1")
+		return req
+	}
+
+	t.Run("正常系: プロキシを指定しない場合はヘッダーを使わず接続元のアドレスを使う", func(t *testing.T) {
+		extract, err := NewIPExtractor(nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "203.0.113.10", extract(newRequest("203.0.113.10:50000", "198.51.100.1")))
+	})
+
+	t.Run("正常系: 信頼するプロキシが付与したX-Forwarded-Forからクライアントのアドレスを使う", func(t *testing.T) {
+		extract, err := NewIPExtractor([]string{"10.0.0.0/16"})
+		require.NoError(t, err)
+
+		// クライアントが先頭に偽装したアドレスを付けても、ALBが末尾に追加した接続元のアドレスを使う
+		assert.Equal(t, "203.0.113.10", extract(newRequest("10.0.1.5:50000", "198.51.100.1, 203.0.113.10")))
+		assert.Equal(t, "203.0.113.10", extract(newRequest("10.0.1.5:50000", "10.0.0.9, 203.0.113.10")))
+	})
+
+	t.Run("正常系: 信頼しない接続元から受け取ったX-Forwarded-Forは使わない", func(t *testing.T) {
+		extract, err := NewIPExtractor([]string{"10.0.0.0/16"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "203.0.113.10", extract(newRequest("203.0.113.10:50000", "198.51.100.1")))
+	})
+
+	t.Run("異常系: CIDRが不正", func(t *testing.T) {
+		_, err := NewIPExtractor([]string{"10.0.0.0"})
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+)
+
+// HeaderAPIKey はクライアントを識別するAPIキーのヘッダー
+const HeaderAPIKey = "X-API-Key"
+
+// fallbackRetryAfterSeconds は再試行可能な時刻を算出できない場合の Retry-After の値
+const fallbackRetryAfterSeconds = 60
+
+// RateLimiter はクライアント (送信元IPアドレス) ごとのトークンバケットによるレート制限を行う
+type RateLimiter struct {
+	rps     rate.Limit
+	burst   int
+	idleTTL time.Duration
+
+	mu          sync.Mutex
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+// bucket はクライアント1件分のトークンバケット
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimiter は新しいRateLimiterを作成する
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	burst := cfg.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rps:         rate.Limit(cfg.RequestsPerSecond),
+		burst:       burst,
+		idleTTL:     cfg.IdleTTL,
+		buckets:     make(map[string]*bucket),
+		lastCleanup: time.Now(),
+		now:         time.Now,
+	}
+}
+
+// Middleware はレート制限を行うEchoミドルウェアを返す
+// 上限を超えたリクエストには 429 と Retry-After ヘッダーを返す
+func (l *RateLimiter) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			allowed, retryAfter := l.allow(clientKey(c))
+			if !allowed {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				return echo.NewHTTPError(http.StatusTooManyRequests, "リクエストが多すぎます。しばらく待ってから再度お試しください")
+			}
+			return next(c)
+		}
+	}
+}
+
+// allow はトークンを1つ消費できるかを判定する
+// 消費できない場合は、次のトークンが補充されるまでの秒数 (切り上げ) を返す
+func (l *RateLimiter) allow(key string) (bool, int) {
+	now := l.now()
+
+	l.mu.Lock()
+	l.cleanupLocked(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(l.rps, l.burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	l.mu.Unlock()
+
+	r := b.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		// 補充レートが0でバケットが空になった場合など、待っても許可されない
+		return false, fallbackRetryAfterSeconds
+	}
+	delay := r.DelayFrom(now)
+	if delay <= 0 {
+		return true, 0
+	}
+	// 待たずに拒否するため予約を取り消してトークンを返却する
+	r.CancelAt(now)
+	return false, int(math.Ceil(delay.Seconds()))
+}
+
+// cleanupLocked はしばらくアクセスのないクライアントのバケットを破棄する (呼び出し側でロックを保持すること)
+func (l *RateLimiter) cleanupLocked(now time.Time) {
+	if l.idleTTL <= 0 || now.Sub(l.lastCleanup) < l.idleTTL {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) >= l.idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastCleanup = now
+}
+
+// clientKey はレート制限の単位となるクライアント識別子を返す
+// X-API-Key は検証していない値のため、リクエストごとに別の値を送れば新しいバケットを得られてしまう
+// そのためAPIキーは使わず、送信元IP単位で制限する (送信元IPは e.IPExtractor で決める。NewIPExtractor を参照)
+func clientKey(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
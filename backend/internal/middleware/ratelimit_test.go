@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Middleware(t *testing.T) {
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+
+	newLimiter := func(now *time.Time) *RateLimiter {
+		l := NewRateLimiter(config.RateLimitConfig{RequestsPerSecond: 1, Burst: 2, IdleTTL: time.Minute})
+		l.now = func() time.Time { return *now }
+		return l
+	}
+	call := func(h echo.HandlerFunc, apiKey, ip string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/qa", nil)
+		if apiKey != "" {
+			req.Header.Set(HeaderAPIKey, apiKey)
+		}
+		req.RemoteAddr = ip + ":50000"
+		rec := httptest.NewRecorder()
+		return rec, h(e.NewContext(req, rec))
+	}
+
+	t.Run("バースト超過で429とRetry-Afterを返す", func(t *testing.T) {
+		now := time.Now()
+		h := newLimiter(&now).Middleware()(ok)
+
+		for i := 0; i < 2; i++ {
+			_, err := call(h, "", "10.0.0.1")
+			require.NoError(t, err)
+		}
+
+		rec, err := call(h, "", "10.0.0.1")
+		require.Error(t, err)
+		httpError, isHTTPError := err.(*echo.HTTPError)
+		require.True(t, isHTTPError)
+		assert.Equal(t, http.StatusTooManyRequests, httpError.Code)
+		assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("時間経過でトークンが補充される", func(t *testing.T) {
+		now := time.Now()
+		h := newLimiter(&now).Middleware()(ok)
+
+		for i := 0; i < 2; i++ {
+			_, err := call(h, "", "10.0.0.1")
+			require.NoError(t, err)
+		}
+		_, err := call(h, "", "10.0.0.1")
+		require.Error(t, err)
+
+		now = now.Add(time.Second)
+		_, err = call(h, "", "10.0.0.1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("IPごとに独立して制限し、APIキーを変えても回避できない", func(t *testing.T) {
+		now := time.Now()
+		l := newLimiter(&now)
+		h := l.Middleware()(ok)
+
+		for i := 0; i < 2; i++ {
+			_, err := call(h, "key-a", "10.0.0.1")
+			require.NoError(t, err)
+		}
+		_, err := call(h, "key-a", "10.0.0.1")
+		require.Error(t, err)
+
+		// 検証していないAPIキーを変えても同じIPのバケットを使う
+		_, err = call(h, "key-b", "10.0.0.1")
+		assert.Error(t, err)
+		_, err = call(h, "", "10.0.0.1")
+		assert.Error(t, err)
+		// 別のIPは別のバケット
+		_, err = call(h, "key-a", "10.0.0.2")
+		assert.NoError(t, err)
+		assert.Len(t, l.buckets, 2)
+	})
+
+	t.Run("X-Forwarded-ForやX-Real-IPを偽装しても同じバケットを使う", func(t *testing.T) {
+		now := time.Now()
+		l := newLimiter(&now)
+		h := l.Middleware()(ok)
+
+		for i := 0; i < 3; i++ {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/qa", nil)
+			req.RemoteAddr = "203.0.113.10:50000"
+			req.Header.Set(echo.HeaderXForwardedFor, fmt.Sprintf("198.51.100.%d", i))
+			req.Header.Set(echo.HeaderXRealIP, fmt.Sprintf("192.0.2.%d", i))
+			err := h(e.NewContext(req, httptest.NewRecorder()))
+			if i < 2 {
+				require.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		}
+		assert.Len(t, l.buckets, 1)
+	})
+
+	t.Run("アイドル状態のバケットを破棄する", func(t *testing.T) {
+		now := time.Now()
+		l := newLimiter(&now)
+		h := l.Middleware()(ok)
+
+		_, err := call(h, "", "10.0.0.1")
+		require.NoError(t, err)
+		require.Len(t, l.buckets, 1)
+
+		now = now.Add(2 * time.Minute)
+		_, err = call(h, "", "10.0.0.2")
+		require.NoError(t, err)
+		assert.Len(t, l.buckets, 1)
+	})
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderTenantID はテナントを識別するヘッダー
+const HeaderTenantID = "X-Tenant-ID"
+
+// anonymousTenant はテナントもAPIキーも指定されていないリクエストのテナント
+const anonymousTenant = "anonymous"
+
+// UsageScope はモデル呼び出しの使用量をリクエストID・テナント・エンドポイントに帰属させるミドルウェアを返す
+// ルーティング後に実行されるよう、ルートグループのミドルウェアとして登録すること
+func UsageScope() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := services.WithUsageScope(req.Context(), services.UsageScope{
+				RequestID: requestID(c),
+				Tenant:    TenantKey(c),
+				Endpoint:  c.Path(),
+			})
+			c.SetRequest(req.WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// TenantKey はリクエストのテナントを返す
+// X-Tenant-ID があればその値、なければAPIキーのハッシュ (キーそのものは記録しない) を使う
+// どちらのヘッダーも検証していないため、ヘッダーを変えれば別のテナントとして扱われる
+// テナントごとの予算・クォータは協調的なクライアント向けの目安であり、悪意のあるクライアントの利用を制限するものではない
+func TenantKey(c echo.Context) string {
+	if tenant := c.Request().Header.Get(HeaderTenantID); tenant != "" {
+		return tenant
+	}
+	if apiKey := c.Request().Header.Get(HeaderAPIKey); apiKey != "" {
+		sum := sha256.Sum256([]byte(apiKey))
+		return "key:" + hex.EncodeToString(sum[:])[:12]
+	}
+	return anonymousTenant
+}
+
+// requestID はリクエストIDを返す (ヘッダーになければロガーが生成してレスポンスヘッダーに設定した値を使う)
+func requestID(c echo.Context) string {
+	if id := c.Request().Header.Get(echo.HeaderXRequestID); id != "" {
+		return id
+	}
+	return c.Response().Header().Get(echo.HeaderXRequestID)
+}
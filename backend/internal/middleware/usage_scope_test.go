@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageScope(t *testing.T) {
+	e := echo.New()
+	var got services.UsageScope
+	e.POST("/api/v1/qa", func(c echo.Context) error {
+		got = services.UsageScopeFromContext(c.Request().Context())
+		return c.NoContent(http.StatusOK)
+	}, UsageScope())
+
+	call := func(headers map[string]string) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/qa", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	t.Run("テナントヘッダーとリクエストIDを帰属先にする", func(t *testing.T) {
+		call(map[string]string{HeaderTenantID: "tenant-a", HeaderAPIKey: "secret", echo.HeaderXRequestID: "req-1"})
+
+		assert.Equal(t, "req-1", got.RequestID)
+		assert.Equal(t, "tenant-a", got.Tenant)
+		assert.Equal(t, "/api/v1/qa", got.Endpoint)
+	})
+
+	t.Run("テナントがなければAPIキーのハッシュを使い、キーそのものは含めない", func(t *testing.T) {
+		call(map[string]string{HeaderAPIKey: "secret"})
+
+		assert.Regexp(t, `^key:[0-9a-f]{12}$`, got.Tenant)
+		assert.NotContains(t, got.Tenant, "secret")
+	})
+
+	t.Run("どちらもなければ匿名", func(t *testing.T) {
+		call(nil)
+
+		assert.Equal(t, "anonymous", got.Tenant)
+	})
+}
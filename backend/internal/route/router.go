@@ -12,9 +12,20 @@ func SetupRoutes(e *echo.Echo,
 	summarizeHandler *handler.SummarizeHandler,
 	qaHandler *handler.QAHandler,
 	documentHandler *handler.DocumentHandler,
-	recommendHandler *handler.RecommendHandler) {
+	recommendHandler *handler.RecommendHandler,
+	ingestHandler *handler.IngestHandler,
+	documentVersionHandler *handler.DocumentVersionHandler,
+	reindexHandler *handler.ReindexHandler,
+	modelHandler *handler.ModelHandler,
+	usageHandler *handler.UsageHandler,
+	embeddingCacheHandler *handler.EmbeddingCacheHandler,
+	syncHandler *handler.SyncHandler,
+	extractHandler *handler.ExtractHandler,
+	documentTagHandler *handler.DocumentTagHandler,
+	middlewares ...echo.MiddlewareFunc) {
 
-	api := e.Group("/api/v1")
+	// middlewares はAPI全体に適用する (レート制限など)
+	api := e.Group("/api/v1", middlewares...)
 
 	// アップロードエンドポイント
 	api.POST("/upload", uploadHandler.HandleUpload)
@@ -23,16 +34,76 @@ func SetupRoutes(e *echo.Echo,
 	api.POST("/summarize/text", summarizeHandler.HandleTextSummarize)
 	api.POST("/summarize/file", summarizeHandler.HandleFileSummarize)
 
+	// リクエストで指定できるテキスト生成モデルの一覧
+	api.GET("/models", modelHandler.HandleListModels)
+
 	// QAエンドポイント
 	if qaHandler != nil {
 		api.POST("/qa", qaHandler.HandleQA)
+		api.DELETE("/qa/cache", qaHandler.HandleInvalidateCache)
 	}
 
 	// ドキュメント処理エンドポイント
 	api.POST("/document/process", documentHandler.HandleProcessDocument)
 
+	// 構造化データ抽出エンドポイント (呼び出し元が指定したJSON Schemaに従う)
+	if extractHandler != nil {
+		api.POST("/extract", extractHandler.HandleExtract)
+	}
+
 	// レコメンドエンドポイント
 	if recommendHandler != nil {
 		api.POST("/recommend", recommendHandler.HandleRecommend)
 	}
+
+	// ドキュメント取り込みエンドポイント (重複・類似ドキュメントの検出を含む)
+	if ingestHandler != nil {
+		api.POST("/documents", ingestHandler.HandleIngest)
+		api.POST("/documents/:id/versions", ingestHandler.HandleIngestVersion)
+	}
+
+	// ドキュメントの版の一覧・取得・差分
+	if documentVersionHandler != nil {
+		api.GET("/documents/:id/versions", documentVersionHandler.HandleListVersions)
+		api.GET("/documents/:id/versions/:version", documentVersionHandler.HandleGetVersion)
+		api.GET("/documents/:id/diff", documentVersionHandler.HandleDiff)
+	}
+
+	// ドキュメントの分類 (カテゴリとタグ) の編集
+	if documentTagHandler != nil {
+		api.PUT("/documents/:id/tags", documentTagHandler.HandleUpdateTags)
+	}
+
+	// S3のプレフィックスに直接置かれたドキュメントの同期
+	if syncHandler != nil {
+		api.POST("/documents/sync", syncHandler.HandleStartSync)
+		api.GET("/documents/sync", syncHandler.HandleSyncStatus)
+	}
+
+	// Embeddingモデル切り替え用の再インデックスエンドポイント
+	if reindexHandler != nil {
+		api.POST("/embeddings/reindex", reindexHandler.HandleStartReindex)
+		api.GET("/embeddings/reindex", reindexHandler.HandleReindexStatus)
+		api.POST("/embeddings/reindex/activate", reindexHandler.HandleActivate)
+		api.POST("/embeddings/reindex/rollback", reindexHandler.HandleRollback)
+		api.POST("/embeddings/reindex/finalize", reindexHandler.HandleFinalize)
+		api.POST("/embeddings/reindex/cancel", reindexHandler.HandleCancel)
+	}
+
+	// Embeddingキャッシュのヒット率
+	if embeddingCacheHandler != nil {
+		api.GET("/embeddings/cache/stats", embeddingCacheHandler.HandleStats)
+	}
+
+	// トークン使用量と料金のレポート
+	if usageHandler != nil {
+		api.GET("/usage/report", usageHandler.HandleUsageReport)
+	}
+}
+
+// SetupHealthRoutes はliveness/readinessチェックのルートを設定する
+// ロードバランサーやECSから参照されるため、APIのバージョンとは切り離してルート直下に配置する
+func SetupHealthRoutes(e *echo.Echo, healthHandler *handler.HealthHandler) {
+	e.GET("/livez", healthHandler.HandleLivez)
+	e.GET("/readyz", healthHandler.HandleReadyz)
 }
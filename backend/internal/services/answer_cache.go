@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AnswerCache はクエリEmbeddingが類似した過去の質問の回答を再利用するキャッシュ
+// キャッシュはDBに保存するため、複数のタスク間で共有される
+// ドキュメントの取り込み・削除時にはDB側で全件を破棄する
+// InvalidateDocument はKnowledge BaseのドキュメントIDを指定して、そのドキュメントを根拠にした回答のみを破棄する
+type AnswerCache struct {
+	dbHandler domain.DBHandlerInterface
+	cfg       config.AnswerCacheConfig
+	now       func() time.Time
+}
+
+// NewAnswerCache は新しいAnswerCacheを作成する
+func NewAnswerCache(dbHandler domain.DBHandlerInterface, cfg config.AnswerCacheConfig) *AnswerCache {
+	return &AnswerCache{
+		dbHandler: dbHandler,
+		cfg:       cfg,
+		now:       time.Now,
+	}
+}
+
+// Lookup はクエリEmbeddingとの類似度がしきい値以上の有効な回答を返す
+// キャッシュを参照できない場合も回答の生成は続けられるため、エラーは記録のみ行いキャッシュなしとして扱う
+func (c *AnswerCache) Lookup(ctx context.Context, query, model string, space domain.EmbeddingSpace, embedding []float32) (*QAResult, bool) {
+	entry, err := c.dbHandler.FindCachedAnswer(ctx, model, space, embedding, c.now())
+	if err != nil {
+		log.Error().Err(err).Str("model", model).Msg("Failed to look up cached answer")
+		return nil, false
+	}
+	if entry == nil || entry.Similarity < c.cfg.SimilarityThreshold {
+		return nil, false
+	}
+
+	var result QAResult
+	if err := json.Unmarshal(entry.Result, &result); err != nil {
+		log.Error().Err(err).Int64("cache_id", entry.ID).Msg("Failed to decode cached answer")
+		return nil, false
+	}
+	log.Debug().Int64("cache_id", entry.ID).Float64("similarity", entry.Similarity).Str("model", model).Msg("Answer cache hit")
+
+	result.Query = query
+	result.Cached = true
+	return &result, true
+}
+
+// Store は生成した回答をキャッシュに保存する
+// 保存に失敗しても回答は返せるため、エラーは記録のみ行う
+func (c *AnswerCache) Store(ctx context.Context, model string, space domain.EmbeddingSpace, embedding []float32, result *QAResult) {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode answer for cache")
+		return
+	}
+
+	documentIDs := make([]string, 0, len(result.RetrievedDocuments))
+	for _, doc := range result.RetrievedDocuments {
+		if doc.DocumentID != "" {
+			documentIDs = append(documentIDs, doc.DocumentID)
+		}
+	}
+
+	now := c.now()
+	err = c.dbHandler.SaveCachedAnswer(ctx, domain.CachedAnswer{
+		Query:       result.Query,
+		Model:       model,
+		Space:       space,
+		Embedding:   embedding,
+		Result:      payload,
+		DocumentIDs: documentIDs,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(c.cfg.TTL),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("model", model).Msg("Failed to save cached answer")
+	}
+}
+
+// Invalidate はキャッシュした回答をすべて破棄し、破棄した件数を返す
+// Knowledge Baseのデータソースを同期した後など、ドキュメントの変更をアプリケーションが検知できない場合に使用する
+func (c *AnswerCache) Invalidate(ctx context.Context) (int64, error) {
+	n, err := c.dbHandler.InvalidateCachedAnswers(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("回答キャッシュの破棄に失敗しました: %w", err)
+	}
+	return n, nil
+}
+
+// InvalidateDocument は指定したドキュメントを根拠にした回答を破棄し、破棄した件数を返す
+func (c *AnswerCache) InvalidateDocument(ctx context.Context, documentID string) (int64, error) {
+	n, err := c.dbHandler.InvalidateCachedAnswersForDocument(ctx, documentID)
+	if err != nil {
+		return 0, fmt.Errorf("回答キャッシュの破棄に失敗しました: %w", err)
+	}
+	return n, nil
+}
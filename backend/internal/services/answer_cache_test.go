@@ -0,0 +1,177 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	domainmocks "bedrock-rag-sample/backend/internal/domain/mocks"
+	"bedrock-rag-sample/backend/internal/services"
+	"bedrock-rag-sample/backend/internal/services/mocks"
+	"bedrock-rag-sample/backend/pkg/aws"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQAService_SimpleRAG_AnswerCache(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{AWS: config.AWSConfig{Region: "us-east-1", KnowledgeBaseID: "test-kb-id"}}
+	cacheCfg := config.AnswerCacheConfig{Enabled: true, SimilarityThreshold: 0.95, TTL: time.Hour}
+	space := domain.EmbeddingSpace{Model: "titan-v2-256", Dimension: 2}
+	embedding := []float32{0.6, 0.8}
+	model := "claude-3-haiku"
+
+	setup := func(t *testing.T) (*services.QAService, *mocks.MockBedrockClientInterface, *domainmocks.MockDBHandlerInterface) {
+		ctrl := gomock.NewController(t)
+		mockBedrockClient := mocks.NewMockBedrockClientInterface(ctrl)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		mockBedrockClient.EXPECT().EmbeddingModel().Return(aws.EmbeddingModel{Name: space.Model, Dimension: space.Dimension}).AnyTimes()
+		mockBedrockClient.EXPECT().TextModel().Return(aws.TextModel{Name: model}).AnyTimes()
+
+		qas, err := services.NewQAService(mockBedrockClient, cfg, nil, services.NewAnswerCache(mockDBHandler, cacheCfg), nil)
+		require.NoError(t, err)
+		return qas, mockBedrockClient, mockDBHandler
+	}
+
+	t.Run("正常系_類似した質問の回答をキャッシュから返す", func(t *testing.T) {
+		qas, mockBedrockClient, mockDBHandler := setup(t)
+		cached, err := json.Marshal(services.QAResult{Query: "有給休暇の申請方法は？", Answer: "ポータルから申請します。", Model: model})
+		require.NoError(t, err)
+
+		mockBedrockClient.EXPECT().GenerateEmbedding(gomock.Any(), "有給の申請方法を教えて").Return(embedding, nil)
+		mockDBHandler.EXPECT().FindCachedAnswer(gomock.Any(), model, space, embedding, gomock.Any()).
+			Return(&domain.CachedAnswer{ID: 1, Result: cached, Similarity: 0.97}, nil)
+		mockBedrockClient.EXPECT().GenerateText(gomock.Any(), gomock.Any()).Times(0)
+
+		result, err := qas.SimpleRAG(ctx, "有給の申請方法を教えて", "")
+
+		require.NoError(t, err)
+		assert.True(t, result.Cached)
+		assert.Equal(t, "有給の申請方法を教えて", result.Query)
+		assert.Equal(t, "ポータルから申請します。", result.Answer)
+	})
+
+	t.Run("正常系_しきい値未満の場合は回答を生成してキャッシュする", func(t *testing.T) {
+		qas, mockBedrockClient, mockDBHandler := setup(t)
+		query := "経費精算の締め日は？"
+
+		mockBedrockClient.EXPECT().GenerateEmbedding(gomock.Any(), query).Return(embedding, nil)
+		mockDBHandler.EXPECT().FindCachedAnswer(gomock.Any(), model, space, embedding, gomock.Any()).
+			Return(&domain.CachedAnswer{ID: 1, Result: []byte(`{}`), Similarity: 0.90}, nil)
+		mockBedrockClient.EXPECT().GenerateText(gomock.Any(), gomock.Any()).Return("毎月25日です。", nil)
+		mockDBHandler.EXPECT().SaveCachedAnswer(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, entry domain.CachedAnswer) error {
+				assert.Equal(t, query, entry.Query)
+				assert.Equal(t, model, entry.Model)
+				assert.Equal(t, space, entry.Space)
+				assert.Equal(t, embedding, entry.Embedding)
+				assert.Equal(t, []string{"mock-doc-001"}, entry.DocumentIDs)
+				assert.Equal(t, time.Hour, entry.ExpiresAt.Sub(entry.CreatedAt))
+
+				var stored services.QAResult
+				require.NoError(t, json.Unmarshal(entry.Result, &stored))
+				assert.Equal(t, "毎月25日です。", stored.Answer)
+				assert.False(t, stored.Cached)
+				return nil
+			})
+
+		result, err := qas.SimpleRAG(ctx, query, "")
+
+		require.NoError(t, err)
+		assert.False(t, result.Cached)
+		assert.Equal(t, "毎月25日です。", result.Answer)
+	})
+
+	t.Run("正常系_キャッシュを参照できない場合も回答を生成する", func(t *testing.T) {
+		qas, mockBedrockClient, mockDBHandler := setup(t)
+		query := "社内Wi-Fiのパスワードは？"
+
+		mockBedrockClient.EXPECT().GenerateEmbedding(gomock.Any(), query).Return(embedding, nil)
+		mockDBHandler.EXPECT().FindCachedAnswer(gomock.Any(), model, space, embedding, gomock.Any()).Return(nil, errors.New("db error"))
+		mockBedrockClient.EXPECT().GenerateText(gomock.Any(), gomock.Any()).Return("総務部に確認してください。", nil)
+		mockDBHandler.EXPECT().SaveCachedAnswer(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+
+		result, err := qas.SimpleRAG(ctx, query, "")
+
+		require.NoError(t, err)
+		assert.False(t, result.Cached)
+		assert.Equal(t, "総務部に確認してください。", result.Answer)
+	})
+
+	t.Run("正常系_クエリのEmbeddingに失敗した場合はキャッシュを使わない", func(t *testing.T) {
+		qas, mockBedrockClient, _ := setup(t)
+		query := "健康診断の日程は？"
+
+		mockBedrockClient.EXPECT().GenerateEmbedding(gomock.Any(), query).Return(nil, errors.New("throttled"))
+		mockBedrockClient.EXPECT().GenerateText(gomock.Any(), gomock.Any()).Return("来月です。", nil)
+
+		result, err := qas.SimpleRAG(ctx, query, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "来月です。", result.Answer)
+	})
+}
+
+func TestQAService_InvalidateAnswerCache(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{AWS: config.AWSConfig{Region: "us-east-1", KnowledgeBaseID: "test-kb-id"}}
+
+	t.Run("正常系_全件", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		qas, err := services.NewQAService(mocks.NewMockBedrockClientInterface(ctrl), cfg, nil, services.NewAnswerCache(mockDBHandler, config.AnswerCacheConfig{}), nil)
+		require.NoError(t, err)
+
+		mockDBHandler.EXPECT().InvalidateCachedAnswers(gomock.Any()).Return(int64(3), nil)
+
+		n, err := qas.InvalidateAnswerCache(ctx, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+	})
+
+	t.Run("正常系_ドキュメント指定", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		qas, err := services.NewQAService(mocks.NewMockBedrockClientInterface(ctrl), cfg, nil, services.NewAnswerCache(mockDBHandler, config.AnswerCacheConfig{}), nil)
+		require.NoError(t, err)
+
+		mockDBHandler.EXPECT().InvalidateCachedAnswersForDocument(gomock.Any(), "doc-1").Return(int64(1), nil)
+
+		n, err := qas.InvalidateAnswerCache(ctx, "doc-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+	})
+
+	t.Run("正常系_キャッシュが無効な場合は何もしない", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		qas, err := services.NewQAService(mocks.NewMockBedrockClientInterface(ctrl), cfg, nil, nil, nil)
+		require.NoError(t, err)
+
+		n, err := qas.InvalidateAnswerCache(ctx, "")
+
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("異常系_DBエラー", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		qas, err := services.NewQAService(mocks.NewMockBedrockClientInterface(ctrl), cfg, nil, services.NewAnswerCache(mockDBHandler, config.AnswerCacheConfig{}), nil)
+		require.NoError(t, err)
+
+		dbErr := errors.New("db error")
+		mockDBHandler.EXPECT().InvalidateCachedAnswers(gomock.Any()).Return(int64(0), dbErr)
+
+		_, err = qas.InvalidateAnswerCache(ctx, "")
+
+		assert.ErrorIs(t, err, dbErr)
+	})
+}
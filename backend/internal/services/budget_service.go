@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/pkg/aws"
+
+	"github.com/rs/zerolog/log"
+)
+
+// 予算・クォータの種類
+const (
+	BudgetLimitMonthlyTokens = "monthly_tokens"
+	BudgetLimitMonthlyCost   = "monthly_cost"
+	BudgetLimitDailyRequests = "daily_requests"
+)
+
+// ErrBudgetExceeded はテナントの予算・クォータの上限に達した場合のエラー
+var ErrBudgetExceeded = errors.New("利用上限に達しました")
+
+// BudgetExceededError は上限に達した予算・クォータと、利用が再開できる時刻を表すエラー
+type BudgetExceededError struct {
+	Tenant  string
+	Limit   string    // BudgetLimitMonthlyTokens など
+	ResetAt time.Time // 集計期間が切り替わる時刻 (UTC)
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%v (tenant: %s, limit: %s, reset_at: %s)", ErrBudgetExceeded, e.Tenant, e.Limit, e.ResetAt.Format(time.RFC3339))
+}
+
+func (e *BudgetExceededError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+// BudgetWarning はソフトリミットを超えた予算・クォータ
+type BudgetWarning struct {
+	Limit     string
+	Remaining float64 // 上限までの残り (トークン数・USD・リクエスト数)
+}
+
+// BudgetService はテナントごとの予算・クォータを管理するサービス
+// モデルの呼び出し前に上限を確認し (aws.UsageGuard)、呼び出し後の使用量を加算する (aws.UsageRecorder)
+// 使用量は RefreshInterval ごとにDBから読み直し、その間はこのプロセスでの使用量を加算して判定する
+// テナントはクライアントが指定したヘッダーから決まるため (middleware.TenantKey)、上限は目安として扱うこと
+type BudgetService struct {
+	dbHandler domain.DBHandlerInterface
+	cfg       config.BudgetConfig
+	prices    PriceTable
+	next      aws.UsageRecorder // 使用量の記録先 (UsageService)
+	now       func() time.Time
+
+	mu      sync.Mutex
+	tenants map[string]*tenantBudgetState
+}
+
+// tenantBudgetState はテナント1件分の判定用の使用量
+type tenantBudgetState struct {
+	monthStart  time.Time
+	dayStart    time.Time
+	refreshedAt time.Time
+	usage       domain.TenantUsage
+}
+
+// NewBudgetService は新しいBudgetServiceを作成する
+func NewBudgetService(dbHandler domain.DBHandlerInterface, cfg config.BudgetConfig, prices PriceTable, next aws.UsageRecorder) *BudgetService {
+	return &BudgetService{
+		dbHandler: dbHandler,
+		cfg:       cfg,
+		prices:    prices,
+		next:      next,
+		now:       time.Now,
+		tenants:   make(map[string]*tenantBudgetState),
+	}
+}
+
+// LimitsFor はテナントの上限を返す (個別に設定されていない項目は既定値)
+func (s *BudgetService) LimitsFor(tenant string) config.TenantBudget {
+	limits := s.cfg.Default
+	if override, ok := s.cfg.Tenants[tenant]; ok {
+		if override.MonthlyTokens > 0 {
+			limits.MonthlyTokens = override.MonthlyTokens
+		}
+		if override.MonthlyCostUSD > 0 {
+			limits.MonthlyCostUSD = override.MonthlyCostUSD
+		}
+		if override.DailyRequests > 0 {
+			limits.DailyRequests = override.DailyRequests
+		}
+	}
+	return limits
+}
+
+// CheckUsage はモデルを呼び出す前にテナントの上限を確認する (aws.UsageGuard の実装)
+// 上限に達していれば *BudgetExceededError を返す。許可した呼び出しが新しいリクエストであれば日次リクエスト数に加算する
+// バックグラウンド処理 (システムテナント) は制限しない。使用量を取得できない場合は利用を止めないよう許可する
+func (s *BudgetService) CheckUsage(ctx context.Context) error {
+	scope := UsageScopeFromContext(ctx)
+	if scope.Tenant == UsageTenantSystem {
+		return nil
+	}
+	limits := s.LimitsFor(scope.Tenant)
+	if limits == (config.TenantBudget{}) {
+		return nil
+	}
+
+	state, err := s.state(ctx, scope.Tenant)
+	if err != nil {
+		log.Error().Err(err).Str("tenant", scope.Tenant).Msg("Failed to load tenant usage; budget check skipped")
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exceeded := func(limit string, resetAt time.Time) error {
+		return &BudgetExceededError{Tenant: scope.Tenant, Limit: limit, ResetAt: resetAt}
+	}
+	switch {
+	case limits.MonthlyTokens > 0 && state.usage.MonthlyTokens >= limits.MonthlyTokens:
+		return exceeded(BudgetLimitMonthlyTokens, state.monthStart.AddDate(0, 1, 0))
+	case limits.MonthlyCostUSD > 0 && state.usage.MonthlyCostUSD >= limits.MonthlyCostUSD:
+		return exceeded(BudgetLimitMonthlyCost, state.monthStart.AddDate(0, 1, 0))
+	}
+
+	// 同じリクエストからの2回目以降の呼び出しはリクエスト数に数えない
+	// クライアントが指定できる X-Request-ID ではなく、サーバーがリクエストごとに作成した帰属先で判定する
+	if scope.counted == nil || scope.counted.Load() {
+		return nil
+	}
+	if limits.DailyRequests > 0 && state.usage.DailyRequests >= limits.DailyRequests {
+		return exceeded(BudgetLimitDailyRequests, state.dayStart.AddDate(0, 0, 1))
+	}
+	scope.counted.Store(true)
+	state.usage.DailyRequests++
+	return nil
+}
+
+// RecordUsage は使用量を判定用の集計に加算し、記録先に渡す (aws.UsageRecorder の実装)
+func (s *BudgetService) RecordUsage(ctx context.Context, event aws.UsageEvent) {
+	scope := UsageScopeFromContext(ctx)
+	cost, _ := s.prices.Cost(event)
+
+	s.mu.Lock()
+	if state, ok := s.tenants[scope.Tenant]; ok {
+		state.usage.MonthlyTokens += int64(event.InputTokens + event.OutputTokens)
+		state.usage.MonthlyCostUSD += cost
+	}
+	s.mu.Unlock()
+
+	if s.next != nil {
+		s.next.RecordUsage(ctx, event)
+	}
+}
+
+// Warnings はテナントの使用量がソフトリミットを超えている予算・クォータを返す
+// 判定用の集計のみを参照し、DBは読まない (集計がまだないテナントは警告なし)
+func (s *BudgetService) Warnings(tenant string) []BudgetWarning {
+	limits := s.LimitsFor(tenant)
+
+	s.mu.Lock()
+	state, ok := s.tenants[tenant]
+	var usage domain.TenantUsage
+	if ok {
+		usage = state.usage
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	var warnings []BudgetWarning
+	check := func(name string, used, limit float64) {
+		if limit > 0 && used >= limit*s.cfg.SoftLimitRatio {
+			warnings = append(warnings, BudgetWarning{Limit: name, Remaining: max(limit-used, 0)})
+		}
+	}
+	check(BudgetLimitMonthlyTokens, float64(usage.MonthlyTokens), float64(limits.MonthlyTokens))
+	check(BudgetLimitMonthlyCost, usage.MonthlyCostUSD, limits.MonthlyCostUSD)
+	check(BudgetLimitDailyRequests, float64(usage.DailyRequests), float64(limits.DailyRequests))
+	return warnings
+}
+
+// state はテナントの判定用の集計を返す
+// 集計期間が切り替わった場合や RefreshInterval を過ぎた場合はDBから読み直す
+func (s *BudgetService) state(ctx context.Context, tenant string) (*tenantBudgetState, error) {
+	now := s.now().UTC()
+	dayStart := truncateToDay(now)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	s.mu.Lock()
+	state, ok := s.tenants[tenant]
+	fresh := ok && state.dayStart.Equal(dayStart) && now.Sub(state.refreshedAt) < s.cfg.RefreshInterval
+	s.mu.Unlock()
+	if fresh {
+		return state, nil
+	}
+
+	usage, err := s.dbHandler.GetTenantUsage(ctx, tenant, monthStart, dayStart)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state = &tenantBudgetState{
+		monthStart:  monthStart,
+		dayStart:    dayStart,
+		refreshedAt: now,
+		usage:       usage,
+	}
+	s.tenants[tenant] = state
+	return state, nil
+}
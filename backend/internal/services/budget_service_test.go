@@ -0,0 +1,154 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	domainmocks "bedrock-rag-sample/backend/internal/domain/mocks"
+	"bedrock-rag-sample/backend/internal/services"
+	"bedrock-rag-sample/backend/pkg/aws"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedUsage は記録先に渡された使用量を保持する aws.UsageRecorder
+type recordedUsage []aws.UsageEvent
+
+func (r *recordedUsage) RecordUsage(_ context.Context, event aws.UsageEvent) {
+	*r = append(*r, event)
+}
+
+func newBudgetConfig() config.BudgetConfig {
+	return config.BudgetConfig{
+		Enabled:         true,
+		Default:         config.TenantBudget{MonthlyTokens: 10000, MonthlyCostUSD: 1, DailyRequests: 2},
+		Tenants:         map[string]config.TenantBudget{"tenant-big": {MonthlyTokens: 1000000, DailyRequests: 100}},
+		SoftLimitRatio:  0.8,
+		RefreshInterval: time.Minute,
+	}
+}
+
+func tenantContext(tenant, requestID string) context.Context {
+	return services.WithUsageScope(context.Background(), services.UsageScope{RequestID: requestID, Tenant: tenant, Endpoint: "/api/v1/qa"})
+}
+
+func TestBudgetService_LimitsFor(t *testing.T) {
+	budgetService := services.NewBudgetService(nil, newBudgetConfig(), services.NewPriceTable(nil), nil)
+
+	assert.Equal(t, config.TenantBudget{MonthlyTokens: 10000, MonthlyCostUSD: 1, DailyRequests: 2}, budgetService.LimitsFor("tenant-a"))
+	assert.Equal(t, config.TenantBudget{MonthlyTokens: 1000000, MonthlyCostUSD: 1, DailyRequests: 100}, budgetService.LimitsFor("tenant-big"))
+}
+
+func TestBudgetService_CheckUsage(t *testing.T) {
+	t.Run("日次リクエスト数はリクエストごとに数え、上限で拒否する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		budgetService := services.NewBudgetService(mockDBHandler, newBudgetConfig(), services.NewPriceTable(nil), nil)
+
+		mockDBHandler.EXPECT().
+			GetTenantUsage(gomock.Any(), "tenant-a", gomock.Any(), gomock.Any()).
+			Return(domain.TenantUsage{DailyRequests: 1}, nil).
+			Times(1)
+
+		ctx := tenantContext("tenant-a", "req-1")
+		require.NoError(t, budgetService.CheckUsage(ctx))
+		// 同じリクエストの2回目の呼び出し (検索とEmbeddingなど) は数えない
+		require.NoError(t, budgetService.CheckUsage(ctx))
+
+		err := budgetService.CheckUsage(tenantContext("tenant-a", "req-2"))
+
+		var budgetErr *services.BudgetExceededError
+		require.ErrorAs(t, err, &budgetErr)
+		assert.ErrorIs(t, err, services.ErrBudgetExceeded)
+		assert.Equal(t, services.BudgetLimitDailyRequests, budgetErr.Limit)
+		assert.True(t, budgetErr.ResetAt.After(time.Now()))
+		assert.Equal(t, 0, budgetErr.ResetAt.Hour())
+	})
+
+	t.Run("別のリクエストはリクエストIDが同じでも数える", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		budgetService := services.NewBudgetService(mockDBHandler, newBudgetConfig(), services.NewPriceTable(nil), nil)
+
+		mockDBHandler.EXPECT().
+			GetTenantUsage(gomock.Any(), "tenant-a", gomock.Any(), gomock.Any()).
+			Return(domain.TenantUsage{}, nil).
+			Times(1)
+
+		// クライアントが毎回同じ X-Request-ID を送っても上限を回避できない
+		require.NoError(t, budgetService.CheckUsage(tenantContext("tenant-a", "fixed-id")))
+		require.NoError(t, budgetService.CheckUsage(tenantContext("tenant-a", "fixed-id")))
+
+		err := budgetService.CheckUsage(tenantContext("tenant-a", "fixed-id"))
+
+		assert.ErrorIs(t, err, services.ErrBudgetExceeded)
+	})
+
+	t.Run("このプロセスでの使用量を加算し、月間料金の上限で拒否する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		recorder := &recordedUsage{}
+		budgetService := services.NewBudgetService(mockDBHandler, newBudgetConfig(), services.NewPriceTable(nil), recorder)
+
+		mockDBHandler.EXPECT().
+			GetTenantUsage(gomock.Any(), "tenant-big", gomock.Any(), gomock.Any()).
+			Return(domain.TenantUsage{MonthlyCostUSD: 0.5}, nil)
+
+		ctx := tenantContext("tenant-big", "req-1")
+		require.NoError(t, budgetService.CheckUsage(ctx))
+		// claude-3-sonnet の出力 40,000 トークン = 0.6 USD
+		budgetService.RecordUsage(ctx, aws.UsageEvent{Model: "claude-3-sonnet", OutputTokens: 40000})
+
+		err := budgetService.CheckUsage(tenantContext("tenant-big", "req-2"))
+
+		var budgetErr *services.BudgetExceededError
+		require.ErrorAs(t, err, &budgetErr)
+		assert.Equal(t, services.BudgetLimitMonthlyCost, budgetErr.Limit)
+		assert.Equal(t, 1, budgetErr.ResetAt.Day())
+		assert.Len(t, *recorder, 1, "記録先にも使用量を渡す")
+	})
+
+	t.Run("システムテナントは制限しない", func(t *testing.T) {
+		budgetService := services.NewBudgetService(nil, newBudgetConfig(), services.NewPriceTable(nil), nil)
+
+		assert.NoError(t, budgetService.CheckUsage(context.Background()))
+	})
+
+	t.Run("使用量を取得できない場合は許可する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		budgetService := services.NewBudgetService(mockDBHandler, newBudgetConfig(), services.NewPriceTable(nil), nil)
+
+		mockDBHandler.EXPECT().GetTenantUsage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(domain.TenantUsage{}, errors.New("db down"))
+
+		assert.NoError(t, budgetService.CheckUsage(tenantContext("tenant-a", "req-1")))
+	})
+}
+
+func TestBudgetService_Warnings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+	budgetService := services.NewBudgetService(mockDBHandler, newBudgetConfig(), services.NewPriceTable(nil), nil)
+
+	assert.Empty(t, budgetService.Warnings("tenant-a"), "集計がまだないテナントは警告しない")
+
+	mockDBHandler.EXPECT().
+		GetTenantUsage(gomock.Any(), "tenant-a", gomock.Any(), gomock.Any()).
+		Return(domain.TenantUsage{MonthlyTokens: 8500, MonthlyCostUSD: 0.1}, nil)
+	require.NoError(t, budgetService.CheckUsage(tenantContext("tenant-a", "req-1")))
+
+	warnings := budgetService.Warnings("tenant-a")
+
+	assert.Equal(t, []services.BudgetWarning{{Limit: services.BudgetLimitMonthlyTokens, Remaining: 1500}}, warnings)
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"bedrock-rag-sample/backend/internal/domain"
+)
+
+// chunkCitation はチャンクの引用元を返す (取り込み元のないドキュメントの場合は空)
+// gitリポジトリから取り込んだドキュメントは、取り込んだ時点のコミットのファイルと行範囲を示す
+// (リモートのリポジトリはブラウザで開けるURL、ローカルのリポジトリは git+<リポジトリ>#<コミット>:<パス>#L<開始行>-L<終了行>)
+// それ以外のドキュメントは取り込み元のURIを返す
+func chunkCitation(doc *domain.Document, chunk domain.DocumentChunk) string {
+	if doc == nil || doc.SourceURI == "" {
+		return ""
+	}
+	repo, _, p, ok := parseGitSourceURI(doc.SourceURI)
+	if !ok || doc.SourceVersion == "" {
+		return doc.SourceURI
+	}
+
+	var lines string
+	if chunk.StartLine > 0 {
+		lines = fmt.Sprintf("#L%d-L%d", chunk.StartLine, chunk.EndLine)
+	}
+	if web := gitWebURL(repo); web != "" {
+		segments := strings.Split(p, "/")
+		for i, segment := range segments {
+			segments[i] = url.PathEscape(segment)
+		}
+		return web + "/blob/" + doc.SourceVersion + "/" + strings.Join(segments, "/") + lines
+	}
+	return gitSourceURI(repo, doc.SourceVersion, p) + lines
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/pkg/fswatch"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrInvalidWatchDir は監視するディレクトリの設定が不正な場合のエラー
+var ErrInvalidWatchDir = errors.New("監視するディレクトリの設定が不正です")
+
+// maxDebounceFactor は書き込みが続く場合に同期を待つ上限 (Debounce の倍数)
+const maxDebounceFactor = 10
+
+// DirectorySyncService はローカルのディレクトリ配下のファイルを取り込み、documents テーブルと同期するサービス
+// ファイルのパスを取り込み元 (file:///path) として記録し、サイズと更新日時で変更を検出する
+// inotify (使えない場合は定期的な走査) で変更を検出し、連続した書き込みが落ち着いてから同期する
+type DirectorySyncService struct {
+	*syncJob
+	dir *dirSyncSource
+	cfg config.WatchConfig
+}
+
+// NewDirectorySyncService は新しいDirectorySyncServiceを作成する
+func NewDirectorySyncService(dbHandler domain.DBHandlerInterface, ingestService IngestServiceInterface, runner BackgroundRunner, cfg *config.Config) (*DirectorySyncService, error) {
+	root, err := filepath.Abs(cfg.Watch.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWatchDir, err)
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWatchDir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s はディレクトリではありません", ErrInvalidWatchDir, root)
+	}
+	if cfg.Watch.PollInterval <= 0 {
+		return nil, fmt.Errorf("%w: 走査の間隔 (WATCH_POLL_INTERVAL) は正の値を指定してください", ErrInvalidWatchDir)
+	}
+	filter, err := fswatch.NewFilter(cfg.Watch.Include, cfg.Watch.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWatchDir, err)
+	}
+
+	dir := &dirSyncSource{root: root, ignore: filter.Ignore}
+	return &DirectorySyncService{
+		syncJob: newSourceSync("dir-sync", dir, dbHandler, ingestService, runner, cfg.Sync.BatchSize),
+		dir:     dir,
+		cfg:     cfg.Watch,
+	}, nil
+}
+
+// Watch はディレクトリの監視を開始する
+// 開始直後に1回同期し (停止中の変更と中断された同期を反映する)、以降は変更を検出するたびに同期する
+// stop がキャンセルされると監視を終了する (実行中の同期はシャットダウンの猶予期限まで続ける)
+func (s *DirectorySyncService) Watch(stop context.Context) error {
+	return s.runner.Go("dir-watch", func(ctx context.Context) {
+		s.watch(stop, ctx)
+	})
+}
+
+// watch は変更の通知を受け取り、最後の変更から Debounce が経過したら同期する
+// 書き込みが続く場合も、最初の変更から Debounce の maxDebounceFactor 倍を超えては待たない
+func (s *DirectorySyncService) watch(stop, ctx context.Context) {
+	logger := log.With().Str("source", s.Source()).Logger()
+
+	// 監視を開始してから同期し、同期中の変更を取りこぼさないようにする
+	w, err := fswatch.NewInotify(s.dir.root, s.dir.ignore)
+	if err != nil {
+		logger.Warn().Err(err).Dur("interval", s.cfg.PollInterval).Msg("inotify is unavailable; falling back to polling")
+		w, err = fswatch.NewPoller(s.dir.root, s.cfg.PollInterval, s.dir.ignore)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to watch directory")
+			return
+		}
+	}
+	defer w.Close()
+	logger.Info().Msg("Watching directory")
+
+	timer := time.NewTimer(0)
+	pending := timer.C // 開始直後の同期
+	var firstChange time.Time
+	for {
+		select {
+		case <-stop.Done():
+			return
+		case <-ctx.Done():
+			return
+		case rel := <-w.Events():
+			logger.Debug().Str("path", rel).Msg("Directory changed")
+			now := time.Now()
+			if pending == nil {
+				firstChange = now
+			}
+			wait := s.cfg.Debounce
+			if limit := firstChange.Add(maxDebounceFactor * s.cfg.Debounce).Sub(now); limit < wait {
+				wait = max(limit, 0)
+			}
+			timer.Reset(wait)
+			pending = timer.C
+		case <-pending:
+			pending = nil
+			// 他のプロセスが同期中の場合は、その同期が変更を見落とした可能性があるため、改めて同期する
+			if err := s.runLogged(ctx); errors.Is(err, ErrSyncInProgress) {
+				firstChange = time.Now()
+				timer.Reset(s.cfg.Debounce)
+				pending = timer.C
+			}
+		}
+	}
+}
+
+// dirSyncSource はローカルのディレクトリの取り込み元
+// キーはルートからの相対パス (区切りは /)
+type dirSyncSource struct {
+	root   string
+	ignore fswatch.IgnoreFunc
+}
+
+// source は取り込み元のURI (file:///path/) を返す
+// キーのバイト順とURIのバイト順を一致させるため、パスはエスケープしない
+func (d *dirSyncSource) source() string {
+	return d.uri("")
+}
+
+func (d *dirSyncSource) uri(key string) string {
+	return "file://" + filepath.ToSlash(d.root) + "/" + key
+}
+
+func (d *dirSyncSource) listPage(ctx context.Context, startAfter string, limit int) (*syncPage, error) {
+	page := &syncPage{}
+	err := fswatch.Walk(d.root, startAfter, d.ignore, func(rel string, info fs.FileInfo) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(page.objects) == limit {
+			page.truncated = true
+			return fs.SkipAll
+		}
+		page.objects = append(page.objects, syncObject{
+			key:        rel,
+			version:    fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano()),
+			modifiedAt: info.ModTime().Truncate(time.Microsecond),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if n := len(page.objects); n > 0 {
+		page.lastKey = page.objects[n-1].key
+	}
+	return page, nil
+}
+
+func (d *dirSyncSource) read(_ context.Context, key string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(d.root, filepath.FromSlash(key)))
+}
@@ -0,0 +1,215 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	domainmocks "bedrock-rag-sample/backend/internal/domain/mocks"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// goRunner はバックグラウンドタスクをゴルーチンで実行する BackgroundRunner
+type goRunner struct {
+	wg sync.WaitGroup
+}
+
+func (r *goRunner) Go(_ string, fn func(ctx context.Context)) error {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		fn(context.Background())
+	}()
+	return nil
+}
+
+// writeTestFile はテスト用のファイルを作成する (ディレクトリも作成する)
+func writeTestFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	p := filepath.Join(root, filepath.FromSlash(rel))
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+	require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+}
+
+func newWatchTestConfig(dir string) *config.Config {
+	return &config.Config{
+		Sync:  config.SyncConfig{BatchSize: 2},
+		Watch: config.WatchConfig{Dir: dir, Exclude: []string{".*"}, Debounce: 20 * time.Millisecond, PollInterval: 10 * time.Millisecond},
+	}
+}
+
+func TestNewDirectorySyncService(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "a.txt", "a")
+
+	tests := []struct {
+		name   string
+		modify func(cfg *config.WatchConfig)
+	}{
+		{name: "異常系: ディレクトリが存在しない", modify: func(cfg *config.WatchConfig) { cfg.Dir = filepath.Join(dir, "missing") }},
+		{name: "異常系: ディレクトリではない", modify: func(cfg *config.WatchConfig) { cfg.Dir = filepath.Join(dir, "a.txt") }},
+		{name: "異常系: 不正なグロブ", modify: func(cfg *config.WatchConfig) { cfg.Include = []string{"[a-"} }},
+		{name: "異常系: 走査の間隔が0", modify: func(cfg *config.WatchConfig) { cfg.PollInterval = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newWatchTestConfig(dir)
+			tt.modify(&cfg.Watch)
+
+			_, err := services.NewDirectorySyncService(nil, nil, syncRunner{}, cfg)
+
+			assert.ErrorIs(t, err, services.ErrInvalidWatchDir)
+		})
+	}
+
+	t.Run("正常系: 取り込み元のURI", func(t *testing.T) {
+		s, err := services.NewDirectorySyncService(nil, nil, syncRunner{}, newWatchTestConfig(dir))
+
+		require.NoError(t, err)
+		assert.Equal(t, "file://"+filepath.ToSlash(dir)+"/", s.Source())
+	})
+}
+
+func TestDirectorySyncService_Run(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: パスの順にページごとに取り込み、削除されたファイルのドキュメントを削除する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		mockIngestService := servicemocks.NewMockIngestServiceInterface(ctrl)
+
+		dir := t.TempDir()
+		writeTestFile(t, dir, "a.txt", "規程A")
+		writeTestFile(t, dir, "docs/b.md", "規程B")
+		writeTestFile(t, dir, "docs/c.bin", "binary")
+		writeTestFile(t, dir, ".hidden.txt", "除外")
+		s, err := services.NewDirectorySyncService(mockDBHandler, mockIngestService, syncRunner{}, newWatchTestConfig(dir))
+		require.NoError(t, err)
+		source := s.Source()
+
+		// 取り込みリクエストの取り込み元はファイルのパス・サイズ・更新日時
+		expectedRequest := func(rel, content string) services.IngestRequest {
+			info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel)))
+			require.NoError(t, err)
+			modifiedAt := info.ModTime().Truncate(time.Microsecond)
+			return services.IngestRequest{
+				Filename: filepath.Base(rel), Content: []byte(content), DuplicatePolicy: services.DuplicatePolicyVersion,
+				SourceURI: source + rel, SourceVersion: fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano()), SourceModifiedAt: &modifiedAt,
+			}
+		}
+		expectedStats := domain.SyncStats{Created: 2, Deleted: 1, Skipped: 1}
+
+		mockDBHandler.EXPECT().ClaimSyncState(gomock.Any(), source, gomock.Any(), gomock.Any()).Return(&domain.SyncState{Source: source}, true, nil)
+		gomock.InOrder(
+			mockDBHandler.EXPECT().ListDocumentsBySource(gomock.Any(), domain.SourceRange{Prefix: source, Through: source + "docs/b.md"}).Return(nil, nil),
+			mockIngestService.EXPECT().Ingest(gomock.Any(), expectedRequest("a.txt", "規程A")).
+				Return(&services.IngestResult{Document: &domain.Document{ID: 1, Version: 1}}, nil),
+			mockDBHandler.EXPECT().SaveSyncCheckpoint(gomock.Any(), source, gomock.Any(), gomock.Any(), "a.txt", gomock.Any()).Return(nil),
+			mockIngestService.EXPECT().Ingest(gomock.Any(), expectedRequest("docs/b.md", "規程B")).
+				Return(&services.IngestResult{Document: &domain.Document{ID: 2, Version: 1}}, nil),
+			mockDBHandler.EXPECT().SaveSyncCheckpoint(gomock.Any(), source, gomock.Any(), gomock.Any(), "docs/b.md", gomock.Any()).Return(nil).Times(2),
+			mockDBHandler.EXPECT().ListDocumentsBySource(gomock.Any(), domain.SourceRange{Prefix: source, After: source + "docs/b.md"}).
+				Return([]domain.Document{{ID: 9, SourceURI: source + "old.txt"}}, nil),
+			mockDBHandler.EXPECT().DeleteDocument(gomock.Any(), int64(9)).Return(nil),
+			mockDBHandler.EXPECT().SaveSyncCheckpoint(gomock.Any(), source, gomock.Any(), gomock.Any(), "old.txt", gomock.Any()).Return(nil),
+			mockDBHandler.EXPECT().CompleteSyncPass(gomock.Any(), source, gomock.Any(), "", expectedStats).Return(nil),
+		)
+		mockDBHandler.EXPECT().GetSyncState(gomock.Any(), source).Return(&domain.SyncState{Source: source, Stats: expectedStats}, nil)
+
+		state, err := s.Run(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, expectedStats, state.Stats)
+	})
+}
+
+func TestDirectorySyncService_Watch(t *testing.T) {
+	t.Run("正常系: 開始時に同期し、連続した変更をまとめて取り込む", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		mockIngestService := servicemocks.NewMockIngestServiceInterface(ctrl)
+
+		dir := t.TempDir()
+		runner := &goRunner{}
+		cfg := newWatchTestConfig(dir)
+		cfg.Watch.Debounce = 200 * time.Millisecond
+		s, err := services.NewDirectorySyncService(mockDBHandler, mockIngestService, runner, cfg)
+		require.NoError(t, err)
+		source := s.Source()
+
+		// 取り込んだドキュメントを記録し、次の同期で変更なしと判定されるようにする
+		var mu sync.Mutex
+		var docs []domain.Document
+		ingested := make(map[string]int)
+		passes := make(chan domain.SyncStats, 10)
+
+		mockDBHandler.EXPECT().ClaimSyncState(gomock.Any(), source, gomock.Any(), gomock.Any()).
+			Return(&domain.SyncState{Source: source}, true, nil).AnyTimes()
+		mockDBHandler.EXPECT().ListDocumentsBySource(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(context.Context, domain.SourceRange) ([]domain.Document, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				return append([]domain.Document(nil), docs...), nil
+			}).AnyTimes()
+		mockIngestService.EXPECT().Ingest(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req services.IngestRequest) (*services.IngestResult, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				ingested[req.SourceURI]++
+				docs = append(docs, domain.Document{ID: int64(len(docs) + 1), SourceURI: req.SourceURI, SourceVersion: req.SourceVersion, SourceModifiedAt: req.SourceModifiedAt})
+				return &services.IngestResult{Document: &docs[len(docs)-1]}, nil
+			}).AnyTimes()
+		mockDBHandler.EXPECT().SaveSyncCheckpoint(gomock.Any(), source, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		mockDBHandler.EXPECT().CompleteSyncPass(gomock.Any(), source, gomock.Any(), "", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _, _ string, stats domain.SyncStats) error {
+				passes <- stats
+				return nil
+			}).AnyTimes()
+		mockDBHandler.EXPECT().GetSyncState(gomock.Any(), source).Return(&domain.SyncState{Source: source}, nil).AnyTimes()
+
+		waitPass := func() domain.SyncStats {
+			select {
+			case stats := <-passes:
+				return stats
+			case <-time.After(5 * time.Second):
+				t.Fatal("sync did not run")
+				return domain.SyncStats{}
+			}
+		}
+
+		stop, cancel := context.WithCancel(context.Background())
+		require.NoError(t, s.Watch(stop))
+		defer func() {
+			cancel()
+			runner.wg.Wait()
+		}()
+
+		assert.Equal(t, domain.SyncStats{}, waitPass())
+
+		for i := 0; i < 5; i++ {
+			writeTestFile(t, dir, "a.txt", fmt.Sprintf("規程 第%d版", i))
+		}
+		writeTestFile(t, dir, "docs/b.txt", "規程B")
+		writeTestFile(t, dir, "docs/.b.txt.swp", "除外")
+
+		stats := waitPass()
+		assert.Equal(t, 2, stats.Created)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, map[string]int{source + "a.txt": 1, source + "docs/b.txt": 1}, ingested)
+	})
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/pkg/aws"
+)
+
+// ドキュメントの分類の方式
+const (
+	ClassifyModeLLM      = "llm"      // テキスト生成モデルにカテゴリとタグを選ばせる
+	ClassifyModeCentroid = "centroid" // カテゴリごとの説明・例文のEmbeddingの重心と比較してカテゴリを選ぶ (タグは付けない)
+)
+
+const (
+	// maxTagLength はタグの最大文字数
+	maxTagLength = 50
+	// maxDocumentTags はAPIで1つのドキュメントに付けられるタグの上限
+	maxDocumentTags = 20
+)
+
+var (
+	// ErrInvalidClassifyMode は分類の方式の指定が不正な場合のエラー
+	ErrInvalidClassifyMode = errors.New("分類の方式は llm / centroid のいずれかを指定してください")
+	// ErrInvalidTaxonomy はタクソノミーの定義が不正な場合のエラー
+	ErrInvalidTaxonomy = errors.New("タクソノミーの定義が不正です")
+	// ErrUnknownCategory はタクソノミーに定義されていないカテゴリを指定した場合のエラー
+	ErrUnknownCategory = errors.New("タクソノミーに定義されていないカテゴリです")
+	// ErrInvalidTags はタグの指定が不正な場合のエラー
+	ErrInvalidTags = errors.New("タグの指定が不正です")
+)
+
+// defaultCategoryDescriptions は既定のカテゴリの説明 (タクソノミーのファイルを指定しない場合に使用する)
+var defaultCategoryDescriptions = map[string]string{
+	"contract": "契約書・覚書・合意書など、当事者間の権利と義務を定めた文書",
+	"invoice":  "請求書・見積書・領収書など、取引の金額と支払いに関する文書",
+	"manual":   "操作手順書・取扱説明書・ガイドなど、作業や製品の使い方を説明する文書",
+	"policy":   "社内規程・方針・ガイドラインなど、組織のルールを定めた文書",
+}
+
+// TaxonomyCategory はタクソノミーのカテゴリ
+type TaxonomyCategory struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Examples    []string `json:"examples,omitempty"` // カテゴリに該当する文書の例文 (centroid 方式で重心の計算に使用する)
+}
+
+// Taxonomy はドキュメントに付けるカテゴリの一覧
+type Taxonomy struct {
+	Categories []TaxonomyCategory
+}
+
+// LoadTaxonomy は設定からタクソノミーを読み込む
+// TaxonomyFile を指定した場合はそのJSONファイル ([{"name", "description", "examples"}]) を、指定しない場合は Categories を使用する
+func LoadTaxonomy(cfg config.ClassifyConfig) (*Taxonomy, error) {
+	var categories []TaxonomyCategory
+	if cfg.TaxonomyFile != "" {
+		data, err := os.ReadFile(cfg.TaxonomyFile)
+		if err != nil {
+			return nil, fmt.Errorf("タクソノミーのファイルの読み込みに失敗しました: %w", err)
+		}
+		if err := json.Unmarshal(data, &categories); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTaxonomy, err)
+		}
+	} else {
+		for _, name := range cfg.Categories {
+			categories = append(categories, TaxonomyCategory{Name: name, Description: defaultCategoryDescriptions[name]})
+		}
+	}
+
+	seen := make(map[string]bool, len(categories))
+	for i := range categories {
+		name := strings.TrimSpace(categories[i].Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: カテゴリ名が空です", ErrInvalidTaxonomy)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: カテゴリ %q が重複しています", ErrInvalidTaxonomy, name)
+		}
+		seen[name] = true
+		categories[i].Name = name
+	}
+	return &Taxonomy{Categories: categories}, nil
+}
+
+// Has はタクソノミーにカテゴリが定義されているかどうかを返す
+func (t *Taxonomy) Has(name string) bool {
+	for _, c := range t.Categories {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Classification はドキュメントの分類結果
+type Classification struct {
+	Category string   `json:"category"` // 該当するカテゴリがない場合は空
+	Tags     []string `json:"tags"`
+}
+
+// NewDocumentClassifier は設定の方式に応じた分類器を作成する
+// 分類の方式を指定していない場合は nil を返す (取り込み時に分類しない)
+func NewDocumentClassifier(cfg config.ClassifyConfig, taxonomy *Taxonomy, bedrockClient aws.BedrockClientInterface, extractService ExtractServiceInterface) (DocumentClassifier, error) {
+	switch cfg.Mode {
+	case "":
+		return nil, nil
+	case ClassifyModeLLM:
+		return NewLLMClassifier(extractService, taxonomy, cfg)
+	case ClassifyModeCentroid:
+		return NewCentroidClassifier(bedrockClient, taxonomy, cfg), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrInvalidClassifyMode, cfg.Mode)
+}
+
+// LLMClassifier はテキスト生成モデルでドキュメントのカテゴリと自由なタグを選ぶ分類器
+// 構造化データ抽出と同じ仕組みで、カテゴリを列挙型とするスキーマに従う出力を得る
+type LLMClassifier struct {
+	extractService ExtractServiceInterface
+	taxonomy       *Taxonomy
+	schema         json.RawMessage
+	model          string
+	maxTags        int
+	sampleChars    int
+}
+
+// NewLLMClassifier は新しいLLMClassifierを作成する
+func NewLLMClassifier(extractService ExtractServiceInterface, taxonomy *Taxonomy, cfg config.ClassifyConfig) (*LLMClassifier, error) {
+	var categories strings.Builder
+	names := make([]any, 0, len(taxonomy.Categories)+1)
+	for _, c := range taxonomy.Categories {
+		names = append(names, c.Name)
+		fmt.Fprintf(&categories, "\n- %s: %s", c.Name, c.Description)
+	}
+	names = append(names, "")
+
+	tags := map[string]any{
+		"type":        "array",
+		"items":       map[string]any{"type": "string", "maxLength": maxTagLength},
+		"description": "ドキュメントの主題・対象・部署などを表す短いキーワード (ドキュメントの言語で記述する)",
+	}
+	if cfg.MaxTags > 0 {
+		tags["maxItems"] = cfg.MaxTags
+	}
+	schema, err := json.Marshal(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"category": map[string]any{
+				"type":        "string",
+				"enum":        names,
+				"description": "ドキュメントに最も当てはまるカテゴリ (どれにも当てはまらない場合は空文字)" + categories.String(),
+			},
+			"tags": tags,
+		},
+		"required":             []string{"category", "tags"},
+		"additionalProperties": false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("分類のスキーマの作成に失敗しました: %w", err)
+	}
+
+	return &LLMClassifier{
+		extractService: extractService,
+		taxonomy:       taxonomy,
+		schema:         schema,
+		model:          cfg.Model,
+		maxTags:        cfg.MaxTags,
+		sampleChars:    cfg.SampleChars,
+	}, nil
+}
+
+// Classify はドキュメントのテキストの先頭からカテゴリとタグを選ぶ
+func (c *LLMClassifier) Classify(ctx context.Context, text string) (*Classification, error) {
+	result, err := c.extractService.Extract(ctx, ExtractRequest{Text: classifySample(text, c.sampleChars), Schema: c.schema, Model: c.model})
+	if err != nil {
+		return nil, fmt.Errorf("ドキュメントの分類に失敗しました: %w", err)
+	}
+	var output Classification
+	if err := json.Unmarshal(result.Data, &output); err != nil {
+		return nil, fmt.Errorf("分類結果の解析に失敗しました: %w", err)
+	}
+	if !c.taxonomy.Has(output.Category) {
+		output.Category = ""
+	}
+	output.Tags = cleanTags(output.Tags, c.maxTags)
+	return &output, nil
+}
+
+// CentroidClassifier はカテゴリごとの説明と例文のEmbeddingの重心に最も近いカテゴリを選ぶ分類器
+// テキスト生成モデルを呼び出さないため安価だが、タグは付けない
+type CentroidClassifier struct {
+	bedrockClient aws.BedrockClientInterface
+	taxonomy      *Taxonomy
+	minScore      float64
+	sampleChars   int
+
+	mu        sync.Mutex
+	centroids [][]float32 // taxonomy.Categories と同じ順序 (最初の分類時に計算する)
+}
+
+// NewCentroidClassifier は新しいCentroidClassifierを作成する
+func NewCentroidClassifier(bedrockClient aws.BedrockClientInterface, taxonomy *Taxonomy, cfg config.ClassifyConfig) *CentroidClassifier {
+	return &CentroidClassifier{
+		bedrockClient: bedrockClient,
+		taxonomy:      taxonomy,
+		minScore:      cfg.MinScore,
+		sampleChars:   cfg.SampleChars,
+	}
+}
+
+// Classify はドキュメントのテキストの先頭のEmbeddingと最も類似したカテゴリを選ぶ
+// 類似度が MinScore に満たない場合は未分類とする
+func (c *CentroidClassifier) Classify(ctx context.Context, text string) (*Classification, error) {
+	centroids, err := c.loadCentroids(ctx)
+	if err != nil {
+		return nil, err
+	}
+	embedding, err := c.bedrockClient.GenerateEmbedding(ctx, classifySample(text, c.sampleChars))
+	if err != nil {
+		return nil, fmt.Errorf("ドキュメントのEmbedding生成に失敗しました: %w", err)
+	}
+
+	best, bestScore := -1, c.minScore
+	for i, centroid := range centroids {
+		if score := cosineSimilarity(embedding, centroid); score >= bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return &Classification{}, nil
+	}
+	return &Classification{Category: c.taxonomy.Categories[best].Name}, nil
+}
+
+// loadCentroids はカテゴリごとの重心を返す (最初の呼び出しで計算し、以降は再利用する)
+func (c *CentroidClassifier) loadCentroids(ctx context.Context) ([][]float32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.centroids != nil {
+		return c.centroids, nil
+	}
+
+	// カテゴリの説明と例文をまとめてEmbeddingを生成し、カテゴリごとに平均する
+	var texts []string
+	var owners []int
+	for i, category := range c.taxonomy.Categories {
+		label := category.Name
+		if category.Description != "" {
+			label += ": " + category.Description
+		}
+		texts = append(texts, label)
+		owners = append(owners, i)
+		for _, example := range category.Examples {
+			texts = append(texts, example)
+			owners = append(owners, i)
+		}
+	}
+	embeddings, err := c.bedrockClient.GenerateEmbeddings(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("カテゴリのEmbedding生成に失敗しました: %w", err)
+	}
+	if len(embeddings) != len(texts) {
+		return nil, fmt.Errorf("カテゴリのEmbedding生成結果の件数が一致しません (入力: %d, 出力: %d)", len(texts), len(embeddings))
+	}
+
+	centroids := make([][]float32, len(c.taxonomy.Categories))
+	counts := make([]int, len(c.taxonomy.Categories))
+	for j, embedding := range embeddings {
+		i := owners[j]
+		if centroids[i] == nil {
+			centroids[i] = make([]float32, len(embedding))
+		}
+		for k := range embedding {
+			centroids[i][k] += embedding[k]
+		}
+		counts[i]++
+	}
+	for i := range centroids {
+		for k := range centroids[i] {
+			centroids[i][k] /= float32(counts[i])
+		}
+	}
+	c.centroids = centroids
+	return centroids, nil
+}
+
+// classifySample は分類に使用するテキストの先頭 (文字単位) を返す
+func classifySample(text string, limit int) string {
+	text = strings.TrimSpace(text)
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit])
+}
+
+// cosineSimilarity は2つのベクトルのコサイン類似度を返す (次元が異なる場合やゼロベクトルの場合は0)
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// normalizeTag はタグの前後の空白を除き、英字を小文字にそろえる
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// NormalizeTags はAPIで指定されたタグを正規化し、重複を除く
+// 空のタグ、長すぎるタグ、上限を超える数のタグは ErrInvalidTags とする
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" {
+			return nil, fmt.Errorf("%w: 空のタグは指定できません", ErrInvalidTags)
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("%w: タグは %d 文字以内で指定してください (%s)", ErrInvalidTags, maxTagLength, tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > maxDocumentTags {
+		return nil, fmt.Errorf("%w: タグは %d 個まで指定できます", ErrInvalidTags, maxDocumentTags)
+	}
+	return normalized, nil
+}
+
+// cleanTags は分類器が選んだタグを正規化し、使えないタグを除いて上限までに切り詰める
+func cleanTags(tags []string, limit int) []string {
+	if limit <= 0 || limit > maxDocumentTags {
+		limit = maxDocumentTags
+	}
+	cleaned := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		cleaned = append(cleaned, tag)
+		if len(cleaned) == limit {
+			break
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+)
+
+// DocumentClassifier は取り込むドキュメントのカテゴリとタグを選ぶ分類器のインターフェース
+type DocumentClassifier interface {
+	Classify(ctx context.Context, text string) (*Classification, error)
+}
+
+// インターフェースを実装していることを静的にチェック
+var (
+	_ DocumentClassifier = (*LLMClassifier)(nil)
+	_ DocumentClassifier = (*CentroidClassifier)(nil)
+)
@@ -0,0 +1,231 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/services"
+	"bedrock-rag-sample/backend/internal/services/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTaxonomy(t *testing.T) {
+	t.Run("正常系: 既定のカテゴリに説明を付ける", func(t *testing.T) {
+		taxonomy, err := services.LoadTaxonomy(config.ClassifyConfig{Categories: []string{"contract", "invoice", "faq"}})
+
+		require.NoError(t, err)
+		require.Len(t, taxonomy.Categories, 3)
+		assert.Equal(t, "contract", taxonomy.Categories[0].Name)
+		assert.NotEmpty(t, taxonomy.Categories[0].Description)
+		assert.Empty(t, taxonomy.Categories[2].Description)
+		assert.True(t, taxonomy.Has("faq"))
+		assert.False(t, taxonomy.Has("manual"))
+	})
+
+	t.Run("正常系: ファイルの定義を優先する", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "taxonomy.json")
+		require.NoError(t, os.WriteFile(path, []byte(`[{"name": "nda", "description": "秘密保持契約", "examples": ["秘密情報を第三者に開示しない"]}]`), 0o600))
+
+		taxonomy, err := services.LoadTaxonomy(config.ClassifyConfig{Categories: []string{"contract"}, TaxonomyFile: path})
+
+		require.NoError(t, err)
+		require.Len(t, taxonomy.Categories, 1)
+		assert.Equal(t, "nda", taxonomy.Categories[0].Name)
+		assert.Equal(t, []string{"秘密情報を第三者に開示しない"}, taxonomy.Categories[0].Examples)
+	})
+
+	t.Run("異常系: カテゴリが重複している", func(t *testing.T) {
+		_, err := services.LoadTaxonomy(config.ClassifyConfig{Categories: []string{"contract", " contract"}})
+
+		assert.ErrorIs(t, err, services.ErrInvalidTaxonomy)
+	})
+
+	t.Run("異常系: ファイルがJSONではない", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "taxonomy.json")
+		require.NoError(t, os.WriteFile(path, []byte("contract,invoice"), 0o600))
+
+		_, err := services.LoadTaxonomy(config.ClassifyConfig{TaxonomyFile: path})
+
+		assert.ErrorIs(t, err, services.ErrInvalidTaxonomy)
+	})
+}
+
+func TestNewDocumentClassifier(t *testing.T) {
+	taxonomy := &services.Taxonomy{Categories: []services.TaxonomyCategory{{Name: "contract"}}}
+
+	t.Run("正常系: 方式を指定しない場合は分類しない", func(t *testing.T) {
+		classifier, err := services.NewDocumentClassifier(config.ClassifyConfig{}, taxonomy, nil, nil)
+
+		require.NoError(t, err)
+		assert.Nil(t, classifier)
+	})
+
+	t.Run("異常系: 不明な方式", func(t *testing.T) {
+		_, err := services.NewDocumentClassifier(config.ClassifyConfig{Mode: "keyword"}, taxonomy, nil, nil)
+
+		assert.ErrorIs(t, err, services.ErrInvalidClassifyMode)
+	})
+}
+
+func TestLLMClassifier_Classify(t *testing.T) {
+	ctx := context.Background()
+	taxonomy := &services.Taxonomy{Categories: []services.TaxonomyCategory{
+		{Name: "contract", Description: "契約書"},
+		{Name: "invoice", Description: "請求書"},
+	}}
+	cfg := config.ClassifyConfig{Mode: services.ClassifyModeLLM, Model: "claude-3-haiku", MaxTags: 2, SampleChars: 5}
+
+	t.Run("正常系: スキーマでカテゴリを制約し、タグを正規化する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExtractService := mocks.NewMockExtractServiceInterface(ctrl)
+
+		mockExtractService.EXPECT().Extract(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, req services.ExtractRequest) (*services.ExtractResult, error) {
+			assert.Equal(t, "請求書番号", req.Text)
+			assert.Equal(t, "claude-3-haiku", req.Model)
+			var schema struct {
+				Properties struct {
+					Category struct {
+						Enum []string `json:"enum"`
+					} `json:"category"`
+					Tags struct {
+						MaxItems int `json:"maxItems"`
+					} `json:"tags"`
+				} `json:"properties"`
+			}
+			require.NoError(t, json.Unmarshal(req.Schema, &schema))
+			assert.Equal(t, []string{"contract", "invoice", ""}, schema.Properties.Category.Enum)
+			assert.Equal(t, 2, schema.Properties.Tags.MaxItems)
+			return &services.ExtractResult{Data: json.RawMessage(`{"category": "invoice", "tags": [" AWS ", "aws", "", "経理", "支払い"]}`)}, nil
+		})
+
+		classifier, err := services.NewLLMClassifier(mockExtractService, taxonomy, cfg)
+		require.NoError(t, err)
+		result, err := classifier.Classify(ctx, "  請求書番号: INV-001")
+
+		require.NoError(t, err)
+		assert.Equal(t, "invoice", result.Category)
+		assert.Equal(t, []string{"aws", "経理"}, result.Tags)
+	})
+
+	t.Run("正常系: 該当するカテゴリがない場合は未分類とする", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExtractService := mocks.NewMockExtractServiceInterface(ctrl)
+
+		mockExtractService.EXPECT().Extract(gomock.Any(), gomock.Any()).
+			Return(&services.ExtractResult{Data: json.RawMessage(`{"category": "", "tags": []}`)}, nil)
+
+		classifier, err := services.NewLLMClassifier(mockExtractService, taxonomy, cfg)
+		require.NoError(t, err)
+		result, err := classifier.Classify(ctx, "議事録")
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Category)
+		assert.Empty(t, result.Tags)
+	})
+
+	t.Run("異常系: 抽出エラー", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExtractService := mocks.NewMockExtractServiceInterface(ctrl)
+
+		mockExtractService.EXPECT().Extract(gomock.Any(), gomock.Any()).Return(nil, services.ErrExtractionInvalid)
+
+		classifier, err := services.NewLLMClassifier(mockExtractService, taxonomy, cfg)
+		require.NoError(t, err)
+		_, err = classifier.Classify(ctx, "議事録")
+
+		assert.ErrorIs(t, err, services.ErrExtractionInvalid)
+	})
+}
+
+func TestCentroidClassifier_Classify(t *testing.T) {
+	ctx := context.Background()
+	taxonomy := &services.Taxonomy{Categories: []services.TaxonomyCategory{
+		{Name: "contract", Description: "契約書", Examples: []string{"甲と乙は以下のとおり合意する"}},
+		{Name: "invoice", Description: "請求書"},
+	}}
+	cfg := config.ClassifyConfig{Mode: services.ClassifyModeCentroid, MinScore: 0.5}
+
+	t.Run("正常系: 最も近い重心のカテゴリを選び、重心は再利用する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := mocks.NewMockBedrockClientInterface(ctrl)
+
+		mockBedrockClient.EXPECT().GenerateEmbeddings(gomock.Any(), []string{"contract: 契約書", "甲と乙は以下のとおり合意する", "invoice: 請求書"}).
+			Return([][]float32{{1, 0}, {0.8, 0.2}, {0, 1}}, nil).Times(1)
+		mockBedrockClient.EXPECT().GenerateEmbedding(gomock.Any(), "業務委託契約書").Return([]float32{0.9, 0.1}, nil)
+		mockBedrockClient.EXPECT().GenerateEmbedding(gomock.Any(), "請求書").Return([]float32{0.1, 0.9}, nil)
+
+		classifier := services.NewCentroidClassifier(mockBedrockClient, taxonomy, cfg)
+		first, err := classifier.Classify(ctx, "業務委託契約書")
+		require.NoError(t, err)
+		second, err := classifier.Classify(ctx, "請求書")
+		require.NoError(t, err)
+
+		assert.Equal(t, "contract", first.Category)
+		assert.Empty(t, first.Tags)
+		assert.Equal(t, "invoice", second.Category)
+	})
+
+	t.Run("正常系: 類似度が下限に満たない場合は未分類とする", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := mocks.NewMockBedrockClientInterface(ctrl)
+
+		mockBedrockClient.EXPECT().GenerateEmbeddings(gomock.Any(), gomock.Any()).Return([][]float32{{1, 0}, {1, 0}, {0, 1}}, nil)
+		mockBedrockClient.EXPECT().GenerateEmbedding(gomock.Any(), gomock.Any()).Return([]float32{-1, -1}, nil)
+
+		result, err := services.NewCentroidClassifier(mockBedrockClient, taxonomy, cfg).Classify(ctx, "議事録")
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Category)
+	})
+
+	t.Run("異常系: カテゴリのEmbedding生成エラー", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := mocks.NewMockBedrockClientInterface(ctrl)
+
+		mockBedrockClient.EXPECT().GenerateEmbeddings(gomock.Any(), gomock.Any()).Return(nil, errors.New("ThrottlingException"))
+
+		_, err := services.NewCentroidClassifier(mockBedrockClient, taxonomy, cfg).Classify(ctx, "議事録")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestNormalizeTags(t *testing.T) {
+	t.Run("正常系: 空白と大文字小文字をそろえて重複を除く", func(t *testing.T) {
+		tags, err := services.NormalizeTags([]string{" AWS ", "aws", "人事  評価"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"aws", "人事 評価"}, tags)
+	})
+
+	t.Run("異常系: 空のタグ", func(t *testing.T) {
+		_, err := services.NormalizeTags([]string{"aws", " "})
+
+		assert.ErrorIs(t, err, services.ErrInvalidTags)
+	})
+
+	t.Run("異常系: タグが多すぎる", func(t *testing.T) {
+		tags := make([]string, 21)
+		for i := range tags {
+			tags[i] = string(rune('a' + i))
+		}
+
+		_, err := services.NormalizeTags(tags)
+
+		assert.ErrorIs(t, err, services.ErrInvalidTags)
+	})
+}
@@ -71,7 +71,7 @@ func (s *DocumentService) ProcessDocument(ctx context.Context, file *multipart.F
 	// テキストが短い場合は要約を省略
 	if len(extractResult.Text) > 200 {
 		// 要約サービスを使用してテキスト要約
-		summaryResult, err := s.summarizeService.SummarizeText(ctx, extractResult.Text)
+		summaryResult, err := s.summarizeService.SummarizeText(ctx, extractResult.Text, "")
 		if err == nil && summaryResult.Summary != "" {
 			result.Summary = summaryResult.Summary
 		}
@@ -82,25 +82,11 @@ func (s *DocumentService) ProcessDocument(ctx context.Context, file *multipart.F
 
 // ProcessDocumentByS3Key はS3キーで指定されたドキュメントを処理する (新規追加)
 func (s *DocumentService) ProcessDocumentByS3Key(ctx context.Context, s3Key string) (*DocumentProcessResult, error) {
-	// ファイル拡張子を確認
-	ext := strings.ToLower(filepath.Ext(s3Key))
-	// filename := filepath.Base(s3Key) // 不要なので削除
-
-	// サポートされる形式を確認
-	if ext != ".pdf" && ext != ".jpg" && ext != ".jpeg" && ext != ".png" && ext != ".tiff" {
-		return nil, fmt.Errorf("サポートされていないファイル形式です: %s", ext)
-	}
-
-	// Textractを使用してテキスト抽出 (S3キー版を呼び出す)
-	extractResult, err := s.textractClient.ExtractTextFromS3Key(ctx, s3Key) // ExtractTextFromS3Document -> ExtractTextFromS3Key に修正
+	extractResult, err := s.ExtractTextByS3Key(ctx, s3Key)
 	if err != nil {
-		return nil, fmt.Errorf("テキスト抽出に失敗しました (key: %s): %w", s3Key, err)
-	}
-
-	// 抽出されたテキストが空でないか確認
-	if extractResult.Text == "" {
-		return nil, fmt.Errorf("ドキュメントからテキストを抽出できませんでした (key: %s)", s3Key)
+		return nil, err
 	}
+	ext := strings.ToLower(filepath.Ext(s3Key))
 
 	// 結果オブジェクトを作成
 	result := &DocumentProcessResult{
@@ -113,7 +99,7 @@ func (s *DocumentService) ProcessDocumentByS3Key(ctx context.Context, s3Key stri
 	// テキストが短い場合は要約を省略
 	if len(extractResult.Text) > 200 {
 		// 要約サービスを使用してテキスト要約
-		summaryResult, err := s.summarizeService.SummarizeText(ctx, extractResult.Text)
+		summaryResult, err := s.summarizeService.SummarizeText(ctx, extractResult.Text, "")
 		if err == nil && summaryResult.Summary != "" {
 			result.Summary = summaryResult.Summary
 		}
@@ -121,3 +107,27 @@ func (s *DocumentService) ProcessDocumentByS3Key(ctx context.Context, s3Key stri
 
 	return result, nil
 }
+
+// ExtractTextByS3Key はS3キーで指定されたドキュメントからTextractでテキストを抽出する (要約は行わない)
+// 対応していない形式の場合は ErrUnsupportedFileType、テキストがない場合は ErrNoExtractableText を返す
+func (s *DocumentService) ExtractTextByS3Key(ctx context.Context, s3Key string) (*aws.TextractResult, error) {
+	// ファイル拡張子を確認
+	ext := strings.ToLower(filepath.Ext(s3Key))
+
+	// サポートされる形式を確認
+	if ext != ".pdf" && ext != ".jpg" && ext != ".jpeg" && ext != ".png" && ext != ".tiff" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFileType, ext)
+	}
+
+	// Textractを使用してテキスト抽出 (S3キー版を呼び出す)
+	extractResult, err := s.textractClient.ExtractTextFromS3Key(ctx, s3Key)
+	if err != nil {
+		return nil, fmt.Errorf("テキスト抽出に失敗しました (key: %s): %w", s3Key, err)
+	}
+
+	// 抽出されたテキストが空でないか確認
+	if extractResult.Text == "" {
+		return nil, fmt.Errorf("%w (key: %s)", ErrNoExtractableText, s3Key)
+	}
+	return extractResult, nil
+}
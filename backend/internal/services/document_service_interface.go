@@ -3,12 +3,15 @@ package services
 import (
 	"context"
 	"mime/multipart"
+
+	"bedrock-rag-sample/backend/pkg/aws"
 )
 
 // DocumentServiceInterface はドキュメント処理サービスのインターフェース
 type DocumentServiceInterface interface {
 	ProcessDocument(ctx context.Context, file *multipart.FileHeader) (*DocumentProcessResult, error)
 	ProcessDocumentByS3Key(ctx context.Context, s3Key string) (*DocumentProcessResult, error)
+	ExtractTextByS3Key(ctx context.Context, s3Key string) (*aws.TextractResult, error)
 	// 他の DocumentService メソッドが必要であればここに追加
 }
 
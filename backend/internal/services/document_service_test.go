@@ -38,8 +38,8 @@ func newSummarizeServiceAdapter(mockService *servicemocks.MockSummarizeServiceIn
 }
 
 // SummarizeText はモックサービスに委譲する (型変換は不要)
-func (a *summarizeServiceAdapter) SummarizeText(ctx context.Context, text string) (*services.SummarizeResult, error) {
-	return a.mockService.SummarizeText(ctx, text) // モックが *services.SummarizeResult を返す想定
+func (a *summarizeServiceAdapter) SummarizeText(ctx context.Context, text, model string) (*services.SummarizeResult, error) {
+	return a.mockService.SummarizeText(ctx, text, model) // モックが *services.SummarizeResult を返す想定
 }
 
 // SummarizeFile はインターフェースを満たすためのダミー実装 (新しいシグネチャ)
@@ -49,8 +49,8 @@ func (a *summarizeServiceAdapter) SummarizeFile(ctx context.Context, fileContent
 }
 
 // SummarizeFileByS3Key はモックサービスに委譲する (型変換は不要)
-func (a *summarizeServiceAdapter) SummarizeFileByS3Key(ctx context.Context, s3Key string) (*services.SummarizeResult, error) {
-	return a.mockService.SummarizeFileByS3Key(ctx, s3Key) // モックが *services.SummarizeResult を返す想定
+func (a *summarizeServiceAdapter) SummarizeFileByS3Key(ctx context.Context, s3Key, model string) (*services.SummarizeResult, error) {
+	return a.mockService.SummarizeFileByS3Key(ctx, s3Key, model) // モックが *services.SummarizeResult を返す想定
 }
 
 func TestProcessDocument(t *testing.T) {
@@ -211,7 +211,7 @@ func TestProcessDocument(t *testing.T) {
 
 			if tc.shouldCallSummary && tc.textractResult != nil {
 				mockSummarize.EXPECT().
-					SummarizeText(ctx, tc.textractResult.Text).
+					SummarizeText(ctx, tc.textractResult.Text, "").
 					Return(tc.summarizeResult, tc.summarizeErr).
 					MaxTimes(1)
 			}
@@ -326,7 +326,7 @@ func TestProcessDocumentByS3Key(t *testing.T) {
 
 			if tc.shouldCallSummary && tc.textractResult != nil {
 				mockSummarize.EXPECT().
-					SummarizeText(ctx, tc.textractResult.Text).
+					SummarizeText(ctx, tc.textractResult.Text, "").
 					Return(tc.summarizeResult, tc.summarizeErr).
 					MaxTimes(1)
 			}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"bedrock-rag-sample/backend/internal/domain"
+)
+
+// DocumentTagService はドキュメントのカテゴリとタグを編集するサービス
+// 分類は版ごとに保存されるため、指定したIDの版だけを更新する
+type DocumentTagService struct {
+	dbHandler domain.DBHandlerInterface
+	taxonomy  *Taxonomy
+}
+
+// NewDocumentTagService は新しいDocumentTagServiceを作成する
+func NewDocumentTagService(dbHandler domain.DBHandlerInterface, taxonomy *Taxonomy) *DocumentTagService {
+	return &DocumentTagService{
+		dbHandler: dbHandler,
+		taxonomy:  taxonomy,
+	}
+}
+
+// UpdateClassification はドキュメントのカテゴリとタグを置き換え、更新後のドキュメントを返す
+// category が空の場合は未分類とし、tags が空の場合はタグをすべて外す
+func (s *DocumentTagService) UpdateClassification(ctx context.Context, documentID int64, category string, tags []string) (*domain.Document, error) {
+	category = strings.TrimSpace(category)
+	if category != "" && !s.taxonomy.Has(category) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCategory, category)
+	}
+	normalized, err := NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.dbHandler.UpdateDocumentClassification(ctx, documentID, category, normalized); err != nil {
+		return nil, fmt.Errorf("分類の更新に失敗しました: %w", err)
+	}
+	doc, err := s.dbHandler.GetDocumentByID(ctx, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("ドキュメントの取得に失敗しました: %w", err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+
+	"bedrock-rag-sample/backend/internal/domain"
+)
+
+// DocumentTagServiceInterface はドキュメントの分類を編集するサービスのインターフェース
+type DocumentTagServiceInterface interface {
+	UpdateClassification(ctx context.Context, documentID int64, category string, tags []string) (*domain.Document, error)
+}
+
+// インターフェースを実装していることを静的にチェック
+var _ DocumentTagServiceInterface = (*DocumentTagService)(nil)
@@ -0,0 +1,70 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	domainmocks "bedrock-rag-sample/backend/internal/domain/mocks"
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentTagService_UpdateClassification(t *testing.T) {
+	ctx := context.Background()
+	taxonomy := &services.Taxonomy{Categories: []services.TaxonomyCategory{{Name: "contract"}, {Name: "policy"}}}
+
+	t.Run("正常系: 正規化したタグで更新し、更新後のドキュメントを返す", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+
+		mockDBHandler.EXPECT().UpdateDocumentClassification(gomock.Any(), int64(3), "policy", []string{"人事", "aws"}).Return(nil)
+		mockDBHandler.EXPECT().GetDocumentByID(gomock.Any(), int64(3)).
+			Return(&domain.Document{ID: 3, Category: "policy", Tags: []string{"人事", "aws"}}, nil)
+
+		doc, err := services.NewDocumentTagService(mockDBHandler, taxonomy).UpdateClassification(ctx, 3, " policy ", []string{"人事", "AWS", "aws"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "policy", doc.Category)
+		assert.Equal(t, []string{"人事", "aws"}, doc.Tags)
+	})
+
+	t.Run("正常系: 空のカテゴリで未分類に戻す", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+
+		mockDBHandler.EXPECT().UpdateDocumentClassification(gomock.Any(), int64(3), "", []string{}).Return(nil)
+		mockDBHandler.EXPECT().GetDocumentByID(gomock.Any(), int64(3)).Return(&domain.Document{ID: 3}, nil)
+
+		_, err := services.NewDocumentTagService(mockDBHandler, taxonomy).UpdateClassification(ctx, 3, "", nil)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("異常系: タクソノミーにないカテゴリ", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+
+		_, err := services.NewDocumentTagService(mockDBHandler, taxonomy).UpdateClassification(ctx, 3, "invoice", nil)
+
+		assert.ErrorIs(t, err, services.ErrUnknownCategory)
+	})
+
+	t.Run("異常系: ドキュメントが存在しない", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+
+		mockDBHandler.EXPECT().UpdateDocumentClassification(gomock.Any(), int64(99), "", []string{"aws"}).Return(domain.ErrDocumentNotFound)
+
+		_, err := services.NewDocumentTagService(mockDBHandler, taxonomy).UpdateClassification(ctx, 99, "", []string{"aws"})
+
+		assert.ErrorIs(t, err, domain.ErrDocumentNotFound)
+	})
+}
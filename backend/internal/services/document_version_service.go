@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"bedrock-rag-sample/backend/internal/domain"
+)
+
+// ErrInvalidVersionRange は差分を求める版の指定が不正な場合のエラー
+var ErrInvalidVersionRange = errors.New("比較する版の指定が不正です")
+
+// DocumentDiff は論理ドキュメントの2つの版から抽出したテキストの差分
+type DocumentDiff struct {
+	LogicalID   int64  `json:"logical_id"`
+	FromVersion int    `json:"from_version"`
+	ToVersion   int    `json:"to_version"`
+	Added       int    `json:"added"`   // 追加された行数
+	Removed     int    `json:"removed"` // 削除された行数
+	Unified     string `json:"unified"` // unified 形式の差分 (変更がない場合は空)
+}
+
+// DocumentVersionService はドキュメントの版の参照と版どうしの比較を行うサービス
+type DocumentVersionService struct {
+	dbHandler domain.DBHandlerInterface
+}
+
+// NewDocumentVersionService は新しいDocumentVersionServiceを作成する
+func NewDocumentVersionService(dbHandler domain.DBHandlerInterface) *DocumentVersionService {
+	return &DocumentVersionService{
+		dbHandler: dbHandler,
+	}
+}
+
+// ListVersions は論理ドキュメントの版を古い順に返す
+func (s *DocumentVersionService) ListVersions(ctx context.Context, logicalID int64) ([]domain.Document, error) {
+	versions, err := s.dbHandler.ListDocumentVersions(ctx, logicalID)
+	if err != nil {
+		return nil, fmt.Errorf("版の一覧の取得に失敗しました: %w", err)
+	}
+	return versions, nil
+}
+
+// GetVersion は論理ドキュメントの指定した版を抽出したテキストを含めて返す (version が 0 の場合は最新の版)
+func (s *DocumentVersionService) GetVersion(ctx context.Context, logicalID int64, version int) (*domain.Document, error) {
+	if version < 0 {
+		return nil, fmt.Errorf("%w: version %d", ErrInvalidVersionRange, version)
+	}
+	doc, err := s.dbHandler.GetDocumentVersion(ctx, logicalID, version)
+	if err != nil {
+		return nil, fmt.Errorf("版の取得に失敗しました: %w", err)
+	}
+	return doc, nil
+}
+
+// Diff は論理ドキュメントの from の版から to の版への、抽出したテキストの行単位の差分を返す
+// to が 0 の場合は最新の版、from が 0 の場合は to の1つ前の版と比較する
+func (s *DocumentVersionService) Diff(ctx context.Context, logicalID int64, from, to int) (*DocumentDiff, error) {
+	if from < 0 || to < 0 {
+		return nil, fmt.Errorf("%w: from %d, to %d", ErrInvalidVersionRange, from, to)
+	}
+	toDoc, err := s.GetVersion(ctx, logicalID, to)
+	if err != nil {
+		return nil, err
+	}
+	if from == 0 {
+		from = toDoc.Version - 1
+		if from < 1 {
+			return nil, fmt.Errorf("%w: 版 %d より前の版がありません", ErrInvalidVersionRange, toDoc.Version)
+		}
+	}
+	fromDoc, err := s.GetVersion(ctx, logicalID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	ops := diffLines(splitDiffLines(fromDoc.Content), splitDiffLines(toDoc.Content))
+	diff := &DocumentDiff{
+		LogicalID:   logicalID,
+		FromVersion: fromDoc.Version,
+		ToVersion:   toDoc.Version,
+		Unified: unifiedDiff(
+			fmt.Sprintf("v%d/%s", fromDoc.Version, fromDoc.Filename),
+			fmt.Sprintf("v%d/%s", toDoc.Version, toDoc.Filename),
+			ops,
+		),
+	}
+	for _, op := range ops {
+		switch op.kind {
+		case '+':
+			diff.Added++
+		case '-':
+			diff.Removed++
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+
+	"bedrock-rag-sample/backend/internal/domain"
+)
+
+// DocumentVersionServiceInterface はドキュメントの版に関するサービスのインターフェース
+type DocumentVersionServiceInterface interface {
+	ListVersions(ctx context.Context, logicalID int64) ([]domain.Document, error)
+	GetVersion(ctx context.Context, logicalID int64, version int) (*domain.Document, error)
+	Diff(ctx context.Context, logicalID int64, from, to int) (*DocumentDiff, error)
+}
+
+// インターフェースを実装していることを静的にチェック
+var _ DocumentVersionServiceInterface = (*DocumentVersionService)(nil)
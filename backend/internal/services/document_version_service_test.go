@@ -0,0 +1,69 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	domainmocks "bedrock-rag-sample/backend/internal/domain/mocks"
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentVersionService_Diff(t *testing.T) {
+	ctx := context.Background()
+	v1 := &domain.Document{ID: 1, LogicalID: 1, Version: 1, Filename: "leave.txt", Content: "申請は3営業日前まで\n承認は上長\n"}
+	v2 := &domain.Document{ID: 5, LogicalID: 1, Version: 2, Filename: "leave.txt", Content: "申請は5営業日前まで\n承認は上長\n", IsLatest: true}
+
+	setup := func(t *testing.T) (*services.DocumentVersionService, *domainmocks.MockDBHandlerInterface) {
+		ctrl := gomock.NewController(t)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		return services.NewDocumentVersionService(mockDBHandler), mockDBHandler
+	}
+
+	t.Run("正常系: 省略時は最新の版と1つ前の版を比較する", func(t *testing.T) {
+		svc, mockDBHandler := setup(t)
+		mockDBHandler.EXPECT().GetDocumentVersion(ctx, int64(1), 0).Return(v2, nil)
+		mockDBHandler.EXPECT().GetDocumentVersion(ctx, int64(1), 1).Return(v1, nil)
+
+		diff, err := svc.Diff(ctx, 1, 0, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, diff.FromVersion)
+		assert.Equal(t, 2, diff.ToVersion)
+		assert.Equal(t, 1, diff.Added)
+		assert.Equal(t, 1, diff.Removed)
+		assert.Equal(t, "--- v1/leave.txt\n+++ v2/leave.txt\n@@ -1,2 +1,2 @@\n-申請は3営業日前まで\n+申請は5営業日前まで\n 承認は上長\n", diff.Unified)
+	})
+
+	t.Run("異常系: 最初の版より前の版はない", func(t *testing.T) {
+		svc, mockDBHandler := setup(t)
+		mockDBHandler.EXPECT().GetDocumentVersion(ctx, int64(1), 1).Return(v1, nil)
+
+		_, err := svc.Diff(ctx, 1, 0, 1)
+
+		assert.ErrorIs(t, err, services.ErrInvalidVersionRange)
+	})
+
+	t.Run("異常系: 存在しない版", func(t *testing.T) {
+		svc, mockDBHandler := setup(t)
+		mockDBHandler.EXPECT().GetDocumentVersion(ctx, int64(1), 2).Return(v2, nil)
+		mockDBHandler.EXPECT().GetDocumentVersion(ctx, int64(1), 9).Return(nil, fmt.Errorf("%w with logical id 1 and version 9", domain.ErrDocumentNotFound))
+
+		_, err := svc.Diff(ctx, 1, 9, 2)
+
+		assert.ErrorIs(t, err, domain.ErrDocumentNotFound)
+	})
+
+	t.Run("異常系: 負の版", func(t *testing.T) {
+		svc, _ := setup(t)
+
+		_, err := svc.Diff(ctx, 1, -1, 2)
+
+		assert.ErrorIs(t, err, services.ErrInvalidVersionRange)
+	})
+}
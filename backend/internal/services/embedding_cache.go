@@ -0,0 +1,226 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/pkg/aws"
+
+	"github.com/rs/zerolog/log"
+)
+
+// EmbeddingCacheCounts はEmbeddingキャッシュの参照件数
+type EmbeddingCacheCounts struct {
+	Lookups    int64   `json:"lookups"` // 参照したテキスト数
+	MemoryHits int64   `json:"memory_hits"`
+	DBHits     int64   `json:"db_hits"`
+	Misses     int64   `json:"misses"`
+	HitRate    float64 `json:"hit_rate"` // (MemoryHits + DBHits) / Lookups
+}
+
+// EmbeddingCacheKeyStats はキャッシュの識別子 (モデル・入力の種類) ごとの参照件数
+type EmbeddingCacheKeyStats struct {
+	Key string `json:"key"`
+	EmbeddingCacheCounts
+}
+
+// EmbeddingCacheStats はプロセスの起動以降のEmbeddingキャッシュの利用状況
+type EmbeddingCacheStats struct {
+	Total          EmbeddingCacheCounts     `json:"total"`
+	Keys           []EmbeddingCacheKeyStats `json:"keys"`
+	MemoryEntries  int                      `json:"memory_entries"`
+	MemoryCapacity int                      `json:"memory_capacity"`
+	Persistent     bool                     `json:"persistent"` // DBにも保存しているかどうか
+}
+
+// EmbeddingCache はモデルと正規化したテキストのSHA-256をキーにしたEmbeddingのキャッシュ (aws.EmbeddingCache の実装)
+// DBに保存して再アップロードや再処理でも再利用し、その手前にメモリ上のLRUを置いて同じプロセス内の参照を速くする
+// dbHandler が nil の場合はメモリ上のみでキャッシュする
+type EmbeddingCache struct {
+	dbHandler domain.DBHandlerInterface
+	capacity  int
+
+	mu     sync.Mutex
+	lru    *list.List               // 先頭ほど最近使用したもの
+	items  map[string]*list.Element // cacheKey + "|" + テキストのハッシュ → lru の要素
+	counts map[string]*EmbeddingCacheCounts
+}
+
+// embeddingCacheItem はLRUの要素
+type embeddingCacheItem struct {
+	id        string
+	embedding []float32
+}
+
+// NewEmbeddingCache は新しいEmbeddingCacheを作成する
+func NewEmbeddingCache(dbHandler domain.DBHandlerInterface, memoryEntries int) *EmbeddingCache {
+	return &EmbeddingCache{
+		dbHandler: dbHandler,
+		capacity:  max(memoryEntries, 0),
+		lru:       list.New(),
+		items:     make(map[string]*list.Element),
+		counts:    make(map[string]*EmbeddingCacheCounts),
+	}
+}
+
+// インターフェースを実装していることを静的にチェック
+var _ aws.EmbeddingCache = (*EmbeddingCache)(nil)
+
+// embeddingTextHash は正規化したテキストのSHA-256を16進数で返す
+// 前後の空白や空白・改行の連続の違いだけのテキストは同じテキストとして扱う
+func embeddingTextHash(text string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(text), " ")))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetEmbeddings は texts と同じ順序でキャッシュ済みのEmbeddingを返す (見つからない位置は nil)
+// メモリ上にないものはまとめてDBから読み、メモリ上のキャッシュにも追加する
+func (c *EmbeddingCache) GetEmbeddings(ctx context.Context, key string, texts []string) [][]float32 {
+	results := make([][]float32, len(texts))
+	hashes := make([]string, len(texts))
+	var memoryHits, dbHits int64
+
+	c.mu.Lock()
+	var missing []string
+	for i, text := range texts {
+		hashes[i] = embeddingTextHash(text)
+		if embedding, ok := c.getLocked(key, hashes[i]); ok {
+			results[i] = embedding
+			memoryHits++
+		} else {
+			missing = append(missing, hashes[i])
+		}
+	}
+	c.mu.Unlock()
+
+	if len(missing) > 0 && c.dbHandler != nil {
+		found, err := c.dbHandler.GetCachedEmbeddings(ctx, key, missing)
+		if err != nil {
+			log.Error().Err(err).Str("cache_key", key).Msg("Failed to load cached embeddings")
+		}
+		if len(found) > 0 {
+			c.mu.Lock()
+			for i, hash := range hashes {
+				if embedding, ok := found[hash]; ok && results[i] == nil {
+					results[i] = embedding
+					c.putLocked(key, hash, embedding)
+					dbHits++
+				}
+			}
+			c.mu.Unlock()
+		}
+	}
+
+	c.mu.Lock()
+	counts := c.countsLocked(key)
+	counts.Lookups += int64(len(texts))
+	counts.MemoryHits += memoryHits
+	counts.DBHits += dbHits
+	counts.Misses += int64(len(texts)) - memoryHits - dbHits
+	c.mu.Unlock()
+	return results
+}
+
+// PutEmbeddings は生成したEmbeddingをメモリ上のキャッシュとDBに保存する
+// 保存に失敗しても次回に生成し直すだけのため、エラーは記録のみ行う
+func (c *EmbeddingCache) PutEmbeddings(ctx context.Context, key string, texts []string, embeddings [][]float32) {
+	entries := make([]domain.CachedEmbedding, 0, len(texts))
+	seen := make(map[string]struct{}, len(texts))
+
+	c.mu.Lock()
+	for i, text := range texts {
+		if i >= len(embeddings) || embeddings[i] == nil {
+			continue
+		}
+		hash := embeddingTextHash(text)
+		c.putLocked(key, hash, embeddings[i])
+		if _, ok := seen[hash]; !ok {
+			seen[hash] = struct{}{}
+			entries = append(entries, domain.CachedEmbedding{TextHash: hash, Embedding: embeddings[i]})
+		}
+	}
+	c.mu.Unlock()
+
+	if c.dbHandler == nil || len(entries) == 0 {
+		return
+	}
+	if err := c.dbHandler.SaveCachedEmbeddings(ctx, key, entries); err != nil {
+		log.Error().Err(err).Str("cache_key", key).Int("count", len(entries)).Msg("Failed to save cached embeddings")
+	}
+}
+
+// Stats はプロセスの起動以降のキャッシュの利用状況を返す
+func (c *EmbeddingCache) Stats() EmbeddingCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := EmbeddingCacheStats{
+		Keys:           make([]EmbeddingCacheKeyStats, 0, len(c.counts)),
+		MemoryEntries:  c.lru.Len(),
+		MemoryCapacity: c.capacity,
+		Persistent:     c.dbHandler != nil,
+	}
+	for key, counts := range c.counts {
+		stats.Keys = append(stats.Keys, EmbeddingCacheKeyStats{Key: key, EmbeddingCacheCounts: counts.withHitRate()})
+		stats.Total.Lookups += counts.Lookups
+		stats.Total.MemoryHits += counts.MemoryHits
+		stats.Total.DBHits += counts.DBHits
+		stats.Total.Misses += counts.Misses
+	}
+	sort.Slice(stats.Keys, func(i, j int) bool { return stats.Keys[i].Key < stats.Keys[j].Key })
+	stats.Total = stats.Total.withHitRate()
+	return stats
+}
+
+// withHitRate はヒット率を計算した参照件数を返す
+func (c EmbeddingCacheCounts) withHitRate() EmbeddingCacheCounts {
+	if c.Lookups > 0 {
+		c.HitRate = float64(c.MemoryHits+c.DBHits) / float64(c.Lookups)
+	}
+	return c
+}
+
+// getLocked はメモリ上のキャッシュからEmbeddingを取得し、最近使用したものとして先頭に移す
+func (c *EmbeddingCache) getLocked(key, hash string) ([]float32, bool) {
+	elem, ok := c.items[key+"|"+hash]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*embeddingCacheItem).embedding, true
+}
+
+// putLocked はメモリ上のキャッシュにEmbeddingを追加し、件数の上限を超えた分は最も古いものから破棄する
+func (c *EmbeddingCache) putLocked(key, hash string, embedding []float32) {
+	if c.capacity == 0 {
+		return
+	}
+	id := key + "|" + hash
+	if elem, ok := c.items[id]; ok {
+		elem.Value.(*embeddingCacheItem).embedding = embedding
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.items[id] = c.lru.PushFront(&embeddingCacheItem{id: id, embedding: embedding})
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.items, oldest.Value.(*embeddingCacheItem).id)
+	}
+}
+
+// countsLocked は key の参照件数を返す
+func (c *EmbeddingCache) countsLocked(key string) *EmbeddingCacheCounts {
+	counts, ok := c.counts[key]
+	if !ok {
+		counts = &EmbeddingCacheCounts{}
+		c.counts[key] = counts
+	}
+	return counts
+}
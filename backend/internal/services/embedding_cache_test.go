@@ -0,0 +1,101 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	domainmocks "bedrock-rag-sample/backend/internal/domain/mocks"
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestEmbeddingCache(t *testing.T) {
+	ctx := context.Background()
+	key := "titan-v2-256/document"
+
+	t.Run("保存したEmbeddingはメモリから返し、空白の違いは同じテキストとして扱う", func(t *testing.T) {
+		cache := services.NewEmbeddingCache(nil, 10)
+
+		cache.PutEmbeddings(ctx, key, []string{"社内 規程"}, [][]float32{{0.1, 0.2}})
+		results := cache.GetEmbeddings(ctx, key, []string{"  社内\n規程 ", "未登録"})
+
+		assert.Equal(t, [][]float32{{0.1, 0.2}, nil}, results)
+		assert.Nil(t, cache.GetEmbeddings(ctx, "titan-v2-256/query", []string{"社内 規程"})[0], "識別子が異なるキャッシュは共有しない")
+
+		stats := cache.Stats()
+		assert.Equal(t, services.EmbeddingCacheCounts{Lookups: 3, MemoryHits: 1, Misses: 2, HitRate: 1.0 / 3}, stats.Total)
+		require.Len(t, stats.Keys, 2)
+		assert.Equal(t, key, stats.Keys[0].Key)
+		assert.Equal(t, 0.5, stats.Keys[0].HitRate)
+		assert.False(t, stats.Persistent)
+	})
+
+	t.Run("メモリにないものはDBから読み、メモリにも追加する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		cache := services.NewEmbeddingCache(mockDBHandler, 10)
+
+		mockDBHandler.EXPECT().GetCachedEmbeddings(gomock.Any(), key, []string{textHash("a"), textHash("b")}).
+			Return(map[string][]float32{textHash("a"): {1, 1}}, nil).Times(1)
+		mockDBHandler.EXPECT().GetCachedEmbeddings(gomock.Any(), key, []string{textHash("b")}).
+			Return(map[string][]float32{}, nil).Times(1)
+
+		first := cache.GetEmbeddings(ctx, key, []string{"a", "b"})
+		second := cache.GetEmbeddings(ctx, key, []string{"a", "b"})
+
+		assert.Equal(t, [][]float32{{1, 1}, nil}, first)
+		assert.Equal(t, [][]float32{{1, 1}, nil}, second)
+		stats := cache.Stats()
+		assert.Equal(t, services.EmbeddingCacheCounts{Lookups: 4, MemoryHits: 1, DBHits: 1, Misses: 2, HitRate: 0.5}, stats.Total)
+		assert.True(t, stats.Persistent)
+	})
+
+	t.Run("生成したEmbeddingは重複を除いてDBに保存する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		cache := services.NewEmbeddingCache(mockDBHandler, 10)
+
+		mockDBHandler.EXPECT().SaveCachedEmbeddings(gomock.Any(), key, []domain.CachedEmbedding{
+			{TextHash: textHash("a"), Embedding: []float32{1, 1}},
+			{TextHash: textHash("b"), Embedding: []float32{2, 2}},
+		}).Return(nil).Times(1)
+
+		cache.PutEmbeddings(ctx, key, []string{"a", "b", "a"}, [][]float32{{1, 1}, {2, 2}, {1, 1}})
+	})
+
+	t.Run("DBのエラーはキャッシュなしとして扱う", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		cache := services.NewEmbeddingCache(mockDBHandler, 10)
+
+		mockDBHandler.EXPECT().GetCachedEmbeddings(gomock.Any(), key, gomock.Any()).Return(nil, errors.New("db error"))
+		mockDBHandler.EXPECT().SaveCachedEmbeddings(gomock.Any(), key, gomock.Any()).Return(errors.New("db error"))
+
+		assert.Equal(t, [][]float32{nil}, cache.GetEmbeddings(ctx, key, []string{"a"}))
+		cache.PutEmbeddings(ctx, key, []string{"a"}, [][]float32{{1, 1}})
+		assert.Equal(t, [][]float32{{1, 1}}, cache.GetEmbeddings(ctx, key, []string{"a"}), "メモリ上のキャッシュは利用できる")
+	})
+
+	t.Run("件数の上限を超えると最も古いものから破棄する", func(t *testing.T) {
+		cache := services.NewEmbeddingCache(nil, 2)
+
+		cache.PutEmbeddings(ctx, key, []string{"a", "b"}, [][]float32{{1}, {2}})
+		cache.GetEmbeddings(ctx, key, []string{"a"}) // a を最近使用したものにする
+		cache.PutEmbeddings(ctx, key, []string{"c"}, [][]float32{{3}})
+
+		assert.Equal(t, [][]float32{{1}, nil, {3}}, cache.GetEmbeddings(ctx, key, []string{"a", "b", "c"}))
+		assert.Equal(t, 2, cache.Stats().MemoryEntries)
+	})
+}
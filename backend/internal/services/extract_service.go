@@ -0,0 +1,353 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"bedrock-rag-sample/backend/pkg/aws"
+	"bedrock-rag-sample/backend/pkg/jsonschema"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrInvalidExtractRequest は構造化データ抽出のリクエストが不正な場合のエラー
+	ErrInvalidExtractRequest = errors.New("構造化データ抽出のリクエストが不正です")
+	// ErrExtractionInvalid は再試行してもモデルの出力がJSON Schemaに適合しなかった場合のエラー
+	ErrExtractionInvalid = errors.New("抽出結果がJSON Schemaに適合しませんでした")
+)
+
+const (
+	// extractMaxAttempts はモデルの出力がスキーマに適合しない場合に、検証エラーを伝えて生成し直す回数 (最初の生成を含む)
+	extractMaxAttempts = 3
+	// maxExtractTextLength は抽出の対象にできるテキストの最大文字数 (切り詰めると項目を取りこぼすため、超える場合はエラーとする)
+	maxExtractTextLength = 100000
+	// extractToolName はツールの利用に対応したモデルに抽出結果を記録させるツールの名前
+	extractToolName = "record_extraction"
+)
+
+// extractSystemPrompt は構造化データ抽出のシステムプロンプト
+const extractSystemPrompt = `あなたはドキュメントから指定された項目を抽出するアシスタントです。
+- ドキュメントに記載されている情報だけを抽出し、推測で値を補わないでください。
+- 記載がない項目は、スキーマが null を許す場合は null とし、必須ではない場合は省略してください。
+- data には抽出した値を、指定されたJSON Schemaに従って格納してください。
+- evidence には data の各値の根拠となるドキュメントの箇所を、一字一句変えずに引用してください。
+  path は data 内の値の位置を示すJSON Pointer (例: /vendor/name, /lines/0/amount) です。`
+
+// ExtractService はドキュメントのテキストから、呼び出し元が指定したJSON Schemaに従う構造化データを抽出するサービス
+// ツールの利用に対応したモデルでは、スキーマを入力とするツールを必ず呼び出させて出力の形式を制約する
+// 対応していないモデルでは、スキーマをプロンプトに含めてJSONのみを出力させる
+// 出力はスキーマで検証し、適合しない場合は検証エラーを伝えて生成し直す
+// 個人情報を除去した場合は除去した後のテキストから抽出し、根拠の箇所もそのテキストでの位置を返す
+type ExtractService struct {
+	bedrockClient   aws.BedrockClientInterface
+	documentService DocumentServiceInterface
+	models          *TextModelRouter
+	redactor        *PIIRedactor
+}
+
+// NewExtractService は新しいExtractServiceを作成する
+// models が nil の場合はモデルの指定を受け付けず、bedrockClient の既定モデルを使用する
+// redactor が nil の場合は抽出の対象のテキストから個人情報を除去しない
+func NewExtractService(bedrockClient aws.BedrockClientInterface, documentService DocumentServiceInterface, models *TextModelRouter, redactor *PIIRedactor) *ExtractService {
+	return &ExtractService{
+		bedrockClient:   bedrockClient,
+		documentService: documentService,
+		models:          models,
+		redactor:        redactor,
+	}
+}
+
+// ExtractRequest は構造化データ抽出のリクエスト
+type ExtractRequest struct {
+	Text   string          // 抽出の対象とするテキスト (S3Key と同時には指定できない)
+	S3Key  string          // 抽出の対象とするドキュメントのS3キー (Textractでテキストを抽出する)
+	Schema json.RawMessage // 抽出する項目のJSON Schema
+	Model  string          // 抽出に使用するモデル (空の場合は抽出エンドポイントの既定モデル)
+}
+
+// FieldSpan は抽出した値の根拠となるドキュメントの箇所
+type FieldSpan struct {
+	Path      string `json:"path"`                 // data 内の値の位置 (JSON Pointer)
+	Quote     string `json:"quote"`                // モデルが根拠として引用した箇所
+	Found     bool   `json:"found"`                // 引用をドキュメントのテキストから見つけられたかどうか
+	Start     int    `json:"start"`                // テキストの先頭からの開始位置 (文字単位)
+	End       int    `json:"end"`                  // テキストの先頭からの終了位置 (文字単位、この位置を含まない)
+	StartLine int    `json:"start_line,omitempty"` // 開始行 (1始まり)
+	EndLine   int    `json:"end_line,omitempty"`   // 終了行 (1始まり)
+}
+
+// ExtractResult は構造化データ抽出の結果
+type ExtractResult struct {
+	Data       json.RawMessage `json:"data"`  // JSON Schemaに適合する抽出結果
+	Spans      []FieldSpan     `json:"spans"` // 値ごとの根拠の箇所
+	SourceText string          `json:"source_text"`
+	Model      string          `json:"model,omitempty"` // 抽出に使用したモデルのカタログ名
+	Attempts   int             `json:"attempts"`        // 生成した回数
+}
+
+// extractionOutput はモデルに出力させる形式 (抽出結果と根拠の引用)
+type extractionOutput struct {
+	Data     json.RawMessage      `json:"data"`
+	Evidence []extractionEvidence `json:"evidence"`
+}
+
+type extractionEvidence struct {
+	Path  string `json:"path"`
+	Quote string `json:"quote"`
+}
+
+// Extract はテキストまたはS3のドキュメントから、JSON Schemaに従う構造化データを抽出する
+func (s *ExtractService) Extract(ctx context.Context, req ExtractRequest) (*ExtractResult, error) {
+	if (req.Text == "") == (req.S3Key == "") {
+		return nil, fmt.Errorf("%w: テキストとS3キーのどちらか一方を指定してください", ErrInvalidExtractRequest)
+	}
+	schema, err := jsonschema.Compile(req.Schema)
+	if err != nil {
+		return nil, err
+	}
+	client, modelName, err := textClientFor(s.bedrockClient, s.models, EndpointExtract, req.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	text := req.Text
+	if req.S3Key != "" {
+		extracted, err := s.documentService.ExtractTextByS3Key(ctx, req.S3Key)
+		if err != nil {
+			return nil, err
+		}
+		text = extracted.Text
+	}
+	if n := utf8.RuneCountInString(text); n > maxExtractTextLength {
+		return nil, fmt.Errorf("%w: テキストが長すぎます (%d 文字、上限 %d 文字)", ErrInvalidExtractRequest, n, maxExtractTextLength)
+	}
+	if text, err = s.redactor.RedactText(ctx, RedactEndpointExtract, text); err != nil {
+		return nil, err
+	}
+
+	outputSchema, err := json.Marshal(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"data": req.Schema,
+			"evidence": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"path":  map[string]any{"type": "string", "description": "data 内の値の位置 (JSON Pointer)"},
+						"quote": map[string]any{"type": "string", "description": "値の根拠となるドキュメントの箇所 (原文のまま)"},
+					},
+					"required": []string{"path", "quote"},
+				},
+			},
+		},
+		"required": []string{"data", "evidence"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	converseReq := aws.ConverseRequest{
+		System:      []string{extractSystemPrompt},
+		Temperature: new(float64),
+	}
+	useTools := client.TextModel().SupportsTools
+	prompt := "<document>\n" + text + "\n</document>\n\n"
+	if useTools {
+		converseReq.Tools = []aws.ConverseTool{{
+			Name:        extractToolName,
+			Description: "ドキュメントから抽出した値 (data) と、各値の根拠となる引用 (evidence) を記録する",
+			InputSchema: outputSchema,
+		}}
+		converseReq.ToolChoice = extractToolName
+		prompt += "ドキュメントから項目を抽出し、" + extractToolName + " ツールを呼び出して記録してください。"
+	} else {
+		prompt += "ドキュメントから項目を抽出し、次のJSON Schemaに従うJSONオブジェクトを出力してください。説明や前置きは含めないでください。\n<schema>\n" +
+			string(outputSchema) + "\n</schema>"
+	}
+	converseReq.Messages = []aws.ConverseMessage{aws.UserMessage(prompt)}
+
+	var problems []string
+	for attempt := 1; attempt <= extractMaxAttempts; attempt++ {
+		resp, err := client.Converse(ctx, converseReq)
+		if err != nil {
+			return nil, fmt.Errorf("構造化データの抽出に失敗しました: %w", err)
+		}
+
+		payload, toolUseID := extractionPayload(resp, useTools)
+		var output *extractionOutput
+		output, problems = checkExtraction(schema, payload)
+		if len(problems) == 0 {
+			return &ExtractResult{
+				Data:       output.Data,
+				Spans:      locateEvidence(text, output),
+				SourceText: text,
+				Model:      modelName,
+				Attempts:   attempt,
+			}, nil
+		}
+		if resp.StopReason == aws.StopReasonMaxTokens {
+			problems = append(problems, "出力が最大トークン数に達して途中で終わっています")
+		}
+		log.Warn().Int("attempt", attempt).Strs("problems", problems).Msg("Extraction output does not match the schema")
+
+		// 検証エラーを伝えて生成し直させる (出力が空の場合は、最初のプロンプトからやり直す)
+		if len(resp.Message.Content) == 0 {
+			continue
+		}
+		feedback := "出力がJSON Schemaに適合しません。次の問題を修正して、もう一度出力してください。\n- " + strings.Join(problems, "\n- ")
+		next := aws.UserMessage(feedback)
+		if toolUseID != "" {
+			next = aws.ToolResultMessage(aws.ConverseToolResult{ToolUseID: toolUseID, Text: feedback, IsError: true})
+		}
+		converseReq.Messages = append(converseReq.Messages, resp.Message, next)
+	}
+	return nil, fmt.Errorf("%w (%d 回生成): %s", ErrExtractionInvalid, extractMaxAttempts, strings.Join(problems, "; "))
+}
+
+// extractionPayload はモデルの出力から抽出結果のJSONを取り出す
+// ツールを利用した場合は、抽出結果を記録するツールの呼び出しの入力とその呼び出しのIDを返す
+func extractionPayload(resp *aws.ConverseResponse, useTools bool) ([]byte, string) {
+	if useTools {
+		for _, use := range resp.ToolUses() {
+			if use.Name == extractToolName {
+				return use.Input, use.ID
+			}
+		}
+	}
+	// コードブロックや前置きを含めて出力するモデルがあるため、最初の { から最後の } までを取り出す
+	text := resp.Text()
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, ""
+	}
+	return []byte(text[start : end+1]), ""
+}
+
+// checkExtraction はモデルの出力を解釈してJSON Schemaで検証し、問題があればその一覧を返す
+func checkExtraction(schema *jsonschema.Schema, payload []byte) (*extractionOutput, []string) {
+	if len(payload) == 0 {
+		return nil, []string{"抽出結果のJSONオブジェクトが出力されていません"}
+	}
+	var output extractionOutput
+	if err := json.Unmarshal(payload, &output); err != nil {
+		return nil, []string{fmt.Sprintf("出力を data と evidence のJSONオブジェクトとして解釈できません: %v", err)}
+	}
+	if len(output.Data) == 0 {
+		return nil, []string{"data がありません"}
+	}
+	errs, err := schema.Validate(output.Data)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("data をJSONとして解釈できません: %v", err)}
+	}
+	if len(errs) > 0 {
+		problems := make([]string, len(errs))
+		for i, e := range errs {
+			problems[i] = fmt.Sprintf("data%s: %s", e.Path, e.Message)
+		}
+		return nil, problems
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, output.Data); err == nil {
+		output.Data = compact.Bytes()
+	}
+	return &output, nil
+}
+
+// locateEvidence は根拠の引用をドキュメントのテキストから探し、値ごとの箇所を返す
+// data にない位置を指す引用は含めない
+func locateEvidence(text string, output *extractionOutput) []FieldSpan {
+	var data any
+	_ = json.Unmarshal(output.Data, &data)
+
+	spans := make([]FieldSpan, 0, len(output.Evidence))
+	for _, e := range output.Evidence {
+		if !pointerExists(data, e.Path) {
+			continue
+		}
+		span := FieldSpan{Path: e.Path, Quote: e.Quote}
+		if start, end, ok := locateQuote(text, e.Quote); ok {
+			span.Found = true
+			span.Start = utf8.RuneCountInString(text[:start])
+			span.End = span.Start + utf8.RuneCountInString(text[start:end])
+			span.StartLine = 1 + strings.Count(text[:start], "\n")
+			span.EndLine = span.StartLine + strings.Count(text[start:end], "\n")
+		}
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+// locateQuote は引用がテキストに現れる最初の位置 (バイト単位) を返す
+// 完全に一致しない場合は、空白と改行を無視して探す (Textractの抽出結果は原文と改行や空白の位置が異なることがあるため)
+func locateQuote(text, quote string) (int, int, bool) {
+	quote = strings.TrimSpace(quote)
+	if quote == "" {
+		return 0, 0, false
+	}
+	if i := strings.Index(text, quote); i >= 0 {
+		return i, i + len(quote), true
+	}
+
+	stripped, offsets := stripSpaces(text)
+	strippedQuote, _ := stripSpaces(quote)
+	i := strings.Index(stripped, strippedQuote)
+	if i < 0 {
+		return 0, 0, false
+	}
+	return offsets[i], offsets[i+len(strippedQuote)-1] + 1, true
+}
+
+// stripSpaces は空白文字を取り除いた文字列と、その各バイトの元の文字列での位置を返す
+func stripSpaces(s string) (string, []int) {
+	var sb strings.Builder
+	offsets := make([]int, 0, len(s))
+	for i, r := range s {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		n, _ := sb.WriteRune(r)
+		for j := range n {
+			offsets = append(offsets, i+j)
+		}
+	}
+	return sb.String(), offsets
+}
+
+// pointerExists はJSON Pointerが値の中の位置を指しているかどうかを返す
+func pointerExists(v any, pointer string) bool {
+	if pointer == "" {
+		return true
+	}
+	rest, ok := strings.CutPrefix(pointer, "/")
+	if !ok {
+		return false
+	}
+	for _, token := range strings.Split(rest, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := v.(type) {
+		case map[string]any:
+			if v, ok = node[token]; !ok {
+				return false
+			}
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) || strconv.Itoa(i) != token {
+				return false
+			}
+			v = node[i]
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,11 @@
+package services
+
+import "context"
+
+// ExtractServiceInterface は構造化データ抽出サービスのインターフェース
+type ExtractServiceInterface interface {
+	Extract(ctx context.Context, req ExtractRequest) (*ExtractResult, error)
+}
+
+// インターフェースを実装していることを静的にチェック
+var _ ExtractServiceInterface = (*ExtractService)(nil)
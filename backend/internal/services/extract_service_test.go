@@ -0,0 +1,274 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+	"bedrock-rag-sample/backend/pkg/aws"
+	"bedrock-rag-sample/backend/pkg/jsonschema"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const extractTestSchema = `{
+	"type": "object",
+	"properties": {
+		"invoice_number": {"type": "string"},
+		"total": {"type": "integer"}
+	},
+	"required": ["invoice_number", "total"]
+}`
+
+const extractTestText = "請求書\n請求番号: INV-001\nご請求金額 10,800円"
+
+// toolUseResponse は抽出結果を記録するツールを呼び出した応答を返す
+func toolUseResponse(id, input string) *aws.ConverseResponse {
+	return &aws.ConverseResponse{
+		Message: aws.ConverseMessage{Role: aws.ConverseRoleAssistant, Content: []aws.ConverseContent{
+			{ToolUse: &aws.ConverseToolUse{ID: id, Name: "record_extraction", Input: json.RawMessage(input)}},
+		}},
+		StopReason: aws.StopReasonToolUse,
+	}
+}
+
+func TestExtractService_Extract(t *testing.T) {
+	ctx := context.Background()
+	haiku, _ := aws.LookupTextModel("claude-3-haiku")
+	mistral, _ := aws.LookupTextModel("mistral-7b-instruct")
+	validOutput := `{"data": {"invoice_number": "INV-001", "total": 10800},
+		"evidence": [{"path": "/invoice_number", "quote": "INV-001"}, {"path": "/total", "quote": "ご請求金額 10,800円"}]}`
+
+	t.Run("正常系: ツールの呼び出しで抽出し、根拠の箇所を返す", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		extractService := services.NewExtractService(mockBedrockClient, nil, nil, nil)
+
+		mockBedrockClient.EXPECT().TextModel().Return(haiku)
+		mockBedrockClient.EXPECT().
+			Converse(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, req aws.ConverseRequest) (*aws.ConverseResponse, error) {
+				require.Len(t, req.Tools, 1)
+				assert.Equal(t, "record_extraction", req.ToolChoice)
+				assert.Contains(t, string(req.Tools[0].InputSchema), `"invoice_number"`)
+				assert.Contains(t, req.Messages[0].Content[0].Text, extractTestText)
+				return toolUseResponse("tool-1", validOutput), nil
+			})
+
+		result, err := extractService.Extract(ctx, services.ExtractRequest{Text: extractTestText, Schema: json.RawMessage(extractTestSchema)})
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"invoice_number": "INV-001", "total": 10800}`, string(result.Data))
+		assert.Equal(t, 1, result.Attempts)
+		assert.Equal(t, []services.FieldSpan{
+			{Path: "/invoice_number", Quote: "INV-001", Found: true, Start: 10, End: 17, StartLine: 2, EndLine: 2},
+			{Path: "/total", Quote: "ご請求金額 10,800円", Found: true, Start: 18, End: 31, StartLine: 3, EndLine: 3},
+		}, result.Spans)
+	})
+
+	t.Run("正常系: スキーマに適合しない場合は検証エラーを伝えて生成し直す", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		extractService := services.NewExtractService(mockBedrockClient, nil, nil, nil)
+
+		mockBedrockClient.EXPECT().TextModel().Return(haiku)
+		gomock.InOrder(
+			mockBedrockClient.EXPECT().Converse(ctx, gomock.Any()).
+				Return(toolUseResponse("tool-1", `{"data": {"invoice_number": "INV-001", "total": "10,800円"}, "evidence": []}`), nil),
+			mockBedrockClient.EXPECT().Converse(ctx, gomock.Any()).
+				DoAndReturn(func(_ context.Context, req aws.ConverseRequest) (*aws.ConverseResponse, error) {
+					require.Len(t, req.Messages, 3)
+					assert.Equal(t, aws.ConverseRoleAssistant, req.Messages[1].Role)
+					toolResult := req.Messages[2].Content[0].ToolResult
+					require.NotNil(t, toolResult)
+					assert.Equal(t, "tool-1", toolResult.ToolUseID)
+					assert.True(t, toolResult.IsError)
+					assert.Contains(t, toolResult.Text, "data/total: 型が integer ではありません (string)")
+					return toolUseResponse("tool-2", validOutput), nil
+				}),
+		)
+
+		result, err := extractService.Extract(ctx, services.ExtractRequest{Text: extractTestText, Schema: json.RawMessage(extractTestSchema)})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Attempts)
+		assert.JSONEq(t, `{"invoice_number": "INV-001", "total": 10800}`, string(result.Data))
+	})
+
+	t.Run("正常系: ツールに対応していないモデルはプロンプトでJSONを出力させる", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		extractService := services.NewExtractService(mockBedrockClient, nil, nil, nil)
+
+		mockBedrockClient.EXPECT().TextModel().Return(mistral)
+		mockBedrockClient.EXPECT().
+			Converse(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, req aws.ConverseRequest) (*aws.ConverseResponse, error) {
+				assert.Empty(t, req.Tools)
+				assert.Contains(t, req.Messages[0].Content[0].Text, "<schema>")
+				return &aws.ConverseResponse{Message: aws.ConverseMessage{Role: aws.ConverseRoleAssistant, Content: []aws.ConverseContent{
+					{Text: "抽出結果です。\n```json\n" + validOutput + "\n```"},
+				}}}, nil
+			})
+
+		result, err := extractService.Extract(ctx, services.ExtractRequest{Text: extractTestText, Schema: json.RawMessage(extractTestSchema)})
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"invoice_number": "INV-001", "total": 10800}`, string(result.Data))
+		assert.Len(t, result.Spans, 2)
+	})
+
+	t.Run("正常系: S3のドキュメントからTextractで抽出したテキストを使う", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockDocumentService := servicemocks.NewMockDocumentServiceInterface(ctrl)
+		extractService := services.NewExtractService(mockBedrockClient, mockDocumentService, nil, nil)
+
+		mockDocumentService.EXPECT().ExtractTextByS3Key(ctx, "documents/invoice.pdf").Return(&aws.TextractResult{Text: extractTestText}, nil)
+		mockBedrockClient.EXPECT().TextModel().Return(haiku)
+		mockBedrockClient.EXPECT().Converse(ctx, gomock.Any()).Return(toolUseResponse("tool-1", validOutput), nil)
+
+		result, err := extractService.Extract(ctx, services.ExtractRequest{S3Key: "documents/invoice.pdf", Schema: json.RawMessage(extractTestSchema)})
+
+		require.NoError(t, err)
+		assert.Equal(t, extractTestText, result.SourceText)
+	})
+
+	t.Run("正常系: 空白と改行の違いを無視して引用を探し、dataにない位置の引用は除く", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		extractService := services.NewExtractService(mockBedrockClient, nil, nil, nil)
+
+		mockBedrockClient.EXPECT().TextModel().Return(haiku)
+		mockBedrockClient.EXPECT().Converse(ctx, gomock.Any()).Return(toolUseResponse("tool-1", `{
+			"data": {"invoice_number": "INV-001", "total": 10800},
+			"evidence": [
+				{"path": "/invoice_number", "quote": "請求番号:INV-001 ご請求金額"},
+				{"path": "/total", "quote": "合計 10,800円"},
+				{"path": "/due_date", "quote": "請求書"}
+			]}`), nil)
+
+		result, err := extractService.Extract(ctx, services.ExtractRequest{Text: extractTestText, Schema: json.RawMessage(extractTestSchema)})
+
+		require.NoError(t, err)
+		assert.Equal(t, []services.FieldSpan{
+			{Path: "/invoice_number", Quote: "請求番号:INV-001 ご請求金額", Found: true, Start: 4, End: 23, StartLine: 2, EndLine: 3},
+			{Path: "/total", Quote: "合計 10,800円"},
+		}, result.Spans)
+	})
+
+	t.Run("異常系: 生成し直してもスキーマに適合しない", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		extractService := services.NewExtractService(mockBedrockClient, nil, nil, nil)
+
+		mockBedrockClient.EXPECT().TextModel().Return(haiku)
+		mockBedrockClient.EXPECT().Converse(ctx, gomock.Any()).
+			Return(toolUseResponse("tool-1", `{"data": {"invoice_number": "INV-001"}, "evidence": []}`), nil).
+			Times(3)
+
+		_, err := extractService.Extract(ctx, services.ExtractRequest{Text: extractTestText, Schema: json.RawMessage(extractTestSchema)})
+
+		assert.ErrorIs(t, err, services.ErrExtractionInvalid)
+		assert.Contains(t, err.Error(), "data/total: 必須のプロパティがありません")
+	})
+
+	t.Run("異常系: モデルの呼び出しに失敗", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		extractService := services.NewExtractService(mockBedrockClient, nil, nil, nil)
+
+		mockBedrockClient.EXPECT().TextModel().Return(haiku)
+		mockBedrockClient.EXPECT().Converse(ctx, gomock.Any()).Return(nil, aws.ErrModelBusy)
+
+		_, err := extractService.Extract(ctx, services.ExtractRequest{Text: extractTestText, Schema: json.RawMessage(extractTestSchema)})
+
+		assert.ErrorIs(t, err, aws.ErrModelBusy)
+	})
+
+	t.Run("正常系: 個人情報を除去したテキストから抽出する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		redactor, err := services.NewPIIRedactor(config.RedactConfig{Enabled: true, DefaultMode: "mask", Detectors: []string{"phone"}}, nil)
+		require.NoError(t, err)
+		extractService := services.NewExtractService(mockBedrockClient, nil, nil, redactor)
+
+		mockBedrockClient.EXPECT().TextModel().Return(haiku)
+		mockBedrockClient.EXPECT().
+			Converse(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, req aws.ConverseRequest) (*aws.ConverseResponse, error) {
+				assert.NotContains(t, req.Messages[0].Content[0].Text, "03-1234-5678")
+				return toolUseResponse("tool-1", validOutput), nil
+			})
+
+		result, err := extractService.Extract(ctx, services.ExtractRequest{Text: "TEL 03-1234-5678\n" + extractTestText, Schema: json.RawMessage(extractTestSchema)})
+
+		require.NoError(t, err)
+		assert.Equal(t, "TEL [PHONE]\n"+extractTestText, result.SourceText)
+		assert.Equal(t, 3, result.Spans[0].StartLine)
+	})
+
+	t.Run("異常系: ドキュメントからテキストを抽出できない", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDocumentService := servicemocks.NewMockDocumentServiceInterface(ctrl)
+		extractService := services.NewExtractService(servicemocks.NewMockBedrockClientInterface(ctrl), mockDocumentService, nil, nil)
+
+		mockDocumentService.EXPECT().ExtractTextByS3Key(ctx, "documents/blank.pdf").Return(nil, services.ErrNoExtractableText)
+
+		_, err := extractService.Extract(ctx, services.ExtractRequest{S3Key: "documents/blank.pdf", Schema: json.RawMessage(extractTestSchema)})
+
+		assert.ErrorIs(t, err, services.ErrNoExtractableText)
+	})
+
+	t.Run("異常系: リクエストが不正", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		extractService := services.NewExtractService(servicemocks.NewMockBedrockClientInterface(ctrl), nil, nil, nil)
+
+		tests := []struct {
+			name string
+			req  services.ExtractRequest
+			want error
+		}{
+			{
+				name: "テキストとS3キーの両方を指定",
+				req:  services.ExtractRequest{Text: "本文", S3Key: "documents/a.pdf", Schema: json.RawMessage(extractTestSchema)},
+				want: services.ErrInvalidExtractRequest,
+			},
+			{
+				name: "テキストもS3キーも指定しない",
+				req:  services.ExtractRequest{Schema: json.RawMessage(extractTestSchema)},
+				want: services.ErrInvalidExtractRequest,
+			},
+			{
+				name: "スキーマが不正",
+				req:  services.ExtractRequest{Text: "本文", Schema: json.RawMessage(`{"type": "invoice"}`)},
+				want: jsonschema.ErrInvalidSchema,
+			},
+			{
+				name: "スキーマがない",
+				req:  services.ExtractRequest{Text: "本文"},
+				want: jsonschema.ErrInvalidSchema,
+			},
+		}
+		for _, tt := range tests {
+			_, err := extractService.Extract(ctx, tt.req)
+			assert.True(t, errors.Is(err, tt.want), "%s: %v", tt.name, err)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"strings"
+)
+
+// simHashShingleSize はSimHashの特徴量にする文字 n-gram の長さ
+// 日本語は空白で単語に区切られないため、単語ではなく文字単位の n-gram を使う
+const simHashShingleSize = 4
+
+// contentHash はファイルの内容のSHA-256を16進数で返す (完全一致の重複の検出用)
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// contentSimHash はテキストの64ビットのSimHashを返す (類似した重複の検出用)
+// 大文字・小文字や空白の違いは無視する。内容が似ているほどハミング距離が小さくなる
+func contentSimHash(text string) int64 {
+	runes := []rune(strings.ToLower(strings.Join(strings.Fields(text), " ")))
+	if len(runes) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	for i := 0; i < max(len(runes)-simHashShingleSize+1, 1); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(string(runes[i:min(i+simHashShingleSize, len(runes))])))
+		v := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if v&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit, w := range weights {
+		if w > 0 {
+			hash |= 1 << bit
+		}
+	}
+	return int64(hash)
+}
+
+// simHashSimilarity はSimHashのハミング距離を 0〜1 の類似度に変換する
+func simHashSimilarity(distance int) float64 {
+	return 1 - float64(distance)/64
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"math/bits"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentHash(t *testing.T) {
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", contentHash(nil))
+	assert.NotEqual(t, contentHash([]byte("a")), contentHash([]byte("b")))
+}
+
+func TestContentSimHash(t *testing.T) {
+	base := strings.Repeat("有給休暇の申請は社内ポータルの勤怠メニューから行います。申請は取得日の3営業日前までに上長の承認を得てください。", 3)
+	distance := func(a, b string) int {
+		return bits.OnesCount64(uint64(contentSimHash(a) ^ contentSimHash(b)))
+	}
+
+	t.Run("空白と大文字・小文字の違いは無視する", func(t *testing.T) {
+		assert.Equal(t, contentSimHash("Hello  World\n"), contentSimHash("hello world"))
+	})
+
+	t.Run("一部を編集したテキストは距離が小さい", func(t *testing.T) {
+		edited := strings.Replace(base, "3営業日前", "5営業日前", 1)
+		assert.LessOrEqual(t, distance(base, edited), 6)
+	})
+
+	t.Run("内容の異なるテキストは距離が大きい", func(t *testing.T) {
+		other := "経費精算は月末締めで、領収書の原本を経理部へ提出します。交通費は定期区間を除いて精算できます。"
+		assert.Greater(t, distance(base, other), 10)
+	})
+
+	t.Run("空のテキストは0", func(t *testing.T) {
+		assert.Equal(t, int64(0), contentSimHash(" \n"))
+	})
+}
+
+func TestSimHashSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, simHashSimilarity(0))
+	assert.Equal(t, 0.5, simHashSimilarity(32))
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// 依存先チェックの状態
+const (
+	CheckStatusUp   = "up"
+	CheckStatusDown = "down"
+)
+
+// サービス全体の状態
+const (
+	HealthStatusOK          = "ok"          // すべての依存先が正常
+	HealthStatusDegraded    = "degraded"    // 一部の機能が利用できない
+	HealthStatusUnavailable = "unavailable" // 必須の依存先が停止しておりリクエストを受け付けられない
+)
+
+// HealthCheck は依存先1件分のヘルスチェック定義
+type HealthCheck struct {
+	Name     string                          // 依存先名 (例: "postgres")
+	Critical bool                            // trueの場合、失敗時にサービス全体をunavailableとする
+	Features []string                        // この依存先に依存する機能名 (例: "recommend")
+	CacheTTL time.Duration                   // 0より大きい場合、結果をこの期間キャッシュする
+	Check    func(ctx context.Context) error // 疎通確認処理
+}
+
+// CheckResult は依存先1件分のチェック結果
+type CheckResult struct {
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	LatencyMs int64     `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+	Cached    bool      `json:"cached,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// FeatureStatus は機能ごとの利用可否
+type FeatureStatus struct {
+	Available bool     `json:"available"`
+	Degraded  []string `json:"degraded_by,omitempty"` // 利用不可の原因となっている依存先
+}
+
+// HealthReport はreadinessチェックの結果全体
+type HealthReport struct {
+	Status   string                   `json:"status"`
+	Checks   map[string]CheckResult   `json:"checks"`
+	Features map[string]FeatureStatus `json:"features"`
+}
+
+// HealthService は依存先ごとのヘルスチェックを実行するサービス
+type HealthService struct {
+	checks  []HealthCheck
+	timeout time.Duration
+
+	mu    sync.Mutex
+	cache map[string]CheckResult
+	now   func() time.Time
+}
+
+// NewHealthService は新しいHealthServiceを作成する
+// timeout はチェック1件あたりのタイムアウト (0以下の場合はタイムアウトなし)
+func NewHealthService(timeout time.Duration, checks ...HealthCheck) *HealthService {
+	return &HealthService{
+		checks:  checks,
+		timeout: timeout,
+		cache:   make(map[string]CheckResult),
+		now:     time.Now,
+	}
+}
+
+// Readiness はすべての依存先をチェックし、機能ごとの利用可否を含むレポートを返す
+func (s *HealthService) Readiness(ctx context.Context) *HealthReport {
+	results := make([]CheckResult, len(s.checks))
+
+	// 各チェックは独立しているので並行して実行する
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			results[i] = s.runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := &HealthReport{
+		Status:   HealthStatusOK,
+		Checks:   make(map[string]CheckResult, len(s.checks)),
+		Features: make(map[string]FeatureStatus),
+	}
+
+	for i, check := range s.checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+
+		for _, feature := range check.Features {
+			status, ok := report.Features[feature]
+			if !ok {
+				status = FeatureStatus{Available: true}
+			}
+			if result.Status != CheckStatusUp {
+				status.Available = false
+				status.Degraded = append(status.Degraded, check.Name)
+			}
+			report.Features[feature] = status
+		}
+
+		if result.Status != CheckStatusUp {
+			if check.Critical {
+				report.Status = HealthStatusUnavailable
+			} else if report.Status == HealthStatusOK {
+				report.Status = HealthStatusDegraded
+			}
+		}
+	}
+
+	return report
+}
+
+// runCheck は1件のチェックを実行する (有効なキャッシュがある場合はそれを返す)
+func (s *HealthService) runCheck(ctx context.Context, check HealthCheck) CheckResult {
+	if check.CacheTTL > 0 {
+		s.mu.Lock()
+		cached, ok := s.cache[check.Name]
+		s.mu.Unlock()
+		if ok && s.now().Sub(cached.CheckedAt) < check.CacheTTL {
+			cached.Cached = true
+			return cached
+		}
+	}
+
+	checkCtx := ctx
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		checkCtx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	start := s.now()
+	err := check.Check(checkCtx)
+	result := CheckResult{
+		Status:    CheckStatusUp,
+		Critical:  check.Critical,
+		LatencyMs: s.now().Sub(start).Milliseconds(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = CheckStatusDown
+		result.Error = err.Error()
+	}
+
+	// 失敗結果はキャッシュせず、次回のチェックで即座に復旧を検知できるようにする
+	if check.CacheTTL > 0 && err == nil {
+		s.mu.Lock()
+		s.cache[check.Name] = result
+		s.mu.Unlock()
+	}
+
+	return result
+}
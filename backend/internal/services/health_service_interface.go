@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+)
+
+// HealthServiceInterface はヘルスチェックサービスのインターフェース
+type HealthServiceInterface interface {
+	Readiness(ctx context.Context) *HealthReport
+}
+
+// インターフェースを実装していることを静的にチェック
+var _ HealthServiceInterface = (*HealthService)(nil)
@@ -0,0 +1,102 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthService_Readiness(t *testing.T) {
+	ctx := context.Background()
+	ok := func(ctx context.Context) error { return nil }
+	fail := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	t.Run("正常系_すべて正常", func(t *testing.T) {
+		svc := services.NewHealthService(time.Second,
+			services.HealthCheck{Name: "postgres", Features: []string{"recommend"}, Check: ok},
+			services.HealthCheck{Name: "s3", Critical: true, Features: []string{"upload"}, Check: ok},
+		)
+
+		report := svc.Readiness(ctx)
+
+		assert.Equal(t, services.HealthStatusOK, report.Status)
+		assert.Equal(t, services.CheckStatusUp, report.Checks["postgres"].Status)
+		assert.True(t, report.Features["recommend"].Available)
+		assert.True(t, report.Features["upload"].Available)
+	})
+
+	t.Run("縮退_必須でない依存先の失敗", func(t *testing.T) {
+		svc := services.NewHealthService(time.Second,
+			services.HealthCheck{Name: "postgres", Features: []string{"recommend"}, Check: fail},
+			services.HealthCheck{Name: "bedrock", Critical: true, Features: []string{"recommend", "qa"}, Check: ok},
+		)
+
+		report := svc.Readiness(ctx)
+
+		assert.Equal(t, services.HealthStatusDegraded, report.Status)
+		assert.Equal(t, services.CheckStatusDown, report.Checks["postgres"].Status)
+		assert.Equal(t, "connection refused", report.Checks["postgres"].Error)
+		assert.False(t, report.Features["recommend"].Available)
+		assert.Equal(t, []string{"postgres"}, report.Features["recommend"].Degraded)
+		assert.True(t, report.Features["qa"].Available)
+	})
+
+	t.Run("異常系_必須の依存先の失敗", func(t *testing.T) {
+		svc := services.NewHealthService(time.Second,
+			services.HealthCheck{Name: "postgres", Features: []string{"recommend"}, Check: fail},
+			services.HealthCheck{Name: "s3", Critical: true, Features: []string{"upload"}, Check: fail},
+		)
+
+		report := svc.Readiness(ctx)
+
+		assert.Equal(t, services.HealthStatusUnavailable, report.Status)
+	})
+
+	t.Run("タイムアウト", func(t *testing.T) {
+		svc := services.NewHealthService(10*time.Millisecond,
+			services.HealthCheck{Name: "slow", Critical: true, Check: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}},
+		)
+
+		report := svc.Readiness(ctx)
+
+		assert.Equal(t, services.HealthStatusUnavailable, report.Status)
+		assert.Contains(t, report.Checks["slow"].Error, "deadline exceeded")
+	})
+
+	t.Run("キャッシュ_成功結果のみ再利用される", func(t *testing.T) {
+		calls := 0
+		shouldFail := true
+		svc := services.NewHealthService(time.Second,
+			services.HealthCheck{Name: "bedrock", Critical: true, CacheTTL: time.Hour, Check: func(ctx context.Context) error {
+				calls++
+				if shouldFail {
+					return errors.New("throttled")
+				}
+				return nil
+			}},
+		)
+
+		// 失敗結果はキャッシュされない
+		report := svc.Readiness(ctx)
+		require.Equal(t, services.CheckStatusDown, report.Checks["bedrock"].Status)
+		shouldFail = false
+		report = svc.Readiness(ctx)
+		require.Equal(t, services.CheckStatusUp, report.Checks["bedrock"].Status)
+		assert.False(t, report.Checks["bedrock"].Cached)
+
+		// 成功結果はTTLの間キャッシュされる
+		report = svc.Readiness(ctx)
+		assert.Equal(t, services.CheckStatusUp, report.Checks["bedrock"].Status)
+		assert.True(t, report.Checks["bedrock"].Cached)
+		assert.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/pkg/aws"
+
+	"github.com/rs/zerolog/log"
+)
+
+// 重複したドキュメントの扱い
+const (
+	DuplicatePolicyReject  = "reject"  // 取り込まずにエラーとする
+	DuplicatePolicyLink    = "link"    // 取り込まずに既存のドキュメントを返す
+	DuplicatePolicyVersion = "version" // 既存のドキュメントの新しい版として取り込む
+)
+
+// 重複の種類
+const (
+	DuplicateMatchExact = "exact" // ファイルの内容が完全に一致する
+	DuplicateMatchNear  = "near"  // 抽出したテキストが類似している
+)
+
+var (
+	// ErrInvalidDuplicatePolicy は重複したドキュメントの扱いの指定が不正な場合のエラー
+	ErrInvalidDuplicatePolicy = errors.New("重複したドキュメントの扱いは reject / link / version のいずれかを指定してください")
+	// ErrUnsupportedFileType は取り込めないファイル形式の場合のエラー
+	ErrUnsupportedFileType = errors.New("サポートされていないファイル形式です")
+	// ErrNoExtractableText はドキュメントからテキストを抽出できなかった場合のエラー
+	ErrNoExtractableText = errors.New("ドキュメントからテキストを抽出できませんでした")
+	// ErrDuplicateDocument は既存のドキュメントと重複している場合のエラー
+	ErrDuplicateDocument = errors.New("既に取り込まれたドキュメントと重複しています")
+)
+
+// DuplicateMatch は取り込もうとしたドキュメントと重複している既存のドキュメント
+type DuplicateMatch struct {
+	DocumentID int64   `json:"document_id"`
+	LogicalID  int64   `json:"logical_id"`
+	Filename   string  `json:"filename"`
+	Match      string  `json:"match"`      // DuplicateMatchExact または DuplicateMatchNear
+	Similarity float64 `json:"similarity"` // 完全一致は 1
+}
+
+// DuplicateDocumentError は重複したため取り込まなかったことを表すエラー
+type DuplicateDocumentError struct {
+	Existing DuplicateMatch
+}
+
+func (e *DuplicateDocumentError) Error() string {
+	return fmt.Sprintf("%v (existing_document_id: %d, match: %s, similarity: %.3f)", ErrDuplicateDocument, e.Existing.DocumentID, e.Existing.Match, e.Existing.Similarity)
+}
+
+func (e *DuplicateDocumentError) Unwrap() error {
+	return ErrDuplicateDocument
+}
+
+// Textractでテキストを抽出するファイル形式と、内容をそのままテキストとして扱うファイル形式
+var (
+	textractFileTypes  = map[string]bool{".pdf": true, ".png": true, ".jpg": true, ".jpeg": true, ".tiff": true}
+	plainTextFileTypes = map[string]bool{".txt": true, ".md": true, ".markdown": true, ".adoc": true, ".asciidoc": true, ".rst": true, ".csv": true}
+)
+
+// isSupportedFile は取り込めるファイル形式かどうかを拡張子で判定する
+func isSupportedFile(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return textractFileTypes[ext] || plainTextFileTypes[ext]
+}
+
+// IngestRequest はドキュメント1件の取り込みリクエスト
+type IngestRequest struct {
+	Filename        string
+	Content         []byte
+	DuplicatePolicy string // 空の場合は設定の既定値
+	LogicalID       int64  // 新しい版を追加する論理ドキュメントのID (0 の場合は新しいドキュメントとして取り込む)
+
+	// 取り込み元 (同期で取り込む場合のみ。次回の同期で変更を検出するために記録する)
+	SourceURI        string
+	SourceVersion    string
+	SourceModifiedAt *time.Time
+}
+
+// IngestResult はドキュメントの取り込み結果
+type IngestResult struct {
+	Document  *domain.Document `json:"document"`
+	Linked    bool             `json:"linked"`              // 重複のため取り込まず、既存のドキュメントを返した場合は true
+	Duplicate *DuplicateMatch  `json:"duplicate,omitempty"` // 重複していた既存のドキュメント
+}
+
+// IngestService はドキュメントをS3に保存し、テキストの抽出・チャンク分割・Embedding生成までを行うサービス
+// 取り込み時にファイルのハッシュ (完全一致) と抽出したテキストのSimHash (類似) で重複を検出する
+// 分類器を指定した場合は、抽出したテキストからカテゴリとタグを付ける
+// 個人情報の除去を設定した場合は、抽出したテキストから除去してから保存するため、チャンクとEmbeddingにも元の値は残らない
+type IngestService struct {
+	s3Client         aws.S3ClientInterface
+	textractClient   aws.TextractClientInterface
+	dbHandler        domain.DBHandlerInterface
+	recommendService RecommendServiceInterface
+	classifier       DocumentClassifier
+	redactor         *PIIRedactor
+	cfg              config.IngestConfig
+	now              func() time.Time
+}
+
+// NewIngestService は新しいIngestServiceを作成する
+// classifier が nil の場合は取り込み時に分類しない。redactor が nil の場合は個人情報を除去しない
+func NewIngestService(s3Client aws.S3ClientInterface, textractClient aws.TextractClientInterface, dbHandler domain.DBHandlerInterface, recommendService RecommendServiceInterface, classifier DocumentClassifier, redactor *PIIRedactor, cfg config.IngestConfig) *IngestService {
+	return &IngestService{
+		s3Client:         s3Client,
+		textractClient:   textractClient,
+		dbHandler:        dbHandler,
+		recommendService: recommendService,
+		classifier:       classifier,
+		redactor:         redactor,
+		cfg:              cfg,
+		now:              time.Now,
+	}
+}
+
+// Ingest はドキュメント1件を取り込む
+// 重複を検出した場合は policy に従い、reject は *DuplicateDocumentError を返し、link は既存のドキュメントを返す
+// version は既存のドキュメントを DuplicateOf に記録し、その論理ドキュメントの新しい版として取り込む
+// req.LogicalID を指定した場合は、その論理ドキュメントの新しい版として取り込む (内容が似ているのは当然のため、類似した重複は確認しない)
+func (s *IngestService) Ingest(ctx context.Context, req IngestRequest) (*IngestResult, error) {
+	policy := req.DuplicatePolicy
+	if policy == "" {
+		policy = s.cfg.DuplicatePolicy
+	}
+	switch policy {
+	case DuplicatePolicyReject, DuplicatePolicyLink, DuplicatePolicyVersion:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDuplicatePolicy, policy)
+	}
+
+	filename := filepath.Base(req.Filename)
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !isSupportedFile(filename) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFileType, ext)
+	}
+
+	hash := contentHash(req.Content)
+	existing, err := s.dbHandler.FindDocumentByContentHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("重複の確認に失敗しました: %w", err)
+	}
+	var duplicate *DuplicateMatch
+	if existing != nil {
+		duplicate = &DuplicateMatch{DocumentID: existing.ID, LogicalID: existing.LogicalID, Filename: existing.Filename, Match: DuplicateMatchExact, Similarity: 1}
+		if result, err := s.resolveDuplicate(policy, duplicate); result != nil || err != nil {
+			return result, err
+		}
+	}
+
+	// 同じ名前のファイルを上書きしないよう、ハッシュと取り込み時刻をキーに含める
+	key := s.s3Client.ObjectKey(uploadFolderFor(ext), hash[:16], strconv.FormatInt(s.now().UnixMilli(), 10), filename)
+	if err := s.s3Client.PutObject(ctx, key, req.Content); err != nil {
+		return nil, fmt.Errorf("ファイルのアップロードに失敗しました: %w", err)
+	}
+
+	doc := &domain.Document{
+		LogicalID:        req.LogicalID,
+		Filename:         filename,
+		S3Key:            key,
+		ContentHash:      hash,
+		SourceURI:        req.SourceURI,
+		SourceVersion:    req.SourceVersion,
+		SourceModifiedAt: req.SourceModifiedAt,
+	}
+	result, err := s.store(ctx, policy, duplicate, doc, req.Content, ext)
+	if err != nil || result.Linked {
+		// 取り込まなかったファイルは残さない
+		if deleteErr := s.s3Client.DeleteObject(ctx, key); deleteErr != nil {
+			log.Error().Err(deleteErr).Str("s3_key", key).Msg("Failed to delete uploaded object")
+		}
+	}
+	return result, err
+}
+
+// store はテキストを抽出して類似した重複を確認し、ドキュメントとチャンクを保存する
+// 完全一致の重複がなく、類似した重複を検出した場合も policy に従う
+func (s *IngestService) store(ctx context.Context, policy string, duplicate *DuplicateMatch, doc *domain.Document, content []byte, ext string) (*IngestResult, error) {
+	text, err := s.extractText(ctx, doc.S3Key, content, ext)
+	if err != nil {
+		return nil, err
+	}
+	redacted, err := s.redactor.Redact(RedactEndpointIngest, text)
+	if err != nil {
+		return nil, err
+	}
+	doc.Content = redacted.Text
+	doc.SimHash = contentSimHash(doc.Content)
+
+	if duplicate == nil && doc.LogicalID == 0 && s.cfg.NearDuplicateMaxDistance >= 0 {
+		near, err := s.dbHandler.FindNearDuplicateDocuments(ctx, doc.SimHash, s.cfg.NearDuplicateMaxDistance, 1)
+		if err != nil {
+			return nil, fmt.Errorf("重複の確認に失敗しました: %w", err)
+		}
+		if len(near) > 0 {
+			duplicate = &DuplicateMatch{
+				DocumentID: near[0].DocumentID,
+				LogicalID:  near[0].LogicalID,
+				Filename:   near[0].Filename,
+				Match:      DuplicateMatchNear,
+				Similarity: simHashSimilarity(near[0].Distance),
+			}
+			if result, err := s.resolveDuplicate(policy, duplicate); result != nil || err != nil {
+				return result, err
+			}
+		}
+	}
+	if duplicate != nil {
+		doc.DuplicateOf = &duplicate.DocumentID
+		if doc.LogicalID == 0 {
+			doc.LogicalID = duplicate.LogicalID
+		}
+	}
+	s.classify(ctx, doc)
+
+	if err := s.dbHandler.CreateDocument(ctx, doc); err != nil {
+		return nil, fmt.Errorf("ドキュメントの保存に失敗しました: %w", err)
+	}
+	s.redactor.Audit(ctx, RedactEndpointIngest, &doc.ID, redacted.Findings)
+	if err := s.recommendService.ProcessDocumentForEmbedding(ctx, doc); err != nil {
+		// チャンクのないドキュメントが検索対象に残らないよう削除する
+		if deleteErr := s.dbHandler.DeleteDocument(ctx, doc.ID); deleteErr != nil {
+			log.Error().Err(deleteErr).Int64("document_id", doc.ID).Msg("Failed to delete document after embedding failure")
+		}
+		return nil, fmt.Errorf("チャンクの作成に失敗しました: %w", err)
+	}
+	// レスポンスが大きくならないよう、抽出したテキストは返さない
+	doc.Content = ""
+	return &IngestResult{Document: doc, Duplicate: duplicate}, nil
+}
+
+// classify はドキュメントにカテゴリとタグを付ける
+// 分類できなくても取り込みは続け、未分類のまま保存する (後からAPIで付けられる)
+func (s *IngestService) classify(ctx context.Context, doc *domain.Document) {
+	if s.classifier == nil {
+		return
+	}
+	classification, err := s.classifier.Classify(ctx, doc.Content)
+	if err != nil {
+		log.Warn().Err(err).Str("filename", doc.Filename).Msg("Failed to classify document")
+		return
+	}
+	doc.Category = classification.Category
+	doc.Tags = classification.Tags
+}
+
+// resolveDuplicate は重複を検出した場合の policy に応じた結果を返す
+// version の場合は取り込みを続けるため、結果もエラーも返さない
+func (s *IngestService) resolveDuplicate(policy string, duplicate *DuplicateMatch) (*IngestResult, error) {
+	switch policy {
+	case DuplicatePolicyReject:
+		return nil, &DuplicateDocumentError{Existing: *duplicate}
+	case DuplicatePolicyLink:
+		return &IngestResult{Document: &domain.Document{ID: duplicate.DocumentID, LogicalID: duplicate.LogicalID, Filename: duplicate.Filename}, Linked: true, Duplicate: duplicate}, nil
+	}
+	return nil, nil
+}
+
+// extractText はファイル形式に応じてテキストを抽出する
+func (s *IngestService) extractText(ctx context.Context, key string, content []byte, ext string) (string, error) {
+	var text string
+	if plainTextFileTypes[ext] {
+		if !utf8.Valid(content) {
+			return "", fmt.Errorf("%w: UTF-8のテキストではありません", ErrNoExtractableText)
+		}
+		text = string(content)
+	} else {
+		result, err := s.textractClient.ExtractTextFromS3Key(ctx, key)
+		if err != nil {
+			return "", fmt.Errorf("テキスト抽出に失敗しました (key: %s): %w", key, err)
+		}
+		text = result.Text
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return "", ErrNoExtractableText
+	}
+	return text, nil
+}
@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+)
+
+// IngestServiceInterface はドキュメント取り込みサービスのインターフェース
+type IngestServiceInterface interface {
+	Ingest(ctx context.Context, req IngestRequest) (*IngestResult, error)
+}
+
+// インターフェースを実装していることを静的にチェック
+var _ IngestServiceInterface = (*IngestService)(nil)
@@ -0,0 +1,336 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	domainmocks "bedrock-rag-sample/backend/internal/domain/mocks"
+	"bedrock-rag-sample/backend/internal/services"
+	"bedrock-rag-sample/backend/internal/services/mocks"
+	"bedrock-rag-sample/backend/pkg/aws"
+	awsmock "bedrock-rag-sample/backend/pkg/aws/mock"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestService_Ingest(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.IngestConfig{DuplicatePolicy: services.DuplicatePolicyReject, NearDuplicateMaxDistance: 3}
+	content := []byte("有給休暇の申請は社内ポータルの勤怠メニューから行います。")
+
+	type deps struct {
+		s3         *awsmock.MockS3ClientInterface
+		textract   *awsmock.MockTextractClientInterface
+		db         *domainmocks.MockDBHandlerInterface
+		recommend  *mocks.MockRecommendServiceInterface
+		classifier *mocks.MockDocumentClassifier
+	}
+	setup := func(t *testing.T, cfg config.IngestConfig) (*services.IngestService, deps) {
+		ctrl := gomock.NewController(t)
+		d := deps{
+			s3:         awsmock.NewMockS3ClientInterface(ctrl),
+			textract:   awsmock.NewMockTextractClientInterface(ctrl),
+			db:         domainmocks.NewMockDBHandlerInterface(ctrl),
+			recommend:  mocks.NewMockRecommendServiceInterface(ctrl),
+			classifier: mocks.NewMockDocumentClassifier(ctrl),
+		}
+		d.s3.EXPECT().ObjectKey(gomock.Any()).DoAndReturn(func(elem ...string) string {
+			return path.Join(append([]string{"documents"}, elem...)...)
+		}).AnyTimes()
+		return services.NewIngestService(d.s3, d.textract, d.db, d.recommend, nil, nil, cfg), d
+	}
+	existing := &domain.Document{ID: 3, LogicalID: 1, Filename: "leave.txt"}
+
+	t.Run("正常系: 新しいドキュメントを取り込む", func(t *testing.T) {
+		svc, d := setup(t, cfg)
+
+		d.db.EXPECT().FindDocumentByContentHash(gomock.Any(), gomock.Any()).Return(nil, nil)
+		d.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), content).Return(nil)
+		d.db.EXPECT().FindNearDuplicateDocuments(gomock.Any(), gomock.Any(), 3, 1).Return(nil, nil)
+		d.db.EXPECT().CreateDocument(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, doc *domain.Document) error {
+			assert.Equal(t, "leave.txt", doc.Filename)
+			assert.Equal(t, string(content), doc.Content)
+			assert.Len(t, doc.ContentHash, 64)
+			assert.Nil(t, doc.DuplicateOf)
+			doc.ID = 10
+			return nil
+		})
+		d.recommend.EXPECT().ProcessDocumentForEmbedding(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := svc.Ingest(ctx, services.IngestRequest{Filename: "leave.txt", Content: content})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), result.Document.ID)
+		assert.Empty(t, result.Document.Content)
+		assert.False(t, result.Linked)
+		assert.Nil(t, result.Duplicate)
+	})
+
+	t.Run("正常系: 分類器が選んだカテゴリとタグを付けて保存する", func(t *testing.T) {
+		_, d := setup(t, cfg)
+		svc := services.NewIngestService(d.s3, d.textract, d.db, d.recommend, d.classifier, nil, cfg)
+
+		d.db.EXPECT().FindDocumentByContentHash(gomock.Any(), gomock.Any()).Return(nil, nil)
+		d.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), content).Return(nil)
+		d.db.EXPECT().FindNearDuplicateDocuments(gomock.Any(), gomock.Any(), 3, 1).Return(nil, nil)
+		d.classifier.EXPECT().Classify(gomock.Any(), string(content)).Return(&services.Classification{Category: "policy", Tags: []string{"休暇"}}, nil)
+		d.db.EXPECT().CreateDocument(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, doc *domain.Document) error {
+			assert.Equal(t, "policy", doc.Category)
+			assert.Equal(t, []string{"休暇"}, doc.Tags)
+			doc.ID = 10
+			return nil
+		})
+		d.recommend.EXPECT().ProcessDocumentForEmbedding(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := svc.Ingest(ctx, services.IngestRequest{Filename: "leave.txt", Content: content})
+
+		require.NoError(t, err)
+		assert.Equal(t, "policy", result.Document.Category)
+	})
+
+	t.Run("正常系: 分類に失敗しても未分類のまま取り込む", func(t *testing.T) {
+		_, d := setup(t, cfg)
+		svc := services.NewIngestService(d.s3, d.textract, d.db, d.recommend, d.classifier, nil, cfg)
+
+		d.db.EXPECT().FindDocumentByContentHash(gomock.Any(), gomock.Any()).Return(nil, nil)
+		d.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), content).Return(nil)
+		d.db.EXPECT().FindNearDuplicateDocuments(gomock.Any(), gomock.Any(), 3, 1).Return(nil, nil)
+		d.classifier.EXPECT().Classify(gomock.Any(), gomock.Any()).Return(nil, errors.New("ThrottlingException"))
+		d.db.EXPECT().CreateDocument(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, doc *domain.Document) error {
+			assert.Empty(t, doc.Category)
+			assert.Empty(t, doc.Tags)
+			doc.ID = 10
+			return nil
+		})
+		d.recommend.EXPECT().ProcessDocumentForEmbedding(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := svc.Ingest(ctx, services.IngestRequest{Filename: "leave.txt", Content: content})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("正常系: 個人情報を除去して保存し、除去した箇所を記録する", func(t *testing.T) {
+		_, d := setup(t, cfg)
+		redactor, err := services.NewPIIRedactor(config.RedactConfig{Enabled: true, DefaultMode: "mask", Detectors: []string{"phone"}}, d.db)
+		require.NoError(t, err)
+		svc := services.NewIngestService(d.s3, d.textract, d.db, d.recommend, nil, redactor, cfg)
+		content := []byte("担当者の電話: 090-1234-5678")
+
+		d.db.EXPECT().FindDocumentByContentHash(gomock.Any(), gomock.Any()).Return(nil, nil)
+		d.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), content).Return(nil)
+		d.db.EXPECT().FindNearDuplicateDocuments(gomock.Any(), gomock.Any(), 3, 1).Return(nil, nil)
+		d.db.EXPECT().CreateDocument(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, doc *domain.Document) error {
+			assert.Equal(t, "担当者の電話: [PHONE]", doc.Content)
+			doc.ID = 10
+			return nil
+		})
+		d.db.EXPECT().SaveRedactionAudit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, records []domain.PIIRedaction) error {
+			require.Len(t, records, 1)
+			assert.Equal(t, services.RedactEndpointIngest, records[0].Endpoint)
+			assert.Equal(t, int64(10), *records[0].DocumentID)
+			assert.Equal(t, "phone", records[0].Type)
+			assert.Equal(t, 8, records[0].Start)
+			return nil
+		})
+		d.recommend.EXPECT().ProcessDocumentForEmbedding(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, doc *domain.Document) error {
+			assert.NotContains(t, doc.Content, "090-1234-5678")
+			return nil
+		})
+
+		_, err = svc.Ingest(ctx, services.IngestRequest{Filename: "contact.txt", Content: content})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("異常系: 個人情報を拒否する設定では取り込まず、アップロードしたファイルを削除する", func(t *testing.T) {
+		_, d := setup(t, cfg)
+		redactor, err := services.NewPIIRedactor(config.RedactConfig{Enabled: true, DefaultMode: "reject", Detectors: []string{"email"}}, d.db)
+		require.NoError(t, err)
+		svc := services.NewIngestService(d.s3, d.textract, d.db, d.recommend, nil, redactor, cfg)
+
+		d.db.EXPECT().FindDocumentByContentHash(gomock.Any(), gomock.Any()).Return(nil, nil)
+		d.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		d.s3.EXPECT().DeleteObject(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err = svc.Ingest(ctx, services.IngestRequest{Filename: "contact.txt", Content: []byte("連絡先: taro@example.com")})
+
+		assert.ErrorIs(t, err, services.ErrPIIDetected)
+	})
+
+	t.Run("正常系: PDFはTextractでテキストを抽出する", func(t *testing.T) {
+		svc, d := setup(t, config.IngestConfig{DuplicatePolicy: services.DuplicatePolicyReject, NearDuplicateMaxDistance: -1})
+
+		var key string
+		d.db.EXPECT().FindDocumentByContentHash(gomock.Any(), gomock.Any()).Return(nil, nil)
+		d.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, k string, _ []byte) error {
+			key = k
+			return nil
+		})
+		d.textract.EXPECT().ExtractTextFromS3Key(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, k string) (*aws.TextractResult, error) {
+			assert.Equal(t, key, k)
+			return &aws.TextractResult{Text: "抽出したテキスト"}, nil
+		})
+		d.db.EXPECT().CreateDocument(gomock.Any(), gomock.Any()).Return(nil)
+		d.recommend.EXPECT().ProcessDocumentForEmbedding(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := svc.Ingest(ctx, services.IngestRequest{Filename: "manual.PDF", Content: []byte("%PDF-1.7")})
+
+		require.NoError(t, err)
+		assert.Contains(t, key, "documents/pdf/")
+		assert.Equal(t, "manual.PDF", path.Base(key))
+	})
+
+	t.Run("異常系: 完全一致の重複は reject で既存のドキュメントIDを返す", func(t *testing.T) {
+		svc, d := setup(t, cfg)
+
+		d.db.EXPECT().FindDocumentByContentHash(gomock.Any(), gomock.Any()).Return(existing, nil)
+
+		_, err := svc.Ingest(ctx, services.IngestRequest{Filename: "leave-copy.txt", Content: content})
+
+		var dupErr *services.DuplicateDocumentError
+		require.ErrorAs(t, err, &dupErr)
+		assert.ErrorIs(t, err, services.ErrDuplicateDocument)
+		assert.Equal(t, int64(3), dupErr.Existing.DocumentID)
+		assert.Equal(t, services.DuplicateMatchExact, dupErr.Existing.Match)
+	})
+
+	t.Run("正常系: 完全一致の重複は link で既存のドキュメントを返す", func(t *testing.T) {
+		svc, d := setup(t, cfg)
+
+		d.db.EXPECT().FindDocumentByContentHash(gomock.Any(), gomock.Any()).Return(existing, nil)
+
+		result, err := svc.Ingest(ctx, services.IngestRequest{Filename: "leave-copy.txt", Content: content, DuplicatePolicy: services.DuplicatePolicyLink})
+
+		require.NoError(t, err)
+		assert.True(t, result.Linked)
+		assert.Equal(t, int64(3), result.Document.ID)
+		assert.Equal(t, 1.0, result.Duplicate.Similarity)
+	})
+
+	t.Run("正常系: 完全一致の重複は version で既存のドキュメントの新しい版として取り込む", func(t *testing.T) {
+		svc, d := setup(t, cfg)
+
+		d.db.EXPECT().FindDocumentByContentHash(gomock.Any(), gomock.Any()).Return(existing, nil)
+		d.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		d.db.EXPECT().CreateDocument(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, doc *domain.Document) error {
+			require.NotNil(t, doc.DuplicateOf)
+			assert.Equal(t, int64(3), *doc.DuplicateOf)
+			assert.Equal(t, int64(1), doc.LogicalID)
+			doc.ID = 11
+			return nil
+		})
+		d.recommend.EXPECT().ProcessDocumentForEmbedding(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := svc.Ingest(ctx, services.IngestRequest{Filename: "leave.txt", Content: content, DuplicatePolicy: services.DuplicatePolicyVersion})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(11), result.Document.ID)
+		assert.Equal(t, services.DuplicateMatchExact, result.Duplicate.Match)
+	})
+
+	t.Run("正常系: 論理ドキュメントを指定した場合は類似した重複を確認せずに新しい版として取り込む", func(t *testing.T) {
+		svc, d := setup(t, cfg)
+
+		d.db.EXPECT().FindDocumentByContentHash(gomock.Any(), gomock.Any()).Return(nil, nil)
+		d.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		d.db.EXPECT().CreateDocument(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, doc *domain.Document) error {
+			assert.Equal(t, int64(1), doc.LogicalID)
+			assert.Nil(t, doc.DuplicateOf)
+			doc.ID, doc.Version = 12, 2
+			return nil
+		})
+		d.recommend.EXPECT().ProcessDocumentForEmbedding(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := svc.Ingest(ctx, services.IngestRequest{Filename: "leave.txt", Content: content, LogicalID: 1})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Document.Version)
+	})
+
+	t.Run("異常系: 類似した重複は reject でアップロードしたファイルを削除する", func(t *testing.T) {
+		svc, d := setup(t, cfg)
+
+		d.db.EXPECT().FindDocumentByContentHash(gomock.Any(), gomock.Any()).Return(nil, nil)
+		d.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		d.db.EXPECT().FindNearDuplicateDocuments(gomock.Any(), gomock.Any(), 3, 1).
+			Return([]domain.NearDuplicate{{DocumentID: 3, Filename: "leave.txt", Distance: 2}}, nil)
+		d.s3.EXPECT().DeleteObject(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := svc.Ingest(ctx, services.IngestRequest{Filename: "leave-v2.txt", Content: content})
+
+		var dupErr *services.DuplicateDocumentError
+		require.ErrorAs(t, err, &dupErr)
+		assert.Equal(t, services.DuplicateMatchNear, dupErr.Existing.Match)
+		assert.InDelta(t, 1-2.0/64, dupErr.Existing.Similarity, 1e-9)
+	})
+
+	t.Run("正常系: 類似した重複は link で既存のドキュメントを返し、ファイルを削除する", func(t *testing.T) {
+		svc, d := setup(t, cfg)
+
+		d.db.EXPECT().FindDocumentByContentHash(gomock.Any(), gomock.Any()).Return(nil, nil)
+		d.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		d.db.EXPECT().FindNearDuplicateDocuments(gomock.Any(), gomock.Any(), 3, 1).
+			Return([]domain.NearDuplicate{{DocumentID: 3, Filename: "leave.txt", Distance: 1}}, nil)
+		d.s3.EXPECT().DeleteObject(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := svc.Ingest(ctx, services.IngestRequest{Filename: "leave-v2.txt", Content: content, DuplicatePolicy: services.DuplicatePolicyLink})
+
+		require.NoError(t, err)
+		assert.True(t, result.Linked)
+		assert.Equal(t, int64(3), result.Document.ID)
+	})
+
+	t.Run("異常系: チャンクの作成に失敗した場合はドキュメントとファイルを削除する", func(t *testing.T) {
+		svc, d := setup(t, cfg)
+		embedErr := errors.New("throttled")
+
+		d.db.EXPECT().FindDocumentByContentHash(gomock.Any(), gomock.Any()).Return(nil, nil)
+		d.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		d.db.EXPECT().FindNearDuplicateDocuments(gomock.Any(), gomock.Any(), 3, 1).Return(nil, nil)
+		d.db.EXPECT().CreateDocument(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, doc *domain.Document) error {
+			doc.ID = 12
+			return nil
+		})
+		d.recommend.EXPECT().ProcessDocumentForEmbedding(gomock.Any(), gomock.Any()).Return(embedErr)
+		d.db.EXPECT().DeleteDocument(gomock.Any(), int64(12)).Return(nil)
+		d.s3.EXPECT().DeleteObject(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := svc.Ingest(ctx, services.IngestRequest{Filename: "leave.txt", Content: content})
+
+		assert.ErrorIs(t, err, embedErr)
+	})
+
+	t.Run("異常系: テキストを抽出できない", func(t *testing.T) {
+		svc, d := setup(t, cfg)
+
+		d.db.EXPECT().FindDocumentByContentHash(gomock.Any(), gomock.Any()).Return(nil, nil)
+		d.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		d.s3.EXPECT().DeleteObject(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := svc.Ingest(ctx, services.IngestRequest{Filename: "empty.txt", Content: []byte(" \n")})
+
+		assert.ErrorIs(t, err, services.ErrNoExtractableText)
+	})
+
+	t.Run("異常系: サポートされていないファイル形式", func(t *testing.T) {
+		svc, _ := setup(t, cfg)
+
+		_, err := svc.Ingest(ctx, services.IngestRequest{Filename: "tool.exe", Content: content})
+
+		assert.ErrorIs(t, err, services.ErrUnsupportedFileType)
+	})
+
+	t.Run("異常系: 重複したドキュメントの扱いが不正", func(t *testing.T) {
+		svc, _ := setup(t, cfg)
+
+		_, err := svc.Ingest(ctx, services.IngestRequest{Filename: "leave.txt", Content: content, DuplicatePolicy: "overwrite"})
+
+		assert.ErrorIs(t, err, services.ErrInvalidDuplicatePolicy)
+	})
+}
@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	aws "bedrock-rag-sample/backend/pkg/aws"
 	context "context"
 	reflect "reflect"
 
@@ -34,6 +35,35 @@ func (m *MockBedrockClientInterface) EXPECT() *MockBedrockClientInterfaceMockRec
 	return m.recorder
 }
 
+// Converse mocks base method.
+func (m *MockBedrockClientInterface) Converse(ctx context.Context, req aws.ConverseRequest) (*aws.ConverseResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Converse", ctx, req)
+	ret0, _ := ret[0].(*aws.ConverseResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Converse indicates an expected call of Converse.
+func (mr *MockBedrockClientInterfaceMockRecorder) Converse(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Converse", reflect.TypeOf((*MockBedrockClientInterface)(nil).Converse), ctx, req)
+}
+
+// EmbeddingModel mocks base method.
+func (m *MockBedrockClientInterface) EmbeddingModel() aws.EmbeddingModel {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmbeddingModel")
+	ret0, _ := ret[0].(aws.EmbeddingModel)
+	return ret0
+}
+
+// EmbeddingModel indicates an expected call of EmbeddingModel.
+func (mr *MockBedrockClientInterfaceMockRecorder) EmbeddingModel() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmbeddingModel", reflect.TypeOf((*MockBedrockClientInterface)(nil).EmbeddingModel))
+}
+
 // GenerateEmbedding mocks base method.
 func (m *MockBedrockClientInterface) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
 	m.ctrl.T.Helper()
@@ -49,6 +79,21 @@ func (mr *MockBedrockClientInterfaceMockRecorder) GenerateEmbedding(ctx, text in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateEmbedding", reflect.TypeOf((*MockBedrockClientInterface)(nil).GenerateEmbedding), ctx, text)
 }
 
+// GenerateEmbeddings mocks base method.
+func (m *MockBedrockClientInterface) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateEmbeddings", ctx, texts)
+	ret0, _ := ret[0].([][]float32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateEmbeddings indicates an expected call of GenerateEmbeddings.
+func (mr *MockBedrockClientInterfaceMockRecorder) GenerateEmbeddings(ctx, texts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateEmbeddings", reflect.TypeOf((*MockBedrockClientInterface)(nil).GenerateEmbeddings), ctx, texts)
+}
+
 // GenerateSummary mocks base method.
 func (m *MockBedrockClientInterface) GenerateSummary(ctx context.Context, text string) (string, error) {
 	m.ctrl.T.Helper()
@@ -78,3 +123,45 @@ func (mr *MockBedrockClientInterfaceMockRecorder) GenerateText(ctx, prompt inter
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateText", reflect.TypeOf((*MockBedrockClientInterface)(nil).GenerateText), ctx, prompt)
 }
+
+// TextModel mocks base method.
+func (m *MockBedrockClientInterface) TextModel() aws.TextModel {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TextModel")
+	ret0, _ := ret[0].(aws.TextModel)
+	return ret0
+}
+
+// TextModel indicates an expected call of TextModel.
+func (mr *MockBedrockClientInterfaceMockRecorder) TextModel() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TextModel", reflect.TypeOf((*MockBedrockClientInterface)(nil).TextModel))
+}
+
+// WithEmbeddingModel mocks base method.
+func (m *MockBedrockClientInterface) WithEmbeddingModel(model aws.EmbeddingModel) aws.BedrockClientInterface {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithEmbeddingModel", model)
+	ret0, _ := ret[0].(aws.BedrockClientInterface)
+	return ret0
+}
+
+// WithEmbeddingModel indicates an expected call of WithEmbeddingModel.
+func (mr *MockBedrockClientInterfaceMockRecorder) WithEmbeddingModel(model interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithEmbeddingModel", reflect.TypeOf((*MockBedrockClientInterface)(nil).WithEmbeddingModel), model)
+}
+
+// WithTextModel mocks base method.
+func (m *MockBedrockClientInterface) WithTextModel(model aws.TextModel) aws.BedrockClientInterface {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTextModel", model)
+	ret0, _ := ret[0].(aws.BedrockClientInterface)
+	return ret0
+}
+
+// WithTextModel indicates an expected call of WithTextModel.
+func (mr *MockBedrockClientInterfaceMockRecorder) WithTextModel(model interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTextModel", reflect.TypeOf((*MockBedrockClientInterface)(nil).WithTextModel), model)
+}
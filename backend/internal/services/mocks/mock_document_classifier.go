@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/document_classifier_interface.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	services "bedrock-rag-sample/backend/internal/services"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDocumentClassifier is a mock of DocumentClassifier interface.
+type MockDocumentClassifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockDocumentClassifierMockRecorder
+}
+
+// MockDocumentClassifierMockRecorder is the mock recorder for MockDocumentClassifier.
+type MockDocumentClassifierMockRecorder struct {
+	mock *MockDocumentClassifier
+}
+
+// NewMockDocumentClassifier creates a new mock instance.
+func NewMockDocumentClassifier(ctrl *gomock.Controller) *MockDocumentClassifier {
+	mock := &MockDocumentClassifier{ctrl: ctrl}
+	mock.recorder = &MockDocumentClassifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDocumentClassifier) EXPECT() *MockDocumentClassifierMockRecorder {
+	return m.recorder
+}
+
+// Classify mocks base method.
+func (m *MockDocumentClassifier) Classify(ctx context.Context, text string) (*services.Classification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Classify", ctx, text)
+	ret0, _ := ret[0].(*services.Classification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Classify indicates an expected call of Classify.
+func (mr *MockDocumentClassifierMockRecorder) Classify(ctx, text interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Classify", reflect.TypeOf((*MockDocumentClassifier)(nil).Classify), ctx, text)
+}
@@ -6,6 +6,7 @@ package mocks
 
 import (
 	services "bedrock-rag-sample/backend/internal/services"
+	aws "bedrock-rag-sample/backend/pkg/aws"
 	context "context"
 	multipart "mime/multipart"
 	reflect "reflect"
@@ -36,6 +37,21 @@ func (m *MockDocumentServiceInterface) EXPECT() *MockDocumentServiceInterfaceMoc
 	return m.recorder
 }
 
+// ExtractTextByS3Key mocks base method.
+func (m *MockDocumentServiceInterface) ExtractTextByS3Key(ctx context.Context, s3Key string) (*aws.TextractResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtractTextByS3Key", ctx, s3Key)
+	ret0, _ := ret[0].(*aws.TextractResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExtractTextByS3Key indicates an expected call of ExtractTextByS3Key.
+func (mr *MockDocumentServiceInterfaceMockRecorder) ExtractTextByS3Key(ctx, s3Key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractTextByS3Key", reflect.TypeOf((*MockDocumentServiceInterface)(nil).ExtractTextByS3Key), ctx, s3Key)
+}
+
 // ProcessDocument mocks base method.
 func (m *MockDocumentServiceInterface) ProcessDocument(ctx context.Context, file *multipart.FileHeader) (*services.DocumentProcessResult, error) {
 	m.ctrl.T.Helper()
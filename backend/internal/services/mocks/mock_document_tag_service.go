@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/document_tag_service_interface.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	domain "bedrock-rag-sample/backend/internal/domain"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDocumentTagServiceInterface is a mock of DocumentTagServiceInterface interface.
+type MockDocumentTagServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockDocumentTagServiceInterfaceMockRecorder
+}
+
+// MockDocumentTagServiceInterfaceMockRecorder is the mock recorder for MockDocumentTagServiceInterface.
+type MockDocumentTagServiceInterfaceMockRecorder struct {
+	mock *MockDocumentTagServiceInterface
+}
+
+// NewMockDocumentTagServiceInterface creates a new mock instance.
+func NewMockDocumentTagServiceInterface(ctrl *gomock.Controller) *MockDocumentTagServiceInterface {
+	mock := &MockDocumentTagServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockDocumentTagServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDocumentTagServiceInterface) EXPECT() *MockDocumentTagServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// UpdateClassification mocks base method.
+func (m *MockDocumentTagServiceInterface) UpdateClassification(ctx context.Context, documentID int64, category string, tags []string) (*domain.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateClassification", ctx, documentID, category, tags)
+	ret0, _ := ret[0].(*domain.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateClassification indicates an expected call of UpdateClassification.
+func (mr *MockDocumentTagServiceInterfaceMockRecorder) UpdateClassification(ctx, documentID, category, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateClassification", reflect.TypeOf((*MockDocumentTagServiceInterface)(nil).UpdateClassification), ctx, documentID, category, tags)
+}
@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/document_version_service_interface.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	domain "bedrock-rag-sample/backend/internal/domain"
+	services "bedrock-rag-sample/backend/internal/services"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDocumentVersionServiceInterface is a mock of DocumentVersionServiceInterface interface.
+type MockDocumentVersionServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockDocumentVersionServiceInterfaceMockRecorder
+}
+
+// MockDocumentVersionServiceInterfaceMockRecorder is the mock recorder for MockDocumentVersionServiceInterface.
+type MockDocumentVersionServiceInterfaceMockRecorder struct {
+	mock *MockDocumentVersionServiceInterface
+}
+
+// NewMockDocumentVersionServiceInterface creates a new mock instance.
+func NewMockDocumentVersionServiceInterface(ctrl *gomock.Controller) *MockDocumentVersionServiceInterface {
+	mock := &MockDocumentVersionServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockDocumentVersionServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDocumentVersionServiceInterface) EXPECT() *MockDocumentVersionServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Diff mocks base method.
+func (m *MockDocumentVersionServiceInterface) Diff(ctx context.Context, logicalID int64, from, to int) (*services.DocumentDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Diff", ctx, logicalID, from, to)
+	ret0, _ := ret[0].(*services.DocumentDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Diff indicates an expected call of Diff.
+func (mr *MockDocumentVersionServiceInterfaceMockRecorder) Diff(ctx, logicalID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Diff", reflect.TypeOf((*MockDocumentVersionServiceInterface)(nil).Diff), ctx, logicalID, from, to)
+}
+
+// GetVersion mocks base method.
+func (m *MockDocumentVersionServiceInterface) GetVersion(ctx context.Context, logicalID int64, version int) (*domain.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion", ctx, logicalID, version)
+	ret0, _ := ret[0].(*domain.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersion indicates an expected call of GetVersion.
+func (mr *MockDocumentVersionServiceInterfaceMockRecorder) GetVersion(ctx, logicalID, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockDocumentVersionServiceInterface)(nil).GetVersion), ctx, logicalID, version)
+}
+
+// ListVersions mocks base method.
+func (m *MockDocumentVersionServiceInterface) ListVersions(ctx context.Context, logicalID int64) ([]domain.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVersions", ctx, logicalID)
+	ret0, _ := ret[0].([]domain.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVersions indicates an expected call of ListVersions.
+func (mr *MockDocumentVersionServiceInterfaceMockRecorder) ListVersions(ctx, logicalID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVersions", reflect.TypeOf((*MockDocumentVersionServiceInterface)(nil).ListVersions), ctx, logicalID)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/extract_service_interface.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	services "bedrock-rag-sample/backend/internal/services"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockExtractServiceInterface is a mock of ExtractServiceInterface interface.
+type MockExtractServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockExtractServiceInterfaceMockRecorder
+}
+
+// MockExtractServiceInterfaceMockRecorder is the mock recorder for MockExtractServiceInterface.
+type MockExtractServiceInterfaceMockRecorder struct {
+	mock *MockExtractServiceInterface
+}
+
+// NewMockExtractServiceInterface creates a new mock instance.
+func NewMockExtractServiceInterface(ctrl *gomock.Controller) *MockExtractServiceInterface {
+	mock := &MockExtractServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockExtractServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExtractServiceInterface) EXPECT() *MockExtractServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Extract mocks base method.
+func (m *MockExtractServiceInterface) Extract(ctx context.Context, req services.ExtractRequest) (*services.ExtractResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Extract", ctx, req)
+	ret0, _ := ret[0].(*services.ExtractResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Extract indicates an expected call of Extract.
+func (mr *MockExtractServiceInterfaceMockRecorder) Extract(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Extract", reflect.TypeOf((*MockExtractServiceInterface)(nil).Extract), ctx, req)
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/git_sync_service_interface.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	domain "bedrock-rag-sample/backend/internal/domain"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockGitSyncServiceInterface is a mock of GitSyncServiceInterface interface.
+type MockGitSyncServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockGitSyncServiceInterfaceMockRecorder
+}
+
+// MockGitSyncServiceInterfaceMockRecorder is the mock recorder for MockGitSyncServiceInterface.
+type MockGitSyncServiceInterfaceMockRecorder struct {
+	mock *MockGitSyncServiceInterface
+}
+
+// NewMockGitSyncServiceInterface creates a new mock instance.
+func NewMockGitSyncServiceInterface(ctrl *gomock.Controller) *MockGitSyncServiceInterface {
+	mock := &MockGitSyncServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockGitSyncServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGitSyncServiceInterface) EXPECT() *MockGitSyncServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Run mocks base method.
+func (m *MockGitSyncServiceInterface) Run(ctx context.Context) (*domain.SyncState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx)
+	ret0, _ := ret[0].(*domain.SyncState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockGitSyncServiceInterfaceMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockGitSyncServiceInterface)(nil).Run), ctx)
+}
+
+// Status mocks base method.
+func (m *MockGitSyncServiceInterface) Status(ctx context.Context) (*domain.SyncState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", ctx)
+	ret0, _ := ret[0].(*domain.SyncState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockGitSyncServiceInterfaceMockRecorder) Status(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockGitSyncServiceInterface)(nil).Status), ctx)
+}
+
+// Trigger mocks base method.
+func (m *MockGitSyncServiceInterface) Trigger(ctx context.Context) (*domain.SyncState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trigger", ctx)
+	ret0, _ := ret[0].(*domain.SyncState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Trigger indicates an expected call of Trigger.
+func (mr *MockGitSyncServiceInterfaceMockRecorder) Trigger(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trigger", reflect.TypeOf((*MockGitSyncServiceInterface)(nil).Trigger), ctx)
+}
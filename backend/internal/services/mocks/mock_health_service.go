@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/health_service_interface.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	services "bedrock-rag-sample/backend/internal/services"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockHealthServiceInterface is a mock of HealthServiceInterface interface.
+type MockHealthServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthServiceInterfaceMockRecorder
+}
+
+// MockHealthServiceInterfaceMockRecorder is the mock recorder for MockHealthServiceInterface.
+type MockHealthServiceInterfaceMockRecorder struct {
+	mock *MockHealthServiceInterface
+}
+
+// NewMockHealthServiceInterface creates a new mock instance.
+func NewMockHealthServiceInterface(ctrl *gomock.Controller) *MockHealthServiceInterface {
+	mock := &MockHealthServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockHealthServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthServiceInterface) EXPECT() *MockHealthServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Readiness mocks base method.
+func (m *MockHealthServiceInterface) Readiness(ctx context.Context) *services.HealthReport {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Readiness", ctx)
+	ret0, _ := ret[0].(*services.HealthReport)
+	return ret0
+}
+
+// Readiness indicates an expected call of Readiness.
+func (mr *MockHealthServiceInterfaceMockRecorder) Readiness(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Readiness", reflect.TypeOf((*MockHealthServiceInterface)(nil).Readiness), ctx)
+}
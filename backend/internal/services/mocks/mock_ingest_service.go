@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/ingest_service_interface.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	services "bedrock-rag-sample/backend/internal/services"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIngestServiceInterface is a mock of IngestServiceInterface interface.
+type MockIngestServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockIngestServiceInterfaceMockRecorder
+}
+
+// MockIngestServiceInterfaceMockRecorder is the mock recorder for MockIngestServiceInterface.
+type MockIngestServiceInterfaceMockRecorder struct {
+	mock *MockIngestServiceInterface
+}
+
+// NewMockIngestServiceInterface creates a new mock instance.
+func NewMockIngestServiceInterface(ctrl *gomock.Controller) *MockIngestServiceInterface {
+	mock := &MockIngestServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockIngestServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIngestServiceInterface) EXPECT() *MockIngestServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Ingest mocks base method.
+func (m *MockIngestServiceInterface) Ingest(ctx context.Context, req services.IngestRequest) (*services.IngestResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ingest", ctx, req)
+	ret0, _ := ret[0].(*services.IngestResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ingest indicates an expected call of Ingest.
+func (mr *MockIngestServiceInterfaceMockRecorder) Ingest(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ingest", reflect.TypeOf((*MockIngestServiceInterface)(nil).Ingest), ctx, req)
+}
@@ -35,17 +35,32 @@ func (m *MockQAServiceInterface) EXPECT() *MockQAServiceInterfaceMockRecorder {
 	return m.recorder
 }
 
+// InvalidateAnswerCache mocks base method.
+func (m *MockQAServiceInterface) InvalidateAnswerCache(ctx context.Context, documentID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateAnswerCache", ctx, documentID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InvalidateAnswerCache indicates an expected call of InvalidateAnswerCache.
+func (mr *MockQAServiceInterfaceMockRecorder) InvalidateAnswerCache(ctx, documentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateAnswerCache", reflect.TypeOf((*MockQAServiceInterface)(nil).InvalidateAnswerCache), ctx, documentID)
+}
+
 // SimpleRAG mocks base method.
-func (m *MockQAServiceInterface) SimpleRAG(ctx context.Context, query string) (*services.QAResult, error) {
+func (m *MockQAServiceInterface) SimpleRAG(ctx context.Context, query, model string) (*services.QAResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SimpleRAG", ctx, query)
+	ret := m.ctrl.Call(m, "SimpleRAG", ctx, query, model)
 	ret0, _ := ret[0].(*services.QAResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SimpleRAG indicates an expected call of SimpleRAG.
-func (mr *MockQAServiceInterfaceMockRecorder) SimpleRAG(ctx, query interface{}) *gomock.Call {
+func (mr *MockQAServiceInterfaceMockRecorder) SimpleRAG(ctx, query, model interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimpleRAG", reflect.TypeOf((*MockQAServiceInterface)(nil).SimpleRAG), ctx, query)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimpleRAG", reflect.TypeOf((*MockQAServiceInterface)(nil).SimpleRAG), ctx, query, model)
 }
@@ -37,18 +37,18 @@ func (m *MockRecommendServiceInterface) EXPECT() *MockRecommendServiceInterfaceM
 }
 
 // FindSimilarDocuments mocks base method.
-func (m *MockRecommendServiceInterface) FindSimilarDocuments(ctx context.Context, query string, limit int) (*services.RecommendResult, error) {
+func (m *MockRecommendServiceInterface) FindSimilarDocuments(ctx context.Context, query string, limit int, filter domain.ChunkFilter) (*services.RecommendResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindSimilarDocuments", ctx, query, limit)
+	ret := m.ctrl.Call(m, "FindSimilarDocuments", ctx, query, limit, filter)
 	ret0, _ := ret[0].(*services.RecommendResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindSimilarDocuments indicates an expected call of FindSimilarDocuments.
-func (mr *MockRecommendServiceInterfaceMockRecorder) FindSimilarDocuments(ctx, query, limit interface{}) *gomock.Call {
+func (mr *MockRecommendServiceInterfaceMockRecorder) FindSimilarDocuments(ctx, query, limit, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSimilarDocuments", reflect.TypeOf((*MockRecommendServiceInterface)(nil).FindSimilarDocuments), ctx, query, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSimilarDocuments", reflect.TypeOf((*MockRecommendServiceInterface)(nil).FindSimilarDocuments), ctx, query, limit, filter)
 }
 
 // ProcessDocumentForEmbedding mocks base method.
@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/reindex_service_interface.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	domain "bedrock-rag-sample/backend/internal/domain"
+	services "bedrock-rag-sample/backend/internal/services"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockReindexServiceInterface is a mock of ReindexServiceInterface interface.
+type MockReindexServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockReindexServiceInterfaceMockRecorder
+}
+
+// MockReindexServiceInterfaceMockRecorder is the mock recorder for MockReindexServiceInterface.
+type MockReindexServiceInterfaceMockRecorder struct {
+	mock *MockReindexServiceInterface
+}
+
+// NewMockReindexServiceInterface creates a new mock instance.
+func NewMockReindexServiceInterface(ctrl *gomock.Controller) *MockReindexServiceInterface {
+	mock := &MockReindexServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockReindexServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReindexServiceInterface) EXPECT() *MockReindexServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Activate mocks base method.
+func (m *MockReindexServiceInterface) Activate(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Activate", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Activate indicates an expected call of Activate.
+func (mr *MockReindexServiceInterfaceMockRecorder) Activate(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Activate", reflect.TypeOf((*MockReindexServiceInterface)(nil).Activate), ctx)
+}
+
+// Cancel mocks base method.
+func (m *MockReindexServiceInterface) Cancel(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockReindexServiceInterfaceMockRecorder) Cancel(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockReindexServiceInterface)(nil).Cancel), ctx)
+}
+
+// Finalize mocks base method.
+func (m *MockReindexServiceInterface) Finalize(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finalize", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Finalize indicates an expected call of Finalize.
+func (mr *MockReindexServiceInterfaceMockRecorder) Finalize(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finalize", reflect.TypeOf((*MockReindexServiceInterface)(nil).Finalize), ctx)
+}
+
+// Rollback mocks base method.
+func (m *MockReindexServiceInterface) Rollback(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rollback indicates an expected call of Rollback.
+func (mr *MockReindexServiceInterfaceMockRecorder) Rollback(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockReindexServiceInterface)(nil).Rollback), ctx)
+}
+
+// Start mocks base method.
+func (m *MockReindexServiceInterface) Start(ctx context.Context, modelName string) (*domain.ReindexJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", ctx, modelName)
+	ret0, _ := ret[0].(*domain.ReindexJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockReindexServiceInterfaceMockRecorder) Start(ctx, modelName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockReindexServiceInterface)(nil).Start), ctx, modelName)
+}
+
+// Status mocks base method.
+func (m *MockReindexServiceInterface) Status(ctx context.Context) (*services.ReindexStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", ctx)
+	ret0, _ := ret[0].(*services.ReindexStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockReindexServiceInterfaceMockRecorder) Status(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockReindexServiceInterface)(nil).Status), ctx)
+}
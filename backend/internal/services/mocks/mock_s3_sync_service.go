@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/s3_sync_service_interface.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	domain "bedrock-rag-sample/backend/internal/domain"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockS3SyncServiceInterface is a mock of S3SyncServiceInterface interface.
+type MockS3SyncServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockS3SyncServiceInterfaceMockRecorder
+}
+
+// MockS3SyncServiceInterfaceMockRecorder is the mock recorder for MockS3SyncServiceInterface.
+type MockS3SyncServiceInterfaceMockRecorder struct {
+	mock *MockS3SyncServiceInterface
+}
+
+// NewMockS3SyncServiceInterface creates a new mock instance.
+func NewMockS3SyncServiceInterface(ctrl *gomock.Controller) *MockS3SyncServiceInterface {
+	mock := &MockS3SyncServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockS3SyncServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockS3SyncServiceInterface) EXPECT() *MockS3SyncServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Run mocks base method.
+func (m *MockS3SyncServiceInterface) Run(ctx context.Context) (*domain.SyncState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx)
+	ret0, _ := ret[0].(*domain.SyncState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockS3SyncServiceInterfaceMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockS3SyncServiceInterface)(nil).Run), ctx)
+}
+
+// Status mocks base method.
+func (m *MockS3SyncServiceInterface) Status(ctx context.Context) (*domain.SyncState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", ctx)
+	ret0, _ := ret[0].(*domain.SyncState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockS3SyncServiceInterfaceMockRecorder) Status(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockS3SyncServiceInterface)(nil).Status), ctx)
+}
+
+// Trigger mocks base method.
+func (m *MockS3SyncServiceInterface) Trigger(ctx context.Context) (*domain.SyncState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trigger", ctx)
+	ret0, _ := ret[0].(*domain.SyncState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Trigger indicates an expected call of Trigger.
+func (mr *MockS3SyncServiceInterfaceMockRecorder) Trigger(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trigger", reflect.TypeOf((*MockS3SyncServiceInterface)(nil).Trigger), ctx)
+}
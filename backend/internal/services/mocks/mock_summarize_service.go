@@ -52,31 +52,31 @@ func (mr *MockSummarizeServiceInterfaceMockRecorder) SummarizeFile(ctx, fileCont
 }
 
 // SummarizeFileByS3Key mocks base method.
-func (m *MockSummarizeServiceInterface) SummarizeFileByS3Key(ctx context.Context, s3Key string) (*services.SummarizeResult, error) {
+func (m *MockSummarizeServiceInterface) SummarizeFileByS3Key(ctx context.Context, s3Key, model string) (*services.SummarizeResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SummarizeFileByS3Key", ctx, s3Key)
+	ret := m.ctrl.Call(m, "SummarizeFileByS3Key", ctx, s3Key, model)
 	ret0, _ := ret[0].(*services.SummarizeResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SummarizeFileByS3Key indicates an expected call of SummarizeFileByS3Key.
-func (mr *MockSummarizeServiceInterfaceMockRecorder) SummarizeFileByS3Key(ctx, s3Key interface{}) *gomock.Call {
+func (mr *MockSummarizeServiceInterfaceMockRecorder) SummarizeFileByS3Key(ctx, s3Key, model interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SummarizeFileByS3Key", reflect.TypeOf((*MockSummarizeServiceInterface)(nil).SummarizeFileByS3Key), ctx, s3Key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SummarizeFileByS3Key", reflect.TypeOf((*MockSummarizeServiceInterface)(nil).SummarizeFileByS3Key), ctx, s3Key, model)
 }
 
 // SummarizeText mocks base method.
-func (m *MockSummarizeServiceInterface) SummarizeText(ctx context.Context, text string) (*services.SummarizeResult, error) {
+func (m *MockSummarizeServiceInterface) SummarizeText(ctx context.Context, text, model string) (*services.SummarizeResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SummarizeText", ctx, text)
+	ret := m.ctrl.Call(m, "SummarizeText", ctx, text, model)
 	ret0, _ := ret[0].(*services.SummarizeResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SummarizeText indicates an expected call of SummarizeText.
-func (mr *MockSummarizeServiceInterfaceMockRecorder) SummarizeText(ctx, text interface{}) *gomock.Call {
+func (mr *MockSummarizeServiceInterfaceMockRecorder) SummarizeText(ctx, text, model interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SummarizeText", reflect.TypeOf((*MockSummarizeServiceInterface)(nil).SummarizeText), ctx, text)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SummarizeText", reflect.TypeOf((*MockSummarizeServiceInterface)(nil).SummarizeText), ctx, text, model)
 }
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/usage_service_interface.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	services "bedrock-rag-sample/backend/internal/services"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockUsageServiceInterface is a mock of UsageServiceInterface interface.
+type MockUsageServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsageServiceInterfaceMockRecorder
+}
+
+// MockUsageServiceInterfaceMockRecorder is the mock recorder for MockUsageServiceInterface.
+type MockUsageServiceInterfaceMockRecorder struct {
+	mock *MockUsageServiceInterface
+}
+
+// NewMockUsageServiceInterface creates a new mock instance.
+func NewMockUsageServiceInterface(ctrl *gomock.Controller) *MockUsageServiceInterface {
+	mock := &MockUsageServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockUsageServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsageServiceInterface) EXPECT() *MockUsageServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Report mocks base method.
+func (m *MockUsageServiceInterface) Report(ctx context.Context, query services.UsageReportQuery) (*services.UsageReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Report", ctx, query)
+	ret0, _ := ret[0].(*services.UsageReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Report indicates an expected call of Report.
+func (mr *MockUsageServiceInterfaceMockRecorder) Report(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockUsageServiceInterface)(nil).Report), ctx, query)
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/web_crawl_service_interface.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	domain "bedrock-rag-sample/backend/internal/domain"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWebCrawlServiceInterface is a mock of WebCrawlServiceInterface interface.
+type MockWebCrawlServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebCrawlServiceInterfaceMockRecorder
+}
+
+// MockWebCrawlServiceInterfaceMockRecorder is the mock recorder for MockWebCrawlServiceInterface.
+type MockWebCrawlServiceInterfaceMockRecorder struct {
+	mock *MockWebCrawlServiceInterface
+}
+
+// NewMockWebCrawlServiceInterface creates a new mock instance.
+func NewMockWebCrawlServiceInterface(ctrl *gomock.Controller) *MockWebCrawlServiceInterface {
+	mock := &MockWebCrawlServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockWebCrawlServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebCrawlServiceInterface) EXPECT() *MockWebCrawlServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Run mocks base method.
+func (m *MockWebCrawlServiceInterface) Run(ctx context.Context) (*domain.SyncState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx)
+	ret0, _ := ret[0].(*domain.SyncState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockWebCrawlServiceInterfaceMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockWebCrawlServiceInterface)(nil).Run), ctx)
+}
+
+// Status mocks base method.
+func (m *MockWebCrawlServiceInterface) Status(ctx context.Context) (*domain.SyncState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", ctx)
+	ret0, _ := ret[0].(*domain.SyncState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockWebCrawlServiceInterfaceMockRecorder) Status(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockWebCrawlServiceInterface)(nil).Status), ctx)
+}
+
+// Trigger mocks base method.
+func (m *MockWebCrawlServiceInterface) Trigger(ctx context.Context) (*domain.SyncState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trigger", ctx)
+	ret0, _ := ret[0].(*domain.SyncState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Trigger indicates an expected call of Trigger.
+func (mr *MockWebCrawlServiceInterfaceMockRecorder) Trigger(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trigger", reflect.TypeOf((*MockWebCrawlServiceInterface)(nil).Trigger), ctx)
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/pkg/redact"
+
+	"github.com/rs/zerolog/log"
+)
+
+// 個人情報の扱いを設定するエンドポイント
+const (
+	RedactEndpointIngest    = "ingest"
+	RedactEndpointSummarize = "summarize"
+	RedactEndpointQA        = "qa"
+	RedactEndpointExtract   = "extract"
+	RedactEndpointRecommend = "recommend"
+)
+
+var redactEndpoints = []string{RedactEndpointIngest, RedactEndpointSummarize, RedactEndpointQA, RedactEndpointExtract, RedactEndpointRecommend}
+
+// ErrPIIDetected は個人情報を拒否する設定のエンドポイントで個人情報を検出した場合のエラー
+var ErrPIIDetected = redact.ErrPIIDetected
+
+// ErrInvalidRedactConfig は個人情報の除去の設定が不正な場合のエラー
+var ErrInvalidRedactConfig = errors.New("個人情報の除去の設定が不正です")
+
+// PIIRedactor はBedrockに送る前のテキストから個人情報を除去し、除去した箇所を監査用に記録する
+// エンドポイントごとに扱い (off / mask / hash / reject) を切り替える
+// nil の場合は何も除去しない
+type PIIRedactor struct {
+	redactor  *redact.Redactor
+	modes     map[string]redact.Mode
+	dbHandler domain.DBHandlerInterface
+}
+
+// NewPIIRedactor は新しいPIIRedactorを作成する
+// 無効な設定の場合は nil を返す。dbHandler が nil の場合は監査の記録を保存しない
+func NewPIIRedactor(cfg config.RedactConfig, dbHandler domain.DBHandlerInterface) (*PIIRedactor, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	defaultMode, err := redact.ParseMode(cfg.DefaultMode)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRedactConfig, err)
+	}
+	modes := make(map[string]redact.Mode, len(redactEndpoints))
+	for _, endpoint := range redactEndpoints {
+		modes[endpoint] = defaultMode
+	}
+	for endpoint, value := range cfg.Modes {
+		if _, ok := modes[endpoint]; !ok {
+			return nil, fmt.Errorf("%w: 不明なエンドポイントです: %s", ErrInvalidRedactConfig, endpoint)
+		}
+		mode, err := redact.ParseMode(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidRedactConfig, endpoint, err)
+		}
+		modes[endpoint] = mode
+	}
+	for endpoint, mode := range modes {
+		if mode == redact.ModeHash && cfg.HashKey == "" {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidRedactConfig, endpoint, redact.ErrHashKeyRequired)
+		}
+	}
+
+	var detectors []redact.Detector
+	for _, name := range cfg.Detectors {
+		d, err := redact.Builtin(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRedactConfig, err)
+		}
+		detectors = append(detectors, d)
+	}
+	if cfg.DictionaryFile != "" {
+		d, err := redact.LoadDictionary(cfg.DictionaryFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRedactConfig, err)
+		}
+		detectors = append(detectors, d)
+	}
+
+	return &PIIRedactor{
+		redactor:  redact.New(cfg.HashKey, detectors...),
+		modes:     modes,
+		dbHandler: dbHandler,
+	}, nil
+}
+
+// Redact はエンドポイントの扱いに従ってテキストの個人情報を除去する (監査の記録は保存しない)
+// 拒否する設定で個人情報を検出した場合は ErrPIIDetected を返す
+func (r *PIIRedactor) Redact(endpoint, text string) (*redact.Result, error) {
+	if r == nil {
+		return &redact.Result{Text: text}, nil
+	}
+	return r.redactor.Redact(text, r.modes[endpoint])
+}
+
+// RedactText はテキストの個人情報を除去し、除去した箇所を監査用に記録する
+func (r *PIIRedactor) RedactText(ctx context.Context, endpoint, text string) (string, error) {
+	result, err := r.Redact(endpoint, text)
+	if err != nil {
+		return "", err
+	}
+	r.Audit(ctx, endpoint, nil, result.Findings)
+	return result.Text, nil
+}
+
+// MaskText は検索で取得した文書など、利用者が選べないテキストの個人情報を除去する
+// 拒否する設定の場合も拒否はせず伏せ字に置き換える
+func (r *PIIRedactor) MaskText(ctx context.Context, endpoint, text string) (string, error) {
+	if r == nil {
+		return text, nil
+	}
+	mode := r.modes[endpoint]
+	if mode == redact.ModeReject {
+		mode = redact.ModeMask
+	}
+	result, err := r.redactor.Redact(text, mode)
+	if err != nil {
+		return "", err
+	}
+	r.Audit(ctx, endpoint, nil, result.Findings)
+	return result.Text, nil
+}
+
+// Audit は除去した個人情報を監査用に記録する
+// 記録に失敗しても除去したテキストは使えるため、エラーはログに残すのみとする
+func (r *PIIRedactor) Audit(ctx context.Context, endpoint string, documentID *int64, findings []redact.Finding) {
+	if r == nil || len(findings) == 0 {
+		return
+	}
+
+	scope := UsageScopeFromContext(ctx)
+	records := make([]domain.PIIRedaction, 0, len(findings))
+	for _, f := range findings {
+		records = append(records, domain.PIIRedaction{
+			Endpoint:   endpoint,
+			DocumentID: documentID,
+			RequestID:  scope.RequestID,
+			Tenant:     scope.Tenant,
+			Type:       f.Type,
+			Start:      f.Start,
+			End:        f.End,
+			ValueHash:  f.ValueHash,
+		})
+	}
+	log.Info().Str("endpoint", endpoint).Int("findings", len(findings)).Msg("Redacted PII before sending to Bedrock")
+
+	if r.dbHandler == nil {
+		return
+	}
+	if err := r.dbHandler.SaveRedactionAudit(ctx, records); err != nil {
+		log.Error().Err(err).Str("endpoint", endpoint).Msg("Failed to save PII redaction audit")
+	}
+}
@@ -0,0 +1,141 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	domainmocks "bedrock-rag-sample/backend/internal/domain/mocks"
+	"bedrock-rag-sample/backend/internal/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPIIRedactor(t *testing.T) {
+	t.Run("正常系: 無効な場合は除去しない", func(t *testing.T) {
+		redactor, err := services.NewPIIRedactor(config.RedactConfig{DefaultMode: "reject"}, nil)
+
+		require.NoError(t, err)
+		assert.Nil(t, redactor)
+		text, err := redactor.RedactText(context.Background(), services.RedactEndpointQA, "090-1234-5678")
+		require.NoError(t, err)
+		assert.Equal(t, "090-1234-5678", text)
+	})
+
+	t.Run("正常系: 辞書の語も除去する", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dictionary.tsv")
+		require.NoError(t, os.WriteFile(path, []byte("customer\t株式会社サンプル\n"), 0o600))
+
+		redactor, err := services.NewPIIRedactor(config.RedactConfig{Enabled: true, DefaultMode: "mask", DictionaryFile: path}, nil)
+		require.NoError(t, err)
+		result, err := redactor.Redact(services.RedactEndpointIngest, "株式会社サンプル様との契約")
+
+		require.NoError(t, err)
+		assert.Equal(t, "[CUSTOMER]様との契約", result.Text)
+	})
+
+	tests := []struct {
+		name string
+		cfg  config.RedactConfig
+	}{
+		{"異常系: 不明な扱い", config.RedactConfig{Enabled: true, DefaultMode: "drop"}},
+		{"異常系: 不明なエンドポイント", config.RedactConfig{Enabled: true, DefaultMode: "mask", Modes: map[string]string{"upload": "reject"}}},
+		{"異常系: 鍵を設定せずに hash を指定した", config.RedactConfig{Enabled: true, DefaultMode: "mask", Modes: map[string]string{"qa": "hash"}}},
+		{"異常系: 不明な検出器", config.RedactConfig{Enabled: true, DefaultMode: "mask", Detectors: []string{"address"}}},
+		{"異常系: 辞書のファイルがない", config.RedactConfig{Enabled: true, DefaultMode: "mask", DictionaryFile: filepath.Join(t.TempDir(), "missing.tsv")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := services.NewPIIRedactor(tt.cfg, nil)
+
+			assert.ErrorIs(t, err, services.ErrInvalidRedactConfig)
+		})
+	}
+}
+
+func TestPIIRedactor_RedactText(t *testing.T) {
+	cfg := config.RedactConfig{
+		Enabled:     true,
+		DefaultMode: "mask",
+		Modes:       map[string]string{"qa": "reject", "extract": "hash", "recommend": "off"},
+		Detectors:   []string{"phone", "email"},
+		HashKey:     "secret",
+	}
+	ctx := services.WithUsageScope(context.Background(), services.UsageScope{RequestID: "req-1", Tenant: "tenant-a", Endpoint: "/api/v1/summarize/text"})
+	text := "担当 090-1234-5678"
+
+	t.Run("正常系: エンドポイントの扱いで除去し、リクエストの情報とともに記録する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		redactor, err := services.NewPIIRedactor(cfg, mockDBHandler)
+		require.NoError(t, err)
+
+		mockDBHandler.EXPECT().SaveRedactionAudit(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, records []domain.PIIRedaction) error {
+			require.Len(t, records, 1)
+			assert.Equal(t, domain.PIIRedaction{
+				Endpoint: services.RedactEndpointSummarize, RequestID: "req-1", Tenant: "tenant-a",
+				Type: "phone", Start: 3, End: 16, ValueHash: records[0].ValueHash,
+			}, records[0])
+			assert.Len(t, records[0].ValueHash, 64)
+			return nil
+		})
+
+		masked, err := redactor.RedactText(ctx, services.RedactEndpointSummarize, text)
+
+		require.NoError(t, err)
+		assert.Equal(t, "担当 [PHONE]", masked)
+	})
+
+	t.Run("正常系: エンドポイントごとに扱いを切り替える", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		redactor, err := services.NewPIIRedactor(cfg, mockDBHandler)
+		require.NoError(t, err)
+
+		mockDBHandler.EXPECT().SaveRedactionAudit(gomock.Any(), gomock.Any()).Return(nil)
+
+		hashed, err := redactor.RedactText(ctx, services.RedactEndpointExtract, text)
+		require.NoError(t, err)
+		assert.Regexp(t, `^担当 \[PHONE:[0-9a-f]{12}\]$`, hashed)
+
+		unchanged, err := redactor.RedactText(ctx, services.RedactEndpointRecommend, text)
+		require.NoError(t, err)
+		assert.Equal(t, text, unchanged)
+
+		_, err = redactor.RedactText(ctx, services.RedactEndpointQA, text)
+		assert.ErrorIs(t, err, services.ErrPIIDetected)
+	})
+
+	t.Run("正常系: 検索した文書は拒否する設定でも伏せ字にする", func(t *testing.T) {
+		redactor, err := services.NewPIIRedactor(cfg, nil)
+		require.NoError(t, err)
+
+		masked, err := redactor.MaskText(ctx, services.RedactEndpointQA, text)
+
+		require.NoError(t, err)
+		assert.Equal(t, "担当 [PHONE]", masked)
+	})
+
+	t.Run("正常系: 記録に失敗しても除去したテキストを返す", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		redactor, err := services.NewPIIRedactor(cfg, mockDBHandler)
+		require.NoError(t, err)
+
+		mockDBHandler.EXPECT().SaveRedactionAudit(gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+
+		masked, err := redactor.RedactText(ctx, services.RedactEndpointIngest, "連絡先 taro@example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, "連絡先 [EMAIL]", masked)
+	})
+}
@@ -7,10 +7,12 @@ import (
 	"strings"
 
 	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
 	"bedrock-rag-sample/backend/pkg/aws"
 
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockagent"
+	"github.com/rs/zerolog/log"
 )
 
 // QAService はQ&A処理を行うサービス
@@ -18,10 +20,15 @@ type QAService struct {
 	bedrockClient aws.BedrockClientInterface
 	kbID          string
 	agentClient   *bedrockagent.Client
+	models        *TextModelRouter
+	cache         *AnswerCache
+	redactor      *PIIRedactor
 }
 
 // NewQAService は新しいQAServiceを作成する
-func NewQAService(bedrockClient aws.BedrockClientInterface, cfg *config.Config) (*QAService, error) {
+// models が nil の場合はモデルの指定を受け付けず、bedrockClient の既定モデルを使用する
+// cache が nil の場合は回答をキャッシュしない。redactor が nil の場合は質問と検索した文書から個人情報を除去しない
+func NewQAService(bedrockClient aws.BedrockClientInterface, cfg *config.Config, models *TextModelRouter, cache *AnswerCache, redactor *PIIRedactor) (*QAService, error) {
 	// AWSクライアントの初期化
 	awsCfg, err := awsconfig.LoadDefaultConfig(context.TODO(), awsconfig.WithRegion(cfg.AWS.Region))
 	if err != nil {
@@ -37,6 +44,9 @@ func NewQAService(bedrockClient aws.BedrockClientInterface, cfg *config.Config)
 		bedrockClient: bedrockClient,
 		kbID:          cfg.AWS.KnowledgeBaseID,
 		agentClient:   bedrockagent.NewFromConfig(awsCfg),
+		models:        models,
+		cache:         cache,
+		redactor:      redactor,
 	}, nil
 }
 
@@ -53,15 +63,46 @@ type QAResult struct {
 	Query              string              `json:"query"`
 	Answer             string              `json:"answer"`
 	RetrievedDocuments []RetrievedDocument `json:"retrieved_documents,omitempty"`
+	Model              string              `json:"model,omitempty"` // 回答の生成に使用したモデルのカタログ名
+	Cached             bool                `json:"cached"`          // 類似した過去の質問の回答をキャッシュから返した場合は true
 }
 
 // SimpleRAG はシンプルなRAG（Retrieval Augmented Generation）を実行する
 // 直接BedrockのLLMを利用する簡易実装
-func (s *QAService) SimpleRAG(ctx context.Context, query string) (*QAResult, error) {
+// model が空の場合はQAエンドポイントの既定モデルを使用する
+// 回答キャッシュが有効な場合は、クエリEmbeddingが類似した過去の質問の回答を検索・生成なしで返す
+// 個人情報の除去を設定した場合は、質問から除去してから検索・生成し、検索した文書の個人情報も伏せ字にする
+func (s *QAService) SimpleRAG(ctx context.Context, query, model string) (*QAResult, error) {
 	// クエリとシステムが空ではないことを確認
 	if query == "" {
 		return nil, errors.New("クエリが空です")
 	}
+	query, err := s.redactor.RedactText(ctx, RedactEndpointQA, query)
+	if err != nil {
+		return nil, err
+	}
+
+	client, modelName, err := textClientFor(s.bedrockClient, s.models, EndpointQA, model)
+	if err != nil {
+		return nil, err
+	}
+
+	var cacheModel string
+	var space domain.EmbeddingSpace
+	var embedding []float32
+	if s.cache != nil {
+		// モデルの選択が設定されていない場合もモデルごとに分けられるよう、クライアントのモデル名をキーにする
+		cacheModel = client.TextModel().Name
+		embeddingModel := s.bedrockClient.EmbeddingModel()
+		space = domain.EmbeddingSpace{Model: embeddingModel.Name, Dimension: embeddingModel.Dimension}
+		embedding, err = s.bedrockClient.GenerateEmbedding(ctx, query)
+		if err != nil {
+			// キャッシュを使わずに回答を生成する
+			log.Warn().Err(err).Msg("Failed to embed query for answer cache")
+		} else if cached, ok := s.cache.Lookup(ctx, query, cacheModel, space, embedding); ok {
+			return cached, nil
+		}
+	}
 
 	// 関連ドキュメントの検索
 	docs, err := s.retrieveDocuments(ctx, query)
@@ -69,21 +110,43 @@ func (s *QAService) SimpleRAG(ctx context.Context, query string) (*QAResult, err
 		// 検索エラーは記録するが処理は続行（ドキュメントなしで回答を生成）
 		docs = []RetrievedDocument{}
 	}
+	for i := range docs {
+		if docs[i].Content, err = s.redactor.MaskText(ctx, RedactEndpointQA, docs[i].Content); err != nil {
+			return nil, err
+		}
+	}
 
 	// RAGプロンプトの構築
 	ragPrompt := buildRAGPrompt(query, docs)
 
 	// LLMで回答を生成
-	answer, err := s.bedrockClient.GenerateText(ctx, ragPrompt)
+	answer, err := client.GenerateText(ctx, ragPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("回答の生成に失敗しました: %w", err)
 	}
 
-	return &QAResult{
+	result := &QAResult{
 		Query:              query,
 		Answer:             answer,
 		RetrievedDocuments: docs,
-	}, nil
+		Model:              modelName,
+	}
+	if s.cache != nil && embedding != nil {
+		s.cache.Store(ctx, cacheModel, space, embedding, result)
+	}
+	return result, nil
+}
+
+// InvalidateAnswerCache は回答キャッシュを破棄し、破棄した件数を返す
+// documentID を指定した場合はそのドキュメントを根拠にした回答のみを破棄する。キャッシュが無効な場合は何もしない
+func (s *QAService) InvalidateAnswerCache(ctx context.Context, documentID string) (int64, error) {
+	if s.cache == nil {
+		return 0, nil
+	}
+	if documentID != "" {
+		return s.cache.InvalidateDocument(ctx, documentID)
+	}
+	return s.cache.Invalidate(ctx)
 }
 
 // retrieveDocuments はKnowledge Baseから関連ドキュメントを検索する
@@ -106,11 +169,10 @@ func (s *QAService) retrieveDocuments(ctx context.Context, query string) ([]Retr
 }
 
 // buildRAGPrompt はRAG用のプロンプトを構築する
+// ロールの形式 (Human/Assistant など) はモデルごとのコーデックで整形するため、本文のみを組み立てる
 func buildRAGPrompt(query string, docs []RetrievedDocument) string {
 	var sb strings.Builder
 
-	sb.WriteString("<human>")
-
 	// コンテキスト情報が存在する場合は追加
 	if len(docs) > 0 {
 		sb.WriteString("以下は質問に関連する情報です:\n\n")
@@ -122,7 +184,6 @@ func buildRAGPrompt(query string, docs []RetrievedDocument) string {
 
 	// 質問を追加
 	sb.WriteString(fmt.Sprintf("質問: %s", query))
-	sb.WriteString("</human>\n\n<assistant>")
 
 	return sb.String()
 }
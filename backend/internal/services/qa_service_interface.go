@@ -6,7 +6,8 @@ import (
 
 // QAServiceInterface はQAサービスのインターフェース
 type QAServiceInterface interface {
-	SimpleRAG(ctx context.Context, query string) (*QAResult, error)
+	SimpleRAG(ctx context.Context, query, model string) (*QAResult, error)
+	InvalidateAnswerCache(ctx context.Context, documentID string) (int64, error)
 	// 他の QAService メソッドが必要であればここに追加
 }
 
@@ -19,7 +19,6 @@ import (
 // Helper function to build the expected RAG prompt based on current logic
 func buildExpectedRAGPrompt(query string, docs []services.RetrievedDocument) string {
 	var sb strings.Builder
-	sb.WriteString("<human>")
 	if len(docs) > 0 {
 		sb.WriteString("以下は質問に関連する情報です:\n\n")
 		for i, doc := range docs {
@@ -27,7 +26,6 @@ func buildExpectedRAGPrompt(query string, docs []services.RetrievedDocument) str
 		}
 	}
 	sb.WriteString(fmt.Sprintf("質問: %s", query))
-	sb.WriteString("</human>\n\n<assistant>")
 	return sb.String()
 }
 
@@ -43,7 +41,7 @@ func TestQAService_NewQAService(t *testing.T) {
 				KnowledgeBaseID: "test-kb-id",
 			},
 		}
-		qas, err := services.NewQAService(mockBedrockClient, cfg)
+		qas, err := services.NewQAService(mockBedrockClient, cfg, nil, nil, nil)
 		assert.NoError(t, err)
 		assert.NotNil(t, qas)
 	})
@@ -55,7 +53,7 @@ func TestQAService_NewQAService(t *testing.T) {
 				// KnowledgeBaseID: "", // KB ID is empty
 			},
 		}
-		qas, err := services.NewQAService(mockBedrockClient, cfg)
+		qas, err := services.NewQAService(mockBedrockClient, cfg, nil, nil, nil)
 		assert.Error(t, err)
 		assert.Nil(t, qas)
 		assert.Contains(t, err.Error(), "knowledge Base IDが設定されていません")
@@ -79,7 +77,7 @@ func TestQAService_SimpleRAG(t *testing.T) {
 	}
 
 	// NewQAService を使ってインスタンスを生成 (bedrockClient はモック)
-	qas, err := services.NewQAService(mockBedrockClient, cfg)
+	qas, err := services.NewQAService(mockBedrockClient, cfg, nil, nil, nil)
 	require.NoError(t, err) // テストの前提条件としてエラーがないことを確認
 	require.NotNil(t, qas)
 
@@ -100,7 +98,7 @@ func TestQAService_SimpleRAG(t *testing.T) {
 			Return(expectedAnswer, nil).
 			Times(1)
 
-		result, err := qas.SimpleRAG(ctx, query)
+		result, err := qas.SimpleRAG(ctx, query, "")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
@@ -110,7 +108,7 @@ func TestQAService_SimpleRAG(t *testing.T) {
 	})
 
 	t.Run("異常系_クエリが空", func(t *testing.T) {
-		result, err := qas.SimpleRAG(ctx, "")
+		result, err := qas.SimpleRAG(ctx, "", "")
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -131,7 +129,7 @@ func TestQAService_SimpleRAG(t *testing.T) {
 			Return("", bedrockError).
 			Times(1)
 
-		result, err := qas.SimpleRAG(ctx, query)
+		result, err := qas.SimpleRAG(ctx, query, "")
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -139,6 +137,37 @@ func TestQAService_SimpleRAG(t *testing.T) {
 		assert.Contains(t, err.Error(), "回答の生成に失敗しました")
 	})
 
+	t.Run("正常系: 個人情報を除去した質問で回答を生成する", func(t *testing.T) {
+		redactor, err := services.NewPIIRedactor(config.RedactConfig{Enabled: true, DefaultMode: "mask", Detectors: []string{"phone"}}, nil)
+		require.NoError(t, err)
+		svc, err := services.NewQAService(mockBedrockClient, cfg, nil, nil, redactor)
+		require.NoError(t, err)
+
+		mockBedrockClient.EXPECT().
+			GenerateText(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, prompt string) (string, error) {
+				assert.Contains(t, prompt, "質問: [PHONE] の契約者は？")
+				assert.NotContains(t, prompt, "090-1234-5678")
+				return "回答", nil
+			})
+
+		result, err := svc.SimpleRAG(ctx, "090-1234-5678 の契約者は？", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "[PHONE] の契約者は？", result.Query)
+	})
+
+	t.Run("異常系: 個人情報を拒否する設定では回答を生成しない", func(t *testing.T) {
+		redactor, err := services.NewPIIRedactor(config.RedactConfig{Enabled: true, DefaultMode: "reject", Detectors: []string{"phone"}}, nil)
+		require.NoError(t, err)
+		svc, err := services.NewQAService(mockBedrockClient, cfg, nil, nil, redactor)
+		require.NoError(t, err)
+
+		_, err = svc.SimpleRAG(ctx, "090-1234-5678 の契約者は？", "")
+
+		assert.ErrorIs(t, err, services.ErrPIIDetected)
+	})
+
 	// retrieveDocuments がエラーを返すケースのテストも追加可能だが、
 	// 現在の実装ではエラーを無視して空のドキュメントで続行するため、
 	// SimpleRAG レベルでは正常系と同じような動作になる。
@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"bedrock-rag-sample/backend/internal/domain"
 	"bedrock-rag-sample/backend/pkg/aws"
@@ -13,13 +15,17 @@ import (
 type RecommendService struct {
 	bedrockClient aws.BedrockClientInterface
 	dbHandler     domain.DBHandlerInterface
+	redactor      *PIIRedactor
 }
 
 // NewRecommendService は新しいRecommendServiceを作成する
-func NewRecommendService(bedrockClient aws.BedrockClientInterface, dbHandler domain.DBHandlerInterface) *RecommendService {
+// redactor が nil の場合は検索クエリから個人情報を除去しない
+// (チャンクにするドキュメントの本文は取り込み時に除去する)
+func NewRecommendService(bedrockClient aws.BedrockClientInterface, dbHandler domain.DBHandlerInterface, redactor *PIIRedactor) *RecommendService {
 	return &RecommendService{
 		bedrockClient: bedrockClient,
 		dbHandler:     dbHandler,
+		redactor:      redactor,
 	}
 }
 
@@ -36,43 +42,232 @@ type RecommendResult struct {
 	Documents         map[int64]*domain.Document `json:"documents,omitempty"`
 }
 
+// ingestRetryRounds は失敗したチャンクのEmbedding生成をやり直す最大ラウンド数 (初回を含む)
+const ingestRetryRounds = 3
+
+// embeddingSpaceChangeAttempts は保存中に検索用のモデルが切り替わった場合に、Embeddingを生成し直して保存する最大回数 (初回を含む)
+const embeddingSpaceChangeAttempts = 3
+
+// ingestRetryPolicy はラウンド間の待機時間の方針
+// 個々の呼び出しはBedrockクライアント側でも再試行されるため、ここではサーキットブレーカーの復帰を待てる程度の間隔を空ける
+var ingestRetryPolicy = aws.RetryPolicy{BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second}
+
 // ProcessDocumentForEmbedding はドキュメントをチャンクに分割し、Embeddingを生成する
+// 一時的な障害で失敗したチャンクだけを再試行し、全チャンクのEmbeddingが揃った場合のみまとめて保存する
+// Embeddingモデルの切り替え中は、切り替え先 (または切り替え前) のモデルのEmbeddingも合わせて保存する
 func (s *RecommendService) ProcessDocumentForEmbedding(ctx context.Context, doc *domain.Document) error {
 	// ドキュメントをチャンクに分割
 	chunks := s.splitIntoChunks(doc.Content)
+	if len(chunks) == 0 {
+		return nil
+	}
 
-	// 各チャンクのEmbeddingを生成し保存
+	texts := make([]string, len(chunks))
 	for i, chunk := range chunks {
-		// Embeddingを生成
-		embedding, err := s.bedrockClient.GenerateEmbedding(ctx, chunk)
+		texts[i] = chunk.content
+	}
+
+	// 保存するまでの間に検索用のモデルが切り替わった場合は、切り替え後のモデルで生成し直す
+	for attempt := 1; ; attempt++ {
+		state, err := s.dbHandler.GetEmbeddingIndexState(ctx, s.defaultEmbeddingSpace())
 		if err != nil {
-			return fmt.Errorf("embedding生成に失敗しました: %w", err)
+			return fmt.Errorf("embeddingモデルの状態の取得に失敗しました: %w", err)
 		}
 
-		// 生成したEmbeddingを保存
-		_, err = s.dbHandler.SaveDocumentEmbedding(ctx, doc.ID, chunk, i, embedding)
+		client, err := embeddingClientFor(s.bedrockClient, state.Active)
+		if err != nil {
+			return err
+		}
+		embeddings, err := embedChunks(ctx, client, texts)
 		if err != nil {
+			return err
+		}
+
+		// 切り替え先・切り戻し先のモデルがある場合は、そのEmbeddingもチャンクと一緒に保存する
+		shadow, err := s.embedShadow(ctx, doc.ID, state.Secondary(), texts)
+		if err != nil {
+			return err
+		}
+
+		// 全チャンクが揃ってからまとめて保存する (途中までのチャンクが残らないようにする)
+		records := make([]domain.ChunkEmbedding, len(chunks))
+		for i, chunk := range chunks {
+			records[i] = domain.ChunkEmbedding{
+				ChunkIndex: i,
+				Content:    chunk.content,
+				StartLine:  chunk.startLine,
+				EndLine:    chunk.endLine,
+				Embedding:  embeddings[i],
+			}
+		}
+		err = s.dbHandler.SaveDocumentChunks(ctx, doc.ID, state.Active, records, shadow)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, domain.ErrEmbeddingSpaceChanged) || attempt >= embeddingSpaceChangeAttempts {
 			return fmt.Errorf("embeddingの保存に失敗しました: %w", err)
 		}
 	}
+}
 
-	return nil
+// embedShadow は検索用以外のモデル (secondary) で全チャンクのEmbeddingを生成する (secondary が nil の場合は nil を返す)
+func (s *RecommendService) embedShadow(ctx context.Context, documentID int64, secondary *domain.EmbeddingSpace, texts []string) (*domain.ShadowEmbeddingSet, error) {
+	if secondary == nil {
+		return nil, nil
+	}
+	client, err := embeddingClientFor(s.bedrockClient, *secondary)
+	if err != nil {
+		return nil, err
+	}
+	embeddings, err := embedChunks(ctx, client, texts)
+	if err != nil {
+		return nil, fmt.Errorf("切り替え用のembedding生成に失敗しました (model: %s): %w", secondary.Model, err)
+	}
+	shadow := &domain.ShadowEmbeddingSet{Space: *secondary, Embeddings: make([]domain.ShadowEmbedding, len(texts))}
+	for i := range texts {
+		shadow.Embeddings[i] = domain.ShadowEmbedding{DocumentID: documentID, ChunkIndex: i, Embedding: embeddings[i]}
+	}
+	return shadow, nil
+}
+
+// embedChunks は全チャンクのEmbeddingを生成する
+// 一時的な障害で失敗したチャンクだけを次のラウンドで再試行し、回復しないエラーの場合は即座に返す
+func embedChunks(ctx context.Context, client aws.BedrockClientInterface, chunks []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(chunks))
+	pending := make([]int, len(chunks))
+	for i := range chunks {
+		pending[i] = i
+	}
+
+	for round := 0; len(pending) > 0; round++ {
+		texts := make([]string, len(pending))
+		for j, i := range pending {
+			texts[j] = chunks[i]
+		}
+
+		// 未処理のチャンクをまとめて並行生成し、失敗したチャンクだけを次のラウンドに回す
+		results, err := client.GenerateEmbeddings(ctx, texts)
+		if err == nil && len(results) != len(pending) {
+			return nil, fmt.Errorf("embedding生成結果の件数が一致しません (入力: %d, 出力: %d)", len(pending), len(results))
+		}
+		for j, i := range pending {
+			if j < len(results) && results[j] != nil {
+				embeddings[i] = results[j]
+			}
+		}
+
+		var failed []int
+		if err != nil {
+			failedIndices, cause := splitEmbeddingsError(err, len(pending))
+			if !isRetryableIngestError(cause) {
+				return nil, fmt.Errorf("embedding生成に失敗しました (chunk: %d): %w", pending[failedIndices[0]], cause)
+			}
+			for _, j := range failedIndices {
+				failed = append(failed, pending[j])
+			}
+		}
+		lastErr := err
+
+		if len(failed) == 0 {
+			break
+		}
+		if round+1 >= ingestRetryRounds {
+			return nil, fmt.Errorf("embedding生成に失敗しました (%d/%d チャンク): %w", len(failed), len(chunks), lastErr)
+		}
+
+		timer := time.NewTimer(ingestRetryPolicy.Backoff(round))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("embedding生成が中断されました: %w", ctx.Err())
+		case <-timer.C:
+		}
+		pending = failed
+	}
+
+	return embeddings, nil
+}
+
+// defaultEmbeddingSpace は設定で指定されたEmbeddingモデルを返す
+// 検索に使用するモデルが未記録の場合 (初回起動時) のみ使用し、以降のモデル変更は再インデックスで行う
+func (s *RecommendService) defaultEmbeddingSpace() domain.EmbeddingSpace {
+	model := s.bedrockClient.EmbeddingModel()
+	return domain.EmbeddingSpace{Model: model.Name, Dimension: model.Dimension}
+}
+
+// embeddingClientFor は space のモデルでEmbeddingを生成するクライアントを返す
+func embeddingClientFor(client aws.BedrockClientInterface, space domain.EmbeddingSpace) (aws.BedrockClientInterface, error) {
+	if client.EmbeddingModel().Name == space.Model {
+		return client, nil
+	}
+	model, err := aws.LookupEmbeddingModel(space.Model)
+	if err != nil {
+		return nil, err
+	}
+	if model.Dimension != space.Dimension {
+		return nil, fmt.Errorf("%w: model %s has %d dimensions, index expects %d", domain.ErrEmbeddingDimensionMismatch, model.Name, model.Dimension, space.Dimension)
+	}
+	return client.WithEmbeddingModel(model), nil
+}
+
+// splitEmbeddingsError は GenerateEmbeddings のエラーから失敗した入力の位置と原因を取り出す
+// 一部失敗の情報を持たないエラーの場合は、すべての入力が失敗したものとして扱う
+func splitEmbeddingsError(err error, n int) ([]int, error) {
+	var embErr *aws.EmbeddingsError
+	if errors.As(err, &embErr) && len(embErr.FailedIndices) > 0 {
+		return embErr.FailedIndices, embErr.Err
+	}
+	all := make([]int, n)
+	for i := range all {
+		all[i] = i
+	}
+	return all, err
+}
+
+// isRetryableIngestError はインジェスト時に後のラウンドで再試行すべきエラーかを判定する
+// サーキットブレーカーによる遮断も、時間を置けば回復が見込めるため再試行の対象とする
+func isRetryableIngestError(err error) bool {
+	return aws.IsRetryableError(err) || errors.Is(err, aws.ErrCircuitOpen)
 }
 
 // FindSimilarDocuments はクエリに類似したドキュメントを検索する
-func (s *RecommendService) FindSimilarDocuments(ctx context.Context, query string, limit int) (*RecommendResult, error) {
+// 既定では最新の版のドキュメントのみを対象とし、filter.AllVersions で過去の版も含める
+// filter.Category と filter.Tags で分類による絞り込みができる (タグは保存時と同じく正規化して比較する)
+func (s *RecommendService) FindSimilarDocuments(ctx context.Context, query string, limit int, filter domain.ChunkFilter) (*RecommendResult, error) {
 	if limit <= 0 {
 		limit = 5 // デフォルト値
 	}
+	filter.Category = strings.TrimSpace(filter.Category)
+	if len(filter.Tags) > 0 {
+		tags, err := NormalizeTags(filter.Tags)
+		if err != nil {
+			return nil, err
+		}
+		filter.Tags = tags
+	}
+	query, err := s.redactor.RedactText(ctx, RedactEndpointRecommend, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// 検索用の列と同じモデルでクエリのEmbeddingを生成する
+	state, err := s.dbHandler.GetEmbeddingIndexState(ctx, s.defaultEmbeddingSpace())
+	if err != nil {
+		return nil, fmt.Errorf("embeddingモデルの状態の取得に失敗しました: %w", err)
+	}
+	client, err := embeddingClientFor(s.bedrockClient, state.Active)
+	if err != nil {
+		return nil, err
+	}
 
 	// クエリのEmbeddingを生成
-	queryEmbedding, err := s.bedrockClient.GenerateEmbedding(ctx, query)
+	queryEmbedding, err := client.GenerateEmbedding(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("クエリのEmbedding生成に失敗しました: %w", err)
 	}
 
 	// 類似したチャンクを検索
-	chunks, err := s.dbHandler.FindSimilarChunks(ctx, queryEmbedding, limit)
+	chunks, err := s.dbHandler.FindSimilarChunks(ctx, state.Active, queryEmbedding, limit, filter)
 	if err != nil {
 		return nil, fmt.Errorf("類似チャンクの検索に失敗しました: %w", err)
 	}
@@ -96,29 +291,54 @@ func (s *RecommendService) FindSimilarDocuments(ctx context.Context, query strin
 		}
 	}
 
+	// 取り込み元のあるドキュメントのチャンクには引用元 (gitリポジトリの場合はファイルと行範囲) を示す
+	for i, chunk := range result.RecommendedChunks {
+		result.RecommendedChunks[i].Citation = chunkCitation(result.Documents[chunk.DocumentID], chunk)
+	}
+
 	return result, nil
 }
 
+// textChunk はドキュメントから切り出したチャンクと、元のテキストでの行範囲 (1始まり) を表す
+type textChunk struct {
+	content   string
+	startLine int
+	endLine   int
+}
+
 // splitIntoChunks はテキストをチャンクに分割する
-func (s *RecommendService) splitIntoChunks(text string) []string {
-	var chunks []string
+// 各チャンクには引用元として示せるよう、元のテキストでの開始行と終了行を記録する
+func (s *RecommendService) splitIntoChunks(text string) []textChunk {
+	var chunks []textChunk
 
 	// 単純な行分割（より洗練された分割方法も実装可能）
 	paragraphs := strings.Split(text, "\n\n")
 
 	var currentChunk strings.Builder
+	var current textChunk
+	offset := 0
+	line := 1
 
 	for _, para := range paragraphs {
+		paraStart := offset
+		startLine := line
+		offset += len(para) + len("\n\n")
+		line += strings.Count(para, "\n") + 2
+
 		// 段落が空でない場合のみ処理
 		trimmedPara := strings.TrimSpace(para)
 		if trimmedPara == "" {
 			continue
 		}
+		leading := strings.Index(para, trimmedPara)
+		paraStartLine := startLine + strings.Count(text[paraStart:paraStart+leading], "\n")
+		paraEndLine := paraStartLine + strings.Count(trimmedPara, "\n")
 
 		// 現在のチャンクに追加すると長すぎる場合は、新しいチャンクを開始
 		if currentChunk.Len()+len(trimmedPara) > ChunkSize {
 			if currentChunk.Len() > 0 {
-				chunks = append(chunks, currentChunk.String())
+				current.content = currentChunk.String()
+				chunks = append(chunks, current)
 				currentChunk.Reset()
 
 				// 最大チャンク数に達した場合は終了
@@ -131,13 +351,17 @@ func (s *RecommendService) splitIntoChunks(text string) []string {
 		// 段落を追加
 		if currentChunk.Len() > 0 {
 			currentChunk.WriteString("\n\n")
+		} else {
+			current.startLine = paraStartLine
 		}
 		currentChunk.WriteString(trimmedPara)
+		current.endLine = paraEndLine
 	}
 
 	// 最後のチャンクを追加
 	if currentChunk.Len() > 0 {
-		chunks = append(chunks, currentChunk.String())
+		current.content = currentChunk.String()
+		chunks = append(chunks, current)
 	}
 
 	return chunks
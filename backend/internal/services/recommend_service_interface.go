@@ -9,7 +9,7 @@ import (
 // RecommendServiceInterface は推薦サービスのインターフェース
 type RecommendServiceInterface interface {
 	ProcessDocumentForEmbedding(ctx context.Context, doc *domain.Document) error
-	FindSimilarDocuments(ctx context.Context, query string, limit int) (*RecommendResult, error)
+	FindSimilarDocuments(ctx context.Context, query string, limit int, filter domain.ChunkFilter) (*RecommendResult, error)
 	// 他の RecommendService メソッドが必要であればここに追加
 }
 
@@ -3,13 +3,18 @@ package services_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
+	"bedrock-rag-sample/backend/config"
 	"bedrock-rag-sample/backend/internal/domain"
 	domainmocks "bedrock-rag-sample/backend/internal/domain/mocks" // DB モック
 	"bedrock-rag-sample/backend/internal/services"
 	servicemocks "bedrock-rag-sample/backend/internal/services/mocks" // Bedrock モック
+	"bedrock-rag-sample/backend/pkg/aws"
 
+	"github.com/aws/smithy-go"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -23,7 +28,12 @@ func TestRecommendService_FindSimilarDocuments(t *testing.T) {
 	mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
 
 	// テスト対象サービス生成
-	recommendService := services.NewRecommendService(mockBedrockClient, mockDBHandler)
+	recommendService := services.NewRecommendService(mockBedrockClient, mockDBHandler, nil)
+
+	embeddingModel := aws.EmbeddingModel{Name: "titan-v2-256", Dimension: 3}
+	space := domain.EmbeddingSpace{Model: "titan-v2-256", Dimension: 3}
+	mockBedrockClient.EXPECT().EmbeddingModel().Return(embeddingModel).AnyTimes()
+	mockDBHandler.EXPECT().GetEmbeddingIndexState(gomock.Any(), space).Return(&domain.EmbeddingIndexState{Active: space}, nil).AnyTimes()
 
 	ctx := context.Background()
 	query := "類似文書を探すクエリ"
@@ -47,7 +57,7 @@ func TestRecommendService_FindSimilarDocuments(t *testing.T) {
 
 		// 2. 類似チャンク検索
 		mockDBHandler.EXPECT().
-			FindSimilarChunks(ctx, queryEmbedding, limit).
+			FindSimilarChunks(ctx, space, queryEmbedding, limit, domain.ChunkFilter{}).
 			Return(similarChunks, nil).
 			Times(1)
 
@@ -62,7 +72,7 @@ func TestRecommendService_FindSimilarDocuments(t *testing.T) {
 			Times(1)
 
 		// --- テスト実行 ---
-		result, err := recommendService.FindSimilarDocuments(ctx, query, limit)
+		result, err := recommendService.FindSimilarDocuments(ctx, query, limit, domain.ChunkFilter{})
 
 		// --- アサーション ---
 		assert.NoError(t, err)
@@ -81,7 +91,7 @@ func TestRecommendService_FindSimilarDocuments(t *testing.T) {
 			Return(nil, embeddingError).
 			Times(1)
 
-		result, err := recommendService.FindSimilarDocuments(ctx, query, limit)
+		result, err := recommendService.FindSimilarDocuments(ctx, query, limit, domain.ChunkFilter{})
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -96,11 +106,11 @@ func TestRecommendService_FindSimilarDocuments(t *testing.T) {
 			Return(queryEmbedding, nil).
 			Times(1)
 		mockDBHandler.EXPECT().
-			FindSimilarChunks(ctx, queryEmbedding, limit).
+			FindSimilarChunks(ctx, space, queryEmbedding, limit, domain.ChunkFilter{}).
 			Return(nil, findError).
 			Times(1)
 
-		result, err := recommendService.FindSimilarDocuments(ctx, query, limit)
+		result, err := recommendService.FindSimilarDocuments(ctx, query, limit, domain.ChunkFilter{})
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -108,6 +118,76 @@ func TestRecommendService_FindSimilarDocuments(t *testing.T) {
 		assert.Contains(t, err.Error(), "類似チャンクの検索に失敗しました")
 	})
 
+	t.Run("正常系_タグを正規化して絞り込む", func(t *testing.T) {
+		mockBedrockClient.EXPECT().GenerateEmbedding(ctx, query).Return(queryEmbedding, nil).Times(1)
+		mockDBHandler.EXPECT().
+			FindSimilarChunks(ctx, space, queryEmbedding, limit, domain.ChunkFilter{Category: "policy", Tags: []string{"aws", "人事"}}).
+			Return(nil, nil).
+			Times(1)
+
+		_, err := recommendService.FindSimilarDocuments(ctx, query, limit, domain.ChunkFilter{Category: " policy", Tags: []string{" AWS", "人事", "aws"}})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("異常系_不正なタグ", func(t *testing.T) {
+		_, err := recommendService.FindSimilarDocuments(ctx, query, limit, domain.ChunkFilter{Tags: []string{" "}})
+
+		assert.ErrorIs(t, err, services.ErrInvalidTags)
+	})
+
+	t.Run("正常系_取り込み元のあるドキュメントのチャンクに引用元を示す", func(t *testing.T) {
+		docs := map[int64]*domain.Document{
+			1: {ID: 1, SourceURI: "git+https://git.example.com/hr/handbook.git#main:docs/休暇 規程.md", SourceVersion: "abc123"},
+			2: {ID: 2, SourceURI: "git+git@github.com:org/handbook.git#main:README.md", SourceVersion: "def456"},
+			3: {ID: 3, SourceURI: "git+/srv/handbook#main:docs/leave.md", SourceVersion: "abc123"},
+			4: {ID: 4, SourceURI: "https://wiki.example.com/hr/leave", SourceVersion: `"etag"`},
+			5: {ID: 5, Filename: "upload.txt"},
+		}
+		chunks := []domain.DocumentChunk{
+			{ID: 1, DocumentID: 1, StartLine: 3, EndLine: 8},
+			{ID: 2, DocumentID: 2, StartLine: 1, EndLine: 1},
+			{ID: 3, DocumentID: 3, StartLine: 10, EndLine: 12},
+			{ID: 4, DocumentID: 4, StartLine: 1, EndLine: 5},
+			{ID: 5, DocumentID: 5, StartLine: 1, EndLine: 2},
+		}
+		mockBedrockClient.EXPECT().GenerateEmbedding(ctx, query).Return(queryEmbedding, nil)
+		mockDBHandler.EXPECT().FindSimilarChunks(ctx, space, queryEmbedding, limit, domain.ChunkFilter{}).Return(chunks, nil)
+		for id, doc := range docs {
+			mockDBHandler.EXPECT().GetDocumentByID(ctx, id).Return(doc, nil)
+		}
+
+		result, err := recommendService.FindSimilarDocuments(ctx, query, limit, domain.ChunkFilter{})
+
+		require.NoError(t, err)
+		citations := make([]string, len(result.RecommendedChunks))
+		for i, chunk := range result.RecommendedChunks {
+			citations[i] = chunk.Citation
+		}
+		assert.Equal(t, []string{
+			"https://git.example.com/hr/handbook/blob/abc123/docs/%E4%BC%91%E6%9A%87%20%E8%A6%8F%E7%A8%8B.md#L3-L8",
+			"https://github.com/org/handbook/blob/def456/README.md#L1-L1",
+			"git+/srv/handbook#abc123:docs/leave.md#L10-L12",
+			"https://wiki.example.com/hr/leave",
+			"",
+		}, citations)
+	})
+
+	t.Run("正常系: 個人情報を除去したクエリで検索する", func(t *testing.T) {
+		redactor, err := services.NewPIIRedactor(config.RedactConfig{Enabled: true, DefaultMode: "mask", Detectors: []string{"email"}}, mockDBHandler)
+		require.NoError(t, err)
+		svc := services.NewRecommendService(mockBedrockClient, mockDBHandler, redactor)
+
+		mockDBHandler.EXPECT().SaveRedactionAudit(ctx, gomock.Any()).Return(nil)
+		mockBedrockClient.EXPECT().GenerateEmbedding(ctx, "[EMAIL] からの問い合わせ").Return(queryEmbedding, nil)
+		mockDBHandler.EXPECT().FindSimilarChunks(ctx, space, queryEmbedding, limit, domain.ChunkFilter{}).Return(nil, nil)
+
+		result, err := svc.FindSimilarDocuments(ctx, "taro@example.com からの問い合わせ", limit, domain.ChunkFilter{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "[EMAIL] からの問い合わせ", result.Query)
+	})
+
 	// GetDocumentByID エラーケースは、エラーが発生しても結果には含まれないだけなので、
 	// ハンドラーレベルで重要なエラーでなければ、このレベルでのテストは省略可能。
 	// 必要であれば追加。
@@ -121,7 +201,11 @@ func TestRecommendService_ProcessDocumentForEmbedding(t *testing.T) {
 	mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
 	mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
 
-	recommendService := services.NewRecommendService(mockBedrockClient, mockDBHandler)
+	recommendService := services.NewRecommendService(mockBedrockClient, mockDBHandler, nil)
+
+	space := domain.EmbeddingSpace{Model: "titan-v1", Dimension: 2}
+	mockBedrockClient.EXPECT().EmbeddingModel().Return(aws.EmbeddingModel{Name: "titan-v1", Dimension: 2}).AnyTimes()
+	mockDBHandler.EXPECT().GetEmbeddingIndexState(gomock.Any(), space).Return(&domain.EmbeddingIndexState{Active: space}, nil).AnyTimes()
 
 	ctx := context.Background()
 	doc := &domain.Document{
@@ -133,18 +217,18 @@ func TestRecommendService_ProcessDocumentForEmbedding(t *testing.T) {
 
 	t.Run("正常系", func(t *testing.T) {
 		// --- モックの期待動作設定 ---
-		// 1. GenerateEmbedding for combined chunk
+		// 1. 全チャンクのEmbeddingをまとめて生成
 		mockBedrockClient.EXPECT().
-			GenerateEmbedding(ctx, combinedChunk).
-			Return(embedding1, nil).
+			GenerateEmbeddings(ctx, []string{combinedChunk}).
+			Return([][]float32{embedding1}, nil).
 			Times(1)
-		// 2. SaveDocumentEmbedding for combined chunk
+		// 2. 全チャンクをまとめて保存
 		mockDBHandler.EXPECT().
-			SaveDocumentEmbedding(ctx, doc.ID, combinedChunk, 0, embedding1).
-			Return(int64(1), nil). // チャンク ID 1
+			SaveDocumentChunks(ctx, doc.ID, space, []domain.ChunkEmbedding{
+				{ChunkIndex: 0, Content: combinedChunk, StartLine: 1, EndLine: 3, Embedding: embedding1},
+			}, gomock.Nil()).
+			Return(nil).
 			Times(1)
-		// 3. GenerateEmbedding for chunk2 - Not expected anymore
-		// 4. SaveDocumentEmbedding for chunk2 - Not expected anymore
 
 		// --- テスト実行 ---
 		err := recommendService.ProcessDocumentForEmbedding(ctx, doc)
@@ -157,9 +241,11 @@ func TestRecommendService_ProcessDocumentForEmbedding(t *testing.T) {
 		embeddingError := errors.New("embedding error")
 		// 結合されたチャンクでエラーが発生
 		mockBedrockClient.EXPECT().
-			GenerateEmbedding(ctx, combinedChunk).
+			GenerateEmbeddings(ctx, []string{combinedChunk}).
 			Return(nil, embeddingError).
 			Times(1)
+		// 再試行しても回復しないエラーでは保存しない
+		mockDBHandler.EXPECT().SaveDocumentChunks(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 
 		err := recommendService.ProcessDocumentForEmbedding(ctx, doc)
 
@@ -168,13 +254,100 @@ func TestRecommendService_ProcessDocumentForEmbedding(t *testing.T) {
 		assert.Contains(t, err.Error(), "embedding生成に失敗しました")
 	})
 
-	t.Run("異常系_SaveDocumentEmbeddingエラー", func(t *testing.T) {
+	t.Run("正常系_一時的な障害のチャンクを再試行して保存", func(t *testing.T) {
+		throttled := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+		gomock.InOrder(
+			mockBedrockClient.EXPECT().GenerateEmbeddings(ctx, []string{combinedChunk}).Return(nil, throttled).Times(1),
+			mockBedrockClient.EXPECT().GenerateEmbeddings(ctx, []string{combinedChunk}).Return([][]float32{embedding1}, nil).Times(1),
+		)
+		mockDBHandler.EXPECT().
+			SaveDocumentChunks(ctx, doc.ID, space, []domain.ChunkEmbedding{
+				{ChunkIndex: 0, Content: combinedChunk, StartLine: 1, EndLine: 3, Embedding: embedding1},
+			}, gomock.Nil()).
+			Return(nil).
+			Times(1)
+
+		err := recommendService.ProcessDocumentForEmbedding(ctx, doc)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("正常系_一部のチャンクのみ失敗した場合は失敗分だけ再試行", func(t *testing.T) {
+		chunkA := strings.Repeat("あ", services.ChunkSize/3)
+		chunkB := strings.Repeat("い", services.ChunkSize/3)
+		chunkC := strings.Repeat("う", services.ChunkSize/3)
+		multiDoc := &domain.Document{ID: 789, Content: chunkA + "\n\n" + chunkB + "\n\n" + chunkC}
+		embeddingA := []float32{0.1}
+		embeddingB := []float32{0.2}
+		embeddingC := []float32{0.3}
+		throttled := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+
+		gomock.InOrder(
+			mockBedrockClient.EXPECT().
+				GenerateEmbeddings(ctx, []string{chunkA, chunkB, chunkC}).
+				Return([][]float32{embeddingA, nil, embeddingC}, &aws.EmbeddingsError{FailedIndices: []int{1}, Err: throttled}).
+				Times(1),
+			// 2回目は失敗したチャンクだけを再送する
+			mockBedrockClient.EXPECT().
+				GenerateEmbeddings(ctx, []string{chunkB}).
+				Return([][]float32{embeddingB}, nil).
+				Times(1),
+		)
+		mockDBHandler.EXPECT().
+			SaveDocumentChunks(ctx, multiDoc.ID, space, []domain.ChunkEmbedding{
+				{ChunkIndex: 0, Content: chunkA, StartLine: 1, EndLine: 1, Embedding: embeddingA},
+				{ChunkIndex: 1, Content: chunkB, StartLine: 3, EndLine: 3, Embedding: embeddingB},
+				{ChunkIndex: 2, Content: chunkC, StartLine: 5, EndLine: 5, Embedding: embeddingC},
+			}, gomock.Nil()).
+			Return(nil).
+			Times(1)
+
+		err := recommendService.ProcessDocumentForEmbedding(ctx, multiDoc)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("正常系_チャンクに元のテキストでの行範囲を記録する", func(t *testing.T) {
+		long := strings.Repeat("あ", services.ChunkSize/3)
+		lineDoc := &domain.Document{ID: 456, Content: "\n# 見出し\n本文\n\n\n\n" + long + "\n末尾"}
+		embeddingA := []float32{0.1}
+		embeddingB := []float32{0.2}
+
+		mockBedrockClient.EXPECT().
+			GenerateEmbeddings(ctx, []string{"# 見出し\n本文", long + "\n末尾"}).
+			Return([][]float32{embeddingA, embeddingB}, nil).
+			Times(1)
+		mockDBHandler.EXPECT().
+			SaveDocumentChunks(ctx, lineDoc.ID, space, []domain.ChunkEmbedding{
+				{ChunkIndex: 0, Content: "# 見出し\n本文", StartLine: 2, EndLine: 3, Embedding: embeddingA},
+				{ChunkIndex: 1, Content: long + "\n末尾", StartLine: 7, EndLine: 8, Embedding: embeddingB},
+			}, gomock.Nil()).
+			Return(nil).
+			Times(1)
+
+		err := recommendService.ProcessDocumentForEmbedding(ctx, lineDoc)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("異常系_再試行しても失敗する場合は何も保存しない", func(t *testing.T) {
+		unavailable := fmt.Errorf("bedrock Embeddingの呼び出しに失敗しました: %w", aws.ErrCircuitOpen)
+		mockBedrockClient.EXPECT().GenerateEmbeddings(ctx, []string{combinedChunk}).Return(nil, unavailable).Times(3)
+		mockDBHandler.EXPECT().SaveDocumentChunks(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		err := recommendService.ProcessDocumentForEmbedding(ctx, doc)
+
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, aws.ErrCircuitOpen)
+	})
+
+	t.Run("異常系_SaveDocumentChunksエラー", func(t *testing.T) {
 		saveError := errors.New("save error")
 		// 結合されたチャンクの保存でエラー
-		mockBedrockClient.EXPECT().GenerateEmbedding(ctx, combinedChunk).Return(embedding1, nil).Times(1)
+		mockBedrockClient.EXPECT().GenerateEmbeddings(ctx, []string{combinedChunk}).Return([][]float32{embedding1}, nil).Times(1)
 		mockDBHandler.EXPECT().
-			SaveDocumentEmbedding(ctx, doc.ID, combinedChunk, 0, embedding1).
-			Return(int64(0), saveError).
+			SaveDocumentChunks(ctx, doc.ID, space, gomock.Any(), gomock.Nil()).
+			Return(saveError).
 			Times(1)
 
 		err := recommendService.ProcessDocumentForEmbedding(ctx, doc)
@@ -187,8 +360,8 @@ func TestRecommendService_ProcessDocumentForEmbedding(t *testing.T) {
 	t.Run("エッジケース_空のコンテンツ", func(t *testing.T) {
 		emptyDoc := &domain.Document{ID: 456, Content: ""}
 		// splitIntoChunks は空のスライスを返すはずなので、モックは呼ばれないはず
-		mockBedrockClient.EXPECT().GenerateEmbedding(gomock.Any(), gomock.Any()).Times(0)
-		mockDBHandler.EXPECT().SaveDocumentEmbedding(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockBedrockClient.EXPECT().GenerateEmbeddings(gomock.Any(), gomock.Any()).Times(0)
+		mockDBHandler.EXPECT().SaveDocumentChunks(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 
 		err := recommendService.ProcessDocumentForEmbedding(ctx, emptyDoc)
 		assert.NoError(t, err)
@@ -196,3 +369,126 @@ func TestRecommendService_ProcessDocumentForEmbedding(t *testing.T) {
 }
 
 // splitIntoChunks は ProcessDocumentForEmbedding 経由でテストするため、直接テストは削除
+
+func TestRecommendService_EmbeddingModelSwitch(t *testing.T) {
+	ctx := context.Background()
+	configured := aws.EmbeddingModel{Name: "titan-v1", Dimension: 1536}
+	v1 := domain.EmbeddingSpace{Model: "titan-v1", Dimension: 1536}
+	v2 := domain.EmbeddingSpace{Model: "titan-v2-256", Dimension: 256}
+
+	t.Run("再インデックス中は切り替え先のモデルのEmbeddingもチャンクと一緒に保存する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockV2Client := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		recommendService := services.NewRecommendService(mockBedrockClient, mockDBHandler, nil)
+
+		doc := &domain.Document{ID: 1, Content: "本文"}
+		embeddingV1 := make([]float32, 1536)
+		embeddingV2 := make([]float32, 256)
+
+		mockBedrockClient.EXPECT().EmbeddingModel().Return(configured).AnyTimes()
+		mockDBHandler.EXPECT().GetEmbeddingIndexState(ctx, v1).Return(&domain.EmbeddingIndexState{Active: v1, Pending: &v2}, nil)
+		mockBedrockClient.EXPECT().GenerateEmbeddings(ctx, []string{"本文"}).Return([][]float32{embeddingV1}, nil)
+		mockBedrockClient.EXPECT().
+			WithEmbeddingModel(gomock.Any()).
+			DoAndReturn(func(model aws.EmbeddingModel) aws.BedrockClientInterface {
+				assert.Equal(t, "titan-v2-256", model.Name)
+				return mockV2Client
+			})
+		mockV2Client.EXPECT().GenerateEmbeddings(ctx, []string{"本文"}).Return([][]float32{embeddingV2}, nil)
+		mockDBHandler.EXPECT().SaveDocumentChunks(ctx, doc.ID, v1, []domain.ChunkEmbedding{
+			{ChunkIndex: 0, Content: "本文", StartLine: 1, EndLine: 1, Embedding: embeddingV1},
+		}, &domain.ShadowEmbeddingSet{Space: v2, Embeddings: []domain.ShadowEmbedding{
+			{DocumentID: doc.ID, ChunkIndex: 0, Embedding: embeddingV2},
+		}}).Return(nil)
+
+		err := recommendService.ProcessDocumentForEmbedding(ctx, doc)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("保存までに検索用のモデルが切り替わった場合は切り替え後のモデルで生成し直す", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockV2Client := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		recommendService := services.NewRecommendService(mockBedrockClient, mockDBHandler, nil)
+
+		doc := &domain.Document{ID: 1, Content: "本文"}
+		embeddingV1 := make([]float32, 1536)
+		embeddingV2 := make([]float32, 256)
+
+		mockBedrockClient.EXPECT().EmbeddingModel().Return(configured).AnyTimes()
+		gomock.InOrder(
+			mockDBHandler.EXPECT().GetEmbeddingIndexState(ctx, v1).Return(&domain.EmbeddingIndexState{Active: v1, Pending: &v2}, nil),
+			mockBedrockClient.EXPECT().GenerateEmbeddings(ctx, []string{"本文"}).Return([][]float32{embeddingV1}, nil),
+			mockBedrockClient.EXPECT().WithEmbeddingModel(gomock.Any()).Return(mockV2Client),
+			mockV2Client.EXPECT().GenerateEmbeddings(ctx, []string{"本文"}).Return([][]float32{embeddingV2}, nil),
+			mockDBHandler.EXPECT().SaveDocumentChunks(ctx, doc.ID, v1, gomock.Any(), gomock.Any()).Return(domain.ErrEmbeddingSpaceChanged),
+			// 切り替え後の状態を読み直し、切り替え後のモデルで生成し直す
+			mockDBHandler.EXPECT().GetEmbeddingIndexState(ctx, v1).Return(&domain.EmbeddingIndexState{Active: v2, Previous: &v1}, nil),
+			mockBedrockClient.EXPECT().WithEmbeddingModel(gomock.Any()).Return(mockV2Client),
+			mockV2Client.EXPECT().GenerateEmbeddings(ctx, []string{"本文"}).Return([][]float32{embeddingV2}, nil),
+			// 切り戻しに備えて切り替え前のモデルのEmbeddingも一緒に保存する
+			mockBedrockClient.EXPECT().GenerateEmbeddings(ctx, []string{"本文"}).Return([][]float32{embeddingV1}, nil),
+			mockDBHandler.EXPECT().SaveDocumentChunks(ctx, doc.ID, v2, []domain.ChunkEmbedding{
+				{ChunkIndex: 0, Content: "本文", StartLine: 1, EndLine: 1, Embedding: embeddingV2},
+			}, &domain.ShadowEmbeddingSet{Space: v1, Embeddings: []domain.ShadowEmbedding{
+				{DocumentID: doc.ID, ChunkIndex: 0, Embedding: embeddingV1},
+			}}).Return(nil),
+		)
+
+		err := recommendService.ProcessDocumentForEmbedding(ctx, doc)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("切り替え先のモデルのEmbeddingを生成できない場合はチャンクも保存しない", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockV2Client := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		recommendService := services.NewRecommendService(mockBedrockClient, mockDBHandler, nil)
+
+		doc := &domain.Document{ID: 1, Content: "本文"}
+		embeddingErr := errors.New("validation error")
+
+		mockBedrockClient.EXPECT().EmbeddingModel().Return(configured).AnyTimes()
+		mockDBHandler.EXPECT().GetEmbeddingIndexState(ctx, v1).Return(&domain.EmbeddingIndexState{Active: v1, Pending: &v2}, nil)
+		mockBedrockClient.EXPECT().GenerateEmbeddings(ctx, []string{"本文"}).Return([][]float32{make([]float32, 1536)}, nil)
+		mockBedrockClient.EXPECT().WithEmbeddingModel(gomock.Any()).Return(mockV2Client)
+		mockV2Client.EXPECT().GenerateEmbeddings(ctx, []string{"本文"}).Return(nil, embeddingErr)
+		// 検索できる状態のチャンクと切り替え用のEmbeddingが揃わなくならないよう、どちらも保存しない
+		mockDBHandler.EXPECT().SaveDocumentChunks(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		err := recommendService.ProcessDocumentForEmbedding(ctx, doc)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "切り替え用のembedding生成に失敗しました")
+	})
+
+	t.Run("切り替え後は設定に関わらず検索用のモデルでクエリを埋め込む", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockV2Client := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		recommendService := services.NewRecommendService(mockBedrockClient, mockDBHandler, nil)
+
+		queryEmbedding := make([]float32, 256)
+		mockBedrockClient.EXPECT().EmbeddingModel().Return(configured).AnyTimes()
+		mockDBHandler.EXPECT().GetEmbeddingIndexState(ctx, v1).Return(&domain.EmbeddingIndexState{Active: v2, Previous: &v1}, nil)
+		mockBedrockClient.EXPECT().WithEmbeddingModel(gomock.Any()).Return(mockV2Client)
+		mockV2Client.EXPECT().GenerateEmbedding(ctx, "クエリ").Return(queryEmbedding, nil)
+		mockDBHandler.EXPECT().FindSimilarChunks(ctx, v2, queryEmbedding, 5, domain.ChunkFilter{}).Return(nil, nil)
+
+		result, err := recommendService.FindSimilarDocuments(ctx, "クエリ", 5, domain.ChunkFilter{})
+
+		require.NoError(t, err)
+		assert.Empty(t, result.RecommendedChunks)
+	})
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/pkg/aws"
+
+	"github.com/rs/zerolog/log"
+)
+
+// reindexBatchSize は再インデックスで1回に処理するチャンク数 (処理位置の記録単位)
+const reindexBatchSize = 64
+
+// reindexLeaseTTL は再インデックスジョブの実行権の有効期間
+// 実行中のプロセスが停止した場合、この期間を過ぎると別のプロセスが引き継げる
+const reindexLeaseTTL = 2 * time.Minute
+
+// BackgroundRunner はバックグラウンドタスクを開始する (worker.Group が実装する)
+type BackgroundRunner interface {
+	Go(name string, fn func(ctx context.Context)) error
+}
+
+// ReindexStatus は再インデックスの進捗
+type ReindexStatus struct {
+	Active   domain.EmbeddingSpace   `json:"active"`             // 検索に使用しているモデル
+	Previous *domain.EmbeddingSpace  `json:"previous,omitempty"` // ロールバック可能な切り替え前のモデル
+	Job      *domain.ReindexJob      `json:"job,omitempty"`
+	Coverage *domain.ReindexCoverage `json:"coverage,omitempty"` // 切り替え先のEmbeddingの生成状況 (切り替え前のみ)
+	Progress float64                 `json:"progress"`           // 生成状況の割合 (0-100)
+}
+
+// ReindexService はEmbeddingモデルを切り替えるための再インデックスを管理するサービス
+// 新しいモデルのEmbeddingは切り替え用テーブルにバックグラウンドで生成し、全チャンク分揃ってから検索を切り替える
+type ReindexService struct {
+	bedrockClient aws.BedrockClientInterface
+	dbHandler     domain.DBHandlerInterface
+	runner        BackgroundRunner
+	owner         string
+}
+
+// NewReindexService は新しいReindexServiceを作成する
+func NewReindexService(bedrockClient aws.BedrockClientInterface, dbHandler domain.DBHandlerInterface, runner BackgroundRunner) *ReindexService {
+	hostname, _ := os.Hostname()
+	return &ReindexService{
+		bedrockClient: bedrockClient,
+		dbHandler:     dbHandler,
+		runner:        runner,
+		owner:         fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano()),
+	}
+}
+
+// Start は modelName への再インデックスを開始する
+// 同じモデルへの中断・失敗したジョブがある場合は、記録済みの処理位置から再開する
+func (s *ReindexService) Start(ctx context.Context, modelName string) (*domain.ReindexJob, error) {
+	model, err := aws.LookupEmbeddingModel(modelName)
+	if err != nil {
+		return nil, err
+	}
+	target := domain.EmbeddingSpace{Model: model.Name, Dimension: model.Dimension}
+
+	state, err := s.dbHandler.GetEmbeddingIndexState(ctx, s.defaultEmbeddingSpace())
+	if err != nil {
+		return nil, fmt.Errorf("embeddingモデルの状態の取得に失敗しました: %w", err)
+	}
+	if state.Active.Model == target.Model {
+		return nil, fmt.Errorf("%w: %s は既に検索に使用されています", domain.ErrReindexInvalidState, target.Model)
+	}
+
+	job, err := s.dbHandler.FindOpenReindexJob(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("再インデックスジョブの取得に失敗しました: %w", err)
+	}
+	switch {
+	case job == nil:
+		if job, err = s.dbHandler.CreateReindexJob(ctx, target); err != nil {
+			return nil, fmt.Errorf("再インデックスジョブの作成に失敗しました: %w", err)
+		}
+	case job.TargetModel != target.Model || job.Status == domain.ReindexStatusActivated:
+		return nil, fmt.Errorf("%w: %s への再インデックス (job: %d, status: %s) を先に完了または中止してください", domain.ErrReindexInvalidState, job.TargetModel, job.ID, job.Status)
+	case job.Status == domain.ReindexStatusReady:
+		// 生成済みのため、切り替えを待つだけでよい
+		return job, nil
+	}
+
+	if err := s.launch(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// ResumeInterrupted はプロセスの停止で中断された実行中のジョブを再開する (起動時に呼び出す)
+func (s *ReindexService) ResumeInterrupted(ctx context.Context) error {
+	job, err := s.dbHandler.FindOpenReindexJob(ctx)
+	if err != nil {
+		return fmt.Errorf("再インデックスジョブの取得に失敗しました: %w", err)
+	}
+	if job == nil || job.Status != domain.ReindexStatusRunning {
+		return nil
+	}
+	return s.launch(job)
+}
+
+// Status は検索に使用しているモデルと再インデックスの進捗を返す
+func (s *ReindexService) Status(ctx context.Context) (*ReindexStatus, error) {
+	state, err := s.dbHandler.GetEmbeddingIndexState(ctx, s.defaultEmbeddingSpace())
+	if err != nil {
+		return nil, fmt.Errorf("embeddingモデルの状態の取得に失敗しました: %w", err)
+	}
+	job, err := s.dbHandler.FindOpenReindexJob(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("再インデックスジョブの取得に失敗しました: %w", err)
+	}
+
+	status := &ReindexStatus{Active: state.Active, Previous: state.Previous, Job: job}
+	if job == nil || job.Status == domain.ReindexStatusActivated {
+		status.Progress = 100
+		return status, nil
+	}
+
+	coverage, err := s.dbHandler.GetReindexCoverage(ctx, job.Target())
+	if err != nil {
+		return nil, fmt.Errorf("再インデックスの進捗の取得に失敗しました: %w", err)
+	}
+	status.Coverage = &coverage
+	status.Progress = 100
+	if coverage.Total > 0 {
+		status.Progress = float64(coverage.Covered) * 100 / float64(coverage.Total)
+	}
+	return status, nil
+}
+
+// Activate は検索を切り替え先のモデルに切り替える (全チャンク分のEmbeddingが揃っている場合のみ)
+func (s *ReindexService) Activate(ctx context.Context) error {
+	return s.withOpenJob(ctx, s.dbHandler.ActivateReindexJob)
+}
+
+// Rollback は検索を切り替え前のモデルに戻す (切り替え前のEmbeddingを削除するまで可能)
+func (s *ReindexService) Rollback(ctx context.Context) error {
+	return s.withOpenJob(ctx, s.dbHandler.RollbackReindexJob)
+}
+
+// Finalize は切り替え前のモデルのEmbeddingを削除し、再インデックスを完了する
+func (s *ReindexService) Finalize(ctx context.Context) error {
+	return s.withOpenJob(ctx, s.dbHandler.FinalizeReindexJob)
+}
+
+// Cancel は切り替え前の再インデックスを中止し、生成済みのEmbeddingを削除する
+func (s *ReindexService) Cancel(ctx context.Context) error {
+	return s.withOpenJob(ctx, s.dbHandler.CancelReindexJob)
+}
+
+// withOpenJob は終了していないジョブに対して操作を行う
+func (s *ReindexService) withOpenJob(ctx context.Context, op func(ctx context.Context, jobID int64) error) error {
+	job, err := s.dbHandler.FindOpenReindexJob(ctx)
+	if err != nil {
+		return fmt.Errorf("再インデックスジョブの取得に失敗しました: %w", err)
+	}
+	if job == nil {
+		return domain.ErrReindexJobNotFound
+	}
+	return op(ctx, job.ID)
+}
+
+// launch はジョブをバックグラウンドで実行する
+func (s *ReindexService) launch(job *domain.ReindexJob) error {
+	if err := s.runner.Go("reindex", func(ctx context.Context) {
+		// 再インデックスのEmbedding生成はシステムの使用量として記録する
+		ctx = WithUsageScope(ctx, UsageScope{
+			RequestID: fmt.Sprintf("reindex-%d", job.ID),
+			Tenant:    UsageTenantSystem,
+			Endpoint:  "reindex",
+		})
+		s.run(ctx, job)
+	}); err != nil {
+		return fmt.Errorf("再インデックスを開始できませんでした: %w", err)
+	}
+	return nil
+}
+
+// run はジョブの実行権を取得し、未生成のチャンクのEmbeddingをバッチごとに生成・保存する
+// 停止 (ctx のキャンセル) した場合は状態を running のまま残し、リースの期限切れ後に再開できるようにする
+func (s *ReindexService) run(ctx context.Context, job *domain.ReindexJob) {
+	logger := log.With().Int64("job_id", job.ID).Str("target_model", job.TargetModel).Logger()
+
+	claimed, err := s.dbHandler.ClaimReindexJob(ctx, job.ID, s.owner, reindexLeaseTTL)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to claim reindex job")
+		return
+	}
+	if !claimed {
+		logger.Info().Msg("Reindex job is running in another process")
+		return
+	}
+
+	if err := s.process(ctx, job); err != nil {
+		switch {
+		case ctx.Err() != nil:
+			logger.Warn().Err(err).Msg("Reindex job interrupted; it will be resumed later")
+		case errors.Is(err, domain.ErrReindexLeaseLost):
+			logger.Info().Msg("Reindex job was cancelled or taken over")
+		default:
+			logger.Error().Err(err).Msg("Reindex job failed")
+			if err := s.dbHandler.UpdateReindexJobStatus(context.WithoutCancel(ctx), job.ID,
+				[]string{domain.ReindexStatusRunning}, domain.ReindexStatusFailed, err.Error()); err != nil {
+				logger.Error().Err(err).Msg("Failed to mark reindex job as failed")
+			}
+		}
+		return
+	}
+	logger.Info().Msg("Reindex job is ready to activate")
+}
+
+// process は未生成のチャンクがなくなるまでEmbeddingを生成し、完了したらジョブを ready にする
+func (s *ReindexService) process(ctx context.Context, job *domain.ReindexJob) error {
+	target := job.Target()
+	client, err := embeddingClientFor(s.bedrockClient, target)
+	if err != nil {
+		return err
+	}
+
+	cursor := job.LastChunkID
+	rescanned := false
+	for {
+		chunks, err := s.dbHandler.ListChunksForReindex(ctx, target, cursor, reindexBatchSize)
+		if err != nil {
+			return err
+		}
+
+		if len(chunks) == 0 {
+			coverage, err := s.dbHandler.GetReindexCoverage(ctx, target)
+			if err != nil {
+				return err
+			}
+			if coverage.Complete() {
+				return s.dbHandler.UpdateReindexJobStatus(ctx, job.ID, []string{domain.ReindexStatusRunning}, domain.ReindexStatusReady, "")
+			}
+			// 処理位置より前に未生成のチャンクが残っている場合 (再開前に追加されたチャンクなど) は先頭から1度だけ探し直す
+			if rescanned {
+				return fmt.Errorf("%w (%d/%d)", domain.ErrReindexIncomplete, coverage.Covered, coverage.Total)
+			}
+			rescanned = true
+			cursor = 0
+			continue
+		}
+
+		texts := make([]string, len(chunks))
+		for i, chunk := range chunks {
+			texts[i] = chunk.Content
+		}
+		embeddings, err := embedChunks(ctx, client, texts)
+		if err != nil {
+			return err
+		}
+
+		shadows := make([]domain.ShadowEmbedding, len(chunks))
+		for i, chunk := range chunks {
+			shadows[i] = domain.ShadowEmbedding{DocumentID: chunk.DocumentID, ChunkIndex: chunk.ChunkIndex, Embedding: embeddings[i]}
+		}
+		cursor = chunks[len(chunks)-1].ID
+		if err := s.dbHandler.SaveReindexBatch(ctx, job, s.owner, reindexLeaseTTL, shadows, cursor); err != nil {
+			return err
+		}
+	}
+}
+
+// defaultEmbeddingSpace は設定で指定されたEmbeddingモデルを返す
+func (s *ReindexService) defaultEmbeddingSpace() domain.EmbeddingSpace {
+	model := s.bedrockClient.EmbeddingModel()
+	return domain.EmbeddingSpace{Model: model.Name, Dimension: model.Dimension}
+}
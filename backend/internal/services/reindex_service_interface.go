@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+
+	"bedrock-rag-sample/backend/internal/domain"
+)
+
+// ReindexServiceInterface は再インデックスサービスのインターフェース
+type ReindexServiceInterface interface {
+	Start(ctx context.Context, modelName string) (*domain.ReindexJob, error)
+	Status(ctx context.Context) (*ReindexStatus, error)
+	Activate(ctx context.Context) error
+	Rollback(ctx context.Context) error
+	Finalize(ctx context.Context) error
+	Cancel(ctx context.Context) error
+}
+
+// インターフェースを実装していることを静的にチェック
+var _ ReindexServiceInterface = (*ReindexService)(nil)
@@ -0,0 +1,206 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/domain"
+	domainmocks "bedrock-rag-sample/backend/internal/domain/mocks"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+	"bedrock-rag-sample/backend/pkg/aws"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncRunner はバックグラウンドタスクをその場で実行する BackgroundRunner
+type syncRunner struct{}
+
+func (syncRunner) Go(_ string, fn func(ctx context.Context)) error {
+	fn(context.Background())
+	return nil
+}
+
+func TestReindexService_Start(t *testing.T) {
+	ctx := context.Background()
+	configured := aws.EmbeddingModel{Name: "titan-v1", Dimension: 1536}
+	v1 := domain.EmbeddingSpace{Model: "titan-v1", Dimension: 1536}
+	v2 := domain.EmbeddingSpace{Model: "titan-v2-256", Dimension: 256}
+
+	t.Run("正常系: 未生成のチャンクをバッチごとに保存し、完了したら切り替え可能にする", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockV2Client := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		reindexService := services.NewReindexService(mockBedrockClient, mockDBHandler, syncRunner{})
+
+		job := &domain.ReindexJob{ID: 7, TargetModel: v2.Model, Dimension: v2.Dimension, Status: domain.ReindexStatusRunning}
+		chunks := []domain.DocumentChunk{
+			{ID: 11, DocumentID: 1, ChunkIndex: 0, Content: "チャンク1"},
+			{ID: 12, DocumentID: 1, ChunkIndex: 1, Content: "チャンク2"},
+		}
+		embeddings := [][]float32{make([]float32, 256), make([]float32, 256)}
+
+		mockBedrockClient.EXPECT().EmbeddingModel().Return(configured).AnyTimes()
+		mockBedrockClient.EXPECT().WithEmbeddingModel(gomock.Any()).Return(mockV2Client)
+		mockDBHandler.EXPECT().GetEmbeddingIndexState(gomock.Any(), v1).Return(&domain.EmbeddingIndexState{Active: v1}, nil)
+		mockDBHandler.EXPECT().FindOpenReindexJob(gomock.Any()).Return(nil, nil)
+		mockDBHandler.EXPECT().CreateReindexJob(gomock.Any(), v2).Return(job, nil)
+		mockDBHandler.EXPECT().ClaimReindexJob(gomock.Any(), int64(7), gomock.Any(), gomock.Any()).Return(true, nil)
+		gomock.InOrder(
+			mockDBHandler.EXPECT().ListChunksForReindex(gomock.Any(), v2, int64(0), gomock.Any()).Return(chunks, nil),
+			mockDBHandler.EXPECT().ListChunksForReindex(gomock.Any(), v2, int64(12), gomock.Any()).Return(nil, nil),
+		)
+		mockV2Client.EXPECT().GenerateEmbeddings(gomock.Any(), []string{"チャンク1", "チャンク2"}).Return(embeddings, nil)
+		mockDBHandler.EXPECT().
+			SaveReindexBatch(gomock.Any(), job, gomock.Any(), gomock.Any(), []domain.ShadowEmbedding{
+				{DocumentID: 1, ChunkIndex: 0, Embedding: embeddings[0]},
+				{DocumentID: 1, ChunkIndex: 1, Embedding: embeddings[1]},
+			}, int64(12)).
+			Return(nil)
+		mockDBHandler.EXPECT().GetReindexCoverage(gomock.Any(), v2).Return(domain.ReindexCoverage{Covered: 2, Total: 2}, nil)
+		mockDBHandler.EXPECT().
+			UpdateReindexJobStatus(gomock.Any(), int64(7), []string{domain.ReindexStatusRunning}, domain.ReindexStatusReady, "").
+			Return(nil)
+
+		started, err := reindexService.Start(ctx, "titan-v2-256")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), started.ID)
+	})
+
+	t.Run("正常系: 中断したジョブは記録済みの処理位置から再開する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		reindexService := services.NewReindexService(mockBedrockClient, mockDBHandler, syncRunner{})
+
+		job := &domain.ReindexJob{ID: 7, TargetModel: v2.Model, Dimension: v2.Dimension, Status: domain.ReindexStatusRunning, LastChunkID: 500}
+
+		mockBedrockClient.EXPECT().EmbeddingModel().Return(configured).AnyTimes()
+		mockBedrockClient.EXPECT().WithEmbeddingModel(gomock.Any()).Return(servicemocks.NewMockBedrockClientInterface(ctrl))
+		mockDBHandler.EXPECT().FindOpenReindexJob(gomock.Any()).Return(job, nil)
+		mockDBHandler.EXPECT().ClaimReindexJob(gomock.Any(), int64(7), gomock.Any(), gomock.Any()).Return(true, nil)
+		mockDBHandler.EXPECT().ListChunksForReindex(gomock.Any(), v2, int64(500), gomock.Any()).Return(nil, nil)
+		mockDBHandler.EXPECT().GetReindexCoverage(gomock.Any(), v2).Return(domain.ReindexCoverage{Covered: 10, Total: 10}, nil)
+		mockDBHandler.EXPECT().
+			UpdateReindexJobStatus(gomock.Any(), int64(7), []string{domain.ReindexStatusRunning}, domain.ReindexStatusReady, "").
+			Return(nil)
+
+		err := reindexService.ResumeInterrupted(ctx)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("異常系: Embeddingの生成に失敗した場合はジョブを失敗状態にする", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockV2Client := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		reindexService := services.NewReindexService(mockBedrockClient, mockDBHandler, syncRunner{})
+
+		job := &domain.ReindexJob{ID: 7, TargetModel: v2.Model, Dimension: v2.Dimension, Status: domain.ReindexStatusFailed}
+		bedrockErr := errors.New("access denied")
+
+		mockBedrockClient.EXPECT().EmbeddingModel().Return(configured).AnyTimes()
+		mockBedrockClient.EXPECT().WithEmbeddingModel(gomock.Any()).Return(mockV2Client)
+		mockDBHandler.EXPECT().GetEmbeddingIndexState(gomock.Any(), v1).Return(&domain.EmbeddingIndexState{Active: v1, Pending: &v2}, nil)
+		mockDBHandler.EXPECT().FindOpenReindexJob(gomock.Any()).Return(job, nil)
+		mockDBHandler.EXPECT().ClaimReindexJob(gomock.Any(), int64(7), gomock.Any(), gomock.Any()).Return(true, nil)
+		mockDBHandler.EXPECT().ListChunksForReindex(gomock.Any(), v2, int64(0), gomock.Any()).
+			Return([]domain.DocumentChunk{{ID: 1, DocumentID: 1, Content: "チャンク"}}, nil)
+		mockV2Client.EXPECT().GenerateEmbeddings(gomock.Any(), gomock.Any()).Return(nil, bedrockErr)
+		mockDBHandler.EXPECT().
+			UpdateReindexJobStatus(gomock.Any(), int64(7), []string{domain.ReindexStatusRunning}, domain.ReindexStatusFailed, gomock.Any()).
+			Return(nil)
+
+		_, err := reindexService.Start(ctx, "titan-v2-256")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("異常系: 別モデルへの再インデックスが進行中の場合は開始しない", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		reindexService := services.NewReindexService(mockBedrockClient, mockDBHandler, syncRunner{})
+
+		mockBedrockClient.EXPECT().EmbeddingModel().Return(configured).AnyTimes()
+		mockDBHandler.EXPECT().GetEmbeddingIndexState(gomock.Any(), v1).Return(&domain.EmbeddingIndexState{Active: v1}, nil)
+		mockDBHandler.EXPECT().FindOpenReindexJob(gomock.Any()).
+			Return(&domain.ReindexJob{ID: 3, TargetModel: "cohere-multilingual-v3", Dimension: 1024, Status: domain.ReindexStatusRunning}, nil)
+
+		_, err := reindexService.Start(ctx, "titan-v2-256")
+
+		assert.ErrorIs(t, err, domain.ErrReindexInvalidState)
+	})
+
+	t.Run("異常系: 未登録のモデルは指定できない", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		reindexService := services.NewReindexService(servicemocks.NewMockBedrockClientInterface(ctrl), domainmocks.NewMockDBHandlerInterface(ctrl), syncRunner{})
+
+		_, err := reindexService.Start(ctx, "unknown")
+
+		assert.ErrorIs(t, err, aws.ErrUnknownEmbeddingModel)
+	})
+}
+
+func TestReindexService_Status(t *testing.T) {
+	ctx := context.Background()
+	v1 := domain.EmbeddingSpace{Model: "titan-v1", Dimension: 1536}
+	v2 := domain.EmbeddingSpace{Model: "titan-v2-256", Dimension: 256}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+	mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+	reindexService := services.NewReindexService(mockBedrockClient, mockDBHandler, syncRunner{})
+
+	job := &domain.ReindexJob{ID: 7, TargetModel: v2.Model, Dimension: v2.Dimension, Status: domain.ReindexStatusRunning}
+	mockBedrockClient.EXPECT().EmbeddingModel().Return(aws.EmbeddingModel{Name: "titan-v1", Dimension: 1536}).AnyTimes()
+	mockDBHandler.EXPECT().GetEmbeddingIndexState(ctx, v1).Return(&domain.EmbeddingIndexState{Active: v1, Pending: &v2}, nil)
+	mockDBHandler.EXPECT().FindOpenReindexJob(ctx).Return(job, nil)
+	mockDBHandler.EXPECT().GetReindexCoverage(ctx, v2).Return(domain.ReindexCoverage{Covered: 1, Total: 4}, nil)
+
+	status, err := reindexService.Status(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, v1, status.Active)
+	assert.Equal(t, job, status.Job)
+	assert.InDelta(t, 25.0, status.Progress, 1e-9)
+}
+
+func TestReindexService_Transitions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: 進行中のジョブに対して切り替えを行う", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		reindexService := services.NewReindexService(servicemocks.NewMockBedrockClientInterface(ctrl), mockDBHandler, syncRunner{})
+
+		mockDBHandler.EXPECT().FindOpenReindexJob(ctx).Return(&domain.ReindexJob{ID: 7, Status: domain.ReindexStatusReady}, nil)
+		mockDBHandler.EXPECT().ActivateReindexJob(ctx, int64(7)).Return(nil)
+
+		assert.NoError(t, reindexService.Activate(ctx))
+	})
+
+	t.Run("異常系: 進行中のジョブがない場合はエラー", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		reindexService := services.NewReindexService(servicemocks.NewMockBedrockClientInterface(ctrl), mockDBHandler, syncRunner{})
+
+		mockDBHandler.EXPECT().FindOpenReindexJob(ctx).Return(nil, nil)
+
+		assert.ErrorIs(t, reindexService.Rollback(ctx), domain.ErrReindexJobNotFound)
+	})
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/pkg/aws"
+)
+
+// ErrInvalidSyncPrefix は同期するプレフィックスの設定が不正な場合のエラー
+var ErrInvalidSyncPrefix = errors.New("同期するプレフィックスが不正です")
+
+// S3SyncService はS3のプレフィックスに直接置かれたドキュメントを取り込み、documents テーブルと同期するサービス
+// オブジェクトのETagと更新日時で変更を検出する
+type S3SyncService struct {
+	*syncJob
+	interval time.Duration
+}
+
+// NewS3SyncService は新しいS3SyncServiceを作成する
+// 取り込んだファイルの保存先 (S3_DOCUMENTS_PATH) と重なるプレフィックスは、取り込んだファイルを再度取り込んでしまうため指定できない
+func NewS3SyncService(s3Client aws.S3ClientInterface, dbHandler domain.DBHandlerInterface, ingestService IngestServiceInterface, runner BackgroundRunner, cfg *config.Config) (*S3SyncService, error) {
+	prefix, documentsPath := cfg.Sync.Prefix, cfg.AWS.S3DocumentsPath
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: バケット全体は同期できません", ErrInvalidSyncPrefix)
+	}
+	if strings.HasPrefix(prefix, documentsPath) || strings.HasPrefix(documentsPath, prefix) {
+		return nil, fmt.Errorf("%w: %s は取り込んだファイルの保存先 (%s) と重なっています", ErrInvalidSyncPrefix, prefix, documentsPath)
+	}
+
+	source := &s3SyncSource{s3Client: s3Client, bucket: cfg.AWS.S3BucketName, prefix: prefix}
+	return &S3SyncService{
+		syncJob:  newSourceSync("s3-sync", source, dbHandler, ingestService, runner, cfg.Sync.BatchSize),
+		interval: cfg.Sync.Interval,
+	}, nil
+}
+
+// StartSchedule は設定した間隔 (SYNC_INTERVAL) で同期するバックグラウンドタスクを開始する
+func (s *S3SyncService) StartSchedule(stop context.Context) error {
+	return s.startSchedule(stop, s.interval)
+}
+
+// s3SyncSource はS3のプレフィックスの取り込み元
+type s3SyncSource struct {
+	s3Client aws.S3ClientInterface
+	bucket   string
+	prefix   string
+}
+
+// source は取り込み元のURI (s3://bucket/prefix) を返す
+func (s *s3SyncSource) source() string {
+	return s.uri(s.prefix)
+}
+
+// uri はキーの取り込み元のURI (s3://bucket/key) を返す
+func (s *s3SyncSource) uri(key string) string {
+	return "s3://" + s.bucket + "/" + key
+}
+
+func (s *s3SyncSource) listPage(ctx context.Context, startAfter string, limit int) (*syncPage, error) {
+	page, err := s.s3Client.ListObjectsPage(ctx, s.prefix, startAfter, limit)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]syncObject, len(page.Objects))
+	for i, obj := range page.Objects {
+		objects[i] = syncObject{key: obj.Key, version: obj.ETag, modifiedAt: obj.LastModified}
+	}
+	return &syncPage{objects: objects, lastKey: page.LastKey, truncated: page.Truncated}, nil
+}
+
+func (s *s3SyncSource) read(ctx context.Context, key string) ([]byte, error) {
+	return s.s3Client.DownloadFileContent(ctx, key)
+}
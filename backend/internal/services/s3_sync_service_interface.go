@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+
+	"bedrock-rag-sample/backend/internal/domain"
+)
+
+// S3SyncServiceInterface はS3のプレフィックスの同期サービスのインターフェース
+type S3SyncServiceInterface interface {
+	Status(ctx context.Context) (*domain.SyncState, error)
+	Trigger(ctx context.Context) (*domain.SyncState, error)
+	Run(ctx context.Context) (*domain.SyncState, error)
+}
+
+// インターフェースを実装していることを静的にチェック
+var _ S3SyncServiceInterface = (*S3SyncService)(nil)
@@ -0,0 +1,285 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	domainmocks "bedrock-rag-sample/backend/internal/domain/mocks"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+	"bedrock-rag-sample/backend/pkg/aws"
+	awsmock "bedrock-rag-sample/backend/pkg/aws/mock"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const syncSource = "s3://bucket/inbox/"
+
+func newSyncTestConfig() *config.Config {
+	return &config.Config{
+		AWS:  config.AWSConfig{S3BucketName: "bucket", S3DocumentsPath: "documents/"},
+		Sync: config.SyncConfig{Prefix: "inbox/", BatchSize: 2},
+	}
+}
+
+type s3SyncMocks struct {
+	s3     *awsmock.MockS3ClientInterface
+	db     *domainmocks.MockDBHandlerInterface
+	ingest *servicemocks.MockIngestServiceInterface
+}
+
+func newS3SyncService(t *testing.T, ctrl *gomock.Controller) (*services.S3SyncService, s3SyncMocks) {
+	m := s3SyncMocks{
+		s3:     awsmock.NewMockS3ClientInterface(ctrl),
+		db:     domainmocks.NewMockDBHandlerInterface(ctrl),
+		ingest: servicemocks.NewMockIngestServiceInterface(ctrl),
+	}
+	s, err := services.NewS3SyncService(m.s3, m.db, m.ingest, syncRunner{}, newSyncTestConfig())
+	require.NoError(t, err)
+	return s, m
+}
+
+func TestNewS3SyncService(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+	}{
+		{name: "異常系: バケット全体", prefix: ""},
+		{name: "異常系: 取り込んだファイルの保存先の中", prefix: "documents/inbox/"},
+		{name: "異常系: 取り込んだファイルの保存先を含む", prefix: "doc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newSyncTestConfig()
+			cfg.Sync.Prefix = tt.prefix
+
+			_, err := services.NewS3SyncService(nil, nil, nil, syncRunner{}, cfg)
+
+			assert.ErrorIs(t, err, services.ErrInvalidSyncPrefix)
+		})
+	}
+
+	t.Run("正常系: 取り込み元のURI", func(t *testing.T) {
+		s, err := services.NewS3SyncService(nil, nil, nil, syncRunner{}, newSyncTestConfig())
+
+		require.NoError(t, err)
+		assert.Equal(t, syncSource, s.Source())
+	})
+}
+
+func TestS3SyncService_Run(t *testing.T) {
+	ctx := context.Background()
+	modifiedAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	previous := modifiedAt.Add(-time.Hour)
+
+	t.Run("正常系: 新規・更新を取り込み、削除されたオブジェクトのドキュメントを削除する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		s, m := newS3SyncService(t, ctrl)
+
+		page := &aws.S3ObjectPage{Objects: []aws.S3Object{
+			{Key: "inbox/a.txt", ETag: "etag-a", LastModified: modifiedAt},
+			{Key: "inbox/b.txt", ETag: "etag-b2", LastModified: modifiedAt},
+			{Key: "inbox/c.txt", ETag: "etag-c", LastModified: previous},
+			{Key: "inbox/e.zip", ETag: "etag-e", LastModified: modifiedAt},
+		}, LastKey: "inbox/e.zip"}
+		docs := []domain.Document{
+			{ID: 2, LogicalID: 2, ContentHash: "old", SourceURI: syncSource + "b.txt", SourceVersion: "etag-b1", SourceModifiedAt: &previous},
+			{ID: 3, LogicalID: 3, SourceURI: syncSource + "c.txt", SourceVersion: "etag-c", SourceModifiedAt: &previous},
+			{ID: 4, LogicalID: 4, SourceURI: syncSource + "d.txt", SourceVersion: "etag-d", SourceModifiedAt: &previous},
+		}
+		expectedStats := domain.SyncStats{Created: 1, Updated: 1, Deleted: 1, Unchanged: 1, Skipped: 1}
+
+		m.db.EXPECT().ClaimSyncState(gomock.Any(), syncSource, gomock.Any(), gomock.Any()).Return(&domain.SyncState{Source: syncSource}, true, nil)
+		m.s3.EXPECT().ListObjectsPage(gomock.Any(), "inbox/", "", 2).Return(page, nil)
+		m.db.EXPECT().ListDocumentsBySource(gomock.Any(), domain.SourceRange{Prefix: syncSource}).Return(docs, nil)
+		m.s3.EXPECT().DownloadFileContent(gomock.Any(), "inbox/a.txt").Return([]byte("新規"), nil)
+		m.s3.EXPECT().DownloadFileContent(gomock.Any(), "inbox/b.txt").Return([]byte("更新"), nil)
+		m.ingest.EXPECT().Ingest(gomock.Any(), services.IngestRequest{
+			Filename: "a.txt", Content: []byte("新規"), DuplicatePolicy: services.DuplicatePolicyVersion,
+			SourceURI: syncSource + "a.txt", SourceVersion: "etag-a", SourceModifiedAt: &modifiedAt,
+		}).Return(&services.IngestResult{Document: &domain.Document{ID: 10, Version: 1}}, nil)
+		m.ingest.EXPECT().Ingest(gomock.Any(), services.IngestRequest{
+			Filename: "b.txt", Content: []byte("更新"), DuplicatePolicy: services.DuplicatePolicyVersion, LogicalID: 2,
+			SourceURI: syncSource + "b.txt", SourceVersion: "etag-b2", SourceModifiedAt: &modifiedAt,
+		}).Return(&services.IngestResult{Document: &domain.Document{ID: 11, Version: 2}}, nil)
+		m.db.EXPECT().DeleteDocument(gomock.Any(), int64(4)).Return(nil)
+		gomock.InOrder(
+			m.db.EXPECT().SaveSyncCheckpoint(gomock.Any(), syncSource, gomock.Any(), gomock.Any(), "inbox/a.txt", gomock.Any()).Return(nil),
+			m.db.EXPECT().SaveSyncCheckpoint(gomock.Any(), syncSource, gomock.Any(), gomock.Any(), "inbox/b.txt", gomock.Any()).Return(nil),
+			m.db.EXPECT().SaveSyncCheckpoint(gomock.Any(), syncSource, gomock.Any(), gomock.Any(), "inbox/d.txt", gomock.Any()).Return(nil),
+			m.db.EXPECT().CompleteSyncPass(gomock.Any(), syncSource, gomock.Any(), "", expectedStats).Return(nil),
+		)
+		m.db.EXPECT().GetSyncState(gomock.Any(), syncSource).Return(&domain.SyncState{Source: syncSource, Stats: expectedStats}, nil)
+
+		state, err := s.Run(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, expectedStats, state.Stats)
+	})
+
+	t.Run("正常系: 上書きされたものの内容が同じ場合は取り込み元の情報のみを更新する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		s, m := newS3SyncService(t, ctrl)
+
+		sum := sha256.Sum256([]byte("同じ内容"))
+		docs := []domain.Document{
+			{ID: 2, LogicalID: 2, ContentHash: hex.EncodeToString(sum[:]), SourceURI: syncSource + "b.txt", SourceVersion: "etag-b1", SourceModifiedAt: &previous},
+		}
+
+		m.db.EXPECT().ClaimSyncState(gomock.Any(), syncSource, gomock.Any(), gomock.Any()).Return(&domain.SyncState{Source: syncSource}, true, nil)
+		m.s3.EXPECT().ListObjectsPage(gomock.Any(), "inbox/", "", 2).
+			Return(&aws.S3ObjectPage{Objects: []aws.S3Object{{Key: "inbox/b.txt", ETag: "etag-b2", LastModified: modifiedAt}}}, nil)
+		m.db.EXPECT().ListDocumentsBySource(gomock.Any(), gomock.Any()).Return(docs, nil)
+		m.s3.EXPECT().DownloadFileContent(gomock.Any(), "inbox/b.txt").Return([]byte("同じ内容"), nil)
+		m.db.EXPECT().UpdateDocumentSource(gomock.Any(), int64(2), "etag-b2", modifiedAt).Return(nil)
+		m.db.EXPECT().SaveSyncCheckpoint(gomock.Any(), syncSource, gomock.Any(), gomock.Any(), "inbox/b.txt", domain.SyncStats{Unchanged: 1}).Return(nil)
+		m.db.EXPECT().CompleteSyncPass(gomock.Any(), syncSource, gomock.Any(), "", domain.SyncStats{Unchanged: 1}).Return(nil)
+		m.db.EXPECT().GetSyncState(gomock.Any(), syncSource).Return(&domain.SyncState{Source: syncSource}, nil)
+
+		_, err := s.Run(ctx)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("正常系: 記録済みのチェックポイントから再開し、ページごとに突き合わせる", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		s, m := newS3SyncService(t, ctrl)
+
+		resumed := &domain.SyncState{Source: syncSource, Checkpoint: "inbox/b.txt", Stats: domain.SyncStats{Created: 2}}
+		unchanged := func(key string) domain.Document {
+			return domain.Document{SourceURI: syncSource + key, SourceVersion: "etag-" + key, SourceModifiedAt: &previous}
+		}
+		object := func(key string) aws.S3Object {
+			return aws.S3Object{Key: "inbox/" + key, ETag: "etag-" + key, LastModified: previous}
+		}
+
+		m.db.EXPECT().ClaimSyncState(gomock.Any(), syncSource, gomock.Any(), gomock.Any()).Return(resumed, true, nil)
+		gomock.InOrder(
+			m.s3.EXPECT().ListObjectsPage(gomock.Any(), "inbox/", "inbox/b.txt", 2).
+				Return(&aws.S3ObjectPage{Objects: []aws.S3Object{object("c.txt"), object("d.txt")}, LastKey: "inbox/d.txt", Truncated: true}, nil),
+			m.db.EXPECT().ListDocumentsBySource(gomock.Any(), domain.SourceRange{Prefix: syncSource, After: syncSource + "b.txt", Through: syncSource + "d.txt"}).
+				Return([]domain.Document{unchanged("c.txt"), unchanged("d.txt")}, nil),
+			m.db.EXPECT().SaveSyncCheckpoint(gomock.Any(), syncSource, gomock.Any(), gomock.Any(), "inbox/d.txt", domain.SyncStats{Created: 2, Unchanged: 2}).Return(nil),
+			m.s3.EXPECT().ListObjectsPage(gomock.Any(), "inbox/", "inbox/d.txt", 2).
+				Return(&aws.S3ObjectPage{Objects: []aws.S3Object{object("e.txt")}, LastKey: "inbox/e.txt"}, nil),
+			m.db.EXPECT().ListDocumentsBySource(gomock.Any(), domain.SourceRange{Prefix: syncSource, After: syncSource + "d.txt"}).
+				Return([]domain.Document{unchanged("e.txt")}, nil),
+			m.db.EXPECT().CompleteSyncPass(gomock.Any(), syncSource, gomock.Any(), "", domain.SyncStats{Created: 2, Unchanged: 3}).Return(nil),
+		)
+		m.db.EXPECT().GetSyncState(gomock.Any(), syncSource).Return(&domain.SyncState{Source: syncSource}, nil)
+
+		_, err := s.Run(ctx)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("正常系: 取り込みの失敗は件数に数えて続行する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		s, m := newS3SyncService(t, ctrl)
+
+		m.db.EXPECT().ClaimSyncState(gomock.Any(), syncSource, gomock.Any(), gomock.Any()).Return(&domain.SyncState{Source: syncSource}, true, nil)
+		m.s3.EXPECT().ListObjectsPage(gomock.Any(), "inbox/", "", 2).
+			Return(&aws.S3ObjectPage{Objects: []aws.S3Object{{Key: "inbox/a.txt", ETag: "etag-a", LastModified: modifiedAt}}}, nil)
+		m.db.EXPECT().ListDocumentsBySource(gomock.Any(), gomock.Any()).Return(nil, nil)
+		m.s3.EXPECT().DownloadFileContent(gomock.Any(), "inbox/a.txt").Return([]byte("本文"), nil)
+		m.ingest.EXPECT().Ingest(gomock.Any(), gomock.Any()).Return(nil, errors.New("bedrock unavailable"))
+		m.db.EXPECT().CompleteSyncPass(gomock.Any(), syncSource, gomock.Any(), "", domain.SyncStats{Failed: 1}).Return(nil)
+		m.db.EXPECT().GetSyncState(gomock.Any(), syncSource).Return(&domain.SyncState{Source: syncSource}, nil)
+
+		_, err := s.Run(ctx)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("異常系: 他のプロセスが同期を実行中", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		s, m := newS3SyncService(t, ctrl)
+
+		m.db.EXPECT().ClaimSyncState(gomock.Any(), syncSource, gomock.Any(), gomock.Any()).Return(nil, false, nil)
+
+		_, err := s.Run(ctx)
+
+		assert.ErrorIs(t, err, services.ErrSyncInProgress)
+	})
+
+	t.Run("異常系: 一覧に失敗した場合はチェックポイントを残して実行権を解放する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		s, m := newS3SyncService(t, ctrl)
+
+		listErr := errors.New("access denied")
+		m.db.EXPECT().ClaimSyncState(gomock.Any(), syncSource, gomock.Any(), gomock.Any()).
+			Return(&domain.SyncState{Source: syncSource, Checkpoint: "inbox/b.txt"}, true, nil)
+		m.s3.EXPECT().ListObjectsPage(gomock.Any(), "inbox/", "inbox/b.txt", 2).Return(nil, listErr)
+		m.db.EXPECT().ReleaseSyncState(gomock.Any(), syncSource, gomock.Any(), "access denied").Return(nil)
+
+		_, err := s.Run(ctx)
+
+		assert.ErrorIs(t, err, listErr)
+	})
+
+	t.Run("異常系: 実行権を失った場合は解放しない", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		s, m := newS3SyncService(t, ctrl)
+
+		m.db.EXPECT().ClaimSyncState(gomock.Any(), syncSource, gomock.Any(), gomock.Any()).Return(&domain.SyncState{Source: syncSource}, true, nil)
+		m.s3.EXPECT().ListObjectsPage(gomock.Any(), "inbox/", "", 2).Return(&aws.S3ObjectPage{}, nil)
+		m.db.EXPECT().ListDocumentsBySource(gomock.Any(), gomock.Any()).Return(nil, nil)
+		m.db.EXPECT().CompleteSyncPass(gomock.Any(), syncSource, gomock.Any(), "", domain.SyncStats{}).Return(domain.ErrSyncLeaseLost)
+
+		_, err := s.Run(ctx)
+
+		assert.ErrorIs(t, err, domain.ErrSyncLeaseLost)
+	})
+}
+
+func TestS3SyncService_Trigger(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系: バックグラウンドで同期を開始し、開始前の状態を返す", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		s, m := newS3SyncService(t, ctrl)
+
+		before := &domain.SyncState{Source: syncSource}
+		gomock.InOrder(
+			m.db.EXPECT().GetSyncState(gomock.Any(), syncSource).Return(before, nil),
+			m.db.EXPECT().ClaimSyncState(gomock.Any(), syncSource, gomock.Any(), gomock.Any()).Return(&domain.SyncState{Source: syncSource}, true, nil),
+		)
+		m.s3.EXPECT().ListObjectsPage(gomock.Any(), "inbox/", "", 2).Return(&aws.S3ObjectPage{}, nil)
+		m.db.EXPECT().ListDocumentsBySource(gomock.Any(), gomock.Any()).Return(nil, nil)
+		m.db.EXPECT().CompleteSyncPass(gomock.Any(), syncSource, gomock.Any(), "", domain.SyncStats{}).Return(nil)
+		m.db.EXPECT().GetSyncState(gomock.Any(), syncSource).Return(&domain.SyncState{Source: syncSource}, nil)
+
+		state, err := s.Trigger(ctx)
+
+		require.NoError(t, err)
+		assert.Same(t, before, state)
+	})
+
+	t.Run("異常系: 同期を実行中", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		s, m := newS3SyncService(t, ctrl)
+
+		m.db.EXPECT().GetSyncState(gomock.Any(), syncSource).Return(&domain.SyncState{Source: syncSource, Running: true}, nil)
+
+		_, err := s.Trigger(ctx)
+
+		assert.ErrorIs(t, err, services.ErrSyncInProgress)
+	})
+}
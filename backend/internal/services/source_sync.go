@@ -0,0 +1,398 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"bedrock-rag-sample/backend/internal/domain"
+
+	"github.com/rs/zerolog/log"
+)
+
+// syncLeaseTTL は同期の実行権の有効期間 (チェックポイントを記録するたびに延長する)
+// 実行中のプロセスが停止した場合、この期間を過ぎると別のプロセスが続きから再開できる
+const syncLeaseTTL = 5 * time.Minute
+
+// defaultSyncBatchSize は1回に一覧するオブジェクト数の既定値
+const defaultSyncBatchSize = 100
+
+// ErrSyncInProgress は同期を実行中の場合のエラー
+var ErrSyncInProgress = errors.New("同期を実行中です")
+
+// syncObject は取り込み元の1ファイル
+type syncObject struct {
+	key        string    // 取り込み元の中での位置 (バイト順に一覧する)
+	version    string    // 内容が変わると変わる識別子 (S3のETagなど)
+	modifiedAt time.Time // 更新日時 (DBに保存できるマイクロ秒の精度)
+}
+
+// syncPage は一覧したオブジェクトの1ページ
+type syncPage struct {
+	objects   []syncObject
+	lastKey   string // このページで一覧した最後のキー (取り込み対象外のキーを含む)
+	truncated bool   // 続きのページがあるかどうか
+}
+
+// syncSource は同期する取り込み元 (S3のプレフィックス・ローカルのディレクトリなど)
+type syncSource interface {
+	// source は取り込み元自体のURIを返す (同期の状態を記録するキー。配下のキーのURIはこれで始まる)
+	source() string
+	// uri はキーの取り込み元のURIを返す (uri("") にキーを連結したもの)
+	uri(key string) string
+	// listPage は startAfter より後のオブジェクトをキーのバイト順に最大 limit 件返す
+	listPage(ctx context.Context, startAfter string, limit int) (*syncPage, error)
+	// read はオブジェクトの内容を読み込む
+	read(ctx context.Context, key string) ([]byte, error)
+}
+
+// syncJob は取り込み元の同期の実行を管理する
+// 実行権 (リース) を取得してから同期の処理 (pass) を実行し、処理件数と完了を同期の状態として記録する
+type syncJob struct {
+	name      string // ログとバックグラウンドタスクの名前
+	source    string // 同期の状態を記録する取り込み元のURI
+	dbHandler domain.DBHandlerInterface
+	runner    BackgroundRunner
+	owner     string
+	running   atomic.Bool // このプロセスで同期を実行中かどうか
+	// pass は同期の処理の本体 (state.Stats に処理件数を数え、取り込み元の末尾まで処理したら complete を呼ぶ)
+	pass func(ctx context.Context, state *domain.SyncState) error
+}
+
+// newSyncJob は取り込み元の同期の実行を管理する syncJob を生成する (pass は生成後に設定する)
+func newSyncJob(name, source string, dbHandler domain.DBHandlerInterface, runner BackgroundRunner) *syncJob {
+	hostname, _ := os.Hostname()
+	return &syncJob{
+		name:      name,
+		source:    source,
+		dbHandler: dbHandler,
+		runner:    runner,
+		owner:     fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano()),
+	}
+}
+
+// Source は同期する取り込み元のURIを返す
+func (s *syncJob) Source() string {
+	return s.source
+}
+
+// Status は同期の状態を返す
+func (s *syncJob) Status(ctx context.Context) (*domain.SyncState, error) {
+	state, err := s.dbHandler.GetSyncState(ctx, s.Source())
+	if err != nil {
+		return nil, fmt.Errorf("同期の状態の取得に失敗しました: %w", err)
+	}
+	return state, nil
+}
+
+// Trigger は同期をバックグラウンドで開始し、開始前の状態を返す
+func (s *syncJob) Trigger(ctx context.Context) (*domain.SyncState, error) {
+	state, err := s.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if state.Running || s.running.Load() {
+		return nil, ErrSyncInProgress
+	}
+	if err := s.runner.Go(s.name, s.runInBackground); err != nil {
+		return nil, fmt.Errorf("同期を開始できませんでした: %w", err)
+	}
+	return state, nil
+}
+
+// startSchedule は指定した間隔で同期するバックグラウンドタスクを開始する
+// 開始直後にも1回同期するため、前回の停止で中断された同期はその時点で再開される
+// stop がキャンセルされると次の同期を開始せずに終了する (実行中の同期はシャットダウンの猶予期限まで続ける)
+func (s *syncJob) startSchedule(stop context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return nil
+	}
+	return s.runner.Go(s.name+"-schedule", func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.runInBackground(ctx)
+			select {
+			case <-stop.Done():
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// runInBackground はバックグラウンドタスクとして同期を実行する
+func (s *syncJob) runInBackground(ctx context.Context) {
+	_ = s.runLogged(ctx)
+}
+
+// runLogged は同期を実行し、エラーをログに記録して返す
+func (s *syncJob) runLogged(ctx context.Context) error {
+	// 同期で取り込んだドキュメントのEmbedding生成はシステムの使用量として記録する
+	ctx = WithUsageScope(ctx, UsageScope{
+		RequestID: fmt.Sprintf("%s-%d", s.name, time.Now().UnixNano()),
+		Tenant:    UsageTenantSystem,
+		Endpoint:  "sync",
+	})
+	_, err := s.Run(ctx)
+	switch {
+	case errors.Is(err, ErrSyncInProgress):
+		log.Info().Str("source", s.Source()).Msg("Sync is already running")
+	case err != nil:
+		log.Error().Err(err).Str("source", s.Source()).Msg("Sync failed")
+	}
+	return err
+}
+
+// Run は同期を1回実行し、完了後の状態を返す
+// 前回の同期が途中で中断されていた場合は、記録済みのチェックポイントから再開する
+// 他のプロセス (またはこのプロセス) が同期を実行中の場合は ErrSyncInProgress を返す
+func (s *syncJob) Run(ctx context.Context) (*domain.SyncState, error) {
+	if !s.running.CompareAndSwap(false, true) {
+		return nil, ErrSyncInProgress
+	}
+	defer s.running.Store(false)
+
+	source := s.Source()
+	state, claimed, err := s.dbHandler.ClaimSyncState(ctx, source, s.owner, syncLeaseTTL)
+	if err != nil {
+		return nil, fmt.Errorf("同期の開始に失敗しました: %w", err)
+	}
+	if !claimed {
+		return nil, ErrSyncInProgress
+	}
+
+	logger := log.With().Str("source", source).Str("checkpoint", state.Checkpoint).Logger()
+	logger.Info().Msg("Sync started")
+	if err := s.pass(ctx, state); err != nil {
+		// チェックポイントは残したまま実行権を解放し、次回の同期で続きから再開する
+		if !errors.Is(err, domain.ErrSyncLeaseLost) {
+			if releaseErr := s.dbHandler.ReleaseSyncState(context.WithoutCancel(ctx), source, s.owner, err.Error()); releaseErr != nil {
+				logger.Error().Err(releaseErr).Msg("Failed to release sync state")
+			}
+		}
+		return nil, fmt.Errorf("同期が中断されました (%s まで処理済み): %w", state.Checkpoint, err)
+	}
+	logger.Info().Interface("stats", state.Stats).Msg("Sync completed")
+
+	return s.Status(ctx)
+}
+
+// checkpoint は処理済みの位置と処理件数を記録し、リースを延長する
+func (s *syncJob) checkpoint(ctx context.Context, state *domain.SyncState) error {
+	return s.dbHandler.SaveSyncCheckpoint(ctx, state.Source, s.owner, syncLeaseTTL, state.Checkpoint, state.Stats)
+}
+
+// complete は取り込み元の末尾まで同期したことを記録し (state.Cursor に同期した取り込み元の版を記録する)、リースを解放する
+func (s *syncJob) complete(ctx context.Context, state *domain.SyncState) error {
+	return s.dbHandler.CompleteSyncPass(ctx, state.Source, s.owner, state.Cursor, state.Stats)
+}
+
+// listingSync は取り込み元のオブジェクトを一覧して取り込み、documents テーブルと同期する
+// オブジェクトの識別子と更新日時をドキュメントの取り込み元と比較し、新しいオブジェクトを取り込み、更新されたオブジェクトは新しい版として取り込み、
+// 削除されたオブジェクトから取り込んだドキュメントを削除する
+// キーの順に走査し、処理済みの位置をチェックポイントとして記録するため、大きな取り込み元も中断した位置から再開できる
+type listingSync struct {
+	job           *syncJob
+	source        syncSource
+	dbHandler     domain.DBHandlerInterface
+	ingestService IngestServiceInterface
+	batchSize     int
+}
+
+// newSourceSync は一覧できる取り込み元を同期する syncJob を生成する
+func newSourceSync(name string, source syncSource, dbHandler domain.DBHandlerInterface, ingestService IngestServiceInterface, runner BackgroundRunner, batchSize int) *syncJob {
+	if batchSize <= 0 {
+		batchSize = defaultSyncBatchSize
+	}
+	job := newSyncJob(name, source.source(), dbHandler, runner)
+	l := &listingSync{job: job, source: source, dbHandler: dbHandler, ingestService: ingestService, batchSize: batchSize}
+	job.pass = l.sync
+	return job
+}
+
+// sync はチェックポイントから末尾までページごとにオブジェクトを一覧し、同じ範囲の取り込み元のドキュメントと突き合わせる
+func (s *listingSync) sync(ctx context.Context, state *domain.SyncState) error {
+	for {
+		page, err := s.source.listPage(ctx, state.Checkpoint, s.batchSize)
+		if err != nil {
+			return err
+		}
+
+		// このページで一覧したキーの範囲 (最後のページは末尾まで) にあるドキュメントが突き合わせの対象
+		r := domain.SourceRange{Prefix: s.source.source()}
+		if state.Checkpoint != "" {
+			r.After = s.source.uri(state.Checkpoint)
+		}
+		if page.truncated {
+			r.Through = s.source.uri(page.lastKey)
+		}
+		docs, err := s.dbHandler.ListDocumentsBySource(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range s.syncEntries(page.objects, docs) {
+			changed, err := s.syncEntry(ctx, entry, &state.Stats)
+			if err != nil {
+				return err
+			}
+			// 取り込みや削除のたびに位置を記録し (リースも延長する)、中断しても処理をやり直さないようにする
+			if changed {
+				state.Checkpoint = entry.key
+				if err := s.job.checkpoint(ctx, state); err != nil {
+					return err
+				}
+			}
+		}
+
+		if !page.truncated {
+			return s.job.complete(ctx, state)
+		}
+		state.Checkpoint = page.lastKey
+		if err := s.job.checkpoint(ctx, state); err != nil {
+			return err
+		}
+	}
+}
+
+// syncEntry はキー1件分の突き合わせの対象
+type syncEntry struct {
+	key    string
+	object *syncObject       // 削除されたオブジェクトの場合は nil
+	docs   []domain.Document // そのオブジェクトから取り込んだドキュメント (IDの順)
+}
+
+// syncEntries はオブジェクトとドキュメントをキーごとにまとめ、キーのバイト順に並べる
+func (s *listingSync) syncEntries(objects []syncObject, docs []domain.Document) []*syncEntry {
+	entries := make(map[string]*syncEntry, len(objects))
+	entry := func(key string) *syncEntry {
+		e, ok := entries[key]
+		if !ok {
+			e = &syncEntry{key: key}
+			entries[key] = e
+		}
+		return e
+	}
+	for i := range objects {
+		entry(objects[i].key).object = &objects[i]
+	}
+	prefix := s.source.uri("")
+	for _, doc := range docs {
+		e := entry(strings.TrimPrefix(doc.SourceURI, prefix))
+		e.docs = append(e.docs, doc)
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	sorted := make([]*syncEntry, len(keys))
+	for i, key := range keys {
+		sorted[i] = entries[key]
+	}
+	return sorted
+}
+
+// syncEntry はキー1件を同期し、ドキュメントを変更したかどうかを返す
+// 1件の取り込みの失敗は件数に数えて続行し (次回の同期で再度取り込む)、DBへのアクセスなど同期を続けられないエラーのみを返す
+func (s *listingSync) syncEntry(ctx context.Context, e *syncEntry, stats *domain.SyncStats) (bool, error) {
+	uri := s.source.uri(e.key)
+	if e.object == nil {
+		if err := deleteSourceDocuments(ctx, s.dbHandler, uri, e.docs); err != nil {
+			return false, err
+		}
+		stats.Deleted++
+		return true, nil
+	}
+
+	obj := e.object
+	var latest *domain.Document
+	if n := len(e.docs); n > 0 {
+		latest = &e.docs[n-1]
+	}
+	if latest != nil && latest.SourceVersion == obj.version && latest.SourceModifiedAt != nil && latest.SourceModifiedAt.Equal(obj.modifiedAt) {
+		stats.Unchanged++
+		return false, nil
+	}
+	if !isSupportedFile(obj.key) {
+		stats.Skipped++
+		return false, nil
+	}
+
+	content, err := s.source.read(ctx, obj.key)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		log.Warn().Err(err).Str("source_uri", uri).Msg("Failed to read object for sync")
+		stats.Failed++
+		return false, nil
+	}
+	return ingestSourceContent(ctx, s.dbHandler, s.ingestService, latest, path.Base(obj.key), uri, *obj, content, stats)
+}
+
+// deleteSourceDocuments は削除された取り込み元から取り込んだドキュメント (すべての版) を削除する
+func deleteSourceDocuments(ctx context.Context, dbHandler domain.DBHandlerInterface, uri string, docs []domain.Document) error {
+	for _, doc := range docs {
+		if err := dbHandler.DeleteDocument(ctx, doc.ID); err != nil && !errors.Is(err, domain.ErrDocumentNotFound) {
+			return fmt.Errorf("削除された取り込み元のドキュメントの削除に失敗しました (%s): %w", uri, err)
+		}
+	}
+	log.Info().Str("source_uri", uri).Int("documents", len(docs)).Msg("Deleted documents for removed source")
+	return nil
+}
+
+// ingestSourceContent は取り込み元から読み込んだ内容を取り込み (既存のドキュメントがある場合は新しい版とする)、ドキュメントを変更したかどうかを返す
+// 最新の版と内容が同じ場合は、新しい版を作らずに取り込み元の識別子と更新日時のみを更新する
+// 取り込みの失敗は件数に数えて nil を返し、同期を続けられないエラーのみを返す
+func ingestSourceContent(ctx context.Context, dbHandler domain.DBHandlerInterface, ingestService IngestServiceInterface, latest *domain.Document,
+	filename, uri string, obj syncObject, content []byte, stats *domain.SyncStats) (bool, error) {
+	if latest != nil && latest.ContentHash == contentHash(content) {
+		if err := dbHandler.UpdateDocumentSource(ctx, latest.ID, obj.version, obj.modifiedAt); err != nil {
+			return false, err
+		}
+		stats.Unchanged++
+		return true, nil
+	}
+
+	modifiedAt := obj.modifiedAt
+	req := IngestRequest{
+		Filename: filename,
+		Content:  content,
+		// 重複していても取り込み元を記録しなければ、次回の同期で再び新しいオブジェクトとして扱ってしまうため、版として取り込む
+		DuplicatePolicy:  DuplicatePolicyVersion,
+		SourceURI:        uri,
+		SourceVersion:    obj.version,
+		SourceModifiedAt: &modifiedAt,
+	}
+	if latest != nil {
+		req.LogicalID = latest.LogicalID
+	}
+	result, err := ingestService.Ingest(ctx, req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		log.Warn().Err(err).Str("source_uri", uri).Msg("Failed to ingest source for sync")
+		stats.Failed++
+		return false, nil
+	}
+
+	if latest == nil {
+		stats.Created++
+	} else {
+		stats.Updated++
+	}
+	log.Info().Str("source_uri", uri).Int64("document_id", result.Document.ID).Int("version", result.Document.Version).Msg("Ingested source for sync")
+	return true, nil
+}
@@ -13,13 +13,19 @@ import (
 type SummarizeService struct {
 	bedrockClient aws.BedrockClientInterface
 	uploadService UploadServiceInterface
+	models        *TextModelRouter
+	redactor      *PIIRedactor
 }
 
 // NewSummarizeService は新しいSummarizeServiceを作成する
-func NewSummarizeService(bedrockClient aws.BedrockClientInterface, uploadService UploadServiceInterface) *SummarizeService {
+// models が nil の場合はモデルの指定を受け付けず、bedrockClient の既定モデルを使用する
+// redactor が nil の場合は要約するテキストから個人情報を除去しない
+func NewSummarizeService(bedrockClient aws.BedrockClientInterface, uploadService UploadServiceInterface, models *TextModelRouter, redactor *PIIRedactor) *SummarizeService {
 	return &SummarizeService{
 		bedrockClient: bedrockClient,
 		uploadService: uploadService,
+		models:        models,
+		redactor:      redactor,
 	}
 }
 
@@ -29,21 +35,31 @@ type SummarizeResult struct {
 	Summary      string            `json:"summary"`
 	SourceText   string            `json:"source_text,omitempty"`
 	UploadInfo   *UploadFileResult `json:"upload_info,omitempty"`
+	Model        string            `json:"model,omitempty"` // 要約に使用したモデルのカタログ名
 }
 
 // SummarizeText はテキストを要約する
-func (s *SummarizeService) SummarizeText(ctx context.Context, text string) (*SummarizeResult, error) {
+// model が空の場合は要約エンドポイントの既定モデルを使用する
+func (s *SummarizeService) SummarizeText(ctx context.Context, text, model string) (*SummarizeResult, error) {
 	if text == "" {
 		return nil, errors.New("テキストが空です")
 	}
 
+	client, modelName, err := textClientFor(s.bedrockClient, s.models, EndpointSummarize, model)
+	if err != nil {
+		return nil, err
+	}
+
 	// テキストの長さを制限（例: 最大10000文字）
 	if len(text) > 10000 {
 		text = text[:10000]
 	}
+	if text, err = s.redactor.RedactText(ctx, RedactEndpointSummarize, text); err != nil {
+		return nil, err
+	}
 
 	// Bedrockを使って要約を生成
-	summary, err := s.bedrockClient.GenerateSummary(ctx, text)
+	summary, err := client.GenerateSummary(ctx, text)
 	if err != nil {
 		return nil, fmt.Errorf("要約の生成に失敗しました: %w", err)
 	}
@@ -51,6 +67,7 @@ func (s *SummarizeService) SummarizeText(ctx context.Context, text string) (*Sum
 	return &SummarizeResult{
 		Summary:    summary,
 		SourceText: text,
+		Model:      modelName,
 	}, nil
 }
 
@@ -87,8 +104,15 @@ func (s *SummarizeService) SummarizeFile(ctx context.Context, fileContent io.Rea
 		text = text[:10000]
 	}
 
-	// Bedrockを使って要約を生成
-	summary, err := s.bedrockClient.GenerateSummary(ctx, text)
+	// Bedrockを使って要約を生成 (要約エンドポイントの既定モデルを使用する)
+	client, modelName, err := textClientFor(s.bedrockClient, s.models, EndpointSummarize, "")
+	if err != nil {
+		return nil, err
+	}
+	if text, err = s.redactor.RedactText(ctx, RedactEndpointSummarize, text); err != nil {
+		return nil, err
+	}
+	summary, err := client.GenerateSummary(ctx, text)
 	if err != nil {
 		return nil, fmt.Errorf("要約の生成に失敗しました: %w", err)
 	}
@@ -96,13 +120,20 @@ func (s *SummarizeService) SummarizeFile(ctx context.Context, fileContent io.Rea
 	return &SummarizeResult{
 		Summary:    summary,
 		SourceText: text,
+		Model:      modelName,
 		// UploadInfo は削除 (このメソッドはアップロードしなくなったため)
 		// UploadInfo: uploadResult,
 	}, nil
 }
 
 // SummarizeFileByS3Key はS3キーで指定されたファイルを要約する (新規追加)
-func (s *SummarizeService) SummarizeFileByS3Key(ctx context.Context, s3Key string) (*SummarizeResult, error) {
+// model が空の場合は要約エンドポイントの既定モデルを使用する
+func (s *SummarizeService) SummarizeFileByS3Key(ctx context.Context, s3Key, model string) (*SummarizeResult, error) {
+	client, modelName, err := textClientFor(s.bedrockClient, s.models, EndpointSummarize, model)
+	if err != nil {
+		return nil, err
+	}
+
 	// uploadService から S3 クライアントを取得
 	s3Client := s.uploadService.GetS3Client()
 	if s3Client == nil {
@@ -116,11 +147,16 @@ func (s *SummarizeService) SummarizeFileByS3Key(ctx context.Context, s3Key strin
 		return nil, fmt.Errorf("S3からのファイルダウンロードに失敗しました (key: %s): %w", s3Key, err)
 	}
 
+	text, err := s.redactor.RedactText(ctx, RedactEndpointSummarize, string(fileContent))
+	if err != nil {
+		return nil, err
+	}
+
 	// テキストを要約
-	summary, err := s.bedrockClient.GenerateSummary(ctx, string(fileContent))
+	summary, err := client.GenerateSummary(ctx, text)
 	if err != nil {
 		return nil, fmt.Errorf("bedrockでのファイル要約に失敗しました (key: %s): %w", s3Key, err)
 	}
 
-	return &SummarizeResult{Summary: summary}, nil // OriginalTextは含めない（任意）
+	return &SummarizeResult{Summary: summary, Model: modelName}, nil // OriginalTextは含めない（任意）
 }
@@ -7,9 +7,9 @@ import (
 
 // SummarizeServiceInterface は要約サービスのインターフェース
 type SummarizeServiceInterface interface {
-	SummarizeText(ctx context.Context, text string) (*SummarizeResult, error)
+	SummarizeText(ctx context.Context, text, model string) (*SummarizeResult, error)
 	SummarizeFile(ctx context.Context, fileContent io.Reader, fileName string) (*SummarizeResult, error)
-	SummarizeFileByS3Key(ctx context.Context, s3Key string) (*SummarizeResult, error)
+	SummarizeFileByS3Key(ctx context.Context, s3Key, model string) (*SummarizeResult, error)
 	// 他の SummarizeService メソッドが必要であればここに追加
 }
 
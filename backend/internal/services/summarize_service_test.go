@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"bedrock-rag-sample/backend/config"
 	"bedrock-rag-sample/backend/internal/services"
 	servicemocks "bedrock-rag-sample/backend/internal/services/mocks" // Bedrock, Upload モック
 	awsmock "bedrock-rag-sample/backend/pkg/aws/mock"                 // S3 モック
@@ -23,7 +24,7 @@ func TestSummarizeService_SummarizeText(t *testing.T) {
 	mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
 	mockUploadService := servicemocks.NewMockUploadServiceInterface(ctrl) // このテストでは使わないが初期化
 
-	summarizeService := services.NewSummarizeService(mockBedrockClient, mockUploadService)
+	summarizeService := services.NewSummarizeService(mockBedrockClient, mockUploadService, nil, nil)
 
 	ctx := context.Background()
 	inputText := "これは要約対象の長いテキストです。"
@@ -35,7 +36,7 @@ func TestSummarizeService_SummarizeText(t *testing.T) {
 			Return(expectedSummary, nil).
 			Times(1)
 
-		result, err := summarizeService.SummarizeText(ctx, inputText)
+		result, err := summarizeService.SummarizeText(ctx, inputText, "")
 
 		assert.NoError(t, err)
 		require.NotNil(t, result)
@@ -44,7 +45,7 @@ func TestSummarizeService_SummarizeText(t *testing.T) {
 	})
 
 	t.Run("異常系_テキストが空", func(t *testing.T) {
-		result, err := summarizeService.SummarizeText(ctx, "")
+		result, err := summarizeService.SummarizeText(ctx, "", "")
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		assert.Contains(t, err.Error(), "テキストが空です")
@@ -57,12 +58,35 @@ func TestSummarizeService_SummarizeText(t *testing.T) {
 			Return("", bedrockError).
 			Times(1)
 
-		result, err := summarizeService.SummarizeText(ctx, inputText)
+		result, err := summarizeService.SummarizeText(ctx, inputText, "")
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		assert.ErrorIs(t, err, bedrockError)
 		assert.Contains(t, err.Error(), "要約の生成に失敗しました")
 	})
+
+	t.Run("正常系: 個人情報を除去したテキストを要約する", func(t *testing.T) {
+		redactor, err := services.NewPIIRedactor(config.RedactConfig{Enabled: true, DefaultMode: "mask", Detectors: []string{"email"}}, nil)
+		require.NoError(t, err)
+		svc := services.NewSummarizeService(mockBedrockClient, mockUploadService, nil, redactor)
+
+		mockBedrockClient.EXPECT().GenerateSummary(ctx, "問い合わせは [EMAIL] まで").Return(expectedSummary, nil)
+
+		result, err := svc.SummarizeText(ctx, "問い合わせは info@example.com まで", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "問い合わせは [EMAIL] まで", result.SourceText)
+	})
+
+	t.Run("異常系: 個人情報を拒否する設定では要約しない", func(t *testing.T) {
+		redactor, err := services.NewPIIRedactor(config.RedactConfig{Enabled: true, DefaultMode: "mask", Modes: map[string]string{"summarize": "reject"}, Detectors: []string{"email"}}, nil)
+		require.NoError(t, err)
+		svc := services.NewSummarizeService(mockBedrockClient, mockUploadService, nil, redactor)
+
+		_, err = svc.SummarizeText(ctx, "問い合わせは info@example.com まで", "")
+
+		assert.ErrorIs(t, err, services.ErrPIIDetected)
+	})
 }
 
 func TestSummarizeService_SummarizeFileByS3Key(t *testing.T) {
@@ -73,7 +97,7 @@ func TestSummarizeService_SummarizeFileByS3Key(t *testing.T) {
 	mockUploadService := servicemocks.NewMockUploadServiceInterface(ctrl)
 	mockS3Client := awsmock.NewMockS3ClientInterface(ctrl) // S3 モックも必要
 
-	summarizeService := services.NewSummarizeService(mockBedrockClient, mockUploadService)
+	summarizeService := services.NewSummarizeService(mockBedrockClient, mockUploadService, nil, nil)
 
 	ctx := context.Background()
 	s3Key := "path/to/file.txt"
@@ -99,7 +123,7 @@ func TestSummarizeService_SummarizeFileByS3Key(t *testing.T) {
 			Times(1)
 
 		// --- テスト実行 ---
-		result, err := summarizeService.SummarizeFileByS3Key(ctx, s3Key)
+		result, err := summarizeService.SummarizeFileByS3Key(ctx, s3Key, "")
 
 		// --- アサーション ---
 		assert.NoError(t, err)
@@ -115,7 +139,7 @@ func TestSummarizeService_SummarizeFileByS3Key(t *testing.T) {
 			Return(nil, downloadError).
 			Times(1)
 
-		result, err := summarizeService.SummarizeFileByS3Key(ctx, s3Key)
+		result, err := summarizeService.SummarizeFileByS3Key(ctx, s3Key, "")
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		assert.ErrorIs(t, err, downloadError)
@@ -134,7 +158,7 @@ func TestSummarizeService_SummarizeFileByS3Key(t *testing.T) {
 			Return("", summaryError).
 			Times(1)
 
-		result, err := summarizeService.SummarizeFileByS3Key(ctx, s3Key)
+		result, err := summarizeService.SummarizeFileByS3Key(ctx, s3Key, "")
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		assert.ErrorIs(t, err, summaryError)
@@ -152,7 +176,7 @@ func TestSummarizeService_SummarizeFile(t *testing.T) {
 	// mockUploadService := servicemocks.NewMockUploadServiceInterface(ctrl)
 
 	// SummarizeService の生成 (uploadService は nil で OK)
-	summarizeService := services.NewSummarizeService(mockBedrockClient, nil)
+	summarizeService := services.NewSummarizeService(mockBedrockClient, nil, nil, nil)
 
 	ctx := context.Background()
 	fileName := "test_summarize.txt"
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	render := func(ops []diffOp) string {
+		var sb strings.Builder
+		for _, op := range ops {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.text)
+			sb.WriteByte('\n')
+		}
+		return sb.String()
+	}
+
+	t.Run("変更・追加・削除", func(t *testing.T) {
+		a := []string{"a", "b", "c", "d"}
+		b := []string{"a", "x", "c", "d", "e"}
+
+		assert.Equal(t, " a\n-b\n+x\n c\n d\n+e\n", render(diffLines(a, b)))
+	})
+
+	t.Run("空のテキストとの比較", func(t *testing.T) {
+		assert.Equal(t, "+a\n+b\n", render(diffLines(nil, []string{"a", "b"})))
+		assert.Equal(t, "-a\n", render(diffLines([]string{"a"}, nil)))
+		assert.Empty(t, diffLines(nil, nil))
+	})
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Run("離れた変更は別のハンクにする", func(t *testing.T) {
+		var a []string
+		for i := 1; i <= 20; i++ {
+			a = append(a, string(rune('a'+i-1)))
+		}
+		b := append([]string(nil), a...)
+		b[1] = "B"
+		b[17] = "R"
+
+		expected := strings.Join([]string{
+			"--- v1/doc.txt",
+			"+++ v2/doc.txt",
+			"@@ -1,5 +1,5 @@",
+			" a", "-b", "+B", " c", " d", " e",
+			"@@ -15,6 +15,6 @@",
+			" o", " p", " q", "-r", "+R", " s", " t",
+			"",
+		}, "\n")
+		assert.Equal(t, expected, unifiedDiff("v1/doc.txt", "v2/doc.txt", diffLines(a, b)))
+	})
+
+	t.Run("近い変更は同じハンクにまとめる", func(t *testing.T) {
+		a := []string{"1", "2", "3", "4", "5", "6", "7", "8"}
+		b := []string{"1", "X", "3", "4", "5", "6", "7", "Y"}
+
+		assert.Equal(t, "--- a\n+++ b\n@@ -1,8 +1,8 @@\n 1\n-2\n+X\n 3\n 4\n 5\n 6\n 7\n-8\n+Y\n",
+			unifiedDiff("a", "b", diffLines(a, b)))
+	})
+
+	t.Run("空のファイルへの追加", func(t *testing.T) {
+		assert.Equal(t, "--- a\n+++ b\n@@ -0,0 +1 @@\n+x\n", unifiedDiff("a", "b", diffLines(nil, []string{"x"})))
+	})
+
+	t.Run("変更がない場合は空", func(t *testing.T) {
+		assert.Empty(t, unifiedDiff("a", "b", diffLines([]string{"x"}, []string{"x"})))
+	})
+}
+
+func TestSplitDiffLines(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, splitDiffLines("a\r\nb\n"))
+	assert.Nil(t, splitDiffLines(""))
+}
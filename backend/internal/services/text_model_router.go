@@ -0,0 +1,118 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/pkg/aws"
+)
+
+// 既定のテキスト生成モデルを切り替える単位 (エンドポイント)
+const (
+	EndpointSummarize = "summarize"
+	EndpointQA        = "qa"
+	EndpointExtract   = "extract"
+)
+
+// ErrModelNotAllowed は許可リストにないモデルがリクエストで指定された場合のエラー
+var ErrModelNotAllowed = errors.New("指定されたモデルは利用を許可されていません")
+
+// TextModelRouter はリクエストで指定されたモデルを許可リストで検証し、エンドポイントごとの既定モデルを決定する
+type TextModelRouter struct {
+	allowed  []aws.TextModel
+	defaults map[string]aws.TextModel
+}
+
+// NewTextModelRouter は設定からTextModelRouterを作成する
+// 既定モデルがカタログにない、または許可リストに含まれない場合はエラーを返す
+func NewTextModelRouter(cfg *config.Config) (*TextModelRouter, error) {
+	names := cfg.AWS.TextModelAllowList
+	if len(names) == 0 {
+		names = aws.TextModelNames()
+	}
+
+	r := &TextModelRouter{defaults: make(map[string]aws.TextModel)}
+	for _, name := range names {
+		model, err := aws.LookupTextModel(name)
+		if err != nil {
+			return nil, fmt.Errorf("許可リストのモデルが不正です: %w", err)
+		}
+		r.allowed = append(r.allowed, model)
+	}
+
+	for endpoint, name := range map[string]string{
+		EndpointSummarize: cfg.AWS.SummarizeModel,
+		EndpointQA:        cfg.AWS.QAModel,
+		EndpointExtract:   cfg.AWS.ExtractModel,
+	} {
+		if name == "" {
+			name = cfg.AWS.BedrockModelID
+		}
+		model, err := r.lookupAllowed(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s の既定モデルが不正です: %w", endpoint, err)
+		}
+		r.defaults[endpoint] = model
+	}
+	return r, nil
+}
+
+// Resolve はエンドポイントで使用するモデルを返す (requested が空の場合はエンドポイントの既定モデル)
+func (r *TextModelRouter) Resolve(endpoint, requested string) (aws.TextModel, error) {
+	if requested == "" {
+		model, ok := r.defaults[endpoint]
+		if !ok {
+			return aws.TextModel{}, fmt.Errorf("エンドポイント %s の既定モデルが設定されていません", endpoint)
+		}
+		return model, nil
+	}
+	return r.lookupAllowed(requested)
+}
+
+// Allowed はリクエストで指定できるモデルの一覧を返す
+func (r *TextModelRouter) Allowed() []aws.TextModel {
+	return slices.Clone(r.allowed)
+}
+
+// Defaults はエンドポイントごとの既定モデルのカタログ名を返す
+func (r *TextModelRouter) Defaults() map[string]string {
+	defaults := make(map[string]string, len(r.defaults))
+	for endpoint, model := range r.defaults {
+		defaults[endpoint] = model.Name
+	}
+	return defaults
+}
+
+// lookupAllowed はカタログからモデルを取得し、許可リストに含まれることを確認する
+func (r *TextModelRouter) lookupAllowed(nameOrID string) (aws.TextModel, error) {
+	model, err := aws.LookupTextModel(nameOrID)
+	if err != nil {
+		return aws.TextModel{}, err
+	}
+	if !slices.ContainsFunc(r.allowed, func(m aws.TextModel) bool { return m.Name == model.Name }) {
+		return aws.TextModel{}, fmt.Errorf("%w: %s", ErrModelNotAllowed, model.Name)
+	}
+	return model, nil
+}
+
+// textClientFor はエンドポイントとリクエストに応じたモデルを使用するクライアントを返す
+// router が nil の場合はモデルの指定を受け付けず、client の既定モデルを使用する
+func textClientFor(client aws.BedrockClientInterface, router *TextModelRouter, endpoint, requested string) (aws.BedrockClientInterface, string, error) {
+	if router == nil {
+		if requested != "" {
+			return nil, "", fmt.Errorf("%w: %s (モデルの選択は設定されていません)", ErrModelNotAllowed, requested)
+		}
+		return client, "", nil
+	}
+
+	model, err := router.Resolve(endpoint, requested)
+	if err != nil {
+		return nil, "", err
+	}
+	if client.TextModel().Name == model.Name {
+		return client, model.Name, nil
+	}
+	return client.WithTextModel(model), model.Name, nil
+}
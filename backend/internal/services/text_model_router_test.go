@@ -0,0 +1,141 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+	"bedrock-rag-sample/backend/pkg/aws"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouterConfig() *config.Config {
+	return &config.Config{
+		AWS: config.AWSConfig{
+			Region:             "us-east-1",
+			KnowledgeBaseID:    "test-kb-id",
+			BedrockModelID:     "anthropic.claude-3-haiku-20240307-v1:0",
+			TextModelAllowList: []string{"claude-3-haiku", "claude-3-5-sonnet", "titan-text-lite"},
+			SummarizeModel:     "titan-text-lite",
+			QAModel:            "claude-3-5-sonnet",
+		},
+	}
+}
+
+func TestTextModelRouter_Resolve(t *testing.T) {
+	router, err := services.NewTextModelRouter(newTestRouterConfig())
+	require.NoError(t, err)
+
+	t.Run("指定がない場合はエンドポイントの既定モデル", func(t *testing.T) {
+		summarize, err := router.Resolve(services.EndpointSummarize, "")
+		require.NoError(t, err)
+		qa, err := router.Resolve(services.EndpointQA, "")
+		require.NoError(t, err)
+
+		assert.Equal(t, "titan-text-lite", summarize.Name)
+		assert.Equal(t, "claude-3-5-sonnet", qa.Name)
+	})
+
+	t.Run("許可リストのモデルを指定できる", func(t *testing.T) {
+		model, err := router.Resolve(services.EndpointSummarize, "claude-3-haiku")
+		require.NoError(t, err)
+		assert.Equal(t, "claude-3-haiku", model.Name)
+	})
+
+	t.Run("異常系_許可リストにないモデル", func(t *testing.T) {
+		_, err := router.Resolve(services.EndpointQA, "llama3-70b-instruct")
+		assert.ErrorIs(t, err, services.ErrModelNotAllowed)
+	})
+
+	t.Run("異常系_カタログにないモデル", func(t *testing.T) {
+		_, err := router.Resolve(services.EndpointQA, "unknown")
+		assert.ErrorIs(t, err, aws.ErrUnknownTextModel)
+	})
+}
+
+func TestNewTextModelRouter(t *testing.T) {
+	t.Run("許可リストが空の場合はカタログの全モデルを許可する", func(t *testing.T) {
+		cfg := newTestRouterConfig()
+		cfg.AWS.TextModelAllowList = nil
+
+		router, err := services.NewTextModelRouter(cfg)
+
+		require.NoError(t, err)
+		assert.Len(t, router.Allowed(), len(aws.TextModelNames()))
+	})
+
+	t.Run("異常系_既定モデルが許可リストにない", func(t *testing.T) {
+		cfg := newTestRouterConfig()
+		cfg.AWS.QAModel = "mistral-large"
+
+		_, err := services.NewTextModelRouter(cfg)
+
+		assert.ErrorIs(t, err, services.ErrModelNotAllowed)
+	})
+}
+
+func TestTextModelRouting(t *testing.T) {
+	ctx := context.Background()
+	router, err := services.NewTextModelRouter(newTestRouterConfig())
+	require.NoError(t, err)
+	haiku, _ := aws.LookupTextModel("claude-3-haiku")
+
+	t.Run("要約はリクエストで指定したモデルで生成する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		mockSonnetClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+		summarizeService := services.NewSummarizeService(mockBedrockClient, nil, router, nil)
+
+		mockBedrockClient.EXPECT().TextModel().Return(haiku)
+		mockBedrockClient.EXPECT().
+			WithTextModel(gomock.Any()).
+			DoAndReturn(func(model aws.TextModel) aws.BedrockClientInterface {
+				assert.Equal(t, "claude-3-5-sonnet", model.Name)
+				return mockSonnetClient
+			})
+		mockSonnetClient.EXPECT().GenerateSummary(ctx, "本文").Return("要約", nil)
+
+		result, err := summarizeService.SummarizeText(ctx, "本文", "claude-3-5-sonnet")
+
+		require.NoError(t, err)
+		assert.Equal(t, "要約", result.Summary)
+		assert.Equal(t, "claude-3-5-sonnet", result.Model)
+	})
+
+	t.Run("QAは既定モデルがクライアントと同じ場合はそのまま使用する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockBedrockClient := servicemocks.NewMockBedrockClientInterface(ctrl)
+
+		cfg := newTestRouterConfig()
+		cfg.AWS.QAModel = ""
+		haikuRouter, err := services.NewTextModelRouter(cfg)
+		require.NoError(t, err)
+		qaService, err := services.NewQAService(mockBedrockClient, cfg, haikuRouter, nil, nil)
+		require.NoError(t, err)
+
+		mockBedrockClient.EXPECT().TextModel().Return(haiku)
+		mockBedrockClient.EXPECT().GenerateText(ctx, gomock.Any()).Return("回答", nil)
+
+		result, err := qaService.SimpleRAG(ctx, "質問", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "claude-3-haiku", result.Model)
+	})
+
+	t.Run("異常系_許可リストにないモデルはBedrockを呼び出さない", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		summarizeService := services.NewSummarizeService(servicemocks.NewMockBedrockClientInterface(ctrl), nil, router, nil)
+
+		_, err := summarizeService.SummarizeText(ctx, "本文", "mistral-large")
+
+		assert.ErrorIs(t, err, services.ErrModelNotAllowed)
+	})
+}
@@ -30,19 +30,8 @@ type UploadFileResult struct {
 
 // UploadFile はファイルをS3にアップロードする
 func (s *UploadService) UploadFile(ctx context.Context, file *multipart.FileHeader) (*UploadFileResult, error) {
-	// ファイルの拡張子を取得
-	ext := filepath.Ext(file.Filename)
-	var folderPath string
-
 	// ファイル種類に応じてフォルダを分ける
-	switch ext {
-	case ".pdf":
-		folderPath = "pdf"
-	case ".png", ".jpg", ".jpeg":
-		folderPath = "images"
-	default:
-		folderPath = "others"
-	}
+	folderPath := uploadFolderFor(filepath.Ext(file.Filename))
 
 	// S3にアップロード
 	key, err := s.s3Client.UploadFile(ctx, file, folderPath)
@@ -64,6 +53,18 @@ func (s *UploadService) UploadFile(ctx context.Context, file *multipart.FileHead
 	}, nil
 }
 
+// uploadFolderFor はファイルの拡張子に応じたS3内のフォルダを返す
+func uploadFolderFor(ext string) string {
+	switch ext {
+	case ".pdf":
+		return "pdf"
+	case ".png", ".jpg", ".jpeg":
+		return "images"
+	default:
+		return "others"
+	}
+}
+
 // GetS3Client は内部の S3 クライアントインターフェースを返す
 func (s *UploadService) GetS3Client() aws.S3ClientInterface {
 	return s.s3Client
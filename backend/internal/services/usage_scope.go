@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"sync/atomic"
+)
+
+// リクエストに紐付かない呼び出し (バックグラウンド処理など) の使用量の帰属先
+const (
+	UsageTenantSystem       = "system"
+	UsageEndpointBackground = "background"
+)
+
+// UsageScope はモデル呼び出しの使用量を帰属させるリクエストの情報
+type UsageScope struct {
+	RequestID string // ログと使用量の記録用 (クライアントが X-Request-ID で指定した値の場合があるため、識別には使わない)
+	Tenant    string // テナント (X-Tenant-ID またはAPIキーから決定する)
+	Endpoint  string // APIのルート (例: "/api/v1/qa")
+
+	id      string       // サーバーがリクエストごとに採番したID (使用量の記録に残し、DBでの日次リクエスト数の集計に使う)
+	counted *atomic.Bool // 日次リクエスト数に加算したか (WithUsageScope が受け付けたリクエストごとに作成する)
+}
+
+type usageScopeKey struct{}
+
+// WithUsageScope は使用量の帰属先を ctx に設定する
+// 受け付けたリクエストごとに1回呼び出すこと (日次リクエスト数は、ここで設定した帰属先ごとに1回だけ数える)
+func WithUsageScope(ctx context.Context, scope UsageScope) context.Context {
+	scope.id = rand.Text()
+	scope.counted = new(atomic.Bool)
+	return context.WithValue(ctx, usageScopeKey{}, scope)
+}
+
+// UsageScopeFromContext は ctx に設定された使用量の帰属先を返す
+// 設定されていない場合はバックグラウンド処理として扱う
+func UsageScopeFromContext(ctx context.Context) UsageScope {
+	scope, _ := ctx.Value(usageScopeKey{}).(UsageScope)
+	if scope.Tenant == "" {
+		scope.Tenant = UsageTenantSystem
+	}
+	if scope.Endpoint == "" {
+		scope.Endpoint = UsageEndpointBackground
+	}
+	return scope
+}
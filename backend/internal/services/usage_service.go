@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/pkg/aws"
+
+	"github.com/rs/zerolog/log"
+)
+
+// usageReportDateLayout は使用量レポートの日付の形式
+const usageReportDateLayout = "2006-01-02"
+
+// usageRecordTimeout は使用量の保存のタイムアウト (リクエストのキャンセル後も保存する)
+const usageRecordTimeout = 5 * time.Second
+
+// ErrInvalidUsagePeriod は使用量レポートの期間が不正な場合のエラー
+var ErrInvalidUsagePeriod = errors.New("集計期間が不正です")
+
+// defaultModelPrices はモデルのカタログ名ごとの既定の料金 (USD / 1,000トークン、us-east-1 のオンデマンド料金)
+// 料金の改定やリージョン差は USAGE_MODEL_PRICES で上書きする
+var defaultModelPrices = map[string]config.ModelPrice{
+	"claude-3-haiku":      {InputPer1K: 0.00025, OutputPer1K: 0.00125},
+	"claude-3-sonnet":     {InputPer1K: 0.003, OutputPer1K: 0.015},
+	"claude-3-5-sonnet":   {InputPer1K: 0.003, OutputPer1K: 0.015},
+	"llama3-8b-instruct":  {InputPer1K: 0.0003, OutputPer1K: 0.0006},
+	"llama3-70b-instruct": {InputPer1K: 0.00265, OutputPer1K: 0.0035},
+	"mistral-7b-instruct": {InputPer1K: 0.00015, OutputPer1K: 0.0002},
+	"mistral-large":       {InputPer1K: 0.004, OutputPer1K: 0.012},
+	"titan-text-express":  {InputPer1K: 0.0002, OutputPer1K: 0.0006},
+	"titan-text-lite":     {InputPer1K: 0.00015, OutputPer1K: 0.0002},
+
+	"titan-v1":               {InputPer1K: 0.0001},
+	"titan-v2-256":           {InputPer1K: 0.00002},
+	"titan-v2-512":           {InputPer1K: 0.00002},
+	"titan-v2-1024":          {InputPer1K: 0.00002},
+	"cohere-multilingual-v3": {InputPer1K: 0.0001},
+}
+
+// PriceTable はモデルごとの料金表
+type PriceTable map[string]config.ModelPrice
+
+// NewPriceTable は既定の料金表に overrides を上書きした料金表を作成する
+func NewPriceTable(overrides map[string]config.ModelPrice) PriceTable {
+	table := make(PriceTable, len(defaultModelPrices)+len(overrides))
+	for name, price := range defaultModelPrices {
+		table[name] = price
+	}
+	for name, price := range overrides {
+		table[name] = price
+	}
+	return table
+}
+
+// Cost は使用量の料金 (USD) を返す
+// 料金表はカタログ名で引き、見つからなければモデルIDで引く。どちらにもなければ false を返す
+func (t PriceTable) Cost(event aws.UsageEvent) (float64, bool) {
+	price, ok := t[event.Model]
+	if !ok {
+		price, ok = t[event.ModelID]
+	}
+	if !ok {
+		return 0, false
+	}
+	return float64(event.InputTokens)/1000*price.InputPer1K + float64(event.OutputTokens)/1000*price.OutputPer1K, true
+}
+
+// UsageReportRow は日・エンドポイント・テナントごとの使用量
+type UsageReportRow struct {
+	Day          string  `json:"day"` // UTC の日付 (YYYY-MM-DD)
+	Endpoint     string  `json:"endpoint"`
+	Tenant       string  `json:"tenant"`
+	Requests     int     `json:"requests"` // APIリクエスト数
+	Calls        int     `json:"calls"`    // モデルの呼び出し回数
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// UsageTotals は集計期間全体の使用量
+type UsageTotals struct {
+	Calls        int     `json:"calls"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// UsageReport は使用量レポート
+type UsageReport struct {
+	From  string           `json:"from"` // 集計開始日 (含む)
+	To    string           `json:"to"`   // 集計終了日 (含む)
+	Rows  []UsageReportRow `json:"rows"`
+	Total UsageTotals      `json:"total"`
+}
+
+// UsageReportQuery は使用量レポートの条件
+type UsageReportQuery struct {
+	From     time.Time // 集計開始日 (UTC、含む)
+	To       time.Time // 集計終了日 (UTC、含む)
+	Tenant   string
+	Endpoint string
+}
+
+// UsageService はモデル呼び出しのトークン使用量と料金を記録・集計するサービス
+type UsageService struct {
+	dbHandler domain.DBHandlerInterface
+	prices    PriceTable
+	now       func() time.Time
+}
+
+// NewUsageService は新しいUsageServiceを作成する
+func NewUsageService(dbHandler domain.DBHandlerInterface, prices PriceTable) *UsageService {
+	return &UsageService{
+		dbHandler: dbHandler,
+		prices:    prices,
+		now:       time.Now,
+	}
+}
+
+// RecordUsage は使用量を料金表で値付けして保存する (aws.UsageRecorder の実装)
+// 保存に失敗してもモデルの呼び出し結果には影響させず、ログに残す
+func (s *UsageService) RecordUsage(ctx context.Context, event aws.UsageEvent) {
+	scope := UsageScopeFromContext(ctx)
+	cost, priced := s.prices.Cost(event)
+	if !priced {
+		log.Warn().Str("model", event.Model).Str("model_id", event.ModelID).Msg("No price configured for model; usage is recorded at zero cost")
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), usageRecordTimeout)
+	defer cancel()
+
+	err := s.dbHandler.SaveUsageRecord(ctx, domain.UsageRecord{
+		RequestID:    scope.RequestID,
+		ScopeID:      scope.id,
+		Tenant:       scope.Tenant,
+		Endpoint:     scope.Endpoint,
+		Model:        event.Model,
+		Operation:    event.Operation,
+		InputTokens:  event.InputTokens,
+		OutputTokens: event.OutputTokens,
+		CostUSD:      cost,
+		CreatedAt:    s.now(),
+	})
+	if err != nil {
+		log.Error().Err(err).
+			Str("request_id", scope.RequestID).
+			Str("tenant", scope.Tenant).
+			Str("model", event.Model).
+			Int("input_tokens", event.InputTokens).
+			Int("output_tokens", event.OutputTokens).
+			Msg("Failed to record model usage")
+	}
+}
+
+// Report は期間内の使用量を日・エンドポイント・テナントごとに集計する
+func (s *UsageService) Report(ctx context.Context, query UsageReportQuery) (*UsageReport, error) {
+	from := truncateToDay(query.From)
+	to := truncateToDay(query.To)
+	if to.Before(from) {
+		return nil, ErrInvalidUsagePeriod
+	}
+
+	aggregates, err := s.dbHandler.AggregateUsage(ctx, domain.UsageFilter{
+		From:     from,
+		To:       to.AddDate(0, 0, 1),
+		Tenant:   query.Tenant,
+		Endpoint: query.Endpoint,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UsageReport{
+		From: from.Format(usageReportDateLayout),
+		To:   to.Format(usageReportDateLayout),
+		Rows: make([]UsageReportRow, 0, len(aggregates)),
+	}
+	for _, a := range aggregates {
+		report.Rows = append(report.Rows, UsageReportRow{
+			Day:          a.Day.UTC().Format(usageReportDateLayout),
+			Endpoint:     a.Endpoint,
+			Tenant:       a.Tenant,
+			Requests:     a.Requests,
+			Calls:        a.Calls,
+			InputTokens:  a.InputTokens,
+			OutputTokens: a.OutputTokens,
+			CostUSD:      a.CostUSD,
+		})
+		report.Total.Calls += a.Calls
+		report.Total.InputTokens += a.InputTokens
+		report.Total.OutputTokens += a.OutputTokens
+		report.Total.CostUSD += a.CostUSD
+	}
+	return report, nil
+}
+
+// truncateToDay は UTC の日の始まりに切り捨てる
+func truncateToDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
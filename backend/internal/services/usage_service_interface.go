@@ -0,0 +1,18 @@
+package services
+
+import (
+	"context"
+
+	"bedrock-rag-sample/backend/pkg/aws"
+)
+
+// UsageServiceInterface は使用量サービスのインターフェース
+type UsageServiceInterface interface {
+	Report(ctx context.Context, query UsageReportQuery) (*UsageReport, error)
+}
+
+// インターフェースを実装していることを静的にチェック
+var (
+	_ UsageServiceInterface = (*UsageService)(nil)
+	_ aws.UsageRecorder     = (*UsageService)(nil)
+)
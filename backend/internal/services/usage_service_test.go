@@ -0,0 +1,173 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	domainmocks "bedrock-rag-sample/backend/internal/domain/mocks"
+	"bedrock-rag-sample/backend/internal/services"
+	"bedrock-rag-sample/backend/pkg/aws"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceTable_Cost(t *testing.T) {
+	prices := services.NewPriceTable(map[string]config.ModelPrice{
+		"claude-3-haiku":         {InputPer1K: 0.001, OutputPer1K: 0.002},
+		"meta.custom-model-v1:0": {InputPer1K: 0.01},
+	})
+
+	t.Run("上書きした料金で計算する", func(t *testing.T) {
+		cost, ok := prices.Cost(aws.UsageEvent{Model: "claude-3-haiku", InputTokens: 2000, OutputTokens: 500})
+		require.True(t, ok)
+		assert.InDelta(t, 0.003, cost, 1e-12)
+	})
+
+	t.Run("上書きしていないモデルは既定の料金", func(t *testing.T) {
+		cost, ok := prices.Cost(aws.UsageEvent{Model: "titan-v2-1024", InputTokens: 1000})
+		require.True(t, ok)
+		assert.InDelta(t, 0.00002, cost, 1e-12)
+	})
+
+	t.Run("カタログ名がなければモデルIDで引く", func(t *testing.T) {
+		cost, ok := prices.Cost(aws.UsageEvent{Model: "custom", ModelID: "meta.custom-model-v1:0", InputTokens: 1000})
+		require.True(t, ok)
+		assert.InDelta(t, 0.01, cost, 1e-12)
+	})
+
+	t.Run("料金表にないモデル", func(t *testing.T) {
+		_, ok := prices.Cost(aws.UsageEvent{Model: "unknown"})
+		assert.False(t, ok)
+	})
+}
+
+func TestUsageService_RecordUsage(t *testing.T) {
+	event := aws.UsageEvent{Model: "claude-3-haiku", ModelID: "anthropic.claude-3-haiku-20240307-v1:0", Operation: aws.UsageOperationConverse, InputTokens: 1000, OutputTokens: 200}
+
+	t.Run("リクエストの帰属先と料金を保存する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		usageService := services.NewUsageService(mockDBHandler, services.NewPriceTable(nil))
+
+		ctx := services.WithUsageScope(context.Background(), services.UsageScope{RequestID: "req-1", Tenant: "tenant-a", Endpoint: "/api/v1/qa"})
+		mockDBHandler.EXPECT().
+			SaveUsageRecord(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, record domain.UsageRecord) error {
+				assert.Equal(t, "req-1", record.RequestID)
+				assert.Equal(t, "tenant-a", record.Tenant)
+				assert.Equal(t, "/api/v1/qa", record.Endpoint)
+				assert.Equal(t, "claude-3-haiku", record.Model)
+				assert.Equal(t, aws.UsageOperationConverse, record.Operation)
+				assert.Equal(t, 1000, record.InputTokens)
+				assert.Equal(t, 200, record.OutputTokens)
+				assert.InDelta(t, 0.0005, record.CostUSD, 1e-12)
+				assert.False(t, record.CreatedAt.IsZero())
+				return nil
+			})
+
+		usageService.RecordUsage(ctx, event)
+	})
+
+	t.Run("リクエストIDが同じでも受け付けたリクエストごとに別のIDで保存する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		usageService := services.NewUsageService(mockDBHandler, services.NewPriceTable(nil))
+
+		var scopeIDs []string
+		mockDBHandler.EXPECT().
+			SaveUsageRecord(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, record domain.UsageRecord) error {
+				scopeIDs = append(scopeIDs, record.ScopeID)
+				return nil
+			}).
+			Times(3)
+
+		// 1件目のリクエストはモデルを2回呼び出す
+		first := services.WithUsageScope(context.Background(), services.UsageScope{RequestID: "fixed", Tenant: "tenant-a"})
+		usageService.RecordUsage(first, event)
+		usageService.RecordUsage(first, event)
+		second := services.WithUsageScope(context.Background(), services.UsageScope{RequestID: "fixed", Tenant: "tenant-a"})
+		usageService.RecordUsage(second, event)
+
+		require.Len(t, scopeIDs, 3)
+		assert.NotEmpty(t, scopeIDs[0])
+		assert.Equal(t, scopeIDs[0], scopeIDs[1])
+		assert.NotEqual(t, scopeIDs[0], scopeIDs[2])
+	})
+
+	t.Run("キャンセルされたリクエストでも保存する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		usageService := services.NewUsageService(mockDBHandler, services.NewPriceTable(nil))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		mockDBHandler.EXPECT().
+			SaveUsageRecord(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, record domain.UsageRecord) error {
+				assert.NoError(t, ctx.Err())
+				assert.Equal(t, services.UsageTenantSystem, record.Tenant)
+				assert.Equal(t, services.UsageEndpointBackground, record.Endpoint)
+				return nil
+			})
+
+		usageService.RecordUsage(ctx, event)
+	})
+
+	t.Run("保存に失敗してもパニックしない", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		usageService := services.NewUsageService(mockDBHandler, services.NewPriceTable(nil))
+
+		mockDBHandler.EXPECT().SaveUsageRecord(gomock.Any(), gomock.Any()).Return(errors.New("db down"))
+
+		usageService.RecordUsage(context.Background(), event)
+	})
+}
+
+func TestUsageService_Report(t *testing.T) {
+	ctx := context.Background()
+	day1 := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	t.Run("正常系: 終了日を含めて集計し、合計を計算する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockDBHandler := domainmocks.NewMockDBHandlerInterface(ctrl)
+		usageService := services.NewUsageService(mockDBHandler, services.NewPriceTable(nil))
+
+		mockDBHandler.EXPECT().
+			AggregateUsage(ctx, domain.UsageFilter{From: day1, To: day2.AddDate(0, 0, 1), Tenant: "tenant-a"}).
+			Return([]domain.UsageAggregate{
+				{Day: day1, Endpoint: "/api/v1/qa", Tenant: "tenant-a", Requests: 2, Calls: 4, InputTokens: 1000, OutputTokens: 100, CostUSD: 0.5},
+				{Day: day2, Endpoint: "/api/v1/summarize/text", Tenant: "tenant-a", Requests: 1, Calls: 1, InputTokens: 300, OutputTokens: 50, CostUSD: 0.25},
+			}, nil)
+
+		report, err := usageService.Report(ctx, services.UsageReportQuery{From: day1.Add(5 * time.Hour), To: day2, Tenant: "tenant-a"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "2026-10-01", report.From)
+		assert.Equal(t, "2026-10-02", report.To)
+		require.Len(t, report.Rows, 2)
+		assert.Equal(t, "2026-10-02", report.Rows[1].Day)
+		assert.Equal(t, services.UsageTotals{Calls: 5, InputTokens: 1300, OutputTokens: 150, CostUSD: 0.75}, report.Total)
+	})
+
+	t.Run("異常系: 終了日が開始日より前", func(t *testing.T) {
+		usageService := services.NewUsageService(nil, services.NewPriceTable(nil))
+
+		_, err := usageService.Report(ctx, services.UsageReportQuery{From: day2, To: day1})
+
+		assert.ErrorIs(t, err, services.ErrInvalidUsagePeriod)
+	})
+}
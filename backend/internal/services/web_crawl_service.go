@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	"bedrock-rag-sample/backend/pkg/aws"
+	"bedrock-rag-sample/backend/pkg/webcrawl"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrInvalidCrawlConfig はクロールの設定が不正な場合のエラー
+var ErrInvalidCrawlConfig = errors.New("クロールの設定が不正です")
+
+const (
+	// maxSitemapFetches は1回のクロールで取得するサイトマップ (インデックスから参照されるものを含む) の数の上限
+	maxSitemapFetches = 50
+	// crawlLeaseInterval はページを変更しなかった場合にもリースを延長する間隔
+	crawlLeaseInterval = syncLeaseTTL / 5
+	// maxPageFilenameLength はタイトルから作るファイル名の長さの上限 (文字数)
+	maxPageFilenameLength = 100
+)
+
+// WebCrawlService はWebページをクロールして取り込み、documents テーブルと同期するサービス
+// シードのページとサイトマップのページから、許可したドメインの中でリンクをたどり (robots.txt に従う)、
+// ページの本文を正規化したURLを取り込み元 (引用の位置) として取り込む。取得したHTMLはS3にスナップショットとして保存する
+// 再クロールでは ETag・Last-Modified による条件付きリクエストで変更を検出し、見つからなくなったページのドキュメントを削除する
+// クロールは途中から再開できないため、中断した場合は次回に最初からクロールする (変更のないページは条件付きリクエストで済む)
+type WebCrawlService struct {
+	*syncJob
+	s3Client      aws.S3ClientInterface
+	dbHandler     domain.DBHandlerInterface
+	ingestService IngestServiceInterface
+	cfg           config.CrawlConfig
+	domains       []string
+}
+
+// NewWebCrawlService は新しいWebCrawlServiceを作成する
+func NewWebCrawlService(s3Client aws.S3ClientInterface, dbHandler domain.DBHandlerInterface, ingestService IngestServiceInterface, runner BackgroundRunner, cfg *config.Config) (*WebCrawlService, error) {
+	crawl := cfg.Crawl
+	if len(crawl.Seeds) == 0 && len(crawl.Sitemaps) == 0 {
+		return nil, fmt.Errorf("%w: シード (CRAWL_SEEDS) またはサイトマップ (CRAWL_SITEMAPS) を指定してください", ErrInvalidCrawlConfig)
+	}
+	if crawl.MaxDepth < 0 || crawl.MaxPages <= 0 {
+		return nil, fmt.Errorf("%w: 深さ (CRAWL_MAX_DEPTH) は0以上、ページ数 (CRAWL_MAX_PAGES) は正の値を指定してください", ErrInvalidCrawlConfig)
+	}
+
+	domains := make([]string, 0, len(crawl.AllowedDomains))
+	for _, d := range crawl.AllowedDomains {
+		domains = append(domains, strings.ToLower(strings.TrimPrefix(d, ".")))
+	}
+	for _, raw := range append(slices.Clone(crawl.Seeds), crawl.Sitemaps...) {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: %s は http または https のURLではありません", ErrInvalidCrawlConfig, raw)
+		}
+		if len(crawl.AllowedDomains) == 0 {
+			domains = append(domains, strings.ToLower(u.Hostname()))
+		}
+	}
+	slices.Sort(domains)
+	domains = slices.Compact(domains)
+
+	s := &WebCrawlService{
+		syncJob:       newSyncJob("web-crawl", "web:"+strings.Join(domains, ","), dbHandler, runner),
+		s3Client:      s3Client,
+		dbHandler:     dbHandler,
+		ingestService: ingestService,
+		cfg:           crawl,
+		domains:       domains,
+	}
+	s.pass = s.crawl
+	return s, nil
+}
+
+// StartSchedule は設定した間隔 (CRAWL_INTERVAL) でクロールするバックグラウンドタスクを開始する
+func (s *WebCrawlService) StartSchedule(stop context.Context) error {
+	return s.startSchedule(stop, s.cfg.Interval)
+}
+
+// crawlItem はクロールするページ
+type crawlItem struct {
+	url   string
+	depth int
+}
+
+// crawler は1回のクロールの状態
+type crawler struct {
+	*WebCrawlService
+	client         *webcrawl.Client
+	state          *domain.SyncState
+	known          map[string][]domain.Document // 取り込み元のURL → 取り込んだドキュメント (IDの順)
+	seen           map[string]bool
+	queue          []crawlItem
+	fetched        int
+	lastCheckpoint time.Time
+}
+
+// crawl はシードとサイトマップからページを幅優先でクロールし、その後で到達しなかった取り込み済みのページを取得し直す
+func (s *WebCrawlService) crawl(ctx context.Context, state *domain.SyncState) error {
+	c := &crawler{
+		WebCrawlService: s,
+		client: webcrawl.NewClient(webcrawl.Options{
+			UserAgent: s.cfg.UserAgent,
+			Delay:     s.cfg.Delay,
+			Timeout:   s.cfg.Timeout,
+		}),
+		state:          state,
+		known:          make(map[string][]domain.Document),
+		seen:           make(map[string]bool),
+		lastCheckpoint: time.Now(),
+	}
+	for _, prefix := range []string{"http://", "https://"} {
+		docs, err := s.dbHandler.ListDocumentsBySource(ctx, domain.SourceRange{Prefix: prefix})
+		if err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			if s.allowed(doc.SourceURI) {
+				c.known[doc.SourceURI] = append(c.known[doc.SourceURI], doc)
+			}
+		}
+	}
+
+	for _, seed := range s.cfg.Seeds {
+		c.enqueue(seed, 0)
+	}
+	if err := c.enqueueSitemaps(ctx); err != nil {
+		return err
+	}
+
+	if err := c.drain(ctx); err != nil {
+		return err
+	}
+
+	// リンクをたどって到達しなかったページも、削除されていなければ取り込んだままにする (リンクはたどらない)
+	// 上限のページ数に達した場合、残りのページは次回のクロールで確認する
+	remaining := make([]string, 0, len(c.known))
+	for u := range c.known {
+		if !c.seen[u] {
+			remaining = append(remaining, u)
+		}
+	}
+	slices.Sort(remaining)
+	for _, u := range remaining {
+		if c.fetched >= s.cfg.MaxPages {
+			break
+		}
+		c.seen[u] = true
+		if err := c.visit(ctx, crawlItem{url: u, depth: s.cfg.MaxDepth}); err != nil {
+			return err
+		}
+		// リダイレクトされた場合はリダイレクト先を取得する
+		if err := c.drain(ctx); err != nil {
+			return err
+		}
+	}
+	return s.complete(ctx, state)
+}
+
+// drain はクロールするページがなくなるか、上限のページ数に達するまでクロールする
+func (c *crawler) drain(ctx context.Context) error {
+	for len(c.queue) > 0 && c.fetched < c.cfg.MaxPages {
+		item := c.queue[0]
+		c.queue = c.queue[1:]
+		if err := c.visit(ctx, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allowed はURLのホストがクロールを許可したドメイン (またはそのサブドメイン) かどうかを返す
+func (s *WebCrawlService) allowed(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, d := range s.domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue はURLを正規化してクロールするページに加える (許可したドメインの外と、既に加えたURLは除く)
+func (c *crawler) enqueue(rawURL string, depth int) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return
+	}
+	normalized := webcrawl.NormalizeURL(u)
+	if depth > c.cfg.MaxDepth || c.seen[normalized] || !c.allowed(normalized) {
+		return
+	}
+	c.seen[normalized] = true
+	c.queue = append(c.queue, crawlItem{url: normalized, depth: depth})
+}
+
+// enqueueSitemaps はサイトマップに列挙されたページを深さ0のページとして加える
+// サイトマップを取得できない場合は、そのサイトマップを読み飛ばしてクロールを続ける
+func (c *crawler) enqueueSitemaps(ctx context.Context) error {
+	pending := slices.Clone(c.cfg.Sitemaps)
+	fetched := make(map[string]bool)
+	for len(pending) > 0 && len(fetched) < maxSitemapFetches {
+		sitemapURL := pending[0]
+		pending = pending[1:]
+		if fetched[sitemapURL] || !c.allowed(sitemapURL) {
+			continue
+		}
+		fetched[sitemapURL] = true
+
+		resp, err := c.client.Fetch(ctx, sitemapURL, webcrawl.Conditional{})
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			log.Warn().Err(err).Str("sitemap", sitemapURL).Msg("Failed to fetch sitemap")
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			log.Warn().Int("status", resp.StatusCode).Str("sitemap", sitemapURL).Msg("Failed to fetch sitemap")
+			continue
+		}
+		sitemap, err := webcrawl.ParseSitemap(resp.Body)
+		if err != nil {
+			log.Warn().Err(err).Str("sitemap", sitemapURL).Msg("Failed to parse sitemap")
+			continue
+		}
+		for _, u := range sitemap.URLs {
+			c.enqueue(u, 0)
+		}
+		pending = append(pending, sitemap.Sitemaps...)
+	}
+	return nil
+}
+
+// visit はページを1件クロールし、ページを変更した場合やリースの延長が必要な場合は処理件数を記録する
+func (c *crawler) visit(ctx context.Context, item crawlItem) error {
+	c.fetched++
+	changed, err := c.crawlPage(ctx, item)
+	if err != nil {
+		return err
+	}
+	if changed || time.Since(c.lastCheckpoint) >= crawlLeaseInterval {
+		c.lastCheckpoint = time.Now()
+		return c.checkpoint(ctx, c.state)
+	}
+	return nil
+}
+
+// crawlPage はページを取得して取り込み、リンクをクロールするページに加える。ドキュメントを変更したかどうかを返す
+// 1ページの取得・取り込みの失敗は件数に数えて続行し、DBへのアクセスなどクロールを続けられないエラーのみを返す
+func (c *crawler) crawlPage(ctx context.Context, item crawlItem) (bool, error) {
+	stats := &c.state.Stats
+	docs := c.known[item.url]
+	var latest *domain.Document
+	cond := webcrawl.Conditional{}
+	if n := len(docs); n > 0 {
+		latest = &docs[n-1]
+		cond.ETag = latest.SourceVersion
+		if latest.SourceModifiedAt != nil {
+			cond.LastModified = *latest.SourceModifiedAt
+		}
+	}
+	logger := log.With().Str("source_uri", item.url).Logger()
+
+	resp, err := c.client.Fetch(ctx, item.url, cond)
+	switch {
+	case errors.Is(err, webcrawl.ErrDisallowed):
+		// robots.txt で禁止されたページは取り込んだドキュメントも削除する
+		return c.removePage(ctx, item.url, docs)
+	case err != nil:
+		if ctx.Err() != nil {
+			return false, err
+		}
+		logger.Warn().Err(err).Msg("Failed to fetch page")
+		stats.Failed++
+		return false, nil
+	case resp.NotModified:
+		stats.Unchanged++
+		c.enqueueLinks(item, c.snapshotLinks(ctx, item))
+		return false, nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return c.removePage(ctx, item.url, docs)
+	case resp.StatusCode != http.StatusOK:
+		logger.Warn().Int("status", resp.StatusCode).Msg("Failed to fetch page")
+		stats.Failed++
+		return false, nil
+	}
+
+	// リダイレクトされたページはリダイレクト先のURLで取り込む
+	if resp.URL != item.url {
+		changed, err := c.removePage(ctx, item.url, docs)
+		if err == nil {
+			c.enqueue(resp.URL, item.depth)
+		}
+		return changed, err
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.ContentType); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		stats.Skipped++
+		return false, nil
+	}
+
+	base, _ := url.Parse(item.url)
+	page, err := webcrawl.Extract(resp.Body, base)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to extract page")
+		stats.Failed++
+		return false, nil
+	}
+	if !page.NoFollow {
+		c.enqueueLinks(item, page.Links)
+	}
+	if page.NoIndex || page.Text == "" {
+		return c.removePage(ctx, item.url, docs)
+	}
+
+	if err := c.s3Client.PutObject(ctx, c.snapshotKey(item.url), resp.Body); err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		logger.Warn().Err(err).Msg("Failed to save page snapshot")
+		stats.Failed++
+		return false, nil
+	}
+
+	modifiedAt := resp.LastModified
+	if modifiedAt.IsZero() {
+		modifiedAt = time.Now()
+	}
+	obj := syncObject{key: item.url, version: resp.ETag, modifiedAt: modifiedAt.Truncate(time.Microsecond)}
+	return ingestSourceContent(ctx, c.dbHandler, c.ingestService, latest, pageFilename(page.Title, base), item.url, obj, []byte(page.Text), stats)
+}
+
+// removePage は取り込めなくなったページ (削除・robots.txt での禁止・noindex) のドキュメントを削除する
+func (c *crawler) removePage(ctx context.Context, pageURL string, docs []domain.Document) (bool, error) {
+	if len(docs) == 0 {
+		c.state.Stats.Skipped++
+		return false, nil
+	}
+	if err := deleteSourceDocuments(ctx, c.dbHandler, pageURL, docs); err != nil {
+		return false, err
+	}
+	delete(c.known, pageURL)
+	c.state.Stats.Deleted++
+	return true, nil
+}
+
+// enqueueLinks はページのリンクを1つ深いページとして加える
+func (c *crawler) enqueueLinks(item crawlItem, links []string) {
+	for _, link := range links {
+		c.enqueue(link, item.depth+1)
+	}
+}
+
+// snapshotLinks は変更されていないページのリンクを、保存したスナップショットから取得する
+// 深さの上限のページはリンクをたどらないため読み込まない
+func (c *crawler) snapshotLinks(ctx context.Context, item crawlItem) []string {
+	if item.depth >= c.cfg.MaxDepth {
+		return nil
+	}
+	body, err := c.s3Client.DownloadFileContent(ctx, c.snapshotKey(item.url))
+	if err != nil {
+		log.Debug().Err(err).Str("source_uri", item.url).Msg("Failed to read page snapshot")
+		return nil
+	}
+	base, _ := url.Parse(item.url)
+	page, err := webcrawl.Extract(body, base)
+	if err != nil || page.NoFollow {
+		return nil
+	}
+	return page.Links
+}
+
+// snapshotKey はページのスナップショットのS3のキーを返す (web/ホスト/URLのハッシュ.html)
+func (c *crawler) snapshotKey(pageURL string) string {
+	host := ""
+	if u, err := url.Parse(pageURL); err == nil {
+		host = u.Host
+	}
+	sum := sha256.Sum256([]byte(pageURL))
+	return c.s3Client.ObjectKey("web", host, hex.EncodeToString(sum[:])+".html")
+}
+
+// pageFilename はページのタイトル (なければURLのパス) から取り込むファイル名を作る (本文はテキストとして取り込む)
+func pageFilename(title string, u *url.URL) string {
+	name := title
+	if name == "" {
+		name = path.Base(u.Path)
+		if name == "/" || name == "." {
+			name = u.Hostname()
+		}
+	}
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > maxPageFilenameLength {
+		name = string(runes[:maxPageFilenameLength])
+	}
+	return name + ".txt"
+}
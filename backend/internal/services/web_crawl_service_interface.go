@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+
+	"bedrock-rag-sample/backend/internal/domain"
+)
+
+// WebCrawlServiceInterface はWebページのクロールサービスのインターフェース
+type WebCrawlServiceInterface interface {
+	Status(ctx context.Context) (*domain.SyncState, error)
+	Trigger(ctx context.Context) (*domain.SyncState, error)
+	Run(ctx context.Context) (*domain.SyncState, error)
+}
+
+// インターフェースを実装していることを静的にチェック
+var _ WebCrawlServiceInterface = (*WebCrawlService)(nil)
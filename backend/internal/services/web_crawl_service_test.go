@@ -0,0 +1,262 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+	"bedrock-rag-sample/backend/internal/domain"
+	domainmocks "bedrock-rag-sample/backend/internal/domain/mocks"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+	awsmock "bedrock-rag-sample/backend/pkg/aws/mock"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCrawlTestConfig(seeds ...string) *config.Config {
+	return &config.Config{
+		Crawl: config.CrawlConfig{Seeds: seeds, MaxDepth: 1, MaxPages: 100, Timeout: 5 * time.Second, UserAgent: "rag-crawler/1.0"},
+	}
+}
+
+// crawlTestServer はクロールのテスト用のWebサーバー (リクエストされたパスを記録する)
+type crawlTestServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	requested []string
+}
+
+func newCrawlTestServer(t *testing.T, pages map[string]http.HandlerFunc) *crawlTestServer {
+	s := &crawlTestServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requested = append(s.requested, r.URL.Path)
+		s.mu.Unlock()
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		}
+		handler, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// htmlPage は本文を返すハンドラー
+func htmlPage(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(body))
+	}
+}
+
+func TestNewWebCrawlService(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *config.CrawlConfig)
+	}{
+		{name: "異常系: シードもサイトマップもない", modify: func(cfg *config.CrawlConfig) { cfg.Seeds = nil }},
+		{name: "異常系: http のURLではない", modify: func(cfg *config.CrawlConfig) { cfg.Seeds = []string{"file:///etc/passwd"} }},
+		{name: "異常系: ページ数の上限が0", modify: func(cfg *config.CrawlConfig) { cfg.MaxPages = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newCrawlTestConfig("https://wiki.example.com/")
+			tt.modify(&cfg.Crawl)
+
+			_, err := services.NewWebCrawlService(nil, nil, nil, syncRunner{}, cfg)
+
+			assert.ErrorIs(t, err, services.ErrInvalidCrawlConfig)
+		})
+	}
+
+	t.Run("正常系: ドメインの指定がなければシードとサイトマップのホストをクロールする", func(t *testing.T) {
+		cfg := newCrawlTestConfig("https://Wiki.example.com/", "https://docs.example.com/a")
+		cfg.Crawl.Sitemaps = []string{"https://wiki.example.com/sitemap.xml"}
+
+		s, err := services.NewWebCrawlService(nil, nil, nil, syncRunner{}, cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "web:docs.example.com,wiki.example.com", s.Source())
+	})
+}
+
+func TestWebCrawlService_Run(t *testing.T) {
+	ctx := context.Background()
+	lastModified := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	type crawlMocks struct {
+		s3     *awsmock.MockS3ClientInterface
+		db     *domainmocks.MockDBHandlerInterface
+		ingest *servicemocks.MockIngestServiceInterface
+	}
+	newMocks := func(ctrl *gomock.Controller) crawlMocks {
+		m := crawlMocks{
+			s3:     awsmock.NewMockS3ClientInterface(ctrl),
+			db:     domainmocks.NewMockDBHandlerInterface(ctrl),
+			ingest: servicemocks.NewMockIngestServiceInterface(ctrl),
+		}
+		m.s3.EXPECT().ObjectKey(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(elem ...string) string { return path.Join(elem...) }).AnyTimes()
+		return m
+	}
+
+	t.Run("正常系: シードとサイトマップから深さの上限までクロールし、robots.txt と noindex に従う", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		m := newMocks(ctrl)
+
+		var server *crawlTestServer
+		server = newCrawlTestServer(t, map[string]http.HandlerFunc{
+			"/": htmlPage(`<html><head><title>トップ</title></head><body><nav><a href="/nav">ナビ</a></nav>
+<main><p>社内Wiki</p><a href="a">規程</a> <a href="/private/x">非公開</a> <a href="/n">下書き</a> <a href="https://external.example.com/">外部</a></main></body></html>`),
+			"/a": func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"a1"`)
+				w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+				htmlPage(`<title>休暇規程</title><h1>休暇規程</h1><p>年次有給休暇</p><a href="/deep">深いページ</a>`)(w, r)
+			},
+			"/n":   htmlPage(`<html><head><meta name="robots" content="noindex"></head><body>下書き</body></html>`),
+			"/s":   htmlPage(`<title>FAQ</title><p>よくある質問</p>`),
+			"/nav": htmlPage(`<p>ナビ</p>`),
+			"/sitemap.xml": func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>` + server.URL + `/s</loc></url></urlset>`))
+			},
+		})
+		cfg := newCrawlTestConfig(server.URL)
+		cfg.Crawl.Sitemaps = []string{server.URL + "/sitemap.xml"}
+		s, err := services.NewWebCrawlService(m.s3, m.db, m.ingest, syncRunner{}, cfg)
+		require.NoError(t, err)
+		source := s.Source()
+
+		ingested := make(map[string]services.IngestRequest)
+		expectedStats := domain.SyncStats{Created: 4, Skipped: 2}
+		m.db.EXPECT().ClaimSyncState(gomock.Any(), source, gomock.Any(), gomock.Any()).Return(&domain.SyncState{Source: source}, true, nil)
+		m.db.EXPECT().ListDocumentsBySource(gomock.Any(), domain.SourceRange{Prefix: "http://"}).Return(nil, nil)
+		m.db.EXPECT().ListDocumentsBySource(gomock.Any(), domain.SourceRange{Prefix: "https://"}).Return(nil, nil)
+		m.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(4)
+		m.ingest.EXPECT().Ingest(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req services.IngestRequest) (*services.IngestResult, error) {
+				ingested[req.SourceURI] = req
+				return &services.IngestResult{Document: &domain.Document{ID: int64(len(ingested)), Version: 1}}, nil
+			}).Times(4)
+		m.db.EXPECT().SaveSyncCheckpoint(gomock.Any(), source, gomock.Any(), gomock.Any(), "", gomock.Any()).Return(nil).Times(4)
+		m.db.EXPECT().CompleteSyncPass(gomock.Any(), source, gomock.Any(), "", expectedStats).Return(nil)
+		m.db.EXPECT().GetSyncState(gomock.Any(), source).Return(&domain.SyncState{Source: source, Stats: expectedStats}, nil)
+
+		state, err := s.Run(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, expectedStats, state.Stats)
+		assert.ElementsMatch(t, []string{server.URL + "/", server.URL + "/s", server.URL + "/a", server.URL + "/nav"}, keys(ingested))
+
+		// URLを引用の位置として、本文を取り込む
+		req := ingested[server.URL+"/a"]
+		assert.Equal(t, "休暇規程.txt", req.Filename)
+		assert.Equal(t, "# 休暇規程\n\n年次有給休暇\n\n深いページ", string(req.Content))
+		assert.Equal(t, `"a1"`, req.SourceVersion)
+		assert.True(t, lastModified.Equal(*req.SourceModifiedAt))
+		assert.Equal(t, services.DuplicatePolicyVersion, req.DuplicatePolicy)
+		assert.Equal(t, "社内Wiki\n\n規程 非公開 下書き 外部", string(ingested[server.URL+"/"].Content))
+
+		assert.NotContains(t, server.requested, "/deep", "深さの上限を超えるページは取得しない")
+		assert.NotContains(t, server.requested, "/private/x", "robots.txt で禁止されたページは取得しない")
+	})
+
+	t.Run("正常系: 再クロールでは条件付きリクエストで変更を検出し、見つからなくなったページのドキュメントを削除する", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		m := newMocks(ctrl)
+
+		server := newCrawlTestServer(t, map[string]http.HandlerFunc{
+			"/": func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, `"top1"`, r.Header.Get("If-None-Match"))
+				assert.Equal(t, lastModified.Format(http.TimeFormat), r.Header.Get("If-Modified-Since"))
+				w.WriteHeader(http.StatusNotModified)
+			},
+			// ETag は変わったが本文は同じ
+			"/a": func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"a2"`)
+				w.Header().Set("Last-Modified", lastModified.Add(time.Hour).Format(http.TimeFormat))
+				htmlPage(`<main><p>年次有給休暇</p></main>`)(w, r)
+			},
+		})
+		s, err := services.NewWebCrawlService(m.s3, m.db, m.ingest, syncRunner{}, newCrawlTestConfig(server.URL+"/"))
+		require.NoError(t, err)
+		source := s.Source()
+
+		expectedStats := domain.SyncStats{Unchanged: 2, Deleted: 1}
+		m.db.EXPECT().ClaimSyncState(gomock.Any(), source, gomock.Any(), gomock.Any()).Return(&domain.SyncState{Source: source}, true, nil)
+		m.db.EXPECT().ListDocumentsBySource(gomock.Any(), domain.SourceRange{Prefix: "http://"}).Return([]domain.Document{
+			{ID: 1, SourceURI: server.URL + "/", SourceVersion: `"top1"`, SourceModifiedAt: &lastModified},
+			{ID: 2, SourceURI: server.URL + "/a", SourceVersion: `"a1"`, SourceModifiedAt: &lastModified, ContentHash: textHash("年次有給休暇")},
+			{ID: 9, SourceURI: server.URL + "/gone"},
+			{ID: 10, SourceURI: "http://other.example.com/"},
+		}, nil)
+		m.db.EXPECT().ListDocumentsBySource(gomock.Any(), domain.SourceRange{Prefix: "https://"}).Return(nil, nil)
+		gomock.InOrder(
+			// 変更されていないページのリンクはスナップショットから取得する
+			m.s3.EXPECT().DownloadFileContent(gomock.Any(), gomock.Any()).Return([]byte(`<a href="/a">規程</a>`), nil),
+			m.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+			m.db.EXPECT().UpdateDocumentSource(gomock.Any(), int64(2), `"a2"`, lastModified.Add(time.Hour)).Return(nil),
+			m.db.EXPECT().SaveSyncCheckpoint(gomock.Any(), source, gomock.Any(), gomock.Any(), "", gomock.Any()).Return(nil),
+			m.db.EXPECT().DeleteDocument(gomock.Any(), int64(9)).Return(nil),
+			m.db.EXPECT().SaveSyncCheckpoint(gomock.Any(), source, gomock.Any(), gomock.Any(), "", gomock.Any()).Return(nil),
+			m.db.EXPECT().CompleteSyncPass(gomock.Any(), source, gomock.Any(), "", expectedStats).Return(nil),
+		)
+		m.db.EXPECT().GetSyncState(gomock.Any(), source).Return(&domain.SyncState{Source: source, Stats: expectedStats}, nil)
+
+		state, err := s.Run(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, expectedStats, state.Stats)
+	})
+
+	t.Run("正常系: ページ数の上限に達したらクロールを終える", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		m := newMocks(ctrl)
+
+		server := newCrawlTestServer(t, map[string]http.HandlerFunc{
+			"/": htmlPage(`<p>トップ</p><a href="/a">a</a>`),
+		})
+		cfg := newCrawlTestConfig(server.URL + "/")
+		cfg.Crawl.MaxPages = 1
+		s, err := services.NewWebCrawlService(m.s3, m.db, m.ingest, syncRunner{}, cfg)
+		require.NoError(t, err)
+		source := s.Source()
+
+		m.db.EXPECT().ClaimSyncState(gomock.Any(), source, gomock.Any(), gomock.Any()).Return(&domain.SyncState{Source: source}, true, nil)
+		m.db.EXPECT().ListDocumentsBySource(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+		m.s3.EXPECT().PutObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		m.ingest.EXPECT().Ingest(gomock.Any(), gomock.Any()).Return(&services.IngestResult{Document: &domain.Document{ID: 1, Version: 1}}, nil)
+		m.db.EXPECT().SaveSyncCheckpoint(gomock.Any(), source, gomock.Any(), gomock.Any(), "", gomock.Any()).Return(nil)
+		m.db.EXPECT().CompleteSyncPass(gomock.Any(), source, gomock.Any(), "", domain.SyncStats{Created: 1}).Return(nil)
+		m.db.EXPECT().GetSyncState(gomock.Any(), source).Return(&domain.SyncState{Source: source}, nil)
+
+		_, err = s.Run(ctx)
+
+		require.NoError(t, err)
+		assert.NotContains(t, server.requested, "/a")
+	})
+}
+
+func keys[V any](m map[string]V) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrShuttingDown はシャットダウン開始後にタスクを登録しようとした場合のエラー
+var ErrShuttingDown = errors.New("シャットダウン中のため新しいバックグラウンドタスクを受け付けられません")
+
+// Group はバックグラウンドタスク (インジェスト処理など) を追跡し、シャットダウン時に完了を待つ
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	closing bool
+}
+
+// NewGroup は新しいGroupを作成する
+func NewGroup() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Go はバックグラウンドタスクを開始する
+// タスクに渡されるコンテキストは、シャットダウンの猶予期限を過ぎた時点でキャンセルされる
+func (g *Group) Go(name string, fn func(ctx context.Context)) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closing {
+		return ErrShuttingDown
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Str("task", name).Interface("panic", r).Msg("Background task panicked")
+			}
+		}()
+		fn(g.ctx)
+	}()
+	return nil
+}
+
+// Shutdown は新しいタスクの受付を停止し、実行中のタスクの完了を待つ
+// ctx の期限までに完了しなかった場合はタスクのコンテキストをキャンセルし、ctx のエラーを返す
+func (g *Group) Shutdown(ctx context.Context) error {
+	g.mu.Lock()
+	g.closing = true
+	g.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		g.cancel()
+		return nil
+	case <-ctx.Done():
+		// コンテキストを無視するタスクでプロセス終了がブロックされないよう、完了は待たない
+		g.cancel()
+		return ctx.Err()
+	}
+}
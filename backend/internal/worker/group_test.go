@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup_Shutdown(t *testing.T) {
+	t.Run("正常系_実行中のタスクの完了を待つ", func(t *testing.T) {
+		g := NewGroup()
+		finished := make(chan struct{})
+
+		require.NoError(t, g.Go("ingest", func(ctx context.Context) {
+			time.Sleep(20 * time.Millisecond)
+			close(finished)
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, g.Shutdown(ctx))
+
+		select {
+		case <-finished:
+		default:
+			t.Fatal("Shutdown returned before the task finished")
+		}
+	})
+
+	t.Run("異常系_期限超過でタスクをキャンセルする", func(t *testing.T) {
+		g := NewGroup()
+		cancelled := make(chan struct{})
+
+		require.NoError(t, g.Go("slow", func(ctx context.Context) {
+			<-ctx.Done()
+			close(cancelled)
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := g.Shutdown(ctx)
+
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("task context was not cancelled")
+		}
+	})
+
+	t.Run("異常系_シャットダウン後のタスク登録", func(t *testing.T) {
+		g := NewGroup()
+		require.NoError(t, g.Shutdown(context.Background()))
+
+		err := g.Go("late", func(ctx context.Context) {})
+
+		assert.ErrorIs(t, err, ErrShuttingDown)
+	})
+
+	t.Run("パニックしたタスクがシャットダウンを妨げない", func(t *testing.T) {
+		g := NewGroup()
+		require.NoError(t, g.Go("panic", func(ctx context.Context) {
+			panic("boom")
+		}))
+
+		assert.NoError(t, g.Shutdown(context.Background()))
+	})
+}
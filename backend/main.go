@@ -15,22 +15,28 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"bedrock-rag-sample/backend/config"
-	_ "bedrock-rag-sample/backend/docs"                   // docs パッケージをインポート (init()を実行するため)
-	domain "bedrock-rag-sample/backend/internal/domain"   // エイリアス domain を指定
-	"bedrock-rag-sample/backend/internal/handler"         // 修正
-	dto "bedrock-rag-sample/backend/internal/handler/dto" // エイリアス dto を指定
-	"bedrock-rag-sample/backend/internal/route"           // 修正
+	_ "bedrock-rag-sample/backend/docs"                            // docs パッケージをインポート (init()を実行するため)
+	domain "bedrock-rag-sample/backend/internal/domain"            // エイリアス domain を指定
+	"bedrock-rag-sample/backend/internal/handler"                  // 修正
+	dto "bedrock-rag-sample/backend/internal/handler/dto"          // エイリアス dto を指定
+	appmiddleware "bedrock-rag-sample/backend/internal/middleware" // echo の middleware と区別するためエイリアスを指定
+	"bedrock-rag-sample/backend/internal/route"                    // 修正
 
 	// 修正 (エイリアス domain)
 	"bedrock-rag-sample/backend/internal/services"
+	"bedrock-rag-sample/backend/internal/worker"
 	"bedrock-rag-sample/backend/pkg/aws"
+	"bedrock-rag-sample/backend/pkg/redact"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -70,9 +76,16 @@ func customHTTPErrorHandler(err error, c echo.Context) {
 		errorCode  = "INTERNAL_SERVER_ERROR"
 		message    = "内部サーバーエラーが発生しました"
 		details    = ""
+
+		existingDocumentID *int64
+		guardrailFindings  []dto.GuardrailFinding
+		piiTypes           []string
 	)
 
-	var httpError *echo.HTTPError
+	var (
+		httpError  *echo.HTTPError
+		blockedErr *aws.ContentBlockedError
+	)
 	if errors.As(err, &httpError) {
 		statusCode = httpError.Code
 		// httpError.Messageがstringの場合のみメッセージとして使用
@@ -95,8 +108,43 @@ func customHTTPErrorHandler(err error, c echo.Context) {
 			errorCode = "FORBIDDEN"
 		case http.StatusNotFound:
 			errorCode = "NOT_FOUND"
+		case http.StatusConflict:
+			errorCode = "CONFLICT"
+		case http.StatusUnprocessableEntity:
+			errorCode = "UNPROCESSABLE_ENTITY"
+		case http.StatusTooManyRequests:
+			errorCode = "TOO_MANY_REQUESTS"
+		case http.StatusServiceUnavailable:
+			errorCode = "SERVICE_UNAVAILABLE"
 			// 他のステータスコードに対応するエラーコードを追加可能
 		}
+		// 予算・クォータの上限はステータスコードだけでは流量制御と区別できないため専用のコードを返す
+		if errors.Is(err, services.ErrBudgetExceeded) {
+			errorCode = dto.ErrorCodeBudgetExceeded
+		}
+		// スキーマに適合する出力が得られなかった抽出は、入力の誤りによる422と区別できるよう専用のコードを返す
+		if errors.Is(err, services.ErrExtractionInvalid) {
+			errorCode = dto.ErrorCodeExtractionInvalid
+		}
+		// 個人情報を含むため拒否した入力は、どの種類を取り除けばよいか分かるよう検出した種類を返す
+		if errors.Is(err, services.ErrPIIDetected) {
+			errorCode = dto.ErrorCodePIIDetected
+			var rejectedErr *redact.RejectedError
+			if errors.As(err, &rejectedErr) {
+				piiTypes = rejectedErr.Types
+			}
+		}
+		// 重複したドキュメントは既存のドキュメントを参照できるようIDを返す
+		var duplicateErr *services.DuplicateDocumentError
+		if errors.As(err, &duplicateErr) {
+			errorCode = dto.ErrorCodeDuplicateDocument
+			existingDocumentID = &duplicateErr.Existing.DocumentID
+		}
+		// ガードレールによる遮断はモデル呼び出しの障害と区別し、遮断した理由を返す
+		if errors.As(err, &blockedErr) {
+			errorCode = dto.ErrorCodeContentBlocked
+			guardrailFindings = toGuardrailFindings(blockedErr.Outcome.Findings)
+		}
 
 		if httpError.Internal != nil {
 			details = httpError.Internal.Error()
@@ -106,6 +154,13 @@ func customHTTPErrorHandler(err error, c echo.Context) {
 			log.Warn().Int("status", statusCode).Str("code", errorCode).Msg(message)
 		}
 
+	} else if errors.As(err, &blockedErr) {
+		// サービス層のエラーをそのまま返したハンドラーでも、ガードレールによる遮断は422とする
+		statusCode = http.StatusUnprocessableEntity
+		errorCode = dto.ErrorCodeContentBlocked
+		message = blockedErr.Error()
+		guardrailFindings = toGuardrailFindings(blockedErr.Outcome.Findings)
+		log.Warn().Int("status", statusCode).Str("code", errorCode).Msg(message)
 	} else {
 		// echo.HTTPError以外のエラーは内部サーバーエラーとして扱う
 		// 元のエラーメッセージはログに出力し、レスポンスには含めない
@@ -116,8 +171,11 @@ func customHTTPErrorHandler(err error, c echo.Context) {
 
 	errorResponse := dto.ErrorResponse{ // apiModels -> dto に修正
 		Error: dto.ErrorDetail{ // apiModels -> dto に修正
-			Code:    errorCode,
-			Message: message,
+			Code:               errorCode,
+			Message:            message,
+			ExistingDocumentID: existingDocumentID,
+			GuardrailFindings:  guardrailFindings,
+			PIITypes:           piiTypes,
 			// details は本番では基本返さない方針。
 			// 開発用に返す場合は環境変数などで制御する。
 			// Details: details,
@@ -133,6 +191,15 @@ func customHTTPErrorHandler(err error, c echo.Context) {
 	}
 }
 
+// toGuardrailFindings はガードレールが遮断した理由をレスポンスの形式に変換する
+func toGuardrailFindings(findings []aws.GuardrailFinding) []dto.GuardrailFinding {
+	var out []dto.GuardrailFinding
+	for _, f := range findings {
+		out = append(out, dto.GuardrailFinding{Source: f.Source, Policy: f.Policy, Type: f.Type, Action: f.Action})
+	}
+	return out
+}
+
 // zerologLoggerMiddleware は zerolog を使用したリクエストロギングミドルウェア
 func zerologLoggerMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -201,6 +268,15 @@ func main() {
 	}
 	log.Info().Msg("Bedrock client initialized")
 
+	// テキスト生成モデルの選択 (許可リストとエンドポイントごとの既定モデル)
+	textModels, err := services.NewTextModelRouter(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("テキスト生成モデルの設定が不正です")
+	}
+	log.Info().
+		Interface("defaults", textModels.Defaults()).
+		Msg("Text model router initialized")
+
 	// Textractクライアントを初期化
 	textractClient, err := aws.NewTextractClient(cfg, s3Client)
 	if err != nil {
@@ -215,37 +291,218 @@ func main() {
 		dbHandler = nil // エラーの場合は nil を設定
 	} else {
 		log.Info().Msg("DB handler initialized")
-		// プログラム終了時にDBコネクションを閉じる
-		defer func() {
-			if dbHandler != nil {
-				if err := dbHandler.Close(); err != nil {
-					log.Error().Err(err).Msg("Failed to close database connection")
-				}
-				log.Info().Msg("Database connection closed")
+		// DBコネクションはグレースフルシャットダウンの最後に閉じる
+
+		if cfg.DB.AutoMigrate {
+			if err := dbHandler.Migrate(context.Background()); err != nil {
+				log.Fatal().Err(err).Msg("データベースのマイグレーションに失敗しました")
 			}
-		}()
+			log.Info().Msg("Database migrations applied")
+		}
+	}
+
+	// バックグラウンドタスク (インジェスト処理など) の管理
+	workers := worker.NewGroup()
+
+	// Bedrockに送るテキストの個人情報の除去 (REDACT_ENABLED を指定した場合のみ。DBに接続できない場合は監査の記録を保存しない)
+	var redactStore domain.DBHandlerInterface
+	if dbHandler != nil {
+		redactStore = dbHandler
+	}
+	redactor, err := services.NewPIIRedactor(cfg.Redact, redactStore)
+	if err != nil {
+		log.Fatal().Err(err).Msg("個人情報の除去の設定が不正です")
+	}
+	if redactor != nil {
+		log.Info().
+			Str("default_mode", cfg.Redact.DefaultMode).
+			Strs("detectors", cfg.Redact.Detectors).
+			Bool("dictionary", cfg.Redact.DictionaryFile != "").
+			Bool("audit", redactStore != nil).
+			Msg("PII redaction enabled")
 	}
 
 	// サービスを初期化
 	uploadService := services.NewUploadService(s3Client)
-	summarizeService := services.NewSummarizeService(bedrockClient, uploadService)
+	summarizeService := services.NewSummarizeService(bedrockClient, uploadService, textModels, redactor)
 	log.Info().Msg("Upload and Summarize services initialized")
 
 	// ドキュメント処理サービスを初期化
 	documentService := services.NewDocumentService(textractClient, summarizeService)
+	extractService := services.NewExtractService(bedrockClient, documentService, textModels, redactor)
 	log.Info().Msg("Document service initialized")
 
+	// ドキュメントの分類 (タクソノミーはタグの編集でも使用する。分類器は CLASSIFY_MODE を指定した場合のみ)
+	taxonomy, err := services.LoadTaxonomy(cfg.Classify)
+	if err != nil {
+		log.Fatal().Err(err).Msg("タクソノミーの設定が不正です")
+	}
+	classifier, err := services.NewDocumentClassifier(cfg.Classify, taxonomy, bedrockClient, extractService)
+	if err != nil {
+		log.Fatal().Err(err).Msg("分類の設定が不正です")
+	}
+	if classifier != nil {
+		log.Info().
+			Str("mode", cfg.Classify.Mode).
+			Int("categories", len(taxonomy.Categories)).
+			Msg("Document classifier initialized")
+	}
+
+	// 同じテキストのEmbeddingを再利用するキャッシュ (DBに接続できない場合はメモリ上のみ)
+	var embeddingCache *services.EmbeddingCache
+	if cfg.EmbedCache.Enabled {
+		var cacheStore domain.DBHandlerInterface
+		if dbHandler != nil {
+			cacheStore = dbHandler
+		}
+		embeddingCache = services.NewEmbeddingCache(cacheStore, cfg.EmbedCache.MemoryEntries)
+		bedrockClient.SetEmbeddingCache(embeddingCache)
+		log.Info().
+			Int("memory_entries", cfg.EmbedCache.MemoryEntries).
+			Bool("persistent", cacheStore != nil).
+			Msg("Embedding cache enabled")
+	}
+
+	// トークン使用量と料金の記録 (DBに接続できない場合は記録しない)
+	var usageService *services.UsageService
+	var budgetService *services.BudgetService
+	if dbHandler != nil && cfg.Usage.Enabled {
+		prices := services.NewPriceTable(cfg.Usage.Prices)
+		usageService = services.NewUsageService(dbHandler, prices)
+		bedrockClient.SetUsageRecorder(usageService)
+		log.Info().Msg("Usage tracking enabled")
+
+		// テナントごとの予算・クォータ (使用量の記録を前提とする)
+		if cfg.Budget.Enabled {
+			budgetService = services.NewBudgetService(dbHandler, cfg.Budget, prices, usageService)
+			bedrockClient.SetUsageRecorder(budgetService)
+			bedrockClient.SetUsageGuard(budgetService)
+			log.Info().
+				Int64("monthly_tokens", cfg.Budget.Default.MonthlyTokens).
+				Float64("monthly_cost_usd", cfg.Budget.Default.MonthlyCostUSD).
+				Int("daily_requests", cfg.Budget.Default.DailyRequests).
+				Int("tenant_overrides", len(cfg.Budget.Tenants)).
+				Msg("Tenant budgets enabled")
+		}
+	} else if cfg.Budget.Enabled {
+		log.Warn().Msg("Tenant budgets require usage tracking and a database connection; budgets are disabled")
+	}
+
 	// レコメンドサービスを初期化
 	var recommendService *services.RecommendService
+	var reindexService *services.ReindexService
 	if dbHandler != nil {
-		recommendService = services.NewRecommendService(bedrockClient, dbHandler)
+		recommendService = services.NewRecommendService(bedrockClient, dbHandler, redactor)
 		log.Info().Msg("Recommend service initialized")
+
+		// 前回の停止で中断された再インデックスを再開する
+		reindexService = services.NewReindexService(bedrockClient, dbHandler, workers)
+		if err := reindexService.ResumeInterrupted(context.Background()); err != nil {
+			log.Error().Err(err).Msg("Failed to resume interrupted reindex job")
+		}
 	} else {
 		log.Warn().Msg("Recommend service skipped due to DB connection failure")
 	}
 
+	// ドキュメント取り込みサービス (DBに保存するため、DBに接続できない場合は使用しない)
+	var ingestService *services.IngestService
+	if recommendService != nil {
+		ingestService = services.NewIngestService(s3Client, textractClient, dbHandler, recommendService, classifier, redactor, cfg.Ingest)
+		log.Info().
+			Str("duplicate_policy", cfg.Ingest.DuplicatePolicy).
+			Int("near_duplicate_max_distance", cfg.Ingest.NearDuplicateMaxDistance).
+			Msg("Ingest service initialized")
+	}
+
+	// S3のプレフィックスに直接置かれたドキュメントの同期 (取り込みサービスを使うため、DBに接続できない場合は使用しない)
+	var syncService *services.S3SyncService
+	if cfg.Sync.Enabled {
+		if ingestService != nil {
+			syncService, err = services.NewS3SyncService(s3Client, dbHandler, ingestService, workers, cfg)
+			if err != nil {
+				log.Fatal().Err(err).Msg("同期の設定が不正です")
+			}
+			log.Info().
+				Str("source", syncService.Source()).
+				Dur("interval", cfg.Sync.Interval).
+				Msg("S3 sync service initialized")
+		} else {
+			log.Warn().Msg("S3 sync requires a database connection; sync is disabled")
+		}
+	}
+
+	// ローカルのディレクトリの監視 (WATCH_DIR を指定した場合のみ。取り込みサービスを使うため、DBに接続できない場合は使用しない)
+	var dirSyncService *services.DirectorySyncService
+	if cfg.Watch.Dir != "" {
+		if ingestService != nil {
+			dirSyncService, err = services.NewDirectorySyncService(dbHandler, ingestService, workers, cfg)
+			if err != nil {
+				log.Fatal().Err(err).Msg("ディレクトリの監視の設定が不正です")
+			}
+			log.Info().
+				Str("source", dirSyncService.Source()).
+				Strs("include", cfg.Watch.Include).
+				Strs("exclude", cfg.Watch.Exclude).
+				Msg("Directory sync service initialized")
+		} else {
+			log.Warn().Msg("Directory watching requires a database connection; watching is disabled")
+		}
+	}
+
+	// Webページのクロール (CRAWL_SEEDS または CRAWL_SITEMAPS を指定した場合のみ。取り込みサービスを使うため、DBに接続できない場合は使用しない)
+	var crawlService *services.WebCrawlService
+	if len(cfg.Crawl.Seeds) > 0 || len(cfg.Crawl.Sitemaps) > 0 {
+		if ingestService != nil {
+			crawlService, err = services.NewWebCrawlService(s3Client, dbHandler, ingestService, workers, cfg)
+			if err != nil {
+				log.Fatal().Err(err).Msg("クロールの設定が不正です")
+			}
+			log.Info().
+				Str("source", crawlService.Source()).
+				Int("max_depth", cfg.Crawl.MaxDepth).
+				Int("max_pages", cfg.Crawl.MaxPages).
+				Dur("interval", cfg.Crawl.Interval).
+				Msg("Web crawl service initialized")
+		} else {
+			log.Warn().Msg("Web crawling requires a database connection; crawling is disabled")
+		}
+	}
+
+	// gitリポジトリの同期 (GIT_REPO を指定した場合のみ。取り込みサービスを使うため、DBに接続できない場合は使用しない)
+	var gitSyncService *services.GitSyncService
+	if cfg.Git.Repo != "" {
+		if ingestService != nil {
+			gitSyncService, err = services.NewGitSyncService(dbHandler, ingestService, workers, cfg)
+			if err != nil {
+				log.Fatal().Err(err).Msg("gitリポジトリの同期の設定が不正です")
+			}
+			log.Info().
+				Str("source", gitSyncService.Source()).
+				Strs("include", cfg.Git.Include).
+				Strs("exclude", cfg.Git.Exclude).
+				Dur("interval", cfg.Git.Interval).
+				Msg("Git sync service initialized")
+		} else {
+			log.Warn().Msg("Git sync requires a database connection; git sync is disabled")
+		}
+	}
+
+	// 回答キャッシュはDBに保存するため、DBに接続できない場合は使用しない
+	var answerCache *services.AnswerCache
+	if cfg.QACache.Enabled {
+		if dbHandler != nil {
+			answerCache = services.NewAnswerCache(dbHandler, cfg.QACache)
+			log.Info().
+				Float64("similarity_threshold", cfg.QACache.SimilarityThreshold).
+				Dur("ttl", cfg.QACache.TTL).
+				Msg("QA answer cache enabled")
+		} else {
+			log.Warn().Msg("QA answer cache requires a database connection; cache is disabled")
+		}
+	}
+
 	// QAサービスの初期化
-	qaService, err := services.NewQAService(bedrockClient, cfg)
+	qaService, err := services.NewQAService(bedrockClient, cfg, textModels, answerCache, redactor)
 	if err != nil {
 		log.Warn().Err(err).Msg("QAサービスの初期化に失敗しました。Knowledge Base機能は利用できません。BEDROCK_KB_IDを確認してください")
 	} else {
@@ -256,6 +513,8 @@ func main() {
 	uploadHandler := handler.NewUploadHandler(uploadService)
 	summarizeHandler := handler.NewSummarizeHandler(summarizeService)
 	documentHandler := handler.NewDocumentHandler(documentService)
+	extractHandler := handler.NewExtractHandler(extractService)
+	modelHandler := handler.NewModelHandler(textModels)
 	log.Info().Msg("Upload, Summarize, Document handlers initialized")
 
 	// レコメンドハンドラーの初期化
@@ -265,6 +524,54 @@ func main() {
 		log.Info().Msg("Recommend handler initialized")
 	}
 
+	// ドキュメント取り込みハンドラーの初期化
+	var ingestHandler *handler.IngestHandler
+	if ingestService != nil {
+		ingestHandler = handler.NewIngestHandler(ingestService)
+		log.Info().Msg("Ingest handler initialized")
+	}
+
+	// ドキュメントの版のハンドラーの初期化
+	var documentVersionHandler *handler.DocumentVersionHandler
+	if dbHandler != nil {
+		documentVersionHandler = handler.NewDocumentVersionHandler(services.NewDocumentVersionService(dbHandler))
+		log.Info().Msg("Document version handler initialized")
+	}
+
+	// ドキュメントの分類の編集ハンドラーの初期化
+	var documentTagHandler *handler.DocumentTagHandler
+	if dbHandler != nil {
+		documentTagHandler = handler.NewDocumentTagHandler(services.NewDocumentTagService(dbHandler, taxonomy))
+		log.Info().Msg("Document tag handler initialized")
+	}
+
+	// 同期ハンドラーの初期化
+	var syncHandler *handler.SyncHandler
+	if syncService != nil {
+		syncHandler = handler.NewSyncHandler(syncService)
+		log.Info().Msg("Sync handler initialized")
+	}
+
+	// 再インデックスハンドラーの初期化
+	var reindexHandler *handler.ReindexHandler
+	if reindexService != nil {
+		reindexHandler = handler.NewReindexHandler(reindexService)
+		log.Info().Msg("Reindex handler initialized")
+	}
+
+	// 使用量レポートハンドラーの初期化
+	var usageHandler *handler.UsageHandler
+	if usageService != nil {
+		usageHandler = handler.NewUsageHandler(usageService)
+		log.Info().Msg("Usage handler initialized")
+	}
+
+	// Embeddingキャッシュのハンドラーの初期化
+	var embeddingCacheHandler *handler.EmbeddingCacheHandler
+	if embeddingCache != nil {
+		embeddingCacheHandler = handler.NewEmbeddingCacheHandler(embeddingCache)
+	}
+
 	// QAハンドラーの初期化（サービスが初期化できなかった場合はnilが渡される）
 	var qaHandler *handler.QAHandler
 	if qaService != nil {
@@ -274,12 +581,60 @@ func main() {
 		log.Warn().Msg("QA handler skipped due to QA service initialization failure")
 	}
 
+	// ヘルスチェックサービスの初期化
+	// 起動時に初期化できなかった依存先もreadinessで縮退状態として報告する
+	// ALBは /readyz でタスクを切り離すため、Critical にはすべてのエンドポイントが依存するS3のみを指定する
+	// Bedrockのスロットリングや障害時も、アップロードなどBedrockを使わない機能は提供し続ける
+	healthService := services.NewHealthService(cfg.Health.CheckTimeout,
+		services.HealthCheck{
+			Name:     "postgres",
+			Features: []string{"recommend", "ingest", "document_versions", "document_tags", "sync", "reindex", "usage", "budget", "qa_cache", "embedding_cache"},
+			Check: func(ctx context.Context) error {
+				if dbHandler == nil {
+					return errors.New("データベースに接続されていません")
+				}
+				return dbHandler.Ping(ctx)
+			},
+		},
+		services.HealthCheck{
+			Name:     "s3",
+			Critical: true,
+			Features: []string{"upload", "summarize_file", "document"},
+			Check:    s3Client.HeadBucket,
+		},
+		services.HealthCheck{
+			Name:     "bedrock",
+			Features: []string{"summarize_text", "summarize_file", "document", "qa", "recommend", "extract", "ingest", "reindex"},
+			CacheTTL: cfg.Health.BedrockCacheTTL,
+			Check:    bedrockClient.CheckModelAccess,
+		},
+		services.HealthCheck{
+			Name:     "knowledge_base",
+			Features: []string{"qa"},
+			Check: func(ctx context.Context) error {
+				if qaService == nil {
+					return errors.New("knowledge Baseが設定されていません (BEDROCK_KB_ID)")
+				}
+				return nil
+			},
+		},
+	)
+	healthHandler := handler.NewHealthHandler(healthService)
+	log.Info().Msg("Health service initialized")
+
 	// Echo instance
 	e := echo.New()
 
 	// カスタムエラーハンドラーを設定
 	e.HTTPErrorHandler = customHTTPErrorHandler
 
+	// 送信元IPアドレス (レート制限の単位) はクライアントが偽装できるヘッダーではなく、信頼するプロキシが付与した値から決める
+	ipExtractor, err := appmiddleware.NewIPExtractor(cfg.Server.TrustedProxies)
+	if err != nil {
+		log.Fatal().Err(err).Msg("送信元IPアドレスの判定方法の設定が不正です")
+	}
+	e.IPExtractor = ipExtractor
+
 	// Middleware
 	e.Use(zerologLoggerMiddleware) // <- zerologベースのロガーミドルウェアを使用
 	// e.Use(middleware.Logger()) // <- コメントアウト
@@ -291,18 +646,128 @@ func main() {
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 	log.Info().Msg("Swagger UI endpoint configured at /swagger/")
 
+	// モデル呼び出しの使用量をリクエスト・テナント・エンドポイントに帰属させる
+	apiMiddlewares := []echo.MiddlewareFunc{appmiddleware.UsageScope()}
+	if budgetService != nil {
+		apiMiddlewares = append(apiMiddlewares, appmiddleware.BudgetHeaders(budgetService))
+	}
+
+	// クライアントごとのレート制限 (Bedrockへのバースト流入を抑える)
+	if cfg.RateLimit.Enabled {
+		apiMiddlewares = append(apiMiddlewares, appmiddleware.NewRateLimiter(cfg.RateLimit).Middleware())
+		log.Info().
+			Float64("rps", cfg.RateLimit.RequestsPerSecond).
+			Int("burst", cfg.RateLimit.Burst).
+			Msg("Rate limiter configured")
+	}
+
 	// ルートを設定
-	route.SetupRoutes(e, uploadHandler, summarizeHandler, qaHandler, documentHandler, recommendHandler)
+	route.SetupRoutes(e, uploadHandler, summarizeHandler, qaHandler, documentHandler, recommendHandler, ingestHandler, documentVersionHandler, reindexHandler, modelHandler, usageHandler, embeddingCacheHandler, syncHandler, extractHandler, documentTagHandler, apiMiddlewares...)
 	log.Info().Msg("Routes configured")
 
-	// ヘルスチェック用のエンドポイント
+	// liveness/readinessチェック用のエンドポイント
+	route.SetupHealthRoutes(e, healthHandler)
+
+	// ヘルスチェック用のエンドポイント (後方互換のため残す。依存先の状態は /readyz を参照)
 	e.GET("/health", func(c echo.Context) error {
 		log.Debug().Msg("Health check requested") // デバッグレベルでログ出力
 		return c.String(http.StatusOK, "Healthy")
 	})
 
+	// サーバーのタイムアウトを設定
+	e.Server.ReadTimeout = cfg.Server.ReadTimeout
+	e.Server.ReadHeaderTimeout = cfg.Server.ReadHeaderTimeout
+	e.Server.WriteTimeout = cfg.Server.WriteTimeout
+	e.Server.IdleTimeout = cfg.Server.IdleTimeout
+
+	// SIGINT/SIGTERM を受けたらグレースフルシャットダウンを開始する
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// 定期的な同期・クロールとディレクトリの監視はシャットダウンの開始とともに止める
+	if syncService != nil {
+		if err := syncService.StartSchedule(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to start S3 sync schedule")
+		}
+	}
+	if dirSyncService != nil {
+		if err := dirSyncService.Watch(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to start directory watching")
+		}
+	}
+	if crawlService != nil {
+		if err := crawlService.StartSchedule(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to start web crawl schedule")
+		}
+	}
+	if gitSyncService != nil {
+		if err := gitSyncService.StartSchedule(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to start git sync schedule")
+		}
+	}
+
 	// Start server
-	serverAddress := ":8080"
-	log.Info().Str("address", serverAddress).Msg("Starting server")
-	e.Logger.Fatal(e.Start(serverAddress))
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Info().Str("address", cfg.Server.Address).Msg("Starting server")
+		if err := e.Start(cfg.Server.Address); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		log.Info().Msg("Shutdown signal received")
+	case err := <-serverErr:
+		if err != nil {
+			log.Error().Err(err).Msg("Server stopped unexpectedly")
+			exitCode = 1
+		}
+	}
+	// 2回目のシグナルではプロセスを即時終了させる
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := gracefulShutdown(shutdownCtx, e, workers, dbHandler); err != nil {
+		exitCode = 1
+	}
+	log.Info().Msg("Server stopped")
+	os.Exit(exitCode)
+}
+
+// gracefulShutdown は新規接続の受付を停止し、処理中のリクエストとバックグラウンドタスクの完了を待ってからリソースを解放する
+// いずれかの段階が ctx の期限を超えた場合もリソースの解放は必ず行う
+func gracefulShutdown(ctx context.Context, e *echo.Echo, workers *worker.Group, dbHandler *domain.DBHandler) error {
+	var errs []error
+
+	// 1. HTTPサーバー: 新規接続を止め、処理中のリクエストを待つ
+	if err := e.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to drain in-flight requests")
+		errs = append(errs, err)
+	} else {
+		log.Info().Msg("HTTP server drained")
+	}
+
+	// 2. バックグラウンドタスク: 完了を待ち、期限を過ぎたらキャンセルする
+	if err := workers.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("Background tasks did not finish before shutdown deadline")
+		errs = append(errs, err)
+	} else {
+		log.Info().Msg("Background tasks finished")
+	}
+
+	// 3. リソースの解放
+	if dbHandler != nil {
+		if err := dbHandler.Close(); err != nil {
+			log.Error().Err(err).Msg("Failed to close database connection")
+			errs = append(errs, err)
+		} else {
+			log.Info().Msg("Database connection closed")
+		}
+	}
+
+	return errors.Join(errs...)
 }
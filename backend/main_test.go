@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bedrock-rag-sample/backend/internal/handler"
+	dto "bedrock-rag-sample/backend/internal/handler/dto"
+	"bedrock-rag-sample/backend/internal/services"
+	servicemocks "bedrock-rag-sample/backend/internal/services/mocks"
+	"bedrock-rag-sample/backend/pkg/redact"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomHTTPErrorHandler(t *testing.T) {
+	newServer := func() *echo.Echo {
+		e := echo.New()
+		e.HTTPErrorHandler = customHTTPErrorHandler
+		return e
+	}
+
+	serve := func(e *echo.Echo, path, body string) (*httptest.ResponseRecorder, dto.ErrorResponse) {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		var resp dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return rec, resp
+	}
+
+	t.Run("異常系: スキーマに適合しない抽出結果はEXTRACTION_INVALIDを返す", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExtractService := servicemocks.NewMockExtractServiceInterface(ctrl)
+		mockExtractService.EXPECT().
+			Extract(gomock.Any(), gomock.Any()).
+			Return(nil, fmt.Errorf("%w: 2回生成し直しても適合しませんでした", services.ErrExtractionInvalid))
+
+		e := newServer()
+		e.POST("/extract", handler.NewExtractHandler(mockExtractService).HandleExtract)
+
+		rec, resp := serve(e, "/extract", `{"text": "合計 1,000円", "schema": {"type": "object"}}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, dto.ErrorCodeExtractionInvalid, resp.Error.Code)
+	})
+
+	t.Run("異常系: 個人情報を含む質問はPII_DETECTEDと検出した種類を返す", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockQAService := servicemocks.NewMockQAServiceInterface(ctrl)
+		mockQAService.EXPECT().
+			SimpleRAG(gomock.Any(), "090-1234-5678に連絡して", "").
+			Return(nil, fmt.Errorf("質問の検査に失敗しました: %w", &redact.RejectedError{Types: []string{"email", "phone"}}))
+
+		e := newServer()
+		e.POST("/qa", handler.NewQAHandler(mockQAService).HandleQA)
+
+		rec, resp := serve(e, "/qa", `{"query": "090-1234-5678に連絡して"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, dto.ErrorCodePIIDetected, resp.Error.Code)
+		assert.Equal(t, []string{"email", "phone"}, resp.Error.PIITypes)
+	})
+
+	t.Run("異常系: 原因を特定できない422はUNPROCESSABLE_ENTITYを返す", func(t *testing.T) {
+		e := newServer()
+		e.POST("/unprocessable", func(c echo.Context) error {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "処理できない入力です")
+		})
+
+		rec, resp := serve(e, "/unprocessable", `{}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, "UNPROCESSABLE_ENTITY", resp.Error.Code)
+		assert.Equal(t, "処理できない入力です", resp.Error.Message)
+	})
+}
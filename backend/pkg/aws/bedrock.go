@@ -2,7 +2,6 @@ package aws
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -15,9 +14,20 @@ import (
 
 // BedrockClient はAmazon Bedrockとの連携を行うクライアント
 type BedrockClient struct {
-	client  *bedrockruntime.Client
-	region  string
-	modelID string
+	client     *bedrockruntime.Client
+	region     string
+	textModel  TextModel
+	limiter    *ModelConcurrencyLimiter
+	resilience *Resilience
+	usage      UsageRecorder // nil の場合は使用量を記録しない
+	guard      UsageGuard    // nil の場合は利用上限を確認しない
+	guardrail  *Guardrail    // nil の場合はガードレールを適用しない (Embeddingモデルには適用できない)
+
+	embeddingModel       EmbeddingModel
+	embeddingNormalize   bool
+	embeddingConcurrency int
+	embeddingBatchSize   int
+	embeddingCache       EmbeddingCache // nil の場合はキャッシュしない
 }
 
 // NewBedrockClient は新しいBedrockClientを作成する
@@ -27,27 +37,31 @@ func NewBedrockClient(cfg *config.Config) (*BedrockClient, error) {
 		return nil, fmt.Errorf("AWS設定の読み込みに失敗しました: %w", err)
 	}
 
-	client := bedrockruntime.NewFromConfig(awsCfg)
+	textModel, err := LookupTextModel(cfg.AWS.BedrockModelID)
+	if err != nil {
+		return nil, err
+	}
 
-	return &BedrockClient{
-		client:  client,
-		region:  cfg.AWS.Region,
-		modelID: cfg.AWS.BedrockModelID,
-	}, nil
-}
+	embeddingModel, err := LookupEmbeddingModel(cfg.AWS.EmbeddingModel)
+	if err != nil {
+		return nil, err
+	}
 
-// ClaudeInput はClaude Anthropicモデルへの入力形式
-type ClaudeInput struct {
-	Prompt            string  `json:"prompt"`
-	MaxTokensToSample int     `json:"max_tokens_to_sample"`
-	Temperature       float64 `json:"temperature"`
-	TopP              float64 `json:"top_p"`
-	TopK              int     `json:"top_k"`
-}
+	client := bedrockruntime.NewFromConfig(awsCfg, withoutSDKRetry)
 
-// ClaudeOutput はClaude Anthropicモデルからの出力形式
-type ClaudeOutput struct {
-	Completion string `json:"completion"`
+	return &BedrockClient{
+		client:     client,
+		region:     cfg.AWS.Region,
+		textModel:  textModel,
+		limiter:    sharedModelLimiter(cfg),
+		resilience: sharedResilienceFor(cfg),
+		guardrail:  NewGuardrail(cfg),
+
+		embeddingModel:       embeddingModel,
+		embeddingNormalize:   cfg.AWS.EmbeddingNormalize,
+		embeddingConcurrency: cfg.AWS.EmbeddingConcurrency,
+		embeddingBatchSize:   cfg.AWS.EmbeddingBatchSize,
+	}, nil
 }
 
 // TitanEmbeddingInput はAmazon Titan Embeddingモデルへの入力形式
@@ -60,89 +74,105 @@ type TitanEmbeddingOutput struct {
 	Embedding []float32 `json:"embedding"`
 }
 
+// invokeModel はモデルごとの同時実行数の制限内で InvokeModel を呼び出す
+// 一時的な障害は再試行し、障害が続くモデルはサーキットブレーカーで遮断する
+func invokeModel(ctx context.Context, client *bedrockruntime.Client, limiter *ModelConcurrencyLimiter, resilience *Resilience, modelID string, body []byte) (*bedrockruntime.InvokeModelOutput, error) {
+	return callWithResilience(ctx, resilience, modelID, func(ctx context.Context) (*bedrockruntime.InvokeModelOutput, error) {
+		// 再試行の待機中は実行枠を解放しておくため、試行ごとに取得する
+		release, err := limiter.Acquire(ctx, modelID)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		return client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+			ModelId:     aws.String(modelID),
+			Body:        body,
+			ContentType: aws.String("application/json"),
+		})
+	})
+}
+
+// withoutSDKRetry はSDK組み込みの再試行を無効にする
+// 再試行は callWithResilience に一本化し、サーキットブレーカーが実際の失敗回数を数えられるようにする
+func withoutSDKRetry(o *bedrockruntime.Options) {
+	o.RetryMaxAttempts = 1
+}
+
 // GenerateSummary はテキストの要約を生成する
 func (b *BedrockClient) GenerateSummary(ctx context.Context, text string) (string, error) {
-	// 入力プロンプトの作成
-	prompt := fmt.Sprintf(`<human>以下のテキストを100-200文字程度の日本語で要約してください。要約のみを返してください。
+	prompt := fmt.Sprintf(`以下のテキストを100-200文字程度の日本語で要約してください。要約のみを返してください。
 
-%s</human>
-
-<assistant>`, text)
+%s`, text)
 
 	return b.GenerateText(ctx, prompt)
 }
 
 // GenerateText はテキスト生成を行う
 func (b *BedrockClient) GenerateText(ctx context.Context, prompt string) (string, error) {
-	input := ClaudeInput{
-		Prompt:            prompt,
-		MaxTokensToSample: 2048,
-		Temperature:       0.7,
-		TopP:              0.9,
-		TopK:              250,
-	}
-
-	// リクエストボディの作成
-	inputBytes, err := json.Marshal(input)
+	response, err := b.Converse(ctx, ConverseRequest{Messages: []ConverseMessage{UserMessage(prompt)}})
 	if err != nil {
-		return "", fmt.Errorf("入力JSONの作成に失敗しました: %w", err)
+		return "", err
 	}
 
-	// bedrockにリクエスト
-	response, err := b.client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
-		ModelId:     aws.String(b.modelID),
-		Body:        inputBytes,
-		ContentType: aws.String("application/json"),
-	})
+	// 余分な空白や改行を削除
+	return strings.TrimSpace(response.Text()), nil
+}
 
-	if err != nil {
-		return "", fmt.Errorf("bedrockの呼び出しに失敗しました: %w", err)
-	}
+// SetUsageRecorder はモデル呼び出しの使用量の記録先を設定する
+// WithTextModel / WithEmbeddingModel で作成したクライアントにも引き継がれる
+func (b *BedrockClient) SetUsageRecorder(recorder UsageRecorder) {
+	b.usage = recorder
+}
 
-	// レスポンスの解析
-	var output ClaudeOutput
-	if err := json.Unmarshal(response.Body, &output); err != nil {
-		return "", fmt.Errorf("レスポンスの解析に失敗しました: %w", err)
-	}
+// SetUsageGuard はモデルを呼び出す前に利用上限を確認する guard を設定する
+// WithTextModel / WithEmbeddingModel で作成したクライアントにも引き継がれる
+func (b *BedrockClient) SetUsageGuard(guard UsageGuard) {
+	b.guard = guard
+}
 
-	// 余分な空白や改行を削除
-	result := strings.TrimSpace(output.Completion)
+// TextModel は使用中のテキスト生成モデルの定義を返す
+func (b *BedrockClient) TextModel() TextModel {
+	return b.textModel
+}
 
-	return result, nil
+// WithTextModel は指定したテキスト生成モデルを使用するクライアントを返す
+// 同時実行数の制限とサーキットブレーカーは元のクライアントと共有する
+func (b *BedrockClient) WithTextModel(model TextModel) BedrockClientInterface {
+	clone := *b
+	clone.textModel = model
+	return &clone
 }
 
 // GenerateEmbedding はテキストからEmbeddingを生成する
+// 検索クエリのEmbeddingに使用する (文書側は GenerateEmbeddings を使用する)
 func (b *BedrockClient) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
-	// Titan Embeddingモデル ID
-	embeddingModelID := "amazon.titan-embed-text-v1"
-
-	// 入力を準備
-	input := TitanEmbeddingInput{
-		InputText: text,
-	}
-
-	// リクエストボディの作成
-	inputBytes, err := json.Marshal(input)
+	embeddings, err := b.invokeEmbedding(ctx, []string{text}, embeddingPurposeQuery)
 	if err != nil {
-		return nil, fmt.Errorf("入力JSONの作成に失敗しました: %w", err)
+		return nil, err
 	}
+	return embeddings[0], nil
+}
 
-	// bedrockにリクエスト
-	response, err := b.client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
-		ModelId:     aws.String(embeddingModelID),
-		Body:        inputBytes,
-		ContentType: aws.String("application/json"),
-	})
+// EmbeddingModel は使用中のEmbeddingモデルの定義を返す
+func (b *BedrockClient) EmbeddingModel() EmbeddingModel {
+	return b.embeddingModel
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("bedrock Embeddingの呼び出しに失敗しました: %w", err)
-	}
+// WithEmbeddingModel は指定したEmbeddingモデルを使用するクライアントを返す
+// 同時実行数の制限とサーキットブレーカーは元のクライアントと共有する
+func (b *BedrockClient) WithEmbeddingModel(model EmbeddingModel) BedrockClientInterface {
+	clone := *b
+	clone.embeddingModel = model
+	return &clone
+}
 
-	// レスポンスの解析
-	var output TitanEmbeddingOutput
-	if err := json.Unmarshal(response.Body, &output); err != nil {
-		return nil, fmt.Errorf("レスポンスの解析に失敗しました: %w", err)
+// CheckModelAccess はBedrockのモデルへ到達できるかを確認する (ヘルスチェック用)
+// 最も安価なEmbeddingモデルを短いテキストで呼び出し、認証情報・リージョン・モデルアクセス権を検証する
+// キャッシュから応答すると疎通を確認できないため、Embeddingのキャッシュを通さずに毎回モデルを呼び出す
+func (b *BedrockClient) CheckModelAccess(ctx context.Context) error {
+	if _, err := b.callEmbeddingModel(ctx, []string{"ping"}, embeddingPurposeQuery); err != nil {
+		return fmt.Errorf("bedrockモデルへの疎通確認に失敗しました: %w", err)
 	}
-
-	return output.Embedding, nil
+	return nil
 }
@@ -0,0 +1,395 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// ErrToolsNotSupported はツール定義に対応していないモデルにツールを渡した場合のエラー
+var ErrToolsNotSupported = errors.New("このモデルはツールの利用に対応していません")
+
+// 会話のロール
+const (
+	ConverseRoleUser      = "user"
+	ConverseRoleAssistant = "assistant"
+)
+
+// ツールの選択方法
+const (
+	ToolChoiceAuto = "auto" // モデルが必要に応じて選択する (既定)
+	ToolChoiceAny  = "any"  // いずれかのツールを必ず呼び出す
+	// それ以外の値はツール名とみなし、そのツールを必ず呼び出す
+)
+
+// 生成の停止理由
+const (
+	StopReasonEndTurn   = "end_turn"
+	StopReasonToolUse   = "tool_use"
+	StopReasonMaxTokens = "max_tokens"
+
+	StopReasonGuardrailIntervened = "guardrail_intervened" // ガードレールが介入した
+)
+
+// ConverseRequest はConverse APIへのリクエスト
+type ConverseRequest struct {
+	System        []string          // システムプロンプト
+	Messages      []ConverseMessage // 会話履歴 (user から始まり、user と assistant が交互になる)
+	Tools         []ConverseTool    // モデルが呼び出せるツール
+	ToolChoice    string            // ToolChoiceAuto / ToolChoiceAny / ツール名 (空の場合は auto)
+	MaxTokens     int               // 出力する最大トークン数 (0の場合はモデルの既定値)
+	Temperature   *float64          // nil の場合は既定値
+	TopP          *float64          // nil の場合は既定値
+	StopSequences []string
+}
+
+// ConverseMessage は会話の1ターン
+type ConverseMessage struct {
+	Role    string            `json:"role"`
+	Content []ConverseContent `json:"content"`
+}
+
+// ConverseContent はメッセージに含まれるコンテンツ (Text / ToolUse / ToolResult のいずれか1つを設定する)
+type ConverseContent struct {
+	Text       string              `json:"text,omitempty"`
+	ToolUse    *ConverseToolUse    `json:"tool_use,omitempty"`
+	ToolResult *ConverseToolResult `json:"tool_result,omitempty"`
+}
+
+// ConverseTool はモデルに提示するツールの定義
+type ConverseTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"` // 入力のJSON Schema
+}
+
+// ConverseToolUse はモデルによるツールの呼び出し
+type ConverseToolUse struct {
+	ID    string          `json:"id"`
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input"`
+}
+
+// ConverseToolResult はツールの実行結果 (user ロールのメッセージで返す)
+type ConverseToolResult struct {
+	ToolUseID string          `json:"tool_use_id"`
+	Text      string          `json:"text,omitempty"`
+	JSON      json.RawMessage `json:"json,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+}
+
+// TokenUsage は1回の呼び出しで消費したトークン数
+type TokenUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// ConverseResponse はConverse APIのレスポンス
+type ConverseResponse struct {
+	Model      string          `json:"model"` // 使用したモデルのカタログ名
+	Message    ConverseMessage `json:"message"`
+	StopReason string          `json:"stop_reason"`
+	Usage      TokenUsage      `json:"usage"`
+
+	Guardrail *GuardrailOutcome `json:"guardrail,omitempty"` // ガードレールが介入した場合のみ設定する
+}
+
+// Text はレスポンスに含まれるテキストを連結して返す
+func (r *ConverseResponse) Text() string {
+	var sb strings.Builder
+	for _, c := range r.Message.Content {
+		sb.WriteString(c.Text)
+	}
+	return sb.String()
+}
+
+// ToolUses はレスポンスに含まれるツールの呼び出しを返す
+func (r *ConverseResponse) ToolUses() []ConverseToolUse {
+	var uses []ConverseToolUse
+	for _, c := range r.Message.Content {
+		if c.ToolUse != nil {
+			uses = append(uses, *c.ToolUse)
+		}
+	}
+	return uses
+}
+
+// usageEvent はレスポンスのトークン数を使用量として返す
+func (r *ConverseResponse) usageEvent(model TextModel) UsageEvent {
+	return UsageEvent{
+		Model:        model.Name,
+		ModelID:      model.ModelID,
+		Operation:    UsageOperationConverse,
+		InputTokens:  r.Usage.InputTokens,
+		OutputTokens: r.Usage.OutputTokens,
+	}
+}
+
+// UserMessage はテキストのみの user メッセージを作成する
+func UserMessage(text string) ConverseMessage {
+	return ConverseMessage{Role: ConverseRoleUser, Content: []ConverseContent{{Text: text}}}
+}
+
+// ToolResultMessage はツールの実行結果を返す user メッセージを作成する
+func ToolResultMessage(results ...ConverseToolResult) ConverseMessage {
+	msg := ConverseMessage{Role: ConverseRoleUser}
+	for i := range results {
+		msg.Content = append(msg.Content, ConverseContent{ToolResult: &results[i]})
+	}
+	return msg
+}
+
+// Converse は使用中のテキスト生成モデルでConverse APIを呼び出す
+func (b *BedrockClient) Converse(ctx context.Context, req ConverseRequest) (*ConverseResponse, error) {
+	if err := checkUsage(ctx, b.guard); err != nil {
+		return nil, err
+	}
+	response, err := converse(ctx, b.client, b.limiter, b.resilience, b.guardrail, b.textModel, req)
+	if response != nil {
+		recordUsage(ctx, b.usage, response.usageEvent(b.textModel))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// converse はモデルごとの同時実行数の制限内でConverse APIを呼び出す
+// InvokeModel と同じく、一時的な障害は再試行し、障害が続くモデルはサーキットブレーカーで遮断する
+// ガードレールが遮断した場合は、消費したトークン数を記録できるようレスポンスとともに ContentBlockedError を返す
+func converse(ctx context.Context, client *bedrockruntime.Client, limiter *ModelConcurrencyLimiter, resilience *Resilience, guardrail *Guardrail, model TextModel, req ConverseRequest) (*ConverseResponse, error) {
+	input, err := buildConverseInput(model, req)
+	if err != nil {
+		return nil, err
+	}
+	input.GuardrailConfig = guardrail.converseConfig()
+
+	output, err := callWithResilience(ctx, resilience, model.ModelID, func(ctx context.Context) (*bedrockruntime.ConverseOutput, error) {
+		release, err := limiter.Acquire(ctx, model.ModelID)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		return client.Converse(ctx, input)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bedrockの呼び出しに失敗しました (model: %s): %w", model.Name, err)
+	}
+
+	response, err := parseConverseOutput(output)
+	if err != nil {
+		return nil, fmt.Errorf("レスポンスの解析に失敗しました (model: %s): %w", model.Name, err)
+	}
+	response.Model = model.Name
+	if output.StopReason == types.StopReasonGuardrailIntervened {
+		outcome := parseGuardrailTrace(output.Trace)
+		if outcome.Blocked {
+			// 遮断した場合の出力はガードレールに設定したメッセージになる
+			outcome.Message = strings.TrimSpace(response.Text())
+		}
+		response.Guardrail = &outcome
+		if outcome.Blocked {
+			return response, &ContentBlockedError{Model: model.Name, Outcome: outcome}
+		}
+	}
+	return response, nil
+}
+
+// buildConverseInput はリクエストをSDKの入力形式に変換する
+// システムプロンプトに対応していないモデルでは、システムプロンプトを最初の user メッセージの先頭に含める
+func buildConverseInput(model TextModel, req ConverseRequest) (*bedrockruntime.ConverseInput, error) {
+	if len(req.Messages) == 0 {
+		return nil, errors.New("メッセージがありません")
+	}
+	if len(req.Tools) > 0 && !model.SupportsTools {
+		return nil, fmt.Errorf("%w: %s", ErrToolsNotSupported, model.Name)
+	}
+
+	messages := req.Messages
+	input := &bedrockruntime.ConverseInput{ModelId: aws.String(model.ModelID)}
+	if len(req.System) > 0 {
+		if model.SupportsSystemPrompt {
+			for _, s := range req.System {
+				input.System = append(input.System, &types.SystemContentBlockMemberText{Value: s})
+			}
+		} else {
+			messages = prependSystemPrompt(req.System, messages)
+		}
+	}
+
+	for _, m := range messages {
+		msg, err := toSDKMessage(m)
+		if err != nil {
+			return nil, err
+		}
+		input.Messages = append(input.Messages, msg)
+	}
+
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = model.MaxTokens
+	}
+	temperature, topP := defaultTextTemperature, defaultTextTopP
+	if req.Temperature != nil {
+		temperature = *req.Temperature
+	}
+	if req.TopP != nil {
+		topP = *req.TopP
+	}
+	input.InferenceConfig = &types.InferenceConfiguration{
+		MaxTokens:     aws.Int32(int32(maxTokens)),
+		Temperature:   aws.Float32(float32(temperature)),
+		TopP:          aws.Float32(float32(topP)),
+		StopSequences: req.StopSequences,
+	}
+
+	if len(req.Tools) > 0 {
+		toolConfig := &types.ToolConfiguration{}
+		for _, tool := range req.Tools {
+			schema, err := toDocument(tool.InputSchema)
+			if err != nil {
+				return nil, fmt.Errorf("ツール %s の入力スキーマが不正です: %w", tool.Name, err)
+			}
+			spec := types.ToolSpecification{
+				Name:        aws.String(tool.Name),
+				InputSchema: &types.ToolInputSchemaMemberJson{Value: schema},
+			}
+			if tool.Description != "" {
+				spec.Description = aws.String(tool.Description)
+			}
+			toolConfig.Tools = append(toolConfig.Tools, &types.ToolMemberToolSpec{Value: spec})
+		}
+		switch req.ToolChoice {
+		case "", ToolChoiceAuto:
+			toolConfig.ToolChoice = &types.ToolChoiceMemberAuto{}
+		case ToolChoiceAny:
+			toolConfig.ToolChoice = &types.ToolChoiceMemberAny{}
+		default:
+			toolConfig.ToolChoice = &types.ToolChoiceMemberTool{Value: types.SpecificToolChoice{Name: aws.String(req.ToolChoice)}}
+		}
+		input.ToolConfig = toolConfig
+	}
+	return input, nil
+}
+
+// prependSystemPrompt はシステムプロンプトを最初の user メッセージの先頭に挿入したコピーを返す
+func prependSystemPrompt(system []string, messages []ConverseMessage) []ConverseMessage {
+	out := make([]ConverseMessage, len(messages))
+	copy(out, messages)
+	for i, m := range out {
+		if m.Role != ConverseRoleUser {
+			continue
+		}
+		content := []ConverseContent{{Text: strings.Join(system, "\n\n") + "\n\n"}}
+		out[i].Content = append(content, m.Content...)
+		break
+	}
+	return out
+}
+
+// toSDKMessage はメッセージをSDKの形式に変換する
+func toSDKMessage(m ConverseMessage) (types.Message, error) {
+	msg := types.Message{Role: types.ConversationRole(m.Role)}
+	for _, c := range m.Content {
+		switch {
+		case c.ToolUse != nil:
+			input, err := toDocument(c.ToolUse.Input)
+			if err != nil {
+				return types.Message{}, fmt.Errorf("ツール %s の入力が不正です: %w", c.ToolUse.Name, err)
+			}
+			msg.Content = append(msg.Content, &types.ContentBlockMemberToolUse{Value: types.ToolUseBlock{
+				ToolUseId: aws.String(c.ToolUse.ID),
+				Name:      aws.String(c.ToolUse.Name),
+				Input:     input,
+			}})
+		case c.ToolResult != nil:
+			result := types.ToolResultBlock{ToolUseId: aws.String(c.ToolResult.ToolUseID), Status: types.ToolResultStatusSuccess}
+			if c.ToolResult.IsError {
+				result.Status = types.ToolResultStatusError
+			}
+			if c.ToolResult.Text != "" {
+				result.Content = append(result.Content, &types.ToolResultContentBlockMemberText{Value: c.ToolResult.Text})
+			}
+			if len(c.ToolResult.JSON) > 0 {
+				doc, err := toDocument(c.ToolResult.JSON)
+				if err != nil {
+					return types.Message{}, fmt.Errorf("ツールの実行結果が不正です (tool_use_id: %s): %w", c.ToolResult.ToolUseID, err)
+				}
+				result.Content = append(result.Content, &types.ToolResultContentBlockMemberJson{Value: doc})
+			}
+			msg.Content = append(msg.Content, &types.ContentBlockMemberToolResult{Value: result})
+		default:
+			msg.Content = append(msg.Content, &types.ContentBlockMemberText{Value: c.Text})
+		}
+	}
+	return msg, nil
+}
+
+// parseConverseOutput はSDKの出力をレスポンスに変換する
+func parseConverseOutput(output *bedrockruntime.ConverseOutput) (*ConverseResponse, error) {
+	message, ok := output.Output.(*types.ConverseOutputMemberMessage)
+	if !ok {
+		return nil, errors.New("出力にメッセージが含まれていません")
+	}
+
+	response := &ConverseResponse{
+		Message:    ConverseMessage{Role: string(message.Value.Role)},
+		StopReason: string(output.StopReason),
+	}
+	for _, block := range message.Value.Content {
+		switch v := block.(type) {
+		case *types.ContentBlockMemberText:
+			response.Message.Content = append(response.Message.Content, ConverseContent{Text: v.Value})
+		case *types.ContentBlockMemberToolUse:
+			input, err := fromDocument(v.Value.Input)
+			if err != nil {
+				return nil, fmt.Errorf("ツールの入力を解析できません: %w", err)
+			}
+			response.Message.Content = append(response.Message.Content, ConverseContent{ToolUse: &ConverseToolUse{
+				ID:    aws.ToString(v.Value.ToolUseId),
+				Name:  aws.ToString(v.Value.Name),
+				Input: input,
+			}})
+		}
+	}
+	if output.Usage != nil {
+		response.Usage = TokenUsage{
+			InputTokens:  int(aws.ToInt32(output.Usage.InputTokens)),
+			OutputTokens: int(aws.ToInt32(output.Usage.OutputTokens)),
+			TotalTokens:  int(aws.ToInt32(output.Usage.TotalTokens)),
+		}
+	}
+	return response, nil
+}
+
+// toDocument はJSONをSDKのドキュメント型に変換する (空の場合は空のオブジェクト)
+func toDocument(raw json.RawMessage) (document.Interface, error) {
+	var v any = map[string]any{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+	}
+	return document.NewLazyDocument(v), nil
+}
+
+// fromDocument はSDKのドキュメント型をJSONに変換する
+func fromDocument(doc document.Interface) (json.RawMessage, error) {
+	if doc == nil {
+		return json.RawMessage("{}"), nil
+	}
+	b, err := doc.MarshalSmithyDocument()
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(b), nil
+}
@@ -0,0 +1,191 @@
+package aws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustTextModel(t *testing.T, name string) TextModel {
+	t.Helper()
+	model, err := LookupTextModel(name)
+	require.NoError(t, err)
+	return model
+}
+
+func TestBuildConverseInput(t *testing.T) {
+	t.Run("システムプロンプトと推論パラメータの既定値", func(t *testing.T) {
+		model := mustTextModel(t, "claude-3-haiku")
+
+		input, err := buildConverseInput(model, ConverseRequest{
+			System:   []string{"日本語で答えてください"},
+			Messages: []ConverseMessage{UserMessage("質問")},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ModelID, aws.ToString(input.ModelId))
+		require.Len(t, input.System, 1)
+		assert.Equal(t, "日本語で答えてください", input.System[0].(*types.SystemContentBlockMemberText).Value)
+		require.Len(t, input.Messages, 1)
+		assert.Equal(t, types.ConversationRoleUser, input.Messages[0].Role)
+		assert.Equal(t, "質問", input.Messages[0].Content[0].(*types.ContentBlockMemberText).Value)
+		assert.Equal(t, int32(2048), aws.ToInt32(input.InferenceConfig.MaxTokens))
+		assert.InDelta(t, 0.7, aws.ToFloat32(input.InferenceConfig.Temperature), 1e-6)
+		assert.InDelta(t, 0.9, aws.ToFloat32(input.InferenceConfig.TopP), 1e-6)
+		assert.Nil(t, input.ToolConfig)
+	})
+
+	t.Run("システムプロンプト非対応のモデルは最初のメッセージに含める", func(t *testing.T) {
+		model := mustTextModel(t, "titan-text-lite")
+		messages := []ConverseMessage{UserMessage("質問")}
+
+		input, err := buildConverseInput(model, ConverseRequest{System: []string{"指示"}, Messages: messages})
+
+		require.NoError(t, err)
+		assert.Empty(t, input.System)
+		require.Len(t, input.Messages[0].Content, 2)
+		assert.Equal(t, "指示\n\n", input.Messages[0].Content[0].(*types.ContentBlockMemberText).Value)
+		assert.Equal(t, "質問", input.Messages[0].Content[1].(*types.ContentBlockMemberText).Value)
+		assert.Len(t, messages[0].Content, 1, "呼び出し元のメッセージは変更しない")
+	})
+
+	t.Run("ツール定義とツール結果のターン", func(t *testing.T) {
+		model := mustTextModel(t, "claude-3-5-sonnet")
+		temperature := 0.0
+
+		input, err := buildConverseInput(model, ConverseRequest{
+			Messages: []ConverseMessage{
+				UserMessage("東京の天気は？"),
+				{Role: ConverseRoleAssistant, Content: []ConverseContent{
+					{ToolUse: &ConverseToolUse{ID: "tool-1", Name: "get_weather", Input: json.RawMessage(`{"city":"東京"}`)}},
+				}},
+				ToolResultMessage(ConverseToolResult{ToolUseID: "tool-1", JSON: json.RawMessage(`{"weather":"晴れ"}`)}),
+			},
+			Tools: []ConverseTool{{
+				Name:        "get_weather",
+				Description: "天気を取得する",
+				InputSchema: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}}}`),
+			}},
+			ToolChoice:  "get_weather",
+			MaxTokens:   256,
+			Temperature: &temperature,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(256), aws.ToInt32(input.InferenceConfig.MaxTokens))
+		assert.Zero(t, aws.ToFloat32(input.InferenceConfig.Temperature))
+
+		require.Len(t, input.ToolConfig.Tools, 1)
+		spec := input.ToolConfig.Tools[0].(*types.ToolMemberToolSpec).Value
+		assert.Equal(t, "get_weather", aws.ToString(spec.Name))
+		assert.Equal(t, "天気を取得する", aws.ToString(spec.Description))
+		schema, err := spec.InputSchema.(*types.ToolInputSchemaMemberJson).Value.MarshalSmithyDocument()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"type":"object","properties":{"city":{"type":"string"}}}`, string(schema))
+		assert.Equal(t, "get_weather", aws.ToString(input.ToolConfig.ToolChoice.(*types.ToolChoiceMemberTool).Value.Name))
+
+		toolUse := input.Messages[1].Content[0].(*types.ContentBlockMemberToolUse).Value
+		assert.Equal(t, "tool-1", aws.ToString(toolUse.ToolUseId))
+		toolInput, err := toolUse.Input.MarshalSmithyDocument()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"city":"東京"}`, string(toolInput))
+
+		toolResult := input.Messages[2].Content[0].(*types.ContentBlockMemberToolResult).Value
+		assert.Equal(t, "tool-1", aws.ToString(toolResult.ToolUseId))
+		assert.Equal(t, types.ToolResultStatusSuccess, toolResult.Status)
+		require.Len(t, toolResult.Content, 1)
+		assert.IsType(t, &types.ToolResultContentBlockMemberJson{}, toolResult.Content[0])
+	})
+
+	t.Run("ツールのエラー結果", func(t *testing.T) {
+		input, err := buildConverseInput(mustTextModel(t, "claude-3-haiku"), ConverseRequest{
+			Messages: []ConverseMessage{ToolResultMessage(ConverseToolResult{ToolUseID: "tool-1", Text: "タイムアウト", IsError: true})},
+		})
+
+		require.NoError(t, err)
+		toolResult := input.Messages[0].Content[0].(*types.ContentBlockMemberToolResult).Value
+		assert.Equal(t, types.ToolResultStatusError, toolResult.Status)
+		assert.Equal(t, "タイムアウト", toolResult.Content[0].(*types.ToolResultContentBlockMemberText).Value)
+	})
+
+	t.Run("ToolChoice の指定", func(t *testing.T) {
+		tools := []ConverseTool{{Name: "search"}}
+		for choice, want := range map[string]types.ToolChoice{
+			"":             &types.ToolChoiceMemberAuto{},
+			ToolChoiceAuto: &types.ToolChoiceMemberAuto{},
+			ToolChoiceAny:  &types.ToolChoiceMemberAny{},
+		} {
+			input, err := buildConverseInput(mustTextModel(t, "claude-3-haiku"), ConverseRequest{
+				Messages: []ConverseMessage{UserMessage("質問")}, Tools: tools, ToolChoice: choice,
+			})
+			require.NoError(t, err)
+			assert.IsType(t, want, input.ToolConfig.ToolChoice, choice)
+		}
+	})
+
+	t.Run("異常系_ツール非対応のモデル", func(t *testing.T) {
+		_, err := buildConverseInput(mustTextModel(t, "titan-text-express"), ConverseRequest{
+			Messages: []ConverseMessage{UserMessage("質問")},
+			Tools:    []ConverseTool{{Name: "search"}},
+		})
+		assert.ErrorIs(t, err, ErrToolsNotSupported)
+	})
+
+	t.Run("異常系_メッセージなし", func(t *testing.T) {
+		_, err := buildConverseInput(mustTextModel(t, "claude-3-haiku"), ConverseRequest{})
+		assert.Error(t, err)
+	})
+
+	t.Run("異常系_入力スキーマが不正なJSON", func(t *testing.T) {
+		_, err := buildConverseInput(mustTextModel(t, "claude-3-haiku"), ConverseRequest{
+			Messages: []ConverseMessage{UserMessage("質問")},
+			Tools:    []ConverseTool{{Name: "search", InputSchema: json.RawMessage(`{`)}},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestParseConverseOutput(t *testing.T) {
+	t.Run("テキストとツール呼び出し、トークン数", func(t *testing.T) {
+		output := &bedrockruntime.ConverseOutput{
+			Output: &types.ConverseOutputMemberMessage{Value: types.Message{
+				Role: types.ConversationRoleAssistant,
+				Content: []types.ContentBlock{
+					&types.ContentBlockMemberText{Value: "天気を調べます。"},
+					&types.ContentBlockMemberToolUse{Value: types.ToolUseBlock{
+						ToolUseId: aws.String("tool-1"),
+						Name:      aws.String("get_weather"),
+						Input:     document.NewLazyDocument(map[string]any{"city": "東京"}),
+					}},
+				},
+			}},
+			StopReason: types.StopReasonToolUse,
+			Usage:      &types.TokenUsage{InputTokens: aws.Int32(12), OutputTokens: aws.Int32(8), TotalTokens: aws.Int32(20)},
+		}
+
+		response, err := parseConverseOutput(output)
+
+		require.NoError(t, err)
+		assert.Equal(t, ConverseRoleAssistant, response.Message.Role)
+		assert.Equal(t, StopReasonToolUse, response.StopReason)
+		assert.Equal(t, "天気を調べます。", response.Text())
+		assert.Equal(t, TokenUsage{InputTokens: 12, OutputTokens: 8, TotalTokens: 20}, response.Usage)
+
+		uses := response.ToolUses()
+		require.Len(t, uses, 1)
+		assert.Equal(t, "tool-1", uses[0].ID)
+		assert.Equal(t, "get_weather", uses[0].Name)
+		assert.JSONEq(t, `{"city":"東京"}`, string(uses[0].Input))
+	})
+
+	t.Run("異常系_メッセージが含まれない", func(t *testing.T) {
+		_, err := parseConverseOutput(&bedrockruntime.ConverseOutput{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,163 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Cohere Embed の input_type
+const (
+	cohereInputTypeQuery    = "search_query"    // 検索クエリ
+	cohereInputTypeDocument = "search_document" // 検索対象の文書
+)
+
+// CohereEmbeddingInput はCohere Embedモデルへの入力形式
+type CohereEmbeddingInput struct {
+	Texts     []string `json:"texts"`
+	InputType string   `json:"input_type"`
+	Truncate  string   `json:"truncate,omitempty"`
+}
+
+// CohereEmbeddingOutput はCohere Embedモデルからの出力形式
+type CohereEmbeddingOutput struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// EmbeddingsError は複数テキストのEmbedding生成で一部が失敗した場合のエラー
+// 成功したテキストの結果は GenerateEmbeddings の戻り値に含まれ、失敗した位置は nil となる
+type EmbeddingsError struct {
+	FailedIndices []int // 失敗したテキストの位置 (昇順)
+	Err           error // 最初に発生したエラー
+}
+
+func (e *EmbeddingsError) Error() string {
+	return fmt.Sprintf("%d件のEmbedding生成に失敗しました: %v", len(e.FailedIndices), e.Err)
+}
+
+func (e *EmbeddingsError) Unwrap() error {
+	return e.Err
+}
+
+// GenerateEmbeddings は複数テキストのEmbeddingを並行して生成する
+// 戻り値は texts と同じ順序で並ぶ。一部が失敗した場合は成功分の結果と *EmbeddingsError を返す
+func (b *BedrockClient) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	// バッチ入力に対応したモデルは複数テキストを1リクエストにまとめる
+	batchSize := b.embeddingModel.MaxBatchSize
+	if b.embeddingBatchSize > 0 && b.embeddingBatchSize < batchSize {
+		batchSize = b.embeddingBatchSize
+	}
+	return embedInBatches(ctx, texts, batchSize, b.embeddingConcurrency, func(ctx context.Context, batch []string) ([][]float32, error) {
+		return b.invokeEmbedding(ctx, batch, embeddingPurposeDocument)
+	})
+}
+
+// invokeEmbedding は texts のEmbeddingを返す
+// キャッシュが設定されている場合は、キャッシュにないテキストのみモデルを呼び出す
+func (b *BedrockClient) invokeEmbedding(ctx context.Context, texts []string, purpose string) ([][]float32, error) {
+	if b.embeddingCache == nil {
+		return b.callEmbeddingModel(ctx, texts, purpose)
+	}
+	key := embeddingCacheKey(b.embeddingModel, purpose, b.embeddingNormalize)
+	return embedWithCache(ctx, b.embeddingCache, key, b.embeddingModel.Dimension, texts, func(ctx context.Context, texts []string) ([][]float32, error) {
+		return b.callEmbeddingModel(ctx, texts, purpose)
+	})
+}
+
+// callEmbeddingModel は使用中のEmbeddingモデルを呼び出し、texts のEmbeddingを生成する
+func (b *BedrockClient) callEmbeddingModel(ctx context.Context, texts []string, purpose string) ([][]float32, error) {
+	model := b.embeddingModel
+	if err := checkUsage(ctx, b.guard); err != nil {
+		return nil, err
+	}
+
+	// リクエストボディの作成
+	inputBytes, err := model.codec.encode(model, texts, purpose, b.embeddingNormalize)
+	if err != nil {
+		return nil, fmt.Errorf("入力JSONの作成に失敗しました: %w", err)
+	}
+
+	// bedrockにリクエスト
+	response, err := invokeModel(ctx, b.client, b.limiter, b.resilience, model.ModelID, inputBytes)
+	if err != nil {
+		return nil, fmt.Errorf("bedrock Embeddingの呼び出しに失敗しました: %w", err)
+	}
+
+	inputTokens, _ := tokenCountsFromMetadata(response.ResultMetadata)
+	recordUsage(ctx, b.usage, UsageEvent{
+		Model:       model.Name,
+		ModelID:     model.ModelID,
+		Operation:   UsageOperationEmbedding,
+		InputTokens: inputTokens,
+	})
+
+	return model.decodeEmbeddings(response.Body, len(texts), b.embeddingNormalize)
+}
+
+// embedInBatches は texts を batchSize 件ずつに分け、最大 concurrency 個のワーカーで embed を呼び出す
+// 結果は texts と同じ順序で返す。失敗したバッチに含まれるテキストの位置は nil とし、*EmbeddingsError を返す
+func embedInBatches(ctx context.Context, texts []string, batchSize, concurrency int, embed func(ctx context.Context, batch []string) ([][]float32, error)) ([][]float32, error) {
+	results := make([][]float32, len(texts))
+	if len(texts) == 0 {
+		return results, nil
+	}
+	batchSize = max(batchSize, 1)
+	concurrency = max(concurrency, 1)
+
+	type job struct{ start, end int }
+	jobs := make(chan job)
+
+	var (
+		mu       sync.Mutex
+		failed   []int
+		firstErr error
+	)
+	fail := func(j job, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for i := j.start; i < j.end; i++ {
+			failed = append(failed, i)
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(concurrency, (len(texts)+batchSize-1)/batchSize); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				// キャンセル後に残ったバッチは呼び出さずに失敗として扱う
+				if err := ctx.Err(); err != nil {
+					fail(j, err)
+					continue
+				}
+				embeddings, err := embed(ctx, texts[j.start:j.end])
+				if err == nil && len(embeddings) != j.end-j.start {
+					err = fmt.Errorf("Embedding数が入力と一致しません (入力: %d, 出力: %d)", j.end-j.start, len(embeddings))
+				}
+				if err != nil {
+					fail(j, err)
+					continue
+				}
+				// 各ワーカーは重ならない範囲にのみ書き込むためロックは不要
+				copy(results[j.start:j.end], embeddings)
+			}
+		}()
+	}
+
+	for start := 0; start < len(texts); start += batchSize {
+		jobs <- job{start: start, end: min(start+batchSize, len(texts))}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		slices.Sort(failed)
+		return results, &EmbeddingsError{FailedIndices: failed, Err: firstErr}
+	}
+	return results, nil
+}
@@ -0,0 +1,118 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbedInBatches(t *testing.T) {
+	ctx := context.Background()
+	texts := []string{"a", "bb", "ccc", "dddd", "eeeee"}
+
+	// テキスト長を値とするEmbeddingを返す
+	lengthEmbed := func(ctx context.Context, batch []string) ([][]float32, error) {
+		out := make([][]float32, len(batch))
+		for i, text := range batch {
+			out[i] = []float32{float32(len(text))}
+		}
+		return out, nil
+	}
+
+	t.Run("入力と同じ順序で結果を返す", func(t *testing.T) {
+		for _, batchSize := range []int{1, 2, 10} {
+			results, err := embedInBatches(ctx, texts, batchSize, 3, lengthEmbed)
+
+			require.NoError(t, err)
+			require.Len(t, results, len(texts))
+			for i, text := range texts {
+				assert.Equal(t, []float32{float32(len(text))}, results[i])
+			}
+		}
+	})
+
+	t.Run("同時実行数を上限以内に抑える", func(t *testing.T) {
+		var running, peak int32
+		embed := func(ctx context.Context, batch []string) ([][]float32, error) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return lengthEmbed(ctx, batch)
+		}
+
+		_, err := embedInBatches(ctx, texts, 1, 2, embed)
+
+		require.NoError(t, err)
+		assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+	})
+
+	t.Run("一部のバッチが失敗した場合は成功分と失敗位置を返す", func(t *testing.T) {
+		embedErr := errors.New("embed failed")
+		embed := func(ctx context.Context, batch []string) ([][]float32, error) {
+			if batch[0] == "ccc" {
+				return nil, embedErr
+			}
+			return lengthEmbed(ctx, batch)
+		}
+
+		results, err := embedInBatches(ctx, texts, 2, 2, embed)
+
+		var embErr *EmbeddingsError
+		require.True(t, errors.As(err, &embErr))
+		assert.Equal(t, []int{2, 3}, embErr.FailedIndices)
+		assert.ErrorIs(t, err, embedErr)
+		assert.Equal(t, []float32{1}, results[0])
+		assert.Equal(t, []float32{2}, results[1])
+		assert.Nil(t, results[2])
+		assert.Nil(t, results[3])
+		assert.Equal(t, []float32{5}, results[4])
+	})
+
+	t.Run("結果の件数が入力と異なる場合は失敗として扱う", func(t *testing.T) {
+		embed := func(ctx context.Context, batch []string) ([][]float32, error) {
+			return [][]float32{{0}}, nil
+		}
+
+		_, err := embedInBatches(ctx, texts[:2], 2, 1, embed)
+
+		var embErr *EmbeddingsError
+		require.True(t, errors.As(err, &embErr))
+		assert.Equal(t, []int{0, 1}, embErr.FailedIndices)
+	})
+
+	t.Run("キャンセル後のバッチは呼び出さない", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		var calls int32
+		embed := func(ctx context.Context, batch []string) ([][]float32, error) {
+			atomic.AddInt32(&calls, 1)
+			return lengthEmbed(ctx, batch)
+		}
+
+		_, err := embedInBatches(cancelCtx, texts, 1, 2, embed)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("空の入力では呼び出さない", func(t *testing.T) {
+		results, err := embedInBatches(ctx, nil, 1, 2, func(ctx context.Context, batch []string) ([][]float32, error) {
+			t.Fatal("呼び出されないはず")
+			return nil, nil
+		})
+
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
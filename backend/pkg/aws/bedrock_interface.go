@@ -8,7 +8,13 @@ import (
 type BedrockClientInterface interface {
 	GenerateSummary(ctx context.Context, text string) (string, error)
 	GenerateText(ctx context.Context, prompt string) (string, error)
+	Converse(ctx context.Context, req ConverseRequest) (*ConverseResponse, error)
+	TextModel() TextModel
+	WithTextModel(model TextModel) BedrockClientInterface
 	GenerateEmbedding(ctx context.Context, text string) ([]float32, error)
+	GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error)
+	EmbeddingModel() EmbeddingModel
+	WithEmbeddingModel(model EmbeddingModel) BedrockClientInterface
 	// その他のBedrock関連メソッドをここに追加
 }
 
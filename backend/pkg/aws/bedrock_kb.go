@@ -25,8 +25,14 @@ type BedrockKBClient struct {
 	runtimeClient      *bedrockruntime.Client
 	agentRuntimeClient *bedrockagentruntime.Client
 	region             string
-	kbId               string // Knowledge Base ID
-	modelId            string // 使用するモデルID
+	kbId               string    // Knowledge Base ID
+	modelId            string    // 使用するモデルID
+	textModel          TextModel // modelId に対応するテキスト生成モデルの定義
+	limiter            *ModelConcurrencyLimiter
+	resilience         *Resilience
+	usage              UsageRecorder // nil の場合は使用量を記録しない
+	guard              UsageGuard    // nil の場合は利用上限を確認しない
+	guardrail          *Guardrail    // nil の場合はガードレールを適用しない
 }
 
 // NewBedrockKBClient は新しいBedrockKBClientを作成する
@@ -37,8 +43,10 @@ func NewBedrockKBClient(cfg *config.Config) (*BedrockKBClient, error) {
 	}
 
 	agentClient := bedrockagent.NewFromConfig(awsCfg)
-	runtimeClient := bedrockruntime.NewFromConfig(awsCfg)
-	agentRuntimeClient := bedrockagentruntime.NewFromConfig(awsCfg)
+	runtimeClient := bedrockruntime.NewFromConfig(awsCfg, withoutSDKRetry)
+	agentRuntimeClient := bedrockagentruntime.NewFromConfig(awsCfg, func(o *bedrockagentruntime.Options) {
+		o.RetryMaxAttempts = 1 // 再試行は callWithResilience で行う
+	})
 
 	// 環境変数からKnowledge Base IDを取得
 	kbId := os.Getenv("BEDROCK_KB_ID")
@@ -48,6 +56,10 @@ func NewBedrockKBClient(cfg *config.Config) (*BedrockKBClient, error) {
 
 	// デフォルトでClaude 3 Haikuを使用
 	modelId := getEnvOrDefault("BEDROCK_MODEL_ID", "anthropic.claude-3-haiku-20240307-v1:0")
+	textModel, err := LookupTextModel(modelId)
+	if err != nil {
+		return nil, err
+	}
 
 	return &BedrockKBClient{
 		agentClient:        agentClient,
@@ -55,23 +67,23 @@ func NewBedrockKBClient(cfg *config.Config) (*BedrockKBClient, error) {
 		agentRuntimeClient: agentRuntimeClient,
 		region:             cfg.AWS.Region,
 		kbId:               kbId,
-		modelId:            modelId,
+		modelId:            textModel.ModelID,
+		textModel:          textModel,
+		limiter:            sharedModelLimiter(cfg),
+		resilience:         sharedResilienceFor(cfg),
+		guardrail:          NewGuardrail(cfg),
 	}, nil
 }
 
-// ClaudeRAGInput はClaude RAGリクエストの入力形式
-type ClaudeRAGInput struct {
-	Prompt            string  `json:"prompt"`
-	MaxTokensToSample int     `json:"max_tokens_to_sample"`
-	Temperature       float64 `json:"temperature"`
-	TopP              float64 `json:"top_p"`
-	TopK              int     `json:"top_k"`
+// SetUsageRecorder はモデル呼び出しの使用量の記録先を設定する
+func (b *BedrockKBClient) SetUsageRecorder(recorder UsageRecorder) {
+	b.usage = recorder
 }
 
-// ClaudeOutput はClaude出力形式
-// type ClaudeOutput struct {
-// 	Completion string `json:"completion"`
-// }
+// SetUsageGuard はモデルを呼び出す前に利用上限を確認する guard を設定する
+func (b *BedrockKBClient) SetUsageGuard(guard UsageGuard) {
+	b.guard = guard
+}
 
 // RAGRetrieveResult はRetrieveオペレーションの結果
 type RAGRetrieveResult struct {
@@ -91,16 +103,18 @@ type RetrievedReference struct {
 // RetrieveFromKB はKnowledge Baseからクエリに関連するドキュメントを検索する
 func (b *BedrockKBClient) RetrieveFromKB(ctx context.Context, query string) (*RAGRetrieveResult, error) {
 	// agentRuntime.Retrieve APIを呼び出す
-	resp, err := b.agentRuntimeClient.Retrieve(ctx, &bedrockagentruntime.RetrieveInput{
-		KnowledgeBaseId: aws.String(b.kbId),
-		RetrievalQuery: &types.KnowledgeBaseQuery{ // ← types.RetrievalQuery → types.KnowledgeBaseQuery
-			Text: aws.String(query),
-		},
-		RetrievalConfiguration: &types.KnowledgeBaseRetrievalConfiguration{
-			VectorSearchConfiguration: &types.KnowledgeBaseVectorSearchConfiguration{
-				NumberOfResults: aws.Int32(5),
+	resp, err := callWithResilience(ctx, b.resilience, "knowledge-base:"+b.kbId, func(ctx context.Context) (*bedrockagentruntime.RetrieveOutput, error) {
+		return b.agentRuntimeClient.Retrieve(ctx, &bedrockagentruntime.RetrieveInput{
+			KnowledgeBaseId: aws.String(b.kbId),
+			RetrievalQuery: &types.KnowledgeBaseQuery{ // ← types.RetrievalQuery → types.KnowledgeBaseQuery
+				Text: aws.String(query),
 			},
-		},
+			RetrievalConfiguration: &types.KnowledgeBaseRetrievalConfiguration{
+				VectorSearchConfiguration: &types.KnowledgeBaseVectorSearchConfiguration{
+					NumberOfResults: aws.Int32(5),
+				},
+			},
+		})
 	})
 
 	if err != nil {
@@ -146,9 +160,6 @@ func (b *BedrockKBClient) RetrieveFromKB(ctx context.Context, query string) (*RA
 
 // RAGQueryWithKB はKnowledge Baseを使用したRAGベースのクエリを実行する
 func (b *BedrockKBClient) RAGQueryWithKB(ctx context.Context, query string, references []RetrievedReference) (string, error) {
-	// Claude 3 Haiku モデルID
-	modelID := b.modelId
-
 	// 参考情報をプロンプトに組み込む
 	var sb strings.Builder
 	sb.WriteString("以下は関連するドキュメントからの情報です：\n\n")
@@ -157,49 +168,32 @@ func (b *BedrockKBClient) RAGQueryWithKB(ctx context.Context, query string, refe
 		sb.WriteString(fmt.Sprintf("文書[%d]: %s\n", i+1, ref.Content))
 	}
 
-	// 入力プロンプトの作成
-	prompt := fmt.Sprintf(`<human>%s
+	prompt := fmt.Sprintf(`%s
 
-質問: %s</human>
+質問: %s`, sb.String(), query)
 
-<assistant>`, sb.String(), query)
-
-	input := ClaudeRAGInput{
-		Prompt:            prompt,
-		MaxTokensToSample: 2048,
-		Temperature:       0.7,
-		TopP:              0.9,
-		TopK:              250,
+	if err := checkUsage(ctx, b.guard); err != nil {
+		return "", err
 	}
-
-	// リクエストボディの作成
-	inputBytes, err := json.Marshal(input)
-	if err != nil {
-		return "", fmt.Errorf("入力JSONの作成に失敗しました: %w", err)
-	}
-
-	// Bedrockにリクエスト
-	response, err := b.runtimeClient.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
-		ModelId:     aws.String(modelID),
-		Body:        inputBytes,
-		ContentType: aws.String("application/json"),
+	response, err := converse(ctx, b.runtimeClient, b.limiter, b.resilience, b.guardrail, b.textModel, ConverseRequest{
+		Messages: []ConverseMessage{UserMessage(prompt)},
 	})
-
-	if err != nil {
-		return "", fmt.Errorf("bedrockの呼び出しに失敗しました: %w", err)
+	if response != nil {
+		recordUsage(ctx, b.usage, response.usageEvent(b.textModel))
 	}
-
-	// レスポンスの解析
-	var output ClaudeOutput // このClaudeOutputはbedrock.goで定義されている想定
-	if err := json.Unmarshal(response.Body, &output); err != nil {
-		return "", fmt.Errorf("レスポンスの解析に失敗しました: %w", err)
+	if err != nil {
+		return "", err
 	}
 
-	return strings.TrimSpace(output.Completion), nil
+	return strings.TrimSpace(response.Text()), nil
 }
 
 // RAGQueryWithRetrieveAndGenerate はBedrockのRetrieveAndGenerate APIを使用してKnowledge Baseに基づく回答を生成する
 func (b *BedrockKBClient) RAGQueryWithRetrieveAndGenerate(ctx context.Context, query string) (string, error) {
+	if err := checkUsage(ctx, b.guard); err != nil {
+		return "", err
+	}
+
 	// RetrieveAndGenerate APIの呼び出し
 	input := &bedrockagentruntime.RetrieveAndGenerateInput{
 		Input: &types.RetrieveAndGenerateInput{
@@ -214,6 +208,7 @@ func (b *BedrockKBClient) RAGQueryWithRetrieveAndGenerate(ctx context.Context, q
 					PromptTemplate: &types.PromptTemplate{
 						TextPromptTemplate: aws.String("以下の質問に日本語で答えてください。\n質問: ${question}"),
 					},
+					GuardrailConfiguration: b.guardrail.knowledgeBaseConfig(),
 					InferenceConfig: &types.InferenceConfig{
 						TextInferenceConfig: &types.TextInferenceConfig{
 							MaxTokens:   aws.Int32(2048),
@@ -226,11 +221,30 @@ func (b *BedrockKBClient) RAGQueryWithRetrieveAndGenerate(ctx context.Context, q
 		},
 	}
 
-	resp, err := b.agentRuntimeClient.RetrieveAndGenerate(ctx, input)
+	resp, err := callWithResilience(ctx, b.resilience, b.modelId, func(ctx context.Context) (*bedrockagentruntime.RetrieveAndGenerateOutput, error) {
+		// RetrieveAndGenerate も内部で同じモデルを呼び出すため、同時実行数の制限に含める
+		release, err := b.limiter.Acquire(ctx, b.modelId)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		return b.agentRuntimeClient.RetrieveAndGenerate(ctx, input)
+	})
 	if err != nil {
 		return "", fmt.Errorf("retrieveAndGenerateの呼び出しに失敗しました: %w", err)
 	}
 
+	// RetrieveAndGenerate のレスポンスにはトークン数が含まれないため、呼び出し回数のみ記録する
+	recordUsage(ctx, b.usage, UsageEvent{
+		Model:     b.textModel.Name,
+		ModelID:   b.textModel.ModelID,
+		Operation: UsageOperationRetrieveAndGenerate,
+	})
+
+	if resp.GuardrailAction == types.GuadrailActionIntervened {
+		return "", retrieveAndGenerateBlockedError(b.textModel, resp)
+	}
 	if resp.Output == nil || resp.Output.Text == nil {
 		return "", fmt.Errorf("回答の生成に失敗しました: 出力がありません")
 	}
@@ -238,6 +252,16 @@ func (b *BedrockKBClient) RAGQueryWithRetrieveAndGenerate(ctx context.Context, q
 	return *resp.Output.Text, nil
 }
 
+// retrieveAndGenerateBlockedError はガードレールが介入したRetrieveAndGenerateの結果をエラーに変換する
+// RetrieveAndGenerate はトレースを返さず匿名化のみの介入と区別できないため、遮断したものとして扱う
+func retrieveAndGenerateBlockedError(model TextModel, resp *bedrockagentruntime.RetrieveAndGenerateOutput) error {
+	outcome := GuardrailOutcome{Blocked: true}
+	if resp.Output != nil {
+		outcome.Message = strings.TrimSpace(aws.ToString(resp.Output.Text))
+	}
+	return &ContentBlockedError{Model: model.Name, Outcome: outcome}
+}
+
 // getEnvOrDefault は環境変数から値を取得し、存在しなければデフォルト値を返す
 func getEnvOrDefault(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -0,0 +1,111 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+)
+
+// ErrModelBusy はモデルの同時実行枠が待機時間内に空かなかった場合のエラー
+var ErrModelBusy = errors.New("bedrockモデルの同時実行数が上限に達しています")
+
+// ModelConcurrencyLimiter はモデルIDごとに InvokeModel の同時実行数を制限するセマフォ
+// アカウントのクォータを超えて ThrottlingException が発生する前に、アプリケーション側で待機させる
+type ModelConcurrencyLimiter struct {
+	defaultLimit int
+	overrides    map[string]int
+	waitTimeout  time.Duration
+
+	mu         sync.Mutex
+	semaphores map[string]chan struct{}
+}
+
+// NewModelConcurrencyLimiter は新しいModelConcurrencyLimiterを作成する
+// defaultLimit が0以下のモデルは無制限となる
+func NewModelConcurrencyLimiter(defaultLimit int, overrides map[string]int, waitTimeout time.Duration) *ModelConcurrencyLimiter {
+	return &ModelConcurrencyLimiter{
+		defaultLimit: defaultLimit,
+		overrides:    overrides,
+		waitTimeout:  waitTimeout,
+		semaphores:   make(map[string]chan struct{}),
+	}
+}
+
+var (
+	sharedLimiterOnce sync.Once
+	sharedLimiter     *ModelConcurrencyLimiter
+)
+
+// sharedModelLimiter はプロセス全体で共有するリミッターを返す
+// BedrockClient と BedrockKBClient が同じモデルを呼び出しても合計で上限を超えないようにする
+func sharedModelLimiter(cfg *config.Config) *ModelConcurrencyLimiter {
+	sharedLimiterOnce.Do(func() {
+		sharedLimiter = NewModelConcurrencyLimiter(
+			cfg.AWS.BedrockMaxConcurrency,
+			cfg.AWS.BedrockModelConcurrency,
+			cfg.AWS.BedrockConcurrencyWait,
+		)
+	})
+	return sharedLimiter
+}
+
+// Acquire はモデルの実行枠を取得する。取得できた場合は解放用の関数を返す
+// waitTimeout 以内に枠が空かない場合は ErrModelBusy を返す
+func (l *ModelConcurrencyLimiter) Acquire(ctx context.Context, modelID string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	sem := l.semaphore(modelID)
+	if sem == nil {
+		return func() {}, nil
+	}
+
+	// 空きがあれば待たずに取得する
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	default:
+	}
+
+	waitCtx := ctx
+	if l.waitTimeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, l.waitTimeout)
+		defer cancel()
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-waitCtx.Done():
+		// 呼び出し元のキャンセルはそのまま返し、待機時間の超過のみ ErrModelBusy とする
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w (model: %s)", ErrModelBusy, modelID)
+	}
+}
+
+// semaphore はモデルIDに対応するセマフォを返す (無制限の場合はnil)
+func (l *ModelConcurrencyLimiter) semaphore(modelID string) chan struct{} {
+	limit := l.defaultLimit
+	if override, ok := l.overrides[modelID]; ok {
+		limit = override
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sem, ok := l.semaphores[modelID]
+	if !ok {
+		sem = make(chan struct{}, limit)
+		l.semaphores[modelID] = sem
+	}
+	return sem
+}
@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelConcurrencyLimiter_Acquire(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("上限までは即座に取得できる", func(t *testing.T) {
+		l := NewModelConcurrencyLimiter(2, nil, 10*time.Millisecond)
+
+		release1, err := l.Acquire(ctx, "model-a")
+		require.NoError(t, err)
+		release2, err := l.Acquire(ctx, "model-a")
+		require.NoError(t, err)
+
+		_, err = l.Acquire(ctx, "model-a")
+		assert.True(t, errors.Is(err, ErrModelBusy))
+
+		// 別モデルの枠は独立している
+		release3, err := l.Acquire(ctx, "model-b")
+		require.NoError(t, err)
+
+		release1()
+		release4, err := l.Acquire(ctx, "model-a")
+		assert.NoError(t, err)
+
+		release2()
+		release3()
+		release4()
+	})
+
+	t.Run("解放を待って取得できる", func(t *testing.T) {
+		l := NewModelConcurrencyLimiter(1, nil, time.Second)
+		release, err := l.Acquire(ctx, "model-a")
+		require.NoError(t, err)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			release()
+		}()
+
+		release2, err := l.Acquire(ctx, "model-a")
+		require.NoError(t, err)
+		release2()
+	})
+
+	t.Run("モデルごとの上書き設定と無制限", func(t *testing.T) {
+		l := NewModelConcurrencyLimiter(0, map[string]int{"model-a": 1}, 10*time.Millisecond)
+
+		release, err := l.Acquire(ctx, "model-a")
+		require.NoError(t, err)
+		_, err = l.Acquire(ctx, "model-a")
+		assert.True(t, errors.Is(err, ErrModelBusy))
+		release()
+
+		// 上限0のモデルは無制限
+		for i := 0; i < 10; i++ {
+			_, err := l.Acquire(ctx, "model-b")
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("呼び出し元のキャンセルはそのまま返す", func(t *testing.T) {
+		l := NewModelConcurrencyLimiter(1, nil, time.Second)
+		release, err := l.Acquire(ctx, "model-a")
+		require.NoError(t, err)
+		defer release()
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = l.Acquire(cancelled, "model-a")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"context"
+)
+
+// EmbeddingCache は同じテキストのEmbeddingを再利用するキャッシュ
+// key はモデルに入力の種類と正規化の有無を加えた識別子で、同じ key とテキストからは同じEmbeddingが得られる
+// キャッシュの障害でEmbeddingの生成を止めないよう、エラーは実装側で記録し、見つからなかったものとして扱う
+type EmbeddingCache interface {
+	// GetEmbeddings は texts と同じ順序でキャッシュ済みのEmbeddingを返す (見つからない位置は nil)
+	GetEmbeddings(ctx context.Context, key string, texts []string) [][]float32
+	PutEmbeddings(ctx context.Context, key string, texts []string, embeddings [][]float32)
+}
+
+// SetEmbeddingCache はEmbeddingのキャッシュを設定する (nil の場合はキャッシュしない)
+// WithTextModel / WithEmbeddingModel で作成したクライアントにも引き継がれる
+func (b *BedrockClient) SetEmbeddingCache(cache EmbeddingCache) {
+	b.embeddingCache = cache
+}
+
+// embeddingCacheKey はEmbeddingをキャッシュする単位の識別子を返す
+// Cohere Embed のように検索クエリと文書で結果が異なるモデルがあるため、入力の種類も含める
+func embeddingCacheKey(model EmbeddingModel, purpose string, normalize bool) string {
+	key := model.Name + "/" + purpose
+	if normalize {
+		key += "/normalized"
+	}
+	return key
+}
+
+// embedWithCache はキャッシュにないテキストのみ embed でEmbeddingを生成し、結果をキャッシュに保存する
+// 次元数がモデルの定義と異なるキャッシュは使用しない
+func embedWithCache(ctx context.Context, cache EmbeddingCache, key string, dimension int, texts []string, embed func(ctx context.Context, texts []string) ([][]float32, error)) ([][]float32, error) {
+	results := cache.GetEmbeddings(ctx, key, texts)
+	if len(results) != len(texts) {
+		results = make([][]float32, len(texts))
+	}
+
+	var missing []string
+	var missingIdx []int
+	for i, embedding := range results {
+		if len(embedding) != dimension {
+			results[i] = nil
+			missing = append(missing, texts[i])
+			missingIdx = append(missingIdx, i)
+		}
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	embeddings, err := embed(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range missingIdx {
+		results[i] = embeddings[j]
+	}
+	cache.PutEmbeddings(ctx, key, missing, embeddings)
+	return results, nil
+}
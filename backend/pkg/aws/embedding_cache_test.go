@@ -0,0 +1,162 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapEmbeddingCache はテスト用のメモリ上の EmbeddingCache
+type mapEmbeddingCache map[string][]float32
+
+func (c mapEmbeddingCache) GetEmbeddings(_ context.Context, key string, texts []string) [][]float32 {
+	results := make([][]float32, len(texts))
+	for i, text := range texts {
+		results[i] = c[key+"|"+text]
+	}
+	return results
+}
+
+func (c mapEmbeddingCache) PutEmbeddings(_ context.Context, key string, texts []string, embeddings [][]float32) {
+	for i, text := range texts {
+		c[key+"|"+text] = embeddings[i]
+	}
+}
+
+func TestEmbeddingCacheKey(t *testing.T) {
+	titan := EmbeddingModel{Name: "titan-v2-256"}
+
+	assert.Equal(t, "titan-v2-256/document", embeddingCacheKey(titan, embeddingPurposeDocument, false))
+	assert.Equal(t, "titan-v2-256/query/normalized", embeddingCacheKey(titan, embeddingPurposeQuery, true))
+}
+
+func TestEmbedWithCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("キャッシュにないテキストのみ生成して保存する", func(t *testing.T) {
+		cache := mapEmbeddingCache{"k|a": {1, 1}, "k|c": {3, 3}}
+		var embedded []string
+		embed := func(_ context.Context, texts []string) ([][]float32, error) {
+			embedded = append(embedded, texts...)
+			out := make([][]float32, len(texts))
+			for i := range texts {
+				out[i] = []float32{9, 9}
+			}
+			return out, nil
+		}
+
+		results, err := embedWithCache(ctx, cache, "k", 2, []string{"a", "b", "c", "d"}, embed)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b", "d"}, embedded)
+		assert.Equal(t, [][]float32{{1, 1}, {9, 9}, {3, 3}, {9, 9}}, results)
+		assert.Equal(t, []float32{9, 9}, cache["k|b"])
+		assert.Equal(t, []float32{9, 9}, cache["k|d"])
+	})
+
+	t.Run("すべてキャッシュにある場合はモデルを呼び出さない", func(t *testing.T) {
+		cache := mapEmbeddingCache{"k|a": {1, 1}}
+		embed := func(context.Context, []string) ([][]float32, error) {
+			t.Fatal("embed should not be called")
+			return nil, nil
+		}
+
+		results, err := embedWithCache(ctx, cache, "k", 2, []string{"a"}, embed)
+
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{{1, 1}}, results)
+	})
+
+	t.Run("次元数が異なるキャッシュは使わない", func(t *testing.T) {
+		cache := mapEmbeddingCache{"k|a": {1, 1, 1}}
+		embed := func(_ context.Context, texts []string) ([][]float32, error) {
+			return [][]float32{{2, 2}}, nil
+		}
+
+		results, err := embedWithCache(ctx, cache, "k", 2, []string{"a"}, embed)
+
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{{2, 2}}, results)
+	})
+
+	t.Run("異常系_生成に失敗した場合は保存しない", func(t *testing.T) {
+		cache := mapEmbeddingCache{}
+		embedErr := errors.New("throttled")
+		embed := func(context.Context, []string) ([][]float32, error) {
+			return nil, embedErr
+		}
+
+		_, err := embedWithCache(ctx, cache, "k", 2, []string{"a"}, embed)
+
+		assert.ErrorIs(t, err, embedErr)
+		assert.Empty(t, cache)
+	})
+}
+
+func TestCheckModelAccess(t *testing.T) {
+	t.Run("キャッシュが設定されていても毎回モデルを呼び出す", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"embedding":[0.1,0.2],"inputTextTokenCount":1}`))
+		}))
+		defer server.Close()
+
+		model, err := LookupEmbeddingModel("titan-v1")
+		require.NoError(t, err)
+		model.Dimension = 2
+		cache := mapEmbeddingCache{}
+		client := &BedrockClient{
+			client: bedrockruntime.New(bedrockruntime.Options{
+				Region:       "us-east-1",
+				BaseEndpoint: aws.String(server.URL),
+				Credentials:  aws.AnonymousCredentials{},
+			}),
+			embeddingModel: model,
+			embeddingCache: cache,
+		}
+
+		require.NoError(t, client.CheckModelAccess(context.Background()))
+		require.NoError(t, client.CheckModelAccess(context.Background()))
+
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Empty(t, cache, "疎通確認の結果はキャッシュしない")
+	})
+
+	t.Run("モデルを呼び出せない場合はエラーを返す", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Amzn-ErrorType", "AccessDeniedException")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"You don't have access to the model"}`))
+		}))
+		defer server.Close()
+
+		model, err := LookupEmbeddingModel("titan-v1")
+		require.NoError(t, err)
+		// 以前の疎通確認の結果がキャッシュに残っていても、失敗を検知する
+		cache := mapEmbeddingCache{embeddingCacheKey(model, embeddingPurposeQuery, false) + "|ping": make([]float32, model.Dimension)}
+		client := &BedrockClient{
+			client: bedrockruntime.New(bedrockruntime.Options{
+				Region:           "us-east-1",
+				BaseEndpoint:     aws.String(server.URL),
+				Credentials:      aws.AnonymousCredentials{},
+				RetryMaxAttempts: 1,
+			}),
+			embeddingModel: model,
+			embeddingCache: cache,
+		}
+
+		err = client.CheckModelAccess(context.Background())
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,175 @@
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// ErrUnknownEmbeddingModel はレジストリに登録されていないEmbeddingモデルが指定された場合のエラー
+var ErrUnknownEmbeddingModel = errors.New("未対応のEmbeddingモデルです")
+
+// Embeddingの用途 (Cohereなど、クエリと文書で入力形式を区別するモデルで使用する)
+const (
+	embeddingPurposeQuery    = "query"
+	embeddingPurposeDocument = "document"
+)
+
+// EmbeddingModel はEmbeddingモデルの定義
+// 同じモデルIDでも出力次元数が異なれば別のベクトル空間となるため、Name で区別する
+type EmbeddingModel struct {
+	Name         string // レジストリ名 (チャンクと一緒に保存する識別子)
+	ModelID      string // BedrockのモデルID
+	Dimension    int    // 出力ベクトルの次元数
+	MaxBatchSize int    // 1リクエストで送信できるテキスト数
+	codec        embeddingCodec
+}
+
+// embeddingCodec はモデルごとのリクエスト/レスポンス形式
+type embeddingCodec interface {
+	encode(model EmbeddingModel, texts []string, purpose string, normalize bool) ([]byte, error)
+	decode(body []byte) ([][]float32, error)
+}
+
+// embeddingModels は利用可能なEmbeddingモデルの一覧
+var embeddingModels = map[string]EmbeddingModel{
+	"titan-v1": {
+		Name: "titan-v1", ModelID: "amazon.titan-embed-text-v1", Dimension: 1536, MaxBatchSize: 1,
+		codec: titanV1Codec{},
+	},
+	"titan-v2-256": {
+		Name: "titan-v2-256", ModelID: "amazon.titan-embed-text-v2:0", Dimension: 256, MaxBatchSize: 1,
+		codec: titanV2Codec{},
+	},
+	"titan-v2-512": {
+		Name: "titan-v2-512", ModelID: "amazon.titan-embed-text-v2:0", Dimension: 512, MaxBatchSize: 1,
+		codec: titanV2Codec{},
+	},
+	"titan-v2-1024": {
+		Name: "titan-v2-1024", ModelID: "amazon.titan-embed-text-v2:0", Dimension: 1024, MaxBatchSize: 1,
+		codec: titanV2Codec{},
+	},
+	"cohere-multilingual-v3": {
+		Name: "cohere-multilingual-v3", ModelID: "cohere.embed-multilingual-v3", Dimension: 1024, MaxBatchSize: 96,
+		codec: cohereCodec{},
+	},
+}
+
+// LookupEmbeddingModel はレジストリ名からEmbeddingモデルの定義を取得する
+func LookupEmbeddingModel(name string) (EmbeddingModel, error) {
+	model, ok := embeddingModels[name]
+	if !ok {
+		return EmbeddingModel{}, fmt.Errorf("%w: %s (利用可能: %s)", ErrUnknownEmbeddingModel, name, strings.Join(EmbeddingModelNames(), ", "))
+	}
+	return model, nil
+}
+
+// EmbeddingModelNames は利用可能なEmbeddingモデルのレジストリ名を返す
+func EmbeddingModelNames() []string {
+	names := make([]string, 0, len(embeddingModels))
+	for name := range embeddingModels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// decodeEmbeddings はレスポンスを解析し、件数と次元数を検証したうえで必要に応じて正規化する
+func (m EmbeddingModel) decodeEmbeddings(body []byte, n int, normalize bool) ([][]float32, error) {
+	embeddings, err := m.codec.decode(body)
+	if err != nil {
+		return nil, fmt.Errorf("レスポンスの解析に失敗しました: %w", err)
+	}
+	if len(embeddings) != n {
+		return nil, fmt.Errorf("レスポンスのEmbedding数が入力と一致しません (入力: %d, 出力: %d)", n, len(embeddings))
+	}
+	for i, embedding := range embeddings {
+		if len(embedding) != m.Dimension {
+			return nil, fmt.Errorf("Embeddingの次元数が %s の定義と一致しません (期待: %d, 実際: %d)", m.Name, m.Dimension, len(embedding))
+		}
+		if normalize {
+			embeddings[i] = normalizeL2(embedding)
+		}
+	}
+	return embeddings, nil
+}
+
+// normalizeL2 はベクトルをL2ノルムが1になるように正規化する (ゼロベクトルはそのまま返す)
+func normalizeL2(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := math.Sqrt(sum)
+	out := make([]float32, len(v))
+	for i, x := range v {
+		out[i] = float32(float64(x) / norm)
+	}
+	return out
+}
+
+// titanV1Codec はAmazon Titan Embeddings G1 - Text の入出力形式
+type titanV1Codec struct{}
+
+func (titanV1Codec) encode(_ EmbeddingModel, texts []string, _ string, _ bool) ([]byte, error) {
+	return json.Marshal(TitanEmbeddingInput{InputText: texts[0]})
+}
+
+func (titanV1Codec) decode(body []byte) ([][]float32, error) {
+	var output TitanEmbeddingOutput
+	if err := json.Unmarshal(body, &output); err != nil {
+		return nil, err
+	}
+	return [][]float32{output.Embedding}, nil
+}
+
+// TitanV2EmbeddingInput はAmazon Titan Text Embeddings V2 への入力形式
+type TitanV2EmbeddingInput struct {
+	InputText  string `json:"inputText"`
+	Dimensions int    `json:"dimensions"`
+	Normalize  bool   `json:"normalize"`
+}
+
+// titanV2Codec はAmazon Titan Text Embeddings V2 の入出力形式 (次元数と正規化をリクエストで指定する)
+type titanV2Codec struct{}
+
+func (titanV2Codec) encode(model EmbeddingModel, texts []string, _ string, normalize bool) ([]byte, error) {
+	return json.Marshal(TitanV2EmbeddingInput{
+		InputText:  texts[0],
+		Dimensions: model.Dimension,
+		Normalize:  normalize,
+	})
+}
+
+func (titanV2Codec) decode(body []byte) ([][]float32, error) {
+	return titanV1Codec{}.decode(body)
+}
+
+// cohereCodec はCohere Embedの入出力形式 (複数テキストを1リクエストで送信できる)
+type cohereCodec struct{}
+
+func (cohereCodec) encode(_ EmbeddingModel, texts []string, purpose string, _ bool) ([]byte, error) {
+	inputType := cohereInputTypeDocument
+	if purpose == embeddingPurposeQuery {
+		inputType = cohereInputTypeQuery
+	}
+	return json.Marshal(CohereEmbeddingInput{
+		Texts:     texts,
+		InputType: inputType,
+		Truncate:  "END",
+	})
+}
+
+func (cohereCodec) decode(body []byte) ([][]float32, error) {
+	var output CohereEmbeddingOutput
+	if err := json.Unmarshal(body, &output); err != nil {
+		return nil, err
+	}
+	return output.Embeddings, nil
+}
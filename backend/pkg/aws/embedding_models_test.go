@@ -0,0 +1,104 @@
+package aws
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupEmbeddingModel(t *testing.T) {
+	t.Run("登録済みのモデルを取得できる", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			modelID   string
+			dimension int
+		}{
+			{"titan-v1", "amazon.titan-embed-text-v1", 1536},
+			{"titan-v2-256", "amazon.titan-embed-text-v2:0", 256},
+			{"titan-v2-512", "amazon.titan-embed-text-v2:0", 512},
+			{"titan-v2-1024", "amazon.titan-embed-text-v2:0", 1024},
+			{"cohere-multilingual-v3", "cohere.embed-multilingual-v3", 1024},
+		}
+		for _, tt := range tests {
+			model, err := LookupEmbeddingModel(tt.name)
+			require.NoError(t, err)
+			assert.Equal(t, tt.modelID, model.ModelID)
+			assert.Equal(t, tt.dimension, model.Dimension)
+		}
+	})
+
+	t.Run("未登録のモデルはエラー", func(t *testing.T) {
+		_, err := LookupEmbeddingModel("unknown")
+		assert.ErrorIs(t, err, ErrUnknownEmbeddingModel)
+	})
+}
+
+func TestEmbeddingCodecs(t *testing.T) {
+	t.Run("Titan v2 は次元数と正規化をリクエストで指定する", func(t *testing.T) {
+		model, _ := LookupEmbeddingModel("titan-v2-256")
+
+		body, err := model.codec.encode(model, []string{"テキスト"}, embeddingPurposeDocument, true)
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"inputText":"テキスト","dimensions":256,"normalize":true}`, string(body))
+	})
+
+	t.Run("Cohere はクエリと文書で input_type を切り替える", func(t *testing.T) {
+		model, _ := LookupEmbeddingModel("cohere-multilingual-v3")
+
+		queryBody, err := model.codec.encode(model, []string{"質問"}, embeddingPurposeQuery, false)
+		require.NoError(t, err)
+		docBody, err := model.codec.encode(model, []string{"a", "b"}, embeddingPurposeDocument, false)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"texts":["質問"],"input_type":"search_query","truncate":"END"}`, string(queryBody))
+		assert.JSONEq(t, `{"texts":["a","b"],"input_type":"search_document","truncate":"END"}`, string(docBody))
+	})
+
+	t.Run("次元数が定義と異なるレスポンスはエラー", func(t *testing.T) {
+		model := EmbeddingModel{Name: "test", Dimension: 3, codec: titanV1Codec{}}
+		body, _ := json.Marshal(TitanEmbeddingOutput{Embedding: []float32{1, 2}})
+
+		_, err := model.decodeEmbeddings(body, 1, false)
+
+		assert.ErrorContains(t, err, "次元数")
+	})
+
+	t.Run("件数が入力と異なるレスポンスはエラー", func(t *testing.T) {
+		model := EmbeddingModel{Name: "test", Dimension: 1, codec: cohereCodec{}}
+		body, _ := json.Marshal(CohereEmbeddingOutput{Embeddings: [][]float32{{1}}})
+
+		_, err := model.decodeEmbeddings(body, 2, false)
+
+		assert.ErrorContains(t, err, "Embedding数")
+	})
+
+	t.Run("正規化を指定した場合はL2ノルムが1になる", func(t *testing.T) {
+		model := EmbeddingModel{Name: "test", Dimension: 2, codec: titanV1Codec{}}
+		body, _ := json.Marshal(TitanEmbeddingOutput{Embedding: []float32{3, 4}})
+
+		embeddings, err := model.decodeEmbeddings(body, 1, true)
+
+		require.NoError(t, err)
+		assert.InDelta(t, 0.6, embeddings[0][0], 1e-6)
+		assert.InDelta(t, 0.8, embeddings[0][1], 1e-6)
+	})
+}
+
+func TestNormalizeL2(t *testing.T) {
+	t.Run("ゼロベクトルはそのまま返す", func(t *testing.T) {
+		assert.Equal(t, []float32{0, 0}, normalizeL2([]float32{0, 0}))
+	})
+
+	t.Run("正規化後のノルムは1", func(t *testing.T) {
+		v := normalizeL2([]float32{1, 2, 3, 4})
+		var sum float64
+		for _, x := range v {
+			sum += float64(x) * float64(x)
+		}
+		assert.InDelta(t, 1.0, math.Sqrt(sum), 1e-6)
+	})
+}
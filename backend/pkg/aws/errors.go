@@ -0,0 +1,26 @@
+package aws
+
+import (
+	"errors"
+
+	"github.com/aws/smithy-go"
+)
+
+// throttlingErrorCodes はAWSがレート超過時に返すエラーコード
+var throttlingErrorCodes = map[string]bool{
+	"ThrottlingException":                    true,
+	"TooManyRequestsException":               true,
+	"ProvisionedThroughputExceededException": true,
+	"ServiceQuotaExceededException":          true,
+	"RequestLimitExceeded":                   true,
+	"SlowDown":                               true,
+}
+
+// IsThrottlingError はAWSのレート超過エラーかどうかを判定する
+func IsThrottlingError(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return throttlingErrorCodes[apiErr.ErrorCode()]
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"bedrock-rag-sample/backend/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	agenttypes "github.com/aws/aws-sdk-go-v2/service/bedrockagentruntime/types"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// ErrContentBlocked はガードレールが入力または出力を遮断した場合のエラー
+var ErrContentBlocked = errors.New("ガードレールによりコンテンツが遮断されました")
+
+// ガードレールが評価した対象
+const (
+	GuardrailSourceInput  = "input"  // プロンプト (質問・検索した文書を含む)
+	GuardrailSourceOutput = "output" // モデルが生成した回答
+)
+
+// ガードレールのポリシー
+const (
+	GuardrailPolicyContent   = "content"
+	GuardrailPolicyTopic     = "topic"
+	GuardrailPolicyWord      = "word"
+	GuardrailPolicySensitive = "sensitive_information"
+	GuardrailPolicyGrounding = "contextual_grounding"
+)
+
+const (
+	guardrailActionNone     = "NONE"
+	guardrailActionBlocked  = "BLOCKED"
+	guardrailTypeCustomWord = "CUSTOM_WORD"
+	guardrailTypeUnknown    = "UNKNOWN"
+	defaultGuardrailVersion = "DRAFT"
+)
+
+// Guardrail はモデル呼び出しに適用するBedrock Guardrailsの設定
+type Guardrail struct {
+	ID      string
+	Version string
+}
+
+// NewGuardrail は設定からガードレールを作成する (IDが空の場合は nil を返し、ガードレールを適用しない)
+func NewGuardrail(cfg *config.Config) *Guardrail {
+	if cfg == nil || cfg.AWS.GuardrailID == "" {
+		return nil
+	}
+	version := cfg.AWS.GuardrailVersion
+	if version == "" {
+		version = defaultGuardrailVersion
+	}
+	return &Guardrail{ID: cfg.AWS.GuardrailID, Version: version}
+}
+
+// converseConfig はConverse APIに指定するガードレールの設定を返す
+// 介入した理由を解析するため、トレースを有効にする
+func (g *Guardrail) converseConfig() *types.GuardrailConfiguration {
+	if g == nil {
+		return nil
+	}
+	return &types.GuardrailConfiguration{
+		GuardrailIdentifier: aws.String(g.ID),
+		GuardrailVersion:    aws.String(g.Version),
+		Trace:               types.GuardrailTraceEnabled,
+	}
+}
+
+// knowledgeBaseConfig はRetrieveAndGenerate APIに指定するガードレールの設定を返す
+func (g *Guardrail) knowledgeBaseConfig() *agenttypes.GuardrailConfiguration {
+	if g == nil {
+		return nil
+	}
+	return &agenttypes.GuardrailConfiguration{
+		GuardrailId:      aws.String(g.ID),
+		GuardrailVersion: aws.String(g.Version),
+	}
+}
+
+// GuardrailFinding はガードレールが検出した1件の違反
+type GuardrailFinding struct {
+	Source string `json:"source"` // GuardrailSourceInput / GuardrailSourceOutput
+	Policy string `json:"policy"` // GuardrailPolicyContent など
+	Type   string `json:"type"`   // フィルターの種類・トピック名・PIIの種類など
+	Action string `json:"action"` // BLOCKED / ANONYMIZED
+}
+
+// GuardrailOutcome はガードレールが介入した結果
+type GuardrailOutcome struct {
+	Blocked  bool               `json:"blocked"`            // 遮断した (false の場合は匿名化などを行ったうえで回答を返した)
+	Findings []GuardrailFinding `json:"findings,omitempty"` // トレースが得られない場合は空
+	Message  string             `json:"message,omitempty"`  // ガードレールに設定した遮断時のメッセージ
+}
+
+// ContentBlockedError はガードレールが入力または出力を遮断したことを表すエラー
+type ContentBlockedError struct {
+	Model   string
+	Outcome GuardrailOutcome
+}
+
+func (e *ContentBlockedError) Error() string {
+	var reasons []string
+	for _, f := range e.Outcome.Findings {
+		if f.Action == guardrailActionBlocked {
+			reasons = append(reasons, fmt.Sprintf("%s:%s/%s", f.Source, f.Policy, f.Type))
+		}
+	}
+	if len(reasons) == 0 {
+		return fmt.Sprintf("%s (model: %s)", ErrContentBlocked.Error(), e.Model)
+	}
+	return fmt.Sprintf("%s (model: %s, %s)", ErrContentBlocked.Error(), e.Model, strings.Join(reasons, ", "))
+}
+
+func (e *ContentBlockedError) Unwrap() error {
+	return ErrContentBlocked
+}
+
+// parseGuardrailTrace はConverse APIのトレースからガードレールの介入結果を作成する
+// トレースがない場合は理由を判別できないため、遮断したものとして扱う
+func parseGuardrailTrace(trace *types.ConverseTrace) GuardrailOutcome {
+	if trace == nil || trace.Guardrail == nil {
+		return GuardrailOutcome{Blocked: true}
+	}
+
+	var findings []GuardrailFinding
+	for _, key := range sortedKeys(trace.Guardrail.InputAssessment) {
+		findings = append(findings, assessmentFindings(GuardrailSourceInput, trace.Guardrail.InputAssessment[key])...)
+	}
+	for _, key := range sortedKeys(trace.Guardrail.OutputAssessments) {
+		for _, assessment := range trace.Guardrail.OutputAssessments[key] {
+			findings = append(findings, assessmentFindings(GuardrailSourceOutput, assessment)...)
+		}
+	}
+
+	outcome := GuardrailOutcome{Findings: findings, Blocked: len(findings) == 0}
+	for _, f := range findings {
+		if f.Action == guardrailActionBlocked {
+			outcome.Blocked = true
+			break
+		}
+	}
+	return outcome
+}
+
+// assessmentFindings はポリシーごとの評価結果から、ガードレールが対処した違反を取り出す
+func assessmentFindings(source string, a types.GuardrailAssessment) []GuardrailFinding {
+	var findings []GuardrailFinding
+	add := func(policy, typ, action string) {
+		if action == "" || action == guardrailActionNone {
+			return
+		}
+		if typ == "" {
+			typ = guardrailTypeUnknown
+		}
+		findings = append(findings, GuardrailFinding{Source: source, Policy: policy, Type: typ, Action: action})
+	}
+
+	if a.ContentPolicy != nil {
+		for _, f := range a.ContentPolicy.Filters {
+			add(GuardrailPolicyContent, string(f.Type), string(f.Action))
+		}
+	}
+	if a.TopicPolicy != nil {
+		for _, t := range a.TopicPolicy.Topics {
+			add(GuardrailPolicyTopic, aws.ToString(t.Name), string(t.Action))
+		}
+	}
+	if a.WordPolicy != nil {
+		for _, w := range a.WordPolicy.CustomWords {
+			add(GuardrailPolicyWord, guardrailTypeCustomWord, string(w.Action))
+		}
+		for _, w := range a.WordPolicy.ManagedWordLists {
+			add(GuardrailPolicyWord, string(w.Type), string(w.Action))
+		}
+	}
+	if a.SensitiveInformationPolicy != nil {
+		for _, p := range a.SensitiveInformationPolicy.PiiEntities {
+			add(GuardrailPolicySensitive, string(p.Type), string(p.Action))
+		}
+		for _, r := range a.SensitiveInformationPolicy.Regexes {
+			add(GuardrailPolicySensitive, aws.ToString(r.Name), string(r.Action))
+		}
+	}
+	if a.ContextualGroundingPolicy != nil {
+		for _, f := range a.ContextualGroundingPolicy.Filters {
+			add(GuardrailPolicyGrounding, string(f.Type), string(f.Action))
+		}
+	}
+	return findings
+}
+
+// sortedKeys は結果の順序を安定させるため、マップのキーを整列して返す
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,116 @@
+package aws
+
+import (
+	"testing"
+
+	"bedrock-rag-sample/backend/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGuardrail(t *testing.T) {
+	t.Run("IDが空の場合は適用しない", func(t *testing.T) {
+		guardrail := NewGuardrail(&config.Config{})
+
+		assert.Nil(t, guardrail)
+		assert.Nil(t, guardrail.converseConfig())
+		assert.Nil(t, guardrail.knowledgeBaseConfig())
+	})
+
+	t.Run("バージョンを省略した場合はDRAFT", func(t *testing.T) {
+		guardrail := NewGuardrail(&config.Config{AWS: config.AWSConfig{GuardrailID: "gr-123"}})
+
+		require.NotNil(t, guardrail)
+		converseCfg := guardrail.converseConfig()
+		assert.Equal(t, "gr-123", aws.ToString(converseCfg.GuardrailIdentifier))
+		assert.Equal(t, "DRAFT", aws.ToString(converseCfg.GuardrailVersion))
+		assert.Equal(t, types.GuardrailTraceEnabled, converseCfg.Trace)
+		kbCfg := guardrail.knowledgeBaseConfig()
+		assert.Equal(t, "gr-123", aws.ToString(kbCfg.GuardrailId))
+		assert.Equal(t, "DRAFT", aws.ToString(kbCfg.GuardrailVersion))
+	})
+}
+
+func TestParseGuardrailTrace(t *testing.T) {
+	t.Run("遮断した違反と匿名化した違反を取り出す", func(t *testing.T) {
+		trace := &types.ConverseTrace{Guardrail: &types.GuardrailTraceAssessment{
+			InputAssessment: map[string]types.GuardrailAssessment{
+				"gr-123": {
+					ContentPolicy: &types.GuardrailContentPolicyAssessment{Filters: []types.GuardrailContentFilter{
+						{Type: types.GuardrailContentFilterTypePromptAttack, Action: types.GuardrailContentPolicyActionBlocked},
+						{Type: types.GuardrailContentFilterTypeHate, Action: types.GuardrailContentPolicyAction("NONE")},
+					}},
+					TopicPolicy: &types.GuardrailTopicPolicyAssessment{Topics: []types.GuardrailTopic{
+						{Name: aws.String("投資助言"), Action: types.GuardrailTopicPolicyActionBlocked},
+					}},
+				},
+			},
+			OutputAssessments: map[string][]types.GuardrailAssessment{
+				"gr-123": {{
+					SensitiveInformationPolicy: &types.GuardrailSensitiveInformationPolicyAssessment{PiiEntities: []types.GuardrailPiiEntityFilter{
+						{Type: types.GuardrailPiiEntityTypeEmail, Action: types.GuardrailSensitiveInformationPolicyActionAnonymized},
+					}},
+				}},
+			},
+		}}
+
+		outcome := parseGuardrailTrace(trace)
+
+		assert.True(t, outcome.Blocked)
+		assert.Equal(t, []GuardrailFinding{
+			{Source: GuardrailSourceInput, Policy: GuardrailPolicyContent, Type: "PROMPT_ATTACK", Action: "BLOCKED"},
+			{Source: GuardrailSourceInput, Policy: GuardrailPolicyTopic, Type: "投資助言", Action: "BLOCKED"},
+			{Source: GuardrailSourceOutput, Policy: GuardrailPolicySensitive, Type: "EMAIL", Action: "ANONYMIZED"},
+		}, outcome.Findings)
+	})
+
+	t.Run("匿名化のみの場合は遮断しない", func(t *testing.T) {
+		trace := &types.ConverseTrace{Guardrail: &types.GuardrailTraceAssessment{
+			OutputAssessments: map[string][]types.GuardrailAssessment{
+				"gr-123": {{
+					SensitiveInformationPolicy: &types.GuardrailSensitiveInformationPolicyAssessment{Regexes: []types.GuardrailRegexFilter{
+						{Name: aws.String("社員番号"), Action: types.GuardrailSensitiveInformationPolicyActionAnonymized},
+					}},
+				}},
+			},
+		}}
+
+		outcome := parseGuardrailTrace(trace)
+
+		assert.False(t, outcome.Blocked)
+		require.Len(t, outcome.Findings, 1)
+		assert.Equal(t, "社員番号", outcome.Findings[0].Type)
+	})
+
+	t.Run("トレースがない場合は遮断したものとして扱う", func(t *testing.T) {
+		outcome := parseGuardrailTrace(nil)
+
+		assert.True(t, outcome.Blocked)
+		assert.Empty(t, outcome.Findings)
+	})
+}
+
+func TestContentBlockedError(t *testing.T) {
+	output := &bedrockruntime.ConverseOutput{
+		Output: &types.ConverseOutputMemberMessage{Value: types.Message{
+			Role:    types.ConversationRoleAssistant,
+			Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: "この質問にはお答えできません。"}},
+		}},
+		StopReason: types.StopReasonGuardrailIntervened,
+	}
+
+	response, err := parseConverseOutput(output)
+
+	require.NoError(t, err)
+	assert.Equal(t, StopReasonGuardrailIntervened, response.StopReason)
+	blockedErr := &ContentBlockedError{Model: "claude-3-haiku", Outcome: GuardrailOutcome{
+		Blocked:  true,
+		Findings: []GuardrailFinding{{Source: GuardrailSourceInput, Policy: GuardrailPolicyWord, Type: "PROFANITY", Action: "BLOCKED"}},
+	}}
+	assert.ErrorIs(t, blockedErr, ErrContentBlocked)
+	assert.Contains(t, blockedErr.Error(), "input:word/PROFANITY")
+}
@@ -5,6 +5,7 @@
 package mock
 
 import (
+	aws "bedrock-rag-sample/backend/pkg/aws"
 	context "context"
 	multipart "mime/multipart"
 	reflect "reflect"
@@ -35,6 +36,20 @@ func (m *MockS3ClientInterface) EXPECT() *MockS3ClientInterfaceMockRecorder {
 	return m.recorder
 }
 
+// DeleteObject mocks base method.
+func (m *MockS3ClientInterface) DeleteObject(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteObject", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteObject indicates an expected call of DeleteObject.
+func (mr *MockS3ClientInterfaceMockRecorder) DeleteObject(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteObject", reflect.TypeOf((*MockS3ClientInterface)(nil).DeleteObject), ctx, key)
+}
+
 // DownloadFileContent mocks base method.
 func (m *MockS3ClientInterface) DownloadFileContent(ctx context.Context, key string) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +80,82 @@ func (mr *MockS3ClientInterfaceMockRecorder) GetFileURL(ctx, key interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileURL", reflect.TypeOf((*MockS3ClientInterface)(nil).GetFileURL), ctx, key)
 }
 
+// HeadBucket mocks base method.
+func (m *MockS3ClientInterface) HeadBucket(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HeadBucket", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HeadBucket indicates an expected call of HeadBucket.
+func (mr *MockS3ClientInterfaceMockRecorder) HeadBucket(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeadBucket", reflect.TypeOf((*MockS3ClientInterface)(nil).HeadBucket), ctx)
+}
+
+// ListObjects mocks base method.
+func (m *MockS3ClientInterface) ListObjects(ctx context.Context, prefix string) ([]aws.S3Object, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListObjects", ctx, prefix)
+	ret0, _ := ret[0].([]aws.S3Object)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListObjects indicates an expected call of ListObjects.
+func (mr *MockS3ClientInterfaceMockRecorder) ListObjects(ctx, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListObjects", reflect.TypeOf((*MockS3ClientInterface)(nil).ListObjects), ctx, prefix)
+}
+
+// ListObjectsPage mocks base method.
+func (m *MockS3ClientInterface) ListObjectsPage(ctx context.Context, prefix, startAfter string, maxKeys int) (*aws.S3ObjectPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListObjectsPage", ctx, prefix, startAfter, maxKeys)
+	ret0, _ := ret[0].(*aws.S3ObjectPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListObjectsPage indicates an expected call of ListObjectsPage.
+func (mr *MockS3ClientInterfaceMockRecorder) ListObjectsPage(ctx, prefix, startAfter, maxKeys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListObjectsPage", reflect.TypeOf((*MockS3ClientInterface)(nil).ListObjectsPage), ctx, prefix, startAfter, maxKeys)
+}
+
+// ObjectKey mocks base method.
+func (m *MockS3ClientInterface) ObjectKey(elem ...string) string {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range elem {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ObjectKey", varargs...)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ObjectKey indicates an expected call of ObjectKey.
+func (mr *MockS3ClientInterfaceMockRecorder) ObjectKey(elem ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObjectKey", reflect.TypeOf((*MockS3ClientInterface)(nil).ObjectKey), elem...)
+}
+
+// PutObject mocks base method.
+func (m *MockS3ClientInterface) PutObject(ctx context.Context, key string, content []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutObject", ctx, key, content)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutObject indicates an expected call of PutObject.
+func (mr *MockS3ClientInterfaceMockRecorder) PutObject(ctx, key, content interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutObject", reflect.TypeOf((*MockS3ClientInterface)(nil).PutObject), ctx, key, content)
+}
+
 // UploadFile mocks base method.
 func (m *MockS3ClientInterface) UploadFile(ctx context.Context, file *multipart.FileHeader, customPath string) (string, error) {
 	m.ctrl.T.Helper()
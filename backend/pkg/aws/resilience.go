@@ -0,0 +1,279 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+
+	"bedrock-rag-sample/backend/config"
+
+	"github.com/aws/smithy-go"
+)
+
+// ErrCircuitOpen はサーキットブレーカーが開いており呼び出しを遮断した場合のエラー
+var ErrCircuitOpen = errors.New("障害が続いているため一時的に呼び出しを停止しています")
+
+// retryableErrorCodes はレート超過以外で再試行により回復が見込めるエラーコード
+var retryableErrorCodes = map[string]bool{
+	"InternalServerException":     true,
+	"ServiceUnavailableException": true,
+	"ModelNotReadyException":      true,
+	"ModelTimeoutException":       true,
+	"InternalServerError":         true,
+	"ServiceUnavailable":          true,
+	"InternalFailure":             true,
+}
+
+// IsRetryableError は再試行で回復が見込めるエラーかどうかを判定する
+// レート超過・5xx・タイムアウトなどの一時的な障害は true、入力不正や権限エラー、呼び出し元のキャンセルは false
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrModelBusy) {
+		return false
+	}
+	if IsThrottlingError(err) {
+		return true
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if retryableErrorCodes[apiErr.ErrorCode()] {
+			return true
+		}
+		if apiErr.ErrorFault() == smithy.FaultClient {
+			return false
+		}
+	}
+
+	var statusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) && statusErr.HTTPStatusCode() >= 500 {
+		return true
+	}
+
+	// 試行ごとのタイムアウトやネットワークエラー
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// RetryPolicy は再試行の方針
+type RetryPolicy struct {
+	MaxAttempts    int           // 最大試行回数 (初回を含む)
+	BaseDelay      time.Duration // 初回再試行までの基準待機時間
+	MaxDelay       time.Duration // 待機時間の上限
+	AttemptTimeout time.Duration // 1回の試行のタイムアウト (0以下で無制限)
+}
+
+// Backoff は attempt 回目 (0始まり) の失敗後に待機する時間を返す (Full Jitter 方式の指数バックオフ)
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+	ceiling := p.BaseDelay << attempt
+	if ceiling <= 0 || (p.MaxDelay > 0 && ceiling > p.MaxDelay) {
+		ceiling = p.MaxDelay
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// circuitState はサーキットブレーカーの状態
+type circuitState int
+
+const (
+	circuitClosed   circuitState = iota // 通常状態
+	circuitOpen                         // 遮断中
+	circuitHalfOpen                     // 試験的に1件だけ通している状態
+)
+
+// CircuitBreaker は連続した失敗を検知して呼び出しを一時的に遮断する
+type CircuitBreaker struct {
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+
+	mu               sync.Mutex
+	state            circuitState
+	consecutiveFails int
+	openedAt         time.Time
+}
+
+// NewCircuitBreaker は新しいCircuitBreakerを作成する
+// failureThreshold 回連続で失敗すると openTimeout の間遮断し、その後1件だけ試験的に通す
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+	}
+}
+
+// Allow は呼び出しを許可するかを判定する
+func (b *CircuitBreaker) Allow() error {
+	if b.failureThreshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = circuitHalfOpen
+		return nil
+	case circuitHalfOpen:
+		// 試験中の呼び出しの結果が出るまでは遮断する
+		return ErrCircuitOpen
+	default:
+		return nil
+	}
+}
+
+// Record は呼び出し結果を記録する。一時的な障害のみを失敗として数える
+func (b *CircuitBreaker) Record(err error) {
+	if b.failureThreshold <= 0 {
+		return
+	}
+
+	// 呼び出し元のキャンセルや同時実行数制限による待機切れは呼び出し先の状態を表さないため記録しない
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrModelBusy) {
+		b.mu.Lock()
+		if b.state == circuitHalfOpen {
+			b.state = circuitOpen
+		}
+		b.mu.Unlock()
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil || !IsRetryableError(err) {
+		b.state = circuitClosed
+		b.consecutiveFails = 0
+		return
+	}
+
+	b.consecutiveFails++
+	if b.state == circuitHalfOpen || b.consecutiveFails >= b.failureThreshold {
+		b.state = circuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Resilience は再試行とサーキットブレーカー (呼び出し先ごと) をまとめて適用する
+type Resilience struct {
+	policy           RetryPolicy
+	failureThreshold int
+	openTimeout      time.Duration
+
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+}
+
+// NewResilience は新しいResilienceを作成する
+func NewResilience(policy RetryPolicy, failureThreshold int, openTimeout time.Duration) *Resilience {
+	return &Resilience{
+		policy:           policy,
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		breakers:         make(map[string]*CircuitBreaker),
+	}
+}
+
+var (
+	sharedResilienceOnce sync.Once
+	sharedResilience     *Resilience
+)
+
+// sharedResilienceFor はプロセス全体で共有するResilienceを返す
+// 同じモデルへの呼び出しはクライアントが異なっても同じサーキットブレーカーで判定する
+func sharedResilienceFor(cfg *config.Config) *Resilience {
+	sharedResilienceOnce.Do(func() {
+		sharedResilience = NewResilience(RetryPolicy{
+			MaxAttempts:    cfg.Resilience.MaxAttempts,
+			BaseDelay:      cfg.Resilience.BaseDelay,
+			MaxDelay:       cfg.Resilience.MaxDelay,
+			AttemptTimeout: cfg.Resilience.AttemptTimeout,
+		}, cfg.Resilience.BreakerFailureThreshold, cfg.Resilience.BreakerOpenTimeout)
+	})
+	return sharedResilience
+}
+
+// Policy は再試行の方針を返す
+func (r *Resilience) Policy() RetryPolicy {
+	return r.policy
+}
+
+// breaker は呼び出し先に対応するサーキットブレーカーを返す
+func (r *Resilience) breaker(key string) *CircuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.breakers[key]
+	if !ok {
+		b = NewCircuitBreaker(r.failureThreshold, r.openTimeout)
+		r.breakers[key] = b
+	}
+	return b
+}
+
+// callWithResilience は key (モデルIDなど) ごとのサーキットブレーカーと再試行を適用して fn を呼び出す
+// r が nil の場合は fn を1回だけ呼び出す
+func callWithResilience[T any](ctx context.Context, r *Resilience, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	if r == nil {
+		return fn(ctx)
+	}
+
+	var zero T
+	breaker := r.breaker(key)
+	maxAttempts := max(r.policy.MaxAttempts, 1)
+
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if err := breaker.Allow(); err != nil {
+			if lastErr != nil {
+				return zero, fmt.Errorf("%w (key: %s): %w", err, key, lastErr)
+			}
+			return zero, fmt.Errorf("%w (key: %s)", err, key)
+		}
+
+		result, err := callOnce(ctx, r.policy.AttemptTimeout, fn)
+		breaker.Record(err)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		// 呼び出し元のキャンセル・期限切れや再試行しても回復しないエラーは即座に返す
+		if ctx.Err() != nil || !IsRetryableError(err) || attempt == maxAttempts-1 {
+			break
+		}
+
+		timer := time.NewTimer(r.policy.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, lastErr
+		case <-timer.C:
+		}
+	}
+
+	return zero, lastErr
+}
+
+// callOnce は1回分の試行をタイムアウト付きで実行する
+func callOnce[T any](ctx context.Context, timeout time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return fn(attemptCtx)
+}
@@ -0,0 +1,165 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"スロットリング", &smithy.GenericAPIError{Code: "ThrottlingException"}, true},
+		{"サーバーエラー", &smithy.GenericAPIError{Code: "InternalServerException", Fault: smithy.FaultServer}, true},
+		{"モデルのタイムアウト", fmt.Errorf("wrapped: %w", &smithy.GenericAPIError{Code: "ModelTimeoutException"}), true},
+		{"入力不正", &smithy.GenericAPIError{Code: "ValidationException", Fault: smithy.FaultClient}, false},
+		{"権限エラー", &smithy.GenericAPIError{Code: "AccessDeniedException", Fault: smithy.FaultClient}, false},
+		{"試行のタイムアウト", context.DeadlineExceeded, true},
+		{"呼び出し元のキャンセル", context.Canceled, false},
+		{"サーキットブレーカー", ErrCircuitOpen, false},
+		{"同時実行数超過", ErrModelBusy, false},
+		{"その他のエラー", errors.New("boom"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsRetryableError(tc.err))
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		ceiling := min(p.BaseDelay<<attempt, p.MaxDelay)
+		for i := 0; i < 20; i++ {
+			d := p.Backoff(attempt)
+			assert.GreaterOrEqual(t, d, time.Duration(0))
+			assert.LessOrEqual(t, d, ceiling)
+		}
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	b := NewCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+	transient := &smithy.GenericAPIError{Code: "ServiceUnavailableException"}
+
+	// 閾値までは通す
+	require.NoError(t, b.Allow())
+	b.Record(transient)
+	require.NoError(t, b.Allow())
+	b.Record(transient)
+
+	// 連続失敗で遮断
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// 一定時間後に1件だけ試験的に通す
+	now = now.Add(time.Minute)
+	require.NoError(t, b.Allow())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// 試験的な呼び出しが失敗すると再度遮断
+	b.Record(transient)
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// 成功すると通常状態に戻る
+	now = now.Add(time.Minute)
+	require.NoError(t, b.Allow())
+	b.Record(nil)
+	assert.NoError(t, b.Allow())
+	assert.NoError(t, b.Allow())
+}
+
+func TestCallWithResilience(t *testing.T) {
+	ctx := context.Background()
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	transient := &smithy.GenericAPIError{Code: "ThrottlingException"}
+
+	t.Run("一時的な障害は再試行する", func(t *testing.T) {
+		r := NewResilience(policy, 10, time.Minute)
+		calls := 0
+
+		result, err := callWithResilience(ctx, r, "model-a", func(ctx context.Context) (string, error) {
+			calls++
+			if calls < 3 {
+				return "", transient
+			}
+			return "ok", nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "ok", result)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("回復しないエラーは再試行しない", func(t *testing.T) {
+		r := NewResilience(policy, 10, time.Minute)
+		calls := 0
+		validation := &smithy.GenericAPIError{Code: "ValidationException", Fault: smithy.FaultClient}
+
+		_, err := callWithResilience(ctx, r, "model-a", func(ctx context.Context) (string, error) {
+			calls++
+			return "", validation
+		})
+
+		assert.ErrorIs(t, err, validation)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("試行回数の上限で最後のエラーを返す", func(t *testing.T) {
+		r := NewResilience(policy, 10, time.Minute)
+		calls := 0
+
+		_, err := callWithResilience(ctx, r, "model-a", func(ctx context.Context) (string, error) {
+			calls++
+			return "", transient
+		})
+
+		assert.True(t, IsThrottlingError(err))
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("試行ごとのタイムアウト", func(t *testing.T) {
+		r := NewResilience(RetryPolicy{MaxAttempts: 2, AttemptTimeout: 10 * time.Millisecond}, 10, time.Minute)
+		calls := 0
+
+		_, err := callWithResilience(ctx, r, "model-a", func(ctx context.Context) (string, error) {
+			calls++
+			<-ctx.Done()
+			return "", ctx.Err()
+		})
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("モデルごとにサーキットブレーカーで遮断する", func(t *testing.T) {
+		r := NewResilience(RetryPolicy{MaxAttempts: 1}, 2, time.Minute)
+		failing := func(ctx context.Context) (string, error) { return "", transient }
+
+		for i := 0; i < 2; i++ {
+			_, err := callWithResilience(ctx, r, "model-a", failing)
+			require.True(t, IsThrottlingError(err))
+		}
+
+		_, err := callWithResilience(ctx, r, "model-a", failing)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+
+		// 別モデルは影響を受けない
+		result, err := callWithResilience(ctx, r, "model-b", func(ctx context.Context) (string, error) { return "ok", nil })
+		require.NoError(t, err)
+		assert.Equal(t, "ok", result)
+	})
+}
@@ -3,16 +3,22 @@ package aws
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	appconfig "bedrock-rag-sample/backend/config"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 // S3Client はS3操作のためのクライアント
@@ -20,6 +26,7 @@ type S3Client struct {
 	client   *s3.Client
 	bucket   string
 	basePath string
+	now      func() time.Time
 }
 
 // NewS3Client は新しいS3クライアントを作成する
@@ -35,10 +42,12 @@ func NewS3Client(cfg *appconfig.Config) (*S3Client, error) {
 		client:   client,
 		bucket:   cfg.AWS.S3BucketName,
 		basePath: cfg.AWS.S3DocumentsPath,
+		now:      time.Now,
 	}, nil
 }
 
 // UploadFile はファイルをS3にアップロードする
+// 同じ名前のファイルを上書きしないよう、取り込み (IngestService) と同じく内容のハッシュとアップロード時刻をキーに含める
 func (s *S3Client) UploadFile(ctx context.Context, file *multipart.FileHeader, customPath string) (string, error) {
 	src, err := file.Open()
 	if err != nil {
@@ -46,29 +55,52 @@ func (s *S3Client) UploadFile(ctx context.Context, file *multipart.FileHeader, c
 	}
 	defer src.Close()
 
-	// ファイル名を取得
-	filename := filepath.Base(file.Filename)
-
-	// S3内のパスを構築
-	s3Path := s.basePath
-	if customPath != "" {
-		s3Path = filepath.Join(s3Path, customPath)
+	content, err := io.ReadAll(src)
+	if err != nil {
+		return "", fmt.Errorf("ファイルの読み込みに失敗しました: %w", err)
 	}
-	s3Key := filepath.Join(s3Path, filename)
+	sum := sha256.Sum256(content)
+
+	// S3内のパスを構築 (<basePath>/<customPath>/<ハッシュ>/<時刻>/<ファイル名>)
+	s3Key := s.ObjectKey(customPath, hex.EncodeToString(sum[:])[:16], strconv.FormatInt(s.now().UnixMilli(), 10), filepath.Base(file.Filename))
 
 	// S3にアップロード
-	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+	if err := s.PutObject(ctx, s3Key, content); err != nil {
+		return "", err
+	}
+
+	// S3内のファイルへのパスを返す
+	return s3Key, nil
+}
+
+// PutObject は key にファイルの内容をそのまま書き込む (同じキーのオブジェクトは上書きされる)
+func (s *S3Client) PutObject(ctx context.Context, key string, content []byte) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket: aws.String(s.bucket),
-		Key:    aws.String(s3Key),
-		Body:   src,
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(content),
 	})
+	if err != nil {
+		return fmt.Errorf("S3へのアップロードに失敗しました (key: %s): %w", key, err)
+	}
+	return nil
+}
 
+// DeleteObject は key のオブジェクトを削除する (存在しない場合も成功として扱う)
+func (s *S3Client) DeleteObject(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
 	if err != nil {
-		return "", fmt.Errorf("S3へのアップロードに失敗しました: %w", err)
+		return fmt.Errorf("S3のオブジェクトの削除に失敗しました (key: %s): %w", key, err)
 	}
+	return nil
+}
 
-	// S3内のファイルへのパスを返す
-	return s3Key, nil
+// ObjectKey は basePath 以下のキーを返す
+func (s *S3Client) ObjectKey(elem ...string) string {
+	return filepath.Join(append([]string{s.basePath}, elem...)...)
 }
 
 // GetFileURL はS3内のファイルへのアクセスURLを生成する
@@ -105,3 +137,75 @@ func (s *S3Client) DownloadFileContent(ctx context.Context, key string) ([]byte,
 
 	return buf.Bytes(), nil
 }
+
+// ListObjects は prefix で始まるキーのオブジェクトをキーの順にすべて返す
+// "/" で終わるキー (フォルダを表す空のオブジェクト) は含めない
+func (s *S3Client) ListObjects(ctx context.Context, prefix string) ([]S3Object, error) {
+	var objects []S3Object
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("S3のオブジェクトの一覧の取得に失敗しました (prefix: %s): %w", prefix, err)
+		}
+		objects = appendObjects(objects, page.Contents)
+	}
+	return objects, nil
+}
+
+// ListObjectsPage は prefix で始まり startAfter より後のキーのオブジェクトを、キーの順に最大 maxKeys 件返す
+// 大きなバケットを途中から再開しながら走査するために使う ("/" で終わるキーは ListObjects と同様に含めない)
+func (s *S3Client) ListObjectsPage(ctx context.Context, prefix, startAfter string, maxKeys int) (*S3ObjectPage, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s.bucket),
+		Prefix:  aws.String(prefix),
+		MaxKeys: aws.Int32(int32(maxKeys)),
+	}
+	if startAfter != "" {
+		input.StartAfter = aws.String(startAfter)
+	}
+	out, err := s.client.ListObjectsV2(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("S3のオブジェクトの一覧の取得に失敗しました (prefix: %s, start_after: %s): %w", prefix, startAfter, err)
+	}
+
+	page := &S3ObjectPage{
+		Objects:   appendObjects(nil, out.Contents),
+		Truncated: aws.ToBool(out.IsTruncated),
+	}
+	if n := len(out.Contents); n > 0 {
+		page.LastKey = aws.ToString(out.Contents[n-1].Key)
+	}
+	return page, nil
+}
+
+// appendObjects は一覧の結果を S3Object に変換して追加する ("/" で終わるキーは除く)
+func appendObjects(objects []S3Object, contents []types.Object) []S3Object {
+	for _, obj := range contents {
+		key := aws.ToString(obj.Key)
+		if strings.HasSuffix(key, "/") {
+			continue
+		}
+		objects = append(objects, S3Object{
+			Key:          key,
+			ETag:         strings.Trim(aws.ToString(obj.ETag), `"`),
+			Size:         aws.ToInt64(obj.Size),
+			LastModified: aws.ToTime(obj.LastModified),
+		})
+	}
+	return objects
+}
+
+// HeadBucket はバケットへのアクセス可否を確認する (ヘルスチェック用)
+func (s *S3Client) HeadBucket(ctx context.Context) error {
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("S3バケットへのアクセスに失敗しました (bucket: %s): %w", s.bucket, err)
+	}
+	return nil
+}
@@ -3,13 +3,35 @@ package aws
 import (
 	"context"
 	"mime/multipart"
+	"time"
 )
 
+// S3Object はバケット内のオブジェクトの情報
+type S3Object struct {
+	Key          string
+	ETag         string // 内容が変わると変わる識別子 (引用符は除く)
+	Size         int64
+	LastModified time.Time
+}
+
+// S3ObjectPage はオブジェクトの一覧の1ページ
+type S3ObjectPage struct {
+	Objects   []S3Object
+	LastKey   string // このページで一覧した最後のキー ("/" で終わるキーを含む。続きを取得する際の startAfter)
+	Truncated bool   // 続きのページがあるかどうか
+}
+
 // S3ClientInterface はS3クライアントのインターフェース
 type S3ClientInterface interface {
 	UploadFile(ctx context.Context, file *multipart.FileHeader, customPath string) (string, error)
+	PutObject(ctx context.Context, key string, content []byte) error
+	DeleteObject(ctx context.Context, key string) error
+	ObjectKey(elem ...string) string
 	GetFileURL(ctx context.Context, key string) (string, error)
 	DownloadFileContent(ctx context.Context, key string) ([]byte, error)
+	ListObjects(ctx context.Context, prefix string) ([]S3Object, error)
+	ListObjectsPage(ctx context.Context, prefix, startAfter string, maxKeys int) (*S3ObjectPage, error)
+	HeadBucket(ctx context.Context) error
 }
 
 // インターフェースを実装していることを静的にチェック
@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMultipartFile は内容が content のアップロードファイルを作成する
+func newMultipartFile(t *testing.T, filename, content string) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["file"][0]
+}
+
+func TestS3Client_UploadFile(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		puts []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			mu.Lock()
+			puts = append(puts, r.URL.Path)
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	now := time.UnixMilli(1700000000000)
+	client := &S3Client{
+		client: s3.New(s3.Options{
+			Region:       "us-east-1",
+			BaseEndpoint: aws.String(server.URL),
+			UsePathStyle: true,
+			Credentials:  aws.AnonymousCredentials{},
+		}),
+		bucket:   "bucket",
+		basePath: "documents/",
+		now:      func() time.Time { return now },
+	}
+	ctx := context.Background()
+
+	first, err := client.UploadFile(ctx, newMultipartFile(t, "report.pdf", "初版"), "pdf")
+	require.NoError(t, err)
+	now = now.Add(time.Millisecond)
+	second, err := client.UploadFile(ctx, newMultipartFile(t, "report.pdf", "改訂版"), "pdf")
+	require.NoError(t, err)
+	now = now.Add(time.Millisecond)
+	third, err := client.UploadFile(ctx, newMultipartFile(t, "report.pdf", "初版"), "pdf")
+	require.NoError(t, err)
+
+	// 同じ名前のファイルは内容のハッシュとアップロード時刻で別のキーになり、上書きしない
+	assert.Regexp(t, `^documents/pdf/[0-9a-f]{16}/1700000000000/report\.pdf$`, first)
+	assert.NotEqual(t, first, second)
+	assert.NotEqual(t, first, third, "同じ内容でもアップロード時刻が異なれば別のキーにする")
+	require.Len(t, puts, 3)
+	for i, key := range []string{first, second, third} {
+		assert.True(t, strings.HasSuffix(puts[i], "/bucket/"+key), puts[i])
+	}
+}
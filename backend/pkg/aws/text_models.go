@@ -0,0 +1,98 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrUnknownTextModel はカタログに登録されていないテキスト生成モデルが指定された場合のエラー
+var ErrUnknownTextModel = errors.New("未対応のテキスト生成モデルです")
+
+// テキスト生成モデルのファミリー
+const (
+	TextModelFamilyClaude  = "claude"
+	TextModelFamilyLlama   = "llama"
+	TextModelFamilyMistral = "mistral"
+	TextModelFamilyTitan   = "titan"
+)
+
+// テキスト生成の既定のパラメータ
+const (
+	defaultTextTemperature = 0.7
+	defaultTextTopP        = 0.9
+)
+
+// TextModel はテキスト生成モデルの定義
+type TextModel struct {
+	Name      string `json:"name"`       // カタログ名 (リクエストで指定する識別子)
+	ModelID   string `json:"model_id"`   // BedrockのモデルID
+	Family    string `json:"family"`     // モデルのファミリー
+	MaxTokens int    `json:"max_tokens"` // 1回の生成で出力する最大トークン数
+
+	// Converse API で利用できる機能
+	SupportsSystemPrompt bool `json:"supports_system_prompt"` // false の場合はシステムプロンプトを最初のメッセージに含める
+	SupportsTools        bool `json:"supports_tools"`
+}
+
+// textModels は利用可能なテキスト生成モデルのカタログ
+var textModels = map[string]TextModel{
+	"claude-3-haiku": {
+		Name: "claude-3-haiku", ModelID: "anthropic.claude-3-haiku-20240307-v1:0", Family: TextModelFamilyClaude, MaxTokens: 2048,
+		SupportsSystemPrompt: true, SupportsTools: true,
+	},
+	"claude-3-sonnet": {
+		Name: "claude-3-sonnet", ModelID: "anthropic.claude-3-sonnet-20240229-v1:0", Family: TextModelFamilyClaude, MaxTokens: 2048,
+		SupportsSystemPrompt: true, SupportsTools: true,
+	},
+	"claude-3-5-sonnet": {
+		Name: "claude-3-5-sonnet", ModelID: "anthropic.claude-3-5-sonnet-20240620-v1:0", Family: TextModelFamilyClaude, MaxTokens: 2048,
+		SupportsSystemPrompt: true, SupportsTools: true,
+	},
+	"llama3-8b-instruct": {
+		Name: "llama3-8b-instruct", ModelID: "meta.llama3-8b-instruct-v1:0", Family: TextModelFamilyLlama, MaxTokens: 2048,
+		SupportsSystemPrompt: true,
+	},
+	"llama3-70b-instruct": {
+		Name: "llama3-70b-instruct", ModelID: "meta.llama3-70b-instruct-v1:0", Family: TextModelFamilyLlama, MaxTokens: 2048,
+		SupportsSystemPrompt: true,
+	},
+	"mistral-7b-instruct": {
+		Name: "mistral-7b-instruct", ModelID: "mistral.mistral-7b-instruct-v0:2", Family: TextModelFamilyMistral, MaxTokens: 2048,
+	},
+	"mistral-large": {
+		Name: "mistral-large", ModelID: "mistral.mistral-large-2402-v1:0", Family: TextModelFamilyMistral, MaxTokens: 2048,
+		SupportsSystemPrompt: true, SupportsTools: true,
+	},
+	"titan-text-express": {
+		Name: "titan-text-express", ModelID: "amazon.titan-text-express-v1", Family: TextModelFamilyTitan, MaxTokens: 2048,
+	},
+	"titan-text-lite": {
+		Name: "titan-text-lite", ModelID: "amazon.titan-text-lite-v1", Family: TextModelFamilyTitan, MaxTokens: 2048,
+	},
+}
+
+// LookupTextModel はカタログ名またはBedrockのモデルIDからテキスト生成モデルの定義を取得する
+// BEDROCK_MODEL_ID にモデルIDを指定していた既存の設定もそのまま使えるよう、モデルIDでも検索する
+func LookupTextModel(nameOrID string) (TextModel, error) {
+	if model, ok := textModels[nameOrID]; ok {
+		return model, nil
+	}
+	for _, name := range TextModelNames() {
+		if model := textModels[name]; model.ModelID == nameOrID {
+			return model, nil
+		}
+	}
+	return TextModel{}, fmt.Errorf("%w: %s (利用可能: %s)", ErrUnknownTextModel, nameOrID, strings.Join(TextModelNames(), ", "))
+}
+
+// TextModelNames は利用可能なテキスト生成モデルのカタログ名を返す
+func TextModelNames() []string {
+	names := make([]string, 0, len(textModels))
+	for name := range textModels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,25 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupTextModel(t *testing.T) {
+	t.Run("カタログ名とモデルIDのどちらでも取得できる", func(t *testing.T) {
+		byName, err := LookupTextModel("claude-3-haiku")
+		require.NoError(t, err)
+		byID, err := LookupTextModel("anthropic.claude-3-haiku-20240307-v1:0")
+		require.NoError(t, err)
+
+		assert.Equal(t, byName.Name, byID.Name)
+		assert.Equal(t, TextModelFamilyClaude, byName.Family)
+	})
+
+	t.Run("未登録のモデルはエラー", func(t *testing.T) {
+		_, err := LookupTextModel("gpt-4")
+		assert.ErrorIs(t, err, ErrUnknownTextModel)
+	})
+}
@@ -15,12 +15,16 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/textract/types"
 )
 
+// textractResilienceKey はTextract呼び出しのサーキットブレーカーのキー
+const textractResilienceKey = "textract"
+
 // TextractClient はTextract操作のためのクライアント
 type TextractClient struct {
 	client     *textract.Client
 	s3Client   *S3Client
 	region     string
 	bucketName string
+	resilience *Resilience
 }
 
 // NewTextractClient は新しいTextractClientを作成する
@@ -30,13 +34,16 @@ func NewTextractClient(cfg *config.Config, s3Client *S3Client) (*TextractClient,
 		return nil, fmt.Errorf("AWS設定の読み込みに失敗しました: %w", err)
 	}
 
-	client := textract.NewFromConfig(awsCfg)
+	client := textract.NewFromConfig(awsCfg, func(o *textract.Options) {
+		o.RetryMaxAttempts = 1 // 再試行は callWithResilience で行う
+	})
 
 	return &TextractClient{
 		client:     client,
 		s3Client:   s3Client,
 		region:     cfg.AWS.Region,
 		bucketName: cfg.AWS.S3BucketName,
+		resilience: sharedResilienceFor(cfg),
 	}, nil
 }
 
@@ -91,13 +98,15 @@ func (t *TextractClient) extractTextFromS3(ctx context.Context, s3Key string) (s
 	*/
 
 	// Textractにテキスト検出ジョブを送信
-	startResp, err := t.client.StartDocumentTextDetection(ctx, &textract.StartDocumentTextDetectionInput{
-		DocumentLocation: &types.DocumentLocation{
-			S3Object: &types.S3Object{
-				Bucket: aws.String(t.bucketName),
-				Name:   aws.String(s3Key),
+	startResp, err := callWithResilience(ctx, t.resilience, textractResilienceKey, func(ctx context.Context) (*textract.StartDocumentTextDetectionOutput, error) {
+		return t.client.StartDocumentTextDetection(ctx, &textract.StartDocumentTextDetectionInput{
+			DocumentLocation: &types.DocumentLocation{
+				S3Object: &types.S3Object{
+					Bucket: aws.String(t.bucketName),
+					Name:   aws.String(s3Key),
+				},
 			},
-		},
+		})
 	})
 	if err != nil {
 		return "", 0, fmt.Errorf("textract検出に失敗しました: %w", err)
@@ -106,8 +115,10 @@ func (t *TextractClient) extractTextFromS3(ctx context.Context, s3Key string) (s
 	jobId := startResp.JobId
 
 	// テキスト検出を実行
-	output, err := t.client.GetDocumentTextDetection(ctx, &textract.GetDocumentTextDetectionInput{
-		JobId: jobId,
+	output, err := callWithResilience(ctx, t.resilience, textractResilienceKey, func(ctx context.Context) (*textract.GetDocumentTextDetectionOutput, error) {
+		return t.client.GetDocumentTextDetection(ctx, &textract.GetDocumentTextDetectionInput{
+			JobId: jobId,
+		})
 	})
 	if err != nil {
 		return "", 0, fmt.Errorf("textract検出に失敗しました: %w", err)
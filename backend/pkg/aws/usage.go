@@ -0,0 +1,72 @@
+package aws
+
+import (
+	"context"
+	"strconv"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// 使用量を記録するBedrock呼び出しの種類
+const (
+	UsageOperationConverse            = "converse"
+	UsageOperationEmbedding           = "embedding"
+	UsageOperationRetrieveAndGenerate = "retrieve_and_generate"
+)
+
+// InvokeModel のレスポンスに含まれるトークン数のヘッダー
+const (
+	headerInputTokenCount  = "X-Amzn-Bedrock-Input-Token-Count"
+	headerOutputTokenCount = "X-Amzn-Bedrock-Output-Token-Count"
+)
+
+// UsageEvent はモデル呼び出し1回分の使用量
+type UsageEvent struct {
+	Model        string // カタログ名 (テキスト生成) またはレジストリ名 (Embedding)
+	ModelID      string // BedrockのモデルID
+	Operation    string
+	InputTokens  int
+	OutputTokens int
+}
+
+// UsageRecorder はモデル呼び出しの使用量を記録する
+// リクエストの識別子やテナントは ctx から取得する想定のため、ここでは受け取らない
+type UsageRecorder interface {
+	RecordUsage(ctx context.Context, event UsageEvent)
+}
+
+// UsageGuard はモデルを呼び出す前に利用上限を確認する
+// 上限を超えている場合はエラーを返し、モデルは呼び出さない
+type UsageGuard interface {
+	CheckUsage(ctx context.Context) error
+}
+
+// checkUsage は guard が設定されていれば利用上限を確認する
+func checkUsage(ctx context.Context, guard UsageGuard) error {
+	if guard == nil {
+		return nil
+	}
+	return guard.CheckUsage(ctx)
+}
+
+// recordUsage は recorder が設定されていれば使用量を記録する
+func recordUsage(ctx context.Context, recorder UsageRecorder, event UsageEvent) {
+	if recorder == nil {
+		return
+	}
+	recorder.RecordUsage(ctx, event)
+}
+
+// tokenCountsFromMetadata はInvokeModelのレスポンスヘッダーから入出力のトークン数を取得する
+// ヘッダーが含まれない場合は0を返す
+func tokenCountsFromMetadata(metadata middleware.Metadata) (input, output int) {
+	response, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response)
+	if !ok || response == nil {
+		return 0, 0
+	}
+	input, _ = strconv.Atoi(response.Header.Get(headerInputTokenCount))
+	output, _ = strconv.Atoi(response.Header.Get(headerOutputTokenCount))
+	return input, output
+}
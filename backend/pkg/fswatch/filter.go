@@ -0,0 +1,101 @@
+package fswatch
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Filter は取り込むファイルと除外するパスをグロブで指定する
+//
+// パターンの書式は path.Match に加えて、任意の深さのディレクトリに一致する ** を使える
+//   - / を含まないパターンはファイル名・ディレクトリ名に一致する (例: *.md, .git)
+//   - / を含むパターンはルートからの相対パスに一致する (例: docs/**/*.md, /drafts)
+type Filter struct {
+	include [][]string
+	exclude [][]string
+}
+
+// NewFilter は新しいFilterを生成する
+// include が空の場合はすべてのファイルを対象とする。exclude はファイルとディレクトリの両方に適用する
+func NewFilter(include, exclude []string) (*Filter, error) {
+	f := &Filter{}
+	for _, p := range include {
+		segments, err := compilePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		f.include = append(f.include, segments)
+	}
+	for _, p := range exclude {
+		segments, err := compilePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		f.exclude = append(f.exclude, segments)
+	}
+	return f, nil
+}
+
+// Ignore は対象外のパスかどうかを返す (IgnoreFunc として使う)
+// include はファイルにのみ適用し、ディレクトリは除外されていない限り走査する
+func (f *Filter) Ignore(rel string, isDir bool) bool {
+	parts := strings.Split(rel, "/")
+	for _, p := range f.exclude {
+		if matchPattern(p, parts) {
+			return true
+		}
+	}
+	if isDir || len(f.include) == 0 {
+		return false
+	}
+	for _, p := range f.include {
+		if matchPattern(p, parts) {
+			return false
+		}
+	}
+	return true
+}
+
+// compilePattern はパターンをパスの要素ごとに分割する
+// / を含まないパターンは任意の深さの名前に一致するよう、先頭に ** を補う
+func compilePattern(pattern string) ([]string, error) {
+	p := strings.TrimSuffix(pattern, "/")
+	var segments []string
+	if strings.Contains(p, "/") {
+		segments = strings.Split(strings.TrimPrefix(p, "/"), "/")
+	} else {
+		segments = []string{"**", p}
+	}
+	for _, s := range segments {
+		if s == "" {
+			return nil, fmt.Errorf("invalid glob pattern %q: empty path element", pattern)
+		}
+		if _, err := path.Match(s, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+	return segments, nil
+}
+
+// matchPattern はパスの要素がパターンに一致するかどうかを返す
+func matchPattern(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchPattern(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchPattern(pattern[1:], parts[1:])
+}
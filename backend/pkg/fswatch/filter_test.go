@@ -0,0 +1,52 @@
+package fswatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_Ignore(t *testing.T) {
+	f, err := NewFilter([]string{"*.md", "docs/**/*.txt"}, []string{".*", "node_modules", "/drafts", "*~"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{name: "任意の深さのファイル名に一致する", rel: "a/b/readme.md", want: false},
+		{name: "ルートからのパスに一致する", rel: "docs/leave.txt", want: false},
+		{name: "** は複数の階層に一致する", rel: "docs/hr/2026/leave.txt", want: false},
+		{name: "include に一致しないファイルは除外する", rel: "notes/leave.txt", want: true},
+		{name: "include はディレクトリに適用しない", rel: "notes", isDir: true, want: false},
+		{name: "隠しファイルを除外する", rel: "docs/.leave.md", want: true},
+		{name: "隠しディレクトリを除外する", rel: "a/.git", isDir: true, want: true},
+		{name: "名前のパターンで除外する", rel: "web/node_modules", isDir: true, want: true},
+		{name: "/ で始まるパターンはルート直下のみ", rel: "drafts", isDir: true, want: true},
+		{name: "/ で始まるパターンは下の階層に一致しない", rel: "docs/drafts", isDir: true, want: false},
+		{name: "エディタの一時ファイルを除外する", rel: "readme.md~", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, f.Ignore(tt.rel, tt.isDir))
+		})
+	}
+
+	t.Run("include が空の場合はすべてのファイルが対象", func(t *testing.T) {
+		f, err := NewFilter(nil, nil)
+		require.NoError(t, err)
+
+		assert.False(t, f.Ignore("a/b.bin", false))
+	})
+
+	t.Run("不正なパターン", func(t *testing.T) {
+		_, err := NewFilter([]string{"[a-"}, nil)
+		assert.Error(t, err)
+
+		_, err = NewFilter(nil, []string{"docs//a"})
+		assert.Error(t, err)
+	})
+}
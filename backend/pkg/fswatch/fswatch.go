@@ -0,0 +1,113 @@
+// Package fswatch はディレクトリ配下のファイルの変更を監視する
+// Linux では inotify を使い、使えない環境 (他のOSや監視数の上限に達した場合) ではディレクトリを定期的に走査する
+package fswatch
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrUnsupported は inotify を使えない環境の場合のエラー
+var ErrUnsupported = errors.New("inotify is not supported on this platform")
+
+// eventBufferSize は通知のバッファの大きさ
+// バッファが一杯の場合は通知を捨てる (未処理の通知が残っているため、受け取る側は変更を取りこぼさない)
+const eventBufferSize = 256
+
+// IgnoreFunc は監視・走査しないパスかどうかを判定する
+// rel はルートからの相対パス (区切りは /)。ディレクトリを除外した場合は配下のすべてを除外する
+type IgnoreFunc func(rel string, isDir bool) bool
+
+// Watcher はディレクトリ配下の変更を通知する
+type Watcher interface {
+	// Events は変更されたパス (ルートからの相対パス) を通知する
+	// 変更を取りこぼした可能性がある場合 (inotify のキューがあふれた場合など) は空文字列を通知する
+	Events() <-chan string
+	// Close は監視を終了する
+	Close() error
+}
+
+// Walk はルート配下の通常のファイルを、ルートからの相対パスのバイト順に走査する (シンボリックリンクはたどらない)
+// startAfter 以前のパスは読み飛ばすため、前回の続きから走査できる。fn が fs.SkipAll を返すと走査を終了する
+// 走査中に削除されたファイル・ディレクトリは無視する
+func Walk(root, startAfter string, ignore IgnoreFunc, fn func(rel string, info fs.FileInfo) error) error {
+	err := walkDir(root, "", startAfter, ignore, fn)
+	if errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walkDir はディレクトリ1つ分を走査する
+// ディレクトリは名前に / を付けて並べると、配下のパスを含めた全体がバイト順になる
+func walkDir(root, dir, startAfter string, ignore IgnoreFunc, fn func(rel string, info fs.FileInfo) error) error {
+	entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(dir)))
+	if err != nil {
+		if dir != "" && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	type child struct {
+		rel   string
+		order string
+		entry fs.DirEntry
+	}
+	children := make([]child, len(entries))
+	for i, e := range entries {
+		rel := path.Join(dir, e.Name())
+		order := rel
+		if e.IsDir() {
+			order += "/"
+		}
+		children[i] = child{rel: rel, order: order, entry: e}
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].order < children[j].order })
+
+	for _, c := range children {
+		if c.entry.IsDir() {
+			// 配下のすべてのパスが startAfter 以前のディレクトリは読み飛ばす
+			if c.order < startAfter && !strings.HasPrefix(startAfter, c.order) {
+				continue
+			}
+			if ignore != nil && ignore(c.rel, true) {
+				continue
+			}
+			if err := walkDir(root, c.rel, startAfter, ignore, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if c.rel <= startAfter || !c.entry.Type().IsRegular() {
+			continue
+		}
+		if ignore != nil && ignore(c.rel, false) {
+			continue
+		}
+		info, err := c.entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		if err := fn(c.rel, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notify は通知をバッファに入れる (一杯の場合は捨てる)
+func notify(events chan<- string, rel string) {
+	select {
+	case events <- rel:
+	default:
+	}
+}
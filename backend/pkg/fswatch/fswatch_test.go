@@ -0,0 +1,90 @@
+package fswatch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile はテスト用のファイルを作成する (ディレクトリも作成する)
+func writeFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	p := filepath.Join(root, filepath.FromSlash(rel))
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+	require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+}
+
+// waitEvent は指定したパスの通知を待つ (他のパスの通知は読み飛ばす)
+func waitEvent(t *testing.T, w Watcher, rel string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case got := <-w.Events():
+			if got == rel {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no event for %s", rel)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	root := t.TempDir()
+	for _, rel := range []string{"a.txt", "a/b.txt", "a/c/d.txt", "a-b.txt", "b.md", ".git/config"} {
+		writeFile(t, root, rel, rel)
+	}
+	require.NoError(t, os.Symlink(filepath.Join(root, "a.txt"), filepath.Join(root, "link.txt")))
+	ignore := func(rel string, isDir bool) bool { return rel == ".git" }
+
+	walk := func(startAfter string, limit int) []string {
+		var files []string
+		err := Walk(root, startAfter, ignore, func(rel string, info fs.FileInfo) error {
+			if len(files) == limit {
+				return fs.SkipAll
+			}
+			files = append(files, rel)
+			return nil
+		})
+		require.NoError(t, err)
+		return files
+	}
+
+	t.Run("相対パスのバイト順に走査する", func(t *testing.T) {
+		assert.Equal(t, []string{"a-b.txt", "a.txt", "a/b.txt", "a/c/d.txt", "b.md"}, walk("", -1))
+	})
+
+	t.Run("startAfter より後から走査する", func(t *testing.T) {
+		assert.Equal(t, []string{"a/c/d.txt", "b.md"}, walk("a/b.txt", -1))
+		assert.Equal(t, []string{"b.md"}, walk("a/c/d.txt", -1))
+	})
+
+	t.Run("SkipAll で走査を終了する", func(t *testing.T) {
+		assert.Equal(t, []string{"a-b.txt", "a.txt"}, walk("", 2))
+	})
+}
+
+func TestPoller(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "a.txt", "a")
+	writeFile(t, root, "b.txt", "b")
+
+	w, err := NewPoller(root, 10*time.Millisecond, nil)
+	require.NoError(t, err)
+	defer w.Close()
+
+	writeFile(t, root, "docs/c.txt", "c")
+	waitEvent(t, w, "docs/c.txt")
+
+	require.NoError(t, os.Remove(filepath.Join(root, "b.txt")))
+	waitEvent(t, w, "b.txt")
+
+	writeFile(t, root, "a.txt", "changed")
+	waitEvent(t, w, "a.txt")
+}
@@ -0,0 +1,166 @@
+//go:build linux
+
+package fswatch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// inotifyMask は監視するイベント
+// 書き込みの途中 (IN_MODIFY) も通知し、連続した書き込みは受け取る側でまとめる
+const inotifyMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_ATTRIB |
+	syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// inotifyWatcher は inotify でディレクトリ配下を再帰的に監視する Watcher
+// inotify はディレクトリ単位の監視のため、サブディレクトリごとに監視を追加し、作成されたディレクトリにも追加する
+type inotifyWatcher struct {
+	root    string
+	ignore  IgnoreFunc
+	fd      int
+	file    *os.File // fd を読み込む (Fd を呼ぶとブロッキングに戻るため、監視の追加には fd を使う)
+	events  chan string
+	mu      sync.Mutex
+	watches map[int32]string // 監視記述子 → ディレクトリの相対パス (ルートは "")
+	wg      sync.WaitGroup
+}
+
+// NewInotify は inotify でルート配下を監視する Watcher を生成する
+// 監視数の上限 (fs.inotify.max_user_watches) に達した場合はエラーを返す
+func NewInotify(root string, ignore IgnoreFunc) (Watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize inotify: %w", err)
+	}
+	// ノンブロッキングの記述子を os.File にすると、読み込みがランタイムのポーラーで待機し Close で中断できる
+	w := &inotifyWatcher{
+		root:    root,
+		ignore:  ignore,
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		events:  make(chan string, eventBufferSize),
+		watches: make(map[int32]string),
+	}
+	if err := w.addTree(""); err != nil {
+		w.file.Close()
+		return nil, err
+	}
+	w.wg.Add(1)
+	go w.readLoop()
+	return w, nil
+}
+
+func (w *inotifyWatcher) Events() <-chan string {
+	return w.events
+}
+
+func (w *inotifyWatcher) Close() error {
+	err := w.file.Close()
+	w.wg.Wait()
+	return err
+}
+
+// addTree はディレクトリとその配下のディレクトリに監視を追加する
+func (w *inotifyWatcher) addTree(rel string) error {
+	return filepath.WalkDir(filepath.Join(w.root, filepath.FromSlash(rel)), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && rel != "" {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		r, err := filepath.Rel(w.root, p)
+		if err != nil {
+			return err
+		}
+		r = filepath.ToSlash(r)
+		if r == "." {
+			r = ""
+		} else if w.ignore != nil && w.ignore(r, true) {
+			return filepath.SkipDir
+		}
+
+		wd, err := syscall.InotifyAddWatch(w.fd, p, inotifyMask|syscall.IN_ONLYDIR)
+		if err != nil {
+			if errors.Is(err, syscall.ENOENT) && r != "" {
+				return nil
+			}
+			return fmt.Errorf("failed to watch %s: %w", p, err)
+		}
+		w.mu.Lock()
+		w.watches[int32(wd)] = r
+		w.mu.Unlock()
+		return nil
+	})
+}
+
+// readLoop は inotify のイベントを読み込み、変更されたパスを通知する
+func (w *inotifyWatcher) readLoop() {
+	defer w.wg.Done()
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			nameEnd := nameStart + int(event.Len)
+			offset = nameEnd
+			if nameEnd > n {
+				break
+			}
+			name := string(buf[nameStart:nameEnd])
+			for len(name) > 0 && name[len(name)-1] == 0 {
+				name = name[:len(name)-1]
+			}
+			w.handle(event.Wd, event.Mask, name)
+		}
+	}
+}
+
+// handle はイベント1件を処理する
+func (w *inotifyWatcher) handle(wd int32, mask uint32, name string) {
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		notify(w.events, "")
+		return
+	}
+
+	w.mu.Lock()
+	dir, ok := w.watches[wd]
+	if mask&syscall.IN_IGNORED != 0 {
+		delete(w.watches, wd)
+	}
+	w.mu.Unlock()
+	if !ok || mask&syscall.IN_IGNORED != 0 {
+		return
+	}
+
+	rel := dir
+	if name != "" {
+		rel = path.Join(dir, name)
+	}
+	isDir := mask&syscall.IN_ISDIR != 0
+	if rel != "" && w.ignore != nil && w.ignore(rel, isDir) {
+		return
+	}
+	// 作成・移動されたディレクトリにも監視を追加する (監視を追加する前に作られたファイルは受け取る側の走査で検出する)
+	if isDir && mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+		if err := w.addTree(rel); err != nil {
+			notify(w.events, "")
+		}
+	}
+	notify(w.events, rel)
+}
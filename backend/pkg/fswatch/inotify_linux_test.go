@@ -0,0 +1,68 @@
+//go:build linux
+
+package fswatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInotify(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "a.txt", "a")
+	writeFile(t, root, "docs/b.txt", "b")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0o755))
+
+	w, err := NewInotify(root, func(rel string, isDir bool) bool { return rel == ".git" || filepath.Ext(rel) == ".swp" })
+	require.NoError(t, err)
+	defer w.Close()
+
+	t.Run("サブディレクトリのファイルの変更を通知する", func(t *testing.T) {
+		writeFile(t, root, "docs/b.txt", "changed")
+		waitEvent(t, w, "docs/b.txt")
+	})
+
+	t.Run("削除を通知する", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(root, "a.txt")))
+		waitEvent(t, w, "a.txt")
+	})
+
+	t.Run("作成されたディレクトリも監視する", func(t *testing.T) {
+		require.NoError(t, os.Mkdir(filepath.Join(root, "new"), 0o755))
+		waitEvent(t, w, "new")
+		writeFile(t, root, "new/c.txt", "c")
+		waitEvent(t, w, "new/c.txt")
+	})
+
+	t.Run("除外したパスは通知しない", func(t *testing.T) {
+		writeFile(t, root, ".git/HEAD", "ref")
+		writeFile(t, root, "docs/.b.txt.swp", "swap")
+		writeFile(t, root, "d.txt", "d")
+
+		var got []string
+		timeout := time.After(5 * time.Second)
+		for done := false; !done; {
+			select {
+			case rel := <-w.Events():
+				done = rel == "d.txt"
+				got = append(got, rel)
+			case <-timeout:
+				t.Fatal("no event for d.txt")
+			}
+		}
+		assert.NotContains(t, got, ".git/HEAD")
+		assert.NotContains(t, got, "docs/.b.txt.swp")
+	})
+}
+
+func TestInotify_Close(t *testing.T) {
+	w, err := NewInotify(t.TempDir(), nil)
+	require.NoError(t, err)
+
+	assert.NoError(t, w.Close())
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package fswatch
+
+// NewInotify は inotify を使えない環境では ErrUnsupported を返す (NewPoller を使う)
+func NewInotify(root string, ignore IgnoreFunc) (Watcher, error) {
+	return nil, ErrUnsupported
+}
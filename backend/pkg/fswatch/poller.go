@@ -0,0 +1,94 @@
+package fswatch
+
+import (
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// fileStamp はファイルの変更を検出するためのサイズと更新日時
+type fileStamp struct {
+	size    int64
+	modTime int64 // UnixNano
+}
+
+// poller はディレクトリを定期的に走査し、前回の走査との差分を通知する Watcher
+type poller struct {
+	root     string
+	ignore   IgnoreFunc
+	interval time.Duration
+	events   chan string
+	done     chan struct{}
+	once     sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewPoller はディレクトリを interval ごとに走査して変更を検出する Watcher を生成する
+// 生成時点のファイルを基準とし、それ以降の追加・変更・削除を通知する
+func NewPoller(root string, interval time.Duration, ignore IgnoreFunc) (Watcher, error) {
+	snapshot, err := scan(root, ignore)
+	if err != nil {
+		return nil, err
+	}
+	p := &poller{
+		root:     root,
+		ignore:   ignore,
+		interval: interval,
+		events:   make(chan string, eventBufferSize),
+		done:     make(chan struct{}),
+	}
+	p.wg.Add(1)
+	go p.loop(snapshot)
+	return p, nil
+}
+
+func (p *poller) Events() <-chan string {
+	return p.events
+}
+
+func (p *poller) Close() error {
+	p.once.Do(func() { close(p.done) })
+	p.wg.Wait()
+	return nil
+}
+
+func (p *poller) loop(snapshot map[string]fileStamp) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+
+		current, err := scan(p.root, p.ignore)
+		if err != nil {
+			// ルートを一時的に読めない場合は取りこぼした可能性があるとして通知し、次の走査で差分を取り直す
+			notify(p.events, "")
+			continue
+		}
+		for rel, stamp := range current {
+			if prev, ok := snapshot[rel]; !ok || prev != stamp {
+				notify(p.events, rel)
+			}
+		}
+		for rel := range snapshot {
+			if _, ok := current[rel]; !ok {
+				notify(p.events, rel)
+			}
+		}
+		snapshot = current
+	}
+}
+
+// scan はルート配下のファイルのサイズと更新日時を取得する
+func scan(root string, ignore IgnoreFunc) (map[string]fileStamp, error) {
+	files := make(map[string]fileStamp)
+	err := Walk(root, "", ignore, func(rel string, info fs.FileInfo) error {
+		files[rel] = fileStamp{size: info.Size(), modTime: info.ModTime().UnixNano()}
+		return nil
+	})
+	return files, err
+}
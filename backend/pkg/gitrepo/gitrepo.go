@@ -0,0 +1,168 @@
+// Package gitrepo はgitリポジトリのコミット・ツリー・ファイルを読み出す
+// git コマンドを実行して操作するため、実行環境に git が必要
+package gitrepo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotRepository はディレクトリがgitリポジトリではない場合のエラー
+var ErrNotRepository = errors.New("gitリポジトリではありません")
+
+// ErrUnknownRevision はコミット・ブランチ・タグが見つからない場合のエラー
+var ErrUnknownRevision = errors.New("リビジョンが見つかりません")
+
+// Repo はローカルのgitリポジトリ (作業ツリーのあるリポジトリ、またはベアリポジトリ)
+type Repo struct {
+	dir string
+}
+
+// Change はコミット間で変更されたファイル
+type Change struct {
+	Path    string // リポジトリのルートからの相対パス (区切りは /)
+	Deleted bool   // 削除されたかどうか (追加・変更の場合は false)
+}
+
+// Open はローカルのgitリポジトリを開く
+func Open(ctx context.Context, dir string) (*Repo, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	r := &Repo{dir: abs}
+	if _, err := r.git(ctx, "rev-parse", "--git-dir"); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrNotRepository, abs, err)
+	}
+	return r, nil
+}
+
+// Mirror はリモートのリポジトリのミラーを dir に作成して開く
+// dir に作成済みのミラーがある場合は、リモートのブランチとタグを取得して最新にする
+func Mirror(ctx context.Context, remote, dir string) (*Repo, error) {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		r, err := Open(ctx, dir)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := r.git(ctx, "fetch", "--prune", "--quiet", "origin"); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return nil, err
+	}
+	if _, err := (&Repo{}).git(ctx, "clone", "--mirror", "--quiet", "--", remote, dir); err != nil {
+		return nil, err
+	}
+	return Open(ctx, dir)
+}
+
+// Dir はリポジトリのディレクトリを返す
+func (r *Repo) Dir() string {
+	return r.dir
+}
+
+// Resolve はブランチ・タグ・コミットを指すリビジョンをコミットのSHAに解決する
+func (r *Repo) Resolve(ctx context.Context, rev string) (string, error) {
+	out, err := r.git(ctx, "rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownRevision, rev)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// HasCommit はリポジトリにコミットがあるかどうかを返す (強制プッシュや gc で失われたコミットは false)
+func (r *Repo) HasCommit(ctx context.Context, sha string) bool {
+	_, err := r.git(ctx, "cat-file", "-e", sha+"^{commit}")
+	return err == nil
+}
+
+// CommitTime はコミットの日時 (コミッターの日時) を返す
+func (r *Repo) CommitTime(ctx context.Context, sha string) (time.Time, error) {
+	out, err := r.git(ctx, "show", "-s", "--format=%cI", sha)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, strings.TrimSpace(string(out)))
+}
+
+// ListFiles はコミットのツリーにあるファイルのパスを返す (パスのバイト順)
+// サブモジュールとシンボリックリンクは含めない
+func (r *Repo) ListFiles(ctx context.Context, sha string) ([]string, error) {
+	out, err := r.git(ctx, "ls-tree", "-r", "-z", sha)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range splitNUL(out) {
+		// <mode> SP <type> SP <object> TAB <path>
+		meta, p, ok := strings.Cut(entry, "\t")
+		if !ok {
+			return nil, fmt.Errorf("git ls-tree の出力を解釈できません: %q", entry)
+		}
+		if mode, _, _ := strings.Cut(meta, " "); mode == "100644" || mode == "100755" {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// Diff は from から to までに追加・変更・削除されたファイルを返す (パスのバイト順)
+// 名前の変更は、変更前のパスの削除と変更後のパスの追加として返す
+func (r *Repo) Diff(ctx context.Context, from, to string) ([]Change, error) {
+	out, err := r.git(ctx, "diff", "--name-status", "-z", "--no-renames", "--no-ext-diff", from, to)
+	if err != nil {
+		return nil, err
+	}
+	fields := splitNUL(out)
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("git diff の出力を解釈できません")
+	}
+	changes := make([]Change, 0, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		changes = append(changes, Change{Path: fields[i+1], Deleted: fields[i] == "D"})
+	}
+	return changes, nil
+}
+
+// ReadFile はコミットのツリーにあるファイルの内容を返す
+func (r *Repo) ReadFile(ctx context.Context, sha, path string) ([]byte, error) {
+	return r.git(ctx, "cat-file", "blob", sha+":"+path)
+}
+
+// git はリポジトリのディレクトリで git コマンドを実行し、標準出力を返す
+// 認証情報の入力を求めて止まらないよう、端末からの入力は無効にする
+func (r *Repo) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("git %s に失敗しました: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// splitNUL は -z の出力をNUL文字で区切る
+func splitNUL(out []byte) []string {
+	s := strings.TrimSuffix(string(out), "\x00")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\x00")
+}
@@ -0,0 +1,154 @@
+package gitrepo
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRepo はテスト用の作業ツリーのあるリポジトリを作成する
+func testRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git がインストールされていません")
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet", "--initial-branch=main")
+	return dir
+}
+
+// commitFiles はファイルを書き込み (内容が空のファイルは削除し)、コミットしてSHAを返す
+func commitFiles(t *testing.T, dir string, at time.Time, files map[string]string) string {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if content == "" {
+			require.NoError(t, os.Remove(p))
+			continue
+		}
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	runGit(t, dir, "add", "-A")
+	cmd := exec.Command("git", "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "update")
+	cmd.Dir = dir
+	date := at.Format(time.RFC3339)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return string(out)
+}
+
+func TestRepo(t *testing.T) {
+	ctx := context.Background()
+	dir := testRepo(t)
+	at := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	first := commitFiles(t, dir, at, map[string]string{
+		"README.md":        "# handbook\n",
+		"docs/leave.md":    "休暇\n",
+		"docs/travel.md":   "出張\n",
+		"docs/日本語 ファイル.md": "名前\n",
+	})
+	second := commitFiles(t, dir, at.Add(time.Hour), map[string]string{
+		"docs/leave.md":  "休暇 (改訂)\n",
+		"docs/travel.md": "",
+		"docs/new.adoc":  "= 新規\n",
+	})
+
+	r, err := Open(ctx, dir)
+	require.NoError(t, err)
+
+	t.Run("正常系: ブランチをコミットのSHAに解決する", func(t *testing.T) {
+		sha, err := r.Resolve(ctx, "main")
+
+		require.NoError(t, err)
+		assert.Equal(t, second, sha)
+	})
+
+	t.Run("異常系: 存在しないブランチ", func(t *testing.T) {
+		_, err := r.Resolve(ctx, "no-such-branch")
+
+		assert.ErrorIs(t, err, ErrUnknownRevision)
+	})
+
+	t.Run("正常系: コミットの有無", func(t *testing.T) {
+		assert.True(t, r.HasCommit(ctx, first))
+		assert.False(t, r.HasCommit(ctx, strings.Repeat("0", 40)))
+	})
+
+	t.Run("正常系: コミットの日時", func(t *testing.T) {
+		committedAt, err := r.CommitTime(ctx, second)
+
+		require.NoError(t, err)
+		assert.True(t, at.Add(time.Hour).Equal(committedAt))
+	})
+
+	t.Run("正常系: ツリーのファイルを一覧する", func(t *testing.T) {
+		paths, err := r.ListFiles(ctx, first)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"README.md", "docs/leave.md", "docs/travel.md", "docs/日本語 ファイル.md"}, paths)
+	})
+
+	t.Run("正常系: コミット間の変更", func(t *testing.T) {
+		changes, err := r.Diff(ctx, first, second)
+
+		require.NoError(t, err)
+		assert.Equal(t, []Change{
+			{Path: "docs/leave.md"},
+			{Path: "docs/new.adoc"},
+			{Path: "docs/travel.md", Deleted: true},
+		}, changes)
+	})
+
+	t.Run("正常系: コミット時点のファイルを読み込む", func(t *testing.T) {
+		content, err := r.ReadFile(ctx, first, "docs/leave.md")
+
+		require.NoError(t, err)
+		assert.Equal(t, "休暇\n", string(content))
+	})
+
+	t.Run("異常系: gitリポジトリではない", func(t *testing.T) {
+		_, err := Open(ctx, t.TempDir())
+
+		assert.ErrorIs(t, err, ErrNotRepository)
+	})
+}
+
+func TestMirror(t *testing.T) {
+	ctx := context.Background()
+	remote := testRepo(t)
+	at := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	first := commitFiles(t, remote, at, map[string]string{"a.md": "a\n"})
+	dir := filepath.Join(t.TempDir(), "mirror", "handbook.git")
+
+	r, err := Mirror(ctx, remote, dir)
+	require.NoError(t, err)
+	sha, err := r.Resolve(ctx, "main")
+	require.NoError(t, err)
+	assert.Equal(t, first, sha)
+
+	// 2回目はリモートの変更を取得する
+	second := commitFiles(t, remote, at.Add(time.Hour), map[string]string{"b.md": "b\n"})
+	r, err = Mirror(ctx, remote, dir)
+	require.NoError(t, err)
+	sha, err = r.Resolve(ctx, "main")
+	require.NoError(t, err)
+	assert.Equal(t, second, sha)
+}
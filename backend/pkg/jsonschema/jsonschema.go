@@ -0,0 +1,436 @@
+// Package jsonschema はJSON Schemaのサブセットで値を検証する
+// 対応するキーワード: type・properties・required・additionalProperties・items・enum・const・
+// minimum・maximum・exclusiveMinimum・exclusiveMaximum・minLength・maxLength・pattern・minItems・maxItems・format (date・date-time・email)
+// $ref・anyOf・oneOf・allOf・not には対応しない (含むスキーマはコンパイルできない)
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalidSchema はスキーマを解釈できない場合のエラー
+var ErrInvalidSchema = errors.New("JSON Schemaが不正です")
+
+// 値の型 (type キーワードの値)
+var knownTypes = map[string]bool{
+	"null": true, "boolean": true, "integer": true, "number": true, "string": true, "array": true, "object": true,
+}
+
+// unsupportedKeywords は対応しない (検証を省略すると結果が変わる) キーワード
+var unsupportedKeywords = []string{"$ref", "anyOf", "oneOf", "allOf", "not", "if", "patternProperties", "dependentRequired", "dependentSchemas"}
+
+// Schema はコンパイルしたJSON Schema
+type Schema struct {
+	types                []string
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema // nil の場合は制限しない
+	noAdditional         bool    // additionalProperties: false
+	items                *Schema
+	enum                 []any
+	constant             *any
+	minimum              *float64
+	maximum              *float64
+	exclusiveMinimum     *float64
+	exclusiveMaximum     *float64
+	minLength            *int
+	maxLength            *int
+	pattern              *regexp.Regexp
+	minItems             *int
+	maxItems             *int
+	format               string
+}
+
+// ValidationError は値がスキーマに適合しない箇所
+type ValidationError struct {
+	Path    string // 値の位置を示すJSON Pointer (ルートは空)
+	Message string
+}
+
+// Error は位置とメッセージを返す
+func (e ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "(ルート)"
+	}
+	return path + ": " + e.Message
+}
+
+// Compile はJSON Schemaを解釈する
+func Compile(raw []byte) (*Schema, error) {
+	var doc any
+	if err := decode(raw, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	s, err := compile(doc, "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	return s, nil
+}
+
+func compile(doc any, at string) (*Schema, error) {
+	// true は任意の値を許可するスキーマとして扱う (false は許可する値がないため受け付けない)
+	if b, ok := doc.(bool); ok && b {
+		return &Schema{}, nil
+	}
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: スキーマはオブジェクトで指定してください", pointerOrRoot(at))
+	}
+	for _, keyword := range unsupportedKeywords {
+		if _, found := m[keyword]; found {
+			return nil, fmt.Errorf("%s: %s には対応していません", pointerOrRoot(at), keyword)
+		}
+	}
+
+	s := &Schema{}
+	var err error
+	if v, found := m["type"]; found {
+		if s.types, err = compileTypes(v); err != nil {
+			return nil, fmt.Errorf("%s: %v", pointerOrRoot(at), err)
+		}
+	}
+	if v, found := m["properties"]; found {
+		props, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: properties はオブジェクトで指定してください", pointerOrRoot(at))
+		}
+		s.properties = make(map[string]*Schema, len(props))
+		for name, prop := range props {
+			if s.properties[name], err = compile(prop, at+"/properties/"+escape(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if v, found := m["required"]; found {
+		names, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: required は文字列の配列で指定してください", pointerOrRoot(at))
+		}
+		for _, name := range names {
+			str, ok := name.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: required は文字列の配列で指定してください", pointerOrRoot(at))
+			}
+			s.required = append(s.required, str)
+		}
+	}
+	if v, found := m["additionalProperties"]; found {
+		if b, ok := v.(bool); ok {
+			s.noAdditional = !b
+		} else if s.additionalProperties, err = compile(v, at+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+	if v, found := m["items"]; found {
+		if s.items, err = compile(v, at+"/items"); err != nil {
+			return nil, err
+		}
+	}
+	if v, found := m["enum"]; found {
+		values, ok := v.([]any)
+		if !ok || len(values) == 0 {
+			return nil, fmt.Errorf("%s: enum は空ではない配列で指定してください", pointerOrRoot(at))
+		}
+		s.enum = values
+	}
+	if v, found := m["const"]; found {
+		s.constant = &v
+	}
+	for keyword, dst := range map[string]**float64{
+		"minimum": &s.minimum, "maximum": &s.maximum, "exclusiveMinimum": &s.exclusiveMinimum, "exclusiveMaximum": &s.exclusiveMaximum,
+	} {
+		if v, found := m[keyword]; found {
+			n, ok := v.(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("%s: %s は数値で指定してください", pointerOrRoot(at), keyword)
+			}
+			f, _ := n.Float64()
+			*dst = &f
+		}
+	}
+	for keyword, dst := range map[string]**int{
+		"minLength": &s.minLength, "maxLength": &s.maxLength, "minItems": &s.minItems, "maxItems": &s.maxItems,
+	} {
+		if v, found := m[keyword]; found {
+			n, ok := v.(json.Number)
+			i, err := strconv.Atoi(string(n))
+			if !ok || err != nil || i < 0 {
+				return nil, fmt.Errorf("%s: %s は0以上の整数で指定してください", pointerOrRoot(at), keyword)
+			}
+			*dst = &i
+		}
+	}
+	if v, found := m["pattern"]; found {
+		str, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: pattern は文字列で指定してください", pointerOrRoot(at))
+		}
+		if s.pattern, err = regexp.Compile(str); err != nil {
+			return nil, fmt.Errorf("%s: pattern を解釈できません: %v", pointerOrRoot(at), err)
+		}
+	}
+	if v, found := m["format"]; found {
+		// 対応しない形式は、仕様どおり注釈として扱い検証しない
+		s.format, _ = v.(string)
+	}
+	return s, nil
+}
+
+func compileTypes(v any) ([]string, error) {
+	var types []string
+	switch t := v.(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, e := range t {
+			str, ok := e.(string)
+			if !ok {
+				return nil, errors.New("type は文字列または文字列の配列で指定してください")
+			}
+			types = append(types, str)
+		}
+	default:
+		return nil, errors.New("type は文字列または文字列の配列で指定してください")
+	}
+	for _, t := range types {
+		if !knownTypes[t] {
+			return nil, fmt.Errorf("不明な型です: %s", t)
+		}
+	}
+	return types, nil
+}
+
+// Validate はJSONの値を検証し、スキーマに適合しない箇所を返す (適合する場合は空)
+// JSONとして解釈できない場合はエラーを返す
+func (s *Schema) Validate(raw []byte) ([]ValidationError, error) {
+	var v any
+	if err := decode(raw, &v); err != nil {
+		return nil, err
+	}
+	var errs []ValidationError
+	s.validate(v, "", &errs)
+	return errs, nil
+}
+
+func (s *Schema) validate(v any, at string, errs *[]ValidationError) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, ValidationError{Path: at, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.types) > 0 && !matchesType(v, s.types) {
+		fail("型が %s ではありません (%s)", strings.Join(s.types, " または "), typeOf(v))
+		return
+	}
+	if s.enum != nil && !containsValue(s.enum, v) {
+		fail("値が列挙された値のいずれでもありません")
+	}
+	if s.constant != nil && !equal(*s.constant, v) {
+		fail("値が const と一致しません")
+	}
+
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		if s.minimum != nil && f < *s.minimum {
+			fail("%v 以上である必要があります", *s.minimum)
+		}
+		if s.maximum != nil && f > *s.maximum {
+			fail("%v 以下である必要があります", *s.maximum)
+		}
+		if s.exclusiveMinimum != nil && f <= *s.exclusiveMinimum {
+			fail("%v より大きい必要があります", *s.exclusiveMinimum)
+		}
+		if s.exclusiveMaximum != nil && f >= *s.exclusiveMaximum {
+			fail("%v より小さい必要があります", *s.exclusiveMaximum)
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.minLength != nil && n < *s.minLength {
+			fail("%d 文字以上である必要があります", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			fail("%d 文字以下である必要があります", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("パターン %s に一致しません", s.pattern)
+		}
+		if msg := checkFormat(s.format, v); msg != "" {
+			fail("%s", msg)
+		}
+	case []any:
+		if s.minItems != nil && len(v) < *s.minItems {
+			fail("要素が %d 個以上である必要があります", *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			fail("要素が %d 個以下である必要があります", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				s.items.validate(item, at+"/"+strconv.Itoa(i), errs)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.required {
+			if _, found := v[name]; !found {
+				*errs = append(*errs, ValidationError{Path: at + "/" + escape(name), Message: "必須のプロパティがありません"})
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := at + "/" + escape(name)
+			if prop, found := s.properties[name]; found {
+				prop.validate(v[name], child, errs)
+				continue
+			}
+			switch {
+			case s.noAdditional:
+				*errs = append(*errs, ValidationError{Path: child, Message: "スキーマにないプロパティです"})
+			case s.additionalProperties != nil:
+				s.additionalProperties.validate(v[name], child, errs)
+			}
+		}
+	}
+}
+
+// checkFormat は format に対応する形式の文字列かどうかを検証し、適合しない場合はメッセージを返す
+func checkFormat(format, v string) string {
+	var err error
+	switch format {
+	case "date":
+		_, err = time.Parse(time.DateOnly, v)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, v)
+	case "email":
+		var addr *mail.Address
+		if addr, err = mail.ParseAddress(v); err == nil && addr.Address != v {
+			err = errors.New("not a bare address")
+		}
+	default:
+		return ""
+	}
+	if err != nil {
+		return "形式が " + format + " ではありません"
+	}
+	return ""
+}
+
+// typeOf はJSONの値の型を返す (整数の値は integer)
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func matchesType(v any, types []string) bool {
+	actual := typeOf(v)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsValue(values []any, v any) bool {
+	for _, e := range values {
+		if equal(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal はJSONの値が等しいかどうかを返す (数値は値で比較する)
+func equal(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		bn, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		af, _ := a.Float64()
+		bf, _ := bn.Float64()
+		return af == bf
+	case []any:
+		bs, ok := b.([]any)
+		if !ok || len(a) != len(bs) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], bs[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		bm, ok := b.(map[string]any)
+		if !ok || len(a) != len(bm) {
+			return false
+		}
+		for k, av := range a {
+			bv, found := bm[k]
+			if !found || !equal(av, bv) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// decode は数値を json.Number として1つのJSONの値を読み込む
+func decode(raw []byte, v *any) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("JSONの値の後に余分なデータがあります")
+	}
+	return nil
+}
+
+// escape はプロパティ名をJSON Pointerの参照トークンにする
+func escape(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+func pointerOrRoot(at string) string {
+	if at == "" {
+		return "(ルート)"
+	}
+	return at
+}
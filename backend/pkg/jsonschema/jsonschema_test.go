@@ -0,0 +1,130 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const invoiceSchema = `{
+	"type": "object",
+	"properties": {
+		"invoice_number": {"type": "string", "pattern": "^INV-[0-9]+$"},
+		"issued_on": {"type": "string", "format": "date"},
+		"total": {"type": "integer", "minimum": 0},
+		"currency": {"enum": ["JPY", "USD"]},
+		"vendor": {
+			"type": "object",
+			"properties": {"name": {"type": "string", "minLength": 1}},
+			"required": ["name"],
+			"additionalProperties": false
+		},
+		"lines": {
+			"type": "array",
+			"minItems": 1,
+			"items": {"type": "object", "properties": {"amount": {"type": "number"}}, "required": ["amount"]}
+		},
+		"note": {"type": ["string", "null"], "maxLength": 5}
+	},
+	"required": ["invoice_number", "total"]
+}`
+
+func TestValidate(t *testing.T) {
+	schema, err := Compile([]byte(invoiceSchema))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		value string
+		want  []ValidationError
+	}{
+		{
+			name: "正常系: スキーマに適合する",
+			value: `{"invoice_number": "INV-001", "issued_on": "2026-10-01", "total": 10800, "currency": "JPY",
+				"vendor": {"name": "株式会社サンプル"}, "lines": [{"amount": 10000}, {"amount": 800.5}], "note": null}`,
+		},
+		{
+			name:  "異常系: 必須のプロパティがない",
+			value: `{"invoice_number": "INV-001"}`,
+			want:  []ValidationError{{Path: "/total", Message: "必須のプロパティがありません"}},
+		},
+		{
+			name:  "異常系: 型が異なる (小数は integer ではない)",
+			value: `{"invoice_number": "INV-001", "total": 10.5}`,
+			want:  []ValidationError{{Path: "/total", Message: "型が integer ではありません (number)"}},
+		},
+		{
+			name:  "異常系: パターン・日付の形式・列挙された値",
+			value: `{"invoice_number": "001", "issued_on": "2026/10/01", "total": 1, "currency": "EUR"}`,
+			want: []ValidationError{
+				{Path: "/currency", Message: "値が列挙された値のいずれでもありません"},
+				{Path: "/invoice_number", Message: "パターン ^INV-[0-9]+$ に一致しません"},
+				{Path: "/issued_on", Message: "形式が date ではありません"},
+			},
+		},
+		{
+			name:  "異常系: 入れ子のオブジェクトと配列の要素",
+			value: `{"invoice_number": "INV-1", "total": -1, "vendor": {"name": "", "tel": "03"}, "lines": [{"amount": "1"}, {}]}`,
+			want: []ValidationError{
+				{Path: "/lines/0/amount", Message: "型が number ではありません (string)"},
+				{Path: "/lines/1/amount", Message: "必須のプロパティがありません"},
+				{Path: "/total", Message: "0 以上である必要があります"},
+				{Path: "/vendor/name", Message: "1 文字以上である必要があります"},
+				{Path: "/vendor/tel", Message: "スキーマにないプロパティです"},
+			},
+		},
+		{
+			name:  "異常系: 文字数は文字単位で数える",
+			value: `{"invoice_number": "INV-1", "total": 0, "note": "日本語の備考"}`,
+			want:  []ValidationError{{Path: "/note", Message: "5 文字以下である必要があります"}},
+		},
+		{
+			name:  "異常系: ルートの型が異なる",
+			value: `[]`,
+			want:  []ValidationError{{Path: "", Message: "型が object ではありません (array)"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := schema.Validate([]byte(tt.value))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, errs)
+		})
+	}
+
+	t.Run("異常系: JSONではない", func(t *testing.T) {
+		_, err := schema.Validate([]byte(`{"total": `))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{name: "異常系: JSONではない", schema: `{"type": `},
+		{name: "異常系: オブジェクトではない", schema: `"object"`},
+		{name: "異常系: 不明な型", schema: `{"type": "date"}`},
+		{name: "異常系: 対応していないキーワード", schema: `{"properties": {"a": {"$ref": "#/$defs/a"}}}`},
+		{name: "異常系: 正規表現を解釈できない", schema: `{"pattern": "("}`},
+		{name: "異常系: 負の長さ", schema: `{"maxLength": -1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile([]byte(tt.schema))
+
+			assert.ErrorIs(t, err, ErrInvalidSchema)
+		})
+	}
+
+	t.Run("正常系: ValidationError の文字列", func(t *testing.T) {
+		assert.Equal(t, "(ルート): 型が object ではありません (array)", ValidationError{Message: "型が object ではありません (array)"}.Error())
+		assert.Equal(t, "/a~1b: 必須のプロパティがありません", ValidationError{Path: "/" + escape("a/b"), Message: "必須のプロパティがありません"}.Error())
+	})
+}
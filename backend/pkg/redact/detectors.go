@@ -0,0 +1,218 @@
+package redact
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 組み込みの検出器が返す個人情報の種類
+const (
+	TypePhone      = "phone"       // 電話番号 (固定・携帯・IP電話・フリーダイヤル・+81)
+	TypePostalCode = "postal_code" // 郵便番号
+	TypeMyNumber   = "my_number"   // 個人番号 (マイナンバー、チェックデジットを検証する)
+	TypeCreditCard = "credit_card" // クレジットカード番号 (Luhnのチェックを検証する)
+	TypeEmail      = "email"       // メールアドレス
+	TypeDictionary = "dictionary"  // 辞書で種類を指定しなかった語
+)
+
+// ErrUnknownDetector は不明な検出器を指定した場合のエラー
+var ErrUnknownDetector = errors.New("不明な個人情報の検出器です")
+
+// BuiltinTypes は組み込みの検出器の種類の一覧
+var BuiltinTypes = []string{TypePhone, TypePostalCode, TypeMyNumber, TypeCreditCard, TypeEmail}
+
+var (
+	phonePattern      = regexp.MustCompile(`\+81[ -]?\d{1,4}[ -]?\d{1,4}[ -]?\d{4}|\(0\d{1,4}\) ?\d{1,4}-\d{4}|0\d{1,4}-\d{1,4}-\d{4}|0[5789]0\d{8}|0120\d{6}`)
+	postalCodePattern = regexp.MustCompile(`〒 ?\d{3}-?\d{4}|\d{3}-\d{4}`)
+	myNumberPattern   = regexp.MustCompile(`\d{4}[ -]?\d{4}[ -]?\d{4}`)
+	creditCardPattern = regexp.MustCompile(`\d(?:[ -]?\d){12,18}`)
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+)
+
+// Builtin は組み込みの検出器を返す
+func Builtin(name string) (Detector, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case TypePhone:
+		return &patternDetector{typ: TypePhone, pattern: phonePattern, numeric: true, valid: validPhone}, nil
+	case TypePostalCode:
+		return &patternDetector{typ: TypePostalCode, pattern: postalCodePattern, numeric: true}, nil
+	case TypeMyNumber:
+		return &patternDetector{typ: TypeMyNumber, pattern: myNumberPattern, numeric: true, valid: validMyNumber}, nil
+	case TypeCreditCard:
+		return &patternDetector{typ: TypeCreditCard, pattern: creditCardPattern, numeric: true, valid: validCreditCard}, nil
+	case TypeEmail:
+		return &patternDetector{typ: TypeEmail, pattern: emailPattern}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownDetector, name)
+}
+
+// patternDetector は正規表現に一致し、検証を通過した箇所を検出する
+type patternDetector struct {
+	typ     string
+	pattern *regexp.Regexp
+	numeric bool              // true の場合は前後に数字・ハイフンが続く箇所を除く (長い番号の一部を誤検出しないため)
+	valid   func(string) bool // nil の場合は検証しない
+}
+
+func (d *patternDetector) Detect(text string) []Match {
+	var matches []Match
+	for _, loc := range d.pattern.FindAllStringIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if d.numeric && (start > 0 && isNumberPart(text[start-1]) || end < len(text) && isNumberPart(text[end])) {
+			continue
+		}
+		if d.valid != nil && !d.valid(text[start:end]) {
+			continue
+		}
+		matches = append(matches, Match{Type: d.typ, Start: start, End: end})
+	}
+	return matches
+}
+
+func isNumberPart(c byte) bool {
+	return c >= '0' && c <= '9' || c == '-'
+}
+
+// digits は文字列に含まれる数字だけを返す
+func digits(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// validPhone は国内の電話番号の桁数 (先頭の0を含めて10桁または11桁) であるかを返す
+func validPhone(s string) bool {
+	d := digits(s)
+	if strings.HasPrefix(s, "+81") {
+		d = "0" + strings.TrimPrefix(d, "81")
+	}
+	return len(d) == 10 || len(d) == 11
+}
+
+// validMyNumber は個人番号のチェックデジットを検証する
+func validMyNumber(s string) bool {
+	d := digits(s)
+	if len(d) != 12 {
+		return false
+	}
+	sum := 0
+	for n := 1; n <= 11; n++ {
+		p := int(d[11-n] - '0')
+		q := n + 1
+		if n >= 7 {
+			q = n - 5
+		}
+		sum += p * q
+	}
+	check := 11 - sum%11
+	if check >= 10 {
+		check = 0
+	}
+	return int(d[11]-'0') == check
+}
+
+// validCreditCard はクレジットカード番号の桁数とLuhnのチェックを検証する
+func validCreditCard(s string) bool {
+	d := digits(s)
+	if len(d) < 13 || len(d) > 19 {
+		return false
+	}
+	sum := 0
+	for i := range len(d) {
+		n := int(d[len(d)-1-i] - '0')
+		if i%2 == 1 {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+	}
+	return sum%10 == 0
+}
+
+// DictionaryEntry は辞書に登録する語
+type DictionaryEntry struct {
+	Type string // 個人情報の種類 (空の場合は dictionary)
+	Term string
+}
+
+// DictionaryDetector は辞書に登録した語 (氏名・取引先名など) を検出する
+type DictionaryDetector struct {
+	entries []DictionaryEntry
+}
+
+// NewDictionaryDetector は新しいDictionaryDetectorを作成する
+func NewDictionaryDetector(entries []DictionaryEntry) *DictionaryDetector {
+	normalized := make([]DictionaryEntry, 0, len(entries))
+	for _, e := range entries {
+		term, _ := normalize(strings.TrimSpace(e.Term))
+		if term == "" {
+			continue
+		}
+		typ := strings.ToLower(strings.TrimSpace(e.Type))
+		if typ == "" {
+			typ = TypeDictionary
+		}
+		normalized = append(normalized, DictionaryEntry{Type: typ, Term: term})
+	}
+	// 長い語を優先して検出する
+	sort.SliceStable(normalized, func(i, j int) bool {
+		return len(normalized[i].Term) > len(normalized[j].Term)
+	})
+	return &DictionaryDetector{entries: normalized}
+}
+
+// LoadDictionary は辞書のファイルを読み込む
+// 1行に1語を「語」または「種類<TAB>語」の形式で記述する (空行と # で始まる行は無視する)
+func LoadDictionary(path string) (*DictionaryDetector, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("辞書の読み込みに失敗しました: %w", err)
+	}
+	defer f.Close()
+
+	var entries []DictionaryEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if typ, term, ok := strings.Cut(line, "\t"); ok {
+			entries = append(entries, DictionaryEntry{Type: typ, Term: term})
+		} else {
+			entries = append(entries, DictionaryEntry{Term: line})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("辞書の読み込みに失敗しました: %w", err)
+	}
+	return NewDictionaryDetector(entries), nil
+}
+
+// Detect は辞書に登録した語の出現箇所を返す
+func (d *DictionaryDetector) Detect(text string) []Match {
+	var matches []Match
+	for _, e := range d.entries {
+		for offset := 0; offset < len(text); {
+			i := strings.Index(text[offset:], e.Term)
+			if i < 0 {
+				break
+			}
+			start := offset + i
+			matches = append(matches, Match{Type: e.Type, Start: start, End: start + len(e.Term)})
+			offset = start + len(e.Term)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,73 @@
+package redact
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltin(t *testing.T) {
+	tests := []struct {
+		name     string
+		detector string
+		text     string
+		want     []string
+	}{
+		{"正常系: 携帯電話", TypePhone, "TEL 090-1234-5678 まで", []string{"090-1234-5678"}},
+		{"正常系: 市外局番を括弧で囲んだ固定電話", TypePhone, "(03) 1234-5678", []string{"(03) 1234-5678"}},
+		{"正常系: 国際電話の形式", TypePhone, "+81 90 1234 5678", []string{"+81 90 1234 5678"}},
+		{"正常系: ハイフンのない携帯電話", TypePhone, "09012345678", []string{"09012345678"}},
+		{"正常系: 桁数が合わない番号は除く", TypePhone, "03-123-456", nil},
+		{"正常系: 郵便番号", TypePostalCode, "〒1000001 / 100-0001", []string{"〒1000001", "100-0001"}},
+		{"正常系: 長い番号の一部は郵便番号としない", TypePostalCode, "ABC-123-4567-89", nil},
+		{"正常系: 個人番号", TypeMyNumber, "番号: 1234 5678 9018", []string{"1234 5678 9018"}},
+		{"正常系: チェックデジットが合わない個人番号は除く", TypeMyNumber, "123456789012", nil},
+		{"正常系: クレジットカード番号", TypeCreditCard, "4111-1111-1111-1111", []string{"4111-1111-1111-1111"}},
+		{"正常系: Luhnのチェックに合わない番号は除く", TypeCreditCard, "4111111111111112", nil},
+		{"正常系: メールアドレス", TypeEmail, "連絡先 taro.yamada+rag@example.co.jp。", []string{"taro.yamada+rag@example.co.jp"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Builtin(tt.detector)
+			require.NoError(t, err)
+
+			var got []string
+			for _, m := range d.Detect(tt.text) {
+				assert.Equal(t, tt.detector, m.Type)
+				got = append(got, tt.text[m.Start:m.End])
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("異常系: 不明な検出器", func(t *testing.T) {
+		_, err := Builtin("address")
+
+		assert.ErrorIs(t, err, ErrUnknownDetector)
+	})
+}
+
+func TestLoadDictionary(t *testing.T) {
+	t.Run("正常系: 種類を指定した語と指定しない語を検出する", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dictionary.tsv")
+		require.NoError(t, os.WriteFile(path, []byte("# 顧客名\nperson\t山田 太郎\n株式会社サンプル\n\n"), 0o600))
+
+		d, err := LoadDictionary(path)
+		require.NoError(t, err)
+		result, err := New("", d).Redact("山田　太郎様 (株式会社サンプル) と山田 太郎様", ModeMask)
+
+		require.NoError(t, err)
+		assert.Equal(t, "[PERSON]様 ([DICTIONARY]) と[PERSON]様", result.Text)
+	})
+
+	t.Run("異常系: ファイルが存在しない", func(t *testing.T) {
+		_, err := LoadDictionary(filepath.Join(t.TempDir(), "missing.tsv"))
+
+		assert.Error(t, err)
+	})
+}
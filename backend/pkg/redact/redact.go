@@ -0,0 +1,231 @@
+// Package redact はテキストから個人情報を検出し、伏せ字 (mask)・ハッシュ (hash) に置き換えるか、含むテキストを拒否 (reject) する
+// 検出器は Detector を実装して追加できる (組み込みの正規表現の検出器と辞書の検出器を用意している)
+// 全角の英数字・記号とハイフンの異体字は半角に寄せてから検出するため、検出器は半角のテキストだけを考慮すればよい
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Mode は個人情報を検出した場合の扱い
+type Mode string
+
+const (
+	ModeOff    Mode = "off"    // 検出しない
+	ModeMask   Mode = "mask"   // 種類を示す伏せ字 (例: [PHONE]) に置き換える
+	ModeHash   Mode = "hash"   // 種類と値のハッシュ (例: [PHONE:1a2b3c4d5e6f]) に置き換える (同じ値は同じ文字列になる)
+	ModeReject Mode = "reject" // 個人情報を含むテキストを拒否する
+)
+
+// hashTokenLength は置き換えた文字列に含めるハッシュの桁数 (16進数)
+const hashTokenLength = 12
+
+var (
+	// ErrInvalidMode は扱いの指定が不正な場合のエラー
+	ErrInvalidMode = errors.New("個人情報の扱いは off / mask / hash / reject のいずれかを指定してください")
+	// ErrHashKeyRequired はハッシュの鍵を設定せずに hash を指定した場合のエラー
+	ErrHashKeyRequired = errors.New("hash を使用するにはハッシュの鍵を設定してください")
+	// ErrPIIDetected は reject の場合に個人情報を検出したことを表すエラー
+	ErrPIIDetected = errors.New("個人情報が含まれています")
+)
+
+// ParseMode は扱いの指定を解釈する
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case ModeOff, ModeMask, ModeHash, ModeReject:
+		return mode, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrInvalidMode, s)
+}
+
+// Match は検出器が見つけた個人情報の箇所
+type Match struct {
+	Type  string // 個人情報の種類 (例: phone)
+	Start int    // 検出器に渡したテキストでの開始位置 (バイト単位)
+	End   int    // 終了位置 (バイト単位、この位置を含まない)
+}
+
+// Detector は個人情報の検出器
+type Detector interface {
+	Detect(text string) []Match
+}
+
+// DetectorFunc は関数を検出器として使う
+type DetectorFunc func(text string) []Match
+
+// Detect は f(text) を返す
+func (f DetectorFunc) Detect(text string) []Match {
+	return f(text)
+}
+
+// Finding は置き換えた (または検出した) 個人情報の監査用の記録 (元の値は含めない)
+type Finding struct {
+	Type      string `json:"type"`
+	Start     int    `json:"start"`                // 元のテキストでの開始位置 (文字単位)
+	End       int    `json:"end"`                  // 元のテキストでの終了位置 (文字単位、この位置を含まない)
+	ValueHash string `json:"value_hash,omitempty"` // 元の値のHMAC-SHA256 (ハッシュの鍵を設定した場合のみ)
+}
+
+// Result は個人情報を置き換えた結果
+type Result struct {
+	Text     string    // 置き換えた後のテキスト
+	Findings []Finding // 置き換えた個人情報 (出現順)
+}
+
+// RejectedError は reject の場合に個人情報を検出したため拒否したことを表すエラー
+type RejectedError struct {
+	Types []string // 検出した個人情報の種類 (重複なし)
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("%v (%s)", ErrPIIDetected, strings.Join(e.Types, ", "))
+}
+
+func (e *RejectedError) Unwrap() error {
+	return ErrPIIDetected
+}
+
+// Redactor は複数の検出器で個人情報を検出して置き換える
+type Redactor struct {
+	detectors []Detector
+	key       []byte
+}
+
+// New は新しいRedactorを作成する
+// key はハッシュの鍵 (空の場合は hash を使用できず、監査の記録にも値のハッシュを含めない)
+func New(key string, detectors ...Detector) *Redactor {
+	return &Redactor{detectors: detectors, key: []byte(key)}
+}
+
+// Redact は mode に従ってテキストの個人情報を置き換える
+// reject の場合は個人情報を検出すると *RejectedError を返す
+func (r *Redactor) Redact(text string, mode Mode) (*Result, error) {
+	switch mode {
+	case ModeOff:
+		return &Result{Text: text}, nil
+	case ModeMask, ModeReject:
+	case ModeHash:
+		if len(r.key) == 0 {
+			return nil, ErrHashKeyRequired
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMode, mode)
+	}
+
+	matches := r.detect(text)
+	if len(matches) == 0 {
+		return &Result{Text: text}, nil
+	}
+	if mode == ModeReject {
+		var types []string
+		seen := make(map[string]bool)
+		for _, m := range matches {
+			if !seen[m.Type] {
+				seen[m.Type] = true
+				types = append(types, m.Type)
+			}
+		}
+		return nil, &RejectedError{Types: types}
+	}
+
+	var sb strings.Builder
+	findings := make([]Finding, 0, len(matches))
+	last, runes := 0, 0
+	for _, m := range matches {
+		value := text[m.Start:m.End]
+		runes += utf8.RuneCountInString(text[last:m.Start])
+		finding := Finding{Type: m.Type, Start: runes, End: runes + utf8.RuneCountInString(value)}
+		if len(r.key) > 0 {
+			finding.ValueHash = r.hash(value)
+		}
+		findings = append(findings, finding)
+		runes = finding.End
+
+		sb.WriteString(text[last:m.Start])
+		label := strings.ToUpper(m.Type)
+		if mode == ModeHash {
+			sb.WriteString("[" + label + ":" + finding.ValueHash[:hashTokenLength] + "]")
+		} else {
+			sb.WriteString("[" + label + "]")
+		}
+		last = m.End
+	}
+	sb.WriteString(text[last:])
+	return &Result{Text: sb.String(), Findings: findings}, nil
+}
+
+// detect はすべての検出器で個人情報を検出し、重ならない箇所を元のテキストの位置で出現順に返す
+// 重なる場合は先に始まる箇所を、同じ位置から始まる場合は長い箇所を優先する
+func (r *Redactor) detect(text string) []Match {
+	normalized, offsets := normalize(text)
+	var all []Match
+	for _, d := range r.detectors {
+		for _, m := range d.Detect(normalized) {
+			if m.Start < 0 || m.End > len(normalized) || m.Start >= m.End {
+				continue
+			}
+			all = append(all, Match{Type: m.Type, Start: offsets[m.Start], End: offsets[m.End]})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].Start != all[j].Start {
+			return all[i].Start < all[j].Start
+		}
+		return all[i].End > all[j].End
+	})
+
+	var matches []Match
+	end := 0
+	for _, m := range all {
+		if m.Start < end {
+			continue
+		}
+		matches = append(matches, m)
+		end = m.End
+	}
+	return matches
+}
+
+// hash は値のHMAC-SHA256を16進数で返す
+func (r *Redactor) hash(value string) string {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// normalize は全角の英数字・記号とハイフンの異体字を半角に寄せたテキストと、
+// その各バイトの位置に対応する元のテキストの位置 (末尾の位置を含む) を返す
+func normalize(text string) (string, []int) {
+	var sb strings.Builder
+	offsets := make([]int, 0, len(text)+1)
+	for i, c := range text {
+		n := normalizeRune(c)
+		before := sb.Len()
+		sb.WriteRune(n)
+		for range sb.Len() - before {
+			offsets = append(offsets, i)
+		}
+	}
+	offsets = append(offsets, len(text))
+	return sb.String(), offsets
+}
+
+// normalizeRune は全角の英数字・記号を半角に、ハイフンの異体字と全角の空白をASCIIの文字に置き換える
+func normalizeRune(c rune) rune {
+	switch {
+	case c >= '！' && c <= '～':
+		return c - 0xFEE0
+	case c == '‐', c == '‑', c == '‒', c == '–', c == '−':
+		return '-'
+	case c == '　':
+		return ' '
+	}
+	return c
+}
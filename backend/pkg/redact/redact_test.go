@@ -0,0 +1,97 @@
+package redact
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func builtinRedactor(t *testing.T, key string) *Redactor {
+	t.Helper()
+	var detectors []Detector
+	for _, name := range BuiltinTypes {
+		d, err := Builtin(name)
+		require.NoError(t, err)
+		detectors = append(detectors, d)
+	}
+	return New(key, detectors...)
+}
+
+func TestRedactor_Redact(t *testing.T) {
+	text := "担当: 山田\n電話 ０９０－１２３４－５６７８ / mail: yamada@example.co.jp\n〒100-0001 東京都"
+
+	t.Run("正常系: 種類を示す伏せ字に置き換え、元のテキストの文字位置を記録する", func(t *testing.T) {
+		result, err := builtinRedactor(t, "").Redact(text, ModeMask)
+
+		require.NoError(t, err)
+		assert.Equal(t, "担当: 山田\n電話 [PHONE] / mail: [EMAIL]\n[POSTAL_CODE] 東京都", result.Text)
+		require.Len(t, result.Findings, 3)
+		assert.Equal(t, Finding{Type: TypePhone, Start: 10, End: 23}, result.Findings[0])
+		assert.Equal(t, "０９０－１２３４－５６７８", string([]rune(text)[10:23]))
+		assert.Equal(t, TypeEmail, result.Findings[1].Type)
+		assert.Equal(t, "yamada@example.co.jp", string([]rune(text)[result.Findings[1].Start:result.Findings[1].End]))
+		assert.Equal(t, TypePostalCode, result.Findings[2].Type)
+	})
+
+	t.Run("正常系: 同じ値は同じハッシュに置き換える", func(t *testing.T) {
+		result, err := builtinRedactor(t, "secret").Redact("a@example.com と a@example.com", ModeHash)
+
+		require.NoError(t, err)
+		require.Len(t, result.Findings, 2)
+		hash := result.Findings[0].ValueHash
+		assert.Len(t, hash, 64)
+		assert.Equal(t, hash, result.Findings[1].ValueHash)
+		assert.Equal(t, "[EMAIL:"+hash[:12]+"] と [EMAIL:"+hash[:12]+"]", result.Text)
+	})
+
+	t.Run("正常系: 個人情報がない場合はそのまま返す", func(t *testing.T) {
+		result, err := builtinRedactor(t, "").Redact("第3条 2024-05-01 から有効とする", ModeMask)
+
+		require.NoError(t, err)
+		assert.Equal(t, "第3条 2024-05-01 から有効とする", result.Text)
+		assert.Empty(t, result.Findings)
+	})
+
+	t.Run("正常系: off の場合は検出しない", func(t *testing.T) {
+		result, err := builtinRedactor(t, "").Redact(text, ModeOff)
+
+		require.NoError(t, err)
+		assert.Equal(t, text, result.Text)
+	})
+
+	t.Run("異常系: reject の場合は検出した種類を返す", func(t *testing.T) {
+		_, err := builtinRedactor(t, "").Redact(text, ModeReject)
+
+		require.ErrorIs(t, err, ErrPIIDetected)
+		var rejected *RejectedError
+		require.True(t, errors.As(err, &rejected))
+		assert.Equal(t, []string{TypePhone, TypeEmail, TypePostalCode}, rejected.Types)
+	})
+
+	t.Run("異常系: 鍵を設定せずに hash を指定した", func(t *testing.T) {
+		_, err := builtinRedactor(t, "").Redact(text, ModeHash)
+
+		assert.ErrorIs(t, err, ErrHashKeyRequired)
+	})
+
+	t.Run("正常系: 重なる箇所は長い方を優先する", func(t *testing.T) {
+		short := DetectorFunc(func(string) []Match { return []Match{{Type: "a", Start: 0, End: 2}, {Type: "a", Start: 5, End: 7}} })
+		long := DetectorFunc(func(string) []Match { return []Match{{Type: "b", Start: 0, End: 4}} })
+
+		result, err := New("", short, long).Redact("0123456789", ModeMask)
+
+		require.NoError(t, err)
+		assert.Equal(t, "[B]4[A]789", result.Text)
+	})
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode(" Mask ")
+	require.NoError(t, err)
+	assert.Equal(t, ModeMask, mode)
+
+	_, err = ParseMode("drop")
+	assert.ErrorIs(t, err, ErrInvalidMode)
+}
@@ -0,0 +1,232 @@
+package webcrawl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrDisallowed は robots.txt でクロールが禁止されている場合のエラー
+var ErrDisallowed = errors.New("crawling is disallowed by robots.txt")
+
+// ErrTooLarge はレスポンスの本文が上限を超えた場合のエラー
+var ErrTooLarge = errors.New("response body is too large")
+
+const (
+	// defaultMaxBodySize はレスポンスの本文の大きさの既定の上限
+	defaultMaxBodySize = 10 << 20
+	// maxRobotsSize は robots.txt の大きさの上限 (RFC 9309 では少なくとも500KiBを解析する)
+	maxRobotsSize = 500 << 10
+	// maxRedirects はたどるリダイレクトの回数の上限
+	maxRedirects = 10
+)
+
+// Options はクライアントの設定
+type Options struct {
+	UserAgent   string
+	Delay       time.Duration // 同じホストへのリクエストの最小の間隔 (robots.txt の Crawl-delay の方が長ければそちらを使う)
+	Timeout     time.Duration // 1回のリクエストのタイムアウト
+	MaxBodySize int64         // レスポンスの本文の大きさの上限 (0以下の場合は10MiB)
+}
+
+// Conditional は条件付きリクエストに使う前回の取得結果
+type Conditional struct {
+	ETag         string
+	LastModified time.Time
+}
+
+// Response は取得したページ
+type Response struct {
+	URL          string // リダイレクトをたどった後のURL (正規化したもの)
+	StatusCode   int
+	NotModified  bool // 前回の取得から変更されていない (304 Not Modified)
+	Body         []byte
+	ContentType  string
+	ETag         string
+	LastModified time.Time // Last-Modified ヘッダーの日時 (ない場合はゼロ値)
+}
+
+// Client は robots.txt に従い、ホストごとに間隔を空けてページを取得するクライアント
+// robots.txt はホストごとに最初のリクエストの前に取得し、クライアントを破棄するまで再利用する
+type Client struct {
+	http        *http.Client
+	robotsHTTP  *http.Client
+	userAgent   string
+	delay       time.Duration
+	maxBodySize int64
+
+	mu     sync.Mutex
+	robots map[string]*robotsEntry // スキーム://ホスト → robots.txt
+	next   map[string]time.Time    // ホスト → 次にリクエストできる時刻
+}
+
+type robotsEntry struct {
+	once   sync.Once
+	robots *Robots
+}
+
+// NewClient は新しいクライアントを生成する
+func NewClient(opts Options) *Client {
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = defaultMaxBodySize
+	}
+	c := &Client{
+		userAgent:   opts.UserAgent,
+		delay:       opts.Delay,
+		maxBodySize: opts.MaxBodySize,
+		robots:      make(map[string]*robotsEntry),
+		next:        make(map[string]time.Time),
+	}
+	// リダイレクト先も robots.txt で許可されている場合のみたどる
+	c.http = &http.Client{
+		Timeout: opts.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if !c.Robots(req.Context(), req.URL).Allowed(req.URL.RequestURI()) {
+				return ErrDisallowed
+			}
+			return nil
+		},
+	}
+	c.robotsHTTP = &http.Client{Timeout: opts.Timeout}
+	return c
+}
+
+// Robots はURLのホストの robots.txt を返す
+// robots.txt が存在しない (4xx) 場合はすべてを許可し、取得できない (5xx・通信エラー) 場合はすべてを禁止する
+func (c *Client) Robots(ctx context.Context, u *url.URL) *Robots {
+	key := u.Scheme + "://" + u.Host
+	c.mu.Lock()
+	entry, ok := c.robots[key]
+	if !ok {
+		entry = &robotsEntry{}
+		c.robots[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.robots = c.fetchRobots(ctx, key)
+	})
+	return entry.robots
+}
+
+func (c *Client) fetchRobots(ctx context.Context, origin string) *Robots {
+	if err := c.wait(ctx, origin, c.delay); err != nil {
+		return disallowAll
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return disallowAll
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	resp, err := c.robotsHTTP.Do(req)
+	if err != nil {
+		return disallowAll
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+		if err != nil {
+			return disallowAll
+		}
+		return ParseRobots(body, c.userAgent)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return allowAll
+	default:
+		return disallowAll
+	}
+}
+
+// Fetch はページを取得する
+// cond を指定すると If-None-Match・If-Modified-Since を付けて取得し、変更されていなければ NotModified を返す
+// robots.txt で禁止されている場合は ErrDisallowed を返す。2xx・304 以外のステータスコードはエラーにせず、StatusCode に入れて返す
+func (c *Client) Fetch(ctx context.Context, rawURL string, cond Conditional) (*Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	robots := c.Robots(ctx, u)
+	if !robots.Allowed(u.RequestURI()) {
+		return nil, ErrDisallowed
+	}
+	if err := c.wait(ctx, u.Scheme+"://"+u.Host, max(c.delay, robots.CrawlDelay)); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	if cond.ETag != "" {
+		req.Header.Set("If-None-Match", cond.ETag)
+	}
+	if !cond.LastModified.IsZero() {
+		req.Header.Set("If-Modified-Since", cond.LastModified.UTC().Format(http.TimeFormat))
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrDisallowed) {
+			return nil, ErrDisallowed
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &Response{
+		URL:         NormalizeURL(resp.Request.URL),
+		StatusCode:  resp.StatusCode,
+		NotModified: resp.StatusCode == http.StatusNotModified,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        resp.Header.Get("ETag"),
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		result.LastModified = t
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.maxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	if int64(len(body)) > c.maxBodySize {
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrTooLarge, rawURL, c.maxBodySize)
+	}
+	result.Body = body
+	return result, nil
+}
+
+// wait は同じホストへの前回のリクエストから delay が経過するまで待つ
+func (c *Client) wait(ctx context.Context, origin string, delay time.Duration) error {
+	c.mu.Lock()
+	now := time.Now()
+	at := c.next[origin]
+	if at.Before(now) {
+		at = now
+	}
+	c.next[origin] = at.Add(delay)
+	c.mu.Unlock()
+
+	if d := time.Until(at); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
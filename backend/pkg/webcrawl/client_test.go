@@ -0,0 +1,148 @@
+package webcrawl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Fetch(t *testing.T) {
+	ctx := context.Background()
+	lastModified := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	var robotsRequests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		robotsRequests.Add(1)
+		w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "rag-crawler/1.0", r.UserAgent())
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<p>本文</p>"))
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/to-private", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/private/a", http.StatusFound)
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 2048))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := NewClient(Options{UserAgent: "rag-crawler/1.0", Timeout: 5 * time.Second, MaxBodySize: 1024})
+
+	t.Run("正常系: ページを取得し、ETag と Last-Modified を返す", func(t *testing.T) {
+		resp, err := c.Fetch(ctx, server.URL+"/page", Conditional{})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "<p>本文</p>", string(resp.Body))
+		assert.Equal(t, `"v1"`, resp.ETag)
+		assert.True(t, lastModified.Equal(resp.LastModified))
+	})
+
+	t.Run("正常系: 変更されていなければ NotModified を返す", func(t *testing.T) {
+		resp, err := c.Fetch(ctx, server.URL+"/page", Conditional{ETag: `"v1"`, LastModified: lastModified})
+
+		require.NoError(t, err)
+		assert.True(t, resp.NotModified)
+		assert.Empty(t, resp.Body)
+	})
+
+	t.Run("正常系: リダイレクト後のURLを返す", func(t *testing.T) {
+		resp, err := c.Fetch(ctx, server.URL+"/old", Conditional{})
+
+		require.NoError(t, err)
+		assert.Equal(t, server.URL+"/page", resp.URL)
+	})
+
+	t.Run("正常系: 見つからないページはステータスコードを返す", func(t *testing.T) {
+		resp, err := c.Fetch(ctx, server.URL+"/missing", Conditional{})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("異常系: robots.txt で禁止されている", func(t *testing.T) {
+		_, err := c.Fetch(ctx, server.URL+"/private/a", Conditional{})
+
+		assert.ErrorIs(t, err, ErrDisallowed)
+	})
+
+	t.Run("異常系: 禁止されたパスへのリダイレクト", func(t *testing.T) {
+		_, err := c.Fetch(ctx, server.URL+"/to-private", Conditional{})
+
+		assert.ErrorIs(t, err, ErrDisallowed)
+	})
+
+	t.Run("異常系: 本文が上限を超える", func(t *testing.T) {
+		_, err := c.Fetch(ctx, server.URL+"/large", Conditional{})
+
+		assert.ErrorIs(t, err, ErrTooLarge)
+	})
+
+	assert.Equal(t, int32(1), robotsRequests.Load(), "robots.txt はホストごとに1回だけ取得する")
+}
+
+func TestClient_Robots(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		status int
+		want   bool
+	}{
+		{name: "robots.txt がなければすべてを許可する", status: http.StatusNotFound, want: true},
+		{name: "robots.txt を取得できなければすべてを禁止する", status: http.StatusServiceUnavailable, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+			c := NewClient(Options{UserAgent: "rag-crawler"})
+
+			u, err := url.Parse(server.URL + "/page")
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, c.Robots(ctx, u).Allowed("/page"))
+		})
+	}
+}
+
+func TestClient_Delay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	c := NewClient(Options{UserAgent: "rag-crawler", Delay: 50 * time.Millisecond})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := c.Fetch(context.Background(), server.URL+"/page", Conditional{})
+		require.NoError(t, err)
+	}
+
+	// robots.txt を含めて4回のリクエストの間に3回待つ
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+}
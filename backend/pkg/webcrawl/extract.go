@@ -0,0 +1,336 @@
+package webcrawl
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Page はHTMLから抽出したページの内容
+type Page struct {
+	Title    string
+	Text     string   // 本文 (見出しは "#"、リストの項目は "- " で始まる行にする)
+	Links    []string // ページ内のリンク (正規化した http/https のURL。rel="nofollow" のリンクを除く)
+	NoIndex  bool     // <meta name="robots" content="noindex"> が指定されている
+	NoFollow bool     // <meta name="robots" content="nofollow"> が指定されている
+}
+
+// skippedElements は本文に含めない要素 (ナビゲーションや装飾、スクリプトなど)
+var skippedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Form: true, atom.Button: true, atom.Select: true, atom.Iframe: true, atom.Svg: true,
+}
+
+// blockElements は前後で改行する要素
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Ul: true, atom.Ol: true, atom.Li: true,
+	atom.Dl: true, atom.Dt: true, atom.Dd: true, atom.Table: true, atom.Tr: true,
+	atom.Figure: true, atom.Figcaption: true, atom.Details: true, atom.Summary: true, atom.Hr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+// headingLevels は見出しの要素とそのレベル
+var headingLevels = map[atom.Atom]int{atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6}
+
+// Extract はHTMLからタイトル・本文・リンクを抽出する
+// 本文は <main>・role="main"・<article> のいずれか (なければ <body>) から、ナビゲーションやスクリプトなどを除いて抽出する
+// リンクは base (<base href> があればそれ) を基準に解決する
+func Extract(body []byte, base *url.URL) (*Page, error) {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	page := &Page{}
+	var mainRoot, article, bodyNode, firstH1 *html.Node
+	var anchors []*html.Node
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Title:
+				if page.Title == "" {
+					page.Title = collapseSpaces(textContent(n))
+				}
+			case atom.Base:
+				if href, ok := attr(n, "href"); ok {
+					if u, err := base.Parse(href); err == nil {
+						base = u
+					}
+				}
+			case atom.Meta:
+				if name, _ := attr(n, "name"); strings.EqualFold(name, "robots") {
+					content, _ := attr(n, "content")
+					for _, directive := range strings.Split(strings.ToLower(content), ",") {
+						switch strings.TrimSpace(directive) {
+						case "noindex":
+							page.NoIndex = true
+						case "nofollow":
+							page.NoFollow = true
+						case "none":
+							page.NoIndex, page.NoFollow = true, true
+						}
+					}
+				}
+			case atom.A, atom.Area:
+				anchors = append(anchors, n)
+			case atom.Main:
+				if mainRoot == nil {
+					mainRoot = n
+				}
+			case atom.Article:
+				if article == nil {
+					article = n
+				}
+			case atom.Body:
+				bodyNode = n
+			case atom.H1:
+				if firstH1 == nil {
+					firstH1 = n
+				}
+			}
+			if role, _ := attr(n, "role"); role == "main" && mainRoot == nil {
+				mainRoot = n
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(doc)
+
+	if page.Title == "" && firstH1 != nil {
+		page.Title = collapseSpaces(textContent(firstH1))
+	}
+
+	root := doc
+	for _, n := range []*html.Node{mainRoot, article, bodyNode} {
+		if n != nil {
+			root = n
+			break
+		}
+	}
+	w := &textWriter{}
+	w.node(root)
+	page.Text = w.String()
+
+	seen := make(map[string]bool)
+	for _, a := range anchors {
+		href, ok := attr(a, "href")
+		if !ok {
+			continue
+		}
+		if rel, _ := attr(a, "rel"); containsToken(rel, "nofollow") {
+			continue
+		}
+		u, err := base.Parse(strings.TrimSpace(href))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		link := NormalizeURL(u)
+		if !seen[link] {
+			seen[link] = true
+			page.Links = append(page.Links, link)
+		}
+	}
+	return page, nil
+}
+
+// NormalizeURL はURLを正規化する (スキームとホストを小文字にし、既定のポートとフラグメントを除く)
+// 同じページを指すURLを1つのドキュメントにまとめるため、取り込み元のURIには正規化したURLを使う
+func NormalizeURL(u *url.URL) string {
+	n := *u
+	n.Scheme = strings.ToLower(n.Scheme)
+	host := strings.ToLower(n.Hostname())
+	if port := n.Port(); port != "" && !(n.Scheme == "http" && port == "80") && !(n.Scheme == "https" && port == "443") {
+		host += ":" + port
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	n.Host = host
+	n.User = nil
+	n.Fragment = ""
+	n.RawFragment = ""
+	if n.Path == "" {
+		n.Path = "/"
+		n.RawPath = ""
+	}
+	return n.String()
+}
+
+// textWriter は要素の構造を保ってテキストを組み立てる
+type textWriter struct {
+	lines  []string
+	line   strings.Builder
+	prefix string // 現在の行の先頭に付ける文字列 (見出しやリストの項目)
+	space  bool   // 次の単語の前に空白を入れるかどうか
+	pre    int    // <pre> の入れ子の深さ
+}
+
+func (w *textWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+		if skippedElements[n.DataAtom] || isHidden(n) {
+			return
+		}
+	case html.DocumentNode:
+	default:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		w.breakLine()
+		return
+	case atom.Td, atom.Th:
+		if w.line.Len() > 0 {
+			w.line.WriteString(" | ")
+			w.space = false
+		}
+	case atom.Img:
+		if alt, _ := attr(n, "alt"); strings.TrimSpace(alt) != "" {
+			w.text(alt)
+		}
+		return
+	}
+
+	block := blockElements[n.DataAtom]
+	if block {
+		w.breakLine()
+	}
+	if level, ok := headingLevels[n.DataAtom]; ok {
+		w.paragraph()
+		w.prefix = strings.Repeat("#", level) + " "
+	}
+	if n.DataAtom == atom.Li {
+		w.prefix = "- "
+	}
+	if n.DataAtom == atom.Pre {
+		w.pre++
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+
+	if n.DataAtom == atom.Pre {
+		w.pre--
+	}
+	if block {
+		w.breakLine()
+		switch n.DataAtom {
+		case atom.P, atom.Pre, atom.Blockquote, atom.Table, atom.Ul, atom.Ol, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			w.paragraph()
+		}
+	}
+}
+
+// text はテキストを現在の行に追加する (<pre> の中以外では連続した空白を1つにまとめる)
+func (w *textWriter) text(s string) {
+	if w.pre > 0 {
+		for i, l := range strings.Split(s, "\n") {
+			if i > 0 {
+				w.breakLine()
+			}
+			w.line.WriteString(l)
+		}
+		return
+	}
+	if s != "" && isSpace(s[0]) {
+		w.space = true
+	}
+	for _, word := range strings.Fields(s) {
+		if w.space && w.line.Len() > 0 {
+			w.line.WriteByte(' ')
+		}
+		w.line.WriteString(word)
+		w.space = true
+	}
+	if s != "" && !isSpace(s[len(s)-1]) {
+		w.space = false
+	}
+}
+
+// breakLine は現在の行を確定する
+func (w *textWriter) breakLine() {
+	line := strings.TrimRight(w.line.String(), " ")
+	if strings.TrimSpace(line) != "" {
+		w.lines = append(w.lines, w.prefix+line)
+		w.prefix = ""
+	}
+	w.line.Reset()
+	w.space = false
+}
+
+// paragraph は段落の区切り (空行) を入れる
+func (w *textWriter) paragraph() {
+	w.breakLine()
+	if n := len(w.lines); n > 0 && w.lines[n-1] != "" {
+		w.lines = append(w.lines, "")
+	}
+}
+
+func (w *textWriter) String() string {
+	w.breakLine()
+	return strings.TrimSpace(strings.Join(w.lines, "\n"))
+}
+
+// isHidden は表示されない要素かどうかを返す
+func isHidden(n *html.Node) bool {
+	if _, ok := attr(n, "hidden"); ok {
+		return true
+	}
+	v, _ := attr(n, "aria-hidden")
+	return v == "true"
+}
+
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && strings.EqualFold(a.Key, key) {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// textContent は要素内のテキストを連結して返す
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(n)
+	return sb.String()
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func containsToken(list, token string) bool {
+	for _, t := range strings.Fields(strings.ToLower(list)) {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,115 @@
+package webcrawl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtract(t *testing.T) {
+	base, err := url.Parse("https://wiki.example.com/hr/leave")
+	require.NoError(t, err)
+
+	t.Run("正常系: main の本文を構造を保って抽出し、ナビゲーションを除く", func(t *testing.T) {
+		body := []byte(`<!DOCTYPE html>
+<html><head><title> 休暇規程 | 社内Wiki </title><script>var x = 1;</script></head>
+<body>
+<header><a href="/">トップ</a></header>
+<nav><a href="/hr/">人事</a> <a href="/hr/leave#top">休暇</a></nav>
+<main>
+  <h1>休暇規程</h1>
+  <p>年次有給休暇は
+     入社6か月後に<b>10日</b>付与する。</p>
+  <h2>申請</h2>
+  <ul><li>3日前までに申請する</li><li>上長が<a href="approve">承認</a>する</li></ul>
+  <div hidden>非表示</div>
+  <table><tr><th>勤続年数</th><th>日数</th></tr><tr><td>1年</td><td>11日</td></tr></table>
+  <pre>line1
+  line2</pre>
+</main>
+<footer>Copyright <a href="https://other.example.com/" rel="nofollow">外部</a></footer>
+</body></html>`)
+
+		page, err := Extract(body, base)
+
+		require.NoError(t, err)
+		assert.Equal(t, "休暇規程 | 社内Wiki", page.Title)
+		assert.Equal(t, "# 休暇規程\n\n年次有給休暇は 入社6か月後に10日付与する。\n\n## 申請\n\n- 3日前までに申請する\n- 上長が承認する\n\n勤続年数 | 日数\n1年 | 11日\n\nline1\n  line2", page.Text)
+		assert.Equal(t, []string{"https://wiki.example.com/", "https://wiki.example.com/hr/", "https://wiki.example.com/hr/leave", "https://wiki.example.com/hr/approve"}, page.Links)
+		assert.False(t, page.NoIndex)
+	})
+
+	t.Run("正常系: main がなければ body から抽出し、タイトルがなければ h1 を使う", func(t *testing.T) {
+		body := []byte(`<html><body><h1>FAQ</h1><p>質問と回答</p><base href="https://wiki.example.com/faq/"><a href="q1">Q1</a></body></html>`)
+
+		page, err := Extract(body, base)
+
+		require.NoError(t, err)
+		assert.Equal(t, "FAQ", page.Title)
+		assert.Equal(t, "# FAQ\n\n質問と回答\n\nQ1", page.Text)
+		assert.Equal(t, []string{"https://wiki.example.com/faq/q1"}, page.Links)
+	})
+
+	t.Run("正常系: robots メタタグ", func(t *testing.T) {
+		page, err := Extract([]byte(`<html><head><meta name="ROBOTS" content="noindex, nofollow"></head><body>x</body></html>`), base)
+
+		require.NoError(t, err)
+		assert.True(t, page.NoIndex)
+		assert.True(t, page.NoFollow)
+	})
+}
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "HTTPS://Wiki.Example.com:443/a?b=1#c", want: "https://wiki.example.com/a?b=1"},
+		{in: "http://wiki.example.com", want: "http://wiki.example.com/"},
+		{in: "http://127.0.0.1:8080/x", want: "http://127.0.0.1:8080/x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			u, err := url.Parse(tt.in)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, NormalizeURL(u))
+		})
+	}
+}
+
+func TestParseSitemap(t *testing.T) {
+	t.Run("正常系: urlset", func(t *testing.T) {
+		sitemap, err := ParseSitemap([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc> https://wiki.example.com/a </loc><lastmod>2026-10-01</lastmod></url>
+  <url><loc>https://wiki.example.com/b</loc></url>
+</urlset>`))
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://wiki.example.com/a", "https://wiki.example.com/b"}, sitemap.URLs)
+	})
+
+	t.Run("正常系: gzip で圧縮したサイトマップインデックス", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write([]byte(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><sitemap><loc>https://wiki.example.com/s1.xml</loc></sitemap></sitemapindex>`))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+
+		sitemap, err := ParseSitemap(buf.Bytes())
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://wiki.example.com/s1.xml"}, sitemap.Sitemaps)
+	})
+
+	t.Run("異常系: サイトマップではない", func(t *testing.T) {
+		_, err := ParseSitemap([]byte(`<html></html>`))
+
+		assert.Error(t, err)
+	})
+}
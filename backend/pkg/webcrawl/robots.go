@@ -0,0 +1,154 @@
+// Package webcrawl はWebページのクロールに必要な処理 (robots.txt・サイトマップの解析、HTMLの本文の抽出、条件付きリクエスト) を提供する
+package webcrawl
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Robots は robots.txt のうち、クローラーに適用されるグループの規則
+type Robots struct {
+	rules      []robotsRule
+	CrawlDelay time.Duration // 同じホストへのリクエストの間隔 (指定がない場合は0)
+	Sitemaps   []string      // robots.txt に記載されたサイトマップのURL
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// allowAll はすべてのパスを許可する規則 (robots.txt が存在しない場合)
+var allowAll = &Robots{}
+
+// disallowAll はすべてのパスを禁止する規則 (robots.txt を取得できない場合)
+var disallowAll = &Robots{rules: []robotsRule{{allow: false, pattern: "/"}}}
+
+// robotsGroup は User-agent 行で始まる規則のまとまり
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// ParseRobots は robots.txt を解析し、userAgent に適用される規則を返す (RFC 9309)
+// プロダクトトークン (userAgent の "/" より前) に一致するグループがあればそれを、なければ "*" のグループを使う
+// 一致するグループが複数ある場合は規則を合わせる
+func ParseRobots(body []byte, userAgent string) *Robots {
+	token := strings.ToLower(userAgent)
+	if i := strings.IndexAny(token, "/ "); i >= 0 {
+		token = token[:i]
+	}
+
+	var groups []*robotsGroup
+	var current *robotsGroup
+	robots := &Robots{}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// 規則の後の User-agent 行は新しいグループの始まり
+			if current == nil || len(current.rules) > 0 || current.crawlDelay > 0 {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			// 空の Disallow はすべてを許可する (規則なしと同じ)
+			if current == nil || value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{allow: key == "allow", pattern: value})
+		case "crawl-delay":
+			if current == nil {
+				continue
+			}
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		case "sitemap":
+			if value != "" {
+				robots.Sitemaps = append(robots.Sitemaps, value)
+			}
+		}
+	}
+
+	for _, agent := range []string{token, "*"} {
+		matched := false
+		for _, g := range groups {
+			for _, a := range g.agents {
+				if a == agent {
+					matched = true
+					robots.rules = append(robots.rules, g.rules...)
+					robots.CrawlDelay = max(robots.CrawlDelay, g.crawlDelay)
+					break
+				}
+			}
+		}
+		if matched {
+			break
+		}
+	}
+	return robots
+}
+
+// Allowed はパス (クエリを含む) をクロールしてよいかどうかを返す
+// 一致する規則のうちパターンが最も長いものを使い、同じ長さの場合は Allow を優先する。/robots.txt は常に許可する
+func (r *Robots) Allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	allowed, longest := true, -1
+	for _, rule := range r.rules {
+		if !matchRobotsPattern(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > longest || (n == longest && rule.allow) {
+			allowed, longest = rule.allow, n
+		}
+	}
+	return allowed
+}
+
+// matchRobotsPattern はパスがパターンに前方一致するかどうかを返す
+// パターンの * は任意の文字列に、末尾の $ はパスの末尾に一致する
+func matchRobotsPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i, part := range parts[1:] {
+		// 末尾を固定する場合、最後の部分はパスの末尾と一致させる
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		j := strings.Index(rest, part)
+		if j < 0 {
+			return false
+		}
+		rest = rest[j+len(part):]
+	}
+	return !anchored || rest == ""
+}
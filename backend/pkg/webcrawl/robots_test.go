@@ -0,0 +1,66 @@
+package webcrawl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRobots(t *testing.T) {
+	body := []byte(`# 社内Wiki
+User-agent: *
+Disallow: /private/
+Disallow: /*.pdf$
+Allow: /private/public/
+
+User-agent: other-bot
+User-agent: rag-crawler
+Disallow: /drafts
+Allow: /drafts/published
+Crawl-delay: 1.5
+
+Sitemap: https://wiki.example.com/sitemap.xml
+`)
+
+	t.Run("プロダクトトークンに一致するグループの規則を使う", func(t *testing.T) {
+		r := ParseRobots(body, "rag-crawler/1.0")
+
+		assert.False(t, r.Allowed("/drafts/2026"))
+		assert.True(t, r.Allowed("/drafts/published/a"))
+		assert.True(t, r.Allowed("/private/a"), "* のグループは使わない")
+		assert.Equal(t, 1500*time.Millisecond, r.CrawlDelay)
+		assert.Equal(t, []string{"https://wiki.example.com/sitemap.xml"}, r.Sitemaps)
+	})
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{name: "規則に一致しないパスは許可する", path: "/wiki/leave", want: true},
+		{name: "前方一致で禁止する", path: "/private/salary", want: false},
+		{name: "より長い Allow を優先する", path: "/private/public/handbook", want: true},
+		{name: "* と $ のパターン", path: "/files/a.pdf", want: false},
+		{name: "$ は末尾に一致する", path: "/files/a.pdf?download=1", want: true},
+		{name: "robots.txt は常に許可する", path: "/robots.txt", want: true},
+	}
+	r := ParseRobots(body, "another-crawler")
+	for _, tt := range tests {
+		t.Run("* のグループ: "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.Allowed(tt.path))
+		})
+	}
+
+	t.Run("空の Disallow はすべてを許可する", func(t *testing.T) {
+		r := ParseRobots([]byte("User-agent: *\nDisallow:\n"), "rag-crawler")
+
+		assert.True(t, r.Allowed("/any"))
+	})
+
+	t.Run("同じ長さの規則は Allow を優先する", func(t *testing.T) {
+		r := ParseRobots([]byte("User-agent: *\nDisallow: /page\nAllow: /page\n"), "rag-crawler")
+
+		assert.True(t, r.Allowed("/page"))
+	})
+}
@@ -0,0 +1,65 @@
+package webcrawl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxSitemapSize はサイトマップの大きさの上限 (展開後。sitemaps.org の上限の50MB)
+const maxSitemapSize = 50 << 20
+
+// Sitemap はサイトマップ (urlset) またはサイトマップインデックス (sitemapindex) の内容
+type Sitemap struct {
+	URLs     []string // ページのURL
+	Sitemaps []string // サイトマップインデックスが参照するサイトマップのURL
+}
+
+type sitemapXML struct {
+	XMLName xml.Name
+	URLs    []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// ParseSitemap はサイトマップを解析する (gzip で圧縮されたものにも対応する)
+func ParseSitemap(body []byte) (*Sitemap, error) {
+	if len(body) >= 2 && body[0] == 0x1f && body[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+		defer zr.Close()
+		body, err = io.ReadAll(io.LimitReader(zr, maxSitemapSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+	}
+
+	var doc sitemapXML
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse sitemap: %w", err)
+	}
+	if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+		return nil, fmt.Errorf("failed to parse sitemap: unexpected root element <%s>", doc.XMLName.Local)
+	}
+
+	sitemap := &Sitemap{}
+	for _, u := range doc.URLs {
+		if loc := strings.TrimSpace(u.Loc); loc != "" {
+			sitemap.URLs = append(sitemap.URLs, loc)
+		}
+	}
+	for _, s := range doc.Sitemaps {
+		if loc := strings.TrimSpace(s.Loc); loc != "" {
+			sitemap.Sitemaps = append(sitemap.Sitemaps, loc)
+		}
+	}
+	return sitemap, nil
+}
@@ -27,7 +27,7 @@ resource "aws_lb_target_group" "app" {
   health_check {
     enabled             = true
     interval            = 30
-    path                = "/" # Adjust to your backend health check endpoint (e.g., /health)
+    path                = "/readyz" # 必須の依存先 (S3) が停止している場合のみ503を返す (Bedrock・DBの障害は縮退として200を返す)
     protocol            = "HTTP"
     matcher             = "200" # Expect HTTP 200 OK
     timeout             = 5
@@ -39,6 +39,8 @@ resource "aws_ecs_task_definition" "app" {
       name      = "${local.project_name}-app-container"
       image     = aws_ecr_repository.app.repository_url # Will be replaced with specific image tag during deployment
       essential = true
+      # SIGTERM 受信後、SERVER_SHUTDOWN_TIMEOUT (デフォルト25秒) の間に処理中のリクエストを完了させる
+      stopTimeout = 30
       portMappings = [
         {
           containerPort = 8080 # Port the Go application listens on inside the container
@@ -46,10 +48,10 @@ resource "aws_ecs_task_definition" "app" {
           protocol      = "tcp"
         }
       ]
-      # Define environment variables if needed
-      # environment = [
-      #   { name = "EXAMPLE_VAR", value = "example_value" }
-      # ]
+      environment = [
+        # ALB (VPC内) が付与した X-Forwarded-For から送信元IPアドレスを決める (レート制限の単位)
+        { name = "SERVER_TRUSTED_PROXIES", value = aws_vpc.main.cidr_block }
+      ]
       logConfiguration = {
         logDriver = "awslogs"
         options = {